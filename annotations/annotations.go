@@ -0,0 +1,135 @@
+// Package annotations keeps personal notes attached to lines of files. The
+// notes live in one file in the config directory, never in the annotated
+// files themselves.
+package annotations
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// searchRadius is how many lines either side of a note's last known line
+// are searched for its text after edits move it
+const searchRadius = 500
+
+// Note is an annotation on one line of a file
+type Note struct {
+	Line int    `toml:"line"` // 0-indexed line where the note was last seen
+	Hash string `toml:"hash"` // LineHash of that line, used to find it again after edits
+	Text string `toml:"text"`
+}
+
+// Store holds the notes of every annotated file, keyed by absolute path
+type Store struct {
+	Files map[string][]Note `toml:"files"`
+
+	path string // File the store was loaded from and saves to ("" = memory only)
+}
+
+// Load reads the store at path. A missing file gives an empty store.
+func Load(path string) (*Store, error) {
+	s := &Store{Files: make(map[string][]Note), path: path}
+	if path == "" {
+		return s, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+	if _, err := toml.DecodeFile(path, s); err != nil {
+		return s, err
+	}
+	if s.Files == nil {
+		s.Files = make(map[string][]Note)
+	}
+	return s, nil
+}
+
+// Save writes the store back to the file it was loaded from
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	f.WriteString("# Textivus line annotations\n\n")
+	return toml.NewEncoder(f).Encode(s)
+}
+
+// Notes returns a copy of the notes for file, ordered by line
+func (s *Store) Notes(file string) []Note {
+	return append([]Note(nil), s.Files[file]...)
+}
+
+// SetNotes replaces the notes for file; an empty list forgets the file
+func (s *Store) SetNotes(file string, notes []Note) {
+	if len(notes) == 0 {
+		delete(s.Files, file)
+		return
+	}
+	s.Files[file] = append([]Note(nil), notes...)
+}
+
+// LineHash identifies a line's text, ignoring surrounding whitespace so
+// re-indenting a line keeps its notes
+func LineHash(line string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.TrimSpace(line)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Anchor moves each note to the line holding its text nearest to where it
+// was last seen, so notes follow lines as text is inserted or deleted above
+// them. Notes whose line text is gone stay put (clamped to the document)
+// and are reported by Detached. Returns true if any note moved.
+func Anchor(notes []Note, lines []string) bool {
+	moved := false
+	for i := range notes {
+		n := &notes[i]
+		line := findLine(lines, n.Hash, n.Line)
+		if line < 0 {
+			line = max(0, min(n.Line, len(lines)-1))
+		}
+		if line != n.Line {
+			n.Line = line
+			moved = true
+		}
+	}
+	if moved {
+		sort.SliceStable(notes, func(i, j int) bool { return notes[i].Line < notes[j].Line })
+	}
+	return moved
+}
+
+// Detached reports whether note n no longer sits on the line it was written for
+func Detached(n Note, lines []string) bool {
+	return n.Line >= len(lines) || LineHash(lines[n.Line]) != n.Hash
+}
+
+// findLine returns the line nearest to from whose hash is hash, or -1
+func findLine(lines []string, hash string, from int) int {
+	for d := 0; d <= searchRadius; d++ {
+		below, above := from+d, from-d
+		if below >= len(lines) && above < 0 {
+			break
+		}
+		if below >= 0 && below < len(lines) && LineHash(lines[below]) == hash {
+			return below
+		}
+		if d > 0 && above >= 0 && above < len(lines) && LineHash(lines[above]) == hash {
+			return above
+		}
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+package annotations
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAnchorFollowsLines(t *testing.T) {
+	lines := []string{"a", "func main() {", "}", "b"}
+	notes := []Note{{Line: 1, Hash: LineHash("func main() {"), Text: "entry point"}}
+
+	// Two lines inserted above, and the line re-indented
+	lines = []string{"x", "y", "a", "  func main() {", "}", "b"}
+	if !Anchor(notes, lines) || notes[0].Line != 3 {
+		t.Errorf("note on line %d, want it to follow its text to line 3", notes[0].Line)
+	}
+	if Detached(notes[0], lines) {
+		t.Errorf("note should still be attached")
+	}
+
+	// The line itself is gone: the note stays where it was
+	lines = []string{"x", "y"}
+	Anchor(notes, lines)
+	if notes[0].Line != 1 || !Detached(notes[0], lines) {
+		t.Errorf("note on line %d, want it clamped to line 1 and detached", notes[0].Line)
+	}
+}
+
+func TestAnchorPrefersNearestCopy(t *testing.T) {
+	lines := []string{"}", "a", "b", "}", "c"}
+	notes := []Note{{Line: 2, Hash: LineHash("}")}}
+	Anchor(notes, lines)
+	if notes[0].Line != 3 {
+		t.Errorf("note on line %d, want the nearer brace on line 3", notes[0].Line)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations.toml")
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetNotes("/src/main.go", []Note{{Line: 4, Hash: LineHash("x"), Text: "check this"}})
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notes := s.Notes("/src/main.go")
+	if len(notes) != 1 || notes[0].Text != "check this" || notes[0].Line != 4 {
+		t.Errorf("loaded %+v, want the saved note", notes)
+	}
+	s.SetNotes("/src/main.go", nil)
+	if _, ok := s.Files["/src/main.go"]; ok {
+		t.Errorf("file with no notes left should be forgotten")
+	}
+}
@@ -1,9 +1,11 @@
 package clipboard
 
 import (
+	"encoding/base64"
 	"io"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 
 	"github.com/aymanbagabas/go-osc52/v2"
@@ -17,20 +19,62 @@ const (
 	ToolXclip
 	ToolXsel
 	ToolWlClipboard
+	ToolPbcopy
 )
 
+// Provider selects how the clipboard reaches the system clipboard
+type Provider int
+
+const (
+	// ProviderAuto uses native tools locally and OSC52 over SSH
+	ProviderAuto Provider = iota
+	// ProviderNative only uses native tools (xclip, xsel, wl-copy, pbcopy)
+	ProviderNative
+	// ProviderOSC52 only uses terminal escape sequences
+	ProviderOSC52
+	// ProviderInternal never touches the system clipboard
+	ProviderInternal
+)
+
+// ParseProvider converts a config value ("auto", "native", "osc52",
+// "internal") to a Provider, defaulting to ProviderAuto
+func ParseProvider(name string) Provider {
+	switch strings.ToLower(name) {
+	case "native":
+		return ProviderNative
+	case "osc52":
+		return ProviderOSC52
+	case "internal", "off":
+		return ProviderInternal
+	default:
+		return ProviderAuto
+	}
+}
+
 // Clipboard provides unified clipboard access with OSC52 support for SSH.
 type Clipboard struct {
 	// Internal clipboard for when no system clipboard is available
-	internal string
+	internal  string
+	localOnly bool // internal holds a CopyLocal newer than the system clipboard
 	// Whether we're likely in an SSH session
 	isSSH bool
 	// Output writer for OSC52 sequences (typically os.Stdout)
 	output io.Writer
-	// Detected clipboard tool
-	tool ClipboardTool
+	// Detected clipboard tool (valid once detected is set)
+	tool     ClipboardTool
+	detected bool
 	// Whether we've warned about missing clipboard tools
 	warned bool
+	// Ring of previously copied/killed text (for Emacs-style yank-pop)
+	ring *KillRing
+	// How the system clipboard is reached
+	provider Provider
+	// Whether the terminal may be asked for its clipboard (OSC52 query)
+	osc52Read bool
+	// Named registers (a-z, 0-9), kept apart from the system clipboard
+	registers map[rune]string
+	// Last text set as the primary selection, for when it can't be read back
+	primary string
 }
 
 // New creates a new Clipboard instance.
@@ -41,7 +85,7 @@ func New(output io.Writer) *Clipboard {
 	return &Clipboard{
 		isSSH:  isSSHSession(),
 		output: output,
-		tool:   detectClipboardTool(),
+		ring:   NewKillRing(DefaultKillRingSize),
 	}
 }
 
@@ -60,6 +104,16 @@ func isSSHSession() bool {
 	return false
 }
 
+// nativeTool returns the clipboard tool, searching PATH on first use
+// rather than at startup, since lookups can be slow on network filesystems
+func (c *Clipboard) nativeTool() ClipboardTool {
+	if !c.detected {
+		c.tool = detectClipboardTool()
+		c.detected = true
+	}
+	return c.tool
+}
+
 // detectClipboardTool finds an available clipboard tool
 func detectClipboardTool() ClipboardTool {
 	// Check for Wayland first if WAYLAND_DISPLAY is set
@@ -71,6 +125,15 @@ func detectClipboardTool() ClipboardTool {
 		}
 	}
 
+	// macOS ships pbcopy/pbpaste
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("pbcopy"); err == nil {
+			if _, err := exec.LookPath("pbpaste"); err == nil {
+				return ToolPbcopy
+			}
+		}
+	}
+
 	// Check for X11 tools
 	if os.Getenv("DISPLAY") != "" {
 		if _, err := exec.LookPath("xclip"); err == nil {
@@ -88,8 +151,107 @@ func detectClipboardTool() ClipboardTool {
 // In SSH sessions, it uses OSC52 escape sequences.
 // Locally, it tries native clipboard tools first.
 func (c *Clipboard) Copy(text string) error {
+	c.ring.Push(text)
+	return c.store(text)
+}
+
+// CopyLocal keeps text in the editor's own clipboard and kill ring without
+// handing it to the system clipboard, for text too large to send there.
+func (c *Clipboard) CopyLocal(text string) {
+	c.ring.Push(text)
+	c.internal = text
+	c.localOnly = true
+}
+
+// Kill copies killed text to the clipboard. With appendKill set, the text is
+// appended to the most recent kill instead (consecutive kills accumulate).
+func (c *Clipboard) Kill(text string, appendKill bool) error {
+	if !appendKill {
+		return c.Copy(text)
+	}
+	c.ring.AppendToLast(text)
+	return c.store(c.ring.Current())
+}
+
+// Yank returns the text to paste for an Emacs-style yank. Text copied by
+// other applications is added to the kill ring first.
+func (c *Clipboard) Yank() string {
+	text, _ := c.Paste()
+	if text != "" && text != c.ring.Current() {
+		c.ring.Push(text)
+	}
+	return c.ring.Current()
+}
+
+// YankPop returns the next older kill ring entry.
+func (c *Clipboard) YankPop() string {
+	return c.ring.Rotate()
+}
+
+// IsRegisterName reports whether r names a register (a-z or 0-9).
+// Uppercase letters name the same register as their lowercase forms.
+func IsRegisterName(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// SetRegister stores text in a named register. An uppercase name appends
+// to the register instead, as in vi.
+func (c *Clipboard) SetRegister(name rune, text string) {
+	if c.registers == nil {
+		c.registers = make(map[rune]string)
+	}
+	if name >= 'A' && name <= 'Z' {
+		name += 'a' - 'A'
+		c.registers[name] += text
+		return
+	}
+	c.registers[name] = text
+}
+
+// Register returns the text stored in a named register.
+func (c *Clipboard) Register(name rune) string {
+	if name >= 'A' && name <= 'Z' {
+		name += 'a' - 'A'
+	}
+	return c.registers[name]
+}
+
+// KillRing returns the clipboard's kill ring.
+func (c *Clipboard) KillRing() *KillRing {
+	return c.ring
+}
+
+// SetProvider selects how the system clipboard is reached
+func (c *Clipboard) SetProvider(p Provider) {
+	c.provider = p
+}
+
+// Provider returns how the system clipboard is reached
+func (c *Clipboard) Provider() Provider {
+	return c.provider
+}
+
+// SetOSC52Read allows asking the terminal for its clipboard contents.
+// Off by default: any program printing to the terminal can send the same
+// query, so some users would rather the terminal never answers.
+func (c *Clipboard) SetOSC52Read(enabled bool) {
+	c.osc52Read = enabled
+}
+
+// store places text on the system clipboard without touching the kill ring
+func (c *Clipboard) store(text string) error {
 	// Always store internally as a last resort
 	c.internal = text
+	c.localOnly = false
+
+	switch c.provider {
+	case ProviderInternal:
+		return nil
+	case ProviderNative:
+		return c.copyNative(text)
+	case ProviderOSC52:
+		return c.copyOSC52(text)
+	}
 
 	if c.isSSH {
 		// In SSH, always use OSC52
@@ -106,11 +268,33 @@ func (c *Clipboard) Copy(text string) error {
 	return c.copyOSC52(text)
 }
 
+// Append adds text to the end of the current clipboard contents.
+// The separator is inserted between the old and new text unless the
+// existing contents already end with it. An empty clipboard behaves like Copy.
+func (c *Clipboard) Append(text, separator string) error {
+	return c.Copy(c.Appended(text, separator))
+}
+
+// Appended returns what Append would leave on the clipboard, so the size of
+// the result can be checked before it is copied
+func (c *Clipboard) Appended(text, separator string) string {
+	existing, _ := c.Paste()
+	if existing == "" {
+		return text
+	}
+	if !strings.HasSuffix(existing, separator) {
+		existing += separator
+	}
+	return existing + text
+}
+
 // copyNative copies text using native clipboard tools
 func (c *Clipboard) copyNative(text string) error {
 	var cmd *exec.Cmd
 
-	switch c.tool {
+	switch c.nativeTool() {
+	case ToolPbcopy:
+		cmd = exec.Command("pbcopy")
 	case ToolXclip:
 		cmd = exec.Command("xclip", "-selection", "clipboard")
 	case ToolXsel:
@@ -126,16 +310,66 @@ func (c *Clipboard) copyNative(text string) error {
 }
 
 // copyOSC52 copies text using OSC52 escape sequence.
+// Inside tmux or screen the sequence is wrapped so it reaches the outer terminal.
 func (c *Clipboard) copyOSC52(text string) error {
 	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case os.Getenv("STY") != "":
+		seq = seq.Screen()
+	}
 	_, err := io.WriteString(c.output, seq.String())
 	return err
 }
 
+// usesOSC52 reports whether the terminal is the way to the system clipboard
+func (c *Clipboard) usesOSC52() bool {
+	switch c.provider {
+	case ProviderOSC52:
+		return true
+	case ProviderAuto:
+		return c.isSSH || c.nativeTool() == ToolNone
+	}
+	return false
+}
+
+// QueryOSC52 asks the terminal for its clipboard contents. The terminal
+// answers on the input stream, where the caller picks the reply up and
+// passes it to ReceiveOSC52. Returns false (and sends nothing) when OSC52
+// reads are disabled or native tools are used instead.
+func (c *Clipboard) QueryOSC52() bool {
+	if !c.osc52Read || !c.usesOSC52() {
+		return false
+	}
+	_, err := io.WriteString(c.output, osc52.Query().String())
+	return err == nil
+}
+
+// ReceiveOSC52 decodes a terminal's reply to QueryOSC52 and keeps the text
+// for the next Paste. reply is the sequence between the OSC introducer and
+// its terminator, e.g. "52;c;aGVsbG8=".
+func (c *Clipboard) ReceiveOSC52(reply string) (string, error) {
+	parts := strings.SplitN(reply, ";", 3)
+	if len(parts) != 3 || parts[0] != "52" {
+		return "", &ClipboardError{Message: "not an OSC52 reply"}
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", &ClipboardError{Message: "malformed OSC52 reply"}
+	}
+	c.internal = string(data)
+	return c.internal, nil
+}
+
 // Paste returns text from the clipboard.
-// Note: OSC52 paste (OSC52 query) is not widely supported.
-// We rely on native clipboard tools or the internal buffer.
+// Terminals answer OSC52 queries asynchronously (see QueryOSC52), so here
+// we rely on native clipboard tools or the internal buffer.
 func (c *Clipboard) Paste() (string, error) {
+	if c.provider == ProviderInternal || c.provider == ProviderOSC52 || c.localOnly {
+		return c.internal, nil
+	}
+
 	// Try native clipboard tool first
 	text, err := c.pasteNative()
 	if err == nil && text != "" {
@@ -150,7 +384,9 @@ func (c *Clipboard) Paste() (string, error) {
 func (c *Clipboard) pasteNative() (string, error) {
 	var cmd *exec.Cmd
 
-	switch c.tool {
+	switch c.nativeTool() {
+	case ToolPbcopy:
+		cmd = exec.Command("pbpaste")
 	case ToolXclip:
 		cmd = exec.Command("xclip", "-selection", "clipboard", "-o")
 	case ToolXsel:
@@ -168,16 +404,71 @@ func (c *Clipboard) pasteNative() (string, error) {
 	return string(output), nil
 }
 
-// HasContent returns true if there's content available to paste.
-func (c *Clipboard) HasContent() bool {
-	// Check native clipboard
-	text, err := c.pasteNative()
-	if err == nil && text != "" {
+// HasPrimary reports whether the system has a primary selection (X11 and
+// Wayland) that can be reached: one of their clipboard tools is installed
+// and the provider allows using it locally.
+func (c *Clipboard) HasPrimary() bool {
+	switch c.provider {
+	case ProviderInternal, ProviderOSC52:
+		return false
+	case ProviderAuto:
+		if c.isSSH {
+			return false
+		}
+	}
+	switch c.nativeTool() {
+	case ToolXclip, ToolXsel, ToolWlClipboard:
 		return true
 	}
+	return false
+}
 
-	// Check internal clipboard
-	return c.internal != ""
+// SetPrimary makes text the primary selection, the text middle-click
+// pastes. Unlike Copy it leaves the clipboard and kill ring alone.
+func (c *Clipboard) SetPrimary(text string) error {
+	c.primary = text
+	if !c.HasPrimary() {
+		return nil
+	}
+	var cmd *exec.Cmd
+	switch c.nativeTool() {
+	case ToolXclip:
+		cmd = exec.Command("xclip", "-selection", "primary")
+	case ToolXsel:
+		cmd = exec.Command("xsel", "--primary", "--input")
+	default:
+		cmd = exec.Command("wl-copy", "--primary")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// PastePrimary returns the primary selection, which another application
+// may have set since SetPrimary.
+func (c *Clipboard) PastePrimary() (string, error) {
+	if !c.HasPrimary() {
+		return c.primary, nil
+	}
+	var cmd *exec.Cmd
+	switch c.nativeTool() {
+	case ToolXclip:
+		cmd = exec.Command("xclip", "-selection", "primary", "-o")
+	case ToolXsel:
+		cmd = exec.Command("xsel", "--primary", "--output")
+	default:
+		cmd = exec.Command("wl-paste", "--primary", "-n")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return c.primary, nil
+	}
+	return string(output), nil
+}
+
+// HasContent returns true if there's content available to paste.
+func (c *Clipboard) HasContent() bool {
+	text, _ := c.Paste()
+	return text != ""
 }
 
 // Clear clears the internal clipboard.
@@ -190,20 +481,26 @@ func (c *Clipboard) IsSSH() bool {
 	return c.isSSH
 }
 
-// HasNativeClipboard returns true if a native clipboard tool is available.
+// HasNativeClipboard returns true if a native clipboard tool is available
+// and the provider allows using it.
 func (c *Clipboard) HasNativeClipboard() bool {
-	return c.tool != ToolNone
+	if c.provider == ProviderInternal || c.provider == ProviderOSC52 {
+		return false
+	}
+	return c.nativeTool() != ToolNone
 }
 
 // ToolName returns the name of the detected clipboard tool.
 func (c *Clipboard) ToolName() string {
-	switch c.tool {
+	switch c.nativeTool() {
 	case ToolXclip:
 		return "xclip"
 	case ToolXsel:
 		return "xsel"
 	case ToolWlClipboard:
 		return "wl-clipboard"
+	case ToolPbcopy:
+		return "pbcopy"
 	default:
 		return "none"
 	}
@@ -0,0 +1,116 @@
+package clipboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseProvider(t *testing.T) {
+	tests := map[string]Provider{
+		"":         ProviderAuto,
+		"auto":     ProviderAuto,
+		"native":   ProviderNative,
+		"OSC52":    ProviderOSC52,
+		"internal": ProviderInternal,
+		"off":      ProviderInternal,
+		"bogus":    ProviderAuto,
+	}
+	for name, want := range tests {
+		if got := ParseProvider(name); got != want {
+			t.Errorf("ParseProvider(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestInternalProviderStaysLocal(t *testing.T) {
+	var out bytes.Buffer
+	c := New(&out)
+	c.SetProvider(ProviderInternal)
+	c.SetOSC52Read(true)
+
+	if err := c.Copy("secret"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if c.QueryOSC52() {
+		t.Error("QueryOSC52() should not query with the internal provider")
+	}
+	if out.Len() != 0 {
+		t.Errorf("internal provider wrote %q to the terminal", out.String())
+	}
+	if text, _ := c.Paste(); text != "secret" {
+		t.Errorf("Paste() = %q, want %q", text, "secret")
+	}
+}
+
+func TestOSC52Provider(t *testing.T) {
+	var out bytes.Buffer
+	c := New(&out)
+	c.SetProvider(ProviderOSC52)
+	t.Setenv("TMUX", "")
+	t.Setenv("STY", "")
+
+	c.Copy("hello")
+	if got := out.String(); got != "\x1b]52;c;aGVsbG8=\x07" {
+		t.Errorf("Copy() wrote %q", got)
+	}
+
+	// Reads are off unless enabled
+	out.Reset()
+	if c.QueryOSC52() || out.Len() != 0 {
+		t.Error("QueryOSC52() sent a query with OSC52 reads disabled")
+	}
+	c.SetOSC52Read(true)
+	if !c.QueryOSC52() || !strings.Contains(out.String(), "52;c;?") {
+		t.Errorf("QueryOSC52() wrote %q, want a query", out.String())
+	}
+
+	text, err := c.ReceiveOSC52("52;c;d29ybGQ=")
+	if err != nil || text != "world" {
+		t.Fatalf("ReceiveOSC52() = %q, %v", text, err)
+	}
+	if pasted, _ := c.Paste(); pasted != "world" {
+		t.Errorf("Paste() after reply = %q, want %q", pasted, "world")
+	}
+	if _, err := c.ReceiveOSC52("11;rgb:0000/0000/0000"); err == nil {
+		t.Error("ReceiveOSC52() accepted a non-clipboard reply")
+	}
+}
+
+func TestCopyLocal(t *testing.T) {
+	var out bytes.Buffer
+	c := New(&out)
+	c.SetProvider(ProviderOSC52)
+
+	c.CopyLocal("huge")
+	if out.Len() != 0 {
+		t.Errorf("CopyLocal wrote %q to the terminal", out.String())
+	}
+	if text, _ := c.Paste(); text != "huge" {
+		t.Errorf("Paste() = %q, want the local copy", text)
+	}
+	if c.KillRing().Current() != "huge" {
+		t.Errorf("CopyLocal should add to the kill ring")
+	}
+}
+
+func TestPrimaryStaysApart(t *testing.T) {
+	var out bytes.Buffer
+	c := New(&out)
+	c.SetProvider(ProviderInternal)
+
+	if c.HasPrimary() {
+		t.Error("HasPrimary() should be false with the internal provider")
+	}
+	c.Copy("copied")
+	c.SetPrimary("selected")
+	if text, _ := c.PastePrimary(); text != "selected" {
+		t.Errorf("PastePrimary() = %q, want %q", text, "selected")
+	}
+	if text, _ := c.Paste(); text != "copied" {
+		t.Errorf("Paste() = %q, want the clipboard untouched by SetPrimary", text)
+	}
+	if c.KillRing().Len() != 1 {
+		t.Errorf("SetPrimary should not add to the kill ring")
+	}
+}
@@ -0,0 +1,83 @@
+package clipboard
+
+// DefaultKillRingSize is the number of entries kept in the kill ring
+const DefaultKillRingSize = 60
+
+// KillRing is an Emacs-style ring of killed (cut or copied) text.
+// The newest entry is at the end of the slice.
+type KillRing struct {
+	entries []string
+	max     int
+	yankIdx int // Index of the entry returned by the last Current/Rotate
+}
+
+// NewKillRing creates an empty kill ring holding up to max entries.
+func NewKillRing(max int) *KillRing {
+	if max <= 0 {
+		max = DefaultKillRingSize
+	}
+	return &KillRing{max: max}
+}
+
+// Push adds text as the newest entry, dropping the oldest if the ring is full.
+func (k *KillRing) Push(text string) {
+	if text == "" {
+		return
+	}
+	// Don't stack identical consecutive kills
+	if n := len(k.entries); n > 0 && k.entries[n-1] == text {
+		k.yankIdx = n - 1
+		return
+	}
+	k.entries = append(k.entries, text)
+	if len(k.entries) > k.max {
+		k.entries = k.entries[len(k.entries)-k.max:]
+	}
+	k.yankIdx = len(k.entries) - 1
+}
+
+// AppendToLast appends text to the newest entry (used for consecutive kills).
+func (k *KillRing) AppendToLast(text string) {
+	if len(k.entries) == 0 {
+		k.Push(text)
+		return
+	}
+	k.entries[len(k.entries)-1] += text
+	k.yankIdx = len(k.entries) - 1
+}
+
+// Current returns the newest entry and resets the yank position to it.
+func (k *KillRing) Current() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+	k.yankIdx = len(k.entries) - 1
+	return k.entries[k.yankIdx]
+}
+
+// Rotate moves the yank position to the next older entry (wrapping around)
+// and returns it. Used for yank-pop.
+func (k *KillRing) Rotate() string {
+	if len(k.entries) == 0 {
+		return ""
+	}
+	k.yankIdx--
+	if k.yankIdx < 0 {
+		k.yankIdx = len(k.entries) - 1
+	}
+	return k.entries[k.yankIdx]
+}
+
+// Len returns the number of entries in the ring.
+func (k *KillRing) Len() int {
+	return len(k.entries)
+}
+
+// Entries returns the ring's entries, newest first.
+func (k *KillRing) Entries() []string {
+	entries := make([]string, len(k.entries))
+	for i, text := range k.entries {
+		entries[len(k.entries)-1-i] = text
+	}
+	return entries
+}
@@ -0,0 +1,73 @@
+package clipboard
+
+import "testing"
+
+func TestKillRingPushAndRotate(t *testing.T) {
+	k := NewKillRing(3)
+	k.Push("a")
+	k.Push("b")
+	k.Push("c")
+
+	if got := k.Current(); got != "c" {
+		t.Errorf("Current() = %q, want 'c'", got)
+	}
+	if got := k.Rotate(); got != "b" {
+		t.Errorf("Rotate() = %q, want 'b'", got)
+	}
+	if got := k.Rotate(); got != "a" {
+		t.Errorf("Rotate() = %q, want 'a'", got)
+	}
+	if got := k.Rotate(); got != "c" {
+		t.Errorf("Rotate() should wrap to newest, got %q", got)
+	}
+}
+
+func TestKillRingMaxSize(t *testing.T) {
+	k := NewKillRing(2)
+	k.Push("a")
+	k.Push("b")
+	k.Push("c")
+
+	if k.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", k.Len())
+	}
+	k.Current()
+	if got := k.Rotate(); got != "b" {
+		t.Errorf("oldest entry should have been dropped, Rotate() = %q", got)
+	}
+}
+
+func TestKillRingAppendToLast(t *testing.T) {
+	k := NewKillRing(0)
+	k.AppendToLast("line1\n")
+	k.AppendToLast("line2\n")
+
+	if k.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", k.Len())
+	}
+	if got := k.Current(); got != "line1\nline2\n" {
+		t.Errorf("Current() = %q", got)
+	}
+}
+
+func TestKillRingSkipsDuplicates(t *testing.T) {
+	k := NewKillRing(0)
+	k.Push("x")
+	k.Push("x")
+	k.Push("")
+
+	if k.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", k.Len())
+	}
+}
+
+func TestKillRingEntries(t *testing.T) {
+	k := NewKillRing(3)
+	for _, s := range []string{"a", "b", "c", "d"} {
+		k.Push(s)
+	}
+	got := k.Entries()
+	if len(got) != 3 || got[0] != "d" || got[2] != "b" {
+		t.Errorf("Entries() = %v, want [d c b]", got)
+	}
+}
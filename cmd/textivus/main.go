@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/cornish/textivus-editor/config"
 	"github.com/cornish/textivus-editor/editor"
@@ -15,12 +17,30 @@ const version = "0.2.0"
 func main() {
 	// Parse command line arguments
 	args := os.Args[1:]
-	var filename string
+	var filename, gotoTarget string
 	asciiMode := false
+	profileStartup := false
+	debugLog := false
+	endOfFlags := false
 
 	// Handle flags
 	for _, arg := range args {
+		if endOfFlags {
+			if filename == "" {
+				filename = arg
+			}
+			continue
+		}
+		if target, ok := lineArg(arg); ok {
+			gotoTarget = target
+			continue
+		}
 		switch arg {
+		case "--":
+			endOfFlags = true // Everything after is a file name, even "-x" or "+x"
+		case "--wait", "-w":
+			// Textivus runs in the foreground and always waits; accepted for
+			// tools configured for GUI editors
 		case "--version", "-v":
 			fmt.Printf("textivus %s\n", version)
 			os.Exit(0)
@@ -29,6 +49,10 @@ func main() {
 			os.Exit(0)
 		case "--ascii":
 			asciiMode = true
+		case "--startup-profile":
+			profileStartup = true
+		case "--debug":
+			debugLog = true
 		default:
 			if filename == "" && !isFlag(arg) {
 				filename = arg
@@ -36,14 +60,32 @@ func main() {
 		}
 	}
 
+	profile := newStartupProfile(profileStartup)
+
 	// Detect terminal capabilities early
 	config.InitCapabilities()
+	profile.mark("capabilities")
+
+	// A dumb terminal can't position the cursor, so the full-screen UI would be garbage
+	if config.GetCapabilities().DumbTerminal {
+		fmt.Fprintln(os.Stderr, "textivus: TERM=dumb cannot display a full-screen editor.")
+		fmt.Fprintln(os.Stderr, "Run it from a terminal emulator, or set TERM (e.g. TERM=xterm-256color).")
+		os.Exit(1)
+	}
 
 	// Migrate config from old location if needed
 	config.MigrateConfig()
+	profile.mark("config migration")
 
 	// Load configuration
 	cfg, configErr := config.Load()
+	profile.mark("config load")
+
+	// Only pay for the terminal round trip when light/dark themes are set
+	if cfg.Theme.FollowsBackground() {
+		config.ProbeBackground()
+		profile.mark("background probe")
+	}
 
 	// Command-line --ascii overrides config
 	if asciiMode {
@@ -53,6 +95,17 @@ func main() {
 
 	// Create editor with config
 	e := editor.NewWithConfig(cfg)
+	profile.mark("editor setup")
+	if debugLog {
+		path, err := config.DebugLogPath()
+		if err == nil {
+			err = e.EnableDebugLog(path)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "textivus: --debug: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// If config had parse errors, show error dialog on startup
 	if configErr != nil {
@@ -76,14 +129,48 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error accessing file: %v\n", err)
 			os.Exit(1)
 		}
+		e.MarkCommandLineFile()
+		profile.mark("file load")
+	}
+	if gotoTarget != "" {
+		if err := e.GoTo(gotoTarget); err != nil {
+			fmt.Fprintf(os.Stderr, "textivus: +%s: %v\n", gotoTarget, err)
+			os.Exit(1)
+		}
+	}
+	if profileStartup {
+		e.OnFirstRender(func() { profile.mark("first frame") })
 	}
 
 	// Create and run the Bubbletea program
-	p := tea.NewProgram(e, tea.WithAltScreen(), tea.WithMouseAllMotion())
+	// Focus reports let the editor pause background work while unfocused
+	p := tea.NewProgram(e, tea.WithAltScreen(), tea.WithMouseAllMotion(), tea.WithReportFocus())
 	if _, err := p.Run(); err != nil {
+		// A panic in a background command skips the editor's own recovery
+		if errors.Is(err, tea.ErrProgramPanic) {
+			e.EmergencySave()
+		}
 		fmt.Fprintf(os.Stderr, "Error running editor: %v\n", err)
+		fmt.Fprint(os.Stderr, e.EmergencyReport())
 		os.Exit(1)
 	}
+	profile.report(os.Stderr)
+
+	// As $EDITOR, discarding changes to the file is an abort; a non-zero
+	// status lets git, crontab and visudo cancel instead of using the file
+	if filename != "" && e.Aborted() && cfg.Editor.AbortExitCode != 0 {
+		os.Exit(cfg.Editor.AbortExitCode)
+	}
+}
+
+// lineArg parses the "+line" and "+line:col" arguments editors accept from
+// tools like git and less, returning the Go to Line target
+func lineArg(arg string) (string, bool) {
+	target, ok := strings.CutPrefix(arg, "+")
+	if !ok || target == "" || target[0] < '0' || target[0] > '9' {
+		return "", false
+	}
+	return target, true
 }
 
 func isFlag(s string) bool {
@@ -93,12 +180,22 @@ func isFlag(s string) bool {
 func printHelp() {
 	fmt.Println("Textivus - A Text Editor for the Rest of Us")
 	fmt.Println()
-	fmt.Println("Usage: textivus [options] [file]")
+	fmt.Println("Usage: textivus [options] [+line[:col]] [file]")
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -h, --help     Show this help message")
 	fmt.Println("  -v, --version  Show version information")
+	fmt.Println("  -w, --wait     Accepted for $EDITOR use; textivus always waits")
 	fmt.Println("  --ascii        Use ASCII characters for dialogs")
+	fmt.Println("  --startup-profile")
+	fmt.Println("                 Print startup phase timings on exit")
+	fmt.Println("  --debug        Log keys, mode changes, slow renders and errors to")
+	fmt.Println("                 debug.log in the config directory (Help > View Log)")
+	fmt.Println("  +N, +N:C       Start at line N (and column C)")
+	fmt.Println("  --             Treat the next argument as a file name")
+	fmt.Println()
+	fmt.Println("As $EDITOR, discarding unsaved changes exits with status 1 so git,")
+	fmt.Println("crontab and visudo abort (set abort_exit_code = 0 to disable).")
 	fmt.Println()
 	fmt.Println("Keyboard Shortcuts:")
 	fmt.Println("  Ctrl+N         New file")
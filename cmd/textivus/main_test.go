@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestLineArg(t *testing.T) {
+	tests := []struct {
+		arg    string
+		target string
+		ok     bool
+	}{
+		{"+42", "42", true},
+		{"+42:7", "42:7", true},
+		{"+", "", false},
+		{"+notes", "", false},
+		{"notes.txt", "", false},
+		{"-42", "", false},
+	}
+	for _, tt := range tests {
+		target, ok := lineArg(tt.arg)
+		if target != tt.target || ok != tt.ok {
+			t.Errorf("lineArg(%q) = %q, %v, want %q, %v", tt.arg, target, ok, tt.target, tt.ok)
+		}
+	}
+}
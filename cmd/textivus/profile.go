@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// startupPhase is one timed step of startup
+type startupPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// startupProfile records how long each startup phase takes (--startup-profile)
+type startupProfile struct {
+	enabled bool
+	start   time.Time
+	last    time.Time
+	phases  []startupPhase
+}
+
+// newStartupProfile starts timing from now; a disabled profile records nothing
+func newStartupProfile(enabled bool) *startupProfile {
+	now := time.Now()
+	return &startupProfile{enabled: enabled, start: now, last: now}
+}
+
+// mark ends the current phase under the given name
+func (p *startupProfile) mark(name string) {
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	p.phases = append(p.phases, startupPhase{name: name, duration: now.Sub(p.last)})
+	p.last = now
+}
+
+// report writes the per-phase breakdown
+func (p *startupProfile) report(w io.Writer) {
+	if !p.enabled {
+		return
+	}
+	fmt.Fprintln(w, "Startup profile:")
+	for _, phase := range p.phases {
+		fmt.Fprintf(w, "  %-20s %8.2f ms\n", phase.name, ms(phase.duration))
+	}
+	fmt.Fprintf(w, "  %-20s %8.2f ms\n", "total", ms(p.last.Sub(p.start)))
+}
+
+// ms converts a duration to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
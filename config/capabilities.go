@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strings"
+
+	"github.com/muesli/termenv"
 )
 
 // ColorMode represents the terminal color capability
@@ -14,11 +16,26 @@ const (
 	ColorTrueColor                  // 24-bit true color
 )
 
+// Background is the brightness of the terminal's background color
+type Background int
+
+const (
+	BackgroundUnknown Background = iota // Not probed or the terminal didn't answer
+	BackgroundDark
+	BackgroundLight
+)
+
 // TermCapabilities holds detected terminal capabilities
 type TermCapabilities struct {
-	UTF8Support   bool      // Terminal supports UTF-8
-	ColorMode     ColorMode // Color capability level
-	KittyGraphics bool      // Kitty graphics protocol support
+	UTF8Support   bool       // Terminal supports UTF-8
+	ColorMode     ColorMode  // Color capability level
+	KittyGraphics bool       // Kitty graphics protocol support
+	SixelGraphics bool       // Sixel graphics support
+	ITermImages   bool       // iTerm2 inline images protocol support
+	CellWidth     int        // Pixel width of a character cell, 0 if unknown
+	CellHeight    int        // Pixel height of a character cell, 0 if unknown
+	DumbTerminal  bool       // TERM=dumb: no cursor addressing, full-screen UI is impossible
+	Background    Background // Terminal background brightness (see ProbeBackground)
 }
 
 // String returns a human-readable description of the color mode
@@ -41,7 +58,11 @@ func DetectCapabilities() *TermCapabilities {
 		UTF8Support:   detectUTF8Support(),
 		ColorMode:     detectColorMode(),
 		KittyGraphics: detectKittyGraphics(),
+		SixelGraphics: detectSixelGraphics(),
+		ITermImages:   detectITermImages(),
+		DumbTerminal:  strings.ToLower(os.Getenv("TERM")) == "dumb",
 	}
+	caps.CellWidth, caps.CellHeight = cellPixelSize()
 	return caps
 }
 
@@ -96,6 +117,57 @@ func detectKittyGraphics() bool {
 	return os.Getenv("KITTY_WINDOW_ID") != ""
 }
 
+// detectSixelGraphics checks for terminals known to draw Sixel images.
+// xterm only does when started as a VT340 (xterm -ti vt340), which it
+// doesn't advertise, so it has to be chosen with minimap_graphics.
+func detectSixelGraphics() bool {
+	term := strings.ToLower(os.Getenv("TERM"))
+	for _, prefix := range []string{"foot", "mlterm", "contour", "yaft"} {
+		if strings.HasPrefix(term, prefix) {
+			return true
+		}
+	}
+	switch strings.ToLower(os.Getenv("TERM_PROGRAM")) {
+	case "wezterm", "mlterm", "contour":
+		return true
+	}
+	return os.Getenv("MLTERM") != ""
+}
+
+// detectITermImages checks for iTerm2. LC_TERMINAL is passed along by ssh,
+// so it also finds iTerm2 on the far side of a connection.
+func detectITermImages() bool {
+	return os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("LC_TERMINAL") == "iTerm2"
+}
+
+// Minimap graphics modes, as chosen by MinimapGraphics
+const (
+	GraphicsKitty   = "kitty"
+	GraphicsSixel   = "sixel"
+	GraphicsITerm   = "iterm"
+	GraphicsBraille = "braille"
+)
+
+// MinimapGraphics returns how to draw the minimap: Kitty graphics, then
+// iTerm2 inline images, then Sixel, then braille text, whichever the
+// terminal supports first.
+// override is the minimap_graphics setting; "" or "auto" detects.
+func (c *TermCapabilities) MinimapGraphics(override string) string {
+	switch override {
+	case GraphicsKitty, GraphicsSixel, GraphicsITerm, GraphicsBraille:
+		return override
+	}
+	switch {
+	case c.KittyGraphics:
+		return GraphicsKitty
+	case c.ITermImages:
+		return GraphicsITerm
+	case c.SixelGraphics:
+		return GraphicsSixel
+	}
+	return GraphicsBraille
+}
+
 // ShouldUseASCII returns true if ASCII mode should be used based on capabilities
 // Takes into account both auto-detection and user override
 func (c *TermCapabilities) ShouldUseASCII(override *bool) bool {
@@ -123,6 +195,23 @@ func InitCapabilities() {
 	GlobalCapabilities = DetectCapabilities()
 }
 
+// ProbeBackground asks the terminal for its background color (OSC 11) and
+// records whether it is light or dark. It waits for a terminal round trip,
+// so it is only called when light/dark themes are configured.
+func ProbeBackground() {
+	caps := GetCapabilities()
+	out := termenv.NewOutput(os.Stdout)
+	if out.TTY() == nil || caps.DumbTerminal {
+		return
+	}
+	// termenv falls back to COLORFGBG, then to black, if the terminal stays quiet
+	if out.HasDarkBackground() {
+		caps.Background = BackgroundDark
+	} else {
+		caps.Background = BackgroundLight
+	}
+}
+
 // GetCapabilities returns the global capabilities, detecting if needed
 func GetCapabilities() *TermCapabilities {
 	if GlobalCapabilities == nil {
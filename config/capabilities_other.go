@@ -0,0 +1,8 @@
+//go:build !unix
+
+package config
+
+// cellPixelSize is unknown off Unix; callers assume a typical cell size
+func cellPixelSize() (width, height int) {
+	return 0, 0
+}
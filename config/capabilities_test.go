@@ -117,3 +117,66 @@ func TestDetectCapabilities(t *testing.T) {
 		t.Errorf("DetectCapabilities().ColorMode = %d, out of valid range", caps.ColorMode)
 	}
 }
+
+func TestDetectDumbTerminal(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	if !DetectCapabilities().DumbTerminal {
+		t.Errorf("TERM=dumb should be detected")
+	}
+	t.Setenv("TERM", "xterm-256color")
+	if DetectCapabilities().DumbTerminal {
+		t.Errorf("xterm-256color is not a dumb terminal")
+	}
+}
+
+func TestMinimapGraphics(t *testing.T) {
+	tests := []struct {
+		kitty, iterm, sixel bool
+		override            string
+		want                string
+	}{
+		{true, true, true, "", GraphicsKitty},
+		{false, true, true, "", GraphicsITerm},
+		{false, false, true, "iterm", GraphicsITerm},
+		{false, false, true, "auto", GraphicsSixel},
+		{false, false, false, "", GraphicsBraille},
+		{false, false, false, "sixel", GraphicsSixel},
+		{true, false, false, "braille", GraphicsBraille},
+		{false, false, true, "bogus", GraphicsSixel},
+	}
+	for _, tt := range tests {
+		caps := &TermCapabilities{KittyGraphics: tt.kitty, ITermImages: tt.iterm, SixelGraphics: tt.sixel}
+		if got := caps.MinimapGraphics(tt.override); got != tt.want {
+			t.Errorf("kitty=%v iterm=%v sixel=%v override %q: got %q, want %q", tt.kitty, tt.iterm, tt.sixel, tt.override, got, tt.want)
+		}
+	}
+}
+
+func TestDetectSixelGraphics(t *testing.T) {
+	t.Setenv("TERM_PROGRAM", "")
+	t.Setenv("MLTERM", "")
+	t.Setenv("TERM", "foot-extra")
+	if !detectSixelGraphics() {
+		t.Error("foot should be detected as drawing Sixel images")
+	}
+	t.Setenv("TERM", "xterm-256color")
+	if detectSixelGraphics() {
+		t.Error("plain xterm can't be assumed to draw Sixel images")
+	}
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	if !detectSixelGraphics() {
+		t.Error("WezTerm should be detected as drawing Sixel images")
+	}
+}
+
+func TestDetectITermImages(t *testing.T) {
+	t.Setenv("TERM_PROGRAM", "")
+	t.Setenv("LC_TERMINAL", "iTerm2")
+	if !detectITermImages() {
+		t.Error("LC_TERMINAL=iTerm2 should be detected, e.g. over ssh")
+	}
+	t.Setenv("LC_TERMINAL", "")
+	if detectITermImages() {
+		t.Error("no iTerm2 variables should mean no inline images")
+	}
+}
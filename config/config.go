@@ -4,6 +4,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -116,6 +119,13 @@ type Config struct {
 	RecentDirs    []string     `toml:"recent_dirs,omitempty"`    // Recently visited directories (max 10)
 	FavoriteFiles []string     `toml:"favorite_files,omitempty"` // User-favorited files (max 50)
 	FavoriteDirs  []string     `toml:"favorite_dirs,omitempty"`  // User-favorited directories (max 50)
+	TrustedDirs   []string     `toml:"trusted_dirs,omitempty"`   // Projects allowed to run their own commands
+
+	FindHistory    []string `toml:"find_history,omitempty"`    // Past find queries, newest first (with persist_history)
+	ReplaceHistory []string `toml:"replace_history,omitempty"` // Past replacements, newest first
+	GotoHistory    []string `toml:"goto_history,omitempty"`    // Past go-to-line targets, newest first
+
+	FileTypes map[string]FileTypeConfig `toml:"filetypes,omitempty"` // Overrides keyed by extension ("py") or file name ("Makefile")
 }
 
 // MaxRecentFiles is the maximum number of recent files to track
@@ -124,6 +134,9 @@ const MaxRecentFiles = 10
 // MaxRecentDirs is the maximum number of recent directories to track
 const MaxRecentDirs = 10
 
+// MaxHistory is the maximum number of entries kept per input history
+const MaxHistory = 50
+
 // MaxFavorites is the maximum number of favorite files or directories
 const MaxFavorites = 50
 
@@ -285,6 +298,33 @@ func (c *Config) IsFavoriteDir(path string) bool {
 	return false
 }
 
+// TrustDir records a project directory as trusted to run its own commands
+func (c *Config) TrustDir(path string) {
+	if c.IsTrustedDir(path) {
+		return
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	c.TrustedDirs = append(c.TrustedDirs, absPath)
+}
+
+// IsTrustedDir checks if a directory, or one of its parents, has been trusted
+func (c *Config) IsTrustedDir(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	for _, d := range c.TrustedDirs {
+		if absPath == d || strings.HasPrefix(absPath, strings.TrimSuffix(d, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
 // ToggleFavorite toggles favorite status for a file or directory
 // Returns (isNowFavorite, wasChanged)
 func (c *Config) ToggleFavorite(path string, isDir bool) (bool, bool) {
@@ -302,35 +342,234 @@ func (c *Config) ToggleFavorite(path string, isDir bool) (bool, bool) {
 
 // EditorConfig holds editor-specific settings
 type EditorConfig struct {
-	WordWrap        bool  `toml:"word_wrap"`
-	LineNumbers     bool  `toml:"line_numbers"`
-	SyntaxHighlight bool  `toml:"syntax_highlight"`
-	TrueColor       *bool `toml:"true_color"`     // nil = auto (true), false = force 256-color
-	AsciiMode       *bool `toml:"ascii_mode"`     // nil = auto-detect, true/false = override
-	BackupCount     int   `toml:"backup_count"`   // 0=disabled, 1=filename~, >1=filename~1~ through filename~N~
-	Scrollbar       bool  `toml:"scrollbar"`      // Show scrollbar
-	Minimap         bool  `toml:"minimap"`        // Show minimap
-	MaxBuffers      int   `toml:"max_buffers"`    // Maximum open buffers (0=unlimited, default 20)
-	TabWidth        int   `toml:"tab_width"`      // Display width of tabs (default 4)
-	TabsToSpaces    bool  `toml:"tabs_to_spaces"` // Insert spaces instead of tab characters
+	WordWrap        bool   `toml:"word_wrap"`
+	LineNumbers     bool   `toml:"line_numbers"`
+	SyntaxHighlight bool   `toml:"syntax_highlight"`
+	TrueColor       *bool  `toml:"true_color"`      // nil = auto (true), false = force 256-color
+	AsciiMode       *bool  `toml:"ascii_mode"`      // nil = auto-detect, true/false = override
+	BackupCount     int    `toml:"backup_count"`    // 0=disabled, 1=filename~, >1=filename~1~ through filename~N~
+	BackupDir       string `toml:"backup_dir"`      // Directory to keep backups in, named after each file and its path ("" = beside the file)
+	Scrollbar       bool   `toml:"scrollbar"`       // Show scrollbar
+	Minimap         bool   `toml:"minimap"`         // Show minimap
+	FileTree        bool   `toml:"file_tree"`       // Show the directory tree sidebar
+	MinimapHeatmap  string `toml:"minimap_heatmap"` // Minimap tint: "off", "length" or "recency"
+
+	MinimapMovesCursor    bool   `toml:"minimap_moves_cursor"`    // Clicking the minimap moves the cursor, not just the view
+	MinimapGraphics       string `toml:"minimap_graphics"`        // Minimap drawing: "auto", "kitty", "iterm", "sixel" or "braille"
+	MaxBuffers            int    `toml:"max_buffers"`             // Maximum open buffers (0=unlimited, default 20)
+	TabWidth              int    `toml:"tab_width"`               // Columns between tab stops (default 4)
+	TabsToSpaces          bool   `toml:"tabs_to_spaces"`          // Insert spaces instead of tab characters
+	DetectIndent          bool   `toml:"detect_indent"`           // Use the indentation a file already has instead of the two above
+	UndoMemoryMB          int    `toml:"undo_memory_mb"`          // Undo history budget per buffer in MB (0=unlimited, default 64)
+	UndoCheckpointMinutes int    `toml:"undo_checkpoint_minutes"` // Minutes between automatic undo checkpoints (0=none, default 5)
+	ScrollLines           int    `toml:"scroll_lines"`            // Lines scrolled per mouse wheel tick (default 3)
+	ScrollOff             int    `toml:"scroll_off"`              // Lines of context kept above and below the cursor (default 0)
+
+	KeybindingProfile string `toml:"keybinding_profile"` // "default", "vi" or "emacs"
+	UnsavedReminder   int    `toml:"unsaved_reminder"`   // Minutes a buffer may stay modified before a reminder (0=disabled)
+	ReminderAutosave  bool   `toml:"reminder_autosave"`  // Auto-save named files instead of just reminding
+	AmbiguousWidth    string `toml:"ambiguous_width"`    // East Asian ambiguous chars: "auto", "narrow" or "wide"
+	Rulers            []int  `toml:"rulers,omitempty"`   // Columns to draw vertical rulers at
+	ScrollMarkers     bool   `toml:"scroll_markers"`     // Mark line ends scrolled out of view when word wrap is off
+	StatusLineLength  bool   `toml:"status_line_length"` // Show the cursor line's length in columns in the status bar
+	AutoPair          bool   `toml:"auto_pair"`          // Insert closing brackets and quotes as you type
+	HighlightWord     bool   `toml:"highlight_word"`     // Highlight other occurrences of the word under the cursor
+	OpenSummary       bool   `toml:"open_summary"`       // Show encoding, line endings and indentation after opening a file
+	AbortExitCode     int    `toml:"abort_exit_code"`    // Exit status when unsaved changes to the command-line file are discarded (0=success, default 1)
+	TitleFormat       string `toml:"title_format"`       // Terminal title with {path}, {basename}, {dir} and {modified} ("*" when unsaved)
+
+	SearchIgnoreCase bool `toml:"search_ignore_case"` // Find matches regardless of case
+	SearchWholeWord  bool `toml:"search_whole_word"`  // Find only matches that are whole words
+	SearchWrap       bool `toml:"search_wrap"`        // Continue from the top after the last match
+	PersistHistory   bool `toml:"persist_history"`    // Keep find, replace and go-to history between sessions
+
+	StripSoftHyphens bool `toml:"strip_soft_hyphens"` // Remove U+00AD soft hyphens on save
+	StripZeroWidth   bool `toml:"strip_zero_width"`   // Remove zero-width spaces, joiners and word joiners on save
+	StripStrayBOM    bool `toml:"strip_stray_bom"`    // Remove U+FEFF inside the text on save (the encoding BOM is kept)
+	FlagInvisibles   bool `toml:"flag_invisibles"`    // Mark zero-width, bidi control, non-breaking space and decomposed characters
+
+	Clipboard          string `toml:"clipboard"`            // "auto", "native", "osc52" or "internal" (never touch the system clipboard)
+	ClipboardOSC52Read bool   `toml:"clipboard_osc52_read"` // Ask the terminal for its clipboard on paste (OSC52 query)
+	ClipboardMaxMB     int    `toml:"clipboard_max_mb"`     // Ask before copying selections larger than this (0=never ask, default 16)
+	PrimarySelection   bool   `toml:"primary_selection"`    // Selecting sets the X11/Wayland primary selection; middle-click pastes it
+
+	BrowserShowHidden bool   `toml:"browser_show_hidden"` // List dotfiles in the file browser and Save As
+	BrowserSort       string `toml:"browser_sort"`        // File browser order: "name", "size" or "modified"
+	BrowserSortDesc   bool   `toml:"browser_sort_desc"`   // Reverse the file browser order
+
+	PrintCommand   string `toml:"print_command"`    // Shell command File > Print pipes the text to (default "lpr")
+	PrintHeader    bool   `toml:"print_header"`     // Start each printed page with the file name and page number
+	PrintPageLines int    `toml:"print_page_lines"` // Lines per printed page, header included (default 66)
+
+	InsertDateFormats []string `toml:"insert_date_formats"` // Go time layouts offered by Edit > Insert Text
+
+	LocalHistory     bool `toml:"local_history"`      // Keep a timestamped copy of each save for File > Local History
+	LocalHistoryMax  int  `toml:"local_history_max"`  // Snapshots kept per file (0=no limit, default 50)
+	LocalHistoryDays int  `toml:"local_history_days"` // Days snapshots are kept (0=no limit, default 30)
+
+	AutoReload bool `toml:"auto_reload"` // Reload unmodified buffers whose files change on disk
+
+	AutosaveOnFocusLoss bool `toml:"autosave_on_focus_loss"` // Save modified buffers when the terminal loses focus
+	AutosaveOnSwitch    bool `toml:"autosave_on_switch"`     // Save a modified buffer when switching away from it
+}
+
+// FileTypeConfig overrides editor settings for one file type.
+// Unset fields fall back to the [editor] values.
+type FileTypeConfig struct {
+	TabWidth     *int  `toml:"tab_width"`
+	TabsToSpaces *bool `toml:"tabs_to_spaces"`
+	WordWrap     *bool `toml:"word_wrap"`
+	Rulers       []int `toml:"rulers"`
+}
+
+// FileSettings are the effective per-file editing settings
+type FileSettings struct {
+	TabWidth     int
+	TabsToSpaces bool
+	WordWrap     *bool // nil = follow the global word wrap toggle
+	Rulers       []int
+}
+
+// FileSettings resolves the settings for a file, applying any [filetypes]
+// override matching its base name or extension
+func (c *Config) FileSettings(filename string) FileSettings {
+	fs := FileSettings{
+		TabWidth:     c.Editor.TabWidth,
+		TabsToSpaces: c.Editor.TabsToSpaces,
+		Rulers:       c.Editor.Rulers,
+	}
+	if ft, ok := c.fileType(filename); ok {
+		if ft.TabWidth != nil && *ft.TabWidth > 0 {
+			fs.TabWidth = *ft.TabWidth
+		}
+		if ft.TabsToSpaces != nil {
+			fs.TabsToSpaces = *ft.TabsToSpaces
+		}
+		if ft.WordWrap != nil {
+			fs.WordWrap = ft.WordWrap
+		}
+		if ft.Rulers != nil {
+			fs.Rulers = ft.Rulers
+		}
+	}
+	if fs.TabWidth <= 0 {
+		fs.TabWidth = 4
+	}
+	if len(fs.Rulers) > 0 {
+		fs.Rulers = append([]int(nil), fs.Rulers...)
+		sort.Ints(fs.Rulers)
+	}
+	return fs
+}
+
+// fileType finds the override for a file: exact base name first, then extension
+func (c *Config) fileType(filename string) (FileTypeConfig, bool) {
+	if filename == "" || len(c.FileTypes) == 0 {
+		return FileTypeConfig{}, false
+	}
+	base := filepath.Base(filename)
+	if ft, ok := c.FileTypes[base]; ok {
+		return ft, true
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(base), "."))
+	if ext == "" {
+		return FileTypeConfig{}, false
+	}
+	for key, ft := range c.FileTypes {
+		if strings.ToLower(strings.TrimPrefix(key, ".")) == ext {
+			return ft, true
+		}
+	}
+	return FileTypeConfig{}, false
 }
 
 // ThemeConfig holds the theme reference in the main config
 // Just references a theme by name - the actual colors come from theme files
 type ThemeConfig struct {
-	Name string `toml:"name"` // Theme name (built-in or from themes/ directory)
+	Name  string `toml:"name"`            // Theme name (built-in or from themes/ directory)
+	Light string `toml:"light,omitempty"` // Theme used when the terminal background is light
+	Dark  string `toml:"dark,omitempty"`  // Theme used when the terminal background is dark
+}
+
+// FollowsBackground returns true if light or dark themes are configured
+func (t *ThemeConfig) FollowsBackground() bool {
+	return t.Light != "" || t.Dark != ""
 }
 
+// ActiveName returns the theme to use for the detected terminal background,
+// falling back to Name when the background is unknown or has no theme set
+func (t *ThemeConfig) ActiveName() string {
+	switch GetCapabilities().Background {
+	case BackgroundDark:
+		if t.Dark != "" {
+			return t.Dark
+		}
+	case BackgroundLight:
+		if t.Light != "" {
+			return t.Light
+		}
+	}
+	return t.Name
+}
+
+// SetActive records a theme choice in the slot ActiveName reads from,
+// so picking a theme on a light terminal doesn't replace the dark one
+func (t *ThemeConfig) SetActive(name string) {
+	switch GetCapabilities().Background {
+	case BackgroundDark:
+		if t.Dark != "" {
+			t.Dark = name
+			return
+		}
+	case BackgroundLight:
+		if t.Light != "" {
+			t.Light = name
+			return
+		}
+	}
+	t.Name = name
+}
+
+// DefaultTitleFormat is the terminal title used when title_format is empty
+const DefaultTitleFormat = "textivus - {path}{modified}"
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Editor: EditorConfig{
-			WordWrap:        false,
-			LineNumbers:     false,
-			SyntaxHighlight: true,  // Enabled by default
-			MaxBuffers:      20,    // Default max open buffers
-			TabWidth:        4,     // Default tab width
-			TabsToSpaces:    false, // Use real tabs by default
+			WordWrap:              false,
+			LineNumbers:           false,
+			SyntaxHighlight:       true,  // Enabled by default
+			MaxBuffers:            20,    // Default max open buffers
+			TabWidth:              4,     // Default tab width
+			TabsToSpaces:          false, // Use real tabs by default
+			UndoMemoryMB:          64,    // Oldest undo steps are dropped past this
+			UndoCheckpointMinutes: 5,
+			ScrollLines:           3,
+			MinimapHeatmap:        "off",
+			MinimapGraphics:       "auto",
+
+			KeybindingProfile: ProfileDefault,
+			AmbiguousWidth:    "auto", // Follow the locale
+			Clipboard:         "auto", // Native tools locally, OSC52 over SSH
+			ClipboardMaxMB:    16,
+			HighlightWord:     true,
+			ScrollMarkers:     true,
+			DetectIndent:      true,
+			FlagInvisibles:    true,
+			OpenSummary:       true,
+			AbortExitCode:     1, // Lets git, crontab and visudo tell an abort from a save
+			TitleFormat:       DefaultTitleFormat,
+			SearchWrap:        true,
+			BrowserShowHidden: true,
+			BrowserSort:       "name",
+			PrintCommand:      "lpr",
+			PrintHeader:       true,
+			PrintPageLines:    66, // A US Letter page at 6 lines per inch
+			InsertDateFormats: []string{"2006-01-02", "15:04", "2006-01-02 15:04", "Monday, January 2, 2006", time.RFC3339},
+			LocalHistoryMax:   50,
+			LocalHistoryDays:  30,
+			AutoReload:        true,
 		},
 		Theme: ThemeConfig{
 			Name: "default",
@@ -365,6 +604,43 @@ func ThemesDir() (string, error) {
 	return filepath.Join(configDir, configDirName, "themes"), nil
 }
 
+// AnnotationsPath returns the path to the file holding line annotations
+func AnnotationsPath() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "annotations.toml"), nil
+}
+
+// LocalHistoryDir returns the directory holding the snapshots kept by
+// local history, one folder per file
+func LocalHistoryDir() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "local-history"), nil
+}
+
+// DebugLogPath returns the path to the file --debug writes its log to
+func DebugLogPath() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "debug.log"), nil
+}
+
+// SnippetsPath returns the path to the file holding user snippets
+func SnippetsPath() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "snippets.toml"), nil
+}
+
 // ConfigLoadError holds details about a config loading error
 type ConfigLoadError struct {
 	FilePath string
@@ -429,5 +705,5 @@ func (c *Config) Save() error {
 
 // GetResolved loads and returns the complete theme
 func (t *ThemeConfig) GetResolved() Theme {
-	return LoadTheme(t.Name)
+	return LoadTheme(t.ActiveName())
 }
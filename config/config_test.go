@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/BurntSushi/toml"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -270,6 +272,48 @@ func TestThemesDir(t *testing.T) {
 	}
 }
 
+func TestFileSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Editor.TabsToSpaces = true
+	cfg.Editor.Rulers = []int{100}
+	data := `
+[filetypes.Makefile]
+tabs_to_spaces = false
+
+[filetypes.py]
+tab_width = 4
+word_wrap = true
+rulers = [88, 79]
+`
+	if _, err := toml.Decode(data, cfg); err != nil {
+		t.Fatalf("decode filetypes: %v", err)
+	}
+
+	mk := cfg.FileSettings("/src/Makefile")
+	if mk.TabsToSpaces || mk.WordWrap != nil {
+		t.Errorf("Makefile settings = %+v, want hard tabs and global wrap", mk)
+	}
+	if len(mk.Rulers) != 1 || mk.Rulers[0] != 100 {
+		t.Errorf("Makefile rulers = %v, want global [100]", mk.Rulers)
+	}
+
+	py := cfg.FileSettings("script.PY")
+	if !py.TabsToSpaces || py.TabWidth != 4 {
+		t.Errorf("py settings = %+v, want 4 spaces", py)
+	}
+	if py.WordWrap == nil || !*py.WordWrap {
+		t.Errorf("py word wrap override not applied")
+	}
+	if len(py.Rulers) != 2 || py.Rulers[0] != 79 || py.Rulers[1] != 88 {
+		t.Errorf("py rulers = %v, want sorted [79 88]", py.Rulers)
+	}
+
+	other := cfg.FileSettings("notes.txt")
+	if !other.TabsToSpaces || other.WordWrap != nil {
+		t.Errorf("unmatched file should use [editor] settings, got %+v", other)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -282,3 +326,57 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestThemeFollowsBackground(t *testing.T) {
+	old := GlobalCapabilities
+	t.Cleanup(func() { GlobalCapabilities = old })
+	GlobalCapabilities = &TermCapabilities{}
+
+	theme := ThemeConfig{Name: "default", Light: "paper", Dark: "monokai"}
+	if got := theme.ActiveName(); got != "default" {
+		t.Errorf("unknown background: ActiveName() = %q, want default", got)
+	}
+
+	GlobalCapabilities.Background = BackgroundDark
+	if got := theme.ActiveName(); got != "monokai" {
+		t.Errorf("dark background: ActiveName() = %q, want monokai", got)
+	}
+	theme.SetActive("dracula")
+	if theme.Dark != "dracula" || theme.Light != "paper" || theme.Name != "default" {
+		t.Errorf("SetActive on dark background changed the wrong slot: %+v", theme)
+	}
+
+	GlobalCapabilities.Background = BackgroundLight
+	if got := theme.ActiveName(); got != "paper" {
+		t.Errorf("light background: ActiveName() = %q, want paper", got)
+	}
+
+	// No light theme configured falls back to Name
+	theme = ThemeConfig{Name: "default", Dark: "monokai"}
+	if got := theme.ActiveName(); got != "default" {
+		t.Errorf("light background without light theme: ActiveName() = %q, want default", got)
+	}
+	theme.SetActive("nord")
+	if theme.Name != "nord" {
+		t.Errorf("SetActive without light theme should set Name, got %+v", theme)
+	}
+}
+
+func TestIsTrustedDir(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TrustDir("/home/user/project")
+	cfg.TrustDir("/home/user/project")
+
+	if len(cfg.TrustedDirs) != 1 {
+		t.Errorf("TrustDir added a duplicate: %v", cfg.TrustedDirs)
+	}
+	if !cfg.IsTrustedDir("/home/user/project/sub/dir") {
+		t.Error("subdirectory of a trusted project should be trusted")
+	}
+	if cfg.IsTrustedDir("/home/user/project-evil") {
+		t.Error("sibling with a shared prefix should not be trusted")
+	}
+	if cfg.IsTrustedDir("/home/user") {
+		t.Error("parent of a trusted project should not be trusted")
+	}
+}
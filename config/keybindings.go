@@ -8,6 +8,18 @@ import (
 	"github.com/BurntSushi/toml"
 )
 
+// Keybinding profiles select how keys are interpreted on top of the bindings below
+const (
+	ProfileDefault = "default" // Standard modeless editing
+	ProfileVi      = "vi"      // Modal vi-style editing (normal/insert/visual)
+	ProfileEmacs   = "emacs"   // Emacs-style Ctrl/Meta keys with a kill ring
+)
+
+// KeybindingProfiles returns the available keybinding profiles in display order
+func KeybindingProfiles() []string {
+	return []string{ProfileDefault, ProfileVi, ProfileEmacs}
+}
+
 // KeyBinding represents a single action's key bindings
 type KeyBinding struct {
 	Primary   string `toml:"primary"`
@@ -17,28 +29,64 @@ type KeyBinding struct {
 // KeybindingsConfig holds all configurable keybindings
 type KeybindingsConfig struct {
 	// File operations
-	New         KeyBinding `toml:"new"`
-	Open        KeyBinding `toml:"open"`
-	SaveFile    KeyBinding `toml:"save"`
-	SaveAs      KeyBinding `toml:"save_as"`
-	Close       KeyBinding `toml:"close"`
-	RecentFiles KeyBinding `toml:"recent_files"`
-	Quit        KeyBinding `toml:"quit"`
+	New          KeyBinding `toml:"new"`
+	Open         KeyBinding `toml:"open"`
+	SaveFile     KeyBinding `toml:"save"`
+	SaveAs       KeyBinding `toml:"save_as"`
+	Close        KeyBinding `toml:"close"`
+	ReopenClosed KeyBinding `toml:"reopen_closed"`
+	RecentFiles  KeyBinding `toml:"recent_files"`
+	QuickOpen    KeyBinding `toml:"quick_open"`
+	Print        KeyBinding `toml:"print"`
+	Quit         KeyBinding `toml:"quit"`
 
 	// Edit operations
-	Undo      KeyBinding `toml:"undo"`
-	Redo      KeyBinding `toml:"redo"`
-	Cut       KeyBinding `toml:"cut"`
-	Copy      KeyBinding `toml:"copy"`
-	Paste     KeyBinding `toml:"paste"`
-	CutLine   KeyBinding `toml:"cut_line"`
-	SelectAll KeyBinding `toml:"select_all"`
+	Undo        KeyBinding `toml:"undo"`
+	Redo        KeyBinding `toml:"redo"`
+	UndoHistory KeyBinding `toml:"undo_history"`
+	Earlier     KeyBinding `toml:"earlier"`
+	Later       KeyBinding `toml:"later"`
+	Cut         KeyBinding `toml:"cut"`
+	Copy        KeyBinding `toml:"copy"`
+	CopyAppend  KeyBinding `toml:"copy_append"`
+	Paste       KeyBinding `toml:"paste"`
+	CutLine     KeyBinding `toml:"cut_line"`
+	SelectAll   KeyBinding `toml:"select_all"`
+
+	// Clipboard history and registers
+	PasteHistory   KeyBinding `toml:"paste_history"`
+	CopyToRegister KeyBinding `toml:"copy_to_register"`
+	PasteRegister  KeyBinding `toml:"paste_register"`
+	InsertBuffer   KeyBinding `toml:"insert_buffer"`
+	InsertText     KeyBinding `toml:"insert_text"`
+
+	// Text transforms
+	Uppercase    KeyBinding `toml:"uppercase"`
+	Lowercase    KeyBinding `toml:"lowercase"`
+	TitleCase    KeyBinding `toml:"title_case"`
+	ToggleCase   KeyBinding `toml:"toggle_case"`
+	SortLines    KeyBinding `toml:"sort_lines"`
+	ReverseLines KeyBinding `toml:"reverse_lines"`
+	UniqueLines  KeyBinding `toml:"unique_lines"`
+
+	// Whole-buffer indentation conversion
+	IndentToSpaces KeyBinding `toml:"indent_to_spaces"`
+	IndentToTabs   KeyBinding `toml:"indent_to_tabs"`
+
+	// Number increment/decrement
+	IncrementNumber KeyBinding `toml:"increment_number"`
+	DecrementNumber KeyBinding `toml:"decrement_number"`
 
 	// Search operations
-	Find     KeyBinding `toml:"find"`
-	FindNext KeyBinding `toml:"find_next"`
-	Replace  KeyBinding `toml:"replace"`
-	GoToLine KeyBinding `toml:"goto_line"`
+	Find       KeyBinding `toml:"find"`
+	FindNext   KeyBinding `toml:"find_next"`
+	Replace    KeyBinding `toml:"replace"`
+	GoToLine   KeyBinding `toml:"goto_line"`
+	CursorInfo KeyBinding `toml:"cursor_info"`
+
+	// Symbols
+	GoToSymbol     KeyBinding `toml:"go_to_symbol"`
+	GoToDefinition KeyBinding `toml:"go_to_definition"`
 
 	// Navigation
 	WordLeft  KeyBinding `toml:"word_left"`
@@ -46,12 +94,25 @@ type KeybindingsConfig struct {
 	DocStart  KeyBinding `toml:"doc_start"`
 	DocEnd    KeyBinding `toml:"doc_end"`
 
+	// Scrolling
+	CenterCursor KeyBinding `toml:"center_cursor"`
+	HalfPageUp   KeyBinding `toml:"half_page_up"`
+	HalfPageDown KeyBinding `toml:"half_page_down"`
+
 	// Buffer operations
-	NextBuffer KeyBinding `toml:"next_buffer"`
-	PrevBuffer KeyBinding `toml:"prev_buffer"`
+	NextBuffer      KeyBinding `toml:"next_buffer"`
+	PrevBuffer      KeyBinding `toml:"prev_buffer"`
+	MoveBufferLeft  KeyBinding `toml:"move_buffer_left"`
+	MoveBufferRight KeyBinding `toml:"move_buffer_right"`
+	BufferList      KeyBinding `toml:"buffer_list"`
+	SaveAll         KeyBinding `toml:"save_all"`
+	CloseAll        KeyBinding `toml:"close_all"`
+	CloseOthers     KeyBinding `toml:"close_others"`
 
 	// View toggles
 	ToggleLineNumbers KeyBinding `toml:"toggle_line_numbers"`
+	ToggleFileTree    KeyBinding `toml:"toggle_file_tree"`
+	FocusFileTree     KeyBinding `toml:"focus_file_tree"`
 
 	// Help
 	Help KeyBinding `toml:"help"`
@@ -61,28 +122,64 @@ type KeybindingsConfig struct {
 func DefaultKeybindings() *KeybindingsConfig {
 	return &KeybindingsConfig{
 		// File operations
-		New:         KeyBinding{Primary: "ctrl+n"},
-		Open:        KeyBinding{Primary: "ctrl+o"},
-		SaveFile:    KeyBinding{Primary: "ctrl+s"},
-		SaveAs:      KeyBinding{Primary: ""},
-		Close:       KeyBinding{Primary: "ctrl+w"},
-		RecentFiles: KeyBinding{Primary: "ctrl+r"},
-		Quit:        KeyBinding{Primary: "ctrl+q"},
+		New:          KeyBinding{Primary: "ctrl+n"},
+		Open:         KeyBinding{Primary: "ctrl+o"},
+		SaveFile:     KeyBinding{Primary: "ctrl+s"},
+		SaveAs:       KeyBinding{Primary: ""},
+		Close:        KeyBinding{Primary: "ctrl+w"},
+		ReopenClosed: KeyBinding{Primary: "ctrl+shift+t", Alternate: "alt+t"},
+		RecentFiles:  KeyBinding{Primary: "ctrl+r"},
+		QuickOpen:    KeyBinding{Primary: "ctrl+p"},
+		Print:        KeyBinding{Primary: ""},
+		Quit:         KeyBinding{Primary: "ctrl+q"},
 
 		// Edit operations
-		Undo:      KeyBinding{Primary: "ctrl+z"},
-		Redo:      KeyBinding{Primary: "ctrl+y"},
-		Cut:       KeyBinding{Primary: "ctrl+x"},
-		Copy:      KeyBinding{Primary: "ctrl+c"},
-		Paste:     KeyBinding{Primary: "ctrl+v"},
-		CutLine:   KeyBinding{Primary: "ctrl+k"},
-		SelectAll: KeyBinding{Primary: "ctrl+a"},
+		Undo:        KeyBinding{Primary: "ctrl+z"},
+		Redo:        KeyBinding{Primary: "ctrl+y"},
+		UndoHistory: KeyBinding{Primary: "alt+z"},
+		Earlier:     KeyBinding{Primary: ""},
+		Later:       KeyBinding{Primary: ""},
+		Cut:         KeyBinding{Primary: "ctrl+x"},
+		Copy:        KeyBinding{Primary: "ctrl+c"},
+		CopyAppend:  KeyBinding{Primary: "alt+c"},
+		Paste:       KeyBinding{Primary: "ctrl+v"},
+		CutLine:     KeyBinding{Primary: "ctrl+k"},
+		SelectAll:   KeyBinding{Primary: "ctrl+a"},
+
+		// Clipboard history and registers
+		PasteHistory:   KeyBinding{Primary: "ctrl+shift+v", Alternate: "alt+v"},
+		CopyToRegister: KeyBinding{Primary: "alt+r"},
+		PasteRegister:  KeyBinding{Primary: "alt+i"},
+		InsertBuffer:   KeyBinding{Primary: ""},
+		InsertText:     KeyBinding{Primary: "f2"},
+
+		// Text transforms (unbound by default)
+		Uppercase:    KeyBinding{Primary: ""},
+		Lowercase:    KeyBinding{Primary: ""},
+		TitleCase:    KeyBinding{Primary: ""},
+		ToggleCase:   KeyBinding{Primary: ""},
+		SortLines:    KeyBinding{Primary: ""},
+		ReverseLines: KeyBinding{Primary: ""},
+		UniqueLines:  KeyBinding{Primary: ""},
+
+		// Indentation conversion (unbound by default)
+		IndentToSpaces: KeyBinding{Primary: ""},
+		IndentToTabs:   KeyBinding{Primary: ""},
+
+		// Number increment/decrement (Ctrl+A/Ctrl+X in the vi profile)
+		IncrementNumber: KeyBinding{Primary: "alt+a"},
+		DecrementNumber: KeyBinding{Primary: "alt+x"},
 
 		// Search operations
-		Find:     KeyBinding{Primary: "ctrl+f"},
-		FindNext: KeyBinding{Primary: "f3"},
-		Replace:  KeyBinding{Primary: "ctrl+h"},
-		GoToLine: KeyBinding{Primary: "ctrl+g"},
+		Find:       KeyBinding{Primary: "ctrl+f"},
+		FindNext:   KeyBinding{Primary: "f3"},
+		Replace:    KeyBinding{Primary: "ctrl+h"},
+		GoToLine:   KeyBinding{Primary: "ctrl+g"},
+		CursorInfo: KeyBinding{Primary: ""},
+
+		// Symbols
+		GoToSymbol:     KeyBinding{Primary: "ctrl+shift+o", Alternate: "alt+g"},
+		GoToDefinition: KeyBinding{Primary: "f12"},
 
 		// Navigation
 		WordLeft:  KeyBinding{Primary: "ctrl+left"},
@@ -90,12 +187,25 @@ func DefaultKeybindings() *KeybindingsConfig {
 		DocStart:  KeyBinding{Primary: "ctrl+home"},
 		DocEnd:    KeyBinding{Primary: "ctrl+end"},
 
+		// Scrolling
+		CenterCursor: KeyBinding{Primary: "ctrl+l"},
+		HalfPageUp:   KeyBinding{Primary: "alt+pgup"},
+		HalfPageDown: KeyBinding{Primary: "alt+pgdown"},
+
 		// Buffer operations
-		NextBuffer: KeyBinding{Primary: "alt+>", Alternate: "ctrl+tab"},
-		PrevBuffer: KeyBinding{Primary: "alt+<", Alternate: "ctrl+shift+tab"},
+		NextBuffer:      KeyBinding{Primary: "alt+>", Alternate: "ctrl+tab"},
+		PrevBuffer:      KeyBinding{Primary: "alt+<", Alternate: "ctrl+shift+tab"},
+		MoveBufferLeft:  KeyBinding{Primary: "alt+shift+left"},
+		MoveBufferRight: KeyBinding{Primary: "alt+shift+right"},
+		BufferList:      KeyBinding{Primary: "ctrl+e"},
+		SaveAll:         KeyBinding{Primary: ""},
+		CloseAll:        KeyBinding{Primary: ""},
+		CloseOthers:     KeyBinding{Primary: ""},
 
 		// View toggles
-		ToggleLineNumbers: KeyBinding{Primary: "ctrl+l"},
+		ToggleLineNumbers: KeyBinding{Primary: "alt+n"},
+		ToggleFileTree:    KeyBinding{Primary: "ctrl+b"},
+		FocusFileTree:     KeyBinding{Primary: "f6"},
 
 		// Help
 		Help: KeyBinding{Primary: "f1"},
@@ -109,26 +219,63 @@ var ActionNames = map[string]string{
 	"save":                "Save",
 	"save_as":             "Save As",
 	"close":               "Close",
+	"reopen_closed":       "Reopen Closed Buffer",
 	"recent_files":        "Recent Files",
+	"quick_open":          "Quick Open",
+	"print":               "Print",
 	"quit":                "Quit",
 	"undo":                "Undo",
 	"redo":                "Redo",
+	"undo_history":        "Undo History",
+	"earlier":             "Earlier",
+	"later":               "Later",
 	"cut":                 "Cut",
 	"copy":                "Copy",
+	"copy_append":         "Copy Append",
 	"paste":               "Paste",
 	"cut_line":            "Cut Line",
 	"select_all":          "Select All",
+	"paste_history":       "Paste from History",
+	"copy_to_register":    "Copy to Register",
+	"paste_register":      "Paste Register",
+	"insert_buffer":       "Insert Buffer",
+	"insert_text":         "Insert Text",
+	"uppercase":           "Uppercase",
+	"lowercase":           "Lowercase",
+	"title_case":          "Title Case",
+	"toggle_case":         "Toggle Case",
+	"sort_lines":          "Sort Lines",
+	"reverse_lines":       "Reverse Lines",
+	"unique_lines":        "Unique Lines",
+	"indent_to_spaces":    "Convert Indentation to Spaces",
+	"indent_to_tabs":      "Convert Indentation to Tabs",
+	"increment_number":    "Increment Number",
+	"decrement_number":    "Decrement Number",
 	"find":                "Find",
 	"find_next":           "Find Next",
 	"replace":             "Replace",
 	"goto_line":           "Go to Line",
+	"cursor_info":         "Cursor Info",
+	"go_to_symbol":        "Go to Symbol",
+	"go_to_definition":    "Go to Definition",
 	"word_left":           "Word Left",
 	"word_right":          "Word Right",
 	"doc_start":           "Document Start",
 	"doc_end":             "Document End",
+	"center_cursor":       "Center Cursor",
+	"half_page_up":        "Half Page Up",
+	"half_page_down":      "Half Page Down",
 	"next_buffer":         "Next Buffer",
 	"prev_buffer":         "Previous Buffer",
+	"move_buffer_left":    "Move Buffer Left",
+	"move_buffer_right":   "Move Buffer Right",
+	"buffer_list":         "Buffer List",
+	"save_all":            "Save All",
+	"close_all":           "Close All",
+	"close_others":        "Close Others",
 	"toggle_line_numbers": "Toggle Line Numbers",
+	"toggle_file_tree":    "Toggle File Tree",
+	"focus_file_tree":     "Switch Tree/Editor Focus",
 	"help":                "Help",
 }
 
@@ -158,13 +305,28 @@ func LoadKeybindings() *KeybindingsConfig {
 		return kb
 	}
 
-	if _, err := toml.DecodeFile(path, kb); err != nil {
+	meta, err := toml.DecodeFile(path, kb)
+	if err != nil {
 		return kb
 	}
+	migrateKeybindings(kb, meta)
 
 	return kb
 }
 
+// migrateKeybindings updates bindings saved by older versions whose
+// defaults have since moved. Files saved before center_cursor existed still
+// bind Ctrl+L to toggle_line_numbers, which now belongs to center_cursor.
+func migrateKeybindings(kb *KeybindingsConfig, meta toml.MetaData) {
+	if meta.IsDefined("center_cursor") {
+		return
+	}
+	defaults := DefaultKeybindings()
+	if kb.ToggleLineNumbers.Matches(defaults.CenterCursor.Primary) {
+		kb.ToggleLineNumbers = defaults.ToggleLineNumbers
+	}
+}
+
 // Save writes keybindings to disk
 func (kb *KeybindingsConfig) Save() error {
 	path, err := KeybindingsPath()
@@ -204,24 +366,70 @@ func (kb *KeybindingsConfig) GetBinding(action string) KeyBinding {
 		return kb.SaveAs
 	case "close":
 		return kb.Close
+	case "reopen_closed":
+		return kb.ReopenClosed
 	case "recent_files":
 		return kb.RecentFiles
+	case "quick_open":
+		return kb.QuickOpen
+	case "print":
+		return kb.Print
 	case "quit":
 		return kb.Quit
 	case "undo":
 		return kb.Undo
 	case "redo":
 		return kb.Redo
+	case "undo_history":
+		return kb.UndoHistory
+	case "earlier":
+		return kb.Earlier
+	case "later":
+		return kb.Later
 	case "cut":
 		return kb.Cut
 	case "copy":
 		return kb.Copy
+	case "copy_append":
+		return kb.CopyAppend
 	case "paste":
 		return kb.Paste
 	case "cut_line":
 		return kb.CutLine
 	case "select_all":
 		return kb.SelectAll
+	case "paste_history":
+		return kb.PasteHistory
+	case "copy_to_register":
+		return kb.CopyToRegister
+	case "paste_register":
+		return kb.PasteRegister
+	case "insert_buffer":
+		return kb.InsertBuffer
+	case "insert_text":
+		return kb.InsertText
+	case "uppercase":
+		return kb.Uppercase
+	case "lowercase":
+		return kb.Lowercase
+	case "title_case":
+		return kb.TitleCase
+	case "toggle_case":
+		return kb.ToggleCase
+	case "sort_lines":
+		return kb.SortLines
+	case "reverse_lines":
+		return kb.ReverseLines
+	case "unique_lines":
+		return kb.UniqueLines
+	case "indent_to_spaces":
+		return kb.IndentToSpaces
+	case "indent_to_tabs":
+		return kb.IndentToTabs
+	case "increment_number":
+		return kb.IncrementNumber
+	case "decrement_number":
+		return kb.DecrementNumber
 	case "find":
 		return kb.Find
 	case "find_next":
@@ -230,6 +438,12 @@ func (kb *KeybindingsConfig) GetBinding(action string) KeyBinding {
 		return kb.Replace
 	case "goto_line":
 		return kb.GoToLine
+	case "cursor_info":
+		return kb.CursorInfo
+	case "go_to_symbol":
+		return kb.GoToSymbol
+	case "go_to_definition":
+		return kb.GoToDefinition
 	case "word_left":
 		return kb.WordLeft
 	case "word_right":
@@ -238,12 +452,34 @@ func (kb *KeybindingsConfig) GetBinding(action string) KeyBinding {
 		return kb.DocStart
 	case "doc_end":
 		return kb.DocEnd
+	case "center_cursor":
+		return kb.CenterCursor
+	case "half_page_up":
+		return kb.HalfPageUp
+	case "half_page_down":
+		return kb.HalfPageDown
 	case "next_buffer":
 		return kb.NextBuffer
 	case "prev_buffer":
 		return kb.PrevBuffer
+	case "move_buffer_left":
+		return kb.MoveBufferLeft
+	case "move_buffer_right":
+		return kb.MoveBufferRight
+	case "buffer_list":
+		return kb.BufferList
+	case "save_all":
+		return kb.SaveAll
+	case "close_all":
+		return kb.CloseAll
+	case "close_others":
+		return kb.CloseOthers
 	case "toggle_line_numbers":
 		return kb.ToggleLineNumbers
+	case "toggle_file_tree":
+		return kb.ToggleFileTree
+	case "focus_file_tree":
+		return kb.FocusFileTree
 	case "help":
 		return kb.Help
 	}
@@ -263,24 +499,70 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 		kb.SaveAs = binding
 	case "close":
 		kb.Close = binding
+	case "reopen_closed":
+		kb.ReopenClosed = binding
 	case "recent_files":
 		kb.RecentFiles = binding
+	case "quick_open":
+		kb.QuickOpen = binding
+	case "print":
+		kb.Print = binding
 	case "quit":
 		kb.Quit = binding
 	case "undo":
 		kb.Undo = binding
 	case "redo":
 		kb.Redo = binding
+	case "undo_history":
+		kb.UndoHistory = binding
+	case "earlier":
+		kb.Earlier = binding
+	case "later":
+		kb.Later = binding
 	case "cut":
 		kb.Cut = binding
 	case "copy":
 		kb.Copy = binding
+	case "copy_append":
+		kb.CopyAppend = binding
 	case "paste":
 		kb.Paste = binding
 	case "cut_line":
 		kb.CutLine = binding
 	case "select_all":
 		kb.SelectAll = binding
+	case "paste_history":
+		kb.PasteHistory = binding
+	case "copy_to_register":
+		kb.CopyToRegister = binding
+	case "paste_register":
+		kb.PasteRegister = binding
+	case "insert_buffer":
+		kb.InsertBuffer = binding
+	case "insert_text":
+		kb.InsertText = binding
+	case "uppercase":
+		kb.Uppercase = binding
+	case "lowercase":
+		kb.Lowercase = binding
+	case "title_case":
+		kb.TitleCase = binding
+	case "toggle_case":
+		kb.ToggleCase = binding
+	case "sort_lines":
+		kb.SortLines = binding
+	case "reverse_lines":
+		kb.ReverseLines = binding
+	case "unique_lines":
+		kb.UniqueLines = binding
+	case "indent_to_spaces":
+		kb.IndentToSpaces = binding
+	case "indent_to_tabs":
+		kb.IndentToTabs = binding
+	case "increment_number":
+		kb.IncrementNumber = binding
+	case "decrement_number":
+		kb.DecrementNumber = binding
 	case "find":
 		kb.Find = binding
 	case "find_next":
@@ -289,6 +571,12 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 		kb.Replace = binding
 	case "goto_line":
 		kb.GoToLine = binding
+	case "cursor_info":
+		kb.CursorInfo = binding
+	case "go_to_symbol":
+		kb.GoToSymbol = binding
+	case "go_to_definition":
+		kb.GoToDefinition = binding
 	case "word_left":
 		kb.WordLeft = binding
 	case "word_right":
@@ -297,12 +585,34 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 		kb.DocStart = binding
 	case "doc_end":
 		kb.DocEnd = binding
+	case "center_cursor":
+		kb.CenterCursor = binding
+	case "half_page_up":
+		kb.HalfPageUp = binding
+	case "half_page_down":
+		kb.HalfPageDown = binding
 	case "next_buffer":
 		kb.NextBuffer = binding
 	case "prev_buffer":
 		kb.PrevBuffer = binding
+	case "move_buffer_left":
+		kb.MoveBufferLeft = binding
+	case "move_buffer_right":
+		kb.MoveBufferRight = binding
+	case "buffer_list":
+		kb.BufferList = binding
+	case "save_all":
+		kb.SaveAll = binding
+	case "close_all":
+		kb.CloseAll = binding
+	case "close_others":
+		kb.CloseOthers = binding
 	case "toggle_line_numbers":
 		kb.ToggleLineNumbers = binding
+	case "toggle_file_tree":
+		kb.ToggleFileTree = binding
+	case "focus_file_tree":
+		kb.FocusFileTree = binding
 	case "help":
 		kb.Help = binding
 	}
@@ -311,21 +621,71 @@ func (kb *KeybindingsConfig) SetBinding(action string, binding KeyBinding) {
 // AllActions returns a list of all action names in display order
 func AllActions() []string {
 	return []string{
-		"new", "open", "save", "save_as", "close", "recent_files", "quit",
-		"undo", "redo", "cut", "copy", "paste", "cut_line", "select_all",
-		"find", "find_next", "replace", "goto_line",
+		"new", "open", "save", "save_as", "close", "reopen_closed", "recent_files", "quick_open", "print", "quit",
+		"undo", "redo", "undo_history", "earlier", "later", "cut", "copy", "copy_append", "paste", "cut_line", "select_all",
+		"paste_history", "copy_to_register", "paste_register", "insert_buffer", "insert_text",
+		"uppercase", "lowercase", "title_case", "toggle_case", "sort_lines", "reverse_lines", "unique_lines",
+		"indent_to_spaces", "indent_to_tabs",
+		"increment_number", "decrement_number",
+		"find", "find_next", "replace", "goto_line", "cursor_info",
+		"go_to_symbol", "go_to_definition",
 		"word_left", "word_right", "doc_start", "doc_end",
-		"next_buffer", "prev_buffer",
-		"toggle_line_numbers",
+		"center_cursor", "half_page_up", "half_page_down",
+		"next_buffer", "prev_buffer", "move_buffer_left", "move_buffer_right", "buffer_list",
+		"save_all", "close_all", "close_others",
+		"toggle_line_numbers", "toggle_file_tree", "focus_file_tree",
 		"help",
 	}
 }
 
-// Matches checks if a key string matches this binding (primary or alternate)
+// ChordSeparator separates the steps of a multi-key chord such as "ctrl+k ctrl+c"
+const ChordSeparator = " "
+
+// normalizeKey lowercases a key string and collapses the whitespace between chord steps
+func normalizeKey(key string) string {
+	if strings.TrimSpace(key) == "" {
+		return key // The space key itself
+	}
+	return strings.Join(strings.Fields(strings.ToLower(key)), ChordSeparator)
+}
+
+// IsChord returns true if the key string is a multi-key chord
+func IsChord(key string) bool {
+	return strings.Contains(normalizeKey(key), ChordSeparator)
+}
+
+// chordPrefix returns the first step of a chord, or "" for a single key
+func chordPrefix(key string) string {
+	key = normalizeKey(key)
+	if i := strings.Index(key, ChordSeparator); i > 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// Matches checks if a key string matches this binding (primary or alternate).
+// Chords are matched against the full sequence, e.g. "ctrl+k ctrl+c".
 func (b KeyBinding) Matches(key string) bool {
-	key = strings.ToLower(key)
-	return (b.Primary != "" && strings.ToLower(b.Primary) == key) ||
-		(b.Alternate != "" && strings.ToLower(b.Alternate) == key)
+	key = normalizeKey(key)
+	return (b.Primary != "" && normalizeKey(b.Primary) == key) ||
+		(b.Alternate != "" && normalizeKey(b.Alternate) == key)
+}
+
+// HasChordPrefix checks if either key of this binding is a chord starting with key
+func (b KeyBinding) HasChordPrefix(key string) bool {
+	key = normalizeKey(key)
+	return (b.Primary != "" && chordPrefix(b.Primary) == key) ||
+		(b.Alternate != "" && chordPrefix(b.Alternate) == key)
+}
+
+// IsChordPrefix returns true if key starts a chord bound to any action
+func (kb *KeybindingsConfig) IsChordPrefix(key string) bool {
+	for _, action := range AllActions() {
+		if kb.GetBinding(action).HasChordPrefix(key) {
+			return true
+		}
+	}
+	return false
 }
 
 // DisplayString returns a human-readable string for the binding
@@ -354,6 +714,8 @@ func FormatKeyForDisplay(key string) string {
 	key = strings.ReplaceAll(key, "left", "Left")
 	key = strings.ReplaceAll(key, "right", "Right")
 	key = strings.ReplaceAll(key, "tab", "Tab")
+	key = strings.ReplaceAll(key, "pgup", "PgUp")
+	key = strings.ReplaceAll(key, "pgdown", "PgDn")
 	// F-keys
 	for i := 1; i <= 12; i++ {
 		old := strings.ToLower(string(rune('f')) + string(rune('0'+i)))
@@ -373,15 +735,19 @@ func (kb *KeybindingsConfig) FindConflicts() map[string][]string {
 	conflicts := make(map[string][]string)
 	keyToActions := make(map[string][]string)
 
+	prefixToActions := make(map[string][]string)
+
 	for _, action := range AllActions() {
 		binding := kb.GetBinding(action)
-		if binding.Primary != "" {
-			key := strings.ToLower(binding.Primary)
-			keyToActions[key] = append(keyToActions[key], action)
-		}
-		if binding.Alternate != "" {
-			key := strings.ToLower(binding.Alternate)
+		for _, key := range []string{binding.Primary, binding.Alternate} {
+			if key == "" {
+				continue
+			}
+			key = normalizeKey(key)
 			keyToActions[key] = append(keyToActions[key], action)
+			if prefix := chordPrefix(key); prefix != "" {
+				prefixToActions[prefix] = append(prefixToActions[prefix], action)
+			}
 		}
 	}
 
@@ -391,5 +757,12 @@ func (kb *KeybindingsConfig) FindConflicts() map[string][]string {
 		}
 	}
 
+	// A single key that also starts a chord can never fire on its own
+	for prefix, chordActions := range prefixToActions {
+		if actions, ok := keyToActions[prefix]; ok {
+			conflicts[prefix] = append(append([]string{}, actions...), chordActions...)
+		}
+	}
+
 	return conflicts
 }
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyBindingMatchesChord(t *testing.T) {
+	b := KeyBinding{Primary: "Ctrl+K  Ctrl+C", Alternate: "f5"}
+
+	if !b.Matches("ctrl+k ctrl+c") {
+		t.Errorf("chord should match regardless of case and spacing")
+	}
+	if b.Matches("ctrl+k") {
+		t.Errorf("chord prefix alone should not match")
+	}
+	if !b.Matches("F5") {
+		t.Errorf("single-key alternate should still match")
+	}
+	if !b.HasChordPrefix("ctrl+k") || b.HasChordPrefix("f5") {
+		t.Errorf("HasChordPrefix mismatch")
+	}
+	if !IsChord(b.Primary) || IsChord(b.Alternate) {
+		t.Errorf("IsChord mismatch")
+	}
+}
+
+func TestFindConflictsChordPrefix(t *testing.T) {
+	kb := DefaultKeybindings()
+	if len(kb.FindConflicts()) != 0 {
+		t.Fatalf("default keybindings should not conflict: %v", kb.FindConflicts())
+	}
+
+	// cut_line is bound to ctrl+k, which a chord now shadows
+	kb.Help = KeyBinding{Primary: "ctrl+k ctrl+h"}
+	conflicts := kb.FindConflicts()
+	actions, ok := conflicts["ctrl+k"]
+	if !ok || len(actions) != 2 {
+		t.Fatalf("conflicts[ctrl+k] = %v, want cut_line and help", actions)
+	}
+	if !kb.IsChordPrefix("ctrl+k") || kb.IsChordPrefix("ctrl+h") {
+		t.Errorf("IsChordPrefix mismatch")
+	}
+}
+
+func TestLoadKeybindingsMovesOldLineNumbersKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := KeybindingsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saved before center_cursor existed, with the old Ctrl+L default
+	old := "[toggle_line_numbers]\nprimary = \"ctrl+l\"\n"
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	kb := LoadKeybindings()
+	if kb.ToggleLineNumbers != DefaultKeybindings().ToggleLineNumbers {
+		t.Errorf("toggle_line_numbers = %+v, want the new default", kb.ToggleLineNumbers)
+	}
+	if len(kb.FindConflicts()) != 0 {
+		t.Errorf("migrated keybindings conflict: %v", kb.FindConflicts())
+	}
+
+	// A file that knows about center_cursor is taken as the user wrote it
+	chosen := old + "[center_cursor]\nprimary = \"alt+l\"\n"
+	if err := os.WriteFile(path, []byte(chosen), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if kb := LoadKeybindings(); kb.ToggleLineNumbers.Primary != "ctrl+l" {
+		t.Errorf("toggle_line_numbers = %+v, want ctrl+l kept", kb.ToggleLineNumbers)
+	}
+}
@@ -50,6 +50,10 @@ type UIColors struct {
 	// Minimap colors
 	MinimapIndicator string `toml:"minimap_indicator"` // Viewport indicator color
 	MinimapText      string `toml:"minimap_text"`      // Braille text color
+	// Unsaved change markers in the scrollbar and minimap
+	ChangeMarker string `toml:"change_marker"`
+	// Background behind other occurrences of the word under the cursor
+	WordHighlightBg string `toml:"word_highlight_bg"`
 }
 
 // SyntaxColors holds syntax highlighting color settings
@@ -70,29 +74,31 @@ var builtinThemes = map[string]Theme{
 		Description: "Classic DOS EDIT style - blue with cyan highlights",
 		Author:      "Textivus",
 		UI: UIColors{
-			MenuBg:           "4",  // Dark blue
-			MenuFg:           "15", // Bright white
-			MenuHighlightBg:  "6",  // Cyan
-			MenuHighlightFg:  "16", // True black
-			StatusBg:         "4",  // Dark blue
-			StatusFg:         "15", // Bright white
-			StatusAccent:     "14", // Bright cyan
-			SelectionBg:      "6",  // Cyan
-			SelectionFg:      "0",  // Black
-			LineNumber:       "8",  // Gray
-			LineNumberActive: "3",  // Yellow
-			ErrorFg:          "9",  // Bright red
-			DisabledFg:       "8",  // Gray
-			DialogBg:         "7",  // Light gray
-			DialogFg:         "0",  // Black
-			DialogBorder:     "0",  // Black
-			DialogTitle:      "4",  // Blue
-			DialogButton:     "2",  // Green
-			DialogButtonFg:   "15", // White
-			ScrollbarTrack:   "8",  // Gray
-			ScrollbarThumb:   "6",  // Cyan
-			MinimapIndicator: "6",  // Cyan
-			MinimapText:      "8",  // Gray
+			MenuBg:           "4",   // Dark blue
+			MenuFg:           "15",  // Bright white
+			MenuHighlightBg:  "6",   // Cyan
+			MenuHighlightFg:  "16",  // True black
+			StatusBg:         "4",   // Dark blue
+			StatusFg:         "15",  // Bright white
+			StatusAccent:     "14",  // Bright cyan
+			SelectionBg:      "6",   // Cyan
+			SelectionFg:      "0",   // Black
+			LineNumber:       "8",   // Gray
+			LineNumberActive: "3",   // Yellow
+			ErrorFg:          "9",   // Bright red
+			DisabledFg:       "8",   // Gray
+			DialogBg:         "7",   // Light gray
+			DialogFg:         "0",   // Black
+			DialogBorder:     "0",   // Black
+			DialogTitle:      "4",   // Blue
+			DialogButton:     "2",   // Green
+			DialogButtonFg:   "15",  // White
+			ScrollbarTrack:   "8",   // Gray
+			ScrollbarThumb:   "6",   // Cyan
+			MinimapIndicator: "6",   // Cyan
+			MinimapText:      "8",   // Gray
+			ChangeMarker:     "11",  // Bright yellow
+			WordHighlightBg:  "238", // Dark gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "14", // Bright cyan
@@ -132,6 +138,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "43",  // Teal
 			MinimapIndicator: "43",  // Teal
 			MinimapText:      "245", // Gray
+			ChangeMarker:     "179", // Amber
+			WordHighlightBg:  "237", // Dark gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "176", // Purple
@@ -171,6 +179,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "32",  // Blue
 			MinimapIndicator: "32",  // Blue
 			MinimapText:      "245", // Gray
+			ChangeMarker:     "130", // Dark orange
+			WordHighlightBg:  "254", // Near white
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "26",  // Blue
@@ -210,6 +220,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "208", // Orange
 			MinimapIndicator: "208", // Orange
 			MinimapText:      "59",  // Gray
+			ChangeMarker:     "208", // Orange
+			WordHighlightBg:  "238", // Dark gray
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "197", // Pink-red
@@ -249,6 +261,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#5E81AC", // nord10
 			MinimapIndicator: "#88C0D0", // nord8
 			MinimapText:      "#4C566A", // nord3
+			ChangeMarker:     "#EBCB8B", // nord13
+			WordHighlightBg:  "#3B4252", // nord1
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#81A1C1", // nord9
@@ -288,6 +302,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#BD93F9", // purple
 			MinimapIndicator: "#BD93F9", // purple
 			MinimapText:      "#6272A4", // comment
+			ChangeMarker:     "#FFB86C", // orange
+			WordHighlightBg:  "#343746", // between background and selection
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#FF79C6", // pink
@@ -327,6 +343,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#D79921", // yellow
 			MinimapIndicator: "#D79921", // yellow
 			MinimapText:      "#665C54", // bg3
+			ChangeMarker:     "#FABD2F", // bright yellow
+			WordHighlightBg:  "#3C3836", // bg1
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#FB4934", // bright red
@@ -366,6 +384,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#268BD2", // blue
 			MinimapIndicator: "#2AA198", // cyan
 			MinimapText:      "#586E75", // base01
+			ChangeMarker:     "#B58900", // yellow
+			WordHighlightBg:  "#04303B", // between base03 and base02
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#859900", // green
@@ -405,6 +425,8 @@ var builtinThemes = map[string]Theme{
 			ScrollbarThumb:   "#CBA6F7", // mauve
 			MinimapIndicator: "#F5C2E7", // pink
 			MinimapText:      "#6C7086", // overlay0
+			ChangeMarker:     "#F9E2AF", // yellow
+			WordHighlightBg:  "#313244", // surface0
 		},
 		Syntax: SyntaxColors{
 			Keyword:  "#CBA6F7", // mauve
@@ -445,6 +467,12 @@ func LoadTheme(name string) Theme {
 	return DefaultTheme()
 }
 
+// LoadUserTheme loads a theme from the user's themes directory.
+// Unlike LoadTheme it reports errors instead of falling back to a built-in theme.
+func LoadUserTheme(name string) (Theme, error) {
+	return loadUserTheme(name)
+}
+
 // loadUserTheme attempts to load a theme from the user's themes directory
 func loadUserTheme(name string) (Theme, error) {
 	themesDir, err := ThemesDir()
@@ -549,6 +577,12 @@ func mergeWithDefault(theme Theme) Theme {
 	if theme.UI.MinimapText == "" {
 		theme.UI.MinimapText = def.UI.MinimapText
 	}
+	if theme.UI.ChangeMarker == "" {
+		theme.UI.ChangeMarker = def.UI.ChangeMarker
+	}
+	if theme.UI.WordHighlightBg == "" {
+		theme.UI.WordHighlightBg = def.UI.WordHighlightBg
+	}
 
 	// Syntax colors
 	if theme.Syntax.Keyword == "" {
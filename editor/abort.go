@@ -0,0 +1,37 @@
+package editor
+
+// MarkCommandLineFile marks the active buffer as the file named on the
+// command line. When textivus runs as $EDITOR, throwing away unsaved
+// changes to that file is an abort (see Aborted).
+func (e *Editor) MarkCommandLineFile() {
+	doc := e.activeDoc()
+	e.argDoc, e.argFile = doc, doc.filename
+}
+
+// Aborted reports whether the user discarded unsaved changes to the
+// command-line file, by closing its buffer or quitting, without ever having
+// saved it. Saves of other buffers and auto-saves don't count.
+func (e *Editor) Aborted() bool {
+	return e.argDiscarded && !e.argSaved
+}
+
+// noteArgSaved records a save of the active buffer by the user if it holds
+// the command-line file
+func (e *Editor) noteArgSaved() {
+	doc := e.activeDoc()
+	if !e.savingQuietly && doc == e.argDoc && doc.filename == e.argFile {
+		e.argSaved = true
+	}
+}
+
+// noteArgClosed records doc going away, on closing its buffer or quitting;
+// unsaved changes to the command-line file are discarded with it
+func (e *Editor) noteArgClosed(doc *Document) {
+	if doc == nil || doc != e.argDoc {
+		return
+	}
+	if doc.modified && doc.filename == e.argFile {
+		e.argDiscarded = true
+	}
+	e.argDoc = nil // A last buffer is reused for a new file
+}
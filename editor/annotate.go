@@ -0,0 +1,264 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/cornish/textivus-editor/annotations"
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// annotationsWidth is the width of the Annotations dialog
+const annotationsWidth = 60
+
+// noteSegment is the status bar segment showing the note on the cursor line
+const noteSegment = "note"
+
+// noteSegmentWidth is the most of a note the status bar segment shows
+const noteSegmentWidth = 40
+
+// annotationStore returns the notes of all files, loading them on first use.
+// An unreadable annotations file is left alone and notes are kept in memory.
+func (e *Editor) annotationStore() *annotations.Store {
+	if e.noteStore != nil {
+		return e.noteStore
+	}
+	path, err := config.AnnotationsPath()
+	if err == nil {
+		e.noteStore, err = annotations.Load(path)
+	}
+	if err != nil {
+		e.noteStore, _ = annotations.Load("")
+		e.statusbar.SetMessage("Annotations unavailable: "+err.Error(), "error")
+	}
+	return e.noteStore
+}
+
+// noteKey is the store key for a document's notes ("" for untitled buffers)
+func noteKey(doc *Document) string {
+	if doc.filename == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(doc.filename); err == nil {
+		return abs
+	}
+	return doc.filename
+}
+
+// loadNotes reads the active document's notes, placing them on the lines
+// their text has moved to since they were written
+func (e *Editor) loadNotes() {
+	doc := e.activeDoc()
+	doc.notes = e.annotationStore().Notes(noteKey(doc))
+	annotations.Anchor(doc.notes, doc.buffer.Lines())
+	doc.notesVersion = doc.buffer.Version()
+}
+
+// anchorNotes keeps the active document's notes on their lines as text is
+// inserted and deleted above them. It only looks again once the text has
+// changed.
+func (e *Editor) anchorNotes() {
+	doc := e.activeDoc()
+	if len(doc.notes) > 0 && doc.notesVersion != doc.buffer.Version() {
+		annotations.Anchor(doc.notes, doc.buffer.Lines())
+		doc.notesVersion = doc.buffer.Version()
+	}
+}
+
+// saveNotes writes the active document's notes to the store. Notes whose
+// line was edited take on the line's new text, so they follow it from now on.
+func (e *Editor) saveNotes() {
+	doc := e.activeDoc()
+	key := noteKey(doc)
+	store := e.annotationStore()
+	if key == "" || (len(doc.notes) == 0 && len(store.Files[key]) == 0) {
+		return
+	}
+	lines := doc.buffer.Lines()
+	for i := range doc.notes {
+		if annotations.Detached(doc.notes[i], lines) && doc.notes[i].Line < len(lines) {
+			doc.notes[i].Hash = annotations.LineHash(lines[doc.notes[i].Line])
+		}
+	}
+	store.SetNotes(key, doc.notes)
+	if err := store.Save(); err != nil {
+		e.statusbar.SetMessage("Saving annotations failed: "+err.Error(), "error")
+	}
+}
+
+// noteAt returns the index of the note on line, or -1
+func (doc *Document) noteAt(line int) int {
+	for i, n := range doc.notes {
+		if n.Line == line {
+			return i
+		}
+	}
+	return -1
+}
+
+// annotateLine prompts for the note on the cursor line, starting from the
+// existing note if there is one
+func (e *Editor) annotateLine() {
+	doc := e.activeDoc()
+	if doc.filename == "" {
+		e.statusbar.SetMessage("Save the file before adding notes", "info")
+		return
+	}
+	line := doc.cursor.Line()
+	e.showPrompt(fmt.Sprintf("Note for line %d (empty removes): ", line+1), PromptAnnotate)
+	if i := doc.noteAt(line); i >= 0 {
+		e.promptInput = doc.notes[i].Text
+	}
+}
+
+// setNote adds, changes or (with empty text) removes the cursor line's note
+func (e *Editor) setNote(text string) {
+	doc := e.activeDoc()
+	line := doc.cursor.Line()
+	i := doc.noteAt(line)
+	switch {
+	case text == "" && i < 0:
+		e.statusbar.SetMessage("Cancelled", "info")
+		return
+	case text == "":
+		doc.notes = append(doc.notes[:i], doc.notes[i+1:]...)
+		e.statusbar.SetMessage("Note removed", "info")
+	case i >= 0:
+		doc.notes[i].Text = text
+		e.statusbar.SetMessage("Note updated", "info")
+	default:
+		doc.notes = append(doc.notes, annotations.Note{
+			Line: line,
+			Hash: annotations.LineHash(doc.buffer.Lines()[line]),
+			Text: text,
+		})
+		annotations.Anchor(doc.notes, doc.buffer.Lines()) // Keeps them in line order
+		e.statusbar.SetMessage("Note added", "info")
+	}
+	e.saveNotes()
+}
+
+// annotatedLines returns the lines with notes, for the gutter markers
+func (e *Editor) annotatedLines() map[int]bool {
+	doc := e.activeDoc()
+	if len(doc.notes) == 0 {
+		return nil
+	}
+	lines := make(map[int]bool, len(doc.notes))
+	for _, n := range doc.notes {
+		lines[n.Line] = true
+	}
+	return lines
+}
+
+// noteSegmentText shows the note on the cursor line in the status bar
+func (e *Editor) noteSegmentText() string {
+	doc := e.activeDoc()
+	i := doc.noteAt(doc.cursor.Line())
+	if i < 0 {
+		return ""
+	}
+	return e.box.Note + " " + runewidth.Truncate(doc.notes[i].Text, noteSegmentWidth, e.box.Ellipsis)
+}
+
+// showAnnotations opens the Annotations dialog listing the active file's notes
+func (e *Editor) showAnnotations() {
+	if len(e.activeDoc().notes) == 0 {
+		e.statusbar.SetMessage("No annotations in this file", "info")
+		return
+	}
+	e.annotationIndex = 0
+	e.mode = ModeAnnotations
+}
+
+// annotationsDialog builds the Annotations dialog
+func (e *Editor) annotationsDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(annotationsWidth)
+	db.AddTitleBorder(" Annotations ")
+	db.AddEmptyLine()
+	lines := e.activeDoc().buffer.Lines()
+	for i, n := range e.activeDoc().notes {
+		label := fmt.Sprintf("%5d  ", n.Line+1)
+		if annotations.Detached(n, lines) {
+			label = fmt.Sprintf("%5d? ", n.Line+1) // The line was edited or deleted
+		}
+		label += historyPreview(n.Text, db.InnerWidth()-2-len(label), e.box.Ellipsis)
+		db.AddSelectableItem(label, i == e.annotationIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Go to  [E] Edit  [Del] Remove  [Esc] Close")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayAnnotationsDialog overlays the Annotations dialog centered on the viewport
+func (e *Editor) overlayAnnotationsDialog(viewportContent string) string {
+	return e.annotationsDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// goToNote closes the dialog and moves the cursor to the chosen note's line
+func (e *Editor) goToNote(index int) {
+	e.mode = ModeNormal
+	doc := e.activeDoc()
+	if index < 0 || index >= len(doc.notes) {
+		return
+	}
+	doc.selection.Clear()
+	doc.cursor.SetPosition(doc.notes[index].Line, 0)
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// handleAnnotationsKey handles key events in the Annotations dialog
+func (e *Editor) handleAnnotationsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	doc := e.activeDoc()
+	switch msg.String() {
+	case "up":
+		if e.annotationIndex > 0 {
+			e.annotationIndex--
+		}
+	case "down":
+		if e.annotationIndex < len(doc.notes)-1 {
+			e.annotationIndex++
+		}
+	case "enter":
+		e.goToNote(e.annotationIndex)
+	case "e", "E":
+		e.goToNote(e.annotationIndex)
+		e.annotateLine()
+	case "delete", "d", "D":
+		if e.annotationIndex < len(doc.notes) {
+			doc.notes = append(doc.notes[:e.annotationIndex], doc.notes[e.annotationIndex+1:]...)
+			e.saveNotes()
+			e.statusbar.SetMessage("Note removed", "info")
+		}
+		if e.annotationIndex >= len(doc.notes) {
+			e.annotationIndex = len(doc.notes) - 1
+		}
+		if len(doc.notes) == 0 {
+			e.mode = ModeNormal
+		}
+	case "esc":
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// handleAnnotationsMouse selects notes on click and goes to one on a second click
+func (e *Editor) handleAnnotationsMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.annotationsDialog().listMouse(msg, &e.annotationIndex) {
+	case listChoose:
+		e.goToNote(e.annotationIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// registerNoteSegment shows the cursor line's note in the status bar
+func (e *Editor) registerNoteSegment() {
+	e.statusbar.RegisterSegment(ui.StatusSegment{Name: noteSegment, Priority: 5, Render: e.noteSegmentText})
+}
@@ -0,0 +1,80 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAnnotations(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.statusbar.SetWidth(120)
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(2, 0)
+	e.annotateLine()
+	e.promptInput = "check error handling"
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !e.annotatedLines()[2] {
+		t.Fatalf("line 3 should be annotated, got %v", e.annotatedLines())
+	}
+	if view := e.statusbar.View(); !strings.Contains(view, "check error handling") {
+		t.Errorf("status bar = %q, want the cursor line's note", view)
+	}
+
+	// Typing a line above moves the note down with its line
+	doc.cursor.SetPosition(0, 0)
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("// x")}, {Type: tea.KeyEnter}} {
+		e.Update(k)
+	}
+	if !e.annotatedLines()[3] {
+		t.Errorf("note should follow its line to line 4, got %v", e.annotatedLines())
+	}
+
+	// Messages that leave the text alone don't anchor the notes again
+	doc.notes[0].Line = 0
+	e.Update(tea.MouseMsg{Action: tea.MouseActionMotion, X: 5, Y: 5})
+	if doc.notes[0].Line != 0 {
+		t.Errorf("mouse motion re-anchored the notes")
+	}
+	doc.notes[0].Line = 3
+	e.doSave()
+
+	// A new session finds the note again, without the file being touched
+	e2 := New()
+	if err := e2.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if notes := e2.activeDoc().notes; len(notes) != 1 || notes[0].Line != 3 {
+		t.Errorf("reloaded notes = %+v, want the note on line 4", notes)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "check error") {
+		t.Errorf("the note must not be written into the file")
+	}
+
+	// The list dialog removes notes
+	e2.showAnnotations()
+	e2.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if len(e2.activeDoc().notes) != 0 || e2.mode != ModeNormal {
+		t.Errorf("deleting the last note should empty the list and close it")
+	}
+}
+
+func TestAnnotateUntitled(t *testing.T) {
+	e := New()
+	e.annotateLine()
+	if e.mode == ModePrompt {
+		t.Errorf("untitled buffers have no path to key notes by")
+	}
+}
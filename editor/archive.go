@@ -0,0 +1,361 @@
+package editor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveExts lists the file name endings the file browser opens as
+// directories
+var archiveExts = []string{".zip", ".jar", ".tar", ".tar.gz", ".tgz"}
+
+// archiveMemberMax is the largest file opened from inside an archive
+const archiveMemberMax = 64 << 20
+
+// archiveEntry is a file or directory inside an archive
+type archiveEntry struct {
+	name    string // Slash-separated path inside the archive
+	isDir   bool
+	size    int64
+	modTime time.Time
+}
+
+// archiveListing is the contents of an archive, kept while the file
+// browser is inside it
+type archiveListing struct {
+	path    string
+	modTime time.Time
+	entries []archiveEntry
+}
+
+// isArchiveName reports whether a file name has an archive's ending
+func isArchiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, ext := range archiveExts {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isArchiveFile reports whether path is an archive on disk, rather than
+// a directory with an archive's name or a member of another archive
+func isArchiveFile(path string) bool {
+	if !isArchiveName(filepath.Base(path)) {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// splitArchivePath splits a path that runs into an archive into the
+// archive file and the slash-separated path inside it ("" for the
+// archive's top level). ok is false for ordinary paths.
+func splitArchivePath(p string) (archive, inner string, ok bool) {
+	p = filepath.Clean(p)
+	for dir := p; ; dir = filepath.Dir(dir) {
+		if isArchiveFile(dir) {
+			rel, _ := filepath.Rel(dir, p)
+			if rel == "." {
+				rel = ""
+			}
+			return dir, filepath.ToSlash(rel), true
+		}
+		if filepath.Dir(dir) == dir {
+			return "", "", false
+		}
+	}
+}
+
+// archiveMember returns the archive a file was opened from, if it was
+func archiveMember(filename string) (archive string, ok bool) {
+	if filename == "" {
+		return "", false
+	}
+	if _, err := os.Stat(filename); err == nil {
+		return "", false
+	}
+	archive, inner, ok := splitArchivePath(filename)
+	return archive, ok && inner != ""
+}
+
+// readFile reads a file, or the archive member a path running into an
+// archive names
+func readFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err == nil {
+		return data, nil
+	}
+	archive, inner, ok := splitArchivePath(filename)
+	if !ok || inner == "" {
+		return nil, err
+	}
+	return readArchiveMember(archive, inner, archiveMemberMax)
+}
+
+// archiveEntryName cleans the name of an archive member, returning "" for
+// names that would lead outside the archive
+func archiveEntryName(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// isZip reports whether an archive's name says it is a zip file
+func isZip(archive string) bool {
+	lower := strings.ToLower(archive)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".jar")
+}
+
+// isTarGz reports whether an archive's name says it is gzip-compressed
+func isTarGz(archive string) bool {
+	lower := strings.ToLower(archive)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// walkTar calls fn for each member of a tar archive, stopping when fn
+// returns false
+func walkTar(archive string, fn func(hdr *tar.Header, r io.Reader) bool) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if isTarGz(archive) {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(hdr, tr) {
+			return nil
+		}
+	}
+}
+
+// listArchive returns every file and directory in an archive, including
+// directories only implied by the paths of the files in them
+func listArchive(archive string) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	add := func(name string, isDir bool, size int64, modTime time.Time) {
+		if name = archiveEntryName(name); name != "" {
+			entries = append(entries, archiveEntry{name: name, isDir: isDir, size: size, modTime: modTime})
+		}
+	}
+	if isZip(archive) {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			add(f.Name, f.FileInfo().IsDir(), int64(f.UncompressedSize64), f.Modified)
+		}
+	} else {
+		err := walkTar(archive, func(hdr *tar.Header, _ io.Reader) bool {
+			switch hdr.Typeflag {
+			case tar.TypeDir:
+				add(hdr.Name, true, 0, hdr.ModTime)
+			case tar.TypeReg:
+				add(hdr.Name, false, hdr.Size, hdr.ModTime)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Add the directories that have no entry of their own
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.isDir {
+			seen[entry.name] = true
+		}
+	}
+	for _, entry := range entries {
+		for dir := path.Dir(entry.name); dir != "." && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			entries = append(entries, archiveEntry{name: dir, isDir: true})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries, nil
+}
+
+// openArchiveMember calls fn with a reader for the file at inner in an
+// archive and its size
+func openArchiveMember(archive, inner string, fn func(r io.Reader, size int64) error) error {
+	if isZip(archive) {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if archiveEntryName(f.Name) != inner || f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			return fn(rc, int64(f.UncompressedSize64))
+		}
+	} else {
+		found := false
+		var fnErr error
+		err := walkTar(archive, func(hdr *tar.Header, tr io.Reader) bool {
+			if hdr.Typeflag != tar.TypeReg || archiveEntryName(hdr.Name) != inner {
+				return true
+			}
+			found = true
+			fnErr = fn(tr, hdr.Size)
+			return false
+		})
+		if err != nil {
+			return err
+		}
+		if found {
+			return fnErr
+		}
+	}
+	return fmt.Errorf("%s: %w", inner, os.ErrNotExist)
+}
+
+// readArchiveMember reads the file at inner in an archive, failing if it
+// is larger than limit
+func readArchiveMember(archive, inner string, limit int64) ([]byte, error) {
+	var data []byte
+	err := openArchiveMember(archive, inner, func(r io.Reader, size int64) error {
+		if size > limit {
+			return fmt.Errorf("%s is too large to open from an archive (%s)", path.Base(inner), formatFileSize(size))
+		}
+		var err error
+		data, err = io.ReadAll(io.LimitReader(r, limit))
+		return err
+	})
+	return data, err
+}
+
+// archiveChildren returns the entries directly inside dir of an archive
+// ("" for its top level), named by their last path element
+func archiveChildren(entries []archiveEntry, dir string) []archiveEntry {
+	var children []archiveEntry
+	for _, entry := range entries {
+		parent := path.Dir(entry.name)
+		if parent == "." {
+			parent = ""
+		}
+		if parent == dir {
+			entry.name = path.Base(entry.name)
+			children = append(children, entry)
+		}
+	}
+	return children
+}
+
+// archiveHasDir reports whether dir exists inside an archive's entries
+func archiveHasDir(entries []archiveEntry, dir string) bool {
+	if dir == "" {
+		return true
+	}
+	for _, entry := range entries {
+		if entry.isDir && entry.name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// browserArchive lists the archive the file browser is in, reading it
+// again only when the file has changed
+func (e *Editor) browserArchive(archive string) ([]archiveEntry, error) {
+	info, err := os.Stat(archive)
+	if err != nil {
+		return nil, err
+	}
+	if l := e.fileBrowserArchive; l != nil && l.path == archive && l.modTime.Equal(info.ModTime()) {
+		return l.entries, nil
+	}
+	entries, err := listArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	e.fileBrowserArchive = &archiveListing{path: archive, modTime: info.ModTime(), entries: entries}
+	return entries, nil
+}
+
+// loadArchiveDirectory fills the file browser with a directory inside an
+// archive. Members are listed like files, to be opened read-only.
+func (e *Editor) loadArchiveDirectory(archive, inner string) {
+	entries, err := e.browserArchive(archive)
+	if err == nil && !archiveHasDir(entries, inner) {
+		err = fmt.Errorf("%s: %w", inner, os.ErrNotExist)
+	}
+	if err != nil {
+		e.fileBrowserError = "Cannot open archive: " + errorReason(err)
+		return
+	}
+
+	e.fileBrowserError = ""
+	e.fileBrowserFavorites = false
+	e.fileBrowserFilter = ""
+	e.fileBrowserEntries = []FileEntry{{Name: "..", IsDir: true, Readable: true, IsSpecial: true}}
+
+	dirPath := filepath.Join(archive, filepath.FromSlash(inner))
+	var dirs, files []FileEntry
+	for _, child := range archiveChildren(entries, inner) {
+		entry := FileEntry{
+			Name:     child.name,
+			IsDir:    child.isDir,
+			Size:     child.size,
+			ModTime:  child.modTime,
+			Readable: true,
+			FullPath: filepath.Join(dirPath, child.name),
+		}
+		if child.isDir {
+			dirs = append(dirs, entry)
+		} else {
+			files = append(files, entry)
+		}
+	}
+	e.sortBrowserEntries(dirs)
+	e.sortBrowserEntries(files)
+	e.fileBrowserEntries = append(e.fileBrowserEntries, dirs...)
+	e.fileBrowserEntries = append(e.fileBrowserEntries, files...)
+
+	e.fileBrowserDir = dirPath
+	e.fileBrowserSelected = 0
+	e.fileBrowserScroll = 0
+}
+
+// archiveWarning is shown on opening a file from inside an archive
+func archiveWarning(archive string) string {
+	return "Read-only: opened from " + filepath.Base(archive) + " - Save As extracts it"
+}
@@ -0,0 +1,123 @@
+package editor
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeZip writes a zip archive holding files, keyed by member name
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListArchiveTarGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, body := range map[string]string{"app/today.log": "started\n", "../escape.txt": "x", "README": "hi"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(body))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	entries, err := listArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range archiveChildren(entries, "") {
+		names = append(names, entry.name)
+	}
+	if strings.Join(names, " ") != "README app escape.txt" {
+		t.Errorf("top level = %v", names)
+	}
+	data, err := readArchiveMember(path, "app/today.log", archiveMemberMax)
+	if err != nil || string(data) != "started\n" {
+		t.Errorf("read member = %q, %v", data, err)
+	}
+	if _, err := readArchiveMember(path, "app/today.log", 3); err == nil {
+		t.Error("member over the limit was read")
+	}
+}
+
+func TestBrowseIntoArchive(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	writeZip(t, filepath.Join(root, "bundle.zip"), map[string]string{"src/main.go": "package main\n"})
+
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	e.showFileBrowser()
+	open := func(name string) {
+		t.Helper()
+		for i, entry := range e.fileBrowserEntries {
+			if entry.Name == name {
+				e.fileBrowserSelected = i
+				e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+				return
+			}
+		}
+		t.Fatalf("%s not listed in %s: %v", name, e.fileBrowserDir, browserNames(e))
+	}
+	open("bundle.zip")
+	open("src")
+	if want := filepath.Join(root, "bundle.zip", "src"); e.fileBrowserDir != want {
+		t.Fatalf("browsing %s, want %s", e.fileBrowserDir, want)
+	}
+	open("main.go")
+	doc := e.activeDoc()
+	if e.mode != ModeNormal || doc.buffer.String() != "package main\n" || !doc.readOnly {
+		t.Fatalf("opened %q (read-only %v) in mode %v", doc.buffer.String(), doc.readOnly, e.mode)
+	}
+
+	// Saving offers to extract it beside the archive
+	e.SaveFile()
+	if e.mode != ModeSaveAs || e.fileBrowserDir != root || e.saveAsFilename != "main.go" {
+		t.Fatalf("save went to mode %v in %s as %q", e.mode, e.fileBrowserDir, e.saveAsFilename)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if data, err := os.ReadFile(filepath.Join(root, "main.go")); err != nil || string(data) != "package main\n" {
+		t.Fatalf("extracted %q, %v", data, err)
+	}
+	if doc.readOnly || doc.filename != filepath.Join(root, "main.go") {
+		t.Errorf("after extracting: %s, read-only %v", doc.filename, doc.readOnly)
+	}
+
+	// Going up from the archive's top level leaves it
+	e.showFileBrowser()
+	open("bundle.zip")
+	open("..")
+	if e.fileBrowserDir != root {
+		t.Errorf(".. from the archive went to %s", e.fileBrowserDir)
+	}
+}
@@ -0,0 +1,179 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// autoPairs maps each opening character to the closer auto-pair inserts
+var autoPairs = map[rune]rune{'(': ')', '[': ']', '{': '}', '"': '"', '\'': '\''}
+
+// autoPairEnabled reports whether typed brackets and quotes are paired
+func (e *Editor) autoPairEnabled() bool {
+	return e.config != nil && e.config.Editor.AutoPair
+}
+
+// typeRune inserts a character typed by the user, pairing brackets and
+// quotes and typing over auto-inserted closers when auto-pair is on
+func (e *Editor) typeRune(r rune) {
+	doc := e.activeDoc()
+	if !e.autoPairEnabled() || doc.selection.Active && !doc.selection.IsEmpty() {
+		e.insertChar(r)
+		return
+	}
+	e.pruneAutoClosers()
+
+	pos := doc.cursor.ByteOffset()
+	if n := len(doc.autoClosers); n > 0 && doc.autoClosers[n-1] == pos && rune(doc.buffer.ByteAt(pos)) == r {
+		// Type over the closer we inserted
+		doc.autoClosers = doc.autoClosers[:n-1]
+		doc.cursor.SetByteOffset(pos + 1)
+		return
+	}
+
+	if closer, ok := autoPairs[r]; ok && e.canAutoPair(r) {
+		e.insertPair(r, closer)
+	} else {
+		e.insertChar(r)
+		e.shiftAutoClosers(pos, doc.cursor.ByteOffset()-pos)
+	}
+	doc.autoCloserLen = doc.buffer.Length()
+}
+
+// canAutoPair reports whether typing opener at the cursor should insert its
+// closer too. Pairing only happens before whitespace, a closer or the end of
+// the line, and quotes are left alone after word characters ("don't").
+func (e *Editor) canAutoPair(opener rune) bool {
+	doc := e.activeDoc()
+	pos := doc.cursor.ByteOffset()
+	if pos < doc.buffer.Length() {
+		next, _ := doc.buffer.RuneAt(pos)
+		if !unicode.IsSpace(next) && next != ')' && next != ']' && next != '}' {
+			return false
+		}
+	}
+	if opener != '"' && opener != '\'' || pos == 0 {
+		return true
+	}
+	prev := rune(doc.buffer.ByteAt(pos - 1))
+	if prev >= 0x80 {
+		// Multi-byte characters are letters for our purposes
+		return false
+	}
+	return prev != '\\' && prev != opener && prev != '_' && !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// insertPair inserts opener and closer as one undoable edit and leaves the
+// cursor between them
+func (e *Editor) insertPair(opener, closer rune) {
+	if e.refuseEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	pos := doc.cursor.ByteOffset()
+	text := string(opener) + string(closer)
+	entry := &UndoEntry{
+		Position:     pos,
+		Inserted:     text,
+		CursorBefore: pos,
+	}
+
+	doc.cursor.Sync()
+	doc.buffer.Insert(text)
+	doc.cursor.SetByteOffset(pos + len(string(opener)))
+
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+
+	e.shiftAutoClosers(pos, len(text))
+	doc.autoClosers = append(doc.autoClosers, doc.cursor.ByteOffset())
+}
+
+// backspacePair deletes both halves of an empty pair when the cursor sits
+// between them. Returns false if there is no pair to delete.
+func (e *Editor) backspacePair() bool {
+	if e.refuseEdit() {
+		return false
+	}
+	doc := e.activeDoc()
+	pos := doc.cursor.ByteOffset()
+	if !e.autoPairEnabled() || doc.selection.Active && !doc.selection.IsEmpty() || pos == 0 || pos >= doc.buffer.Length() {
+		return false
+	}
+	closer, ok := autoPairs[rune(doc.buffer.ByteAt(pos-1))]
+	if !ok || rune(doc.buffer.ByteAt(pos)) != closer {
+		return false
+	}
+	e.pruneAutoClosers()
+
+	entry := &UndoEntry{
+		Position:     pos - 1,
+		Deleted:      doc.buffer.Substring(pos-1, pos+1),
+		CursorBefore: pos,
+		CursorAfter:  pos - 1,
+	}
+	doc.buffer.Replace(pos-1, pos+1, "")
+	doc.cursor.SetByteOffset(pos - 1)
+	doc.undoStack.Push(entry)
+	doc.modified = true
+
+	if n := len(doc.autoClosers); n > 0 && doc.autoClosers[n-1] == pos {
+		doc.autoClosers = doc.autoClosers[:n-1]
+	}
+	e.shiftAutoClosers(pos, -2)
+	doc.autoCloserLen = doc.buffer.Length()
+	return true
+}
+
+// shiftAutoClosers moves tracked closers after an edit of delta bytes at pos
+func (e *Editor) shiftAutoClosers(pos, delta int) {
+	doc := e.activeDoc()
+	for i, off := range doc.autoClosers {
+		if off >= pos {
+			doc.autoClosers[i] = off + delta
+		}
+	}
+}
+
+// pruneAutoClosers forgets auto-inserted closers that can no longer be typed
+// over: any edit made outside auto-pair typing, or the cursor leaving the pair
+func (e *Editor) pruneAutoClosers() {
+	doc := e.activeDoc()
+	if doc.buffer.Length() != doc.autoCloserLen {
+		doc.autoClosers = nil
+		return
+	}
+	pos := doc.cursor.ByteOffset()
+	for n := len(doc.autoClosers); n > 0; n-- {
+		off := doc.autoClosers[n-1]
+		if off >= pos && off < doc.buffer.Length() && !strings.Contains(doc.buffer.Substring(pos, off), "\n") {
+			break
+		}
+		doc.autoClosers = doc.autoClosers[:n-1]
+	}
+}
+
+// toggleAutoPair turns bracket and quote pairing on or off
+func (e *Editor) toggleAutoPair() {
+	e.config.Editor.AutoPair = !e.config.Editor.AutoPair
+	e.activeDoc().autoClosers = nil
+	e.updateAutoPairLabel()
+	if e.config.Editor.AutoPair {
+		e.statusbar.SetMessage("Auto-pair brackets enabled", "info")
+	} else {
+		e.statusbar.SetMessage("Auto-pair brackets disabled", "info")
+	}
+	e.saveConfig()
+}
+
+// updateAutoPairLabel syncs the Options menu checkbox with the config
+func (e *Editor) updateAutoPairLabel() {
+	if e.autoPairEnabled() {
+		e.menubar.SetItemLabel(ui.ActionAutoPair, "[x] Auto-Pair Brackets")
+	} else {
+		e.menubar.SetItemLabel(ui.ActionAutoPair, "[ ] Auto-Pair Brackets")
+	}
+}
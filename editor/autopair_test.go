@@ -0,0 +1,86 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newAutoPairTestEditor(content string, offset int) *Editor {
+	e := newMarkupTestEditor("main.go", content, offset)
+	e.config.Editor.AutoPair = true
+	return e
+}
+
+func typeKeys(e *Editor, s string) {
+	for _, r := range s {
+		if r == ' ' {
+			e.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+			continue
+		}
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestAutoPair(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		offset  int
+		keys    string
+		want    string
+		cursor  int
+	}{
+		{"pairs bracket", "", 0, "(", "()", 1},
+		{"types over closer", "", 0, "(a)", "(a)", 3},
+		{"nested", "", 0, "f([{x", "f([{x}])", 5},
+		{"nested type over", "", 0, "([x])", "([x])", 5},
+		{"quotes", "", 0, `"hi"`, `"hi"`, 4},
+		{"space keeps pair", "", 0, "{ a }", "{ a }", 5},
+		{"apostrophe in word", "", 0, "don't", "don't", 5},
+		{"no pair before word", "abc", 0, "(", "(abc", 1},
+		{"manual closer not skipped", "()", 1, ")", "())", 2},
+	}
+	for _, tt := range tests {
+		e := newAutoPairTestEditor(tt.content, tt.offset)
+		typeKeys(e, tt.keys)
+		doc := e.activeDoc()
+		if got := doc.buffer.String(); got != tt.want || doc.cursor.ByteOffset() != tt.cursor {
+			t.Errorf("%s: buffer = %q cursor %d, want %q cursor %d", tt.name, got, doc.cursor.ByteOffset(), tt.want, tt.cursor)
+		}
+	}
+
+	// Disabled by default
+	e := newMarkupTestEditor("main.go", "", 0)
+	typeKeys(e, "(")
+	if got := e.activeDoc().buffer.String(); got != "(" {
+		t.Errorf("auto-pair off: buffer = %q, want %q", got, "(")
+	}
+}
+
+func TestAutoPairBackspace(t *testing.T) {
+	e := newAutoPairTestEditor("x", 1)
+	typeKeys(e, "[")
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	doc := e.activeDoc()
+	if got := doc.buffer.String(); got != "x" || doc.cursor.ByteOffset() != 1 {
+		t.Errorf("backspace in pair: buffer = %q cursor %d, want %q cursor 1", got, doc.cursor.ByteOffset(), "x")
+	}
+
+	// Only an empty pair is deleted as a unit
+	e = newAutoPairTestEditor("(a)", 2)
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got := e.activeDoc().buffer.String(); got != "()" {
+		t.Errorf("backspace = %q, want %q", got, "()")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got := e.activeDoc().buffer.String(); got != "" {
+		t.Errorf("second backspace = %q, want empty", got)
+	}
+
+	// Undo restores the pair in one step
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "()" {
+		t.Errorf("after undo = %q, want %q", got, "()")
+	}
+}
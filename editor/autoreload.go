@@ -0,0 +1,140 @@
+package editor
+
+import (
+	"os"
+	"strings"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+// autoReloadEnabled reports whether unmodified buffers follow their files
+// when another program changes them
+func (e *Editor) autoReloadEnabled() bool {
+	return e.config == nil || e.config.Editor.AutoReload
+}
+
+// reloadChangedFiles reloads every unmodified buffer whose file has
+// changed on disk, saying so for the active one
+func (e *Editor) reloadChangedFiles() {
+	for _, doc := range e.documents {
+		if e.reloadChanged(doc) && doc == e.activeDoc() {
+			e.statusbar.SetMessage("Reloaded "+e.bufferName(doc)+" (changed on disk)", "info")
+		}
+	}
+}
+
+// reloadChanged brings an unmodified buffer up to date with its file if it
+// changed on disk, reporting whether the text changed. Buffers with
+// unsaved edits are left for the user to decide about.
+func (e *Editor) reloadChanged(doc *Document) bool {
+	if !e.autoReloadEnabled() || doc.filename == "" || doc.modTime.IsZero() ||
+		doc.modified || doc.hexView || doc.follow {
+		return false
+	}
+	info, err := os.Stat(doc.filename)
+	if err != nil || !info.Mode().IsRegular() || !info.ModTime().After(doc.modTime) {
+		return false
+	}
+	raw, err := os.ReadFile(doc.filename)
+	if err != nil {
+		return false
+	}
+	content, err := enc.DecodeToUTF8(raw, doc.encoding)
+	if err != nil {
+		return false
+	}
+	doc.modTime = info.ModTime()
+	doc.changedOnDisk = false
+	text := string(content)
+	if text == doc.buffer.String() {
+		return false // Only the time changed
+	}
+	e.applyReload(doc, text)
+	return true
+}
+
+// applyReload changes doc's text to text by replacing only the lines that
+// differ, as one undoable edit, so the cursor, scroll position and notes
+// stay with the lines they were on
+func (e *Editor) applyReload(doc *Document, text string) {
+	active := doc == e.activeDoc()
+	changes := diffChanges(diffLines(doc.buffer.Lines(), strings.Split(text, "\n")))
+	line, col := doc.cursor.Line(), doc.cursor.Col()
+	scrollY := doc.scrollY
+	if active {
+		scrollY = e.viewport.ScrollY()
+	}
+
+	entry := &UndoEntry{Name: "Reload from disk", CursorBefore: doc.cursor.ByteOffset()}
+	// Later changes first, so the line offsets of earlier ones still hold
+	for i := len(changes) - 1; i >= 0; i-- {
+		start, end, insert := reloadEdit(doc.buffer, changes[i])
+		entry.Edits = append(entry.Edits, UndoEdit{
+			Position: start,
+			Deleted:  doc.buffer.Substring(start, end),
+			Inserted: insert,
+		})
+		doc.buffer.Replace(start, end, insert)
+	}
+	if len(entry.Edits) > 0 {
+		entry.Position = entry.Edits[len(entry.Edits)-1].Position
+	}
+
+	line = min(mapReloadLine(changes, line), doc.buffer.LineCount()-1)
+	doc.cursor.SetPosition(line, min(col, len(doc.buffer.Lines()[line])))
+	doc.selection.Clear()
+	doc.multiSel = nil
+	doc.autoClosers = nil
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = false
+	doc.markSaved()
+
+	scrollY = mapReloadLine(changes, scrollY)
+	if active {
+		e.viewport.SetScrollY(scrollY)
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	} else {
+		doc.scrollY = scrollY
+	}
+}
+
+// reloadEdit returns the byte range of buf that a line change replaces and
+// the text that replaces it. Lines are taken with the newline after them,
+// except at the end of the text, which has none.
+func reloadEdit(buf *Buffer, change lineChange) (start, end int, insert string) {
+	joined := strings.Join(change.lines, "\n")
+	if change.aEnd < buf.LineCount() {
+		start, end = buf.LineStartOffset(change.aStart), buf.LineStartOffset(change.aEnd)
+		if len(change.lines) > 0 {
+			joined += "\n"
+		}
+		return start, end, joined
+	}
+	switch {
+	case change.aStart == change.aEnd:
+		// New lines after the last one
+		return buf.Length(), buf.Length(), "\n" + joined
+	case len(change.lines) == 0:
+		// The last lines went, with the newline before them
+		return buf.LineStartOffset(change.aStart) - 1, buf.Length(), ""
+	}
+	return buf.LineStartOffset(change.aStart), buf.Length(), joined
+}
+
+// mapReloadLine returns where line of the old text ends up after changes:
+// lines after a change move by the lines it added or removed, and a line
+// that was replaced lands on the matching line of its replacement
+func mapReloadLine(changes []lineChange, line int) int {
+	shift := 0
+	for _, change := range changes {
+		if line < change.aStart {
+			break
+		}
+		if line < change.aEnd {
+			return change.aStart + shift + min(line-change.aStart, max(len(change.lines)-1, 0))
+		}
+		shift += len(change.lines) - (change.aEnd - change.aStart)
+	}
+	return max(line+shift, 0)
+}
@@ -0,0 +1,115 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestApplyReload(t *testing.T) {
+	tests := []struct {
+		name, old, new string
+	}{
+		{"edit in the middle", "a\nb\nc\nd", "a\nB\nc\nd"},
+		{"lines added at the end", "a\nb", "a\nb\nc\nd"},
+		{"lines removed from the end", "a\nb\nc", "a"},
+		{"lines added at the start", "c\nd", "a\nb\nc\nd"},
+		{"lines removed from the start", "a\nb\nc", "c"},
+		{"newline added at the end", "a\nb", "a\nb\n"},
+		{"newline removed from the end", "a\nb\n", "a\nb"},
+		{"everything replaced", "a\nb", "x"},
+		{"emptied", "a\nb\n", ""},
+		{"from empty", "", "a\nb"},
+		{"several changes", "1\n2\n3\n4\n5\n6\n7\n8", "1\n2x\n3\n5\n6\nnew\n7\n8\n9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New()
+			doc := e.activeDoc()
+			doc.buffer = NewBufferFromString(tt.old)
+			doc.cursor = NewCursor(doc.buffer)
+			e.applyReload(doc, tt.new)
+			if got := doc.buffer.String(); got != tt.new {
+				t.Fatalf("buffer = %q, want %q", got, tt.new)
+			}
+			if doc.modified {
+				t.Errorf("reloaded buffer should be unmodified")
+			}
+			e.undo()
+			if got := doc.buffer.String(); got != tt.old {
+				t.Errorf("after undo buffer = %q, want %q", got, tt.old)
+			}
+			e.redo()
+			if got := doc.buffer.String(); got != tt.new {
+				t.Errorf("after redo buffer = %q, want %q", got, tt.new)
+			}
+		})
+	}
+}
+
+func TestMapReloadLine(t *testing.T) {
+	// Lines 1-2 replaced by one line, two lines inserted before line 5
+	changes := []lineChange{{aStart: 1, aEnd: 3, lines: []string{"x"}}, {aStart: 5, aEnd: 5, lines: []string{"y", "z"}}}
+	for line, want := range []int{0, 1, 1, 2, 3, 6, 7} {
+		if got := mapReloadLine(changes, line); got != want {
+			t.Errorf("mapReloadLine(%d) = %d, want %d", line, got, want)
+		}
+	}
+}
+
+func TestAutoReload(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	var lines []string
+	for i := range 10 {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(6, 4)
+
+	// Two lines are added above the cursor by another program
+	changed := append([]string{"new", "lines"}, lines...)
+	writeLater(t, path, strings.Join(changed, "\n"))
+	e.Update(fileCheckMsg{})
+	if got := doc.buffer.String(); got != strings.Join(changed, "\n") {
+		t.Fatalf("buffer not reloaded: %q", got)
+	}
+	if doc.modified || doc.changedOnDisk {
+		t.Errorf("reloaded buffer should match the file")
+	}
+	if doc.cursor.Line() != 8 || doc.cursor.Col() != 4 {
+		t.Errorf("cursor = %d:%d, want it kept on its line at 8:4", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	// A buffer with edits is left alone and the conflict is shown
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	writeLater(t, path, "replaced")
+	e.Update(fileCheckMsg{})
+	if !doc.changedOnDisk || strings.Contains(doc.buffer.String(), "replaced") {
+		t.Errorf("modified buffer should not be reloaded")
+	}
+}
+
+// writeLater writes data to path and dates it a minute ahead, so it reads
+// as changed however quickly the test runs
+func writeLater(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package editor
+
+import "slices"
+
+// autosaveBuffers saves the modified buffers among docs that have a file
+// to go to, marking them auto-saved, and returns how many were saved.
+// Untitled, read-only and hex view buffers are left alone. Saves never
+// ask anything: one that needs an answer is skipped, and skipped holds
+// the status message saying why.
+func (e *Editor) autosaveBuffers(docs []*Document) (saved int, skipped string) {
+	current := e.activeDoc()
+	for _, doc := range docs {
+		if _, ok := archiveMember(doc.filename); ok || !doc.modified || doc.filename == "" || doc.readOnly || doc.hexView {
+			continue
+		}
+		e.switchToBuffer(slices.Index(e.documents, doc))
+		if e.quietSave() {
+			doc.autosaved = true
+			saved++
+		} else if skipped == "" {
+			skipped, _ = e.statusbar.Message()
+		}
+	}
+	e.switchToBuffer(slices.Index(e.documents, current))
+	e.updateMenuState()
+	return saved, skipped
+}
+
+// autosaveOnFocusLoss saves the modified buffers when the terminal loses
+// focus, if autosave_on_focus_loss is set. Dialogs are not interrupted.
+func (e *Editor) autosaveOnFocusLoss() {
+	if e.config == nil || !e.config.Editor.AutosaveOnFocusLoss || e.mode != ModeNormal {
+		return
+	}
+	saved, skipped := e.autosaveBuffers(e.documents)
+	switch {
+	case skipped != "":
+		e.statusbar.SetMessage(skipped, "warning")
+	case saved > 0:
+		e.statusbar.SetMessage("Auto-saved "+countBuffers(saved), "info")
+	}
+}
+
+// autosaveOnSwitch saves the buffer just left for another one, if
+// autosave_on_switch is set
+func (e *Editor) autosaveOnSwitch() {
+	left := e.lastActiveDoc
+	defer func() { e.lastActiveDoc = e.activeDoc() }()
+	if left == nil || left == e.activeDoc() || e.config == nil || !e.config.Editor.AutosaveOnSwitch ||
+		e.mode != ModeNormal || !slices.Contains(e.documents, left) {
+		return
+	}
+	saved, skipped := e.autosaveBuffers([]*Document{left})
+	switch {
+	case skipped != "":
+		e.statusbar.SetMessage(skipped, "warning")
+	case saved > 0:
+		e.statusbar.SetMessage("Auto-saved "+e.bufferName(left), "info")
+	}
+}
@@ -0,0 +1,131 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAutosaveOnSwitch(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	openBuffers(t, e, root, "a.txt", "b.txt")
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	b := e.activeDoc()
+
+	// Off by default: switching keeps the edit unsaved
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">"), Alt: true})
+	if !b.modified {
+		t.Fatalf("switching should not save with autosave_on_switch off")
+	}
+
+	e.config.Editor.AutosaveOnSwitch = true
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">"), Alt: true})
+	if e.activeDoc() != b {
+		t.Fatalf("expected to be back on b.txt")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(">"), Alt: true})
+	if e.activeDoc() == b {
+		t.Fatalf("switching should leave b.txt")
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "b.txt"))
+	if b.modified || !b.autosaved || string(data) != "xb.txt" {
+		t.Errorf("switching away should auto-save b.txt, file holds %q", data)
+	}
+}
+
+func TestAutosaveOnFocusLoss(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	openBuffers(t, e, root, "a.txt", "b.txt")
+	for _, doc := range e.documents {
+		doc.buffer.Replace(0, 0, "edited ")
+		doc.modified = true
+	}
+	e.doNewFile()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	untitled := e.activeDoc()
+
+	e.Update(tea.BlurMsg{})
+	if !e.documents[0].modified {
+		t.Fatalf("losing focus should not save with autosave_on_focus_loss off")
+	}
+
+	e.config.Editor.AutosaveOnFocusLoss = true
+	e.Update(tea.BlurMsg{})
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if data, _ := os.ReadFile(filepath.Join(root, name)); string(data) != "edited "+name {
+			t.Errorf("%s holds %q after losing focus", name, data)
+		}
+	}
+	if e.activeDoc() != untitled || !untitled.modified {
+		t.Errorf("the untitled buffer should stay active and unsaved")
+	}
+}
+
+func TestAutosaveNeverAsks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	e.config.Editor.AutosaveOnFocusLoss = true
+	e.config.Editor.StripSoftHyphens = true
+	openBuffers(t, e, root, "a.txt", "b.txt")
+	a, b := e.documents[0], e.documents[1]
+	a.buffer.Replace(0, 0, "hy\u00ADphen ")
+	a.modified = true
+	b.buffer.Replace(0, 0, "edited ")
+	b.modified = true
+
+	e.Update(tea.BlurMsg{})
+	if e.mode != ModeNormal || e.activeDoc() != b {
+		t.Fatalf("auto-save should not open a dialog or change buffers")
+	}
+	if !a.modified {
+		t.Errorf("the buffer needing an answer should be skipped")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "b.txt")); string(data) != "edited b.txt" {
+		t.Errorf("b.txt holds %q; the other buffers should still be saved", data)
+	}
+	if msg, _ := e.statusbar.Message(); msg == "" {
+		t.Errorf("the skipped save should be reported")
+	}
+}
+
+func TestAutosaveKeepsExternalChanges(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	e.config.Editor.AutosaveOnFocusLoss = true
+	openBuffers(t, e, root, "a.txt", "b.txt")
+	a, b := e.documents[0], e.documents[1]
+	for _, doc := range e.documents {
+		doc.buffer.Replace(0, 0, "edited ")
+		doc.modified = true
+	}
+
+	// Another program rewrites a.txt after it was opened
+	path := filepath.Join(root, "a.txt")
+	if err := os.WriteFile(path, []byte("EXTERNAL"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := a.modTime.Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Update(tea.BlurMsg{})
+	if data, _ := os.ReadFile(path); string(data) != "EXTERNAL" || !a.modified {
+		t.Errorf("a.txt holds %q; auto-save should not overwrite external changes", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "b.txt")); string(data) != "edited b.txt" || b.modified {
+		t.Errorf("b.txt holds %q; the other buffers should still be saved", data)
+	}
+	if msg, _ := e.statusbar.Message(); msg == "" {
+		t.Errorf("the skipped save should be reported")
+	}
+}
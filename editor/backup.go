@@ -0,0 +1,224 @@
+package editor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+// restoreBackupWidth is the width of the Restore Backup dialog
+const restoreBackupWidth = 60
+
+// backupMaxNumber is the highest numbered backup looked for, matching the
+// largest Backup Count the settings allow
+const backupMaxNumber = 99
+
+// backupFile is a backup of the active file listed in the Restore Backup
+// dialog
+type backupFile struct {
+	path    string
+	suffix  string // "~" or "~N~"
+	central bool   // Kept in backup_dir rather than beside the file
+	modTime time.Time
+	size    int64
+}
+
+// backupDir returns the directory backups are kept in, or "" to keep them
+// beside each file. A leading ~ stands for the home directory.
+func (e *Editor) backupDir() string {
+	if e.config == nil || e.config.Editor.BackupDir == "" {
+		return ""
+	}
+	dir := e.config.Editor.BackupDir
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[1:])
+		}
+	}
+	return dir
+}
+
+// backupNameMax is the most bytes of a file's name kept in its encoded
+// name, leaving room for the hash and a ~99~ suffix within the 255 bytes
+// file systems allow
+const backupNameMax = 200
+
+// encodeBackupName turns a file's path into a name for the backup
+// directory: the file's name followed by a hash of its whole path, so files
+// of the same name in different folders don't collide:
+// "/home/me/notes.txt" becomes "notes.txt-" and 16 hex digits
+func encodeBackupName(path string) string {
+	sum := sha256.Sum256([]byte(filepath.ToSlash(path)))
+	name := filepath.Base(path)
+	if len(name) > backupNameMax {
+		name = strings.ToValidUTF8(name[:backupNameMax], "")
+	}
+	return name + "-" + hex.EncodeToString(sum[:8])
+}
+
+// backupSuffix returns the ending of backup n (0 for the single filename~)
+func backupSuffix(n int) string {
+	if n == 0 {
+		return "~"
+	}
+	return fmt.Sprintf("~%d~", n)
+}
+
+// backupPath returns where backup n of filename is written (n = 0 for the
+// single filename~ backup)
+func (e *Editor) backupPath(filename string, n int) string {
+	if dir := e.backupDir(); dir != "" {
+		return filepath.Join(dir, encodeBackupName(filename)+backupSuffix(n))
+	}
+	return filename + backupSuffix(n)
+}
+
+// findBackups lists the backups of filename, newest first. Both places
+// backups can be kept are searched, so those made before backup_dir was
+// set (or cleared) are found too.
+func (e *Editor) findBackups(filename string) []backupFile {
+	bases := []string{filename}
+	if dir := e.backupDir(); dir != "" {
+		bases = append(bases, filepath.Join(dir, encodeBackupName(filename)))
+	}
+	var backups []backupFile
+	for i, base := range bases {
+		for n := 0; n <= backupMaxNumber; n++ {
+			path := base + backupSuffix(n)
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			backups = append(backups, backupFile{
+				path:    path,
+				suffix:  backupSuffix(n),
+				central: i > 0,
+				modTime: info.ModTime(),
+				size:    info.Size(),
+			})
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+	return backups
+}
+
+// showRestoreBackup opens the Restore Backup dialog for the active file
+func (e *Editor) showRestoreBackup() {
+	doc := e.activeDoc()
+	if doc.filename == "" {
+		e.statusbar.SetMessage("No file to restore", "error")
+		return
+	}
+	if len(e.findBackups(doc.filename)) == 0 {
+		e.statusbar.SetMessage("No backups of "+filepath.Base(doc.filename), "info")
+		return
+	}
+	e.backupIndex = 0
+	e.mode = ModeRestoreBackup
+}
+
+// restoreBackupDialog builds the Restore Backup dialog
+func (e *Editor) restoreBackupDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(restoreBackupWidth)
+	db.AddTitleBorder(" Restore Backup ")
+	db.AddEmptyLine()
+	now := time.Now()
+	for i, b := range e.findBackups(e.activeDoc().filename) {
+		when := b.modTime.Format("15:04:05")
+		if y, m, d := b.modTime.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+			when = b.modTime.Format("Jan 2 15:04")
+		}
+		where := "beside the file"
+		if b.central {
+			where = "in backup dir"
+		}
+		db.AddSelectableItem(fmt.Sprintf("  %-12s %9s  %-5s %s", when, formatFileSize(b.size), b.suffix, where), i == e.backupIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Restore  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayRestoreBackupDialog overlays the Restore Backup dialog centered on the viewport
+func (e *Editor) overlayRestoreBackupDialog(viewportContent string) string {
+	return e.restoreBackupDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// chooseBackup closes the dialog and replaces the buffer with the chosen
+// backup, decoded like the file, as one undoable edit. Nothing is written
+// until the buffer is saved.
+func (e *Editor) chooseBackup(index int) {
+	e.mode = ModeNormal
+	doc := e.activeDoc()
+	backups := e.findBackups(doc.filename)
+	if index < 0 || index >= len(backups) {
+		return
+	}
+	if doc.hexView {
+		e.statusbar.SetMessage("Hex view is read-only", "error")
+		return
+	}
+	b := backups[index]
+	raw, err := os.ReadFile(b.path)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot read backup: "+errorReason(err), "error")
+		return
+	}
+	encoding := doc.encoding
+	if encoding == nil {
+		encoding = enc.GetEncodingByID("utf-8")
+	}
+	content, err := enc.DecodeToUTF8(raw, encoding)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot decode backup as "+encoding.Name, "error")
+		return
+	}
+	if string(content) == doc.buffer.String() {
+		e.statusbar.SetMessage("Backup matches the buffer", "info")
+		return
+	}
+	e.transformBuffer(func(string) string { return string(content) })
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.statusbar.SetMessage("Restored backup from "+b.modTime.Format("Jan 2 15:04:05")+" - save to keep it, undo to go back", "success")
+}
+
+// handleRestoreBackupKey handles key events in the Restore Backup dialog
+func (e *Editor) handleRestoreBackupKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := len(e.findBackups(e.activeDoc().filename))
+	switch msg.Type {
+	case tea.KeyUp:
+		e.backupIndex = max(e.backupIndex-1, 0)
+	case tea.KeyDown:
+		e.backupIndex = min(e.backupIndex+1, count-1)
+	case tea.KeyHome:
+		e.backupIndex = 0
+	case tea.KeyEnd:
+		e.backupIndex = count - 1
+	case tea.KeyEnter:
+		e.chooseBackup(e.backupIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// handleRestoreBackupMouse selects backups on click and restores one on a second click
+func (e *Editor) handleRestoreBackupMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.restoreBackupDialog().listMouse(msg, &e.backupIndex) {
+	case listChoose:
+		e.chooseBackup(e.backupIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
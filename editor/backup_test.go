@@ -0,0 +1,115 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBackupDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	backups := filepath.Join(t.TempDir(), "backups")
+	path := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.config.Editor.BackupCount = 2
+	e.config.Editor.BackupDir = backups
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"two\n", "three\n"} {
+		e.transformBuffer(func(string) string { return text })
+		if !e.SaveFile() {
+			t.Fatalf("saving %q failed", text)
+		}
+	}
+
+	if matches, _ := filepath.Glob(filepath.Join(root, "*~*")); len(matches) != 0 {
+		t.Errorf("backups written beside the file: %v", matches)
+	}
+	name := encodeBackupName(path)
+	if !strings.HasPrefix(name, "notes.txt-") || strings.Contains(name, "/") {
+		t.Errorf("encoded name %q", name)
+	}
+	for n, want := range map[int]string{1: "two\n", 2: "one\n"} {
+		data, err := os.ReadFile(filepath.Join(backups, name+backupSuffix(n)))
+		if err != nil || string(data) != want {
+			t.Errorf("backup %d = %q, %v; want %q", n, data, err, want)
+		}
+	}
+
+	// Restoring the oldest backup is an edit of the buffer, undone like any other
+	e.showRestoreBackup()
+	if e.mode != ModeRestoreBackup || len(e.findBackups(path)) != 2 {
+		t.Fatalf("dialog not shown: mode %v, %d backups", e.mode, len(e.findBackups(path)))
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := e.activeDoc().buffer.String(); got != "one\n" || !e.activeDoc().modified {
+		t.Fatalf("restored %q, modified %v", got, e.activeDoc().modified)
+	}
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "three\n" {
+		t.Errorf("after undo: %q", got)
+	}
+}
+
+func TestEncodeBackupName(t *testing.T) {
+	// Paths that an escaping scheme could map to the same name
+	pairs := [][2]string{
+		{"/a%/b", "/a/%b"},
+		{"/a:b", "/a/b"},
+		{"/home/me/notes.txt", "/home/you/notes.txt"},
+	}
+	for _, p := range pairs {
+		if encodeBackupName(p[0]) == encodeBackupName(p[1]) {
+			t.Errorf("%q and %q share the backup name %q", p[0], p[1], encodeBackupName(p[0]))
+		}
+	}
+
+	// However deep or long the path, the name fits a file system's limit
+	deep := "/" + strings.Repeat("folder/", 100) + strings.Repeat("é", 200) + ".txt"
+	name := encodeBackupName(deep) + backupSuffix(backupMaxNumber)
+	if len(name) > 255 || strings.Contains(name, "/") || !utf8.ValidString(name) {
+		t.Errorf("backup name of a long path is %d bytes: %q", len(name), name)
+	}
+}
+
+func TestBackupDirFailureStillSaves(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	path := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A file where the backup folder should be can't hold backups
+	blocked := filepath.Join(root, "backups")
+	if err := os.WriteFile(blocked, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.config.Editor.BackupCount = 1
+	e.config.Editor.BackupDir = blocked
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	e.transformBuffer(func(string) string { return "two\n" })
+	if !e.SaveFile() {
+		t.Fatalf("a failed backup in backup_dir should not stop the save")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "two\n" {
+		t.Errorf("file holds %q after saving", data)
+	}
+	if msg, _ := e.statusbar.Message(); !strings.Contains(msg, "backup failed") {
+		t.Errorf("status = %q, want the failed backup reported", msg)
+	}
+}
@@ -0,0 +1,85 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardLimit returns the size in bytes above which copying asks first,
+// or 0 when it never asks
+func (e *Editor) clipboardLimit() int {
+	if e.config == nil {
+		return 0
+	}
+	return e.config.Editor.ClipboardMaxMB << 20
+}
+
+// copyText puts text on the clipboard and reports its size with verb
+// ("Copied", "Cut"). Text over the clipboard_max_mb limit asks first, since
+// piping hundreds of megabytes through xclip or OSC 52 can hang the
+// terminal. after runs once the text is copied, never on cancel.
+func (e *Editor) copyText(text, verb string, after func()) {
+	size := formatFileSize(int64(len(text)))
+	done := func(msg string) {
+		if after != nil {
+			after()
+		}
+		e.statusbar.SetMessage(msg, "info")
+	}
+
+	if limit := e.clipboardLimit(); limit == 0 || len(text) <= limit {
+		e.clipboard.Copy(text)
+		done(verb + " " + size)
+		return
+	}
+
+	e.showConfirm(&ConfirmDialog{
+		Title:   "Large Selection",
+		Message: "The selection is " + size + ".\nSending it to the system clipboard may be slow.",
+		Buttons: []ConfirmButton{
+			{Label: "Copy Anyway", Hotkey: 'a'},
+			{Label: "Temp File", Hotkey: 't'},
+			{Label: "Editor Only", Hotkey: 'e'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 2,
+		Cancel:  3,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.clipboard.Copy(text)
+				done(verb + " " + size)
+			case 1:
+				path, err := writeClipboardFile(text)
+				if err != nil {
+					e.statusbar.SetMessage("Temp file failed: "+err.Error(), "error")
+					return nil
+				}
+				e.clipboard.CopyLocal(text)
+				done(fmt.Sprintf("%s %s to %s", verb, size, path))
+			case 2:
+				e.clipboard.CopyLocal(text)
+				done(verb + " " + size + " (editor clipboard only)")
+			default:
+				e.statusbar.SetMessage("Copy cancelled", "info")
+			}
+			return nil
+		},
+	})
+}
+
+// writeClipboardFile writes text to a new temporary file and returns its path
+func writeClipboardFile(text string) (string, error) {
+	f, err := os.CreateTemp("", "textivus-clipboard-*.txt")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
@@ -0,0 +1,117 @@
+package editor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/clipboard"
+)
+
+func newBigCopyEditor(t *testing.T) (*Editor, string) {
+	t.Helper()
+	big := strings.Repeat("x", 1<<20+1)
+	e := newMarkupTestEditor("notes.txt", big+"\nend", 0)
+	e.clipboard.SetProvider(clipboard.ProviderInternal)
+	e.config.Editor.ClipboardMaxMB = 1
+	doc := e.activeDoc()
+	doc.selection.Active = true
+	doc.selection.Anchor = 0
+	doc.selection.Cursor = len(big)
+	return e, big
+}
+
+func TestCopyReportsSize(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "hello world", 0)
+	e.clipboard.SetProvider(clipboard.ProviderInternal)
+	e.statusbar.SetWidth(120)
+	doc := e.activeDoc()
+	doc.selection.Active = true
+	doc.selection.Anchor = 0
+	doc.selection.Cursor = 5
+	e.copy()
+	if e.mode != ModeNormal {
+		t.Fatalf("small copies should not ask")
+	}
+	if !strings.Contains(e.statusbar.View(), "Copied 5 B") {
+		t.Errorf("status bar = %q, want the copy size", e.statusbar.View())
+	}
+}
+
+func TestLargeCopyAsksFirst(t *testing.T) {
+	e, big := newBigCopyEditor(t)
+	e.copy()
+	if e.mode != ModeConfirm {
+		t.Fatalf("copying over the limit should ask first")
+	}
+	e.chooseConfirm(3) // Cancel
+	if text, _ := e.clipboard.Paste(); text != "" {
+		t.Errorf("cancelled copy reached the clipboard")
+	}
+
+	e.copy()
+	e.chooseConfirm(2) // Editor Only
+	if text, _ := e.clipboard.Paste(); text != big {
+		t.Errorf("editor-only copy not pasteable, got %d bytes", len(text))
+	}
+}
+
+func TestLargeCutToTempFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	e, big := newBigCopyEditor(t)
+	e.cut()
+	if got := e.activeDoc().buffer.Length(); got != len(big)+4 {
+		t.Fatalf("cut deleted the selection before the user answered")
+	}
+	e.chooseConfirm(1) // Temp File
+	if got := e.activeDoc().buffer.String(); got != "\nend" {
+		t.Errorf("buffer = %q after cut, want the selection gone", got)
+	}
+
+	entries, _ := os.ReadDir(os.Getenv("TMPDIR"))
+	if len(entries) != 1 {
+		t.Fatalf("temp files = %d, want 1", len(entries))
+	}
+	data, err := os.ReadFile(os.Getenv("TMPDIR") + "/" + entries[0].Name())
+	if err != nil || string(data) != big {
+		t.Errorf("temp file holds %d bytes (%v), want the selection", len(data), err)
+	}
+}
+
+func TestLargeCopyAsksOnEveryPath(t *testing.T) {
+	paths := map[string]func(e *Editor){
+		"emacs alt+w": func(e *Editor) {
+			e.handleEmacsKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w"), Alt: true})
+		},
+		"vi yank":   func(e *Editor) { e.viApplyOperator('y', 0, e.activeDoc().selection.Cursor, false) },
+		"vi delete": func(e *Editor) { e.viApplyOperator('d', 0, e.activeDoc().selection.Cursor, false) },
+	}
+	for name, copyBig := range paths {
+		e, big := newBigCopyEditor(t)
+		copyBig(e)
+		if e.mode != ModeConfirm {
+			t.Errorf("%s: copying over the limit should ask first", name)
+			continue
+		}
+		e.chooseConfirm(3) // Cancel
+		if text, _ := e.clipboard.Paste(); text != "" || e.activeDoc().buffer.Length() != len(big)+4 {
+			t.Errorf("%s: cancelling should leave the clipboard and the text alone", name)
+		}
+	}
+
+	// Copy Append checks the clipboard as it would be after appending
+	e, big := newBigCopyEditor(t)
+	e.clipboard.Copy(big)
+	doc := e.activeDoc()
+	doc.selection.Anchor, doc.selection.Cursor = len(big)+1, len(big)+4
+	e.copyAppend()
+	if e.mode != ModeConfirm {
+		t.Fatalf("appending past the limit should ask first")
+	}
+	e.chooseConfirm(2) // Editor Only
+	if text, _ := e.clipboard.Paste(); text != big+"\nend" {
+		t.Errorf("appended clipboard holds %d bytes, want %d", len(text), len(big)+4)
+	}
+}
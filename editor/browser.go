@@ -1,12 +1,14 @@
 package editor
 
 import (
+	"cmp"
 	"fmt"
 	"github.com/cornish/textivus-editor/ui"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mattn/go-runewidth"
@@ -15,7 +17,7 @@ import (
 // handleFileBrowserMouse handles mouse input in file browser mode
 func (e *Editor) handleFileBrowserMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	// Calculate dialog position (must match overlayFileBrowser)
-	boxWidth := 52
+	boxWidth := e.fileBrowserBoxWidth()
 	visibleHeight := e.fileBrowserVisibleHeight()
 	boxHeight := visibleHeight + 6
 
@@ -45,8 +47,8 @@ func (e *Editor) handleFileBrowserMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	switch msg.Button {
 	case tea.MouseButtonLeft:
 		if msg.Action == tea.MouseActionPress {
-			// Check if click is in file list area
-			if relY >= fileListStart && relY < fileListEnd {
+			// Check if click is in file list area, not the preview beside it
+			if relY >= fileListStart && relY < fileListEnd && relX < browserListWidth-1 {
 				clickedIdx := e.fileBrowserScroll + (relY - fileListStart)
 				if clickedIdx >= 0 && clickedIdx < len(e.fileBrowserEntries) {
 					if e.fileBrowserSelected == clickedIdx {
@@ -55,14 +57,17 @@ func (e *Editor) handleFileBrowserMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 							// Not a directory - open the file
 							entry := e.fileBrowserEntries[e.fileBrowserSelected]
 							if !entry.IsDir {
-								fullPath := filepath.Join(e.fileBrowserDir, entry.Name)
+								fullPath := entry.FullPath
+								if fullPath == "" {
+									fullPath = filepath.Join(e.fileBrowserDir, entry.Name)
+								}
 								if err := e.LoadFile(fullPath); err != nil {
 									// Show error in dialog, stay open
 									e.fileBrowserError = "Open failed: " + err.Error()
 								} else {
 									e.mode = ModeNormal
 									e.fileBrowserError = ""
-									e.statusbar.SetMessage("Opened: "+fullPath, "success")
+									e.reportOpened(fullPath)
 								}
 							}
 						}
@@ -209,6 +214,10 @@ func (e *Editor) handleFileBrowserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.Type {
 	case tea.KeyEsc:
+		if e.fileBrowserFilter != "" {
+			e.setBrowserFilter("")
+			return e, nil
+		}
 		e.mode = ModeNormal
 		e.fileBrowserFavorites = false
 		e.statusbar.SetMessage("Cancelled", "info")
@@ -230,14 +239,16 @@ func (e *Editor) handleFileBrowserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						e.mode = ModeNormal
 						e.fileBrowserFavorites = false
 						e.fileBrowserError = ""
-						e.statusbar.SetMessage("Opened: "+fullPath, "success")
+						e.reportOpened(fullPath)
 					}
 				}
 			}
 		}
 
 	case tea.KeyBackspace:
-		e.browserGoToParent()
+		if !e.browserFilterBackspace() {
+			e.browserGoToParent()
+		}
 
 	case tea.KeyUp:
 		e.browserNavigateUp()
@@ -257,11 +268,20 @@ func (e *Editor) handleFileBrowserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyPgDown:
 		e.browserNavigatePgDown(visibleHeight)
 
+	case tea.KeyCtrlF:
+		e.browserToggleFavorite()
+
+	case tea.KeyCtrlH:
+		e.browserToggleHidden()
+
+	case tea.KeyCtrlO:
+		e.browserCycleSort()
+
 	case tea.KeyRunes:
-		// F key toggles favorite
-		if len(msg.Runes) == 1 && (msg.Runes[0] == 'f' || msg.Runes[0] == 'F') {
-			e.browserToggleFavorite()
-		}
+		e.setBrowserFilter(e.fileBrowserFilter + string(msg.Runes))
+
+	case tea.KeySpace:
+		e.setBrowserFilter(e.fileBrowserFilter + " ")
 	}
 
 	return e, nil
@@ -278,7 +298,7 @@ func (e *Editor) fileBrowserVisibleHeight() int {
 		boxHeight = 5
 	}
 	// Subtract header (title + directory + separator) and footer (separator + status + help)
-	return boxHeight - 6
+	return max(boxHeight-6, 0)
 }
 
 // Shared file browser navigation functions
@@ -372,7 +392,7 @@ func (e *Editor) browserEnterDirectory() bool {
 	}
 	entry := e.fileBrowserEntries[e.fileBrowserSelected]
 	if !entry.IsDir {
-		return false
+		return e.browserEnterArchive(entry)
 	}
 	if !entry.Readable {
 		e.fileBrowserError = "Permission denied: " + entry.Name
@@ -401,6 +421,21 @@ func (e *Editor) browserEnterDirectory() bool {
 	return true
 }
 
+// browserEnterArchive opens an archive in the Open File dialog as if it
+// were a directory. Returns false for other files, and in Save As.
+func (e *Editor) browserEnterArchive(entry FileEntry) bool {
+	path := entry.FullPath
+	if path == "" {
+		path = filepath.Join(e.fileBrowserDir, entry.Name)
+	}
+	if e.mode != ModeFileBrowser || !isArchiveFile(path) {
+		return false // Archives inside archives are opened as files
+	}
+	e.fileBrowserError = ""
+	e.loadDirectory(path)
+	return true
+}
+
 // browserToggleFavorite toggles the favorite status of the selected item
 func (e *Editor) browserToggleFavorite() {
 	if e.config == nil {
@@ -427,6 +462,11 @@ func (e *Editor) browserToggleFavorite() {
 	isNowFav, changed := e.config.ToggleFavorite(fullPath, entry.IsDir)
 	if changed {
 		entry.IsFavorite = isNowFav
+		for i := range e.fileBrowserAll {
+			if e.fileBrowserAll[i].FullPath == entry.FullPath && e.fileBrowserAll[i].Name == entry.Name {
+				e.fileBrowserAll[i].IsFavorite = isNowFav
+			}
+		}
 		go e.config.Save()
 
 		if isNowFav {
@@ -441,6 +481,64 @@ func (e *Editor) browserToggleFavorite() {
 	}
 }
 
+// setBrowserFilter narrows the listed entries to those matching filter and
+// selects the best match. The unfiltered list is kept aside while a filter
+// is typed, so deleting it brings every entry back.
+func (e *Editor) setBrowserFilter(filter string) {
+	if e.fileBrowserFilter == "" {
+		e.fileBrowserAll = e.fileBrowserEntries
+	}
+	e.fileBrowserFilter = filter
+	e.fileBrowserEntries = filterBrowserEntries(e.fileBrowserAll, filter)
+	e.fileBrowserSelected = 0
+	e.fileBrowserScroll = 0
+}
+
+// browserFilterBackspace deletes the last character of the filter.
+// Returns false when there is no filter, so Backspace goes to the parent.
+func (e *Editor) browserFilterBackspace() bool {
+	if e.fileBrowserFilter == "" {
+		return false
+	}
+	_, size := utf8.DecodeLastRuneInString(e.fileBrowserFilter)
+	e.setBrowserFilter(e.fileBrowserFilter[:len(e.fileBrowserFilter)-size])
+	return true
+}
+
+// browserFilterLine shows the typed filter on the dialog's status line
+func (e *Editor) browserFilterLine() string {
+	if e.fileBrowserFilter == "" {
+		return ""
+	}
+	line := " Filter: " + e.fileBrowserFilter + "_"
+	if len(e.fileBrowserEntries) == 0 {
+		line += "  (no matches)"
+	}
+	return line
+}
+
+// filterBrowserEntries returns the entries whose names start with filter,
+// then those containing its letters in order, each in listing order. The
+// ".." entry is dropped while filtering so Enter never leaves the directory.
+func filterBrowserEntries(entries []FileEntry, filter string) []FileEntry {
+	if filter == "" {
+		return entries
+	}
+	lower := strings.ToLower(filter)
+	var prefix, fuzzy []FileEntry
+	for _, entry := range entries {
+		if entry.Name == ".." {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(entry.Name), lower) {
+			prefix = append(prefix, entry)
+		} else if _, ok := fuzzyScore(filter, entry.Name); ok {
+			fuzzy = append(fuzzy, entry)
+		}
+	}
+	return append(prefix, fuzzy...)
+}
+
 // saveAsVisibleHeight returns the number of visible file entries in Save As
 func (e *Editor) saveAsVisibleHeight() int {
 	boxHeight := e.viewport.Height() - 4
@@ -451,7 +549,7 @@ func (e *Editor) saveAsVisibleHeight() int {
 		boxHeight = 5
 	}
 	// Subtract header (title + directory + filename + separator) and footer (separator + status + help)
-	return boxHeight - 7
+	return max(boxHeight-7, 0)
 }
 
 // handleSaveAsKey handles keyboard input in Save As mode
@@ -460,12 +558,26 @@ func (e *Editor) handleSaveAsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch msg.Type {
 	case tea.KeyEsc:
+		if e.saveAsFocusBrowser && e.fileBrowserFilter != "" {
+			e.setBrowserFilter("")
+			return e, nil
+		}
 		e.mode = ModeNormal
 		e.fileBrowserFavorites = false
 		e.statusbar.SetMessage("Cancelled", "info")
 
 	case tea.KeyTab:
-		// Toggle focus between filename field and browser
+		// Complete the filename from the directory shown, or else toggle
+		// focus between filename field and browser
+		if !e.saveAsFocusBrowser && e.saveAsFilename != "" {
+			if completed, ok := e.completePath(e.saveAsFilename, e.fileBrowserDir); ok && completed != e.saveAsFilename {
+				e.saveAsFilename = completed
+				break
+			}
+		}
+		e.saveAsFocusBrowser = !e.saveAsFocusBrowser
+
+	case tea.KeyShiftTab:
 		e.saveAsFocusBrowser = !e.saveAsFocusBrowser
 
 	case tea.KeyEnter:
@@ -491,12 +603,7 @@ func (e *Editor) handleSaveAsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			fullPath := filepath.Join(e.fileBrowserDir, e.saveAsFilename)
 			// Check if file exists
 			if _, err := os.Stat(fullPath); err == nil {
-				// File exists - prompt for confirmation
-				e.pendingFilename = fullPath
-				e.promptText = "Overwrite? (y/n): "
-				e.promptInput = ""
-				e.promptAction = PromptConfirmOverwrite
-				e.mode = ModePrompt
+				e.confirmOverwrite(fullPath)
 				return e, nil
 			}
 			// Save the file - try first, only close dialog on success
@@ -513,7 +620,9 @@ func (e *Editor) handleSaveAsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyBackspace:
 		if e.saveAsFocusBrowser {
-			e.browserGoToParent()
+			if !e.browserFilterBackspace() {
+				e.browserGoToParent()
+			}
 		} else {
 			// Delete from filename
 			if len(e.saveAsFilename) > 0 {
@@ -557,19 +666,31 @@ func (e *Editor) handleSaveAsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			e.browserNavigatePgDown(visibleHeight)
 		}
 
-	case tea.KeyRunes:
-		// F key toggles favorite when focus is on browser
-		if e.saveAsFocusBrowser && len(msg.Runes) == 1 && (msg.Runes[0] == 'f' || msg.Runes[0] == 'F') {
+	case tea.KeyCtrlF:
+		if e.saveAsFocusBrowser {
 			e.browserToggleFavorite()
+		}
+
+	case tea.KeyCtrlH:
+		e.browserToggleHidden()
+
+	case tea.KeyCtrlO:
+		e.browserCycleSort()
+
+	case tea.KeyRunes:
+		if e.saveAsFocusBrowser {
+			// Typing in the browser narrows the list
+			e.setBrowserFilter(e.fileBrowserFilter + string(msg.Runes))
 		} else {
-			// Type into filename field, switch focus there
 			e.saveAsFilename += string(msg.Runes)
-			e.saveAsFocusBrowser = false
 		}
 
 	case tea.KeySpace:
-		e.saveAsFilename += " "
-		e.saveAsFocusBrowser = false
+		if e.saveAsFocusBrowser {
+			e.setBrowserFilter(e.fileBrowserFilter + " ")
+		} else {
+			e.saveAsFilename += " "
+		}
 	}
 
 	return e, nil
@@ -591,6 +712,7 @@ func (e *Editor) showFileBrowser() {
 	e.fileBrowserFavorites = false
 	e.fileBrowserScroll = 0
 	e.fileBrowserError = "" // Clear any previous error
+	e.fileBrowserPreview = nil
 	e.loadDirectory(startDir)
 	e.mode = ModeFileBrowser
 }
@@ -599,7 +721,11 @@ func (e *Editor) showFileBrowser() {
 func (e *Editor) showSaveAs() {
 	// Start in current file's directory, or current working directory
 	startDir := ""
-	if e.activeDoc().filename != "" {
+	if archive, ok := archiveMember(e.activeDoc().filename); ok {
+		// Extract beside the archive
+		startDir = filepath.Dir(archive)
+		e.saveAsFilename = filepath.Base(e.activeDoc().filename)
+	} else if e.activeDoc().filename != "" {
 		startDir = filepath.Dir(e.activeDoc().filename)
 		e.saveAsFilename = filepath.Base(e.activeDoc().filename)
 	} else {
@@ -629,6 +755,10 @@ const FavoritesEntryName = "★ Favorites"
 
 // loadDirectory reads the contents of a directory and populates the file browser
 func (e *Editor) loadDirectory(path string) {
+	if archive, inner, ok := splitArchivePath(path); ok {
+		e.loadArchiveDirectory(archive, inner)
+		return
+	}
 	entries, err := os.ReadDir(path)
 	if err != nil {
 		e.fileBrowserError = "Cannot open: " + err.Error()
@@ -638,6 +768,7 @@ func (e *Editor) loadDirectory(path string) {
 	// Clear any previous error on success
 	e.fileBrowserError = ""
 	e.fileBrowserFavorites = false
+	e.fileBrowserFilter = ""
 
 	e.fileBrowserEntries = make([]FileEntry, 0, len(entries)+2)
 
@@ -668,7 +799,11 @@ func (e *Editor) loadDirectory(path string) {
 	// on stale network mounts. Only call Info() for files (to get size).
 	var dirs, files []FileEntry
 	cleanPath := filepath.Clean(path)
+	showHidden := e.config == nil || e.config.Editor.BrowserShowHidden
 	for _, entry := range entries {
+		if !showHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
 		fullPath := filepath.Join(cleanPath, entry.Name())
 		if entry.IsDir() {
 			isFav := e.config != nil && e.config.IsFavoriteDir(fullPath)
@@ -690,6 +825,7 @@ func (e *Editor) loadDirectory(path string) {
 				Name:       entry.Name(),
 				IsDir:      false,
 				Size:       info.Size(),
+				ModTime:    info.ModTime(),
 				Readable:   true,
 				IsFavorite: isFav,
 				FullPath:   fullPath,
@@ -697,13 +833,9 @@ func (e *Editor) loadDirectory(path string) {
 		}
 	}
 
-	// Sort directories and files alphabetically (case-insensitive)
-	sort.Slice(dirs, func(i, j int) bool {
-		return strings.ToLower(dirs[i].Name) < strings.ToLower(dirs[j].Name)
-	})
-	sort.Slice(files, func(i, j int) bool {
-		return strings.ToLower(files[i].Name) < strings.ToLower(files[j].Name)
-	})
+	// Sort directories and files in the chosen order
+	e.sortBrowserEntries(dirs)
+	e.sortBrowserEntries(files)
 
 	// Add directories first, then files
 	e.fileBrowserEntries = append(e.fileBrowserEntries, dirs...)
@@ -718,6 +850,7 @@ func (e *Editor) loadDirectory(path string) {
 func (e *Editor) loadFavorites() {
 	e.fileBrowserError = ""
 	e.fileBrowserFavorites = true
+	e.fileBrowserFilter = ""
 	e.fileBrowserEntries = make([]FileEntry, 0)
 
 	// Add ".." to go back to the previous real directory
@@ -768,6 +901,130 @@ func (e *Editor) loadFavorites() {
 	e.fileBrowserScroll = 0
 }
 
+// File browser sort orders
+const (
+	browserSortName     = "name"
+	browserSortSize     = "size"
+	browserSortModified = "modified"
+)
+
+// sortBrowserEntries orders entries by the configured key, breaking ties by
+// name (case-insensitive). Directories carry no size or time, so they stay
+// in name order when sorting by those.
+func (e *Editor) sortBrowserEntries(entries []FileEntry) {
+	mode, desc := browserSortName, false
+	if e.config != nil {
+		mode, desc = e.config.Editor.BrowserSort, e.config.Editor.BrowserSortDesc
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		c := 0
+		switch mode {
+		case browserSortSize:
+			c = cmp.Compare(a.Size, b.Size)
+		case browserSortModified:
+			c = a.ModTime.Compare(b.ModTime)
+		}
+		if c == 0 {
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+			if mode == browserSortSize || mode == browserSortModified {
+				return c < 0 // Ties stay A to Z either way
+			}
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// browserSortLabel describes the current file browser order
+func (e *Editor) browserSortLabel() string {
+	switch e.config.Editor.BrowserSort {
+	case browserSortSize:
+		if e.config.Editor.BrowserSortDesc {
+			return "size, largest first"
+		}
+		return "size, smallest first"
+	case browserSortModified:
+		if e.config.Editor.BrowserSortDesc {
+			return "modified, newest first"
+		}
+		return "modified, oldest first"
+	}
+	if e.config.Editor.BrowserSortDesc {
+		return "name, Z to A"
+	}
+	return "name, A to Z"
+}
+
+// browserCycleSort steps through name, size and modified time, each
+// ascending then descending, and relists the directory
+func (e *Editor) browserCycleSort() {
+	if e.config == nil {
+		return
+	}
+	ed := &e.config.Editor
+	switch {
+	case !ed.BrowserSortDesc:
+		ed.BrowserSortDesc = true
+	case ed.BrowserSort == browserSortSize:
+		ed.BrowserSort, ed.BrowserSortDesc = browserSortModified, false
+	case ed.BrowserSort == browserSortModified:
+		ed.BrowserSort, ed.BrowserSortDesc = browserSortName, false
+	default:
+		ed.BrowserSort, ed.BrowserSortDesc = browserSortSize, false
+	}
+	e.browserReload()
+	e.statusbar.SetMessage("Sort: "+e.browserSortLabel(), "info")
+	e.saveConfig()
+}
+
+// browserToggleHidden shows or hides dotfiles and relists the directory
+func (e *Editor) browserToggleHidden() {
+	if e.config == nil {
+		return
+	}
+	e.config.Editor.BrowserShowHidden = !e.config.Editor.BrowserShowHidden
+	e.browserReload()
+	if e.config.Editor.BrowserShowHidden {
+		e.statusbar.SetMessage("Hidden files shown", "info")
+	} else {
+		e.statusbar.SetMessage("Hidden files not shown", "info")
+	}
+	e.saveConfig()
+}
+
+// browserReload relists the current directory after a display option
+// changed, keeping the filter and the selected entry
+func (e *Editor) browserReload() {
+	if e.fileBrowserFavorites {
+		return // Favorites keep the order they were added in
+	}
+	selected := ""
+	if e.fileBrowserSelected >= 0 && e.fileBrowserSelected < len(e.fileBrowserEntries) {
+		selected = e.fileBrowserEntries[e.fileBrowserSelected].Name
+	}
+	filter := e.fileBrowserFilter
+	e.fileBrowserPreview = nil
+	e.loadDirectory(e.fileBrowserDir)
+	if filter != "" {
+		e.setBrowserFilter(filter)
+	}
+	for i, entry := range e.fileBrowserEntries {
+		if entry.Name == selected {
+			e.fileBrowserSelected = i
+		}
+	}
+	visibleHeight := e.fileBrowserVisibleHeight()
+	if e.mode == ModeSaveAs {
+		visibleHeight = e.saveAsVisibleHeight()
+	}
+	if e.fileBrowserSelected >= visibleHeight {
+		e.fileBrowserScroll = e.fileBrowserSelected - visibleHeight + 1
+	}
+}
+
 // formatFileSize formats a file size in human-readable format
 func formatFileSize(size int64) string {
 	const (
@@ -790,7 +1047,9 @@ func formatFileSize(size int64) string {
 // overlayFileBrowser overlays the file browser dialog centered on the viewport
 func (e *Editor) overlayFileBrowser(viewportContent string) string {
 	// Box dimensions
-	boxWidth := 52
+	boxWidth := e.fileBrowserBoxWidth()
+	previewWidth := e.browserPreviewWidth()
+	listWidth := browserListWidth - 2
 	visibleHeight := e.fileBrowserVisibleHeight()
 	boxHeight := visibleHeight + 6 // +6 for header (3), status (1), and footer (2)
 
@@ -869,10 +1128,26 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 	}
 	dialogLines = append(dialogLines, e.box.Vertical+dirLine+e.box.Vertical)
 
-	// Separator
-	dialogLines = append(dialogLines, e.box.TeeLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.TeeRight)
+	// Separator, joined by the divider before the preview
+	divider := func(tee string) string {
+		if previewWidth == 0 {
+			return strings.Repeat(e.box.Horizontal, innerWidth)
+		}
+		return strings.Repeat(e.box.Horizontal, listWidth) + tee + strings.Repeat(e.box.Horizontal, previewWidth)
+	}
+	dialogLines = append(dialogLines, e.box.TeeLeft+divider(e.box.TeeDown)+e.box.TeeRight)
 
-	// File list
+	// File list, with the start of the selected file beside it
+	var previewRows []string
+	if previewWidth > 0 {
+		previewRows = e.browserPreviewRows(previewWidth, visibleHeight)
+	}
+	withPreview := func(i int, line string) string {
+		if previewRows == nil {
+			return e.box.Vertical + line + e.box.Vertical
+		}
+		return e.box.Vertical + line + e.box.Vertical + previewRows[i] + e.box.Vertical
+	}
 	// Prefix width: star (★) or space, plus space = 2 visual chars
 	starChar := "★"
 	if e.box.Lock == "*" {
@@ -913,8 +1188,8 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 			} else {
 				line = prefix + "  " + namePadded + fmt.Sprintf("%6s ", formatFileSize(entry.Size))
 			}
-			// Pad line to inner width using Unicode-aware width
-			line = padText(line, innerWidth)
+			// Pad line to the list's width using Unicode-aware width
+			line = padText(line, listWidth)
 			// Style the line
 			if idx == e.fileBrowserSelected {
 				// Selected: use theme button colors
@@ -923,16 +1198,16 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 				// Unreadable: dim/gray
 				line = "\033[2m" + line + "\033[22m"
 			}
-			dialogLines = append(dialogLines, e.box.Vertical+line+e.box.Vertical)
+			dialogLines = append(dialogLines, withPreview(i, line))
 		} else {
 			// Empty line
-			dialogLines = append(dialogLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+			dialogLines = append(dialogLines, withPreview(i, strings.Repeat(" ", listWidth)))
 		}
 	}
 	_ = prefixWidth // suppress unused variable warning
 
 	// Separator
-	dialogLines = append(dialogLines, e.box.TeeLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.TeeRight)
+	dialogLines = append(dialogLines, e.box.TeeLeft+divider(e.box.TeeUp)+e.box.TeeRight)
 
 	// Status/error line
 	statusLine := ""
@@ -943,12 +1218,12 @@ func (e *Editor) overlayFileBrowser(viewportContent string) string {
 		}
 		statusLine = errorStyle + padText(statusLine, innerWidth) + dialogResetStyle
 	} else {
-		statusLine = padText("", innerWidth)
+		statusLine = padText(e.browserFilterLine(), innerWidth)
 	}
 	dialogLines = append(dialogLines, e.box.Vertical+statusLine+e.box.Vertical)
 
 	// Help line
-	helpText := "Enter: Open  F: Favorite  Bksp: Back  Esc: Cancel"
+	helpText := "Enter: Open  ^F: Favorite  Bksp: Back  Esc: Cancel"
 	dialogLines = append(dialogLines, e.box.Vertical+centerText(helpText, innerWidth)+e.box.Vertical)
 
 	// Bottom border
@@ -1155,16 +1430,16 @@ func (e *Editor) overlaySaveAs(viewportContent string) string {
 		}
 		statusLine = errorStyle + padText(statusLine, innerWidth) + dialogResetStyle
 	} else {
-		statusLine = padText("", innerWidth)
+		statusLine = padText(e.browserFilterLine(), innerWidth)
 	}
 	dialogLines = append(dialogLines, e.box.Vertical+statusLine+e.box.Vertical)
 
 	// Help line - changes based on focus
 	var helpText string
 	if e.saveAsFocusBrowser {
-		helpText = "Enter: Select  F: Fav  Tab: Switch  Esc: Cancel"
+		helpText = "Enter: Select  ^F: Fav  Tab: Switch  Esc: Cancel"
 	} else {
-		helpText = "Enter: Save  Tab: Browse  Esc: Cancel"
+		helpText = "Enter: Save  Tab: Complete/Browse  Esc: Cancel"
 	}
 	dialogLines = append(dialogLines, e.box.Vertical+centerText(helpText, innerWidth)+e.box.Vertical)
 
@@ -0,0 +1,202 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// browserNames lists the names shown in the file browser
+func browserNames(e *Editor) []string {
+	var names []string
+	for _, entry := range e.fileBrowserEntries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestFileBrowserFilter(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	os.Mkdir(filepath.Join(root, "abc"), 0755)
+	for _, name := range []string{"alpha.go", "beta.go", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.showFileBrowser()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	got := browserNames(e)
+	if len(got) != 2 || got[0] != "beta.go" || got[1] != "abc" {
+		t.Fatalf("filtered to %v, want the prefix match before the fuzzy one", got)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if len(e.fileBrowserEntries) != 0 || e.browserFilterLine() != " Filter: bz_  (no matches)" {
+		t.Errorf("filter line = %q with %v listed", e.browserFilterLine(), browserNames(e))
+	}
+
+	// Backspace edits the filter first, then goes to the parent
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if e.fileBrowserFilter != "" || len(e.fileBrowserEntries) != 5 || e.fileBrowserDir != root {
+		t.Fatalf("after clearing the filter: %v in %s", browserNames(e), e.fileBrowserDir)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if e.fileBrowserDir != filepath.Dir(root) {
+		t.Errorf("Backspace with no filter should go to the parent, in %s", e.fileBrowserDir)
+	}
+}
+
+func TestFileBrowserFilterOpens(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	for _, name := range []string{"alpha.go", "beta.go"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.showFileBrowser()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bet")})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModeNormal || e.activeDoc().buffer.String() != "beta.go" {
+		t.Errorf("Enter should open the only match")
+	}
+}
+
+func TestSaveAsFilterOnlyInBrowser(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	os.WriteFile(filepath.Join(root, "alpha.go"), nil, 0644)
+
+	e := New()
+	e.showSaveAs()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if e.saveAsFilename != "x" || e.fileBrowserFilter != "" {
+		t.Errorf("typing in the filename field should not filter")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("al")})
+	if e.saveAsFilename != "x" || e.fileBrowserFilter != "al" || len(e.fileBrowserEntries) != 1 {
+		t.Errorf("typing in the browser should filter, got %v", browserNames(e))
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.mode != ModeSaveAs || e.fileBrowserFilter != "" {
+		t.Errorf("Esc should clear the filter before closing")
+	}
+}
+
+func TestFileBrowserSortAndHidden(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	os.Mkdir(filepath.Join(root, "src"), 0755)
+	files := map[string]string{"big.txt": "xxxxxxxx", "small.txt": "x", ".env": "xxxx"}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(root, "small.txt"), old, old)
+
+	e := New()
+	e.showFileBrowser()
+	if got := strings.Join(browserNames(e), " "); got != ".. src .env big.txt small.txt" {
+		t.Fatalf("default listing %q, want directories first, then files A to Z", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlH})
+	if got := strings.Join(browserNames(e), " "); got != ".. src big.txt small.txt" || e.config.Editor.BrowserShowHidden {
+		t.Errorf("Ctrl+H listing %q, want dotfiles hidden", got)
+	}
+
+	// name A-Z -> name Z-A -> size, smallest first -> size, largest first
+	for range 3 {
+		e.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	}
+	if got := strings.Join(browserNames(e), " "); got != ".. src big.txt small.txt" || e.config.Editor.BrowserSort != "size" {
+		t.Errorf("largest first listing %q (sort %q)", got, e.config.Editor.BrowserSort)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if got := strings.Join(browserNames(e), " "); got != ".. src small.txt big.txt" || e.config.Editor.BrowserSort != "modified" {
+		t.Errorf("oldest first listing %q (sort %q)", got, e.config.Editor.BrowserSort)
+	}
+}
+
+func TestReadBrowserPreview(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	var text strings.Builder
+	for i := 1; i <= 50; i++ {
+		text.WriteString("line\r\n")
+	}
+	p := readBrowserPreview(write("text.txt", []byte(text.String())))
+	if p.note != "" || len(p.lines) != browserPreviewLines || p.lines[0] != "line" {
+		t.Errorf("text preview: note %q, %d lines, first %q", p.note, len(p.lines), p.lines[0])
+	}
+	if p := readBrowserPreview(write("latin1.txt", []byte("caf\xe9 cr\xe8me\n"))); len(p.lines) == 0 || p.lines[0] != "café crème" {
+		t.Errorf("Latin-1 preview = %q (%s)", p.lines, p.note)
+	}
+	if p := readBrowserPreview(write("bin", []byte{0x7f, 'E', 'L', 'F', 0, 0, 0, 1, 0, 0})); p.note != "Binary file" {
+		t.Errorf("binary preview note = %q, lines %q", p.note, p.lines)
+	}
+	if p := readBrowserPreview(write("empty", nil)); p.note != "Empty file" {
+		t.Errorf("empty preview note = %q", p.note)
+	}
+	if p := readBrowserPreview(filepath.Join(dir, "missing")); !strings.HasPrefix(p.note, "Cannot read: ") {
+		t.Errorf("missing file note = %q", p.note)
+	}
+}
+
+func TestFileBrowserPreviewPane(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	if err := os.WriteFile(filepath.Join(root, "hello.go"), []byte("package hello\n\tfunc Hi() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	e.showFileBrowser()
+	for i, entry := range e.fileBrowserEntries {
+		if entry.Name == "hello.go" {
+			e.fileBrowserSelected = i
+		}
+	}
+	view := stripAnsi(e.View())
+	if !strings.Contains(view, e.box.Vertical+" package hello") || !strings.Contains(view, e.box.Vertical+"     func Hi() {}") {
+		t.Errorf("preview not shown beside the list:\n%s", view)
+	}
+
+	// Too short for any rows: just the frame
+	for _, height := range []int{10, 11} {
+		e.Update(tea.WindowSizeMsg{Width: 120, Height: height})
+		if view := stripAnsi(e.View()); strings.Contains(view, "package hello") {
+			t.Errorf("preview shown with no rows for it at height %d", height)
+		}
+	}
+
+	// Too narrow for the preview: the list alone
+	e.Update(tea.WindowSizeMsg{Width: 70, Height: 30})
+	if e.fileBrowserBoxWidth() != browserListWidth || strings.Contains(stripAnsi(e.View()), "package hello") {
+		t.Error("preview shown on a narrow terminal")
+	}
+}
@@ -0,0 +1,168 @@
+package editor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+// browserListWidth is the width of the Open File dialog without its preview
+const browserListWidth = 52
+
+// browserPreviewMinWidth is the narrowest preview worth showing beside the
+// file list; narrower terminals get the list alone
+const browserPreviewMinWidth = 24
+
+// browserPreviewMaxWidth keeps the preview from stretching across wide terminals
+const browserPreviewMaxWidth = 64
+
+// browserPreviewLines is how many lines of the selected file are previewed
+const browserPreviewLines = 40
+
+// browserPreviewBytes is how much of the selected file is read for the
+// preview, enough for browserPreviewLines of ordinary text
+const browserPreviewBytes = 16 << 10
+
+// browserPreview is the start of the file selected in the Open File
+// dialog, read once per selection
+type browserPreview struct {
+	path  string
+	lines []string // First lines of the file, as text
+	note  string   // Shown instead of lines: a directory, binary file or error
+}
+
+// browserPreviewWidth returns the width of the Open File dialog's preview
+// column, or 0 when the terminal is too narrow for one
+func (e *Editor) browserPreviewWidth() int {
+	width := min(e.width-browserListWidth-3, browserPreviewMaxWidth)
+	if width < browserPreviewMinWidth {
+		return 0
+	}
+	return width
+}
+
+// fileBrowserBoxWidth returns the width of the Open File dialog, with the
+// preview column and its divider when there is room
+func (e *Editor) fileBrowserBoxWidth() int {
+	if width := e.browserPreviewWidth(); width > 0 {
+		return browserListWidth + width + 1
+	}
+	return browserListWidth
+}
+
+// selectedBrowserPreview returns the preview of the selected entry, reading
+// the file when the selection has moved to another one
+func (e *Editor) selectedBrowserPreview() *browserPreview {
+	if e.fileBrowserSelected < 0 || e.fileBrowserSelected >= len(e.fileBrowserEntries) {
+		return &browserPreview{}
+	}
+	entry := e.fileBrowserEntries[e.fileBrowserSelected]
+	path := entry.FullPath
+	if path == "" {
+		path = filepath.Join(e.fileBrowserDir, entry.Name)
+	}
+	if e.fileBrowserPreview == nil || e.fileBrowserPreview.path != path {
+		switch {
+		case entry.IsSpecial:
+			e.fileBrowserPreview = &browserPreview{path: path}
+		case entry.IsDir:
+			e.fileBrowserPreview = &browserPreview{path: path, note: "Directory"}
+		case isArchiveFile(path):
+			e.fileBrowserPreview = &browserPreview{path: path, note: "Archive - Enter looks inside"}
+		default:
+			e.fileBrowserPreview = readBrowserPreview(path)
+		}
+	}
+	return e.fileBrowserPreview
+}
+
+// readBrowserPreview reads the first lines of the file at path, decoded
+// from its detected encoding. Binary files are not shown.
+func readBrowserPreview(path string) *browserPreview {
+	preview := &browserPreview{path: path}
+	var data []byte
+	read := func(r io.Reader, _ int64) (err error) {
+		data, err = io.ReadAll(io.LimitReader(r, browserPreviewBytes))
+		return err
+	}
+	f, err := os.Open(path)
+	if err == nil {
+		defer f.Close()
+		err = read(f, 0)
+	} else if archive, inner, ok := splitArchivePath(path); ok && inner != "" {
+		err = openArchiveMember(archive, inner, read)
+	}
+	if err != nil {
+		preview.note = "Cannot read: " + errorReason(err)
+		return preview
+	}
+	if len(data) == 0 {
+		preview.note = "Empty file"
+		return preview
+	}
+
+	// A cut in the middle of a character would throw off detection, so end
+	// at the last whole line read
+	if len(data) == browserPreviewBytes {
+		if i := bytes.LastIndexByte(data, '\n'); i > 0 {
+			data = data[:i]
+		}
+	}
+	detection := enc.Detect(data)
+	if detection.Binary {
+		preview.note = "Binary file"
+		return preview
+	}
+	text, err := enc.DecodeToUTF8(data, detection.Encoding)
+	if err != nil {
+		preview.note = "Cannot decode as " + detection.Encoding.Name
+		return preview
+	}
+	lines := strings.Split(strings.ReplaceAll(string(text), "\r\n", "\n"), "\n")
+	preview.lines = lines[:min(len(lines), browserPreviewLines)]
+	return preview
+}
+
+// errorReason returns the part of an OS error that says what went wrong,
+// without the path repeated
+func errorReason(err error) string {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// browserPreviewRows renders the preview as height rows of exactly width
+// cells, or nil when there is no room for it. Tabs become spaces and
+// control characters are dropped, so a file can't send escape sequences
+// to the terminal.
+func (e *Editor) browserPreviewRows(width, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	preview := e.selectedBrowserPreview()
+	rows := make([]string, height)
+	lines := preview.lines
+	if preview.note != "" {
+		lines = []string{"", "  " + preview.note}
+	}
+	tab := strings.Repeat(" ", e.fileSettings().TabWidth)
+	for i := range rows {
+		text := ""
+		if i < len(lines) {
+			text = runewidth.Truncate(inputText(strings.ReplaceAll(lines[i], "\t", tab)), width-1, e.box.Ellipsis)
+			text = " " + text
+		}
+		rows[i] = text + strings.Repeat(" ", max(width-runewidth.StringWidth(text), 0))
+	}
+	if preview.note != "" && len(rows) > 1 {
+		rows[1] = "\033[2m" + rows[1] + "\033[22m"
+	}
+	return rows
+}
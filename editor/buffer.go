@@ -2,6 +2,7 @@ package editor
 
 import (
 	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 )
 
@@ -11,10 +12,15 @@ type Buffer struct {
 	data     []byte
 	gapStart int // Start of the gap (cursor position in logical text)
 	gapEnd   int // End of the gap (exclusive)
+	version  uint64
 }
 
 const initialGapSize = 1024
 
+// versions numbers every change to every buffer, so a version names one
+// text even across buffers
+var versions atomic.Uint64
+
 // NewBuffer creates a new empty buffer.
 func NewBuffer() *Buffer {
 	data := make([]byte, initialGapSize)
@@ -22,9 +28,16 @@ func NewBuffer() *Buffer {
 		data:     data,
 		gapStart: 0,
 		gapEnd:   initialGapSize,
+		version:  versions.Add(1),
 	}
 }
 
+// Version identifies the buffer's current text: it changes with every edit,
+// and no two buffers share one
+func (b *Buffer) Version() uint64 {
+	return b.version
+}
+
 // NewBufferFromString creates a buffer initialized with the given text.
 func NewBufferFromString(s string) *Buffer {
 	b := NewBuffer()
@@ -102,6 +115,7 @@ func (b *Buffer) Insert(s string) {
 	b.expandGap(len(s))
 	copy(b.data[b.gapStart:], s)
 	b.gapStart += len(s)
+	b.version = versions.Add(1)
 }
 
 // InsertRune inserts a single rune at the current cursor position.
@@ -111,6 +125,7 @@ func (b *Buffer) InsertRune(r rune) {
 	b.expandGap(n)
 	copy(b.data[b.gapStart:], buf[:n])
 	b.gapStart += n
+	b.version = versions.Add(1)
 }
 
 // DeleteBefore deletes n bytes before the cursor.
@@ -123,6 +138,7 @@ func (b *Buffer) DeleteBefore(n int) string {
 	}
 	deleted := string(b.data[b.gapStart-n : b.gapStart])
 	b.gapStart -= n
+	b.version = versions.Add(1)
 	return deleted
 }
 
@@ -137,6 +153,7 @@ func (b *Buffer) DeleteAfter(n int) string {
 	}
 	deleted := string(b.data[b.gapEnd : b.gapEnd+n])
 	b.gapEnd += n
+	b.version = versions.Add(1)
 	return deleted
 }
 
@@ -426,6 +443,21 @@ func (b *Buffer) WordCount() int {
 	return count
 }
 
+// countWords counts the words in s the way WordCount does the buffer's
+func countWords(s string) int {
+	count := 0
+	inWord := false
+	for _, r := range s {
+		if isWordSeparator(r) {
+			inWord = false
+		} else if !inWord {
+			count++
+			inWord = true
+		}
+	}
+	return count
+}
+
 // isWordSeparator returns true if the rune is whitespace (word separator).
 func isWordSeparator(r rune) bool {
 	// Consider whitespace as word separators
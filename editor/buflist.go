@@ -0,0 +1,177 @@
+package editor
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// bufferListWidth is the width of the Buffer List dialog
+const bufferListWidth = 60
+
+// bufferListPreviewLines is how many lines of the selected buffer are
+// previewed, starting just above its cursor
+const bufferListPreviewLines = 4
+
+// touchActiveBuffer marks the active buffer as the most recently used
+func (e *Editor) touchActiveBuffer() {
+	doc := e.activeDoc()
+	if doc.lastUsed == 0 || doc.lastUsed < e.bufferUseSeq {
+		e.bufferUseSeq++
+		doc.lastUsed = e.bufferUseSeq
+	}
+}
+
+// bufferListOrder returns the open buffers, most recently used first.
+// Buffers never switched to keep their tab order after the used ones.
+func (e *Editor) bufferListOrder() []*Document {
+	docs := slices.Clone(e.documents)
+	slices.SortStableFunc(docs, func(a, b *Document) int {
+		return b.lastUsed - a.lastUsed
+	})
+	return docs
+}
+
+// showBufferList opens the Buffer List dialog with the previous buffer
+// selected, so Enter flips between the two most recent
+func (e *Editor) showBufferList() {
+	e.touchActiveBuffer()
+	e.bufferListIndex = 0
+	if len(e.documents) > 1 {
+		e.bufferListIndex = 1
+	}
+	e.mode = ModeBufferList
+}
+
+// bufferListPreview returns a few lines of doc around its cursor, fitted to width
+func (e *Editor) bufferListPreview(doc *Document, width int) []string {
+	lines := doc.buffer.Lines()
+	start := max(doc.cursor.Line()-1, 0)
+	end := min(start+bufferListPreviewLines, len(lines))
+	var preview []string
+	for _, line := range lines[start:end] {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		preview = append(preview, runewidth.Truncate(line, width, e.box.Ellipsis))
+	}
+	return preview
+}
+
+// bufferListDialog builds the Buffer List dialog
+func (e *Editor) bufferListDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(bufferListWidth)
+	db.AddTitleBorder(" Buffers ")
+	db.AddEmptyLine()
+	docs := e.bufferListOrder()
+	active := e.activeDoc()
+	for i, doc := range docs {
+		marker := "   "
+		if doc == active {
+			marker = " • "
+		}
+		label := insertBufferLabel(doc, e.bufferName(doc), db.InnerWidth()-runewidth.StringWidth(marker)-1, e.box.Ellipsis)
+		db.AddSelectableItem(marker+label, i == e.bufferListIndex)
+	}
+	db.AddSeparator()
+	preview := []string{"  (empty)"}
+	if e.bufferListIndex < len(docs) && docs[e.bufferListIndex].buffer.Length() > 0 {
+		preview = e.bufferListPreview(docs[e.bufferListIndex], db.InnerWidth()-2)
+		for i := range preview {
+			preview[i] = " " + preview[i]
+		}
+	}
+	for i := range bufferListPreviewLines {
+		if i < len(preview) {
+			db.AddText(preview[i])
+		} else {
+			db.AddEmptyLine()
+		}
+	}
+	db.AddSeparator()
+	db.AddCenteredText("[Enter] Switch  [Del] Close  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayBufferListDialog overlays the Buffer List dialog centered on the viewport
+func (e *Editor) overlayBufferListDialog(viewportContent string) string {
+	return e.bufferListDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// bufferListSwitch closes the dialog and switches to the chosen buffer
+func (e *Editor) bufferListSwitch(index int) {
+	e.mode = ModeNormal
+	docs := e.bufferListOrder()
+	if index < 0 || index >= len(docs) {
+		return
+	}
+	e.switchToBuffer(slices.Index(e.documents, docs[index]))
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// bufferListClose closes the chosen buffer and keeps the dialog open on the
+// rest. A buffer with unsaved changes is confirmed first.
+func (e *Editor) bufferListClose(index int) {
+	docs := e.bufferListOrder()
+	if index < 0 || index >= len(docs) {
+		return
+	}
+	target, current := docs[index], e.activeDoc()
+	name := e.bufferName(target)
+	closeTarget := func() {
+		e.switchToBuffer(slices.Index(e.documents, target))
+		e.doCloseFile()
+		// Go back to the buffer that was being edited, unless that was closed
+		if i := slices.Index(e.documents, current); i >= 0 {
+			e.switchToBuffer(i)
+		}
+		e.statusbar.SetMessage("Closed "+name, "info")
+		e.bufferListIndex = min(index, len(e.documents)-1)
+	}
+	e.confirmUnsaved("Close", "Save them before closing?", []*Document{target}, func() tea.Cmd {
+		closeTarget()
+		return nil
+	})
+}
+
+// handleBufferListKey handles key events in the Buffer List dialog
+func (e *Editor) handleBufferListKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.bufferListIndex > 0 {
+			e.bufferListIndex--
+		}
+	case tea.KeyDown:
+		if e.bufferListIndex < len(e.documents)-1 {
+			e.bufferListIndex++
+		}
+	case tea.KeyHome:
+		e.bufferListIndex = 0
+	case tea.KeyEnd:
+		e.bufferListIndex = len(e.documents) - 1
+	case tea.KeyEnter:
+		e.bufferListSwitch(e.bufferListIndex)
+	case tea.KeyDelete:
+		e.bufferListClose(e.bufferListIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyRunes:
+		// 1-9 switch to the matching buffer directly
+		if len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+			e.bufferListSwitch(int(msg.Runes[0] - '1'))
+		}
+	}
+	return e, nil
+}
+
+// handleBufferListMouse selects buffers on click and switches on a second click
+func (e *Editor) handleBufferListMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.bufferListDialog().listMouse(msg, &e.bufferListIndex) {
+	case listChoose:
+		e.bufferListSwitch(e.bufferListIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
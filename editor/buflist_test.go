@@ -0,0 +1,83 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bufferListNames lists the buffers in the Buffer List's order
+func bufferListNames(e *Editor) string {
+	var names []string
+	for _, doc := range e.bufferListOrder() {
+		names = append(names, e.bufferName(doc))
+	}
+	return strings.Join(names, " ")
+}
+
+func TestBufferList(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("contents of "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := e.LoadFile(filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+		e.Update(nil)
+	}
+	e.switchToBuffer(0)
+	e.Update(nil)
+	if got := bufferListNames(e); got != "a.txt c.txt b.txt" {
+		t.Fatalf("order %q, want most recently used first", got)
+	}
+
+	// The list opens on the previous buffer, so Enter flips back to it
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if e.mode != ModeBufferList || e.bufferListIndex != 1 {
+		t.Fatalf("Ctrl+E should open the list on the previous buffer")
+	}
+	if !strings.Contains(strings.Join(e.bufferListDialog().Lines(), "\n"), "contents of c.txt") {
+		t.Errorf("preview should show the selected buffer")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModeNormal || e.bufferName(e.activeDoc()) != "c.txt" {
+		t.Errorf("Enter should switch to c.txt, in %s", e.bufferName(e.activeDoc()))
+	}
+}
+
+func TestBufferListClose(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+		e.Update(nil)
+	}
+	e.documents[0].modified = true
+
+	// Closing a modified buffer asks first and returns to the list
+	e.showBufferList()
+	e.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if e.mode != ModeConfirm || len(e.documents) != 2 {
+		t.Fatalf("closing unsaved a.txt should ask first")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if e.mode != ModeBufferList || len(e.documents) != 1 || e.bufferName(e.activeDoc()) != "b.txt" {
+		t.Errorf("after discarding: mode %v, %d buffers, active %s", e.mode, len(e.documents), e.bufferName(e.activeDoc()))
+	}
+}
@@ -0,0 +1,85 @@
+package editor
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// uniqueSuffixes labels each path by its base name, adding parent
+// directories to paths whose labels would otherwise collide
+// ("a/config.toml" and "b/config.toml"). Empty paths stay empty.
+func uniqueSuffixes(paths []string) []string {
+	parts := make([][]string, len(paths))
+	depth := make([]int, len(paths))
+	for i, p := range paths {
+		if p != "" {
+			parts[i] = strings.Split(filepath.Clean(p), string(filepath.Separator))
+			depth[i] = 1
+		}
+	}
+	label := func(i int) string {
+		d := min(depth[i], len(parts[i]))
+		return filepath.Join(parts[i][len(parts[i])-d:]...)
+	}
+
+	for {
+		seen := make(map[string][]int)
+		for i := range paths {
+			if paths[i] != "" {
+				seen[label(i)] = append(seen[label(i)], i)
+			}
+		}
+		grew := false
+		for _, group := range seen {
+			if len(group) < 2 {
+				continue
+			}
+			for _, i := range group {
+				if depth[i] < len(parts[i]) {
+					depth[i]++
+					grew = true
+				}
+			}
+		}
+		if !grew {
+			break
+		}
+	}
+
+	labels := make([]string, len(paths))
+	for i := range paths {
+		if paths[i] != "" {
+			labels[i] = label(i)
+		}
+	}
+	return labels
+}
+
+// bufferNames returns the display name of every open buffer, with parent
+// directories added where base names are shared
+func (e *Editor) bufferNames() []string {
+	paths := make([]string, len(e.documents))
+	for i, doc := range e.documents {
+		paths[i] = doc.filename
+	}
+	names := uniqueSuffixes(paths)
+	for i, name := range names {
+		if name == "" {
+			names[i] = "[Untitled]"
+		}
+	}
+	return names
+}
+
+// bufferName returns the display name of doc
+func (e *Editor) bufferName(doc *Document) string {
+	for i, d := range e.documents {
+		if d == doc {
+			return e.bufferNames()[i]
+		}
+	}
+	if doc.filename == "" {
+		return "[Untitled]"
+	}
+	return filepath.Base(doc.filename)
+}
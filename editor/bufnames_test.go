@@ -0,0 +1,50 @@
+package editor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUniqueSuffixes(t *testing.T) {
+	p := filepath.FromSlash
+	tests := []struct {
+		paths []string
+		want  []string
+	}{
+		{
+			[]string{p("/a/notes.txt"), p("/b/todo.txt"), ""},
+			[]string{"notes.txt", "todo.txt", ""},
+		},
+		{
+			[]string{p("/home/me/dirA/config.toml"), p("/home/me/dirB/config.toml"), p("/home/me/main.go")},
+			[]string{p("dirA/config.toml"), p("dirB/config.toml"), "main.go"},
+		},
+		{
+			// Shared parents grow until the labels differ
+			[]string{p("/x/src/app/main.go"), p("/y/src/app/main.go"), p("/y/src/main.go")},
+			[]string{p("x/src/app/main.go"), p("y/src/app/main.go"), p("src/main.go")},
+		},
+	}
+	for _, tt := range tests {
+		if got := uniqueSuffixes(tt.paths); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("uniqueSuffixes(%q) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestBufferNames(t *testing.T) {
+	e := New()
+	e.activeDoc().filename = filepath.FromSlash("/srv/web/config.toml")
+	e.newFile()
+	e.activeDoc().filename = filepath.FromSlash("/srv/api/config.toml")
+	e.newFile()
+
+	want := []string{filepath.FromSlash("web/config.toml"), filepath.FromSlash("api/config.toml"), "[Untitled]"}
+	if got := e.bufferNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("bufferNames() = %q, want %q", got, want)
+	}
+	if got := e.bufferName(e.documents[1]); got != want[1] {
+		t.Errorf("bufferName() = %q, want %q", got, want[1])
+	}
+}
@@ -0,0 +1,60 @@
+package editor
+
+// markSaved snapshots the buffer as the baseline for unsaved-change markers
+func (d *Document) markSaved() {
+	d.savedLines = d.buffer.Lines()
+}
+
+// lineChanges returns the active document's lines edited since the last save,
+// for the scrollbar and minimap markers, or nil when there is nothing to mark
+func (e *Editor) lineChanges(lines []string) []bool {
+	doc := e.activeDoc()
+	if !doc.modified || (!e.scrollbar.IsEnabled() && !e.minimapRenderer.IsEnabled()) {
+		return nil
+	}
+	saved := doc.savedLines
+	if saved == nil {
+		saved = []string{""} // New buffers start out empty
+	}
+	return changedLines(saved, lines)
+}
+
+// changedLines marks the lines of current that differ from saved. Matching
+// lines at the start and end are skipped; in between, a line only counts as
+// changed if the same stretch of the saved text has no identical line, so two
+// far-apart edits don't mark everything between them. Returns nil if nothing
+// changed.
+func changedLines(saved, current []string) []bool {
+	prefix := 0
+	for prefix < len(saved) && prefix < len(current) && saved[prefix] == current[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(saved)-prefix && suffix < len(current)-prefix &&
+		saved[len(saved)-1-suffix] == current[len(current)-1-suffix] {
+		suffix++
+	}
+	if prefix+suffix == len(current) {
+		// Only deletions (or nothing): mark the line the text closed up on
+		if prefix+suffix == len(saved) {
+			return nil
+		}
+		changed := make([]bool, len(current))
+		changed[min(prefix, len(current)-1)] = true
+		return changed
+	}
+
+	remaining := make(map[string]int)
+	for _, line := range saved[prefix : len(saved)-suffix] {
+		remaining[line]++
+	}
+	changed := make([]bool, len(current))
+	for i := prefix; i < len(current)-suffix; i++ {
+		if remaining[current[i]] > 0 {
+			remaining[current[i]]--
+			continue
+		}
+		changed[i] = true
+	}
+	return changed
+}
@@ -0,0 +1,59 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedLines(t *testing.T) {
+	saved := []string{"a", "b", "c", "d", "e"}
+	tests := []struct {
+		name    string
+		current []string
+		want    []bool
+	}{
+		{"unchanged", []string{"a", "b", "c", "d", "e"}, nil},
+		{"edited line", []string{"a", "B", "c", "d", "e"}, []bool{false, true, false, false, false}},
+		{"two edits far apart", []string{"A", "b", "c", "d", "E"}, []bool{true, false, false, false, true}},
+		{"inserted line", []string{"a", "b", "x", "c", "d", "e"}, []bool{false, false, true, false, false, false}},
+		{"deleted line", []string{"a", "b", "d", "e"}, []bool{false, false, true, false}},
+		{"deleted tail", []string{"a", "b"}, []bool{false, true}},
+	}
+	for _, tt := range tests {
+		if got := changedLines(saved, tt.current); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: changedLines() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLineChangesClearedOnSave(t *testing.T) {
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	e := New()
+	e.scrollbar.SetEnabled(true)
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("one\ntwo")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.filename = filepath.Join(t.TempDir(), "out.txt")
+	doc.markSaved()
+
+	doc.buffer.Replace(4, 7, "TWO")
+	doc.modified = true
+	if got := e.lineChanges(doc.buffer.Lines()); !reflect.DeepEqual(got, []bool{false, true}) {
+		t.Fatalf("lineChanges() = %v, want line 2 marked", got)
+	}
+
+	if !e.doSave() {
+		t.Fatal("save failed")
+	}
+	if got := e.lineChanges(doc.buffer.Lines()); got != nil {
+		t.Errorf("lineChanges() after save = %v, want nil", got)
+	}
+}
@@ -0,0 +1,99 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// pathCompletion is Tab completion in progress on a path input
+type pathCompletion struct {
+	matches []string // Completed inputs, in directory order
+	index   int      // Match shown by the last Tab (-1 = the common prefix)
+	shown   string   // Input as the last Tab left it
+}
+
+// completePath completes the path typed in input, relative to dir, the way
+// a shell does. The first Tab extends it as far as the matching names
+// agree; once it can't go further, each Tab shows the next match in turn.
+// Directories end in a separator, so the next Tab continues inside them.
+// It reports false when nothing matches.
+func (e *Editor) completePath(input, dir string) (string, bool) {
+	if c := e.completion; c != nil && input == c.shown && len(c.matches) > 1 {
+		c.index = (c.index + 1) % len(c.matches)
+		c.shown = c.matches[c.index]
+		return c.shown, true
+	}
+
+	matches := pathMatches(input, dir)
+	if len(matches) == 0 {
+		e.completion = nil
+		return input, false
+	}
+	c := &pathCompletion{matches: matches, index: -1, shown: commonPrefix(matches)}
+	if c.shown == input && len(matches) > 1 {
+		c.index, c.shown = 0, matches[0]
+	}
+	e.completion = c
+	if len(matches) > 1 {
+		names := make([]string, len(matches))
+		for i, m := range matches {
+			names[i] = filepath.Base(m)
+			if strings.HasSuffix(m, string(filepath.Separator)) {
+				names[i] += string(filepath.Separator)
+			}
+		}
+		e.statusbar.SetMessage(strings.Join(names, "  "), "info")
+	}
+	return c.shown, true
+}
+
+// pathMatches lists the completions of input: the entries of the directory
+// it names whose names start with its last part. Hidden entries are left
+// out unless that part starts with a dot.
+func pathMatches(input, dir string) []string {
+	typedDir, prefix := filepath.Split(input)
+	searchDir := typedDir
+	if !filepath.IsAbs(searchDir) {
+		searchDir = filepath.Join(dir, typedDir)
+	}
+	if searchDir == "" {
+		searchDir = "."
+	}
+	entries, err := os.ReadDir(searchDir)
+	if err != nil {
+		return nil
+	}
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		match := typedDir + name
+		// Stat follows symlinks, so a link to a directory completes like one
+		if info, err := os.Stat(filepath.Join(searchDir, name)); err == nil && info.IsDir() {
+			match += string(filepath.Separator)
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// commonPrefix returns the longest prefix all of strs share, cut back to
+// whole characters
+func commonPrefix(strs []string) string {
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		n := 0
+		for n < len(prefix) && n < len(s) && prefix[n] == s[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompletePath(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "alpha.txt"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "alphabet.txt"), nil, 0644)
+	os.Mkdir(filepath.Join(dir, "alpine"), 0755)
+	os.WriteFile(filepath.Join(dir, "alpine", "peak.md"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, ".alps"), nil, 0644)
+
+	e := New()
+	steps := []struct{ input, want string }{
+		{"al", "alp"},                 // As far as the names agree
+		{"alp", "alpha.txt"},          // Then cycle through them
+		{"alpha.txt", "alphabet.txt"}, // ...
+		{"alphabet.txt", "alpine/"},
+		{"alpine/", "alpha.txt"}, // Around to the first again
+	}
+	for _, s := range steps {
+		got, ok := e.completePath(s.input, dir)
+		if !ok || got != s.want {
+			t.Errorf("complete %q = %q, %v; want %q", s.input, got, ok, s.want)
+		}
+	}
+
+	e.completion = nil
+	if got, _ := e.completePath("alpi", dir); got != "alpine/" {
+		t.Errorf("a single directory completes with a separator, got %q", got)
+	}
+	if got, _ := e.completePath("alpine/", dir); got != "alpine/peak.md" {
+		t.Errorf("Tab after a directory completes inside it, got %q", got)
+	}
+	if got, _ := e.completePath(".al", dir); got != ".alps" {
+		t.Errorf("a leading dot completes hidden names, got %q", got)
+	}
+	if _, ok := e.completePath("zzz", dir); ok {
+		t.Error("nothing should match zzz")
+	}
+}
+
+func TestSaveAsTabCompletes(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644)
+
+	e := New()
+	e.showSaveAs()
+	e.fileBrowserDir = dir
+	e.saveAsFilename = "no"
+	e.handleSaveAsKey(tea.KeyMsg{Type: tea.KeyTab})
+	if e.saveAsFilename != "notes.txt" || e.saveAsFocusBrowser {
+		t.Fatalf("Tab should complete the name, got %q (browser focus %v)", e.saveAsFilename, e.saveAsFocusBrowser)
+	}
+	e.handleSaveAsKey(tea.KeyMsg{Type: tea.KeyTab})
+	if !e.saveAsFocusBrowser {
+		t.Error("Tab with nothing left to complete should move to the file list")
+	}
+}
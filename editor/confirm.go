@@ -0,0 +1,176 @@
+package editor
+
+import (
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// ConfirmButton is one choice in a confirm dialog
+type ConfirmButton struct {
+	Label  string // Button text, e.g. "Discard"
+	Hotkey rune   // Lowercase key that chooses this button directly
+	Danger bool   // Destructive choice, drawn in the error color
+}
+
+// ConfirmDialog is a modal question answered with buttons instead of typed y/N.
+// Focus starts on Default, which should be the safe choice for destructive questions.
+type ConfirmDialog struct {
+	Title    string
+	Message  string
+	Buttons  []ConfirmButton
+	Default  int                      // Button focused when the dialog opens
+	Cancel   int                      // Button chosen by Escape or clicking outside
+	OnChoose func(choice int) tea.Cmd // Called with the chosen button index
+	Saving   bool                     // Asked in the middle of a save (shown in the status bar)
+	selected int                      // Currently focused button
+	previous Mode                     // Mode to return to when the dialog closes
+}
+
+// confirmMinWidth is the narrowest a confirm dialog is drawn
+const confirmMinWidth = 40
+
+// showConfirm opens a confirm dialog on top of the current mode
+func (e *Editor) showConfirm(d *ConfirmDialog) {
+	d.selected = d.Default
+	d.previous = e.mode
+	if d.previous == ModeConfirm || d.previous == ModePrompt {
+		d.previous = ModeNormal
+	}
+	e.confirm = d
+	e.mode = ModeConfirm
+	e.updateViewportSize()
+}
+
+// confirmDiscard asks before a destructive action on unsaved changes.
+// Cancel is focused so a reflexive Enter never loses work.
+func (e *Editor) confirmDiscard(title, message, action string, hotkey rune, onConfirm func() tea.Cmd) {
+	e.showConfirm(&ConfirmDialog{
+		Title:   title,
+		Message: message,
+		Buttons: []ConfirmButton{
+			{Label: action, Hotkey: hotkey, Danger: true},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 1,
+		Cancel:  1,
+		OnChoose: func(choice int) tea.Cmd {
+			if choice == 0 {
+				return onConfirm()
+			}
+			e.statusbar.SetMessage("Cancelled", "info")
+			return nil
+		},
+	})
+}
+
+// confirmOverwrite asks before saving the active buffer over an existing file
+func (e *Editor) confirmOverwrite(filename string) {
+	e.confirmDiscard("Overwrite", filepath.Base(filename)+" already exists.\nReplace it?", "Overwrite", 'o', func() tea.Cmd {
+		e.activeDoc().filename = filename
+		e.mode = ModeNormal
+		e.doSave()
+		return nil
+	})
+}
+
+// chooseConfirm closes the dialog and runs its callback
+func (e *Editor) chooseConfirm(choice int) (tea.Model, tea.Cmd) {
+	d := e.confirm
+	e.confirm = nil
+	e.mode = d.previous
+	if d.OnChoose == nil {
+		return e, nil
+	}
+	return e, d.OnChoose(choice)
+}
+
+// handleConfirmKey handles keyboard input in the confirm dialog
+func (e *Editor) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := e.confirm
+	if d == nil {
+		e.mode = ModeNormal
+		return e, nil
+	}
+	switch msg.Type {
+	case tea.KeyLeft, tea.KeyShiftTab:
+		d.selected = (d.selected - 1 + len(d.Buttons)) % len(d.Buttons)
+	case tea.KeyRight, tea.KeyTab:
+		d.selected = (d.selected + 1) % len(d.Buttons)
+	case tea.KeyEnter:
+		return e.chooseConfirm(d.selected)
+	case tea.KeyEsc:
+		return e.chooseConfirm(d.Cancel)
+	case tea.KeyRunes:
+		if len(msg.Runes) == 1 {
+			key := []rune(strings.ToLower(string(msg.Runes)))[0]
+			for i, btn := range d.Buttons {
+				if btn.Hotkey == key {
+					return e.chooseConfirm(i)
+				}
+			}
+		}
+	}
+	return e, nil
+}
+
+// confirmDialog builds the confirm dialog; its buttons are its only
+// widgets, so their ids are the button indexes
+func (e *Editor) confirmDialog() *DialogBuilder {
+	d := e.confirm
+	lines := strings.Split(d.Message, "\n")
+
+	buttons := make([]dialogButton, len(d.Buttons))
+	labels := make([]string, len(d.Buttons))
+	for i, btn := range d.Buttons {
+		buttons[i] = dialogButton{label: btn.Label, danger: btn.Danger}
+		labels[i] = "[ " + btn.Label + " ]"
+	}
+
+	boxWidth := confirmMinWidth
+	for _, line := range append(lines, d.Title, strings.Repeat(" ", buttonRowWidth(labels))) {
+		if w := runewidth.StringWidth(line) + 6; w > boxWidth {
+			boxWidth = w
+		}
+	}
+	if boxWidth > e.width-2 && e.width > 10 {
+		boxWidth = e.width - 2
+	}
+
+	db := e.NewDialogBuilder(boxWidth)
+	db.AddTitleBorder(" " + d.Title + " ")
+	db.AddEmptyLine()
+	for _, line := range lines {
+		db.AddCenteredText(line)
+	}
+	db.AddEmptyLine()
+	db.SetFocus(d.selected)
+	db.AddButtons(buttons...)
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayConfirmDialog overlays the confirm dialog
+func (e *Editor) overlayConfirmDialog(viewportContent string) string {
+	if e.confirm == nil {
+		return viewportContent
+	}
+	return e.confirmDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// handleConfirmMouse handles mouse clicks on the confirm dialog buttons
+func (e *Editor) handleConfirmMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if e.confirm == nil || msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return e, nil
+	}
+	hit := e.confirmDialog().HitTest(msg.X, msg.Y-1)
+	if !hit.inside {
+		return e.chooseConfirm(e.confirm.Cancel)
+	}
+	if hit.kind == widgetButton && hit.index >= 0 {
+		return e.chooseConfirm(hit.index)
+	}
+	return e, nil
+}
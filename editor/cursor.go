@@ -13,18 +13,38 @@ type Position struct {
 
 // Cursor manages the cursor position within a buffer.
 type Cursor struct {
-	buf *Buffer
-	pos int // Byte offset in the buffer
+	buf     *Buffer
+	pos     int // Byte offset in the buffer
+	goalCol int // Screen column a run of vertical moves aims for
+	goalPos int // Offset the last vertical move left the cursor at (-1 = no goal)
+	goalLen int // Buffer length when the goal was set, so edits drop it
 }
 
 // NewCursor creates a new cursor for the given buffer.
 func NewCursor(buf *Buffer) *Cursor {
 	return &Cursor{
-		buf: buf,
-		pos: 0,
+		buf:     buf,
+		pos:     0,
+		goalPos: -1,
 	}
 }
 
+// DesiredCol returns the screen column that Up and Down aim for, so the
+// cursor gets back to it after passing through shorter lines. There is
+// none once the cursor has moved any other way, or the text has been
+// edited, since SetDesiredCol.
+func (c *Cursor) DesiredCol() (int, bool) {
+	return c.goalCol, c.goalPos == c.pos && c.goalLen == c.buf.Length()
+}
+
+// SetDesiredCol remembers col as the desired column at the cursor's
+// current position
+func (c *Cursor) SetDesiredCol(col int) {
+	c.goalCol = col
+	c.goalPos = c.pos
+	c.goalLen = c.buf.Length()
+}
+
 // Position returns the current cursor position as line and column.
 func (c *Cursor) Position() Position {
 	line, col := c.buf.PositionToLineCol(c.pos)
@@ -54,26 +74,21 @@ func (c *Cursor) SetPosition(line, col int) {
 	c.buf.MoveCursor(c.pos)
 }
 
-// MoveLeft moves the cursor left by one character (rune).
+// MoveLeft moves the cursor left by one character (grapheme cluster), so
+// an accented letter or an emoji sequence is stepped over whole.
 func (c *Cursor) MoveLeft() bool {
-	if c.pos == 0 {
+	size := c.buf.graphemeBefore(c.pos)
+	if size == 0 {
 		return false
 	}
-	// Move back through UTF-8 bytes to find the previous rune start
-	c.pos--
-	for c.pos > 0 && !utf8.RuneStart(c.buf.ByteAt(c.pos)) {
-		c.pos--
-	}
+	c.pos -= size
 	c.buf.MoveCursor(c.pos)
 	return true
 }
 
-// MoveRight moves the cursor right by one character (rune).
+// MoveRight moves the cursor right by one character (grapheme cluster).
 func (c *Cursor) MoveRight() bool {
-	if c.pos >= c.buf.Length() {
-		return false
-	}
-	_, size := c.buf.RuneAt(c.pos)
+	size := c.buf.graphemeAfter(c.pos)
 	if size == 0 {
 		return false
 	}
@@ -88,7 +103,7 @@ func (c *Cursor) MoveUp() bool {
 	if line == 0 {
 		return false
 	}
-	c.pos = c.buf.LineColToPosition(line-1, col)
+	c.pos = c.buf.graphemeStart(c.buf.LineColToPosition(line-1, col))
 	c.buf.MoveCursor(c.pos)
 	return true
 }
@@ -99,7 +114,7 @@ func (c *Cursor) MoveDown() bool {
 	if line >= c.buf.LineCount()-1 {
 		return false
 	}
-	c.pos = c.buf.LineColToPosition(line+1, col)
+	c.pos = c.buf.graphemeStart(c.buf.LineColToPosition(line+1, col))
 	c.buf.MoveCursor(c.pos)
 	return true
 }
@@ -0,0 +1,119 @@
+package editor
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// debugLogMax is the size past which a debug log is moved aside to
+// name.old when a session starts
+const debugLogMax = 4 << 20
+
+// slowRender is the render time past which a render is logged: a frame at
+// 60 Hz
+const slowRender = 16 * time.Millisecond
+
+// EnableDebugLog writes structured logs for this session to path, one JSON
+// object per line: key presses, mode changes, slow renders, error messages
+// and panics. Each record is written straight to the file, so a crash
+// loses nothing already logged. Typed text is logged only by its length.
+func (e *Editor) EnableDebugLog(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > debugLogMax {
+		os.Rename(path, path+".old")
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	e.debugLogPath = path
+	e.debugLogFile = f
+	e.debugLog = slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	e.debugLog.Info("session start", "pid", os.Getpid(), "term", os.Getenv("TERM"),
+		"colorterm", os.Getenv("COLORTERM"), "term_program", os.Getenv("TERM_PROGRAM"))
+	return nil
+}
+
+// logUpdate records what a message did in the debug log: the key pressed,
+// a change of mode and any error or warning shown. mode and message are as
+// they were before the message.
+func (e *Editor) logUpdate(msg tea.Msg, mode Mode, message string) {
+	if e.debugLog == nil {
+		return
+	}
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		e.debugLog.Debug("key", "key", debugKeyName(msg), "mode", int(mode))
+	case tea.WindowSizeMsg:
+		e.debugLog.Info("resize", "width", msg.Width, "height", msg.Height)
+	case tea.FocusMsg:
+		e.debugLog.Debug("focus", "focused", true)
+	case tea.BlurMsg:
+		e.debugLog.Debug("focus", "focused", false)
+	}
+	if e.mode != mode {
+		e.debugLog.Debug("mode", "from", int(mode), "to", int(e.mode))
+	}
+	if text, kind := e.statusbar.Message(); text != message {
+		switch kind {
+		case "error":
+			e.debugLog.Error("message", "text", text)
+		case "warning":
+			e.debugLog.Warn("message", "text", text)
+		}
+	}
+}
+
+// logRender records a render that took longer than slowRender
+func (e *Editor) logRender(start time.Time) {
+	if d := time.Since(start); e.debugLog != nil && d >= slowRender {
+		e.debugLog.Warn("slow render", "ms", d.Milliseconds(), "width", e.width, "height", e.height,
+			"lines", e.activeDoc().buffer.LineCount())
+	}
+}
+
+// logPanic records a panic and its stack, syncing the log so it survives
+// the crash
+func (e *Editor) logPanic(r any) {
+	if e.debugLog == nil {
+		return
+	}
+	e.debugLog.Error("panic", "value", fmt.Sprint(r), "stack", string(debug.Stack()))
+	e.debugLogFile.Sync()
+}
+
+// debugKeyName names a key press for the log, keeping typed and pasted
+// text out of it
+func debugKeyName(msg tea.KeyMsg) string {
+	if msg.Type == tea.KeyRunes && !msg.Alt {
+		if msg.Paste {
+			return fmt.Sprintf("paste(%d)", len(msg.Runes))
+		}
+		return fmt.Sprintf("text(%d)", len(msg.Runes))
+	}
+	return msg.String()
+}
+
+// viewDebugLog opens the debug log read-only, following it as it grows
+func (e *Editor) viewDebugLog() tea.Cmd {
+	if e.debugLogPath == "" {
+		e.statusbar.SetMessage("Debug logging is off - start textivus with --debug", "info")
+		return nil
+	}
+	if err := e.LoadFile(e.debugLogPath); err != nil {
+		e.statusbar.SetMessage("Cannot open the debug log: "+errorReason(err), "error")
+		return nil
+	}
+	if e.activeDoc().follow {
+		return nil
+	}
+	return e.toggleFollow()
+}
@@ -0,0 +1,62 @@
+package editor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDebugLog(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	if e.viewDebugLog() != nil || e.activeDoc().filename != "" {
+		t.Errorf("View Log should do nothing without --debug")
+	}
+	path := filepath.Join(t.TempDir(), "logs", "debug.log")
+	if err := e.EnableDebugLog(path); err != nil {
+		t.Fatal(err)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("secret")})
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	e.statusbar.SetMessage("Something failed", "error")
+	e.logUpdate(nil, e.mode, "")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("typed text should not be logged:\n%s", data)
+	}
+	var msgs []string
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		msgs = append(msgs, record["msg"].(string))
+		if key, ok := record["key"].(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	got := strings.Join(msgs, ",")
+	if want := "session start,key,key,mode,key,mode,message"; got != want {
+		t.Errorf("logged %s, want %s", got, want)
+	}
+	if got := strings.Join(keys, ","); got != "text(6),ctrl+f,esc" {
+		t.Errorf("keys logged as %s", got)
+	}
+
+	// View Log opens the log read-only, following it
+	e.viewDebugLog()
+	if doc := e.activeDoc(); doc.filename != path || !doc.follow {
+		t.Errorf("View Log should follow the log, got %q follow=%v", doc.filename, doc.follow)
+	}
+}
@@ -13,38 +13,57 @@ type DialogBuilder struct {
 	width      int      // Total box width including borders
 	innerWidth int      // Width inside borders
 	lines      []string // Built dialog lines
+	focus      int      // Line kept visible when the dialog is taller than the viewport (-1 = none)
+	scroll     int      // Body lines to scroll by when nothing has focus
+	focused    int      // Id of the focused widget (-1 = none)
+	widgets    []dialogWidget
+	viewWidth  int // Viewport size the dialog is centered in, for hit testing
+	viewHeight int
 	themeUI    *themeColors
 }
 
 // themeColors holds the resolved theme color escape codes
 type themeColors struct {
-	dialogStyle      string // Base dialog fg/bg
-	selectedStyle    string // Selected item fg/bg
-	dialogResetStyle string // Reset to dialog colors after selection
-	resetStyle       string // Full reset
+	dialogStyle         string // Base dialog fg/bg
+	selectedStyle       string // Selected item fg/bg
+	dangerStyle         string // Destructive button fg
+	dangerSelectedStyle string // Focused destructive button fg/bg
+	dialogResetStyle    string // Reset to dialog colors after selection
+	resetStyle          string // Full reset
 }
 
 // NewDialogBuilder creates a new dialog builder
 func (e *Editor) NewDialogBuilder(width int) *DialogBuilder {
 	themeUI := e.styles.Theme.UI
+	// Never draw wider than the terminal; text is truncated to fit instead
+	if e.width > 0 && width > e.width {
+		width = max(e.width, 4)
+	}
 	return &DialogBuilder{
 		box:        e.box,
 		width:      width,
 		innerWidth: width - 2,
 		lines:      make([]string, 0),
+		focus:      -1,
+		focused:    -1,
+		viewWidth:  e.width,
+		viewHeight: e.viewport.Height(),
 		themeUI: &themeColors{
-			dialogStyle:      ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg),
-			selectedStyle:    ui.ColorToANSI(themeUI.DialogButtonFg, themeUI.DialogButton),
-			dialogResetStyle: ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg),
-			resetStyle:       "\033[0m",
+			dialogStyle:         ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg),
+			selectedStyle:       ui.ColorToANSI(themeUI.DialogButtonFg, themeUI.DialogButton),
+			dangerStyle:         ui.ColorToANSIFg(themeUI.ErrorFg),
+			dangerSelectedStyle: ui.ColorToANSI(themeUI.DialogBg, themeUI.ErrorFg),
+			dialogResetStyle:    ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg),
+			resetStyle:          "\033[0m",
 		},
 	}
 }
 
 // AddTitleBorder adds the top border with an embedded title
 func (db *DialogBuilder) AddTitleBorder(title string) {
-	titlePadLeft := (db.innerWidth - runewidth.StringWidth(title)) / 2
-	titlePadRight := db.innerWidth - runewidth.StringWidth(title) - titlePadLeft
+	title = runewidth.Truncate(title, max(db.innerWidth, 0), db.box.Ellipsis)
+	titlePadLeft := max((db.innerWidth-runewidth.StringWidth(title))/2, 0)
+	titlePadRight := max(db.innerWidth-runewidth.StringWidth(title)-titlePadLeft, 0)
 	line := db.box.TopLeft +
 		strings.Repeat(db.box.Horizontal, titlePadLeft) +
 		title +
@@ -73,15 +92,26 @@ func (db *DialogBuilder) AddCenteredText(text string) {
 	db.lines = append(db.lines, db.box.Vertical+db.CenterText(text)+db.box.Vertical)
 }
 
-// AddSelectableItem adds an item that can be selected (highlighted when selected)
-func (db *DialogBuilder) AddSelectableItem(text string, isSelected bool) {
-	var line string
-	if isSelected {
-		line = db.box.Vertical + db.themeUI.selectedStyle + db.PadText(text) + db.themeUI.dialogResetStyle + db.box.Vertical
-	} else {
-		line = db.box.Vertical + db.PadText(text) + db.box.Vertical
+// Focus marks the next line to be added as the one to keep visible
+// when the dialog has to scroll to fit the viewport
+func (db *DialogBuilder) Focus() {
+	db.focus = len(db.lines)
+}
+
+// Scroll scrolls the body of a dialog taller than the viewport down n
+// lines. It is for dialogs without a focused line, which scroll to keep
+// that line in view instead.
+func (db *DialogBuilder) Scroll(n int) {
+	db.scroll = n
+}
+
+// MaxScroll returns how far the body can scroll in the viewport, 0 when
+// the whole dialog fits
+func (db *DialogBuilder) MaxScroll() int {
+	if db.viewHeight < 3 {
+		return 0
 	}
-	db.lines = append(db.lines, line)
+	return max(len(db.lines)-db.viewHeight, 0)
 }
 
 // AddSeparator adds a horizontal separator line
@@ -124,20 +154,66 @@ func (db *DialogBuilder) Lines() []string {
 	return db.lines
 }
 
+// visibleLines returns the dialog lines that fit in the viewport height and the
+// number of body lines scrolled off the top. A dialog taller than the viewport
+// keeps its borders and shows a window of its body around the focused line.
+func (db *DialogBuilder) visibleLines(viewportHeight int) ([]string, int) {
+	n := len(db.lines)
+	if n <= viewportHeight || viewportHeight < 3 || n < 3 {
+		return db.lines, 0
+	}
+	bodyHeight := viewportHeight - 2
+	body := db.lines[1 : n-1]
+	scroll := max(db.scroll, 0)
+	if db.focus >= 0 {
+		scroll = 0
+		if focus := db.focus - 1; focus >= bodyHeight {
+			scroll = focus - bodyHeight + 1
+		}
+	}
+	if scroll > len(body)-bodyHeight {
+		scroll = len(body) - bodyHeight
+	}
+	top, bottom := db.lines[0], db.lines[n-1]
+	if scroll > 0 {
+		top = db.markBorder(top, db.box.MoreUp)
+	}
+	if scroll < len(body)-bodyHeight {
+		bottom = db.markBorder(bottom, db.box.MoreDown)
+	}
+	visible := make([]string, 0, viewportHeight)
+	visible = append(visible, top)
+	visible = append(visible, body[scroll:scroll+bodyHeight]...)
+	visible = append(visible, bottom)
+	return visible, scroll
+}
+
+// markBorder puts a scroll marker near the right end of a border line
+func (db *DialogBuilder) markBorder(line, marker string) string {
+	for _, corner := range []string{db.box.TopRight, db.box.BottomRight} {
+		end := db.box.Horizontal + db.box.Horizontal + corner
+		if strings.HasSuffix(line, end) {
+			return strings.TrimSuffix(line, end) + marker + db.box.Horizontal + corner
+		}
+	}
+	return line
+}
+
 // Overlay renders the dialog centered on the viewport content
 func (db *DialogBuilder) Overlay(viewportContent string, viewportWidth, viewportHeight int) string {
+	lines, _ := db.visibleLines(viewportHeight)
 	startX := (viewportWidth - db.width) / 2
 	if startX < 0 {
 		startX = 0
 	}
-	startY := (viewportHeight - len(db.lines)) / 2
+	startY := (viewportHeight - len(lines)) / 2
 	if startY < 0 {
 		startY = 0
 	}
 
 	viewportLines := strings.Split(viewportContent, "\n")
 
-	for i, dialogLine := range db.lines {
+	for i, dialogLine := range lines {
 		viewportY := startY + i
 		if viewportY >= 0 && viewportY < len(viewportLines) {
 			// Build the styled dialog line with theme colors
@@ -156,31 +232,30 @@ func (db *DialogBuilder) Overlay(viewportContent string, viewportWidth, viewport
 
 // DialogPosition calculates the dialog position for mouse handling
 type DialogPosition struct {
-	StartX    int
-	StartY    int
-	Width     int
-	Height    int
-	ListStart int // Y offset where selectable list starts (relative to dialog)
-	ListEnd   int // Y offset where selectable list ends
+	StartX int
+	StartY int
+	Width  int
+	Height int
+	Scroll int // Body lines scrolled off the top when the dialog is cropped
 }
 
 // GetPosition returns the dialog's position information for mouse handling
-func (db *DialogBuilder) GetPosition(viewportWidth, viewportHeight, listStart, listCount int) DialogPosition {
+func (db *DialogBuilder) GetPosition(viewportWidth, viewportHeight int) DialogPosition {
+	lines, scroll := db.visibleLines(viewportHeight)
 	startX := (viewportWidth - db.width) / 2
 	if startX < 0 {
 		startX = 0
 	}
-	startY := (viewportHeight - len(db.lines)) / 2
+	startY := (viewportHeight - len(lines)) / 2
 	if startY < 0 {
 		startY = 0
 	}
 	return DialogPosition{
-		StartX:    startX,
-		StartY:    startY,
-		Width:     db.width,
-		Height:    len(db.lines),
-		ListStart: listStart,
-		ListEnd:   listStart + listCount,
+		StartX: startX,
+		StartY: startY,
+		Width:  db.width,
+		Height: len(lines),
+		Scroll: scroll,
 	}
 }
 
@@ -190,14 +265,9 @@ func (dp DialogPosition) MouseInDialog(mouseX, mouseY int) (bool, int, int) {
 	relX := mouseX - dp.StartX
 	relY := mouseY - dp.StartY
 	inside := relX >= 0 && relX < dp.Width && relY >= 0 && relY < dp.Height
-	return inside, relX, relY
-}
-
-// MouseInList checks if mouse Y is in the selectable list area
-// Returns the list index or -1 if not in list
-func (dp DialogPosition) MouseInList(relY int) int {
-	if relY >= dp.ListStart && relY < dp.ListEnd {
-		return relY - dp.ListStart
+	// Map rows of a scrolled body back to the full dialog
+	if relY > 0 && relY < dp.Height-1 {
+		relY += dp.Scroll
 	}
-	return -1
+	return inside, relX, relY
 }
@@ -0,0 +1,150 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestDialogClampsToTerminalWidth(t *testing.T) {
+	e := New()
+	e.width = 30
+	db := e.NewDialogBuilder(66)
+	db.AddText(strings.Repeat("x", 80))
+	if db.InnerWidth() != 28 {
+		t.Errorf("InnerWidth = %d, want 28 for a 30 column terminal", db.InnerWidth())
+	}
+}
+
+func TestDialogScrollsToFocus(t *testing.T) {
+	e := New()
+	db := e.NewDialogBuilder(20)
+	db.AddTitleBorder(" List ")
+	for i := 0; i < 20; i++ {
+		db.AddSelectableItem(fmt.Sprintf("item %d", i), i == 15)
+	}
+	db.AddBottomBorder()
+
+	lines, scroll := db.visibleLines(10)
+	if len(lines) != 10 {
+		t.Fatalf("visible lines = %d, want 10", len(lines))
+	}
+	if scroll != 8 {
+		t.Errorf("scroll = %d, want 8 to show item 15 on the last body row", scroll)
+	}
+	if !strings.Contains(lines[8], "item 15") {
+		t.Errorf("focused item not visible: %q", lines[8])
+	}
+
+	pos := db.GetPosition(20, 10)
+	if _, _, relY := pos.MouseInDialog(pos.StartX+1, pos.StartY+8); relY-1 != 15 {
+		t.Errorf("click on the focused row maps to item %d, want 15", relY-1)
+	}
+}
+
+func TestTooSmallView(t *testing.T) {
+	e := New()
+	e.width, e.height = 30, 8
+	if view := e.View(); !strings.Contains(view, "Terminal too small") {
+		t.Errorf("tiny terminal should show a notice, got %q", view)
+	}
+}
+
+func TestTooSmallDropsDialogInput(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 21, Height: 8})
+	e.mode = ModePasteHistory
+	e.Update(tea.MouseMsg{X: 10, Y: 4, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModePasteHistory {
+		t.Errorf("dialog input should wait until the terminal is big enough")
+	}
+
+	// Titles wider than the dialog are cut rather than overflowing it
+	db := e.NewDialogBuilder(10)
+	db.AddTitleBorder(" A very long title ")
+	if w := runewidth.StringWidth(db.Lines()[0]); w != 10 {
+		t.Errorf("title border is %d wide, want 10", w)
+	}
+}
+
+func TestHelpScrollsInSmallTerminal(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	e.showHelp()
+
+	db := e.helpDialog()
+	if db.MaxScroll() == 0 {
+		t.Fatal("help should not fit in 20 rows")
+	}
+	lines, _ := db.visibleLines(e.viewport.Height())
+	if len(lines) != e.viewport.Height() {
+		t.Errorf("help shows %d lines in a %d row viewport", len(lines), e.viewport.Height())
+	}
+	if !strings.Contains(lines[len(lines)-1], e.box.MoreDown) {
+		t.Errorf("bottom border should mark more below: %q", lines[len(lines)-1])
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if e.mode != ModeHelp || e.dialogScroll != 1 {
+		t.Fatalf("Down should scroll the help, got mode %v scroll %d", e.mode, e.dialogScroll)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if e.dialogScroll != db.MaxScroll() {
+		t.Errorf("End scrolled to %d, want %d", e.dialogScroll, db.MaxScroll())
+	}
+	if view := e.View(); !strings.Contains(view, "Press any key to continue") {
+		t.Error("scrolled to the end, the footer should show")
+	}
+
+	// Growing the terminal leaves less to scroll
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	if limit := e.helpDialog().MaxScroll(); e.dialogScroll != limit {
+		t.Errorf("scroll = %d after resize, want it clamped to %d", e.dialogScroll, limit)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	if e.mode != ModeNormal {
+		t.Error("other keys should still close the help")
+	}
+}
+
+func TestHelpFitsNarrowTerminal(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 50, Height: 60})
+	e.showHelp()
+	db := e.helpDialog()
+	if db.InnerWidth() != 48 {
+		t.Fatalf("InnerWidth = %d, want 48", db.InnerWidth())
+	}
+	// The columns are stacked, so the right one's heading gets a line of its own
+	found := false
+	for _, line := range db.Lines() {
+		if strings.TrimSpace(strings.Trim(line, e.box.Vertical)) == "NAVIGATION" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("narrow help should stack its columns")
+	}
+}
+
+func TestSettingsMouseInSmallTerminal(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	e.showSettingsDialog()
+	e.settingsIndex = settingsRowCancel
+
+	// The dialog is scrolled to show Cancel; clicking it must still hit it
+	db := e.settingsDialog()
+	if pos := db.GetPosition(e.width, e.viewport.Height()); pos.Scroll == 0 {
+		t.Fatal("settings should scroll in 20 rows")
+	}
+	e.handleSettingsMouse(clickAt(db, settingsRowCancel, 1))
+	if e.mode != ModeNormal {
+		t.Errorf("mode = %v after clicking Cancel, want normal", e.mode)
+	}
+}
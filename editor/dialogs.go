@@ -139,58 +139,18 @@ func visualWidth(s string) int {
 	return runewidth.StringWidth(stripAnsi(s))
 }
 
-// overlayAboutDialog overlays the about dialog centered on the viewport
-func (e *Editor) overlayAboutDialog(viewportContent string) string {
+// aboutDialog builds the about dialog
+func (e *Editor) aboutDialog() *DialogBuilder {
 	// Use the stored quote (selected when dialog opened)
 	quote := e.aboutQuote
 	if quote == "" {
 		quote = "A Festivus for the rest of us!"
 	}
 
-	// Box dimensions - content is 64 chars, plus 2 for borders = 66
-	boxWidth := 66
-	innerWidth := boxWidth - 2
-	centerText := func(s string) string {
-		sLen := runewidth.StringWidth(s) // Use visual width for Unicode
-		if sLen >= innerWidth {
-			// Truncate by visual width
-			return runewidth.Truncate(s, innerWidth, "")
-		}
-		padLeft := (innerWidth - sLen) / 2
-		padRight := innerWidth - sLen - padLeft
-		return strings.Repeat(" ", padLeft) + s + strings.Repeat(" ", padRight)
-	}
-
-	// Split quote into lines if too long (max 60 chars per line)
-	maxLineWidth := 60
-	var quoteLines []string
-	quotedText := "\"" + quote + "\""
-	if len(quotedText) <= maxLineWidth {
-		// Fits on one line
-		quoteLines = []string{centerText(quotedText)}
-	} else {
-		// Split at word boundary
-		words := strings.Fields(quote)
-		line1 := "\""
-		line2 := ""
-		for _, word := range words {
-			testLine := line1
-			if len(line1) > 1 {
-				testLine += " "
-			}
-			testLine += word
-			if line2 == "" && len(testLine) <= maxLineWidth {
-				line1 = testLine
-			} else {
-				if line2 != "" {
-					line2 += " "
-				}
-				line2 += word
-			}
-		}
-		line2 += "\""
-		quoteLines = []string{centerText(line1), centerText(line2)}
-	}
+	// Content is 64 chars, plus 2 for borders = 66
+	db := e.NewDialogBuilder(66)
+	db.AddTitleBorder(" About Textivus ")
+	db.AddEmptyLine()
 
 	// Choose logo based on ASCII mode
 	var logoLines []string
@@ -216,32 +176,20 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 		}
 	}
 
-	var aboutLines []string
-
-	// Top border with title
-	title := " About Textivus "
-	titlePadLeft := (innerWidth - len(title)) / 2
-	titlePadRight := innerWidth - len(title) - titlePadLeft
-	aboutLines = append(aboutLines, e.box.TopLeft+strings.Repeat(e.box.Horizontal, titlePadLeft)+title+strings.Repeat(e.box.Horizontal, titlePadRight)+e.box.TopRight)
-
-	// Empty line
-	aboutLines = append(aboutLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
-
-	// Logo lines
-	for _, logoLine := range logoLines {
-		aboutLines = append(aboutLines, e.box.Vertical+centerText(logoLine)+e.box.Vertical)
+	// The logo is dropped rather than cut off on a narrow terminal
+	if runewidth.StringWidth(logoLines[0]) <= db.InnerWidth() {
+		for _, logoLine := range logoLines {
+			db.AddCenteredText(logoLine)
+		}
+		db.AddEmptyLine()
 	}
 
-	// Content lines
-	aboutLines = append(aboutLines,
-		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
-		e.box.Vertical+centerText("A Text Editor for the Rest of Us")+e.box.Vertical,
-		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
-		e.box.Vertical+centerText("Version 0.2.0")+e.box.Vertical,
-		e.box.Vertical+centerText("github.com/cornish/textivus-editor")+e.box.Vertical,
-		e.box.Vertical+centerText("Copyright (c) 2025")+e.box.Vertical,
-		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
-	)
+	db.AddCenteredText("A Text Editor for the Rest of Us")
+	db.AddEmptyLine()
+	db.AddCenteredText("Version 0.2.0")
+	db.AddCenteredText("github.com/cornish/textivus-editor")
+	db.AddCenteredText("Copyright (c) 2025")
+	db.AddEmptyLine()
 
 	// Terminal capabilities
 	caps := config.GetCapabilities()
@@ -249,72 +197,49 @@ func (e *Editor) overlayAboutDialog(viewportContent string) string {
 	if caps.UTF8Support {
 		utf8Status = "Yes"
 	}
-	kittyStatus := "No"
-	if caps.KittyGraphics {
-		kittyStatus = "Yes"
-	}
-	aboutLines = append(aboutLines,
-		e.box.Vertical+centerText("─── Terminal ───")+e.box.Vertical,
-		e.box.Vertical+centerText(fmt.Sprintf("UTF-8: %s   Colors: %s   Kitty: %s", utf8Status, caps.ColorMode.String(), kittyStatus))+e.box.Vertical,
-		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
-	)
-
-	// Quote lines
-	for _, quoteLine := range quoteLines {
-		aboutLines = append(aboutLines, e.box.Vertical+quoteLine+e.box.Vertical)
-	}
-
-	// Footer
-	aboutLines = append(aboutLines,
-		e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical,
-		e.box.Vertical+centerText("Press any key or click to close...")+e.box.Vertical,
-	)
-
-	// Bottom border
-	aboutLines = append(aboutLines, e.box.BottomLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.BottomRight)
-
-	boxHeight := len(aboutLines)
-
-	// Calculate centering
-	startX := (e.width - boxWidth) / 2
-	if startX < 0 {
-		startX = 0
-	}
-	startY := (e.viewport.Height() - boxHeight) / 2
-	if startY < 0 {
-		startY = 0
-	}
-
-	viewportLines := strings.Split(viewportContent, "\n")
-
-	// Get theme dialog colors
-	themeUI := e.styles.Theme.UI
-	dialogStyle := ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg)
-	resetStyle := "\033[0m"
-
-	for i, aboutLine := range aboutLines {
-		viewportY := startY + i
-		if viewportY >= 0 && viewportY < len(viewportLines) {
-			// Build the styled about line with theme colors
-			var styledLine strings.Builder
-			styledLine.WriteString(dialogStyle)
-			styledLine.WriteString(aboutLine)
-			styledLine.WriteString(resetStyle)
+	graphics := map[string]string{
+		config.GraphicsKitty:   "Kitty",
+		config.GraphicsSixel:   "Sixel",
+		config.GraphicsITerm:   "iTerm2",
+		config.GraphicsBraille: "None",
+	}[caps.MinimapGraphics(e.config.Editor.MinimapGraphics)]
+	db.AddCenteredText("─── Terminal ───")
+	db.AddCenteredText(fmt.Sprintf("UTF-8: %s   Colors: %s   Graphics: %s", utf8Status, caps.ColorMode.String(), graphics))
+	db.AddEmptyLine()
 
-			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
+	// Wrap the quote at word boundaries to fit the dialog (at most 60 wide)
+	maxLineWidth := min(60, db.InnerWidth()-2)
+	line := "\""
+	for i, word := range strings.Fields(quote) {
+		switch {
+		case i == 0:
+			line += word
+		case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) > maxLineWidth:
+			db.AddCenteredText(line)
+			line = word
+		default:
+			line += " " + word
 		}
 	}
+	db.AddCenteredText(line + "\"")
 
-	return strings.Join(viewportLines, "\n")
+	db.AddEmptyLine()
+	db.AddCenteredText("Press any key or click to close...")
+	db.AddBottomBorder()
+	db.Scroll(e.dialogScroll)
+	return db
 }
 
-// overlayHelpDialog overlays the help dialog centered on the viewport
-func (e *Editor) overlayHelpDialog(viewportContent string) string {
-	// Two-column layout for keyboard shortcuts
-	boxWidth := 72
-	innerWidth := boxWidth - 2 // 70
-	colWidth := 33             // Each column width
+// overlayAboutDialog overlays the about dialog centered on the viewport
+func (e *Editor) overlayAboutDialog(viewportContent string) string {
+	return e.aboutDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// helpDialog builds the help dialog. The shortcuts are laid out in two
+// columns, one after the other when the terminal is too narrow for both.
+func (e *Editor) helpDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(72)
+	colWidth := 33 // Each column width
 	// Layout: colWidth (33) + separator "  │ " (4) + colWidth (33) = 70
 
 	padText := func(s string, width int) string {
@@ -325,16 +250,6 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		return s + strings.Repeat(" ", width-sw)
 	}
 
-	centerText := func(s string, width int) string {
-		sw := runewidth.StringWidth(s)
-		if sw >= width {
-			return runewidth.Truncate(s, width, "")
-		}
-		padLeft := (width - sw) / 2
-		padRight := width - sw - padLeft
-		return strings.Repeat(" ", padLeft) + s + strings.Repeat(" ", padRight)
-	}
-
 	// Helper to format a keybinding entry
 	fmtKey := func(action, label string) string {
 		binding := e.keybindings.GetBinding(action)
@@ -353,6 +268,8 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		fmtKey("new", "New file"),
 		fmtKey("open", "Open file"),
 		fmtKey("recent_files", "Recent files"),
+		fmtKey("quick_open", "Quick open"),
+		fmtKey("buffer_list", "Buffer list"),
 		fmtKey("close", "Close file"),
 		fmtKey("save", "Save file"),
 		fmtKey("quit", "Quit"),
@@ -360,6 +277,7 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		"  EDIT",
 		fmtKey("undo", "Undo"),
 		fmtKey("redo", "Redo"),
+		fmtKey("undo_history", "Undo history"),
 		fmtKey("cut", "Cut"),
 		fmtKey("copy", "Copy"),
 		fmtKey("paste", "Paste"),
@@ -388,203 +306,84 @@ func (e *Editor) overlayHelpDialog(viewportContent string) string {
 		"  Ctrl+Shift+L/R  Select word",
 		"  Shift+Home/End  Select to line",
 		"  MOUSE: Click, Drag, Scroll",
+		"",
+		"  VIEW",
+		fmtKey("toggle_file_tree", "File tree"),
+		fmtKey("focus_file_tree", "Tree/editor focus"),
 	}
 
-	// Build help lines
-	var helpLines []string
-
-	// Top border with title
-	title := " Keyboard Shortcuts "
-	titlePadLeft := (innerWidth - len(title)) / 2
-	titlePadRight := innerWidth - len(title) - titlePadLeft
-	helpLines = append(helpLines, e.box.TopLeft+strings.Repeat(e.box.Horizontal, titlePadLeft)+title+strings.Repeat(e.box.Horizontal, titlePadRight)+e.box.TopRight)
-
-	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
-
-	// Build two-column content
-	maxRows := len(leftCol)
-	if len(rightCol) > maxRows {
-		maxRows = len(rightCol)
-	}
+	db.AddTitleBorder(" Keyboard Shortcuts ")
+	db.AddEmptyLine()
 
-	colSep := "  " + e.box.Vertical + " "
-	for i := 0; i < maxRows; i++ {
-		left := ""
-		right := ""
-		if i < len(leftCol) {
-			left = leftCol[i]
+	if db.InnerWidth() < 2*colWidth+4 {
+		for _, line := range leftCol {
+			db.AddText(line)
 		}
-		if i < len(rightCol) {
-			right = rightCol[i]
+		db.AddEmptyLine()
+		for _, line := range rightCol {
+			db.AddText(line)
+		}
+	} else {
+		colSep := "  " + e.box.Vertical + " "
+		for i := range max(len(leftCol), len(rightCol)) {
+			left := ""
+			right := ""
+			if i < len(leftCol) {
+				left = leftCol[i]
+			}
+			if i < len(rightCol) {
+				right = rightCol[i]
+			}
+			db.AddText(padText(left, colWidth) + colSep + padText(right, colWidth))
 		}
-		line := padText(left, colWidth) + colSep + padText(right, colWidth)
-		helpLines = append(helpLines, e.box.Vertical+line+e.box.Vertical)
 	}
-
-	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+	db.AddEmptyLine()
 
 	// Options section
 	toggleLnKey := config.FormatKeyForDisplay(e.keybindings.GetBinding("toggle_line_numbers").Primary)
 	if toggleLnKey == "" {
 		toggleLnKey = "(none)"
 	}
-	helpLines = append(helpLines, e.box.Vertical+centerText("OPTIONS: "+toggleLnKey+" Line Numbers", innerWidth)+e.box.Vertical)
-	helpLines = append(helpLines, e.box.Vertical+centerText("MENUS: F10 or Alt+F/E/O/H", innerWidth)+e.box.Vertical)
-
-	// Empty line
-	helpLines = append(helpLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
-
-	// Footer
-	helpLines = append(helpLines, e.box.Vertical+centerText("Press any key to continue...", innerWidth)+e.box.Vertical)
-
-	// Bottom border
-	helpLines = append(helpLines, e.box.BottomLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.BottomRight)
-
-	boxHeight := len(helpLines)
-
-	// Calculate centering
-	startX := (e.width - boxWidth) / 2
-	if startX < 0 {
-		startX = 0
-	}
-	startY := (e.viewport.Height() - boxHeight) / 2
-	if startY < 0 {
-		startY = 0
-	}
-
-	viewportLines := strings.Split(viewportContent, "\n")
-
-	// Get theme dialog colors
-	themeUI := e.styles.Theme.UI
-	dialogStyle := ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg)
-	resetStyle := "\033[0m"
-
-	for i, helpLine := range helpLines {
-		viewportY := startY + i
-		if viewportY >= 0 && viewportY < len(viewportLines) {
-			// Build the styled help line with theme colors
-			var styledLine strings.Builder
-			styledLine.WriteString(dialogStyle)
-			styledLine.WriteString(helpLine)
-			styledLine.WriteString(resetStyle)
+	db.AddCenteredText("OPTIONS: " + toggleLnKey + " Line Numbers")
+	db.AddCenteredText("MENUS: F10 or Alt+F/E/O/H")
+	db.AddEmptyLine()
 
-			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
-		}
-	}
+	db.AddCenteredText("Press any key to continue...")
+	db.AddBottomBorder()
+	db.Scroll(e.dialogScroll)
+	return db
+}
 
-	return strings.Join(viewportLines, "\n")
+// overlayHelpDialog overlays the help dialog centered on the viewport
+func (e *Editor) overlayHelpDialog(viewportContent string) string {
+	return e.helpDialog().Overlay(viewportContent, e.width, e.viewport.Height())
 }
 
 // overlayThemeDialog overlays the theme selection dialog centered on the viewport
 func (e *Editor) overlayThemeDialog(viewportContent string) string {
-	boxWidth := 40
-	innerWidth := boxWidth - 2
-
-	padText := func(s string, width int) string {
-		sw := runewidth.StringWidth(s)
-		if sw > width {
-			return runewidth.Truncate(s, width, "")
-		}
-		return s + strings.Repeat(" ", width-sw)
-	}
-
-	centerText := func(s string, width int) string {
-		sw := runewidth.StringWidth(s)
-		if sw >= width {
-			return runewidth.Truncate(s, width, "")
-		}
-		padLeft := (width - sw) / 2
-		padRight := width - sw - padLeft
-		return strings.Repeat(" ", padLeft) + s + strings.Repeat(" ", padRight)
-	}
-
-	// Get the theme colors for the dialog
-	themeUI := e.styles.Theme.UI
-	dialogStyle := ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg)
-	selectedStyle := ui.ColorToANSI(themeUI.DialogButtonFg, themeUI.DialogButton)
-	dialogResetStyle := ui.ColorToANSI(themeUI.DialogFg, themeUI.DialogBg)
-	resetStyle := "\033[0m"
-
-	// Current theme name for marking
-	currentTheme := "default"
-	if e.config != nil && e.config.Theme.Name != "" {
-		currentTheme = e.config.Theme.Name
-	}
-
-	// Build dialog lines (plain text, color applied in overlay loop)
-	var dialogLines []string
-
-	// Top border with title
-	title := " Select Theme "
-	titlePadLeft := (innerWidth - len(title)) / 2
-	titlePadRight := innerWidth - len(title) - titlePadLeft
-	dialogLines = append(dialogLines, e.box.TopLeft+strings.Repeat(e.box.Horizontal, titlePadLeft)+title+strings.Repeat(e.box.Horizontal, titlePadRight)+e.box.TopRight)
+	return e.themeDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
 
-	// Empty line
-	dialogLines = append(dialogLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
+// themeDialog builds the theme selection dialog
+func (e *Editor) themeDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(40)
+	db.AddTitleBorder(" Select Theme ")
+	db.AddEmptyLine()
 
-	// Theme list - need internal styling for selected item
+	// Mark current theme with asterisk, selected with highlight
+	currentTheme := e.themeName()
 	for i, name := range e.themeList {
-		// Mark current theme with asterisk, selected with highlight
 		prefix := "   "
 		if name == currentTheme {
 			prefix = " * "
 		}
-		displayName := prefix + name
-
-		var line string
-		if i == e.themeIndex {
-			// Selected item - highlighted (internal styling needed)
-			line = e.box.Vertical + selectedStyle + padText(displayName, innerWidth) + dialogResetStyle + e.box.Vertical
-		} else {
-			// Normal item
-			line = e.box.Vertical + padText(displayName, innerWidth) + e.box.Vertical
-		}
-		dialogLines = append(dialogLines, line)
-	}
-
-	// Empty line
-	dialogLines = append(dialogLines, e.box.Vertical+strings.Repeat(" ", innerWidth)+e.box.Vertical)
-
-	// Footer
-	footerText := centerText("[Enter] Select [E]dit [C]opy [Esc]", innerWidth)
-	dialogLines = append(dialogLines, e.box.Vertical+footerText+e.box.Vertical)
-
-	// Bottom border
-	dialogLines = append(dialogLines, e.box.BottomLeft+strings.Repeat(e.box.Horizontal, innerWidth)+e.box.BottomRight)
-
-	boxHeight := len(dialogLines)
-
-	// Calculate centering
-	startX := (e.width - boxWidth) / 2
-	if startX < 0 {
-		startX = 0
-	}
-	startY := (e.viewport.Height() - boxHeight) / 2
-	if startY < 0 {
-		startY = 0
-	}
-
-	viewportLines := strings.Split(viewportContent, "\n")
-
-	for i, dialogLine := range dialogLines {
-		viewportY := startY + i
-		if viewportY >= 0 && viewportY < len(viewportLines) {
-			// Build the styled dialog line with theme colors
-			var styledLine strings.Builder
-			styledLine.WriteString(dialogStyle)
-			styledLine.WriteString(dialogLine)
-			styledLine.WriteString(resetStyle)
-
-			// Overlay on viewport line
-			viewportLines[viewportY] = overlayLineAt(styledLine.String(), viewportLines[viewportY], startX)
-		}
+		db.AddSelectableItem(prefix+name, i == e.themeIndex)
 	}
 
-	return strings.Join(viewportLines, "\n")
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Select [E]dit [C]opy [Esc]")
+	db.AddBottomBorder()
+	return db
 }
 
 // overlayRecentFilesDialog overlays the recent files dialog using DialogBuilder
@@ -592,8 +391,11 @@ func (e *Editor) overlayRecentFilesDialog(viewportContent string) string {
 	if e.config == nil || len(e.config.RecentFiles) == 0 {
 		return viewportContent
 	}
+	return e.recentFilesDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
 
-	// Use DialogBuilder for consistent dialog rendering
+// recentFilesDialog builds the recent files dialog
+func (e *Editor) recentFilesDialog() *DialogBuilder {
 	db := e.NewDialogBuilder(60)
 
 	db.AddTitleBorder(" Recent Files ")
@@ -609,8 +411,7 @@ func (e *Editor) overlayRecentFilesDialog(viewportContent string) string {
 	db.AddEmptyLine()
 	db.AddCenteredText("[Enter] Open  [Del] Remove  [Esc] Cancel")
 	db.AddBottomBorder()
-
-	return db.Overlay(viewportContent, e.width, e.viewport.Height())
+	return db
 }
 
 // formatRecentPath formats a path to fit within the given width
@@ -633,8 +434,11 @@ func (e *Editor) overlayRecentDirsDialog(viewportContent string) string {
 	if e.config == nil || len(e.config.RecentDirs) == 0 {
 		return viewportContent
 	}
+	return e.recentDirsDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
 
-	// Use DialogBuilder for consistent dialog rendering
+// recentDirsDialog builds the recent directories dialog
+func (e *Editor) recentDirsDialog() *DialogBuilder {
 	db := e.NewDialogBuilder(60)
 
 	db.AddTitleBorder(" Recent Directories ")
@@ -650,12 +454,16 @@ func (e *Editor) overlayRecentDirsDialog(viewportContent string) string {
 	db.AddEmptyLine()
 	db.AddCenteredText("[Enter] Browse  [Del] Remove  [Esc] Cancel")
 	db.AddBottomBorder()
-
-	return db.Overlay(viewportContent, e.width, e.viewport.Height())
+	return db
 }
 
 // overlayConfigErrorDialog overlays the config error dialog
 func (e *Editor) overlayConfigErrorDialog(viewportContent string) string {
+	return e.configErrorDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// configErrorDialog builds the config error dialog
+func (e *Editor) configErrorDialog() *DialogBuilder {
 	boxWidth := 56
 	db := e.NewDialogBuilder(boxWidth)
 
@@ -675,119 +483,66 @@ func (e *Editor) overlayConfigErrorDialog(viewportContent string) string {
 
 	db.AddEmptyLine()
 
-	// Button row with selection highlighting
-	buttons := []string{"[ Edit File ]", "[ Use Defaults ]", "[ Quit ]"}
-	var buttonRow strings.Builder
-	for i, btn := range buttons {
-		if i > 0 {
-			buttonRow.WriteString("  ")
-		}
-		if i == e.configErrorChoice {
-			buttonRow.WriteString(db.themeUI.selectedStyle)
-			buttonRow.WriteString(btn)
-			buttonRow.WriteString(db.themeUI.dialogResetStyle)
-		} else {
-			buttonRow.WriteString(btn)
-		}
-	}
-	db.AddCenteredText(buttonRow.String())
-
+	// Buttons are the only widgets, so their ids are configErrorChoice values
+	db.SetFocus(e.configErrorChoice)
+	db.AddButtons(dialogButton{label: "Edit File"}, dialogButton{label: "Use Defaults"}, dialogButton{label: "Quit"})
 	db.AddBottomBorder()
-
-	return db.Overlay(viewportContent, e.width, e.viewport.Height())
+	return db
 }
 
 // overlaySettingsDialog overlays the settings dialog
 func (e *Editor) overlaySettingsDialog(viewportContent string) string {
-	boxWidth := 54
-	db := e.NewDialogBuilder(boxWidth)
+	return e.settingsDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
 
+// settingsDialog builds the settings dialog. Widgets are added in
+// settingsRow order, so the rows are their ids.
+func (e *Editor) settingsDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(54)
 	db.AddTitleBorder(" Settings ")
 	db.AddEmptyLine()
+	db.SetFocus(e.settingsIndex)
 
-	// Settings rows
-	const (
-		rowWordWrap     = 0
-		rowLineNumbers  = 1
-		rowSyntax       = 2
-		rowScrollbar    = 3
-		rowTabsToSpaces = 4
-		rowBackupCount  = 5
-		rowMaxBuffers   = 6
-		rowTabWidth     = 7
-		rowSave         = 8
-		rowCancel       = 9
-	)
-
-	// Helper to format checkbox - pad first, then apply highlighting
-	checkbox := func(label string, checked bool, row int) string {
-		check := "[ ]"
-		if checked {
-			check = "[x]"
-		}
-		line := "  " + check + " " + label
-		padded := db.PadText(line)
-		if e.settingsIndex == row {
-			return db.themeUI.selectedStyle + padded + db.themeUI.dialogResetStyle
-		}
-		return padded
-	}
-
-	// Helper to format number input - pad first, then apply highlighting
-	numberInput := func(label string, value int, row int) string {
-		valStr := fmt.Sprintf("%2d", value)
-		line := "  " + label + ": [" + valStr + "] [-][+]"
-		padded := db.PadText(line)
-		if e.settingsIndex == row {
-			return db.themeUI.selectedStyle + padded + db.themeUI.dialogResetStyle
-		}
-		return padded
-	}
-
-	// Checkboxes
-	db.lines = append(db.lines, db.box.Vertical+checkbox("Word Wrap", e.settingsWordWrap, rowWordWrap)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+checkbox("Line Numbers", e.settingsLineNumbers, rowLineNumbers)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+checkbox("Syntax Highlighting", e.settingsSyntax, rowSyntax)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+checkbox("Scrollbar", e.settingsScrollbar, rowScrollbar)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+checkbox("Tabs to Spaces", e.settingsTabsToSpaces, rowTabsToSpaces)+db.box.Vertical)
-
+	db.AddCheckbox("Word Wrap", e.settingsWordWrap)
+	db.AddCheckbox("Line Numbers", e.settingsLineNumbers)
+	db.AddCheckbox("Syntax Highlighting", e.settingsSyntax)
+	db.AddCheckbox("Scrollbar", e.settingsScrollbar)
+	db.AddCheckbox("Tabs to Spaces", e.settingsTabsToSpaces)
 	db.AddEmptyLine()
 
-	// Number inputs
-	db.lines = append(db.lines, db.box.Vertical+numberInput("Backup Count", e.settingsBackupCount, rowBackupCount)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+db.PadText("    0=disabled, 1=file~, N=rotating")+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+numberInput("Max Buffers", e.settingsMaxBuffers, rowMaxBuffers)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+db.PadText("    0=unlimited")+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+numberInput("Tab Width", e.settingsTabWidth, rowTabWidth)+db.box.Vertical)
-	db.lines = append(db.lines, db.box.Vertical+db.PadText("    1-16 columns")+db.box.Vertical)
-
+	db.AddSpinner("Backup Count", e.settingsBackupCount)
+	db.AddText("    0=disabled, 1=file~, N=rotating")
+	db.AddSpinner("Max Buffers", e.settingsMaxBuffers)
+	db.AddText("    0=unlimited")
+	db.AddSpinner("Tab Width", e.settingsTabWidth)
+	db.AddText("    1-16 columns")
+	db.AddSpinner("Unsaved Reminder", e.settingsReminder)
+	db.AddText("    minutes, 0=disabled")
+
+	db.AddChoice("Keybindings: ", e.settingsKeyProfile)
+	db.AddText("    " + strings.Join(config.KeybindingProfiles(), ", "))
 	db.AddEmptyLine()
 
-	// Buttons - center them properly
-	saveBtnText := "[ Save ]"
-	cancelBtnText := "[ Cancel ]"
-	buttonContent := saveBtnText + "    " + cancelBtnText // 8 + 4 + 10 = 22 chars
-	paddedLine := db.CenterText(buttonContent)
-	// Now apply highlighting by replacing the button text
-	if e.settingsIndex == rowSave {
-		paddedLine = strings.Replace(paddedLine, saveBtnText, db.themeUI.selectedStyle+saveBtnText+db.themeUI.dialogResetStyle, 1)
-	}
-	if e.settingsIndex == rowCancel {
-		paddedLine = strings.Replace(paddedLine, cancelBtnText, db.themeUI.selectedStyle+cancelBtnText+db.themeUI.dialogResetStyle, 1)
-	}
-	db.lines = append(db.lines, db.box.Vertical+paddedLine+db.box.Vertical)
-
+	db.AddButtons(dialogButton{label: "Save"}, dialogButton{label: "Cancel"})
 	db.AddBottomBorder()
-
-	return db.Overlay(viewportContent, e.width, e.viewport.Height())
+	return db
 }
 
 // overlayEncodingDialog overlays the encoding selection dialog
 func (e *Editor) overlayEncodingDialog(viewportContent string) string {
+	return e.encodingDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// encodingDialog builds the encoding selection dialog
+func (e *Editor) encodingDialog() *DialogBuilder {
 	boxWidth := 50
 	db := e.NewDialogBuilder(boxWidth)
 
-	db.AddTitleBorder(" Save As Encoding ")
+	title, help := " Save As Encoding ", "Changes encoding used when saving"
+	if e.encodingReopen {
+		title, help = " Reopen With Encoding ", "Re-reads the file in this encoding"
+	}
+	db.AddTitleBorder(title)
 	db.AddEmptyLine()
 
 	// Get list of supported encodings
@@ -821,11 +576,11 @@ func (e *Editor) overlayEncodingDialog(viewportContent string) string {
 	}
 
 	db.AddEmptyLine()
-	db.AddCenteredText("Changes encoding used when saving")
+	db.AddCenteredText(help)
 	db.AddCenteredText("[Enter] Select  [Esc] Cancel")
 	db.AddBottomBorder()
 
-	return db.Overlay(viewportContent, e.width, e.viewport.Height())
+	return db
 }
 
 // overlayKeybindingsDialog overlays the keybindings configuration dialog
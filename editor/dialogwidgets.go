@@ -0,0 +1,264 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// Dialog widgets record where they were laid out as they are added, so mouse
+// handling hit-tests the same layout the overlay draws instead of repeating
+// its row and column offsets.
+//
+// Every widget gets an id in the order it is added. Keyboard focus is an id:
+// the dialog keeps the focused id in its own state, passes it to SetFocus
+// before adding widgets, and moves it with moveFocus.
+
+// widgetKind is the kind of an interactive dialog widget
+type widgetKind int
+
+const (
+	widgetItem     widgetKind = iota // Row of a selectable list
+	widgetButton                     // Button in a button row
+	widgetInput                      // Labeled text field
+	widgetCheckbox                   // Labeled [x] toggle
+	widgetChoice                     // Labeled < value > picked from a list
+	widgetSpinner                    // Labeled number with [-][+]
+)
+
+// dialogWidget is where a widget was laid out
+type dialogWidget struct {
+	kind   widgetKind
+	index  int    // Position among the dialog's widgets of the same kind
+	line   int    // Dialog line, borders included
+	x0, x1 int    // Columns [x0, x1) within the line, borders included
+	parts  [2]int // Spinner: columns of [-] and [+]
+}
+
+// dialogHit says where a mouse event landed in a dialog
+type dialogHit struct {
+	inside bool       // Inside the dialog's box
+	id     int        // Widget hit, in the order added; -1 for none
+	kind   widgetKind // Kind of the widget hit
+	index  int        // Position among widgets of its kind; -1 for none
+	delta  int        // Spinner: -1 for [-], +1 for [+], 0 elsewhere
+}
+
+// dialogButton is one button of a button row
+type dialogButton struct {
+	label  string
+	danger bool // Destructive choice, drawn in the error color
+}
+
+// SetFocus sets the id of the focused widget, which is drawn highlighted
+// and kept in view when the dialog scrolls. -1 focuses nothing.
+func (db *DialogBuilder) SetFocus(id int) {
+	db.focused = id
+}
+
+// addWidget records a widget on the next line, returning its id and
+// whether it has focus
+func (db *DialogBuilder) addWidget(kind widgetKind, x0, x1 int) (int, bool) {
+	w := dialogWidget{kind: kind, line: len(db.lines), x0: x0, x1: x1}
+	for _, other := range db.widgets {
+		if other.kind == kind {
+			w.index++
+		}
+	}
+	db.widgets = append(db.widgets, w)
+	id := len(db.widgets) - 1
+	if id == db.focused {
+		db.Focus()
+	}
+	return id, id == db.focused
+}
+
+// addRow adds a full-width line, highlighted when focused
+func (db *DialogBuilder) addRow(text string, highlighted bool) {
+	if highlighted {
+		db.lines = append(db.lines, db.box.Vertical+db.themeUI.selectedStyle+db.PadText(text)+db.themeUI.dialogResetStyle+db.box.Vertical)
+		return
+	}
+	db.lines = append(db.lines, db.box.Vertical+db.PadText(text)+db.box.Vertical)
+}
+
+// AddSelectableItem adds an item that can be selected (highlighted when selected)
+func (db *DialogBuilder) AddSelectableItem(text string, isSelected bool) {
+	db.addWidget(widgetItem, 0, db.width)
+	if isSelected {
+		db.Focus()
+	}
+	db.addRow(text, isSelected)
+}
+
+// AddInput adds a labeled text field, with a cursor when focused
+func (db *DialogBuilder) AddInput(label, value string) {
+	_, focused := db.addWidget(widgetInput, 0, db.width)
+	if focused {
+		value += "_"
+	}
+	db.addRow("  "+label+value, focused)
+}
+
+// AddCheckbox adds a labeled toggle
+func (db *DialogBuilder) AddCheckbox(label string, checked bool) {
+	_, focused := db.addWidget(widgetCheckbox, 0, db.width)
+	check := "[ ]"
+	if checked {
+		check = "[x]"
+	}
+	db.addRow("  "+check+" "+label, focused)
+}
+
+// AddChoice adds a labeled value that Left/Right or a click steps through
+func (db *DialogBuilder) AddChoice(label, value string) {
+	_, focused := db.addWidget(widgetChoice, 0, db.width)
+	db.addRow("  "+label+"< "+value+" >", focused)
+}
+
+// AddSpinner adds a labeled number with [-] and [+] to click
+func (db *DialogBuilder) AddSpinner(label string, value int) {
+	text := fmt.Sprintf("  %s: [%2d] ", label, value)
+	minus := 1 + runewidth.StringWidth(text) // After the border
+	_, focused := db.addWidget(widgetSpinner, 0, db.width)
+	db.widgets[len(db.widgets)-1].parts = [2]int{minus, minus + 3}
+	db.addRow(text+"[-][+]", focused)
+}
+
+// AddButtons adds a centered row of buttons, each a widget of its own
+func (db *DialogBuilder) AddButtons(buttons ...dialogButton) {
+	labels := make([]string, len(buttons))
+	for i, b := range buttons {
+		labels[i] = "[ " + b.label + " ]"
+	}
+	rowWidth := buttonRowWidth(labels)
+	col := max((db.innerWidth-rowWidth)/2, 0)
+
+	var row strings.Builder
+	row.WriteString(strings.Repeat(" ", col))
+	for i, label := range labels {
+		if i > 0 {
+			row.WriteString("  ")
+			col += 2
+		}
+		width := runewidth.StringWidth(label)
+		_, focused := db.addWidget(widgetButton, 1+col, 1+col+width)
+		col += width
+		switch {
+		case focused && buttons[i].danger:
+			row.WriteString(db.themeUI.dangerSelectedStyle + label + db.themeUI.dialogResetStyle)
+		case focused:
+			row.WriteString(db.themeUI.selectedStyle + label + db.themeUI.dialogResetStyle)
+		case buttons[i].danger:
+			row.WriteString(db.themeUI.dangerStyle + label + db.themeUI.dialogResetStyle)
+		default:
+			row.WriteString(label)
+		}
+	}
+	row.WriteString(strings.Repeat(" ", max(db.innerWidth-col, 0)))
+	// Styled text can't go through PadText, which would count the escape codes
+	db.lines = append(db.lines, db.box.Vertical+row.String()+db.box.Vertical)
+}
+
+// buttonRowWidth is the width of button labels two spaces apart
+func buttonRowWidth(labels []string) int {
+	width := 2 * max(len(labels)-1, 0)
+	for _, label := range labels {
+		width += runewidth.StringWidth(label)
+	}
+	return width
+}
+
+// HitTest reports what is at column x of viewport row y
+func (db *DialogBuilder) HitTest(x, y int) dialogHit {
+	pos := db.GetPosition(db.viewWidth, db.viewHeight)
+	inside, relX, relY := pos.MouseInDialog(x, y)
+	hit := dialogHit{inside: inside, id: -1, index: -1}
+	if !inside {
+		return hit
+	}
+	for id, w := range db.widgets {
+		if w.line != relY || relX < w.x0 || relX >= w.x1 {
+			continue
+		}
+		hit.id, hit.kind, hit.index = id, w.kind, w.index
+		if w.kind == widgetSpinner {
+			switch {
+			case relX >= w.parts[0] && relX < w.parts[1]:
+				hit.delta = -1
+			case relX >= w.parts[1] && relX < w.parts[1]+3:
+				hit.delta = 1
+			}
+		}
+		break
+	}
+	return hit
+}
+
+// Items returns how many selectable items the dialog holds
+func (db *DialogBuilder) Items() int {
+	n := 0
+	for _, w := range db.widgets {
+		if w.kind == widgetItem {
+			n++
+		}
+	}
+	return n
+}
+
+// moveFocus handles the keys that step focus through a dialog's count
+// widgets: Up and Shift+Tab go back, Down and Tab go forward, stopping at
+// the ends. It reports whether the key was one of them.
+func moveFocus(focus *int, count int, msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyUp, tea.KeyShiftTab:
+		*focus = max(*focus-1, 0)
+	case tea.KeyDown, tea.KeyTab:
+		*focus = min(*focus+1, count-1)
+	default:
+		return false
+	}
+	return true
+}
+
+// listAction is what a mouse event asks of a list dialog
+type listAction int
+
+const (
+	listNone   listAction = iota
+	listChoose            // Act on the selected item
+	listClose             // Close the dialog
+)
+
+// listMouse gives a list dialog the usual mouse behavior: clicking an item
+// selects it and clicking the selected item again chooses it, the wheel
+// moves the selection, and a click outside closes the dialog.
+func (db *DialogBuilder) listMouse(msg tea.MouseMsg, selected *int) listAction {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		*selected = max(*selected-1, 0)
+		return listNone
+	case tea.MouseButtonWheelDown:
+		*selected = max(min(*selected+1, db.Items()-1), 0)
+		return listNone
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return listNone
+		}
+	default:
+		return listNone
+	}
+	hit := db.HitTest(msg.X, msg.Y-1)
+	switch {
+	case !hit.inside:
+		return listClose
+	case hit.kind != widgetItem || hit.index < 0:
+		return listNone
+	case hit.index == *selected:
+		return listChoose
+	}
+	*selected = hit.index
+	return listNone
+}
@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clickAt returns a left press at column x0+dx of a widget, in screen
+// coordinates (the menu bar takes the first row)
+func clickAt(db *DialogBuilder, id, dx int) tea.MouseMsg {
+	pos := db.GetPosition(db.viewWidth, db.viewHeight)
+	w := db.widgets[id]
+	return tea.MouseMsg{
+		X:      pos.StartX + w.x0 + dx,
+		Y:      pos.StartY + w.line - pos.Scroll + 1,
+		Button: tea.MouseButtonLeft,
+		Action: tea.MouseActionPress,
+	}
+}
+
+func TestDialogHitTest(t *testing.T) {
+	e := New()
+	e.width, e.height = 80, 24
+	e.updateViewportSize()
+
+	db := e.NewDialogBuilder(40)
+	db.AddTitleBorder(" Test ")
+	db.AddSelectableItem("one", true)
+	db.AddSelectableItem("two", false)
+	db.AddSpinner("Count", 3)
+	db.AddButtons(dialogButton{label: "OK"}, dialogButton{label: "Cancel", danger: true})
+	db.AddBottomBorder()
+
+	if db.Items() != 2 {
+		t.Errorf("Items() = %d, want 2", db.Items())
+	}
+	click := clickAt(db, 1, 3)
+	if hit := db.HitTest(click.X, click.Y-1); hit.kind != widgetItem || hit.index != 1 {
+		t.Errorf("click on second item hit %+v", hit)
+	}
+	spinner := db.widgets[2]
+	for _, tc := range []struct {
+		x, delta int
+	}{{spinner.parts[0], -1}, {spinner.parts[1] + 1, 1}, {3, 0}} {
+		click := clickAt(db, 2, tc.x)
+		if hit := db.HitTest(click.X, click.Y-1); hit.kind != widgetSpinner || hit.delta != tc.delta {
+			t.Errorf("spinner click at %d: hit %+v, want delta %d", tc.x, hit, tc.delta)
+		}
+	}
+	click = clickAt(db, 4, 0)
+	if hit := db.HitTest(click.X, click.Y-1); hit.kind != widgetButton || hit.index != 1 || hit.id != 4 {
+		t.Errorf("click on Cancel hit %+v", hit)
+	}
+	if hit := db.HitTest(click.X-1, click.Y-1); hit.id != -1 || !hit.inside {
+		t.Errorf("click between buttons hit %+v, want no widget", hit)
+	}
+	if hit := db.HitTest(0, 0); hit.inside {
+		t.Errorf("click in the corner should be outside the dialog")
+	}
+}
+
+func TestSettingsMouse(t *testing.T) {
+	e := New()
+	e.width, e.height = 80, 40
+	e.updateViewportSize()
+	e.showSettingsDialog()
+	width := e.settingsTabWidth
+
+	db := e.settingsDialog()
+	e.handleSettingsMouse(clickAt(db, settingsRowTabWidth, db.widgets[settingsRowTabWidth].parts[1]))
+	if e.settingsTabWidth != width+1 {
+		t.Errorf("tab width = %d after clicking [+], want %d", e.settingsTabWidth, width+1)
+	}
+	if e.settingsIndex != settingsRowTabWidth {
+		t.Errorf("focus = %d, want the clicked row", e.settingsIndex)
+	}
+
+	wrap := e.settingsWordWrap
+	e.handleSettingsMouse(clickAt(e.settingsDialog(), settingsRowWordWrap, 3))
+	if e.settingsWordWrap == wrap {
+		t.Error("clicking the Word Wrap row should toggle it")
+	}
+
+	e.handleSettingsMouse(clickAt(e.settingsDialog(), settingsRowCancel, 1))
+	if e.mode != ModeNormal {
+		t.Errorf("mode = %v after clicking Cancel, want normal", e.mode)
+	}
+}
+
+func TestConfirmMouse(t *testing.T) {
+	e := New()
+	e.width, e.height = 80, 24
+	e.updateViewportSize()
+	chosen := -1
+	e.showConfirm(&ConfirmDialog{
+		Title:    "Question",
+		Message:  "Really?",
+		Buttons:  []ConfirmButton{{Label: "Yes"}, {Label: "No"}},
+		Default:  1,
+		Cancel:   1,
+		OnChoose: func(choice int) tea.Cmd { chosen = choice; return nil },
+	})
+
+	e.handleConfirmMouse(clickAt(e.confirmDialog(), 0, 2))
+	if chosen != 0 {
+		t.Errorf("chose %d after clicking Yes, want 0", chosen)
+	}
+	if e.mode != ModeNormal {
+		t.Errorf("mode = %v, want the dialog closed", e.mode)
+	}
+}
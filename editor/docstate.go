@@ -0,0 +1,34 @@
+package editor
+
+import "github.com/cornish/textivus-editor/ui"
+
+// newStatusBar creates the status bar with glyphs suited to the terminal
+func newStatusBar(styles ui.Styles, ascii bool) *ui.StatusBar {
+	sb := ui.NewStatusBar(styles)
+	sb.SetASCII(ascii)
+	return sb
+}
+
+// docState returns the save state of the active buffer for the status bar.
+// A conflict with the file on disk outranks everything else since saving
+// would overwrite someone else's changes.
+func (e *Editor) docState() ui.DocState {
+	doc := e.activeDoc()
+	switch {
+	case doc.changedOnDisk:
+		return ui.DocConflict
+	case e.saveWaiting():
+		return ui.DocSaving
+	case doc.modified:
+		return ui.DocModified
+	case doc.autosaved:
+		return ui.DocAutosaved
+	}
+	return ui.DocSaved
+}
+
+// saveWaiting reports whether a save is paused on a question to the user
+// (lossy encoding, invisible characters, or a failed preflight check)
+func (e *Editor) saveWaiting() bool {
+	return e.mode == ModeConfirm && e.confirm != nil && e.confirm.Saving
+}
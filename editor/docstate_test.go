@@ -0,0 +1,65 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestDocState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Opening files updates the recent lists
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	doc := e.activeDoc()
+	if got := e.docState(); got != ui.DocSaved {
+		t.Errorf("after load state = %d, want saved", got)
+	}
+
+	doc.modified = true
+	if got := e.docState(); got != ui.DocModified {
+		t.Errorf("after edit state = %d, want modified", got)
+	}
+
+	e.showConfirm(&ConfirmDialog{Title: "Question", Buttons: []ConfirmButton{{Label: "OK"}}, Saving: true})
+	if got := e.docState(); got != ui.DocSaving {
+		t.Errorf("with a save question open state = %d, want saving", got)
+	}
+	e.mode = ModeNormal
+
+	e.config.Editor.ReminderAutosave = true
+	e.config.Editor.UnsavedReminder = 1
+	now := time.Now()
+	doc.dirtySince = now.Add(-2 * time.Minute)
+	e.checkUnsavedReminder(now)
+	if got := e.docState(); got != ui.DocAutosaved {
+		t.Errorf("after auto-save state = %d, want autosaved", got)
+	}
+
+	// Another program writes the file: the conflict shows at the next check
+	// unless the buffer is reloaded
+	e.config.Editor.AutoReload = false
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	e.Update(fileCheckMsg{})
+	if got := e.docState(); got != ui.DocConflict {
+		t.Errorf("after external change state = %d, want conflict", got)
+	}
+
+	if !e.doSave() {
+		t.Fatalf("save failed")
+	}
+	if got := e.docState(); got != ui.DocSaved {
+		t.Errorf("after save state = %d, want saved", got)
+	}
+}
@@ -2,20 +2,26 @@ package editor
 
 import (
 	"fmt"
+	"log/slog"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/cornish/textivus-editor/annotations"
 	"github.com/cornish/textivus-editor/clipboard"
 	"github.com/cornish/textivus-editor/config"
 	enc "github.com/cornish/textivus-editor/encoding"
+	"github.com/cornish/textivus-editor/snippets"
+	"github.com/cornish/textivus-editor/symbols"
 	"github.com/cornish/textivus-editor/syntax"
 	"github.com/cornish/textivus-editor/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
 )
 
 // Mode represents the editor mode
@@ -38,6 +44,20 @@ const (
 	ModeConfigError
 	ModeSettings
 	ModeEncoding
+	ModeConfirm
+	ModeStatistics
+	ModePasteHistory
+	ModeInsertBuffer
+	ModeAnnotations
+	ModeQuickOpen
+	ModeBufferList
+	ModeSymbols
+	ModePrint
+	ModeInsertText
+	ModeUndoHistory
+	ModeIndent
+	ModeRestoreBackup
+	ModeLocalHistory
 )
 
 // FileEntry represents a file or directory in the file browser
@@ -45,10 +65,11 @@ type FileEntry struct {
 	Name       string
 	IsDir      bool
 	Size       int64
-	Readable   bool   // For directories: whether we can read/enter it
-	IsFavorite bool   // Whether this item is favorited
-	FullPath   string // Full path (used in favorites view)
-	IsSpecial  bool   // True for special entries like "★ Favorites" or ".."
+	ModTime    time.Time // Files only: directories are not stat'ed
+	Readable   bool      // For directories: whether we can read/enter it
+	IsFavorite bool      // Whether this item is favorited
+	FullPath   string    // Full path (used in favorites view)
+	IsSpecial  bool      // True for special entries like "★ Favorites" or ".."
 }
 
 // BoxChars holds characters used for drawing dialog boxes
@@ -61,8 +82,15 @@ type BoxChars struct {
 	Vertical    string
 	TeeLeft     string
 	TeeRight    string
+	TeeDown     string
+	TeeUp       string
 	Lock        string
 	Ellipsis    string
+	Note        string // Gutter marker for annotated lines
+	MoreUp      string // Border marker for a dialog scrolled down
+	MoreDown    string // Border marker for a dialog with more below
+	MoreLeft    string // Edge marker for a line scrolled off the left
+	MoreRight   string // Edge marker for a line running off the right
 }
 
 // UnicodeBoxChars provides Unicode box drawing characters
@@ -75,8 +103,15 @@ var UnicodeBoxChars = BoxChars{
 	Vertical:    "│",
 	TeeLeft:     "├",
 	TeeRight:    "┤",
+	TeeDown:     "┬",
+	TeeUp:       "┴",
 	Lock:        "🔒",
 	Ellipsis:    "…",
+	Note:        "✎",
+	MoreUp:      "▲",
+	MoreDown:    "▼",
+	MoreLeft:    "‹",
+	MoreRight:   "›",
 }
 
 // AsciiBoxChars provides ASCII fallback characters
@@ -89,8 +124,15 @@ var AsciiBoxChars = BoxChars{
 	Vertical:    "|",
 	TeeLeft:     "+",
 	TeeRight:    "+",
+	TeeDown:     "+",
+	TeeUp:       "+",
 	Lock:        "*",
 	Ellipsis:    "...",
+	Note:        "#",
+	MoreUp:      "^",
+	MoreDown:    "v",
+	MoreLeft:    "<",
+	MoreRight:   ">",
 }
 
 // PromptAction represents what to do with the prompt result
@@ -102,13 +144,13 @@ const (
 	PromptOpen
 	PromptConfirmNew
 	PromptConfirmOpen
-	PromptConfirmClose
-	PromptConfirmQuit
-	PromptConfirmOverwrite
 	PromptGoToLine
 	PromptThemeCopyName
-	PromptFileChanged      // File changed on disk - reload?
-	PromptConfirmLossySave // Confirm save with character loss
+	PromptViCommand     // vi ":" command line
+	PromptAnnotate      // Note for the cursor line
+	PromptInsertCommand // Shell command whose output Insert Text inserts
+	PromptEarlier       // How far back Earlier goes through the undo history
+	PromptLater         // How far forward Later goes through the undo history
 )
 
 // fileCheckMsg is sent periodically to check for external file changes
@@ -124,6 +166,47 @@ func fileCheckCmd() tea.Cmd {
 	})
 }
 
+// reminderCheckMsg is sent periodically to check for long-unsaved buffers
+type reminderCheckMsg struct{}
+
+// reminderCheckInterval is how often to check for long-unsaved buffers
+const reminderCheckInterval = 30 * time.Second
+
+// reminderCheckCmd returns a command that sends a reminderCheckMsg after the interval
+func reminderCheckCmd() tea.Cmd {
+	return tea.Tick(reminderCheckInterval, func(t time.Time) tea.Msg {
+		return reminderCheckMsg{}
+	})
+}
+
+// themeCheckMsg is sent periodically to reload the active theme when its file changes
+type themeCheckMsg struct{}
+
+// themeCheckInterval is how often the active theme file is checked
+const themeCheckInterval = time.Second
+
+// themeCheckCmd returns a command that sends a themeCheckMsg after the interval
+func themeCheckCmd() tea.Cmd {
+	return tea.Tick(themeCheckInterval, func(t time.Time) tea.Msg {
+		return themeCheckMsg{}
+	})
+}
+
+// chordTimeoutMsg cancels a pending key chord if its second key never arrives
+type chordTimeoutMsg struct {
+	seq int
+}
+
+// chordTimeout is how long to wait for the second key of a chord
+const chordTimeout = 1500 * time.Millisecond
+
+// chordTimeoutCmd returns a command that sends a chordTimeoutMsg after the timeout
+func chordTimeoutCmd(seq int) tea.Cmd {
+	return tea.Tick(chordTimeout, func(t time.Time) tea.Msg {
+		return chordTimeoutMsg{seq: seq}
+	})
+}
+
 // FestivusQuotes are displayed randomly in the About dialog.
 // Feel free to add more Seinfeld Festivus quotes!
 var FestivusQuotes = []string{
@@ -180,18 +263,55 @@ type Document struct {
 	modified    bool
 	scrollY     int // viewport scroll position for this document
 	highlighter *syntax.Highlighter
-	modTime     time.Time     // file modification time when loaded/saved
-	encoding    *enc.Encoding // detected file encoding
+	modTime     time.Time       // file modification time when loaded/saved
+	encoding    *enc.Encoding   // detected file encoding
+	dirtySince  time.Time       // when unsaved changes were first noticed (zero if clean)
+	remindedAt  time.Time       // when the last unsaved-changes reminder was shown
+	multiSel    []multiRange    // extra selections from Select All Matches (nil when inactive)
+	snippet     *snippetSession // tab stops of the snippet being filled in (nil when none)
+	readOnly    bool            // file could not be opened for writing when loaded
+	roWarned    bool            // the user was warned when first editing a read-only file
+	savedLines  []string        // lines as last loaded or saved, for change markers (nil = new buffer)
+	indent      *indentStyle    // indentation detected on load or chosen in the Indentation dialog (nil = the settings)
+
+	autosaved     bool // last written by the unsaved-changes reminder's auto-save
+	changedOnDisk bool // file was newer on disk at the last check
+
+	autoClosers   []int // offsets of closers inserted by auto-pair, innermost last
+	autoCloserLen int   // buffer length when autoClosers was last updated
+
+	notes        []annotations.Note // line annotations, in line order
+	notesVersion uint64             // buffer version the notes were last anchored to
+
+	lastUsed int // when the buffer was last active, for the Buffer List's recent-first order (0 = never)
+
+	hexView bool   // showing the file's bytes as a read-only hex dump
+	hexData []byte // the bytes shown while in hex view
+
+	follow       bool  // read-only, with text written to the end of the file added as it arrives
+	followOffset int64 // bytes of the file read into the buffer while following
 }
 
 // Editor is the main Bubbletea model for the text editor
 type Editor struct {
 	// Documents (multiple buffer support)
-	documents []*Document
-	activeIdx int
+	documents     []*Document
+	activeIdx     int
+	closedBuffers []closedBuffer // Close history for Reopen Closed, most recent last
+	onFirstRender func()         // Called once after the first frame is rendered
+	themeModTime  time.Time      // Modification time of the active user theme file when applied
 
 	// Shared components
 	clipboard *clipboard.Clipboard
+	noteStore *annotations.Store // Line annotations of all files, loaded on first use
+
+	snippets        *snippets.Set    // User snippets, reloaded when the file changes
+	snippetsModTime time.Time        // Modification time of the snippets file when loaded
+	primarySel      primarySel       // Selection last handed to the primary selection
+	primarySeq      int              // Identifies the latest scheduled primary sync
+	osc52Pending    bool             // A paste is waiting for the terminal's clipboard reply
+	osc52Seq        int              // Identifies the latest OSC52 query, for its timeout
+	osc52Reply      *strings.Builder // OSC52 reply being collected from key input (nil when none)
 
 	// UI components
 	menubar   *ui.MenuBar
@@ -207,6 +327,13 @@ type Editor struct {
 	textRenderer     *ui.TextRenderer
 	minimapRenderer  ui.MinimapController
 	scrollbarAdapter *ui.ScrollbarColumnAdapter
+	fileTree         *ui.FileTreeRenderer
+
+	// File tree sidebar state
+	treeRoot     *treeNode // Working directory the tree was opened on
+	treeSelected int       // Selected visible row
+	treeScroll   int       // First visible row
+	treeFocused  bool      // true = keys go to the tree
 
 	// State
 	mode   Mode
@@ -215,44 +342,95 @@ type Editor struct {
 
 	// Find mode state
 	findQuery  string
+	findCursor int // Bytes of findQuery after the cursor
 	findActive bool
 
 	// Find and Replace mode state
-	replaceQuery string
-	replaceFocus bool // true = replace field, false = find field
+	replaceQuery  string
+	replaceCursor int         // Bytes of replaceQuery after the cursor
+	replaceFocus  bool        // true = replace field, false = find field
+	searchScope   searchScope // Find bar filter: everywhere, code, comments or strings
+
+	// Query replace (asking per match in the find/replace bar)
+	queryReplacing bool
+	queryReplaced  int // Matches replaced so far
+	querySkipped   int // Matches the user said no to
+
+	// Input histories recalled with Up/Down in the find, replace and go-to bars
+	findHistory    inputHistory
+	replaceHistory inputHistory
+	gotoHistory    inputHistory
 
 	// Prompt mode state
-	promptText           string       // The prompt message
-	promptInput          string       // User's input
-	promptAction         PromptAction // What to do with the result
-	pendingFilename      string       // Filename pending confirmation (for overwrite)
-	pendingQuit          bool         // Whether to quit after current action
-	pendingLossySave     bool         // Lossy save pending confirmation
-	pendingLossyCount    int          // Number of characters that will be lost
-	pendingLossyInDialog bool         // Whether lossy save was triggered from dialog
+	promptText        string          // The prompt message
+	promptInput       string          // User's input
+	promptCursor      int             // Bytes of promptInput after the cursor
+	completion        *pathCompletion // Tab completion of the path being typed
+	promptAction      PromptAction    // What to do with the result
+	pendingQuit       bool            // Whether to quit after current action
+	pendingSave       *pendingSave    // Save from the unsaved changes dialog still under way
+	lossySave         lossyChoice     // Answer to the lossy encoding question while its save resumes
+	invisiblesChecked bool            // Invisible characters already reported for the save in progress
 
 	// Terminal state
-	pendingTitle   string // Title to set on next render
-	pendingEscapes string // Escape sequences to output on next render (e.g., clear Kitty graphics)
+	pendingTitle   string          // Title to set on next render
+	pendingEscapes string          // Escape sequences to output on next render (e.g., clear Kitty graphics)
+	sixelFrame     string          // Last frame drawn under a Sixel or iTerm2 minimap
+	sixelFlip      bool            // Alternates to force that minimap to be redrawn
+	exitHooks      []func() string // Cleanup escapes written before the alt screen is left
+	quitting       bool            // Quit requested; renders write exitHooks output
+
+	emergencySaved  bool     // The modified buffers were written out after a crash
+	emergencyFiles  []string // Files they were written to
+	emergencyErrors []string // Buffers that could not be written
+
+	debugLog     *slog.Logger // Session log written with --debug, nil when off
+	debugLogFile *os.File     // File the debug log goes to
+	debugLogPath string       // Its path, for Help > View Log
+
+	savingQuietly bool // A save that skips rather than asks (see quietSave)
+	revealCursor  bool // Scroll to the cursor once the terminal size is known
+
+	// The file named on the command line, for the $EDITOR exit status
+	argDoc       *Document // Its buffer while open (see MarkCommandLineFile)
+	argFile      string    // Its name
+	argSaved     bool      // The user has saved it
+	argDiscarded bool      // Unsaved changes to it were thrown away
+
+	// Git status of the active file, shown in the gitSegment
+	gitPath  string          // File the segment was last read for
+	gitStale bool            // Re-read even if gitPath is unchanged (after a save)
+	gitAsked map[string]bool // Untrusted repositories already asked about this session
 
 	// Mouse state
 	mouseDown   bool
 	mouseStartX int
 	mouseStartY int
 
-	// Key throttling
-	lastPageKey time.Time
+	gutterDrag   bool // The button went down on a line number: dragging selects whole lines
+	gutterAnchor int  // Line the gutter drag started on
+
+	minimapHover  int // Line under the pointer on the minimap, for its tooltip (-1 = none)
+	minimapHoverY int // Viewport row of the pointer on the minimap
 
 	// About dialog state
 	aboutQuote string
 
+	// Body lines the Help, About or Statistics dialog is scrolled down
+	// when it is taller than the viewport
+	dialogScroll int
+
 	// File browser state (shared with Save As)
-	fileBrowserDir       string      // Current directory
-	fileBrowserEntries   []FileEntry // Directory contents
-	fileBrowserSelected  int         // Selected index
-	fileBrowserScroll    int         // Scroll offset
-	fileBrowserError     string      // Error message to display in dialog
-	fileBrowserFavorites bool        // true = showing favorites virtual directory
+	fileBrowserDir       string          // Current directory
+	fileBrowserEntries   []FileEntry     // Listed entries, narrowed by the filter
+	fileBrowserSelected  int             // Selected index
+	fileBrowserScroll    int             // Scroll offset
+	fileBrowserError     string          // Error message to display in dialog
+	fileBrowserPreview   *browserPreview // Start of the selected file, shown beside the list
+	fileBrowserArchive   *archiveListing // Contents of the archive being browsed
+	fileBrowserFavorites bool            // true = showing favorites virtual directory
+	fileBrowserAll       []FileEntry     // Directory contents before filtering
+	fileBrowserFilter    string          // Typed filter narrowing the listed entries
 
 	// Save As state
 	saveAsFilename     string // Filename input for Save As dialog
@@ -266,6 +444,51 @@ type Editor struct {
 	// Recent files dialog state
 	recentFilesIndex int // Selected index in recent files dialog
 
+	// Clipboard history and registers
+	pasteHistoryIndex int            // Selected entry in the Paste from History dialog
+	registerOp        rune           // Pending register operation waiting for a register name
+	insertBufferIndex int            // Selected buffer in the Insert Buffer dialog
+	insertChoices     []insertChoice // What the Insert Text dialog offers
+	insertIndex       int            // Selected choice in the Insert Text dialog
+	annotationIndex   int            // Selected note in the Annotations dialog
+
+	// Quick Open dialog
+	quickOpenQuery      string
+	quickOpenIndex      int              // Selected match
+	quickOpenRoot       string           // Directory the file index was built from
+	quickOpenFiles      []string         // Files under quickOpenRoot, relative to it (nil until indexed)
+	quickOpenIndexing   bool             // The index is being built in the background
+	quickOpenMatched    []quickOpenMatch // Ranked matches for quickOpenMatchedFor
+	quickOpenMatchedFor string           // Query quickOpenMatched was ranked for
+	quickOpenRanked     bool             // quickOpenMatched is up to date with the index
+
+	// Go to Symbol dialog
+	symbolTitle   string
+	symbolQuery   string
+	symbolIndex   int           // Selected match
+	symbolEntries []symbolEntry // Listed symbols, before filtering by the query
+
+	// Print dialog
+	printIndex   int    // Focused row
+	printWhat    int    // printBuffer, printSelection or printLines
+	printRange   string // Lines to print, e.g. "10-20"
+	printHeader  bool   // Add a header with the file name and page numbers
+	printCommand string // Shell command the text is piped to
+
+	bufferListIndex  int // Selected buffer in the Buffer List dialog
+	undoHistoryIndex int // Selected state in the Undo History dialog
+	indentIndex      int // Selected row in the Indentation dialog
+	backupIndex      int // Selected backup in the Restore Backup dialog
+
+	localSnapshots    []localSnapshot // Snapshots listed in the Local History dialog, newest first
+	localHistoryIndex int             // Selected snapshot in the Local History dialog
+	followSeq         int             // Chain of follow checks that is current; older ticks are dropped
+	lastActiveDoc     *Document       // Buffer active after the last update, for autosave_on_switch
+	unfocused         bool            // The terminal reported losing focus; periodic work is paused
+	paused            pausedChecks    // Periodic checks to restart when focus returns
+	minimapSeq        string          // Last minimap image drawn, reused while unfocused
+	bufferUseSeq      int             // Counter stamped on buffers as they become active
+
 	// Recent directories dialog state
 	recentDirsIndex int // Selected index in recent dirs dialog
 
@@ -296,10 +519,26 @@ type Editor struct {
 	settingsBackupCount  int
 	settingsMaxBuffers   int
 	settingsTabWidth     int
+	settingsReminder     int
 	settingsTabsToSpaces bool
+	settingsKeyProfile   string
 
 	// Encoding dialog state
-	encodingIndex int // Selected encoding index
+	encodingIndex  int  // Selected encoding index
+	encodingReopen bool // The encoding dialog re-reads the file instead of setting the save encoding
+
+	// Confirm dialog state (nil when closed)
+	confirm *ConfirmDialog
+
+	// vi keybinding profile state
+	vi viState
+
+	// emacs keybinding profile state
+	emacs emacsState
+
+	// Key chord state
+	chordPrefix string // First key of a pending chord, e.g. "ctrl+k"
+	chordSeq    int    // Incremented per chord so stale timeouts are ignored
 }
 
 // activeDoc returns the currently active document
@@ -323,14 +562,19 @@ func (e *Editor) switchToBuffer(idx int) {
 
 	// Restore new doc's scroll position
 	e.viewport.SetScrollY(e.activeDoc().scrollY)
+	e.applyFileSettings()
 
 	// Update title, menu, and status
 	e.updateTitle()
 	e.updateMenuState()
 
 	// Check if file changed on disk
-	if e.fileChangedOnDisk() {
+	reloaded := e.reloadChanged(e.activeDoc())
+	e.activeDoc().changedOnDisk = e.fileChangedOnDisk()
+	if e.activeDoc().changedOnDisk {
 		e.statusbar.SetMessage("Warning: File changed on disk!", "error")
+	} else if reloaded {
+		e.statusbar.SetMessage("Reloaded "+e.bufferName(e.activeDoc())+" (changed on disk)", "info")
 	} else {
 		e.statusbar.SetMessage("", "")
 	}
@@ -354,26 +598,128 @@ func (e *Editor) prevBuffer() {
 	e.switchToBuffer(prevIdx)
 }
 
+// moveBuffer moves the active buffer delta places along the buffer list,
+// so its Buffers menu entry and Alt+number shortcut follow the new order
+func (e *Editor) moveBuffer(delta int) {
+	to := e.activeIdx + delta
+	if to < 0 || to >= len(e.documents) {
+		return
+	}
+	e.documents[e.activeIdx], e.documents[to] = e.documents[to], e.documents[e.activeIdx]
+	e.activeIdx = to
+	e.updateMenuState()
+	e.statusbar.SetMessage(fmt.Sprintf("Buffer moved to position %d", to+1), "info")
+}
+
+// fileSettings returns the effective settings for the active document,
+// including any per-filetype overrides
+func (e *Editor) fileSettings() config.FileSettings {
+	cfg := e.config
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	filename := ""
+	doc := e.activeDoc()
+	if doc != nil {
+		filename = doc.filename
+	}
+	fs := cfg.FileSettings(filename)
+	if doc != nil && doc.indent != nil {
+		fs.TabsToSpaces = !doc.indent.tabs
+		if doc.indent.width > 0 {
+			fs.TabWidth = doc.indent.width
+		}
+	}
+	return fs
+}
+
+// applyFileSettings applies the active document's per-filetype settings
+// to the viewport. Called whenever the active document or its name changes.
+func (e *Editor) applyFileSettings() {
+	fs := e.fileSettings()
+	e.viewport.SetTabWidth(fs.TabWidth)
+	e.viewport.SetScrollOff(e.config.Editor.ScrollOff)
+
+	wrap := e.config.Editor.WordWrap
+	if fs.WordWrap != nil {
+		wrap = *fs.WordWrap
+	}
+	if wrap != e.viewport.WordWrap() {
+		e.viewport.SetWordWrap(wrap)
+		if wrap {
+			e.menubar.SetItemLabel(ui.ActionWordWrap, "[x] Word Wrap")
+		} else {
+			e.menubar.SetItemLabel(ui.ActionWordWrap, "[ ] Word Wrap")
+		}
+	}
+}
+
 // bufferCount returns the number of open buffers
 func (e *Editor) bufferCount() int {
 	return len(e.documents)
 }
 
-// findBufferByFilename returns the index of a buffer with the given filename, or -1 if not found
+// findBufferByFilename returns the index of a buffer editing the given file, or -1 if not found.
+// A path reached through a symlink finds the buffer opened through its target and vice versa.
 func (e *Editor) findBufferByFilename(filename string) int {
 	for i, doc := range e.documents {
 		if doc.filename == filename {
 			return i
 		}
 	}
+	for i, doc := range e.documents {
+		if doc.filename != "" && sameFile(doc.filename, filename) {
+			return i
+		}
+	}
 	return -1
 }
 
+// sameFile reports whether two paths name the same file: equal once symlinks
+// are resolved, or failing that the same device and inode (hard links, bind mounts)
+func sameFile(a, b string) bool {
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	if errA == nil && errB == nil && ra == rb {
+		return true
+	}
+	ia, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	ib, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ia, ib)
+}
+
 // matchesBinding checks if a key string matches a configured action
 func (e *Editor) matchesBinding(keyStr string, action string) bool {
 	return e.keybindings.GetBinding(action).Matches(keyStr)
 }
 
+// startChord records the first key of a chord and starts its timeout
+func (e *Editor) startChord(keyStr string) tea.Cmd {
+	e.chordPrefix = keyStr
+	e.chordSeq++
+	return chordTimeoutCmd(e.chordSeq)
+}
+
+// finishChord completes a pending chord with its second key
+func (e *Editor) finishChord(msg tea.KeyMsg) tea.Cmd {
+	chord := e.chordPrefix + config.ChordSeparator + msg.String()
+	e.chordPrefix = ""
+	if msg.Type == tea.KeyEsc {
+		return nil
+	}
+	if handled, cmd := e.handleConfigurableBinding(chord, msg); handled {
+		return cmd
+	}
+	e.statusbar.SetMessage(config.FormatKeyForDisplay(chord)+" is not bound", "error")
+	return nil
+}
+
 // handleConfigurableBinding checks if the key matches any configurable binding and executes the action
 // Returns (true, cmd) if handled, (false, nil) otherwise
 func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool, tea.Cmd) {
@@ -394,10 +740,25 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		e.closeFile()
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "reopen_closed") {
+		e.reopenClosed()
+		return true, nil
+	}
 	if e.matchesBinding(keyStr, "recent_files") {
 		e.showRecentFiles()
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "quick_open") {
+		return true, e.showQuickOpen()
+	}
+	if e.matchesBinding(keyStr, "insert_text") {
+		e.showInsertText()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "print") {
+		e.showPrintDialog()
+		return true, nil
+	}
 	if e.matchesBinding(keyStr, "quit") {
 		return true, e.quitEditor()
 	}
@@ -411,6 +772,18 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		e.redo()
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "undo_history") {
+		e.showUndoHistory()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "earlier") {
+		e.showUndoTravel(true)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "later") {
+		e.showUndoTravel(false)
+		return true, nil
+	}
 	if e.matchesBinding(keyStr, "cut") {
 		if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 			e.cut()
@@ -423,8 +796,29 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		}
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "copy_append") {
+		if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
+			e.copyAppend()
+		}
+		return true, nil
+	}
 	if e.matchesBinding(keyStr, "paste") {
-		e.paste()
+		return true, e.paste()
+	}
+	if e.matchesBinding(keyStr, "paste_history") {
+		e.showPasteHistory()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "copy_to_register") {
+		e.startRegisterOp(registerOpCopy)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "paste_register") {
+		e.startRegisterOp(registerOpPaste)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "insert_buffer") {
+		e.showInsertBuffer()
 		return true, nil
 	}
 	if e.matchesBinding(keyStr, "cut_line") {
@@ -436,6 +830,52 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		return true, nil
 	}
 
+	// Text transforms
+	if e.matchesBinding(keyStr, "uppercase") {
+		e.transformSelection(strings.ToUpper)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "lowercase") {
+		e.transformSelection(strings.ToLower)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "title_case") {
+		e.transformSelection(titleCase)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "toggle_case") {
+		e.transformSelection(toggleCase)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "sort_lines") {
+		e.transformLines(sortLines)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "reverse_lines") {
+		e.transformLines(reverseLines)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "unique_lines") {
+		e.transformLines(uniqueLines)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "indent_to_spaces") {
+		e.convertIndentation(false)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "indent_to_tabs") {
+		e.convertIndentation(true)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "increment_number") {
+		e.incrementNumber(1)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "decrement_number") {
+		e.incrementNumber(-1)
+		return true, nil
+	}
+
 	// Search operations
 	if e.matchesBinding(keyStr, "find") {
 		e.mode = ModeFind
@@ -453,7 +893,19 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		return true, nil
 	}
 	if e.matchesBinding(keyStr, "goto_line") {
-		e.showPrompt("Go to line: ", PromptGoToLine)
+		e.showPrompt("Go to line[:col] or #offset: ", PromptGoToLine)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "go_to_symbol") {
+		e.showGoToSymbol()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "go_to_definition") {
+		e.goToDefinition()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "cursor_info") {
+		e.showCursorInfo()
 		return true, nil
 	}
 
@@ -483,6 +935,20 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		return true, nil
 	}
 
+	// Scrolling
+	if e.matchesBinding(keyStr, "center_cursor") {
+		e.centerCursor()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "half_page_up") {
+		e.scrollHalfPage(-1)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "half_page_down") {
+		e.scrollHalfPage(1)
+		return true, nil
+	}
+
 	// Buffer operations
 	if e.matchesBinding(keyStr, "next_buffer") {
 		if e.bufferCount() > 1 {
@@ -496,12 +962,44 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 		}
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "move_buffer_left") {
+		e.moveBuffer(-1)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "move_buffer_right") {
+		e.moveBuffer(1)
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "buffer_list") {
+		e.showBufferList()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "save_all") {
+		e.saveAll()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "close_all") {
+		e.closeAll()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "close_others") {
+		e.closeOthers()
+		return true, nil
+	}
 
 	// View toggles
 	if e.matchesBinding(keyStr, "toggle_line_numbers") {
 		e.toggleLineNumbers()
 		return true, nil
 	}
+	if e.matchesBinding(keyStr, "toggle_file_tree") {
+		e.toggleFileTree()
+		return true, nil
+	}
+	if e.matchesBinding(keyStr, "focus_file_tree") {
+		e.focusFileTree()
+		return true, nil
+	}
 
 	// Help
 	if e.matchesBinding(keyStr, "help") {
@@ -512,11 +1010,55 @@ func (e *Editor) handleConfigurableBinding(keyStr string, msg tea.KeyMsg) (bool,
 	return false, nil
 }
 
+// checkUnsavedReminder reminds the user about buffers that have been modified
+// for longer than the configured interval, or auto-saves the active one
+func (e *Editor) checkUnsavedReminder(now time.Time) {
+	if e.config == nil || e.config.Editor.UnsavedReminder <= 0 {
+		return
+	}
+	interval := time.Duration(e.config.Editor.UnsavedReminder) * time.Minute
+
+	for i, doc := range e.documents {
+		if !doc.modified {
+			doc.dirtySince = time.Time{}
+			doc.remindedAt = time.Time{}
+			continue
+		}
+		if doc.dirtySince.IsZero() {
+			doc.dirtySince = now
+			continue
+		}
+		if now.Sub(doc.dirtySince) < interval || now.Sub(doc.remindedAt) < interval {
+			continue
+		}
+		// Don't interrupt dialogs or prompts
+		if e.mode != ModeNormal {
+			return
+		}
+		doc.remindedAt = now
+
+		// Auto-save only the active named buffer, never stopping to ask: a
+		// save that needs an answer is skipped and the buffer stays modified
+		if e.config.Editor.ReminderAutosave && i == e.activeIdx && doc.filename != "" && !e.fileChangedOnDisk() {
+			if e.quietSave() {
+				doc.autosaved = true
+				e.statusbar.SetMessage("Auto-saved: "+filepath.Base(doc.filename), "success")
+			}
+			return
+		}
+
+		name := e.bufferName(doc)
+		minutes := int(now.Sub(doc.dirtySince).Minutes())
+		e.statusbar.SetMessage(fmt.Sprintf("%s has unsaved changes (%d min)", name, minutes), "warning")
+		return
+	}
+}
+
 // fileChangedOnDisk checks if the file has been modified externally since last load/save
 func (e *Editor) fileChangedOnDisk() bool {
 	doc := e.activeDoc()
-	if doc.filename == "" || doc.modTime.IsZero() {
-		return false
+	if doc.filename == "" || doc.modTime.IsZero() || doc.follow {
+		return false // Followed files are expected to change
 	}
 	fileInfo, err := os.Stat(doc.filename)
 	if err != nil {
@@ -536,6 +1078,9 @@ func NewWithConfig(cfg *config.Config) *Editor {
 	theme := cfg.Theme.GetResolved()
 	styles := ui.NewStyles(theme)
 
+	// Apply ambiguous-width policy before any width calculations
+	ui.SetAmbiguousWidth(cfg.Editor.AmbiguousWidth)
+
 	// Determine ASCII mode: config override or auto-detect from capabilities
 	caps := config.GetCapabilities()
 	asciiMode := caps.ShouldUseASCII(cfg.Editor.AsciiMode)
@@ -561,34 +1106,41 @@ func NewWithConfig(cfg *config.Config) *Editor {
 
 	scrollbar := ui.NewScrollbar(styles)
 
-	// Create minimap renderer - use Kitty graphics when available
+	// Create minimap renderer - use Kitty, iTerm2 or Sixel graphics when available
 	var minimapRenderer ui.MinimapController
-	if caps.KittyGraphics {
+	switch caps.MinimapGraphics(cfg.Editor.MinimapGraphics) {
+	case config.GraphicsKitty:
 		minimapRenderer = ui.NewKittyMinimapRenderer(styles, true)
-	} else {
+	case config.GraphicsITerm:
+		minimapRenderer = ui.NewITermMinimapRenderer(styles)
+	case config.GraphicsSixel:
+		minimapRenderer = ui.NewSixelMinimapRenderer(styles, caps.CellWidth, caps.CellHeight)
+	default:
 		minimapRenderer = ui.NewMinimapRenderer(styles)
 	}
 
 	e := &Editor{
-		documents:   []*Document{doc},
-		activeIdx:   0,
-		clipboard:   clipboard.New(os.Stdout),
-		menubar:     ui.NewMenuBar(styles),
-		statusbar:   ui.NewStatusBar(styles),
-		viewport:    ui.NewViewport(styles),
-		scrollbar:   scrollbar,
-		styles:      styles,
-		box:         box,
-		mode:        ModeNormal,
-		width:       80,
-		height:      24,
-		config:      cfg,
-		keybindings: config.LoadKeybindings(),
+		documents:    []*Document{doc},
+		activeIdx:    0,
+		clipboard:    clipboard.New(os.Stdout),
+		menubar:      ui.NewMenuBar(styles),
+		statusbar:    newStatusBar(styles, asciiMode),
+		viewport:     ui.NewViewport(styles),
+		scrollbar:    scrollbar,
+		styles:       styles,
+		box:          box,
+		mode:         ModeNormal,
+		width:        80,
+		height:       24,
+		config:       cfg,
+		keybindings:  config.LoadKeybindings(),
+		minimapHover: -1,
 		// Initialize column renderers
 		lineNumRenderer:  ui.NewLineNumberRenderer(styles),
 		textRenderer:     ui.NewTextRenderer(styles),
 		minimapRenderer:  minimapRenderer,
 		scrollbarAdapter: ui.NewScrollbarColumnAdapter(scrollbar),
+		fileTree:         ui.NewFileTreeRenderer(),
 	}
 
 	// Initialize compositor with default dimensions
@@ -599,6 +1151,11 @@ func NewWithConfig(cfg *config.Config) *Editor {
 
 	// Apply config settings
 	if cfg != nil {
+		e.clipboard.SetProvider(clipboard.ParseProvider(cfg.Editor.Clipboard))
+		e.clipboard.SetOSC52Read(cfg.Editor.ClipboardOSC52Read)
+		doc.undoStack.SetMaxBytes(e.undoBudget())
+		doc.undoStack.SetCheckpointInterval(e.undoCheckpointInterval())
+		e.themeModTime = e.themeFileModTime()
 		e.viewport.SetWordWrap(cfg.Editor.WordWrap)
 		e.viewport.ShowLineNumbers(cfg.Editor.LineNumbers)
 
@@ -636,6 +1193,14 @@ func NewWithConfig(cfg *config.Config) *Editor {
 			e.minimapRenderer.SetEnabled(true)
 			e.menubar.SetItemLabel(ui.ActionMinimap, "[x] Minimap")
 		}
+		e.updateHeatmapLabel()
+		e.updateAutoPairLabel()
+		e.updateHighlightWordLabel()
+
+		// Apply file tree setting
+		if cfg.Editor.FileTree {
+			e.showFileTree()
+		}
 
 		// Apply theme syntax colors
 		e.activeDoc().highlighter.SetColors(syntax.SyntaxColors{
@@ -653,6 +1218,16 @@ func NewWithConfig(cfg *config.Config) *Editor {
 	// Setup compositor columns AFTER config is applied
 	e.setupCompositorColumns()
 
+	e.statusbar.RegisterSegment(ui.StatusSegment{Name: gitSegment, Priority: 10})
+	e.registerNoteSegment()
+	e.registerIndentSegment()
+	e.registerFollowSegment()
+	e.loadHistory()
+
+	// Delete the Kitty minimap image on exit so it doesn't linger in the
+	// terminal's main screen
+	e.AddExitHook(func() string { return e.minimapRenderer.ClearImage() })
+
 	return e
 }
 
@@ -672,7 +1247,7 @@ func (e *Editor) LoadFile(filename string) error {
 	}
 
 	// Read file content and get mod time
-	rawContent, err := os.ReadFile(filename)
+	rawContent, err := readFile(filename)
 	if err != nil {
 		return err
 	}
@@ -714,8 +1289,15 @@ func (e *Editor) LoadFile(filename string) error {
 		currentDoc.filename = absPath
 		currentDoc.modified = false
 		currentDoc.modTime = modTime
+		currentDoc.changedOnDisk = false
+		currentDoc.autosaved = false
 		currentDoc.highlighter.SetFile(filename)
 		currentDoc.encoding = detectedEnc
+		currentDoc.readOnly = !fileWritable(absPath)
+		currentDoc.roWarned = false
+		currentDoc.hexView = false
+		currentDoc.hexData = nil
+		currentDoc.markSaved()
 	} else {
 		// Check buffer limit before creating new document
 		maxBuffers := 20 // default
@@ -732,24 +1314,47 @@ func (e *Editor) LoadFile(filename string) error {
 			buffer:      buf,
 			cursor:      NewCursor(buf),
 			selection:   NewSelection(),
-			undoStack:   NewUndoStack(1000),
+			undoStack:   e.newUndoStack(1000),
 			highlighter: syntax.New(filename),
 			filename:    absPath,
 			modified:    false,
 			scrollY:     0,
 			modTime:     modTime,
 			encoding:    detectedEnc,
+			readOnly:    !fileWritable(absPath),
 		}
+		doc.markSaved()
 		e.documents = append(e.documents, doc)
 		e.activeIdx = len(e.documents) - 1
 	}
 
+	e.activeDoc().indent = nil
+	if e.detectIndentEnabled() {
+		detectDocIndent(e.activeDoc())
+	}
+
+	if detection.Binary {
+		e.setHexView(e.activeDoc(), rawContent)
+	}
+
 	// Warn if encoding is unsupported
-	if detectedEnc != nil && !detectedEnc.Supported {
+	if e.activeDoc().hexView {
+		e.statusbar.SetMessage(binaryWarning, "warning")
+	} else if detectedEnc != nil && !detectedEnc.Supported {
 		e.statusbar.SetMessage("Warning: Unsupported encoding "+detectedEnc.Name, "error")
+	} else if archive, ok := archiveMember(absPath); ok {
+		e.statusbar.SetMessage(archiveWarning(archive), "warning")
+	} else if e.activeDoc().readOnly {
+		e.statusbar.SetMessage("Read-only: "+filepath.Base(absPath)+" - use Save As to keep changes", "warning")
+	} else if warning := e.hazardWarning(string(content)); warning != "" {
+		e.statusbar.SetMessage(warning, "warning")
+	} else if e.config == nil || e.config.Editor.OpenSummary {
+		e.statusbar.SetMessage(e.openSummary(), "info")
 	}
 
 	e.viewport.SetScrollY(0)
+	e.applyFileSettings()
+	e.loadNotes()
 	e.updateTitle()
 	e.updateMenuState()
 
@@ -771,10 +1376,18 @@ func (e *Editor) SaveFile() bool {
 		e.showPrompt("Save as: ", PromptSaveAs)
 		return false
 	}
+	if _, ok := archiveMember(e.activeDoc().filename); ok {
+		// Saving a file opened from an archive extracts it
+		e.showSaveAs()
+		return false
+	}
 
 	// Check for external changes
 	if e.fileChangedOnDisk() {
-		e.showPrompt("File changed on disk. Overwrite? (y/N): ", PromptFileChanged)
+		e.confirmDiscard("File Changed", "The file changed on disk since it was opened.\nOverwrite the external changes?", "Overwrite", 'o', func() tea.Cmd {
+			e.doSave()
+			return nil
+		})
 		return false
 	}
 
@@ -783,11 +1396,44 @@ func (e *Editor) SaveFile() bool {
 
 // doSave performs the actual file save
 func (e *Editor) doSave() bool {
-	// Create backup if enabled and file exists
+	if e.activeDoc().hexView {
+		e.statusbar.SetMessage("Hex view is read-only", "error")
+		return false
+	}
+	filename := e.activeDoc().filename
+	if _, ok := archiveMember(filename); ok {
+		e.statusbar.SetMessage(filepath.Base(filename)+" is inside an archive - use Save As to extract it", "error")
+		return false
+	}
+	if !e.checkInvisibles(func() bool {
+		e.activeDoc().filename = filename
+		return e.doSave()
+	}) {
+		return false
+	}
+	if !e.checkSavePreflight(func() bool {
+		e.activeDoc().filename = filename
+		return e.doSave()
+	}) {
+		return false
+	}
+	if !e.checkEncodingLoss(func() bool {
+		e.activeDoc().filename = filename
+		return e.doSave()
+	}) {
+		return false
+	}
+
+	// Create backup if enabled and file exists. One that can't be written to
+	// backup_dir doesn't stop the save; one beside the file does, since that
+	// folder is where the file is about to be written.
+	backupFailed := ""
 	if e.config != nil && e.config.Editor.BackupCount > 0 {
-		if err := e.createBackup(); err != nil {
+		if err := e.createBackup(); err != nil && e.backupDir() == "" {
 			e.statusbar.SetMessage("Backup failed: "+err.Error(), "error")
 			return false
+		} else if err != nil {
+			backupFailed = "Saved, but the backup failed: " + err.Error()
 		}
 	}
 
@@ -795,39 +1441,15 @@ func (e *Editor) doSave() bool {
 	var outputData []byte
 	docEnc := e.activeDoc().encoding
 
-	// Check for encoding loss (unless this is a confirmed lossy save)
-	if !e.pendingLossySave && docEnc != nil && docEnc.Supported && docEnc.Encoder != nil {
-		lossCount := enc.CheckEncodingLoss([]byte(content), docEnc)
-		if lossCount > 0 {
-			// Prompt for confirmation
-			e.pendingLossyCount = lossCount
-			e.pendingLossySave = true
-			e.pendingLossyInDialog = false
-			charWord := "character"
-			if lossCount > 1 {
-				charWord = "characters"
-			}
-			e.showPrompt(fmt.Sprintf("%d %s cannot be represented in %s. Save anyway? (y/N)", lossCount, charWord, docEnc.Name), PromptConfirmLossySave)
-			return false
-		}
-	}
-
 	// Encode to original encoding if supported, otherwise save as UTF-8
 	if docEnc != nil && docEnc.Supported {
-		if e.pendingLossySave {
-			// User confirmed lossy save - use replacement characters
-			outputData = enc.EncodeFromUTF8Lossy([]byte(content), docEnc)
-			e.pendingLossySave = false
-			e.pendingLossyCount = 0
-		} else {
-			var encErr error
-			outputData, encErr = enc.EncodeFromUTF8([]byte(content), docEnc)
-			if encErr != nil {
-				// This shouldn't happen if CheckEncodingLoss works correctly
-				e.statusbar.SetMessage("Encoding failed, saving as UTF-8", "warning")
-				outputData = []byte(content)
-				e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
-			}
+		var encErr error
+		outputData, encErr = e.encodeForSave(content, docEnc)
+		if encErr != nil {
+			// This shouldn't happen if FindEncodingLoss works correctly
+			e.statusbar.SetMessage("Encoding failed, saving as UTF-8", "warning")
+			outputData = []byte(content)
+			e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
 		}
 	} else {
 		// Unsupported encoding - convert to UTF-8
@@ -838,14 +1460,14 @@ func (e *Editor) doSave() bool {
 		}
 	}
 
-	err := os.WriteFile(e.activeDoc().filename, outputData, 0644)
-	if err != nil {
-		// Clean up Go's error message for user display
-		errMsg := err.Error()
-		errMsg = strings.TrimPrefix(errMsg, "open ")
-		e.statusbar.SetMessage("Save failed: "+errMsg, "error")
+	if err := safeWriteFile(e.activeDoc().filename, outputData); err != nil {
+		e.showSaveFailed(err, func() bool {
+			e.activeDoc().filename = filename
+			return e.doSave()
+		})
 		return false
 	}
+	e.recordLocalHistory(e.activeDoc().filename, outputData)
 
 	// Update stored mod time after successful save
 	if fileInfo, err := os.Stat(e.activeDoc().filename); err == nil {
@@ -853,9 +1475,23 @@ func (e *Editor) doSave() bool {
 	}
 
 	e.activeDoc().modified = false
+	e.activeDoc().readOnly = false
+	e.activeDoc().roWarned = false
+	e.activeDoc().autosaved = false
+	e.activeDoc().changedOnDisk = false
+	e.activeDoc().markSaved()
+	e.activeDoc().undoStack.Checkpoint("Saved")
 	e.statusbar.SetMessage("Saved: "+e.activeDoc().filename, "success")
+	if backupFailed != "" {
+		e.statusbar.SetMessage(backupFailed, "warning")
+	}
+	e.gitStale = true
+	e.noteArgSaved()
+	e.saveNotes()
 	e.updateTitle()
 	e.updateMenuState()
+	e.applyFileSettings() // Save As may have changed the file type
+	e.checkThemeFile()    // Saving the active theme's file applies it right away
 
 	// Track directory in recent dirs
 	if e.config != nil {
@@ -897,36 +1533,70 @@ func (e *Editor) createBackup() error {
 		backupCount = e.config.Editor.BackupCount
 	}
 
+	filename := e.activeDoc().filename
+	if dir := e.backupDir(); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
 	if backupCount == 1 {
 		// Simple backup: filename~
-		return os.WriteFile(e.activeDoc().filename+"~", src, mode)
+		return os.WriteFile(e.backupPath(filename, 0), src, mode)
 	}
 
 	// Numbered backups: rotate existing backups
 	// Delete oldest backup if it exists
-	oldestBackup := fmt.Sprintf("%s~%d~", e.activeDoc().filename, backupCount)
-	os.Remove(oldestBackup) // Ignore error if doesn't exist
+	os.Remove(e.backupPath(filename, backupCount)) // Ignore error if doesn't exist
 
 	// Rotate backups: ~2~ becomes ~3~, ~1~ becomes ~2~, etc.
 	for i := backupCount - 1; i >= 1; i-- {
-		oldPath := fmt.Sprintf("%s~%d~", e.activeDoc().filename, i)
-		newPath := fmt.Sprintf("%s~%d~", e.activeDoc().filename, i+1)
+		oldPath := e.backupPath(filename, i)
 		if _, err := os.Stat(oldPath); err == nil {
-			os.Rename(oldPath, newPath)
+			os.Rename(oldPath, e.backupPath(filename, i+1))
 		}
 	}
 
 	// Write new backup as ~1~ (newest)
-	return os.WriteFile(fmt.Sprintf("%s~1~", e.activeDoc().filename), src, mode)
+	return os.WriteFile(e.backupPath(filename, 1), src, mode)
 }
 
 // doSaveInDialog performs file save, showing errors in the dialog instead of status bar
 func (e *Editor) doSaveInDialog() bool {
-	// Create backup if enabled and file exists
+	if e.activeDoc().hexView {
+		e.fileBrowserError = "Hex view is read-only"
+		return false
+	}
+	filename := e.activeDoc().filename
+	resume := func() bool {
+		e.activeDoc().filename = filename
+		if e.doSaveInDialog() {
+			e.mode = ModeNormal
+			e.updateTitle()
+			return true
+		}
+		e.mode = ModeFileBrowser
+		return false
+	}
+	if !e.checkInvisibles(resume) {
+		return false
+	}
+	if err := e.preflightActiveSave(); err != nil {
+		e.fileBrowserError = "Cannot save: " + err.Error()
+		return false
+	}
+	if !e.checkEncodingLoss(resume) {
+		return false
+	}
+
+	// Create backup if enabled and file exists, as doSave does
+	backupFailed := ""
 	if e.config != nil && e.config.Editor.BackupCount > 0 {
-		if err := e.createBackup(); err != nil {
+		if err := e.createBackup(); err != nil && e.backupDir() == "" {
 			e.fileBrowserError = "Backup failed: " + err.Error()
 			return false
+		} else if err != nil {
+			backupFailed = "Saved, but the backup failed: " + err.Error()
 		}
 	}
 
@@ -934,40 +1604,15 @@ func (e *Editor) doSaveInDialog() bool {
 	var outputData []byte
 	docEnc := e.activeDoc().encoding
 
-	// Check for encoding loss (unless this is a confirmed lossy save)
-	if !e.pendingLossySave && docEnc != nil && docEnc.Supported && docEnc.Encoder != nil {
-		lossCount := enc.CheckEncodingLoss([]byte(content), docEnc)
-		if lossCount > 0 {
-			// Prompt for confirmation
-			e.pendingLossyCount = lossCount
-			e.pendingLossySave = true
-			e.pendingLossyInDialog = true
-			charWord := "character"
-			if lossCount > 1 {
-				charWord = "characters"
-			}
-			e.showPrompt(fmt.Sprintf("%d %s cannot be represented in %s. Save anyway? (y/N)", lossCount, charWord, docEnc.Name), PromptConfirmLossySave)
-			return false
-		}
-	}
-
 	// Encode to original encoding if supported, otherwise save as UTF-8
 	if docEnc != nil && docEnc.Supported {
-		if e.pendingLossySave {
-			// User confirmed lossy save - use replacement characters
-			outputData = enc.EncodeFromUTF8Lossy([]byte(content), docEnc)
-			e.pendingLossySave = false
-			e.pendingLossyCount = 0
-			e.pendingLossyInDialog = false
-		} else {
-			var encErr error
-			outputData, encErr = enc.EncodeFromUTF8([]byte(content), docEnc)
-			if encErr != nil {
-				// This shouldn't happen if CheckEncodingLoss works correctly
-				e.statusbar.SetMessage("Encoding failed, saving as UTF-8", "warning")
-				outputData = []byte(content)
-				e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
-			}
+		var encErr error
+		outputData, encErr = e.encodeForSave(content, docEnc)
+		if encErr != nil {
+			// This shouldn't happen if FindEncodingLoss works correctly
+			e.statusbar.SetMessage("Encoding failed, saving as UTF-8", "warning")
+			outputData = []byte(content)
+			e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
 		}
 	} else {
 		outputData = []byte(content)
@@ -976,19 +1621,29 @@ func (e *Editor) doSaveInDialog() bool {
 		}
 	}
 
-	err := os.WriteFile(e.activeDoc().filename, outputData, 0644)
-	if err != nil {
-		// Clean up Go's error message for dialog display
-		errMsg := err.Error()
-		errMsg = strings.TrimPrefix(errMsg, "open ")
-		e.fileBrowserError = "Save failed: " + errMsg
+	if err := safeWriteFile(e.activeDoc().filename, outputData); err != nil {
+		e.fileBrowserError = "Save failed: " + saveErrorMessage(err)
 		return false
 	}
+	e.recordLocalHistory(e.activeDoc().filename, outputData)
 
 	e.activeDoc().modified = false
+	e.activeDoc().readOnly = false
+	e.activeDoc().roWarned = false
+	e.activeDoc().autosaved = false
+	e.activeDoc().changedOnDisk = false
+	e.activeDoc().markSaved()
+	e.activeDoc().undoStack.Checkpoint("Saved")
 	e.fileBrowserError = ""
 	e.statusbar.SetMessage("Saved: "+e.activeDoc().filename, "success")
+	if backupFailed != "" {
+		e.statusbar.SetMessage(backupFailed, "warning")
+	}
+	e.gitStale = true
+	e.noteArgSaved()
+	e.saveNotes()
 	e.updateMenuState()
+	e.applyFileSettings()
 
 	// Track directory in recent dirs
 	if e.config != nil {
@@ -1003,11 +1658,58 @@ func (e *Editor) doSaveInDialog() bool {
 func (e *Editor) showPrompt(text string, action PromptAction) {
 	e.promptText = text
 	e.promptInput = ""
+	e.promptCursor = 0
 	e.promptAction = action
+	e.gotoHistory.reset()
 	e.mode = ModePrompt
 	e.updateViewportSize()
 }
 
+// Smallest terminal the full interface is drawn in
+const (
+	minTermWidth  = 40
+	minTermHeight = 10
+)
+
+// tooSmall reports whether the terminal is below the size the interface
+// is drawn in. Dialogs aren't shown then, so they get no keys or clicks.
+func (e *Editor) tooSmall() bool {
+	return e.width < minTermWidth || e.height < minTermHeight
+}
+
+// tooSmallView replaces the interface with a notice when the terminal is too
+// small to draw menus and dialogs without them overlapping
+func (e *Editor) tooSmallView() string {
+	msg := []string{
+		"Terminal too small",
+		fmt.Sprintf("%dx%d, need %dx%d", e.width, e.height, minTermWidth, minTermHeight),
+	}
+	if e.activeDoc().modified {
+		msg = append(msg, "(unsaved changes)")
+	}
+	top := (e.height - len(msg)) / 2
+	if top < 0 {
+		top = 0
+	}
+	lines := make([]string, e.height)
+	for i, text := range msg {
+		if top+i >= len(lines) {
+			break
+		}
+		pad := (e.width - runewidth.StringWidth(text)) / 2
+		if pad < 0 {
+			pad = 0
+		}
+		lines[top+i] = runewidth.Truncate(strings.Repeat(" ", pad)+text, e.width, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// OnFirstRender registers a callback run once after the first frame is rendered
+func (e *Editor) OnFirstRender(f func()) {
+	e.onFirstRender = f
+}
+
 // Init implements tea.Model
 func (e *Editor) Init() tea.Cmd {
 	e.updateTitle()
@@ -1015,15 +1717,47 @@ func (e *Editor) Init() tea.Cmd {
 	return tea.Batch(
 		tea.EnterAltScreen,
 		tea.EnableMouseAllMotion,
-		fileCheckCmd(), // Start periodic file change detection
+		fileCheckCmd(),     // Start periodic file change detection
+		reminderCheckCmd(), // Start periodic unsaved-changes reminders
+		themeCheckCmd(),    // Start theme file hot-reload
 	)
 }
 
 // Update implements tea.Model
 func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	// Check for pending quit (after user confirmed discard)
+	defer e.recoverPanic()
+	if _, ok := msg.(tea.KeyMsg); ok {
+		e.minimapHover = -1 // Typing hides the minimap tooltip
+	}
+	mode, message := e.mode, ""
+	if e.debugLog != nil {
+		message, _ = e.statusbar.Message()
+	}
+	model, cmd := e.update(msg)
+	if resume := e.resumeSave(); resume != nil {
+		cmd = tea.Batch(cmd, resume)
+	}
+	if sync := e.schedulePrimarySync(); sync != nil {
+		cmd = tea.Batch(cmd, sync)
+	}
+	e.syncLineNumberWidth()
+	e.anchorNotes()
+	e.autosaveOnSwitch()
+	e.touchActiveBuffer()
+	e.updateTitle() // Keeps the modified marker in step with edits and saves
+	// Follow the active file's repository across saves and buffer switches
+	if refresh := e.gitRefreshCmd(); refresh != nil {
+		cmd = tea.Batch(cmd, refresh)
+	}
+	e.logUpdate(msg, mode, message)
+	return model, cmd
+}
+
+// update handles a single message for Update
+func (e *Editor) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Check for pending quit (after user confirmed discard)
 	if e.pendingQuit {
-		return e, tea.Quit
+		return e, e.quit()
 	}
 
 	switch msg := msg.(type) {
@@ -1033,19 +1767,118 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		e.menubar.SetWidth(msg.Width)
 		e.statusbar.SetWidth(msg.Width)
 		e.updateViewportSize()
+		if db := e.scrollableDialog(); db != nil {
+			e.dialogScroll = min(e.dialogScroll, db.MaxScroll())
+		}
+		if e.revealCursor {
+			e.revealCursor = false
+			doc := e.activeDoc()
+			e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+		}
 		return e, nil
 
 	case fileCheckMsg:
+		if e.unfocused {
+			e.paused.fileCheck = true
+			return e, nil
+		}
 		// Periodic check for external file changes
-		if e.fileChangedOnDisk() && e.mode == ModeNormal {
+		e.reloadChangedFiles()
+		e.activeDoc().changedOnDisk = e.fileChangedOnDisk()
+		if e.activeDoc().changedOnDisk && e.mode == ModeNormal {
 			e.statusbar.SetMessage("File changed on disk!", "error")
 		}
+		e.gitStale = true        // Pick up commits and checkouts made outside the editor
 		return e, fileCheckCmd() // Schedule next check
 
+	case reminderCheckMsg:
+		if e.unfocused {
+			e.paused.reminder = true
+			return e, nil
+		}
+		e.checkUnsavedReminder(time.Now())
+		return e, reminderCheckCmd()
+
+	case themeCheckMsg:
+		if e.unfocused {
+			e.paused.theme = true
+			return e, nil
+		}
+		e.checkThemeFile()
+		return e, themeCheckCmd()
+
+	case primarySyncMsg:
+		e.syncPrimary(msg)
+		return e, nil
+
+	case followTickMsg:
+		return e, e.checkFollowed(msg)
+
+	case tea.BlurMsg:
+		e.focusLost()
+		return e, nil
+
+	case tea.FocusMsg:
+		return e, e.focusGained()
+
+	case chordTimeoutMsg:
+		if msg.seq == e.chordSeq && e.chordPrefix != "" {
+			e.chordPrefix = ""
+			e.statusbar.SetMessage("Chord cancelled", "info")
+		}
+		return e, nil
+
+	case StatusSegmentMsg:
+		e.statusbar.SetSegmentText(msg.Name, msg.Text)
+		return e, nil
+
+	case gitStatusMsg:
+		return e, e.applyGitStatus(msg)
+
+	case quickOpenIndexMsg:
+		e.applyQuickOpenIndex(msg)
+		return e, nil
+
+	case printDoneMsg:
+		e.applyPrintDone(msg)
+		return e, nil
+
+	case insertOutputMsg:
+		e.applyInsertOutput(msg)
+		return e, nil
+
+	case osc52TimeoutMsg:
+		if msg.seq == e.osc52Seq && e.osc52Pending {
+			e.osc52Pending = false
+			e.pasteClipboard()
+		}
+		return e, nil
+
 	case tea.KeyMsg:
-		return e.handleKey(msg)
+		if e.handleOSC52Key(msg) {
+			return e, nil
+		}
+		if e.tooSmall() && e.mode != ModeNormal {
+			return e, nil
+		}
+		if e.registerOp != registerOpNone {
+			e.handleRegisterKey(msg)
+			e.warnReadOnlyEdit()
+			return e, nil
+		}
+		var rename *tagRename
+		if e.mode == ModeNormal && isTagEditKey(msg) {
+			rename = e.tagRenameAtCursor()
+		}
+		model, cmd := e.handleKey(msg)
+		e.syncTagRename(rename)
+		e.warnReadOnlyEdit()
+		return model, cmd
 
 	case tea.MouseMsg:
+		if e.tooSmall() {
+			return e, nil // Nothing is drawn to click on
+		}
 		// Route mouse to dialog handlers if applicable
 		if e.mode == ModeFileBrowser {
 			return e.handleFileBrowserMouse(msg)
@@ -1068,17 +1901,53 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if e.mode == ModeConfigError {
 			return e.handleConfigErrorMouse(msg)
 		}
+		if e.mode == ModeConfirm {
+			return e.handleConfirmMouse(msg)
+		}
 		if e.mode == ModeSettings {
 			return e.handleSettingsMouse(msg)
 		}
 		if e.mode == ModeEncoding {
 			return e.handleEncodingMouse(msg)
 		}
-		if e.mode == ModeHelp {
-			return e.handleHelpMouse(msg)
+		if db := e.scrollableDialog(); db != nil {
+			return e.handleScrollableDialogMouse(db, msg)
+		}
+		if e.mode == ModePasteHistory {
+			return e.handlePasteHistoryMouse(msg)
+		}
+		if e.mode == ModeInsertBuffer {
+			return e.handleInsertBufferMouse(msg)
+		}
+		if e.mode == ModeAnnotations {
+			return e.handleAnnotationsMouse(msg)
+		}
+		if e.mode == ModeQuickOpen {
+			return e.handleQuickOpenMouse(msg)
+		}
+		if e.mode == ModeBufferList {
+			return e.handleBufferListMouse(msg)
 		}
-		if e.mode == ModeAbout {
-			return e.handleAboutMouse(msg)
+		if e.mode == ModeUndoHistory {
+			return e.handleUndoHistoryMouse(msg)
+		}
+		if e.mode == ModeIndent {
+			return e.handleIndentMouse(msg)
+		}
+		if e.mode == ModeRestoreBackup {
+			return e.handleRestoreBackupMouse(msg)
+		}
+		if e.mode == ModeLocalHistory {
+			return e.handleLocalHistoryMouse(msg)
+		}
+		if e.mode == ModeSymbols {
+			return e.handleSymbolsMouse(msg)
+		}
+		if e.mode == ModePrint {
+			return e.handlePrintMouse(msg)
+		}
+		if e.mode == ModeInsertText {
+			return e.handleInsertTextMouse(msg)
 		}
 		return e.handleMouse(msg)
 	}
@@ -1088,10 +1957,23 @@ func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // setupCompositorColumns configures the compositor columns based on current settings.
 func (e *Editor) setupCompositorColumns() {
+	minimapWidth := 0
+	if e.minimapRenderer.IsEnabled() {
+		minimapWidth = ui.MinimapWidth()
+	}
+	e.viewport.SetMinimapWidth(minimapWidth)
+
 	columns := []ui.Column{
-		// Line numbers (fixed width 5)
+		// File tree sidebar (fixed width)
+		{
+			Width:    ui.FileTreeWidth(),
+			Flexible: false,
+			Enabled:  e.fileTree.IsEnabled(),
+			Renderer: e.fileTree,
+		},
+		// Line numbers (wide enough for the last line's number)
 		{
-			Width:    5,
+			Width:    ui.LineNumberColumnWidth(e.activeDoc().buffer.LineCount()),
 			Flexible: false,
 			Enabled:  e.viewport.ShowLineNum(),
 			Renderer: e.lineNumRenderer,
@@ -1121,6 +2003,18 @@ func (e *Editor) setupCompositorColumns() {
 	e.compositor.SetColumns(columns)
 }
 
+// syncLineNumberWidth sizes the line number column to the active document,
+// keeping the cursor in view when the text area changes width
+func (e *Editor) syncLineNumberWidth() {
+	doc := e.activeDoc()
+	before := e.viewport.LineNumberWidth()
+	e.viewport.SetLineCount(doc.buffer.LineCount())
+	if e.viewport.LineNumberWidth() != before {
+		e.setupCompositorColumns()
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	}
+}
+
 // updateViewportSize recalculates the viewport size based on current state
 func (e *Editor) updateViewportSize() {
 	// Viewport height = total height - menu bar (1) - status bar (1)
@@ -1160,19 +2054,9 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 	selectionMap := make(map[int]ui.SelectionRange)
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		start, end := e.activeDoc().selection.Normalize()
-		startLine, startCol := e.activeDoc().buffer.PositionToLineCol(start)
-		endLine, endCol := e.activeDoc().buffer.PositionToLineCol(end)
-
-		for line := startLine; line <= endLine; line++ {
-			sr := ui.SelectionRange{Start: 0, End: -1}
-			if line == startLine {
-				sr.Start = startCol
-			}
-			if line == endLine {
-				sr.End = endCol
-			}
-			selectionMap[line] = sr
-		}
+		lineRanges(e.activeDoc().buffer, lines, start, end, func(line int, r ui.SelectionRange) {
+			selectionMap[line] = r
+		})
 	}
 
 	// Generate syntax highlighting colors
@@ -1190,10 +2074,10 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 				endLine = len(lines)
 			}
 		}
-		for i := startLine; i < endLine; i++ {
-			colors := e.activeDoc().highlighter.GetLineColors(lines[i])
+		spans := e.activeDoc().highlighter.DocumentColors(lines, e.activeDoc().buffer.Version(), startLine, endLine)
+		for i, colors := range spans {
 			if len(colors) > 0 {
-				lineColors[i] = colors
+				lineColors[startLine+i] = colors
 			}
 		}
 	}
@@ -1204,18 +2088,57 @@ func (e *Editor) buildRenderState() *ui.RenderState {
 		totalVisualLines = e.viewport.CountVisualLines(lines)
 	}
 
+	var tree []ui.TreeRow
+	treeActive := -1
+	if e.fileTree.IsEnabled() {
+		tree, treeActive = e.treeRenderRows()
+	}
+	treeExpanded, treeCollapsed := e.treeIcons()
+
+	fs := e.fileSettings()
+	heat := e.lineHeat(lines)
+	moreLeft, moreRight := "", ""
+	if e.scrollMarkersEnabled() {
+		moreLeft, moreRight = e.box.MoreLeft, e.box.MoreRight
+	}
+	label := ""
+	if heat != nil {
+		label = ui.ReadingTime(e.activeDoc().buffer.WordCount())
+	}
 	return &ui.RenderState{
 		Lines:            lines,
 		CursorLine:       e.activeDoc().cursor.Line(),
-		CursorCol:        e.activeDoc().cursor.Col(),
+		CursorCol:        e.cursorRuneCol(lines),
 		ScrollY:          e.viewport.ScrollY(),
 		ScrollX:          e.viewport.ScrollX(),
 		Selection:        selectionMap,
+		ExtraSelections:  e.multiSelectionMap(),
+		AnnotatedLines:   e.annotatedLines(),
+		AnnotationMarker: e.box.Note,
+		Occurrences:      e.occurrenceMap(lines),
+		Hazards:          e.hazardMap(lines),
+		LineHeat:         heat,
+		MinimapLabel:     label,
+		ChangedLines:     e.lineChanges(lines),
 		LineColors:       lineColors,
 		WordWrap:         e.viewport.WordWrap(),
-		TabWidth:         e.config.Editor.TabWidth,
+		TabWidth:         fs.TabWidth,
+		WrapWidth:        e.viewport.TextWidth(),
+		Rulers:           fs.Rulers,
+		RulerChar:        e.box.Vertical,
+		MoreLeft:         moreLeft,
+		MoreRight:        moreRight,
 		TotalLines:       len(lines),
 		TotalVisualLines: totalVisualLines,
+		Tree:             tree,
+		TreeScroll:       e.treeScroll,
+		TreeSelected:     e.treeSelected,
+		TreeFocused:      e.treeFocused,
+		TreeActive:       treeActive,
+		TreeExpanded:     treeExpanded,
+		TreeCollapsed:    treeCollapsed,
+		TreeEllipsis:     e.box.Ellipsis,
+		TreeBorder:       e.box.Vertical,
 		Styles:           e.styles,
 	}
 }
@@ -1242,16 +2165,54 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e.handlePromptKey(msg)
 	}
 
-	// Handle help mode - any key dismisses
-	if e.mode == ModeHelp {
-		e.mode = ModeNormal
-		return e, nil
+	// Handle help, about and statistics - any key dismisses, except that
+	// the scroll keys scroll one that doesn't fit
+	if db := e.scrollableDialog(); db != nil {
+		return e.handleScrollableDialogKey(db, msg)
 	}
 
-	// Handle about mode - any key dismisses
-	if e.mode == ModeAbout {
-		e.mode = ModeNormal
-		return e, nil
+	// Handle Paste from History dialog
+	if e.mode == ModePasteHistory {
+		return e.handlePasteHistoryKey(msg)
+	}
+
+	// Handle Insert Buffer dialog
+	if e.mode == ModeInsertBuffer {
+		return e.handleInsertBufferKey(msg)
+	}
+
+	// Handle Annotations dialog
+	if e.mode == ModeAnnotations {
+		return e.handleAnnotationsKey(msg)
+	}
+
+	// Handle Quick Open dialog
+	if e.mode == ModeQuickOpen {
+		return e.handleQuickOpenKey(msg)
+	}
+	if e.mode == ModeBufferList {
+		return e.handleBufferListKey(msg)
+	}
+	if e.mode == ModeUndoHistory {
+		return e.handleUndoHistoryKey(msg)
+	}
+	if e.mode == ModeIndent {
+		return e.handleIndentKey(msg)
+	}
+	if e.mode == ModeRestoreBackup {
+		return e.handleRestoreBackupKey(msg)
+	}
+	if e.mode == ModeLocalHistory {
+		return e.handleLocalHistoryKey(msg)
+	}
+	if e.mode == ModeSymbols {
+		return e.handleSymbolsKey(msg)
+	}
+	if e.mode == ModePrint {
+		return e.handlePrintKey(msg)
+	}
+	if e.mode == ModeInsertText {
+		return e.handleInsertTextKey(msg)
 	}
 
 	// Handle config error mode
@@ -1259,6 +2220,11 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e.handleConfigErrorKey(msg)
 	}
 
+	// Handle confirm dialog mode
+	if e.mode == ModeConfirm {
+		return e.handleConfirmKey(msg)
+	}
+
 	// Handle settings mode
 	if e.mode == ModeSettings {
 		return e.handleSettingsKey(msg)
@@ -1302,9 +2268,47 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Clear status message on any key
 	e.statusbar.ClearMessage()
 
+	// Second key of a pending chord
+	if e.chordPrefix != "" {
+		return e, e.finishChord(msg)
+	}
+
+	// The file tree takes navigation keys while it has focus
+	if e.treeFocused && e.fileTree.IsEnabled() {
+		if handled, cmd := e.handleTreeKey(msg); handled {
+			return e, cmd
+		}
+	}
+
+	// Typing edits every range of a multi-selection
+	if len(e.activeDoc().multiSel) > 0 {
+		if handled, cmd := e.handleMultiSelKey(msg); handled {
+			return e, cmd
+		}
+	}
+
+	// vi profile translates keys before the regular bindings see them
+	if e.viEnabled() {
+		if handled, cmd := e.handleViKey(msg); handled {
+			return e, cmd
+		}
+	}
+
+	// emacs profile handles its Ctrl/Meta keys before the regular bindings
+	if e.emacsEnabled() {
+		if handled, cmd := e.handleEmacsKey(msg); handled {
+			return e, cmd
+		}
+	}
+
 	// Get key string for matching against configurable bindings
 	keyStr := msg.String()
 
+	// First key of a chord waits for the second
+	if e.keybindings.IsChordPrefix(keyStr) {
+		return e, e.startChord(keyStr)
+	}
+
 	// Check configurable keybindings first
 	if handled, cmd := e.handleConfigurableBinding(keyStr, msg); handled {
 		return e, cmd
@@ -1322,31 +2326,11 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeyShiftUp:
-		e.moveWithSelection(func() bool {
-			if e.viewport.WordWrap() {
-				newLine, newCol := e.viewport.MoveUpVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-				if newLine == e.activeDoc().cursor.Line() && newCol == e.activeDoc().cursor.Col() {
-					return false
-				}
-				e.activeDoc().cursor.SetPosition(newLine, newCol)
-				return true
-			}
-			return e.activeDoc().cursor.MoveUp()
-		})
+		e.moveWithSelection(e.moveUpVisual)
 		return e, nil
 
 	case tea.KeyShiftDown:
-		e.moveWithSelection(func() bool {
-			if e.viewport.WordWrap() {
-				newLine, newCol := e.viewport.MoveDownVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-				if newLine == e.activeDoc().cursor.Line() && newCol == e.activeDoc().cursor.Col() {
-					return false
-				}
-				e.activeDoc().cursor.SetPosition(newLine, newCol)
-				return true
-			}
-			return e.activeDoc().cursor.MoveDown()
-		})
+		e.moveWithSelection(e.moveDownVisual)
 		return e, nil
 
 	case tea.KeyShiftHome:
@@ -1410,23 +2394,13 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyUp:
 		e.activeDoc().selection.Clear()
-		if e.viewport.WordWrap() {
-			newLine, newCol := e.viewport.MoveUpVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-			e.activeDoc().cursor.SetPosition(newLine, newCol)
-		} else {
-			e.activeDoc().cursor.MoveUp()
-		}
+		e.moveUpVisual()
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
 	case tea.KeyDown:
 		e.activeDoc().selection.Clear()
-		if e.viewport.WordWrap() {
-			newLine, newCol := e.viewport.MoveDownVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-			e.activeDoc().cursor.SetPosition(newLine, newCol)
-		} else {
-			e.activeDoc().cursor.MoveDown()
-		}
+		e.moveDownVisual()
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
@@ -1443,37 +2417,11 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeyPgUp:
-		// Throttle to prevent key queue buildup
-		if time.Since(e.lastPageKey) < 50*time.Millisecond {
-			return e, nil
-		}
-		e.lastPageKey = time.Now()
-
-		// Move cursor up by one page
-		pageSize := e.viewport.Height() - 1 // Keep 1 line of context
-		for i := 0; i < pageSize; i++ {
-			if !e.activeDoc().cursor.MoveUp() {
-				break
-			}
-		}
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		e.scrollPage(-1)
 		return e, nil
 
 	case tea.KeyPgDown:
-		// Throttle to prevent key queue buildup
-		if time.Since(e.lastPageKey) < 50*time.Millisecond {
-			return e, nil
-		}
-		e.lastPageKey = time.Now()
-
-		// Move cursor down by one page
-		pageSize := e.viewport.Height() - 1 // Keep 1 line of context
-		for i := 0; i < pageSize; i++ {
-			if !e.activeDoc().cursor.MoveDown() {
-				break
-			}
-		}
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+		e.scrollPage(1)
 		return e, nil
 
 	// Text editing keys
@@ -1486,8 +2434,8 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// If there's a selection, indent all selected lines
 		if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 			e.indentLines()
-		} else {
-			// No selection - insert tab/spaces based on config
+		} else if !e.expandSnippet() {
+			// No selection or snippet - insert tab/spaces based on config
 			e.insertText(e.getIndentString())
 		}
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
@@ -1500,7 +2448,9 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeyBackspace:
-		e.backspace()
+		if !e.backspacePair() {
+			e.backspace()
+		}
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
@@ -1509,7 +2459,7 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return e, nil
 
 	case tea.KeySpace:
-		e.insertChar(' ')
+		e.typeRune(' ')
 		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 		return e, nil
 
@@ -1562,7 +2512,14 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Regular character input - skip control characters (ASCII 0-31 except tab)
 		for _, r := range msg.Runes {
 			if r >= 32 || r == '\t' {
-				e.insertChar(r)
+				if msg.Paste {
+					e.insertChar(r)
+				} else {
+					e.typeRune(r)
+				}
+				if r == '/' && !msg.Paste {
+					e.autoCloseTag()
+				}
 			}
 		}
 		if len(msg.Runes) > 0 {
@@ -1609,9 +2566,6 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		e.menubar.OpenMenu(0)
 		e.updateViewportSize()
 		return e, nil
-	case "f2":
-		e.insertLoremIpsum()
-		return e, nil
 
 	// Shift+arrow selection (string-based fallback)
 	case "shift+left":
@@ -1621,30 +2575,10 @@ func (e *Editor) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		e.moveWithSelection(e.activeDoc().cursor.MoveRight)
 		return e, nil
 	case "shift+up":
-		e.moveWithSelection(func() bool {
-			if e.viewport.WordWrap() {
-				newLine, newCol := e.viewport.MoveUpVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-				if newLine == e.activeDoc().cursor.Line() && newCol == e.activeDoc().cursor.Col() {
-					return false
-				}
-				e.activeDoc().cursor.SetPosition(newLine, newCol)
-				return true
-			}
-			return e.activeDoc().cursor.MoveUp()
-		})
+		e.moveWithSelection(e.moveUpVisual)
 		return e, nil
 	case "shift+down":
-		e.moveWithSelection(func() bool {
-			if e.viewport.WordWrap() {
-				newLine, newCol := e.viewport.MoveDownVisual(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-				if newLine == e.activeDoc().cursor.Line() && newCol == e.activeDoc().cursor.Col() {
-					return false
-				}
-				e.activeDoc().cursor.SetPosition(newLine, newCol)
-				return true
-			}
-			return e.activeDoc().cursor.MoveDown()
-		})
+		e.moveWithSelection(e.moveDownVisual)
 		return e, nil
 	case "shift+home":
 		e.moveWithSelection(func() bool {
@@ -1693,6 +2627,67 @@ func (e *Editor) moveWithSelection(move func() bool) {
 	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 }
 
+// moveUpVisual moves the cursor up one screen row, keeping its visual column
+// across tabs, wide characters and wrapped lines
+func (e *Editor) moveUpVisual() bool {
+	return e.moveVisual(-1)
+}
+
+// moveDownVisual moves the cursor down one screen row, keeping its visual column
+func (e *Editor) moveDownVisual() bool {
+	return e.moveVisual(1)
+}
+
+// moveVisual moves the cursor rows screen rows, down when positive, toward
+// the cursor's desired column. Reports whether it moved.
+func (e *Editor) moveVisual(rows int) bool {
+	cursor := e.activeDoc().cursor
+	lines := e.activeDoc().buffer.Lines()
+	line, col := cursor.Line(), cursor.Col()
+	goal, ok := cursor.DesiredCol()
+	if !ok {
+		goal = e.viewport.VisualX(lines, line, col)
+	}
+	newLine, newCol := e.viewport.MoveVisualRows(lines, line, col, rows, goal)
+	if newLine == line && newCol == col {
+		return false
+	}
+	cursor.SetPosition(newLine, newCol)
+	cursor.SetDesiredCol(goal)
+	return true
+}
+
+// centerCursor scrolls the cursor's line to the middle of the view, and
+// back to the cursor's column if the view was scrolled sideways off it
+func (e *Editor) centerCursor() {
+	doc := e.activeDoc()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.viewport.CenterCursor(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// scrollPage moves the view and the cursor a page, less a line of context,
+// down when dir is positive
+func (e *Editor) scrollPage(dir int) {
+	e.scrollRows(dir * max(e.viewport.Height()-1, 1))
+}
+
+// scrollHalfPage moves the cursor and the view half a screen, down when dir
+// is positive
+func (e *Editor) scrollHalfPage(dir int) {
+	e.scrollRows(dir * max(e.viewport.Height()/2, 1))
+}
+
+// scrollRows scrolls the view rows screen rows and moves the cursor as far,
+// so it keeps its place on screen where the text allows
+func (e *Editor) scrollRows(rows int) {
+	doc := e.activeDoc()
+	doc.selection.Clear()
+	e.moveVisual(rows)
+	lines := doc.buffer.Lines()
+	e.viewport.ScrollBy(lines, rows)
+	e.viewport.EnsureCursorVisibleWrapped(lines, doc.cursor.Line(), doc.cursor.Col())
+}
+
 // handleMenuKey handles keyboard input in menu mode
 func (e *Editor) handleMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
@@ -1745,29 +2740,40 @@ func (e *Editor) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		e.statusbar.SetMessage("Cancelled", "info")
 
 	case tea.KeyEnter:
+		if e.promptAction == PromptInsertCommand {
+			e.mode = ModeNormal
+			e.updateViewportSize()
+			return e, e.runInsertCommand(strings.TrimSpace(e.promptInput))
+		}
 		oldPromptAction := e.promptAction
 		e.executePrompt()
 		// If quit was confirmed, exit immediately
 		if e.pendingQuit {
-			return e, tea.Quit
+			return e, e.quit()
 		}
 		// Only return to normal mode if executePrompt didn't set up another prompt
-		// (showPrompt changes promptAction)
-		if e.promptAction == oldPromptAction {
+		// (showPrompt changes promptAction) or open a dialog
+		if e.mode == ModePrompt && e.promptAction == oldPromptAction {
 			e.mode = ModeNormal
 			e.updateViewportSize()
 		}
 
-	case tea.KeyBackspace:
-		if len(e.promptInput) > 0 {
-			e.promptInput = e.promptInput[:len(e.promptInput)-1]
+	case tea.KeyUp, tea.KeyDown:
+		if e.promptAction == PromptGoToLine {
+			recallHistory(&e.gotoHistory, &e.promptInput, msg.Type == tea.KeyUp)
+			e.promptCursor = 0
 		}
 
-	case tea.KeyRunes:
-		e.promptInput += string(msg.Runes)
+	case tea.KeyTab:
+		if e.promptAction == PromptSaveAs || e.promptAction == PromptOpen {
+			e.promptInput, _ = e.completePath(e.promptInput, "")
+			e.promptCursor = 0
+		}
 
-	case tea.KeySpace:
-		e.promptInput += " "
+	default:
+		if e.editInput(&e.promptInput, &e.promptCursor, msg) {
+			e.gotoHistory.reset()
+		}
 	}
 
 	return e, nil
@@ -1782,12 +2788,7 @@ func (e *Editor) executePrompt() {
 		if input != "" {
 			// Check if file already exists
 			if _, err := os.Stat(input); err == nil {
-				// File exists - ask for confirmation
-				e.pendingFilename = input
-				e.promptText = "File exists. Overwrite? (y/N): "
-				e.promptInput = ""
-				e.promptAction = PromptConfirmOverwrite
-				e.mode = ModePrompt // Stay in prompt mode
+				e.confirmOverwrite(input)
 				return
 			}
 			e.activeDoc().filename = input
@@ -1796,44 +2797,12 @@ func (e *Editor) executePrompt() {
 			e.statusbar.SetMessage("Save cancelled - no filename", "info")
 		}
 
-	case PromptConfirmOverwrite:
-		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-			e.activeDoc().filename = e.pendingFilename
-			e.doSave()
-		} else {
-			e.statusbar.SetMessage("Save cancelled", "info")
-		}
-		e.pendingFilename = ""
-
-	case PromptConfirmLossySave:
-		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-			// Proceed with lossy save (pendingLossySave is already true)
-			if e.pendingLossyInDialog {
-				if e.doSaveInDialog() {
-					e.mode = ModeNormal
-					e.updateTitle()
-				} else {
-					e.mode = ModeFileBrowser
-				}
-			} else {
-				e.doSave()
-			}
-		} else {
-			e.statusbar.SetMessage("Save cancelled", "info")
-			e.pendingLossySave = false
-			e.pendingLossyCount = 0
-			if e.pendingLossyInDialog {
-				e.mode = ModeFileBrowser
-			}
-		}
-		e.pendingLossyInDialog = false
-
 	case PromptOpen:
 		if input != "" {
 			if err := e.LoadFile(input); err != nil {
 				e.statusbar.SetMessage("Error: "+err.Error(), "error")
 			} else {
-				e.statusbar.SetMessage("Opened: "+input, "success")
+				e.reportOpened(input)
 				e.updateTitle()
 			}
 		}
@@ -1854,51 +2823,16 @@ func (e *Editor) executePrompt() {
 			e.statusbar.SetMessage("Cancelled", "info")
 		}
 
-	case PromptConfirmClose:
-		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-			e.doCloseFile()
-		} else {
-			e.statusbar.SetMessage("Cancelled", "info")
-		}
-
-	case PromptConfirmQuit:
-		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-			e.pendingQuit = true
-		} else {
-			e.statusbar.SetMessage("Cancelled", "info")
-		}
-
-	case PromptFileChanged:
-		if strings.ToLower(input) == "y" || strings.ToLower(input) == "yes" {
-			e.doSave() // Overwrite the external changes
-		} else {
-			e.statusbar.SetMessage("Save cancelled", "info")
-		}
+	case PromptAnnotate:
+		e.setNote(input)
 
 	case PromptGoToLine:
 		if input == "" {
 			e.statusbar.SetMessage("Cancelled", "info")
 			return
 		}
-		lineNum, err := strconv.Atoi(input)
-		if err != nil {
-			e.statusbar.SetMessage("Invalid line number", "error")
-			return
-		}
-		totalLines := e.activeDoc().buffer.LineCount()
-		if lineNum < 1 {
-			e.statusbar.SetMessage("Line number must be at least 1", "error")
-			return
-		}
-		if lineNum > totalLines {
-			e.statusbar.SetMessage(fmt.Sprintf("Line %d exceeds file length (%d lines)", lineNum, totalLines), "error")
-			return
-		}
-		// Convert to 0-indexed
-		e.activeDoc().cursor.SetPosition(lineNum-1, 0)
-		e.activeDoc().selection.Clear()
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-		e.statusbar.SetMessage(fmt.Sprintf("Jumped to line %d", lineNum), "info")
+		e.rememberGoto(input)
+		e.goToInput(input)
 
 	case PromptThemeCopyName:
 		if input == "" {
@@ -1920,6 +2854,12 @@ func (e *Editor) executePrompt() {
 			}
 		}
 		e.themeExportName = ""
+
+	case PromptViCommand:
+		e.executeViCommand(input)
+
+	case PromptEarlier, PromptLater:
+		e.undoTravel(input, e.promptAction == PromptEarlier)
 	}
 }
 
@@ -1929,21 +2869,29 @@ func (e *Editor) handleFindKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc:
 		e.mode = ModeNormal
 		e.findActive = false
+		e.findHistory.reset()
 		e.updateViewportSize()
 
 	case tea.KeyEnter:
-		e.findNext()
-
-	case tea.KeyBackspace:
-		if len(e.findQuery) > 0 {
-			e.findQuery = e.findQuery[:len(e.findQuery)-1]
+		e.rememberSearch(false)
+		if msg.Alt {
+			e.selectAllMatches()
+		} else {
+			e.findNext()
 		}
 
-	case tea.KeyRunes:
-		e.findQuery += string(msg.Runes)
+	case tea.KeyUp, tea.KeyDown:
+		recallHistory(&e.findHistory, &e.findQuery, msg.Type == tea.KeyUp)
+		e.findCursor = 0
 
-	case tea.KeySpace:
-		e.findQuery += " "
+	default:
+		if msg.Type == tea.KeyRunes && msg.Alt {
+			e.handleSearchOptionKey(string(msg.Runes))
+			break
+		}
+		if e.editInput(&e.findQuery, &e.findCursor, msg) {
+			e.findHistory.reset()
+		}
 	}
 
 	return e, nil
@@ -1998,37 +2946,25 @@ func (e *Editor) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 				e.updateViewportSize()
 			}
 
-			// Check if click is on minimap
-			if e.minimapRenderer.IsEnabled() && y >= 0 && y < e.viewport.Height() {
-				// Calculate minimap position (before scrollbar)
-				scrollbarWidth := 0
-				if e.scrollbar.IsEnabled() {
-					scrollbarWidth = e.scrollbar.Width()
-				}
-				minimapStartX := e.width - scrollbarWidth - ui.MinimapWidth()
-				minimapEndX := e.width - scrollbarWidth
-
-				if msg.X >= minimapStartX && msg.X < minimapEndX {
-					lines := e.activeDoc().buffer.Lines()
+			// Clicking the indentation in the status bar changes it
+			if msg.Y == e.height-1 && e.statusbar.SegmentAt(msg.X) == indentSegment {
+				e.showIndentDialog()
+				return e, nil
+			}
 
-					// Get minimap metrics and convert click to visual line
-					renderState := e.buildRenderState()
-					metrics := e.minimapRenderer.GetMetrics(e.viewport.Height(), renderState)
-					visualLine := e.minimapRenderer.RowToVisualLine(y, metrics)
+			// Clicking anywhere ends a multi-selection
+			e.clearMultiSelection()
 
-					// Convert visual line to buffer line
-					var targetLine int
-					if e.viewport.WordWrap() {
-						targetLine, _ = e.viewport.VisualLineToBufferLine(lines, visualLine)
-					} else {
-						targetLine = visualLine
-					}
+			// Clicks in the file tree select and open entries
+			if e.handleTreeMouse(msg, y) {
+				return e, nil
+			}
+			e.treeFocused = false
 
-					e.activeDoc().cursor.SetPosition(targetLine, 0)
-					e.activeDoc().selection.Clear()
-					e.viewport.EnsureCursorVisibleWrapped(lines, e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-					return e, nil
-				}
+			// Clicking the minimap scrolls to that part of the file
+			if line, ok := e.minimapLineAt(msg.X, y); ok {
+				e.clickMinimap(line)
+				return e, nil
 			}
 
 			// Check if click is on scrollbar
@@ -2061,17 +2997,26 @@ func (e *Editor) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			// Clicking a line number selects the line
+			if e.handleGutterPress(msg, y) {
+				return e, nil
+			}
+
 			// Handle click in editor area
 			if y >= 0 && y < e.viewport.Height() {
 				line, col := e.viewport.PositionFromClickWrapped(e.activeDoc().buffer.Lines(), msg.X, y)
 				e.activeDoc().cursor.SetPosition(line, col)
 				e.activeDoc().selection.Clear()
 				e.mouseDown = true
+				e.gutterDrag = false
 				e.mouseStartX = msg.X
 				e.mouseStartY = y
 			}
 		} else if msg.Action == tea.MouseActionRelease {
 			e.mouseDown = false
+			e.gutterDrag = false
+		} else if msg.Action == tea.MouseActionMotion && e.mouseDown && e.gutterDrag {
+			e.dragGutterSelection(y)
 		} else if msg.Action == tea.MouseActionMotion && e.mouseDown {
 			// Drag selection
 			if y >= 0 && y < e.viewport.Height() {
@@ -2087,15 +3032,69 @@ func (e *Editor) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.MouseButtonWheelUp:
-		e.viewport.ScrollUp()
-
-	case tea.MouseButtonWheelDown:
-		e.viewport.ScrollDownWrapped(e.activeDoc().buffer.Lines())
+		if e.handleTreeMouse(msg, y) {
+			return e, nil
+		}
+		if msg.Shift {
+			e.viewport.ScrollLeft(wheelScrollColumns)
+			e.hintCursorColumn()
+			break
+		}
+		for range e.scrollLines() {
+			e.viewport.ScrollUp()
+		}
+
+	case tea.MouseButtonWheelDown:
+		if e.handleTreeMouse(msg, y) {
+			return e, nil
+		}
+		if msg.Shift {
+			e.viewport.ScrollRight(e.activeDoc().buffer.Lines(), wheelScrollColumns)
+			e.hintCursorColumn()
+			break
+		}
+		for range e.scrollLines() {
+			e.viewport.ScrollDownWrapped(e.activeDoc().buffer.Lines())
+		}
+
+	case tea.MouseButtonNone:
+		if msg.Action == tea.MouseActionMotion {
+			e.hoverMinimap(msg.X, y)
+		}
+
+	case tea.MouseButtonMiddle:
+		if e.handleTreeMouse(msg, y) {
+			return e, nil
+		}
+		// Middle-click pastes the primary selection, as in X11
+		if _, onMinimap := e.minimapLineAt(msg.X, y); !onMinimap && msg.Action == tea.MouseActionPress &&
+			e.primaryEnabled() && y >= 0 && y < e.viewport.Height() {
+			e.pastePrimaryAt(msg.X, y)
+		}
+
+	case tea.MouseButtonWheelLeft:
+		e.viewport.ScrollLeft(wheelScrollColumns)
+		e.hintCursorColumn()
+
+	case tea.MouseButtonWheelRight:
+		e.viewport.ScrollRight(e.activeDoc().buffer.Lines(), wheelScrollColumns)
+		e.hintCursorColumn()
 	}
 
 	return e, nil
 }
 
+// wheelScrollColumns is how far a horizontal wheel tick scrolls
+const wheelScrollColumns = 4
+
+// scrollLines returns how many lines a mouse wheel tick scrolls
+func (e *Editor) scrollLines() int {
+	if e.config == nil || e.config.Editor.ScrollLines <= 0 {
+		return 3
+	}
+	return e.config.Editor.ScrollLines
+}
+
 // executeAction executes a menu action
 func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 	switch action {
@@ -2107,30 +3106,84 @@ func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 		e.showRecentFiles()
 	case ui.ActionRecentDirs:
 		e.showRecentDirs()
+	case ui.ActionQuickOpen:
+		return e, e.showQuickOpen()
 	case ui.ActionClose:
 		e.closeFile()
+	case ui.ActionReopenClosed:
+		e.reopenClosed()
 	case ui.ActionSave:
 		e.SaveFile()
 	case ui.ActionSaveAs:
 		e.showSaveAs()
 	case ui.ActionRevert:
 		e.revertFile()
+	case ui.ActionRestoreBackup:
+		e.showRestoreBackup()
+	case ui.ActionLocalHistory:
+		e.showLocalHistory()
 	case ui.ActionExit:
 		return e, e.quitEditor()
 	case ui.ActionUndo:
 		e.undo()
 	case ui.ActionRedo:
 		e.redo()
+	case ui.ActionUndoHistory:
+		e.showUndoHistory()
+	case ui.ActionEarlier:
+		e.showUndoTravel(true)
+	case ui.ActionLater:
+		e.showUndoTravel(false)
 	case ui.ActionCut:
 		e.cut()
 	case ui.ActionCopy:
 		e.copy()
+	case ui.ActionCopyAppend:
+		e.copyAppend()
 	case ui.ActionPaste:
-		e.paste()
+		return e, e.paste()
+	case ui.ActionPasteHistory:
+		e.showPasteHistory()
+	case ui.ActionCopyToRegister:
+		e.startRegisterOp(registerOpCopy)
+	case ui.ActionPasteRegister:
+		e.startRegisterOp(registerOpPaste)
+	case ui.ActionAnnotateLine:
+		e.annotateLine()
+	case ui.ActionAnnotations:
+		e.showAnnotations()
+	case ui.ActionInsertText:
+		e.showInsertText()
+	case ui.ActionInsertBuffer:
+		e.showInsertBuffer()
 	case ui.ActionCutLine:
 		e.cutLine()
 	case ui.ActionSelectAll:
 		e.selectAll()
+	case ui.ActionUppercase:
+		e.transformSelection(strings.ToUpper)
+	case ui.ActionLowercase:
+		e.transformSelection(strings.ToLower)
+	case ui.ActionTitleCase:
+		e.transformSelection(titleCase)
+	case ui.ActionToggleCase:
+		e.transformSelection(toggleCase)
+	case ui.ActionSortLines:
+		e.transformLines(sortLines)
+	case ui.ActionReverseLines:
+		e.transformLines(reverseLines)
+	case ui.ActionUniqueLines:
+		e.transformLines(uniqueLines)
+	case ui.ActionIndentToSpaces:
+		e.convertIndentation(false)
+	case ui.ActionIndentToTabs:
+		e.convertIndentation(true)
+	case ui.ActionIncrementNumber:
+		e.incrementNumber(1)
+	case ui.ActionDecrementNumber:
+		e.incrementNumber(-1)
+	case ui.ActionFixInvisibles:
+		e.fixInvisibles()
 	case ui.ActionFind:
 		e.mode = ModeFind
 		e.findQuery = ""
@@ -2141,23 +3194,53 @@ func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 	case ui.ActionReplace:
 		e.showFindReplace()
 	case ui.ActionGoToLine:
-		e.showPrompt("Go to line: ", PromptGoToLine)
+		e.showPrompt("Go to line[:col] or #offset: ", PromptGoToLine)
+	case ui.ActionCenterCursor:
+		e.centerCursor()
+	case ui.ActionGoToSymbol:
+		e.showGoToSymbol()
+	case ui.ActionGoToDefinition:
+		e.goToDefinition()
+	case ui.ActionCursorInfo:
+		e.showCursorInfo()
 	case ui.ActionWordWrap:
 		e.toggleWordWrap()
 	case ui.ActionLineNumbers:
 		e.toggleLineNumbers()
 	case ui.ActionSyntaxHighlight:
 		e.toggleSyntaxHighlight()
+	case ui.ActionAutoPair:
+		e.toggleAutoPair()
+	case ui.ActionHighlightWord:
+		e.toggleHighlightWord()
 	case ui.ActionScrollbar:
 		e.toggleScrollbar()
 	case ui.ActionMinimap:
 		e.toggleMinimap()
+	case ui.ActionFileTree:
+		e.toggleFileTree()
+	case ui.ActionHexView:
+		e.toggleHexView()
+	case ui.ActionFollowFile:
+		return e, e.toggleFollow()
+	case ui.ActionMinimapHeat:
+		e.cycleMinimapHeatmap()
 	case ui.ActionTheme:
 		e.showThemeDialog()
 	case ui.ActionKeybindings:
 		e.showKeybindingsDialog()
 	case ui.ActionSettings:
 		e.showSettingsDialog()
+	case ui.ActionIndentation:
+		e.showIndentDialog()
+	case ui.ActionBufferList:
+		e.showBufferList()
+	case ui.ActionSaveAll:
+		e.saveAll()
+	case ui.ActionCloseAll:
+		e.closeAll()
+	case ui.ActionCloseOthers:
+		e.closeOthers()
 	case ui.ActionBuffer1:
 		e.switchToBuffer(0)
 	case ui.ActionBuffer2:
@@ -2202,8 +3285,18 @@ func (e *Editor) executeAction(action ui.MenuAction) (tea.Model, tea.Cmd) {
 		e.showHelp()
 	case ui.ActionAbout:
 		e.showAbout()
+	case ui.ActionViewLog:
+		return e, e.viewDebugLog()
+	case ui.ActionStatistics:
+		e.showStatistics()
+	case ui.ActionPrint:
+		e.showPrintDialog()
 	case ui.ActionSetEncoding:
 		e.showEncodingDialog()
+	case ui.ActionToggleBOM:
+		e.toggleBOM()
+	case ui.ActionReopenEncoding:
+		e.showReopenEncodingDialog()
 	}
 	return e, nil
 }
@@ -2297,6 +3390,86 @@ func (e *Editor) toggleScrollbar() {
 	e.saveConfig()
 }
 
+// cycleMinimapHeatmap switches to the next minimap heatmap mode
+func (e *Editor) cycleMinimapHeatmap() {
+	modes := ui.HeatmapModes()
+	next := modes[0]
+	for i, mode := range modes {
+		if mode == e.config.Editor.MinimapHeatmap {
+			next = modes[(i+1)%len(modes)]
+		}
+	}
+	e.config.Editor.MinimapHeatmap = next
+	e.updateHeatmapLabel()
+
+	switch next {
+	case ui.HeatmapLength:
+		e.statusbar.SetMessage("Minimap heat: line length", "info")
+	case ui.HeatmapRecency:
+		e.statusbar.SetMessage("Minimap heat: recent edits", "info")
+	default:
+		e.statusbar.SetMessage("Minimap heat off", "info")
+	}
+	if next != ui.HeatmapOff && !e.minimapRenderer.IsEnabled() {
+		e.toggleMinimap()
+	}
+	e.saveConfig()
+}
+
+// updateHeatmapLabel shows the current heatmap mode in the Options menu
+func (e *Editor) updateHeatmapLabel() {
+	label := "Minimap Heat: Off"
+	switch e.config.Editor.MinimapHeatmap {
+	case ui.HeatmapLength:
+		label = "Minimap Heat: Length"
+	case ui.HeatmapRecency:
+		label = "Minimap Heat: Recency"
+	}
+	e.menubar.SetItemLabel(ui.ActionMinimapHeat, label)
+}
+
+// lineHeat computes the minimap heatmap for the active document, or nil when off
+func (e *Editor) lineHeat(lines []string) []float64 {
+	if !e.minimapRenderer.IsEnabled() {
+		return nil
+	}
+	switch e.config.Editor.MinimapHeatmap {
+	case ui.HeatmapLength:
+		// Lines reaching the first ruler (or 100 columns) are hottest
+		limit := 100
+		if rulers := e.fileSettings().Rulers; len(rulers) > 0 && rulers[0] > 0 {
+			limit = rulers[0]
+		}
+		heat := make([]float64, len(lines))
+		for i, line := range lines {
+			heat[i] = min(float64(utf8.RuneCountInString(line))/float64(limit), 1)
+		}
+		return heat
+
+	case ui.HeatmapRecency:
+		// Newer undo entries burn hotter; untouched lines stay untinted
+		heat := make([]float64, len(lines))
+		for i := range heat {
+			heat[i] = -1
+		}
+		doc := e.activeDoc()
+		recent := doc.undoStack.Recent(heatmapRecentEdits)
+		for i, entry := range recent {
+			pos := min(entry.Position, doc.buffer.Length())
+			line, _ := doc.buffer.PositionToLineCol(pos)
+			h := 1 - float64(i)/float64(len(recent))
+			if line < len(heat) && h > heat[line] {
+				heat[line] = h
+			}
+		}
+		return heat
+	}
+	return nil
+}
+
+// heatmapRecentEdits is how many undo entries the recency heatmap looks at
+const heatmapRecentEdits = 200
+
 // toggleMinimap toggles the minimap on/off
 func (e *Editor) toggleMinimap() {
 	enabled := e.minimapRenderer.Toggle()
@@ -2327,7 +3500,10 @@ func (e *Editor) saveConfig() {
 	if e.config == nil {
 		e.config = config.DefaultConfig()
 	}
-	e.config.Editor.WordWrap = e.viewport.WordWrap()
+	if e.fileSettings().WordWrap == nil {
+		// Don't persist a per-filetype wrap override as the global default
+		e.config.Editor.WordWrap = e.viewport.WordWrap()
+	}
 	e.config.Editor.LineNumbers = e.viewport.ShowLineNum()
 	e.config.Editor.SyntaxHighlight = e.activeDoc().highlighter.Enabled()
 	e.config.Editor.Scrollbar = e.scrollbar.IsEnabled()
@@ -2339,8 +3515,21 @@ func (e *Editor) saveConfig() {
 // applyTheme changes the current theme and updates all UI components
 func (e *Editor) applyTheme(themeName string) {
 	// Load the theme
-	theme := config.LoadTheme(themeName)
+	e.setTheme(config.LoadTheme(themeName))
+
+	// Update config and save
+	if e.config == nil {
+		e.config = config.DefaultConfig()
+	}
+	e.config.Theme.SetActive(themeName)
+	e.themeModTime = e.themeFileModTime()
+	go e.config.Save()
 
+	e.statusbar.SetMessage("Theme: "+themeName, "info")
+}
+
+// setTheme restyles every UI component with the given theme
+func (e *Editor) setTheme(theme config.Theme) {
 	// Create new styles from the theme
 	styles := ui.NewStyles(theme)
 
@@ -2364,15 +3553,47 @@ func (e *Editor) applyTheme(themeName string) {
 		Function: theme.Syntax.Function,
 		Type:     theme.Syntax.Type,
 	})
+}
 
-	// Update config and save
-	if e.config == nil {
-		e.config = config.DefaultConfig()
+// themeName returns the name of the active theme
+func (e *Editor) themeName() string {
+	if e.config != nil && e.config.Theme.ActiveName() != "" {
+		return e.config.Theme.ActiveName()
 	}
-	e.config.Theme.Name = themeName
-	go e.config.Save()
+	return "default"
+}
 
-	e.statusbar.SetMessage("Theme: "+themeName, "info")
+// themeFileModTime returns the modification time of the active theme's user
+// file, or the zero time for built-in themes
+func (e *Editor) themeFileModTime() time.Time {
+	path := config.ThemeFilePath(e.themeName())
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// checkThemeFile re-applies the active theme when its file has changed,
+// so colors can be tuned live while editing an exported theme
+func (e *Editor) checkThemeFile() {
+	modTime := e.themeFileModTime()
+	if modTime.IsZero() || modTime.Equal(e.themeModTime) {
+		return
+	}
+	e.themeModTime = modTime
+
+	name := e.themeName()
+	theme, err := config.LoadUserTheme(name)
+	if err != nil {
+		e.statusbar.SetMessage("Theme "+name+": "+err.Error(), "error")
+		return
+	}
+	e.setTheme(theme)
+	e.statusbar.SetMessage("Theme reloaded: "+name, "info")
 }
 
 // showThemeDialog opens the theme selection dialog
@@ -2395,10 +3616,7 @@ func (e *Editor) showThemeDialog() {
 	}
 
 	// Find current theme index
-	currentTheme := "default"
-	if e.config != nil && e.config.Theme.Name != "" {
-		currentTheme = e.config.Theme.Name
-	}
+	currentTheme := e.themeName()
 	e.themeIndex = 0
 	for i, name := range e.themeList {
 		if name == currentTheme {
@@ -2466,64 +3684,13 @@ func (e *Editor) handleThemeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleThemeMouse handles mouse input in the theme selection dialog
 func (e *Editor) handleThemeMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Calculate dialog position (must match overlayThemeDialog)
-	boxWidth := 40
-	themeCount := len(e.themeList)
-	// Dialog structure: title, empty, themes..., empty, footer, bottom border
-	boxHeight := themeCount + 5
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
-
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Check if click is outside dialog - close it
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
-		}
-		return e, nil
-	}
-
-	// Theme list starts at line 2 (after title border and empty line)
-	themeListStart := 2
-	themeListEnd := themeListStart + themeCount
-
-	switch msg.Button {
-	case tea.MouseButtonLeft:
-		if msg.Action == tea.MouseActionPress {
-			// Check if click is in theme list area
-			if relY >= themeListStart && relY < themeListEnd {
-				clickedIdx := relY - themeListStart
-				if clickedIdx >= 0 && clickedIdx < themeCount {
-					if e.themeIndex == clickedIdx {
-						// Double-click effect: same item clicked again - apply it
-						e.applyTheme(e.themeList[e.themeIndex])
-						e.mode = ModeNormal
-					} else {
-						// First click - just select
-						e.themeIndex = clickedIdx
-					}
-				}
-			}
-		}
-
-	case tea.MouseButtonWheelUp:
-		if e.themeIndex > 0 {
-			e.themeIndex--
-		}
-
-	case tea.MouseButtonWheelDown:
-		if e.themeIndex < themeCount-1 {
-			e.themeIndex++
-		}
+	switch e.themeDialog().listMouse(msg, &e.themeIndex) {
+	case listChoose:
+		e.applyTheme(e.themeList[e.themeIndex])
+		e.mode = ModeNormal
+	case listClose:
+		e.mode = ModeNormal
 	}
-
 	return e, nil
 }
 
@@ -2586,16 +3753,7 @@ func (e *Editor) handleRecentFilesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			e.recentFilesIndex++
 		}
 	case tea.KeyEnter:
-		// Open selected file
-		if e.recentFilesIndex >= 0 && e.recentFilesIndex < recentCount {
-			path := e.config.RecentFiles[e.recentFilesIndex]
-			if err := e.LoadFile(path); err != nil {
-				e.statusbar.SetMessage("Open failed: "+err.Error(), "error")
-			} else {
-				e.statusbar.SetMessage("Opened: "+path, "success")
-			}
-		}
-		e.mode = ModeNormal
+		e.openRecentFile(e.recentFilesIndex)
 	case tea.KeyEsc:
 		e.mode = ModeNormal
 	case tea.KeyDelete, tea.KeyBackspace:
@@ -2622,74 +3780,30 @@ func (e *Editor) handleRecentFilesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleRecentFilesMouse handles mouse input in the recent files dialog
 func (e *Editor) handleRecentFilesMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	recentCount := 0
-	if e.config != nil {
-		recentCount = len(e.config.RecentFiles)
-	}
-	if recentCount == 0 {
+	if e.config == nil || len(e.config.RecentFiles) == 0 {
 		return e, nil
 	}
-
-	// Calculate dialog position (must match overlayRecentFilesDialog)
-	boxWidth := 60
-	boxHeight := recentCount + 5 // title, empty, items..., empty, footer, bottom
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
-
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Check if click is outside dialog - close it
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
-		}
-		return e, nil
+	switch e.recentFilesDialog().listMouse(msg, &e.recentFilesIndex) {
+	case listChoose:
+		e.openRecentFile(e.recentFilesIndex)
+	case listClose:
+		e.mode = ModeNormal
 	}
+	return e, nil
+}
 
-	// File list starts at line 2 (after title border and empty line)
-	listStart := 2
-	listEnd := listStart + recentCount
-
-	switch msg.Button {
-	case tea.MouseButtonLeft:
-		if msg.Action == tea.MouseActionPress {
-			if relY >= listStart && relY < listEnd {
-				clickedIdx := relY - listStart
-				if clickedIdx >= 0 && clickedIdx < recentCount {
-					if e.recentFilesIndex == clickedIdx {
-						// Double-click effect: open file
-						path := e.config.RecentFiles[e.recentFilesIndex]
-						if err := e.LoadFile(path); err != nil {
-							e.statusbar.SetMessage("Open failed: "+err.Error(), "error")
-						} else {
-							e.statusbar.SetMessage("Opened: "+path, "success")
-						}
-						e.mode = ModeNormal
-					} else {
-						e.recentFilesIndex = clickedIdx
-					}
-				}
-			}
-		}
-
-	case tea.MouseButtonWheelUp:
-		if e.recentFilesIndex > 0 {
-			e.recentFilesIndex--
-		}
-
-	case tea.MouseButtonWheelDown:
-		if e.recentFilesIndex < recentCount-1 {
-			e.recentFilesIndex++
-		}
+// openRecentFile closes the recent files dialog and opens entry i
+func (e *Editor) openRecentFile(i int) {
+	e.mode = ModeNormal
+	if i < 0 || i >= len(e.config.RecentFiles) {
+		return
+	}
+	path := e.config.RecentFiles[i]
+	if err := e.LoadFile(path); err != nil {
+		e.statusbar.SetMessage("Open failed: "+err.Error(), "error")
+	} else {
+		e.reportOpened(path)
 	}
-
-	return e, nil
 }
 
 // showRecentDirs opens the recent directories dialog
@@ -2742,17 +3856,7 @@ func (e *Editor) handleRecentDirsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			e.recentDirsIndex++
 		}
 	case tea.KeyEnter:
-		// Navigate to selected directory in file browser
-		if e.recentDirsIndex >= 0 && e.recentDirsIndex < recentCount {
-			path := e.config.RecentDirs[e.recentDirsIndex]
-			e.fileBrowserDir = path
-			e.fileBrowserSelected = 0
-			e.fileBrowserScroll = 0
-			e.fileBrowserError = ""
-			e.loadDirectory(path)
-			e.mode = ModeFileBrowser
-			e.statusbar.SetMessage("Browsing: "+path, "info")
-		}
+		e.browseRecentDir(e.recentDirsIndex)
 	case tea.KeyEsc:
 		e.mode = ModeNormal
 	case tea.KeyDelete, tea.KeyBackspace:
@@ -2779,75 +3883,31 @@ func (e *Editor) handleRecentDirsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleRecentDirsMouse handles mouse input in the recent directories dialog
 func (e *Editor) handleRecentDirsMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	recentCount := 0
-	if e.config != nil {
-		recentCount = len(e.config.RecentDirs)
-	}
-	if recentCount == 0 {
+	if e.config == nil || len(e.config.RecentDirs) == 0 {
 		return e, nil
 	}
-
-	// Calculate dialog position (must match overlayRecentDirsDialog)
-	boxWidth := 60
-	boxHeight := recentCount + 5 // title, empty, items..., empty, footer, bottom
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
-
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Check if click is outside dialog - close it
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
-		}
-		return e, nil
+	switch e.recentDirsDialog().listMouse(msg, &e.recentDirsIndex) {
+	case listChoose:
+		e.browseRecentDir(e.recentDirsIndex)
+	case listClose:
+		e.mode = ModeNormal
 	}
+	return e, nil
+}
 
-	// Directory list starts at line 2 (after title border and empty line)
-	listStart := 2
-	listEnd := listStart + recentCount
-
-	switch msg.Button {
-	case tea.MouseButtonLeft:
-		if msg.Action == tea.MouseActionPress {
-			if relY >= listStart && relY < listEnd {
-				clickedIdx := relY - listStart
-				if clickedIdx >= 0 && clickedIdx < recentCount {
-					if e.recentDirsIndex == clickedIdx {
-						// Double-click effect: open directory in browser
-						path := e.config.RecentDirs[e.recentDirsIndex]
-						e.fileBrowserDir = path
-						e.fileBrowserSelected = 0
-						e.fileBrowserScroll = 0
-						e.fileBrowserError = ""
-						e.loadDirectory(path)
-						e.mode = ModeFileBrowser
-						e.statusbar.SetMessage("Browsing: "+path, "info")
-					} else {
-						e.recentDirsIndex = clickedIdx
-					}
-				}
-			}
-		}
-
-	case tea.MouseButtonWheelUp:
-		if e.recentDirsIndex > 0 {
-			e.recentDirsIndex--
-		}
-
-	case tea.MouseButtonWheelDown:
-		if e.recentDirsIndex < recentCount-1 {
-			e.recentDirsIndex++
-		}
+// browseRecentDir opens entry i of the recent directories in the file browser
+func (e *Editor) browseRecentDir(i int) {
+	if i < 0 || i >= len(e.config.RecentDirs) {
+		return
 	}
-
-	return e, nil
+	path := e.config.RecentDirs[i]
+	e.fileBrowserDir = path
+	e.fileBrowserSelected = 0
+	e.fileBrowserScroll = 0
+	e.fileBrowserError = ""
+	e.loadDirectory(path)
+	e.mode = ModeFileBrowser
+	e.statusbar.SetMessage("Browsing: "+path, "info")
 }
 
 // handleConfigErrorKey handles key events in the config error dialog
@@ -2898,56 +3958,26 @@ func (e *Editor) executeConfigErrorChoice() (tea.Model, tea.Cmd) {
 		e.mode = ModeNormal
 		e.statusbar.SetMessage("Using default settings", "info")
 	case 2: // Quit
-		return e, tea.Quit
+		return e, e.quit()
 	}
 	return e, nil
 }
 
 // handleConfigErrorMouse handles mouse input in the config error dialog
 func (e *Editor) handleConfigErrorMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Dialog dimensions (must match overlayConfigErrorDialog)
-	boxWidth := 56
-	boxHeight := 9
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
-
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Check if click is outside dialog - treat as "Use Defaults"
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.configErrorChoice = 1
-			return e.executeConfigErrorChoice()
-		}
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
 		return e, nil
 	}
-
-	// Button row is at line 7 (0-indexed)
-	buttonRowY := 7
-	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-		if relY == buttonRowY {
-			// Buttons: [ Edit File ]  [ Use Defaults ]  [ Quit ]
-			// Approximate positions within inner width (54)
-			innerX := relX - 1 // Account for border
-			if innerX >= 2 && innerX < 15 {
-				e.configErrorChoice = 0
-				return e.executeConfigErrorChoice()
-			} else if innerX >= 17 && innerX < 34 {
-				e.configErrorChoice = 1
-				return e.executeConfigErrorChoice()
-			} else if innerX >= 36 && innerX < 46 {
-				e.configErrorChoice = 2
-				return e.executeConfigErrorChoice()
-			}
-		}
+	hit := e.configErrorDialog().HitTest(msg.X, msg.Y-1)
+	switch {
+	case !hit.inside:
+		// Click outside is treated as "Use Defaults"
+		e.configErrorChoice = 1
+		return e.executeConfigErrorChoice()
+	case hit.kind == widgetButton && hit.index >= 0:
+		e.configErrorChoice = hit.index
+		return e.executeConfigErrorChoice()
 	}
-
 	return e, nil
 }
 
@@ -2966,103 +3996,120 @@ func (e *Editor) showSettingsDialog() {
 			e.settingsTabWidth = 4
 		}
 		e.settingsTabsToSpaces = e.config.Editor.TabsToSpaces
+		e.settingsReminder = e.config.Editor.UnsavedReminder
+		e.settingsKeyProfile = e.config.Editor.KeybindingProfile
+		if e.settingsKeyProfile == "" {
+			e.settingsKeyProfile = config.ProfileDefault
+		}
 	}
 	e.settingsIndex = 0
 	e.mode = ModeSettings
 }
 
+// Rows of the settings dialog, in the order its widgets are added
+const (
+	settingsRowWordWrap = iota
+	settingsRowLineNumbers
+	settingsRowSyntax
+	settingsRowScrollbar
+	settingsRowTabsToSpaces
+	settingsRowBackupCount
+	settingsRowMaxBuffers
+	settingsRowTabWidth
+	settingsRowReminder
+	settingsRowKeyProfile
+	settingsRowSave
+	settingsRowCancel
+	settingsRowCount
+)
+
 // handleSettingsKey handles key events in the settings dialog
 func (e *Editor) handleSettingsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Settings rows: 0-4 = checkboxes, 5-7 = numbers, 8 = Save, 9 = Cancel
-	const (
-		rowWordWrap     = 0
-		rowLineNumbers  = 1
-		rowSyntax       = 2
-		rowScrollbar    = 3
-		rowTabsToSpaces = 4
-		rowBackupCount  = 5
-		rowMaxBuffers   = 6
-		rowTabWidth     = 7
-		rowSave         = 8
-		rowCancel       = 9
-		maxRow          = 9
-	)
-
+	if moveFocus(&e.settingsIndex, settingsRowCount, msg) {
+		return e, nil
+	}
 	switch msg.Type {
-	case tea.KeyUp:
-		if e.settingsIndex > 0 {
-			e.settingsIndex--
-		}
-	case tea.KeyDown:
-		if e.settingsIndex < maxRow {
-			e.settingsIndex++
-		}
 	case tea.KeyLeft:
 		// Decrease number inputs or navigate to Save button
-		switch e.settingsIndex {
-		case rowBackupCount:
-			if e.settingsBackupCount > 0 {
-				e.settingsBackupCount--
-			}
-		case rowMaxBuffers:
-			if e.settingsMaxBuffers > 0 {
-				e.settingsMaxBuffers--
-			}
-		case rowTabWidth:
-			if e.settingsTabWidth > 1 {
-				e.settingsTabWidth--
-			}
-		case rowCancel:
-			e.settingsIndex = rowSave
+		if e.settingsIndex == settingsRowCancel {
+			e.settingsIndex = settingsRowSave
+		} else {
+			e.stepSetting(e.settingsIndex, -1)
 		}
 	case tea.KeyRight:
 		// Increase number inputs or navigate to Cancel button
-		switch e.settingsIndex {
-		case rowBackupCount:
-			if e.settingsBackupCount < 99 {
-				e.settingsBackupCount++
-			}
-		case rowMaxBuffers:
-			if e.settingsMaxBuffers < 99 {
-				e.settingsMaxBuffers++
-			}
-		case rowTabWidth:
-			if e.settingsTabWidth < 16 {
-				e.settingsTabWidth++
-			}
-		case rowSave:
-			e.settingsIndex = rowCancel
+		if e.settingsIndex == settingsRowSave {
+			e.settingsIndex = settingsRowCancel
+		} else {
+			e.stepSetting(e.settingsIndex, 1)
 		}
 	case tea.KeyEnter, tea.KeySpace:
-		switch e.settingsIndex {
-		case rowWordWrap:
-			e.settingsWordWrap = !e.settingsWordWrap
-		case rowLineNumbers:
-			e.settingsLineNumbers = !e.settingsLineNumbers
-		case rowSyntax:
-			e.settingsSyntax = !e.settingsSyntax
-		case rowScrollbar:
-			e.settingsScrollbar = !e.settingsScrollbar
-		case rowTabsToSpaces:
-			e.settingsTabsToSpaces = !e.settingsTabsToSpaces
-		case rowSave:
-			e.saveSettings()
-			e.mode = ModeNormal
-			// Warn if buffer limit is now lower than current count
-			if e.settingsMaxBuffers > 0 && len(e.documents) > e.settingsMaxBuffers {
-				e.statusbar.SetMessage(fmt.Sprintf("Settings saved (close %d buffers to open new files)", len(e.documents)-e.settingsMaxBuffers), "warning")
-			} else {
-				e.statusbar.SetMessage("Settings saved", "success")
-			}
-		case rowCancel:
-			e.mode = ModeNormal
-		}
+		e.activateSetting(e.settingsIndex)
 	case tea.KeyEsc:
 		e.mode = ModeNormal
 	}
 	return e, nil
 }
 
+// stepSetting changes a number or choice row of the settings dialog by delta
+func (e *Editor) stepSetting(row, delta int) {
+	switch row {
+	case settingsRowBackupCount:
+		e.settingsBackupCount = max(0, min(e.settingsBackupCount+delta, 99))
+	case settingsRowMaxBuffers:
+		e.settingsMaxBuffers = max(0, min(e.settingsMaxBuffers+delta, 99))
+	case settingsRowTabWidth:
+		e.settingsTabWidth = max(1, min(e.settingsTabWidth+delta, 16))
+	case settingsRowReminder:
+		e.settingsReminder = max(0, min(e.settingsReminder+delta, 99))
+	case settingsRowKeyProfile:
+		e.cycleSettingsKeyProfile(delta)
+	}
+}
+
+// activateSetting toggles a checkbox, steps the profile or presses a button
+func (e *Editor) activateSetting(row int) {
+	switch row {
+	case settingsRowWordWrap:
+		e.settingsWordWrap = !e.settingsWordWrap
+	case settingsRowLineNumbers:
+		e.settingsLineNumbers = !e.settingsLineNumbers
+	case settingsRowSyntax:
+		e.settingsSyntax = !e.settingsSyntax
+	case settingsRowScrollbar:
+		e.settingsScrollbar = !e.settingsScrollbar
+	case settingsRowTabsToSpaces:
+		e.settingsTabsToSpaces = !e.settingsTabsToSpaces
+	case settingsRowKeyProfile:
+		e.cycleSettingsKeyProfile(1)
+	case settingsRowSave:
+		e.saveSettings()
+		e.mode = ModeNormal
+		// Warn if buffer limit is now lower than current count
+		if e.settingsMaxBuffers > 0 && len(e.documents) > e.settingsMaxBuffers {
+			e.statusbar.SetMessage(fmt.Sprintf("Settings saved (close %d buffers to open new files)", len(e.documents)-e.settingsMaxBuffers), "warning")
+		} else {
+			e.statusbar.SetMessage("Settings saved", "success")
+		}
+	case settingsRowCancel:
+		e.mode = ModeNormal
+	}
+}
+
+// cycleSettingsKeyProfile moves the keybinding profile selection by delta
+func (e *Editor) cycleSettingsKeyProfile(delta int) {
+	profiles := config.KeybindingProfiles()
+	idx := 0
+	for i, p := range profiles {
+		if p == e.settingsKeyProfile {
+			idx = i
+			break
+		}
+	}
+	idx = (idx + delta + len(profiles)) % len(profiles)
+	e.settingsKeyProfile = profiles[idx]
+}
+
 // saveSettings applies and saves the settings to config
 func (e *Editor) saveSettings() {
 	if e.config == nil {
@@ -3078,6 +4125,12 @@ func (e *Editor) saveSettings() {
 	e.config.Editor.MaxBuffers = e.settingsMaxBuffers
 	e.config.Editor.TabWidth = e.settingsTabWidth
 	e.config.Editor.TabsToSpaces = e.settingsTabsToSpaces
+	e.config.Editor.UnsavedReminder = e.settingsReminder
+	if e.config.Editor.KeybindingProfile != e.settingsKeyProfile {
+		e.config.Editor.KeybindingProfile = e.settingsKeyProfile
+		e.vi = viState{} // Start fresh in normal mode
+		e.emacs = emacsState{}
+	}
 
 	// Apply to current editor state
 	e.viewport.SetWordWrap(e.settingsWordWrap)
@@ -3117,79 +4170,25 @@ func (e *Editor) saveSettings() {
 
 // handleSettingsMouse handles mouse input in the settings dialog
 func (e *Editor) handleSettingsMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Dialog dimensions (must match overlaySettingsDialog)
-	// title + empty + 5 checkboxes + empty + 3 numbers with help + empty + buttons + bottom
-	boxWidth := 54
-	boxHeight := 18
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	mouseY := msg.Y - 1 // Adjust for menu bar
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Click outside = cancel
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
-		}
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
 		return e, nil
 	}
-
-	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-		// Row mapping (0-indexed from content start at line 2):
-		// 0-4: checkboxes (rows 0-4)
-		// 5: empty line
-		// 6: Backup Count (row 5)
-		// 7: help text
-		// 8: Max Buffers (row 6)
-		// 9: help text
-		// 10: Tab Width (row 7)
-		// 11: help text
-		// 12: empty line
-		// 13: buttons (rows 8, 9)
-
-		contentRow := relY - 2
-		if contentRow >= 0 && contentRow <= 4 {
-			// Checkbox rows
-			e.settingsIndex = contentRow
-			switch contentRow {
-			case 0:
-				e.settingsWordWrap = !e.settingsWordWrap
-			case 1:
-				e.settingsLineNumbers = !e.settingsLineNumbers
-			case 2:
-				e.settingsSyntax = !e.settingsSyntax
-			case 3:
-				e.settingsScrollbar = !e.settingsScrollbar
-			case 4:
-				e.settingsTabsToSpaces = !e.settingsTabsToSpaces
-			}
-		} else if contentRow == 6 {
-			e.settingsIndex = 5 // Backup Count
-		} else if contentRow == 8 {
-			e.settingsIndex = 6 // Max Buffers
-		} else if contentRow == 10 {
-			e.settingsIndex = 7 // Tab Width
-		} else if contentRow == 14 {
-			// Button row
-			innerX := relX - 1
-			if innerX >= 12 && innerX < 22 {
-				e.saveSettings()
-				e.mode = ModeNormal
-				// Warn if buffer limit is now lower than current count
-				if e.settingsMaxBuffers > 0 && len(e.documents) > e.settingsMaxBuffers {
-					e.statusbar.SetMessage(fmt.Sprintf("Settings saved (close %d buffers to open new files)", len(e.documents)-e.settingsMaxBuffers), "warning")
-				} else {
-					e.statusbar.SetMessage("Settings saved", "success")
-				}
-			} else if innerX >= 28 && innerX < 40 {
-				e.mode = ModeNormal
-			}
-		}
+	hit := e.settingsDialog().HitTest(msg.X, msg.Y-1)
+	if !hit.inside {
+		// Click outside = cancel
+		e.mode = ModeNormal
+		return e, nil
+	}
+	if hit.id < 0 {
+		return e, nil
+	}
+	e.settingsIndex = hit.id
+	switch hit.kind {
+	case widgetSpinner:
+		e.stepSetting(hit.id, hit.delta)
+	case widgetCheckbox, widgetChoice, widgetButton:
+		e.activateSetting(hit.id)
 	}
-
 	return e, nil
 }
 
@@ -3210,6 +4209,7 @@ func (e *Editor) showEncodingDialog() {
 		}
 	}
 
+	e.encodingReopen = false
 	e.mode = ModeEncoding
 }
 
@@ -3234,10 +4234,7 @@ func (e *Editor) handleEncodingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc:
 		e.mode = ModeNormal
 	case tea.KeyEnter:
-		// Apply the selected encoding
-		selectedEnc := encodings[e.encodingIndex]
-		e.applyEncoding(selectedEnc)
-		e.mode = ModeNormal
+		e.chooseEncoding(encodings[e.encodingIndex])
 	}
 
 	return e, nil
@@ -3246,44 +4243,17 @@ func (e *Editor) handleEncodingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // handleEncodingMouse handles mouse input in the encoding selection dialog
 func (e *Editor) handleEncodingMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	encodings := enc.GetSupportedEncodings()
-	encodingCount := len(encodings)
-
-	// Dialog dimensions (must match overlayEncodingDialog)
-	boxWidth := 50
-	// title + empty + encodings + empty + help + footer + bottom border
-	boxHeight := encodingCount + 6
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	mouseY := msg.Y - 1 // Adjust for menu bar
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Click outside = cancel
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
+	db := e.encodingDialog()
+	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionRelease {
+		// Releasing on the pressed item selects it
+		if hit := db.HitTest(msg.X, msg.Y-1); hit.kind == widgetItem && hit.index == e.encodingIndex {
+			e.chooseEncoding(encodings[e.encodingIndex])
 		}
 		return e, nil
 	}
-
-	// Encoding items start at row 2 (after title and empty line)
-	itemRow := relY - 2
-	if itemRow >= 0 && itemRow < encodingCount {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.encodingIndex = itemRow
-		}
-		// Double-click to select
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionRelease {
-			if e.encodingIndex == itemRow {
-				selectedEnc := encodings[e.encodingIndex]
-				e.applyEncoding(selectedEnc)
-				e.mode = ModeNormal
-			}
-		}
+	if db.listMouse(msg, &e.encodingIndex) == listClose {
+		e.mode = ModeNormal
 	}
-
 	return e, nil
 }
 
@@ -3304,6 +4274,32 @@ func (e *Editor) applyEncoding(newEnc *enc.Encoding) {
 	// Just change the encoding - content stays the same
 	doc.encoding = newEnc
 	e.statusbar.SetMessage("Will save as "+newEnc.Name, "info")
+	e.updateMenuState()
+}
+
+// docEncoding returns the active buffer's encoding, UTF-8 when unset
+func (e *Editor) docEncoding() *enc.Encoding {
+	if docEnc := e.activeDoc().encoding; docEnc != nil {
+		return docEnc
+	}
+	return enc.GetEncodingByID("utf-8")
+}
+
+// toggleBOM switches the active buffer between saving with and without a
+// byte order mark
+func (e *Editor) toggleBOM() {
+	variant := enc.BOMVariant(e.docEncoding())
+	if variant == nil {
+		e.statusbar.SetMessage(e.docEncoding().Name+" has no byte order mark", "info")
+		return
+	}
+	e.activeDoc().encoding = variant
+	if variant.WriteBOM {
+		e.statusbar.SetMessage("Will save with a BOM ("+variant.Name+")", "info")
+	} else {
+		e.statusbar.SetMessage("Will save without a BOM ("+variant.Name+")", "info")
+	}
+	e.updateMenuState()
 }
 
 // showKeybindingsDialog opens the keybindings configuration dialog
@@ -3549,69 +4545,68 @@ func (e *Editor) handleKeybindingsMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 	return e, nil
 }
 
-// handleHelpMouse handles mouse input in help mode
-func (e *Editor) handleHelpMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// Help dialog dimensions (must match overlayHelpDialog)
-	boxWidth := 72
-	// Height: top border + empty + 21 rows + empty + 2 options + empty + footer + bottom
-	boxHeight := 29
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
+// scrollableDialog builds the open read-only dialog (Help, About or
+// Statistics), or returns nil when none is open
+func (e *Editor) scrollableDialog() *DialogBuilder {
+	switch e.mode {
+	case ModeHelp:
+		return e.helpDialog()
+	case ModeAbout:
+		return e.aboutDialog()
+	case ModeStatistics:
+		return e.statisticsDialog()
+	}
+	return nil
+}
 
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
+// scrollDialog scrolls the read-only dialog db by delta lines
+func (e *Editor) scrollDialog(db *DialogBuilder, delta int) {
+	e.dialogScroll = max(0, min(e.dialogScroll+delta, db.MaxScroll()))
+}
 
-	// Check if click is outside dialog - close it
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-			e.mode = ModeNormal
+// handleScrollableDialogKey scrolls a read-only dialog that doesn't fit
+// the viewport with the arrow and page keys; any other key closes it
+func (e *Editor) handleScrollableDialogKey(db *DialogBuilder, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if db.MaxScroll() > 0 {
+		page := max(e.viewport.Height()-3, 1)
+		switch msg.Type {
+		case tea.KeyUp:
+			e.scrollDialog(db, -1)
+			return e, nil
+		case tea.KeyDown:
+			e.scrollDialog(db, 1)
+			return e, nil
+		case tea.KeyPgUp:
+			e.scrollDialog(db, -page)
+			return e, nil
+		case tea.KeyPgDown:
+			e.scrollDialog(db, page)
+			return e, nil
+		case tea.KeyHome:
+			e.dialogScroll = 0
+			return e, nil
+		case tea.KeyEnd:
+			e.dialogScroll = db.MaxScroll()
+			return e, nil
 		}
-		return e, nil
-	}
-
-	// Any click inside the dialog also closes it
-	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-		e.mode = ModeNormal
 	}
-
+	e.mode = ModeNormal
 	return e, nil
 }
 
-// handleAboutMouse handles mouse input in about mode
-func (e *Editor) handleAboutMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
-	// About dialog dimensions (must match overlayAboutDialog)
-	boxWidth := 66
-	// Height: top border + empty + 6 logo + 7 content + 2 quote + 2 footer + bottom
-	boxHeight := 20
-
-	startX := (e.width - boxWidth) / 2
-	startY := (e.viewport.Height() - boxHeight) / 2
-
-	// Adjust mouse Y for menu bar
-	mouseY := msg.Y - 1
-
-	// Calculate relative position within dialog
-	relX := msg.X - startX
-	relY := mouseY - startY
-
-	// Check if click is outside dialog - close it
-	if relX < 0 || relX >= boxWidth || relY < 0 || relY >= boxHeight {
-		if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
+// handleScrollableDialogMouse scrolls a read-only dialog with the wheel;
+// any click, inside the dialog or out, closes it
+func (e *Editor) handleScrollableDialogMouse(db *DialogBuilder, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		e.scrollDialog(db, -1)
+	case tea.MouseButtonWheelDown:
+		e.scrollDialog(db, 1)
+	case tea.MouseButtonLeft:
+		if msg.Action == tea.MouseActionPress {
 			e.mode = ModeNormal
 		}
-		return e, nil
-	}
-
-	// Any click inside the dialog also closes it
-	if msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress {
-		e.mode = ModeNormal
 	}
-
 	return e, nil
 }
 
@@ -3789,8 +4784,7 @@ func (e *Editor) checkKeyConflict(keyStr, currentAction string) []string {
 			continue
 		}
 		binding := e.keybindings.GetBinding(action)
-		if strings.ToLower(binding.Primary) == keyLower ||
-			strings.ToLower(binding.Alternate) == keyLower {
+		if binding.Matches(keyLower) || binding.HasChordPrefix(keyLower) {
 			conflicts = append(conflicts, action)
 		}
 	}
@@ -3799,11 +4793,13 @@ func (e *Editor) checkKeyConflict(keyStr, currentAction string) []string {
 
 // showHelp opens the Help dialog with keyboard shortcuts
 func (e *Editor) showHelp() {
+	e.dialogScroll = 0
 	e.mode = ModeHelp
 }
 
 // showAbout opens the About dialog with a random quote
 func (e *Editor) showAbout() {
+	e.dialogScroll = 0
 	e.mode = ModeAbout
 	e.aboutQuote = FestivusQuotes[rand.Intn(len(FestivusQuotes))]
 }
@@ -3811,6 +4807,9 @@ func (e *Editor) showAbout() {
 // Text manipulation methods
 
 func (e *Editor) insertChar(r rune) {
+	if e.refuseEdit() {
+		return
+	}
 	// Delete selection first if any
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
@@ -3833,6 +4832,9 @@ func (e *Editor) insertChar(r rune) {
 }
 
 func (e *Editor) insertText(s string) {
+	if e.refuseEdit() {
+		return
+	}
 	if s == "" {
 		return
 	}
@@ -3848,106 +4850,39 @@ func (e *Editor) insertText(s string) {
 		CursorBefore: e.activeDoc().cursor.ByteOffset(),
 	}
 
-	e.activeDoc().cursor.Sync()
-	e.activeDoc().buffer.Insert(s)
-	e.activeDoc().cursor.SetByteOffset(e.activeDoc().cursor.ByteOffset() + len(s))
-
-	entry.CursorAfter = e.activeDoc().cursor.ByteOffset()
-	e.activeDoc().undoStack.Push(entry)
-	e.activeDoc().modified = true
-}
-
-// getIndentString returns the string to use for one level of indentation
-func (e *Editor) getIndentString() string {
-	if e.config.Editor.TabsToSpaces {
-		width := e.config.Editor.TabWidth
-		if width <= 0 {
-			width = 4
-		}
-		return strings.Repeat(" ", width)
-	}
-	return "\t"
-}
-
-// indentLines indents all lines in the current selection
-func (e *Editor) indentLines() {
-	doc := e.activeDoc()
-	sel := doc.selection
-
-	// If no selection, just insert tab at cursor
-	if !sel.Active || sel.IsEmpty() {
-		e.insertText(e.getIndentString())
-		return
-	}
-
-	// Get the line range of the selection
-	startPos, endPos := sel.Normalize()
-	startLine, _ := doc.buffer.PositionToLineCol(startPos)
-	endLine, endCol := doc.buffer.PositionToLineCol(endPos)
-
-	// If selection ends at column 0, don't include that line
-	if endCol == 0 && endLine > startLine {
-		endLine--
-	}
-
-	indent := e.getIndentString()
-
-	// Build the undo entry for all changes
-	var deleted strings.Builder
-	var inserted strings.Builder
-
-	// Calculate the range we're modifying
-	rangeStart := doc.buffer.LineStartOffset(startLine)
-	rangeEnd := doc.buffer.LineEndOffset(endLine)
-	if rangeEnd < doc.buffer.Length() {
-		// Include the newline if present
-		if r, size := doc.buffer.RuneAt(rangeEnd); r == '\n' {
-			rangeEnd += size
-		}
-	}
-
-	// Get original text
-	originalText := doc.buffer.Substring(rangeStart, rangeEnd)
-	deleted.WriteString(originalText)
-
-	// Build new text with indentation
-	lines := strings.Split(originalText, "\n")
-	for i, line := range lines {
-		if i < len(lines)-1 || line != "" { // Don't indent empty trailing line
-			inserted.WriteString(indent)
-		}
-		inserted.WriteString(line)
-		if i < len(lines)-1 {
-			inserted.WriteString("\n")
-		}
-	}
-
-	// Create undo entry
-	entry := &UndoEntry{
-		Position:     rangeStart,
-		Deleted:      deleted.String(),
-		Inserted:     inserted.String(),
-		CursorBefore: doc.cursor.ByteOffset(),
-	}
+	e.activeDoc().cursor.Sync()
+	e.activeDoc().buffer.Insert(s)
+	e.activeDoc().cursor.SetByteOffset(e.activeDoc().cursor.ByteOffset() + len(s))
 
-	// Replace the text
-	doc.cursor.Sync()
-	doc.buffer.Replace(rangeStart, rangeEnd, inserted.String())
+	entry.CursorAfter = e.activeDoc().cursor.ByteOffset()
+	e.activeDoc().undoStack.Push(entry)
+	e.activeDoc().modified = true
+}
 
-	// Update selection to cover the indented lines
-	newEndPos := rangeStart + len(inserted.String())
-	if newEndPos > 0 && inserted.String()[len(inserted.String())-1] == '\n' {
-		newEndPos-- // Don't include trailing newline in selection
+// getIndentString returns the string to use for one level of indentation
+func (e *Editor) getIndentString() string {
+	fs := e.fileSettings()
+	if fs.TabsToSpaces {
+		return strings.Repeat(" ", fs.TabWidth)
 	}
-	sel.Anchor = rangeStart
-	sel.Cursor = newEndPos
+	return "\t"
+}
+
+// indentLines indents all lines in the current selection
+func (e *Editor) indentLines() {
+	doc := e.activeDoc()
+	sel := doc.selection
 
-	// Position cursor at end of selection
-	doc.cursor.SetByteOffset(newEndPos)
+	// If no selection, just insert tab at cursor
+	if !sel.Active || sel.IsEmpty() {
+		e.insertText(e.getIndentString())
+		return
+	}
 
-	entry.CursorAfter = doc.cursor.ByteOffset()
-	doc.undoStack.Push(entry)
-	doc.modified = true
+	indent := e.getIndentString()
+	e.editLines("Indent", func(string) (int, string) {
+		return 0, indent
+	})
 }
 
 // dedentLines removes one level of indentation from all lines in the selection
@@ -3962,8 +4897,34 @@ func (e *Editor) dedentLines() {
 		sel.Update(doc.buffer.LineEndOffset(line))
 	}
 
+	tabWidth := e.fileSettings().TabWidth
+	if !e.editLines("Dedent", func(line string) (int, string) {
+		// Remove one tab, or up to tabWidth spaces
+		if strings.HasPrefix(line, "\t") {
+			return 1, ""
+		}
+		n := 0
+		for n < len(line) && n < tabWidth && line[n] == ' ' {
+			n++
+		}
+		return n, ""
+	}) {
+		// Nothing to dedent
+		sel.Clear()
+	}
+}
+
+// editLines changes the start of each line the selection touches: edit
+// returns how many bytes to remove from the start of a line and what to
+// put there. Each line is its own edit in one undo entry, so the rest of
+// the lines are left as they are, and the lines end up selected from the
+// start of the first, also after undo and redo. It reports false, leaving
+// the buffer alone, when no line changes.
+func (e *Editor) editLines(name string, edit func(line string) (int, string)) bool {
+	doc := e.activeDoc()
+
 	// Get the line range of the selection
-	startPos, endPos := sel.Normalize()
+	startPos, endPos := doc.selection.Normalize()
 	startLine, _ := doc.buffer.PositionToLineCol(startPos)
 	endLine, endCol := doc.buffer.PositionToLineCol(endPos)
 
@@ -3972,88 +4933,85 @@ func (e *Editor) dedentLines() {
 		endLine--
 	}
 
-	tabWidth := e.config.Editor.TabWidth
-	if tabWidth <= 0 {
-		tabWidth = 4
-	}
-
-	// Calculate the range we're modifying
-	rangeStart := doc.buffer.LineStartOffset(startLine)
-	rangeEnd := doc.buffer.LineEndOffset(endLine)
-	if rangeEnd < doc.buffer.Length() {
-		if r, size := doc.buffer.RuneAt(rangeEnd); r == '\n' {
-			rangeEnd += size
-		}
-	}
-
-	// Get original text
-	originalText := doc.buffer.Substring(rangeStart, rangeEnd)
-
-	// Build new text with dedentation
-	var inserted strings.Builder
-	lines := strings.Split(originalText, "\n")
-	changed := false
-
-	for i, line := range lines {
-		newLine := line
-		if len(line) > 0 {
-			if line[0] == '\t' {
-				// Remove one tab
-				newLine = line[1:]
-				changed = true
-			} else if line[0] == ' ' {
-				// Remove up to tabWidth spaces
-				spacesToRemove := 0
-				for j := 0; j < len(line) && j < tabWidth && line[j] == ' '; j++ {
-					spacesToRemove++
-				}
-				if spacesToRemove > 0 {
-					newLine = line[spacesToRemove:]
-					changed = true
-				}
-			}
-		}
-		inserted.WriteString(newLine)
-		if i < len(lines)-1 {
-			inserted.WriteString("\n")
-		}
+	if startLine == endLine {
+		name = fmt.Sprintf("%s line %d", name, startLine+1)
+	} else {
+		name = fmt.Sprintf("%s lines %d-%d", name, startLine+1, endLine+1)
 	}
+	return e.editLineRange(name, startLine, endLine, true, edit) > 0
+}
 
-	if !changed {
-		// Nothing to dedent
-		sel.Clear()
-		return
+// editLineRange changes the start of lines startLine to endLine as
+// editLines does, naming the undo entry name. With selectLines the lines
+// end up selected; otherwise the cursor keeps its place in its line's text.
+// It returns how many lines changed.
+func (e *Editor) editLineRange(name string, startLine, endLine int, selectLines bool, edit func(line string) (int, string)) int {
+	if e.refuseEdit() {
+		return 0
 	}
-
-	// Create undo entry
+	doc := e.activeDoc()
 	entry := &UndoEntry{
-		Position:     rangeStart,
-		Deleted:      originalText,
-		Inserted:     inserted.String(),
+		Name:         name,
+		Position:     doc.buffer.LineStartOffset(startLine),
 		CursorBefore: doc.cursor.ByteOffset(),
+		SelectLines:  selectLines,
+		FirstLine:    startLine,
+		LastLine:     endLine,
 	}
 
-	// Replace the text
-	doc.cursor.Sync()
-	doc.buffer.Replace(rangeStart, rangeEnd, inserted.String())
+	// Bytes from the cursor to the end of its line, which no edit touches
+	// unless the cursor is in the part replaced
+	cursorLine := doc.cursor.Line()
+	fromEnd := doc.buffer.LineEndOffset(cursorLine) - doc.cursor.ByteOffset()
 
-	// Update selection to cover the dedented lines
-	newEndPos := rangeStart + len(inserted.String())
-	if newEndPos > 0 && len(inserted.String()) > 0 && inserted.String()[len(inserted.String())-1] == '\n' {
-		newEndPos--
+	doc.cursor.Sync()
+	for line := startLine; line <= endLine; line++ {
+		pos := doc.buffer.LineStartOffset(line)
+		remove, insert := edit(doc.buffer.Substring(pos, doc.buffer.LineEndOffset(line)))
+		if remove == 0 && insert == "" {
+			continue
+		}
+		entry.Edits = append(entry.Edits, UndoEdit{
+			Position: pos,
+			Deleted:  doc.buffer.Substring(pos, pos+remove),
+			Inserted: insert,
+		})
+		doc.buffer.Replace(pos, pos+remove, insert)
+	}
+	if len(entry.Edits) == 0 {
+		return 0
 	}
-	sel.Anchor = rangeStart
-	sel.Cursor = newEndPos
-
-	// Position cursor at end of selection
-	doc.cursor.SetByteOffset(newEndPos)
 
+	if selectLines {
+		e.selectEntryLines(entry)
+	} else {
+		end := doc.buffer.LineEndOffset(cursorLine)
+		doc.cursor.SetByteOffset(max(end-fromEnd, doc.buffer.LineStartOffset(cursorLine)))
+	}
 	entry.CursorAfter = doc.cursor.ByteOffset()
 	doc.undoStack.Push(entry)
 	doc.modified = true
+	return len(entry.Edits)
+}
+
+// selectEntryLines selects the lines an undo entry covers, from the start
+// of the first to the end of the last, with the cursor at the end
+func (e *Editor) selectEntryLines(entry *UndoEntry) {
+	if !entry.SelectLines {
+		return
+	}
+	doc := e.activeDoc()
+	last := min(entry.LastLine, doc.buffer.LineCount()-1)
+	end := doc.buffer.LineEndOffset(last)
+	doc.selection.Start(doc.buffer.LineStartOffset(min(entry.FirstLine, last)))
+	doc.selection.Update(end)
+	doc.cursor.SetByteOffset(end)
 }
 
 func (e *Editor) backspace() {
+	if e.refuseEdit() {
+		return
+	}
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 		return
@@ -4066,9 +5024,9 @@ func (e *Editor) backspace() {
 	// Sync cursor position to buffer gap
 	e.activeDoc().cursor.Sync()
 
-	// Get info about what we're about to delete (the rune before cursor)
+	// Delete the whole character before the cursor, with its combining marks
 	pos := e.activeDoc().cursor.ByteOffset()
-	deleted := e.activeDoc().buffer.DeleteRuneBefore()
+	deleted := e.activeDoc().buffer.DeleteBefore(e.activeDoc().buffer.graphemeBefore(pos))
 	if deleted == "" {
 		return
 	}
@@ -4089,6 +5047,9 @@ func (e *Editor) backspace() {
 }
 
 func (e *Editor) delete() {
+	if e.refuseEdit() {
+		return
+	}
 	if e.activeDoc().selection.Active && !e.activeDoc().selection.IsEmpty() {
 		e.deleteSelection()
 		return
@@ -4098,7 +5059,7 @@ func (e *Editor) delete() {
 		return
 	}
 
-	_, size := e.activeDoc().buffer.RuneAt(e.activeDoc().cursor.ByteOffset())
+	size := e.activeDoc().buffer.graphemeAfter(e.activeDoc().cursor.ByteOffset())
 	if size == 0 {
 		return
 	}
@@ -4117,6 +5078,9 @@ func (e *Editor) delete() {
 }
 
 func (e *Editor) deleteSelection() {
+	if e.refuseEdit() {
+		return
+	}
 	if !e.activeDoc().selection.Active || e.activeDoc().selection.IsEmpty() {
 		return
 	}
@@ -4139,12 +5103,19 @@ func (e *Editor) deleteSelection() {
 }
 
 func (e *Editor) undo() {
+	if e.refuseEdit() {
+		return
+	}
 	entry := e.activeDoc().undoStack.Undo()
 	if entry == nil {
 		return
 	}
 
 	// Reverse the operation
+	for i := len(entry.Edits) - 1; i >= 0; i-- {
+		ed := entry.Edits[i]
+		e.activeDoc().buffer.Replace(ed.Position, ed.Position+len(ed.Inserted), ed.Deleted)
+	}
 	if entry.Inserted != "" {
 		// Was an insertion - delete it
 		e.activeDoc().buffer.Replace(entry.Position, entry.Position+len(entry.Inserted), "")
@@ -4157,16 +5128,23 @@ func (e *Editor) undo() {
 
 	e.activeDoc().cursor.SetByteOffset(entry.CursorBefore)
 	e.activeDoc().selection.Clear()
+	e.selectEntryLines(entry)
 	e.activeDoc().modified = true
 }
 
 func (e *Editor) redo() {
+	if e.refuseEdit() {
+		return
+	}
 	entry := e.activeDoc().undoStack.Redo()
 	if entry == nil {
 		return
 	}
 
 	// Replay the operation
+	for _, ed := range entry.Edits {
+		e.activeDoc().buffer.Replace(ed.Position, ed.Position+len(ed.Deleted), ed.Inserted)
+	}
 	if entry.Deleted != "" {
 		// Was a deletion - delete it again
 		e.activeDoc().buffer.Replace(entry.Position, entry.Position+len(entry.Deleted), "")
@@ -4179,21 +5157,27 @@ func (e *Editor) redo() {
 
 	e.activeDoc().cursor.SetByteOffset(entry.CursorAfter)
 	e.activeDoc().selection.Clear()
+	e.selectEntryLines(entry)
 	e.activeDoc().modified = true
 }
 
 func (e *Editor) cut() {
+	if e.refuseEdit() {
+		return
+	}
 	if !e.activeDoc().selection.Active || e.activeDoc().selection.IsEmpty() {
 		return
 	}
 
 	text := e.activeDoc().selection.GetText(e.activeDoc().buffer)
-	e.clipboard.Copy(text)
-	e.deleteSelection()
+	e.copyText(text, "Cut", e.deleteSelection)
 }
 
 // cutLine cuts the entire current line (like nano's Ctrl+K)
 func (e *Editor) cutLine() {
+	if e.refuseEdit() {
+		return
+	}
 	line := e.activeDoc().cursor.Line()
 	lineStart := e.activeDoc().buffer.LineStartOffset(line)
 	lineEnd := e.activeDoc().buffer.LineEndOffset(line)
@@ -4240,18 +5224,19 @@ func (e *Editor) copy() {
 	}
 
 	text := e.activeDoc().selection.GetText(e.activeDoc().buffer)
-	e.clipboard.Copy(text)
-	e.statusbar.SetMessage("Copied", "info")
+	e.copyText(text, "Copied", nil)
 }
 
-func (e *Editor) paste() {
-	text, err := e.clipboard.Paste()
-	if err != nil || text == "" {
+// copyAppend appends the selection to the existing clipboard contents
+// so several snippets can be collected before a single paste
+func (e *Editor) copyAppend() {
+	if !e.activeDoc().selection.Active || e.activeDoc().selection.IsEmpty() {
 		return
 	}
 
-	e.insertText(text)
-	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	// The limit applies to the clipboard as it will be after appending
+	text := e.activeDoc().selection.GetText(e.activeDoc().buffer)
+	e.copyText(e.clipboard.Appended(text, "\n"), "Appended, clipboard holds", nil)
 }
 
 func (e *Editor) selectAll() {
@@ -4281,7 +5266,7 @@ func (e *Editor) doNewFile() {
 		buffer:      buf,
 		cursor:      NewCursor(buf),
 		selection:   NewSelection(),
-		undoStack:   NewUndoStack(100),
+		undoStack:   e.newUndoStack(100),
 		filename:    "",
 		modified:    false,
 		scrollY:     0,
@@ -4290,6 +5275,7 @@ func (e *Editor) doNewFile() {
 	}
 	e.documents = append(e.documents, doc)
 	e.activeIdx = len(e.documents) - 1
+	e.applyFileSettings()
 
 	e.updateTitle()
 	e.updateMenuState()
@@ -4298,14 +5284,15 @@ func (e *Editor) doNewFile() {
 
 // closeFile closes the current file (same as new, but different messaging)
 func (e *Editor) closeFile() {
-	if e.activeDoc().modified {
-		e.showPrompt("Unsaved changes. Discard? (y/N): ", PromptConfirmClose)
-		return
-	}
-	e.doCloseFile()
+	e.confirmUnsaved("Close", "Save them before closing?", []*Document{e.activeDoc()}, func() tea.Cmd {
+		e.doCloseFile()
+		return nil
+	})
 }
 
 func (e *Editor) doCloseFile() {
+	e.noteArgClosed(e.activeDoc())
+	e.rememberClosed(e.activeDoc())
 	if len(e.documents) > 1 {
 		// Multiple buffers - remove current and switch to another
 		e.documents = append(e.documents[:e.activeIdx], e.documents[e.activeIdx+1:]...)
@@ -4323,44 +5310,222 @@ func (e *Editor) doCloseFile() {
 		e.activeDoc().undoStack.Clear()
 		e.activeDoc().filename = ""
 		e.activeDoc().modified = false
+		e.activeDoc().savedLines = nil
 		e.activeDoc().scrollY = 0
 		e.activeDoc().highlighter.SetFile("")
 		e.activeDoc().encoding = enc.GetEncodingByID("utf-8")
+		e.activeDoc().readOnly = false
+		e.activeDoc().hexView = false
+		e.activeDoc().hexData = nil
 		e.viewport.SetScrollY(0)
 		e.statusbar.SetMessage("File closed", "info")
 	}
+	e.applyFileSettings()
 	e.updateTitle()
 	e.updateMenuState()
 }
 
-// quitEditor exits the editor, checking for unsaved changes in ALL buffers
-func (e *Editor) quitEditor() tea.Cmd {
-	// Check all buffers for unsaved changes
-	unsavedCount := 0
-	for _, doc := range e.documents {
-		if doc.modified {
-			unsavedCount++
+// reportOpened shows the status message after opening a file,
+// warning instead when it is read-only
+func (e *Editor) reportOpened(path string) {
+	if e.activeDoc().hexView {
+		e.statusbar.SetMessage(binaryWarning, "warning")
+		return
+	}
+	if archive, ok := archiveMember(e.activeDoc().filename); ok {
+		e.statusbar.SetMessage(archiveWarning(archive), "warning")
+		return
+	}
+	if e.activeDoc().readOnly {
+		e.statusbar.SetMessage("Read-only: "+path+" - use Save As to keep changes", "warning")
+		return
+	}
+	if warning := e.hazardWarning(e.activeDoc().buffer.String()); warning != "" {
+		e.statusbar.SetMessage(warning, "warning")
+		return
+	}
+	e.statusbar.SetMessage("Opened: "+path, "success")
+}
+
+// warnReadOnlyEdit warns once when a read-only buffer is first modified
+func (e *Editor) warnReadOnlyEdit() {
+	doc := e.activeDoc()
+	if !doc.readOnly || !doc.modified || doc.roWarned {
+		return
+	}
+	doc.roWarned = true
+	e.statusbar.SetMessage("Warning: file is read-only - save with Save As", "warning")
+}
+
+// refuseEdit reports whether the active buffer can't be edited at all (a hex
+// view, a followed file or a file inside an archive), saying why. Edit
+// commands check it before they change anything, clipboard included.
+func (e *Editor) refuseEdit() bool {
+	doc := e.activeDoc()
+	var msg string
+	switch {
+	case doc.hexView:
+		msg = "Hex view is read-only - Options > Hex View to edit as text"
+	case doc.follow:
+		msg = "Followed files are read-only - Options > Follow File to edit"
+	default:
+		archive, ok := archiveMember(doc.filename)
+		if !ok {
+			return false
+		}
+		msg = archiveWarning(archive)
+	}
+	e.statusbar.SetMessage(msg, "warning")
+	return true
+}
+
+// undoBudget returns the configured undo memory budget per buffer in bytes (0 = unlimited)
+func (e *Editor) undoBudget() int {
+	if e.config == nil || e.config.Editor.UndoMemoryMB <= 0 {
+		return 0
+	}
+	return e.config.Editor.UndoMemoryMB << 20
+}
+
+// undoCheckpointInterval returns how often the undo history marks a
+// checkpoint while editing (0 = never)
+func (e *Editor) undoCheckpointInterval() time.Duration {
+	if e.config == nil {
+		return 0
+	}
+	return time.Duration(e.config.Editor.UndoCheckpointMinutes) * time.Minute
+}
+
+// newUndoStack creates an undo stack limited by the configured memory
+// budget and marking checkpoints at the configured interval
+func (e *Editor) newUndoStack(maxSize int) *UndoStack {
+	u := NewUndoStack(maxSize)
+	u.SetMaxBytes(e.undoBudget())
+	u.SetCheckpointInterval(e.undoCheckpointInterval())
+	return u
+}
+
+// closedBuffer remembers where a closed file was so it can be reopened
+type closedBuffer struct {
+	filename string
+	offset   int // Cursor byte offset
+	scrollY  int
+}
+
+// maxClosedBuffers limits the close history
+const maxClosedBuffers = 20
+
+// rememberClosed records a named document in the close history
+func (e *Editor) rememberClosed(doc *Document) {
+	if doc.filename == "" {
+		return
+	}
+	scrollY := doc.scrollY
+	if doc == e.activeDoc() {
+		scrollY = e.viewport.ScrollY()
+	}
+	e.forgetClosed(doc.filename)
+	e.closedBuffers = append(e.closedBuffers, closedBuffer{
+		filename: doc.filename,
+		offset:   doc.cursor.ByteOffset(),
+		scrollY:  scrollY,
+	})
+	if len(e.closedBuffers) > maxClosedBuffers {
+		e.closedBuffers = e.closedBuffers[len(e.closedBuffers)-maxClosedBuffers:]
+	}
+}
+
+// forgetClosed drops a file from the close history
+func (e *Editor) forgetClosed(filename string) {
+	for i, cb := range e.closedBuffers {
+		if cb.filename == filename {
+			e.closedBuffers = append(e.closedBuffers[:i], e.closedBuffers[i+1:]...)
+			return
 		}
 	}
-	if unsavedCount > 0 {
-		msg := "Unsaved changes. Quit anyway? (y/N): "
-		if unsavedCount > 1 {
-			msg = fmt.Sprintf("%d unsaved buffers. Quit anyway? (y/N): ", unsavedCount)
+}
+
+// reopenClosed reopens the most recently closed file at its old cursor position.
+// Repeated use walks back through the close history.
+func (e *Editor) reopenClosed() {
+	for len(e.closedBuffers) > 0 {
+		cb := e.closedBuffers[len(e.closedBuffers)-1]
+		e.closedBuffers = e.closedBuffers[:len(e.closedBuffers)-1]
+
+		// Skip files that are open again or have gone away
+		if e.findBufferByFilename(cb.filename) >= 0 {
+			continue
 		}
-		e.showPrompt(msg, PromptConfirmQuit)
-		return nil
+		if _, err := os.Stat(cb.filename); err != nil {
+			continue
+		}
+
+		if err := e.LoadFile(cb.filename); err != nil {
+			// Keep the entry so it can be retried after closing a buffer
+			e.closedBuffers = append(e.closedBuffers, cb)
+			e.statusbar.SetMessage("Error: "+err.Error(), "error")
+			return
+		}
+		doc := e.activeDoc()
+		offset := cb.offset
+		if offset > doc.buffer.Length() {
+			offset = doc.buffer.Length()
+		}
+		doc.cursor.SetByteOffset(offset)
+		e.viewport.SetScrollY(cb.scrollY)
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+		if !doc.readOnly {
+			e.statusbar.SetMessage("Reopened: "+filepath.Base(cb.filename), "info")
+		}
+		e.updateMenuState()
+		return
 	}
-	return tea.Quit
+	e.statusbar.SetMessage("No closed buffers to reopen", "info")
+	e.updateMenuState()
+}
+
+// quitEditor exits the editor, first asking about each buffer with unsaved
+// changes. It returns nil while asking.
+func (e *Editor) quitEditor() tea.Cmd {
+	return e.confirmUnsaved("Quit", "Save them before quitting?", e.documents, e.quit)
 }
 
-// updateTitle sets the terminal title
+// updateTitle sets the terminal title from the configured format
 func (e *Editor) updateTitle() {
-	e.pendingTitle = "textivus"
-	if e.activeDoc().filename != "" {
-		e.pendingTitle += " - " + e.activeDoc().filename
-	} else {
-		e.pendingTitle += " - [Untitled]"
+	format := config.DefaultTitleFormat
+	if e.config != nil && e.config.Editor.TitleFormat != "" {
+		format = e.config.Editor.TitleFormat
 	}
+	e.pendingTitle = formatTitle(format, e.activeDoc().filename, e.activeDoc().modified)
+}
+
+// formatTitle fills in a title format's placeholders for a file. Control
+// characters are dropped so a file name can't end the title escape sequence.
+func formatTitle(format, filename string, modified bool) string {
+	path, base, dir := "[Untitled]", "[Untitled]", ""
+	if filename != "" {
+		path, base = filename, filepath.Base(filename)
+		dir = filepath.Dir(filename)
+		if abs, err := filepath.Abs(filename); err == nil {
+			dir = filepath.Dir(abs)
+		}
+	}
+	marker := ""
+	if modified {
+		marker = "*"
+	}
+	title := strings.NewReplacer(
+		"{path}", path,
+		"{basename}", base,
+		"{dir}", dir,
+		"{modified}", marker,
+	).Replace(format)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, title)
 }
 
 // getTitle returns the current title for the terminal
@@ -4372,18 +5537,39 @@ func (e *Editor) getTitle() string {
 func (e *Editor) updateMenuState() {
 	// Revert is disabled if there's no file to revert to
 	e.menubar.SetItemDisabled(ui.ActionRevert, e.activeDoc().filename == "")
+	e.menubar.SetItemDisabled(ui.ActionRestoreBackup, e.activeDoc().filename == "")
+	e.menubar.SetItemDisabled(ui.ActionLocalHistory, e.activeDoc().filename == "")
+	e.menubar.SetItemDisabled(ui.ActionReopenClosed, len(e.closedBuffers) == 0)
+	e.menubar.SetItemDisabled(ui.ActionCloseOthers, len(e.documents) == 1)
+
+	// The BOM item names what it will do, and only applies to Unicode
+	if docEnc := e.docEncoding(); docEnc.WriteBOM {
+		e.menubar.SetItemLabel(ui.ActionToggleBOM, "Remove BOM")
+	} else {
+		e.menubar.SetItemLabel(ui.ActionToggleBOM, "Add BOM")
+	}
+	e.menubar.SetItemDisabled(ui.ActionToggleBOM, enc.BOMVariant(e.docEncoding()) == nil)
+
+	if e.activeDoc().hexView {
+		e.menubar.SetItemLabel(ui.ActionHexView, "[x] Hex View")
+	} else {
+		e.menubar.SetItemLabel(ui.ActionHexView, "[ ] Hex View")
+	}
+	e.menubar.SetItemDisabled(ui.ActionHexView, e.activeDoc().filename == "")
+	if e.activeDoc().follow {
+		e.menubar.SetItemLabel(ui.ActionFollowFile, "[x] Follow File")
+	} else {
+		e.menubar.SetItemLabel(ui.ActionFollowFile, "[ ] Follow File")
+	}
+	e.menubar.SetItemDisabled(ui.ActionFollowFile, e.activeDoc().filename == "")
+	e.menubar.SetItemDisabled(ui.ActionGoToSymbol, !symbols.Supported(e.activeDoc().highlighter.Language()))
 
 	// Update buffers menu
-	var names []string
-	for _, doc := range e.documents {
-		name := "[Untitled]"
-		if doc.filename != "" {
-			name = filepath.Base(doc.filename)
-		}
+	names := e.bufferNames()
+	for i, doc := range e.documents {
 		if doc.modified {
-			name = "*" + name
+			names[i] = "*" + names[i]
 		}
-		names = append(names, name)
 	}
 	e.menubar.SetBuffers(names, e.activeIdx)
 }
@@ -4415,22 +5601,6 @@ func (e *Editor) revertFile() {
 	}
 }
 
-func (e *Editor) insertLoremIpsum() {
-	lorem := `Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.
-
-Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident.
-
-Sed ut perspiciatis unde omnis iste natus error sit voluptatem accusantium doloremque laudantium, totam rem aperiam, eaque ipsa quae ab illo inventore veritatis et quasi architecto beatae vitae dicta sunt explicabo.
-
-Nemo enim ipsam voluptatem quia voluptas sit aspernatur aut odit aut fugit, sed quia consequuntur magni dolores eos qui ratione voluptatem sequi nesciunt.
-
-Neque porro quisquam est, qui dolorem ipsum quia dolor sit amet, consectetur, adipisci velit, sed quia non numquam eius modi tempora incidunt ut labore et dolore magnam aliquam quaerat voluptatem.
-`
-	e.insertText(lorem)
-	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
-	e.statusbar.SetMessage("Inserted lorem ipsum", "info")
-}
-
 func (e *Editor) findNext() {
 	if e.findQuery == "" {
 		return
@@ -4442,41 +5612,42 @@ func (e *Editor) findNext() {
 		startPos = 0
 	}
 
-	// Search from cursor position
-	idx := strings.Index(content[startPos:], e.findQuery)
-	if idx >= 0 {
-		pos := startPos + idx
-		e.activeDoc().cursor.SetByteOffset(pos)
-		e.activeDoc().selection.Active = true
-		e.activeDoc().selection.Anchor = pos
-		e.activeDoc().selection.Cursor = pos + len(e.findQuery)
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	matches := e.findMatches(content, e.findQuery)
+	if len(matches) == 0 {
+		e.statusbar.SetMessage("Not found", "error")
 		return
 	}
-
-	// Wrap around
-	idx = strings.Index(content[:startPos], e.findQuery)
-	if idx >= 0 {
-		e.activeDoc().cursor.SetByteOffset(idx)
-		e.activeDoc().selection.Active = true
-		e.activeDoc().selection.Anchor = idx
-		e.activeDoc().selection.Cursor = idx + len(e.findQuery)
-		e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	match, wrapped, ok := e.nextMatch(matches, startPos)
+	if !ok {
+		e.statusbar.SetMessage("No more matches (wrap is off)", "error")
 		return
 	}
-
-	e.statusbar.SetMessage("Not found", "error")
+	if wrapped {
+		e.statusbar.SetMessage("Search wrapped to the top", "info")
+	}
+	e.activeDoc().cursor.SetByteOffset(match.start)
+	e.activeDoc().selection.Active = true
+	e.activeDoc().selection.Anchor = match.start
+	e.activeDoc().selection.Cursor = match.end
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 }
 
 // showFindReplace opens the find and replace bar
 func (e *Editor) showFindReplace() {
 	e.mode = ModeFindReplace
 	e.replaceFocus = false // Start with focus on find field
+	e.findCursor, e.replaceCursor = 0, 0
+	e.findHistory.reset()
+	e.replaceHistory.reset()
 	e.updateViewportSize()
 }
 
 // handleFindReplaceKey handles keyboard input in find/replace mode
 func (e *Editor) handleFindReplaceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if e.queryReplacing {
+		return e.handleQueryReplaceKey(msg)
+	}
+
 	switch msg.Type {
 	case tea.KeyEsc:
 		e.mode = ModeNormal
@@ -4486,44 +5657,52 @@ func (e *Editor) handleFindReplaceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyTab:
 		// Switch between find and replace fields
 		e.replaceFocus = !e.replaceFocus
+		e.findHistory.reset()
+		e.replaceHistory.reset()
 		return e, nil
 
 	case tea.KeyEnter:
 		// Replace next occurrence
+		e.rememberSearch(true)
 		e.replaceNext()
 		return e, nil
 
 	case tea.KeyCtrlA:
 		// Replace all
+		e.rememberSearch(true)
 		e.replaceAll()
 		return e, nil
 
-	case tea.KeyBackspace:
-		if e.replaceFocus {
-			if len(e.replaceQuery) > 0 {
-				e.replaceQuery = e.replaceQuery[:len(e.replaceQuery)-1]
-			}
-		} else {
-			if len(e.findQuery) > 0 {
-				e.findQuery = e.findQuery[:len(e.findQuery)-1]
-			}
-		}
+	case tea.KeyCtrlR:
+		// Replace, asking at each match
+		e.rememberSearch(true)
+		e.startQueryReplace()
 		return e, nil
 
-	case tea.KeyRunes:
+	case tea.KeyUp, tea.KeyDown:
 		if e.replaceFocus {
-			e.replaceQuery += string(msg.Runes)
+			recallHistory(&e.replaceHistory, &e.replaceQuery, msg.Type == tea.KeyUp)
+			e.replaceCursor = 0
 		} else {
-			e.findQuery += string(msg.Runes)
+			recallHistory(&e.findHistory, &e.findQuery, msg.Type == tea.KeyUp)
+			e.findCursor = 0
 		}
 		return e, nil
 
-	case tea.KeySpace:
-		if e.replaceFocus {
-			e.replaceQuery += " "
-		} else {
-			e.findQuery += " "
+	case tea.KeyRunes:
+		if msg.Alt {
+			e.handleSearchOptionKey(string(msg.Runes))
+			return e, nil
 		}
+	}
+
+	field, cursor := &e.findQuery, &e.findCursor
+	if e.replaceFocus {
+		field, cursor = &e.replaceQuery, &e.replaceCursor
+	}
+	if e.editInput(field, cursor, msg) {
+		e.findHistory.reset()
+		e.replaceHistory.reset()
 		return e, nil
 	}
 
@@ -4539,38 +5718,38 @@ func (e *Editor) handleFindReplaceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // replaceNext finds the next occurrence and replaces it
 func (e *Editor) replaceNext() {
+	if e.refuseEdit() {
+		return
+	}
 	if e.findQuery == "" {
 		e.statusbar.SetMessage("No search term", "error")
 		return
 	}
 
 	content := e.activeDoc().buffer.String()
-	startPos := e.activeDoc().cursor.ByteOffset()
-
-	// Search from cursor position
-	idx := strings.Index(content[startPos:], e.findQuery)
-	if idx < 0 {
-		// Wrap around
-		idx = strings.Index(content[:startPos], e.findQuery)
-		if idx < 0 {
-			e.statusbar.SetMessage("Not found", "error")
-			return
-		}
-	} else {
-		idx = startPos + idx
+	matches := e.findMatches(content, e.findQuery)
+	if len(matches) == 0 {
+		e.statusbar.SetMessage("Not found", "error")
+		return
+	}
+	match, _, ok := e.nextMatch(matches, e.activeDoc().cursor.ByteOffset())
+	if !ok {
+		e.statusbar.SetMessage("No more matches (wrap is off)", "error")
+		return
 	}
+	idx := match.start
 
 	// Create undo entry for the replacement
 	entry := &UndoEntry{
 		Position:     idx,
-		Deleted:      e.findQuery,
+		Deleted:      content[match.start:match.end],
 		Inserted:     e.replaceQuery,
 		CursorBefore: e.activeDoc().cursor.ByteOffset(),
 		CursorAfter:  idx + len(e.replaceQuery),
 	}
 
 	// Perform the replacement
-	e.activeDoc().buffer.Replace(idx, idx+len(e.findQuery), e.replaceQuery)
+	e.activeDoc().buffer.Replace(idx, match.end, e.replaceQuery)
 	e.activeDoc().cursor.SetByteOffset(idx + len(e.replaceQuery))
 	e.activeDoc().selection.Clear()
 	e.activeDoc().undoStack.Push(entry)
@@ -4582,13 +5761,17 @@ func (e *Editor) replaceNext() {
 
 // replaceAll replaces all occurrences with a single undo entry
 func (e *Editor) replaceAll() {
+	if e.refuseEdit() {
+		return
+	}
 	if e.findQuery == "" {
 		e.statusbar.SetMessage("No search term", "error")
 		return
 	}
 
 	content := e.activeDoc().buffer.String()
-	count := strings.Count(content, e.findQuery)
+	matches := e.findMatches(content, e.findQuery)
+	count := len(matches)
 	if count == 0 {
 		e.statusbar.SetMessage("Not found", "error")
 		return
@@ -4599,7 +5782,7 @@ func (e *Editor) replaceAll() {
 	cursorBefore := e.activeDoc().cursor.ByteOffset()
 
 	// Replace all occurrences
-	newContent := strings.ReplaceAll(content, e.findQuery, e.replaceQuery)
+	newContent := replaceMatches(content, matches, e.replaceQuery)
 
 	// Create a single undo entry for the entire operation
 	entry := &UndoEntry{
@@ -4614,6 +5797,7 @@ func (e *Editor) replaceAll() {
 	e.activeDoc().buffer = NewBufferFromString(newContent)
 	e.activeDoc().cursor = NewCursor(e.activeDoc().buffer)
 	e.activeDoc().selection.Clear()
+	e.activeDoc().undoStack.Checkpoint("Before Replace All")
 	e.activeDoc().undoStack.Push(entry)
 	e.activeDoc().modified = true
 
@@ -4622,6 +5806,15 @@ func (e *Editor) replaceAll() {
 
 // View implements tea.Model
 func (e *Editor) View() string {
+	defer e.recoverPanic()
+	defer e.logRender(time.Now())
+	if f := e.onFirstRender; f != nil {
+		e.onFirstRender = nil
+		defer f()
+	}
+	if e.tooSmall() {
+		return e.tooSmallView()
+	}
 	var sb strings.Builder
 
 	// Set terminal title using OSC escape sequence
@@ -4635,6 +5828,12 @@ func (e *Editor) View() string {
 		e.pendingEscapes = ""
 	}
 
+	// Cleanup from exit hooks; repeated on every render while quitting since
+	// Bubbletea may only flush the last frame
+	if e.quitting {
+		sb.WriteString(e.exitEscapes())
+	}
+
 	// Menu bar
 	sb.WriteString(e.menubar.View())
 	sb.WriteString("\n")
@@ -4643,6 +5842,10 @@ func (e *Editor) View() string {
 	renderState := e.buildRenderState()
 	viewportContent := e.compositor.Render(renderState)
 
+	if e.mode == ModeNormal {
+		viewportContent = e.overlayMinimapTooltip(viewportContent)
+	}
+
 	// If menu dropdown is open, overlay it on top of the viewport
 	if e.menubar.IsOpen() {
 		dropdownLines, offset := e.menubar.RenderDropdown()
@@ -4668,6 +5871,55 @@ func (e *Editor) View() string {
 		viewportContent = e.overlayAboutDialog(viewportContent)
 	}
 
+	// If statistics dialog is open, overlay it centered on the viewport
+	if e.mode == ModeStatistics {
+		viewportContent = e.overlayStatisticsDialog(viewportContent)
+	}
+
+	// If Paste from History is open, overlay it centered on the viewport
+	if e.mode == ModePasteHistory {
+		viewportContent = e.overlayPasteHistoryDialog(viewportContent)
+	}
+
+	// If Insert Buffer is open, overlay it centered on the viewport
+	if e.mode == ModeInsertBuffer {
+		viewportContent = e.overlayInsertBufferDialog(viewportContent)
+	}
+
+	// If the Annotations list is open, overlay it centered on the viewport
+	if e.mode == ModeAnnotations {
+		viewportContent = e.overlayAnnotationsDialog(viewportContent)
+	}
+
+	// If Quick Open is open, overlay it centered on the viewport
+	if e.mode == ModeQuickOpen {
+		viewportContent = e.overlayQuickOpenDialog(viewportContent)
+	}
+	if e.mode == ModeBufferList {
+		viewportContent = e.overlayBufferListDialog(viewportContent)
+	}
+	if e.mode == ModeUndoHistory {
+		viewportContent = e.overlayUndoHistoryDialog(viewportContent)
+	}
+	if e.mode == ModeIndent {
+		viewportContent = e.overlayIndentDialog(viewportContent)
+	}
+	if e.mode == ModeRestoreBackup {
+		viewportContent = e.overlayRestoreBackupDialog(viewportContent)
+	}
+	if e.mode == ModeLocalHistory {
+		viewportContent = e.overlayLocalHistoryDialog(viewportContent)
+	}
+	if e.mode == ModeSymbols {
+		viewportContent = e.overlaySymbolsDialog(viewportContent)
+	}
+	if e.mode == ModePrint {
+		viewportContent = e.overlayPrintDialog(viewportContent)
+	}
+	if e.mode == ModeInsertText {
+		viewportContent = e.overlayInsertTextDialog(viewportContent)
+	}
+
 	// If file browser is open, overlay it centered on the viewport
 	if e.mode == ModeFileBrowser {
 		viewportContent = e.overlayFileBrowser(viewportContent)
@@ -4713,6 +5965,11 @@ func (e *Editor) View() string {
 		viewportContent = e.overlayEncodingDialog(viewportContent)
 	}
 
+	// If a confirm dialog is open, overlay it centered on the viewport
+	if e.mode == ModeConfirm {
+		viewportContent = e.overlayConfirmDialog(viewportContent)
+	}
+
 	sb.WriteString(viewportContent)
 	sb.WriteString("\n")
 
@@ -4721,58 +5978,57 @@ func (e *Editor) View() string {
 
 	// Find bar if active
 	if e.mode == ModeFind {
-		findContent := "Find: " + e.findQuery
-		cursor := "▂" // Lower quarter block cursor
-		padding := e.width - len(findContent) - 1
+		findContent := e.findBarLabel() + e.findQuery
+		hints := " [Enter] Next [Alt+Enter] Select All [Alt+S/C/W/P] Options"
+		padding := e.width - len(findContent) - 1 - len(hints)
 		if padding < 0 {
-			padding = 0
+			hints = ""
+			padding = max(e.width-len(findContent)-1, 0)
 		}
 		sb.WriteString(barColor)
-		sb.WriteString(findContent)
-		sb.WriteString(cursor)
+		sb.WriteString(e.findBarLabel())
+		sb.WriteString(inputWithCursor(e.findQuery, e.findCursor))
 		sb.WriteString(strings.Repeat(" ", padding))
+		sb.WriteString(hints)
 		sb.WriteString("\033[0m\n")
 	}
 
 	// Find/Replace bar if active (two lines)
 	if e.mode == ModeFindReplace {
-		cursor := "▂" // Lower quarter block cursor
-
 		// Line 1: Find field
-		findLine := "Find: " + e.findQuery
-		findCursorStr := ""
-		if !e.replaceFocus {
-			findCursorStr = cursor
-		}
+		findLine := e.findBarLabel() + e.findQuery
 		findPadding := e.width - len(findLine) - 1
 		if findPadding < 0 {
 			findPadding = 0
 		}
 		sb.WriteString(barColor)
-		sb.WriteString(findLine)
-		sb.WriteString(findCursorStr)
 		if e.replaceFocus {
+			sb.WriteString(findLine)
 			sb.WriteString(" ") // Space where cursor would be
+		} else {
+			sb.WriteString(e.findBarLabel())
+			sb.WriteString(inputWithCursor(e.findQuery, e.findCursor))
 		}
 		sb.WriteString(strings.Repeat(" ", findPadding))
 		sb.WriteString("\033[0m\n")
 
 		// Line 2: Replace field with hints
 		replaceLine := "Replace: " + e.replaceQuery
-		replaceCursorStr := ""
-		if e.replaceFocus {
-			replaceCursorStr = cursor
+		hints := " [Tab] Switch [Enter] Replace [Ctrl+A] All [Ctrl+R] Ask"
+		if e.queryReplacing {
+			hints = queryReplaceHints
 		}
-		hints := " [Tab] Switch [Enter] Replace [Ctrl+A] All"
 		availSpace := e.width - len(replaceLine) - 1 - len(hints)
 		if availSpace < 0 {
 			availSpace = 0
 			hints = ""
 		}
 		sb.WriteString(barColor)
-		sb.WriteString(replaceLine)
-		sb.WriteString(replaceCursorStr)
-		if !e.replaceFocus {
+		if e.replaceFocus {
+			sb.WriteString("Replace: ")
+			sb.WriteString(inputWithCursor(e.replaceQuery, e.replaceCursor))
+		} else {
+			sb.WriteString(replaceLine)
 			sb.WriteString(" ") // Space where cursor would be
 		}
 		sb.WriteString(strings.Repeat(" ", availSpace))
@@ -4783,14 +6039,13 @@ func (e *Editor) View() string {
 	// Prompt bar if active
 	if e.mode == ModePrompt {
 		promptContent := e.promptText + e.promptInput
-		cursor := "▂" // Lower quarter block cursor
 		padding := e.width - len(promptContent) - 1
 		if padding < 0 {
 			padding = 0
 		}
 		sb.WriteString(barColor)
-		sb.WriteString(promptContent)
-		sb.WriteString(cursor)
+		sb.WriteString(e.promptText)
+		sb.WriteString(inputWithCursor(e.promptInput, e.promptCursor))
 		sb.WriteString(strings.Repeat(" ", padding))
 		sb.WriteString("\033[0m\n")
 	}
@@ -4798,10 +6053,25 @@ func (e *Editor) View() string {
 	// Status bar
 	e.statusbar.SetPosition(e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
 	e.statusbar.SetFilename(e.activeDoc().filename)
-	e.statusbar.SetModified(e.activeDoc().modified)
+	e.statusbar.SetDisplayName(e.bufferName(e.activeDoc()))
+	e.statusbar.SetDocState(e.docState())
+	e.statusbar.SetReadOnly(e.activeDoc().readOnly || e.activeDoc().hexView || e.activeDoc().follow)
 	e.statusbar.SetTotalLines(e.activeDoc().buffer.LineCount())
 	e.statusbar.SetCounts(e.activeDoc().buffer.WordCount(), e.activeDoc().buffer.RuneCount())
+	words, chars, lines, _ := e.selectionCounts()
+	e.statusbar.SetSelectionCounts(words, chars, lines)
+	e.statusbar.SetLineLength(e.cursorLineLength())
 	e.statusbar.SetBufferInfo(e.activeIdx, len(e.documents))
+	switch {
+	case e.chordPrefix != "":
+		e.statusbar.SetModeIndicator(config.FormatKeyForDisplay(e.chordPrefix) + " ...")
+	case e.viEnabled():
+		e.statusbar.SetModeIndicator(e.viModeIndicator())
+	case e.emacsEnabled():
+		e.statusbar.SetModeIndicator(e.emacsModeIndicator())
+	default:
+		e.statusbar.SetModeIndicator("")
+	}
 	// Set encoding display
 	docEnc := e.activeDoc().encoding
 	if docEnc != nil {
@@ -4812,7 +6082,10 @@ func (e *Editor) View() string {
 	sb.WriteString(e.statusbar.View())
 
 	// Append Kitty graphics minimap if enabled (rendered as overlay with cursor positioning)
-	if e.minimapRenderer.IsEnabled() {
+	if e.minimapRenderer.IsEnabled() && !e.minimapImageVisible() {
+		// Delete the image while a dialog is open or the editor is quitting
+		sb.WriteString(e.minimapRenderer.ClearImage())
+	} else if e.minimapRenderer.IsEnabled() {
 		// Calculate minimap position
 		// X offset: width - scrollbar (if enabled) - minimap width
 		xOffset := e.width - ui.MinimapWidth()
@@ -4821,7 +6094,24 @@ func (e *Editor) View() string {
 		}
 		// Y offset: 1 for menu bar (viewport starts at row 2, which is index 1)
 		yOffset := 1
-		kittySeq := e.minimapRenderer.GetKittySequence(ui.MinimapWidth(), e.viewport.Height(), xOffset, yOffset, renderState)
+		kittySeq := e.minimapSeq
+		if !e.unfocused || kittySeq == "" {
+			// While the terminal is unfocused the last image stands
+			kittySeq = e.minimapRenderer.GetKittySequence(ui.MinimapWidth(), e.viewport.Height(), xOffset, yOffset, renderState)
+			e.minimapSeq = kittySeq
+		}
+		if ui.ImageInTextCells(e.minimapRenderer) {
+			// Rewriting a line erases the image under it, and Bubbletea
+			// only rewrites lines that changed, so the line carrying the image
+			// must change whenever any other line did
+			if frame := sb.String(); frame != e.sixelFrame {
+				e.sixelFrame = frame
+				e.sixelFlip = !e.sixelFlip
+			}
+			if e.sixelFlip {
+				kittySeq += "\033[m"
+			}
+		}
 		sb.WriteString(kittySeq)
 	}
 
@@ -4837,6 +6127,7 @@ func (e *Editor) SetFilename(filename string) {
 	}
 	e.activeDoc().filename = absPath
 	e.activeDoc().highlighter.SetFile(absPath) // Update syntax highlighter
+	e.applyFileSettings()
 }
 
 // SetConfigError sets the config error state and shows the error dialog
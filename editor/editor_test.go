@@ -0,0 +1,732 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cornish/textivus-editor/config"
+	enc "github.com/cornish/textivus-editor/encoding"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCheckUnsavedReminder(t *testing.T) {
+	e := New()
+	e.config.Editor.UnsavedReminder = 5
+	doc := e.activeDoc()
+	doc.modified = true
+
+	start := time.Now()
+	e.checkUnsavedReminder(start)
+	if doc.dirtySince != start {
+		t.Fatalf("dirtySince not recorded on first check")
+	}
+
+	e.checkUnsavedReminder(start.Add(2 * time.Minute))
+	if !doc.remindedAt.IsZero() {
+		t.Errorf("reminded before the interval elapsed")
+	}
+
+	e.checkUnsavedReminder(start.Add(6 * time.Minute))
+	if doc.remindedAt.IsZero() {
+		t.Errorf("no reminder after the interval elapsed")
+	}
+
+	doc.modified = false
+	e.checkUnsavedReminder(start.Add(7 * time.Minute))
+	if !doc.dirtySince.IsZero() || !doc.remindedAt.IsZero() {
+		t.Errorf("reminder state not cleared after save")
+	}
+}
+
+func TestReminderAutosaveNeverAsks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.config.Editor.UnsavedReminder = 5
+	e.config.Editor.ReminderAutosave = true
+	e.config.Editor.StripSoftHyphens = true
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("hy\u00ADphen")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.filename = filepath.Join(t.TempDir(), "notes.txt")
+	doc.modified = true
+
+	start := time.Now()
+	e.checkUnsavedReminder(start)
+	e.checkUnsavedReminder(start.Add(6 * time.Minute))
+	if e.mode != ModeNormal {
+		t.Fatalf("auto-save opened a dialog (mode %v) while the user may be typing", e.mode)
+	}
+	if !doc.modified || doc.autosaved {
+		t.Errorf("a skipped auto-save should leave the buffer modified")
+	}
+	if _, err := os.Stat(doc.filename); err == nil {
+		t.Errorf("the skipped auto-save wrote the file")
+	}
+}
+
+func TestKeyChord(t *testing.T) {
+	e := New()
+	e.keybindings.SetBinding("select_all", config.KeyBinding{Primary: "ctrl+k ctrl+a"})
+	buf := NewBufferFromString("hello\nworld")
+	doc := e.activeDoc()
+	doc.buffer = buf
+	doc.cursor = NewCursor(buf)
+
+	// ctrl+k starts the chord instead of cutting the line
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if e.chordPrefix != "ctrl+k" {
+		t.Fatalf("chordPrefix = %q, want %q", e.chordPrefix, "ctrl+k")
+	}
+	if got := doc.buffer.String(); got != "hello\nworld" {
+		t.Errorf("chord prefix modified buffer: %q", got)
+	}
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if e.chordPrefix != "" {
+		t.Errorf("chord still pending after second key")
+	}
+	if !doc.selection.Active || doc.selection.GetText(doc.buffer) != "hello\nworld" {
+		t.Errorf("ctrl+k ctrl+a did not select all")
+	}
+}
+
+func TestKeyChordTimeout(t *testing.T) {
+	e := New()
+	e.keybindings.SetBinding("select_all", config.KeyBinding{Primary: "ctrl+k ctrl+a"})
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlK})
+	e.Update(chordTimeoutMsg{seq: e.chordSeq - 1})
+	if e.chordPrefix == "" {
+		t.Fatalf("stale timeout cancelled the pending chord")
+	}
+	e.Update(chordTimeoutMsg{seq: e.chordSeq})
+	if e.chordPrefix != "" {
+		t.Errorf("timeout did not cancel the pending chord")
+	}
+}
+
+func TestConfirmCloseDefaultsToCancel(t *testing.T) {
+	e := New()
+	e.newFile()
+	e.activeDoc().modified = true
+	if got := e.bufferCount(); got != 2 {
+		t.Fatalf("bufferCount = %d, want 2", got)
+	}
+
+	e.closeFile()
+	if e.mode != ModeConfirm {
+		t.Fatalf("closing a modified buffer should open a confirm dialog")
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModeNormal || e.bufferCount() != 2 {
+		t.Errorf("Enter should cancel: mode = %v, buffers = %d", e.mode, e.bufferCount())
+	}
+
+	e.closeFile()
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if e.bufferCount() != 1 {
+		t.Errorf("Discard hotkey should close the buffer, buffers = %d", e.bufferCount())
+	}
+}
+
+func TestConfirmQuit(t *testing.T) {
+	e := New()
+	e.activeDoc().modified = true
+
+	if cmd := e.quitEditor(); cmd != nil {
+		t.Fatalf("quit with unsaved changes should ask first")
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyLeft})
+	_, cmd := e.handleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatalf("choosing Quit should return a quit command")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("choosing Quit returned %T, want tea.QuitMsg", cmd())
+	}
+}
+
+func TestReopenClosedBuffer(t *testing.T) {
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome) // Opening files updates the recent lists
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	os.WriteFile(first, []byte("one\ntwo\nthree"), 0644)
+	os.WriteFile(second, []byte("alpha"), 0644)
+
+	e := New()
+	if err := e.LoadFile(first); err != nil {
+		t.Fatal(err)
+	}
+	e.activeDoc().cursor.SetByteOffset(5)
+	if err := e.LoadFile(second); err != nil {
+		t.Fatal(err)
+	}
+	e.doCloseFile() // second
+	e.doCloseFile() // first, leaving an empty buffer
+
+	e.reopenClosed()
+	if got := e.activeDoc().filename; got != first {
+		t.Fatalf("reopened %q, want most recently closed %q", got, first)
+	}
+	if got := e.activeDoc().cursor.ByteOffset(); got != 5 {
+		t.Errorf("cursor offset = %d, want 5", got)
+	}
+
+	e.reopenClosed()
+	if got := e.activeDoc().filename; got != second {
+		t.Errorf("second reopen gave %q, want %q", got, second)
+	}
+	if e.bufferCount() != 2 {
+		t.Errorf("bufferCount = %d, want 2", e.bufferCount())
+	}
+
+	e.reopenClosed()
+	if e.bufferCount() != 2 {
+		t.Errorf("empty history should not open anything, buffers = %d", e.bufferCount())
+	}
+}
+
+func TestLoadFileMarksReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only files")
+	}
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome) // Opening files updates the recent lists
+
+	path := filepath.Join(t.TempDir(), "locked.txt")
+	os.WriteFile(path, []byte("text"), 0444)
+
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !e.activeDoc().readOnly {
+		t.Errorf("file without write permission should load read-only")
+	}
+}
+
+func TestReadOnlyEditWarnsOnce(t *testing.T) {
+	e := New()
+	e.activeDoc().readOnly = true
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if e.activeDoc().roWarned {
+		t.Fatalf("moving the cursor should not warn")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if !e.activeDoc().roWarned {
+		t.Errorf("first edit of a read-only buffer should warn")
+	}
+}
+
+func TestThemeHotReload(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	themesDir := filepath.Join(configHome, "textivus", "themes")
+	os.MkdirAll(themesDir, 0755)
+	themePath := filepath.Join(themesDir, "mine.toml")
+	os.WriteFile(themePath, []byte("[ui]\nstatus_bg = \"#111111\"\n"), 0644)
+
+	cfg := config.DefaultConfig()
+	cfg.Theme.Name = "mine"
+	e := NewWithConfig(cfg)
+	if got := e.styles.Theme.UI.StatusBg; got != "#111111" {
+		t.Fatalf("StatusBg = %q, want the theme file's color", got)
+	}
+
+	e.checkThemeFile()
+	if e.statusbar.View() != NewWithConfig(cfg).statusbar.View() {
+		t.Fatalf("unchanged theme file should not reload")
+	}
+
+	os.WriteFile(themePath, []byte("[ui]\nstatus_bg = \"#222222\"\n"), 0644)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(themePath, later, later)
+	e.checkThemeFile()
+	if got := e.styles.Theme.UI.StatusBg; got != "#222222" {
+		t.Errorf("StatusBg after edit = %q, want #222222", got)
+	}
+
+	os.WriteFile(themePath, []byte("[ui\nbroken"), 0644)
+	later = later.Add(time.Second)
+	os.Chtimes(themePath, later, later)
+	e.checkThemeFile()
+	if got := e.styles.Theme.UI.StatusBg; got != "#222222" {
+		t.Errorf("a broken theme file should keep the current colors, got %q", got)
+	}
+}
+
+func TestMoveBuffer(t *testing.T) {
+	e := New()
+	e.newFile()
+	e.newFile()
+	for i, doc := range e.documents {
+		doc.filename = []string{"a", "b", "c"}[i]
+	}
+	names := func() string {
+		var s string
+		for _, doc := range e.documents {
+			s += doc.filename
+		}
+		return s
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftLeft, Alt: true})
+	if names() != "acb" || e.activeDoc().filename != "c" {
+		t.Errorf("after move left: order %q, active %q", names(), e.activeDoc().filename)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftLeft, Alt: true})
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftLeft, Alt: true}) // Already first
+	if names() != "cab" || e.activeIdx != 0 {
+		t.Errorf("after moving to front: order %q, index %d", names(), e.activeIdx)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftRight, Alt: true})
+	if names() != "acb" || e.activeIdx != 1 {
+		t.Errorf("after move right: order %q, index %d", names(), e.activeIdx)
+	}
+}
+
+func TestAborted(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Opening files updates the recent lists
+	dir := t.TempDir()
+	start := func() *Editor {
+		e := New()
+		openBuffers(t, e, dir, "COMMIT_EDITMSG")
+		e.MarkCommandLineFile()
+		openBuffers(t, e, dir, "other.txt")
+		return e
+	}
+	edit := func(e *Editor, i int) {
+		e.documents[i].buffer.Replace(0, 0, "edited ")
+		e.documents[i].modified = true
+	}
+	quitDiscarding := func(e *Editor) {
+		e.quitEditor()
+		for e.mode == ModeConfirm {
+			e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		}
+	}
+
+	// Quitting with nothing to discard isn't an abort
+	e := start()
+	quitDiscarding(e)
+	if e.Aborted() {
+		t.Errorf("quitting an unchanged file should not abort")
+	}
+
+	// Saving another buffer doesn't save the command-line file
+	e = start()
+	edit(e, 0)
+	edit(e, 1)
+	e.doSave()
+	quitDiscarding(e)
+	if !e.Aborted() {
+		t.Errorf("discarding changes to the command-line file should abort")
+	}
+
+	// Nor does an auto-save of it
+	e = start()
+	edit(e, 0)
+	e.switchToBuffer(0)
+	e.quietSave()
+	edit(e, 0)
+	quitDiscarding(e)
+	if !e.Aborted() {
+		t.Errorf("an auto-save should not count as saving the file")
+	}
+
+	// Closing its buffer discards too
+	e = start()
+	edit(e, 0)
+	e.closeBuffers([]*Document{e.documents[0]}, nil)
+	if !e.Aborted() {
+		t.Errorf("closing the changed command-line file should abort")
+	}
+
+	// Saving it is not an abort, even if later changes are discarded
+	e = start()
+	edit(e, 0)
+	e.switchToBuffer(0)
+	e.doSave()
+	edit(e, 0)
+	quitDiscarding(e)
+	if e.Aborted() {
+		t.Errorf("a saved command-line file should not abort")
+	}
+}
+
+func TestLoadFileThroughSymlinkReusesBuffer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.txt")
+	os.WriteFile(target, []byte("text"), 0644)
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks unavailable:", err)
+	}
+	hard := filepath.Join(dir, "hard.txt")
+	if err := os.Link(target, hard); err != nil {
+		t.Skip("hard links unavailable:", err)
+	}
+
+	e := New()
+	for _, path := range []string{target, link, hard} {
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(e.documents) != 1 {
+		t.Errorf("got %d buffers, want the symlink and hard link to switch to the open file", len(e.documents))
+	}
+}
+
+func TestFormatTitle(t *testing.T) {
+	if got := formatTitle(config.DefaultTitleFormat, "", false); got != "textivus - [Untitled]" {
+		t.Errorf("untitled title = %q", got)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if got := formatTitle("{basename}{modified} — {dir}", path, true); got != "notes.txt* — "+dir {
+		t.Errorf("custom title = %q", got)
+	}
+	if got := formatTitle("{basename}", "a\x07b.txt", false); got != "ab.txt" {
+		t.Errorf("control characters kept: %q", got)
+	}
+}
+
+func TestTitleFollowsModified(t *testing.T) {
+	e := New()
+	e.config.Editor.TitleFormat = "{basename}{modified}"
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if e.getTitle() != "[Untitled]*" {
+		t.Errorf("title after typing = %q, want the modified marker", e.getTitle())
+	}
+}
+
+func TestSaveKeepsUnicodeBOM(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data []byte
+		text string
+	}{
+		{"bom.txt", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, "hi"},
+		{"nobom.txt", []byte{0, 'h', 0, 'i', 0, '!', 0, '?'}, "hi!?"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		os.WriteFile(path, tt.data, 0644)
+		e := New()
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+		if got := e.activeDoc().buffer.String(); got != tt.text {
+			t.Errorf("%s loaded as %q, want %q", tt.name, got, tt.text)
+		}
+		if !e.doSave() {
+			t.Fatalf("%s: save failed", tt.name)
+		}
+		if got, _ := os.ReadFile(path); string(got) != string(tt.data) {
+			t.Errorf("%s saved as % x, want % x", tt.name, got, tt.data)
+		}
+	}
+}
+
+func TestToggleBOM(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "bom.txt")
+	os.WriteFile(path, []byte("\xEF\xBB\xBFhi"), 0644)
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if e.docEncoding().Name != "UTF-8 BOM" {
+		t.Fatalf("loaded as %s, want UTF-8 BOM", e.docEncoding().Name)
+	}
+
+	e.toggleBOM()
+	e.doSave()
+	if got, _ := os.ReadFile(path); string(got) != "hi" {
+		t.Errorf("after removing the BOM saved %q", got)
+	}
+	e.toggleBOM()
+	e.doSave()
+	if got, _ := os.ReadFile(path); string(got) != "\xEF\xBB\xBFhi" {
+		t.Errorf("after adding the BOM saved %q", got)
+	}
+
+	e.activeDoc().encoding = enc.GetEncodingByID("iso-8859-1")
+	e.toggleBOM()
+	if e.docEncoding().ID != "iso-8859-1" {
+		t.Errorf("Latin-1 has no BOM to toggle, got %s", e.docEncoding().ID)
+	}
+}
+
+func TestLossySave(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "latin1.txt")
+	tests := []struct {
+		key  string
+		want []byte
+	}{
+		{"s", []byte("caf\xe9 ?5")},
+		{"k", []byte("caf\xe9 5")},
+		{"c", nil},
+	}
+	for _, tt := range tests {
+		os.Remove(path)
+		e := New()
+		e.activeDoc().filename = path
+		e.activeDoc().encoding = enc.GetEncodingByID("iso-8859-1")
+		e.activeDoc().buffer.Replace(0, 0, "café €5")
+
+		if e.doSave() {
+			t.Fatalf("save with an unrepresentable character should ask first")
+		}
+		if e.mode != ModeConfirm || !strings.Contains(e.confirm.Message, "Line 1, col 6: € (U+20AC)") {
+			t.Fatalf("dialog should list the lost character, got %q", e.confirm.Message)
+		}
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		got, err := os.ReadFile(path)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("cancel should not write the file")
+			}
+			continue
+		}
+		if string(got) != string(tt.want) {
+			t.Errorf("%s: saved % x, want % x", tt.key, got, tt.want)
+		}
+		if e.lossySave != lossyAsk || e.activeDoc().modified {
+			t.Errorf("%s: the save should finish and reset the answer", tt.key)
+		}
+	}
+}
+
+func TestMouseWheelScroll(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 40, Height: 12})
+	e.activeDoc().buffer.Replace(0, 0, strings.Repeat(strings.Repeat("x", 60)+"\n", 50))
+	wheel := func(button tea.MouseButton, shift bool) {
+		e.Update(tea.MouseMsg{X: 5, Y: 5, Button: button, Action: tea.MouseActionPress, Shift: shift})
+	}
+
+	wheel(tea.MouseButtonWheelDown, false)
+	if got := e.viewport.ScrollY(); got != 3 {
+		t.Errorf("after wheel down ScrollY = %d, want 3", got)
+	}
+	e.config.Editor.ScrollLines = 5
+	wheel(tea.MouseButtonWheelUp, false)
+	if got := e.viewport.ScrollY(); got != 0 {
+		t.Errorf("after wheel up ScrollY = %d, want 0", got)
+	}
+
+	wheel(tea.MouseButtonWheelRight, false)
+	wheel(tea.MouseButtonWheelDown, true)
+	if got := e.viewport.ScrollX(); got != 2*wheelScrollColumns {
+		t.Errorf("after scrolling right ScrollX = %d, want %d", got, 2*wheelScrollColumns)
+	}
+	for range 20 {
+		wheel(tea.MouseButtonWheelRight, false)
+	}
+	if got, want := e.viewport.ScrollX(), 61-e.viewport.TextWidth(); got != want {
+		t.Errorf("ScrollX stopped at %d, want %d", got, want)
+	}
+	wheel(tea.MouseButtonWheelUp, true)
+	wheel(tea.MouseButtonWheelLeft, false)
+	if got, want := e.viewport.ScrollX(), 61-e.viewport.TextWidth()-2*wheelScrollColumns; got != want {
+		t.Errorf("after scrolling left ScrollX = %d, want %d", got, want)
+	}
+
+	// The cursor at the start of the line is out of view: say how to get back
+	e.statusbar.SetWidth(120)
+	if view := e.statusbar.View(); !strings.Contains(view, "Cursor out of view at col 1 (Ctrl+l scrolls back)") {
+		t.Errorf("status bar = %q, want the cursor column hint", view)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if got := e.viewport.ScrollX(); got != 0 {
+		t.Errorf("after Ctrl+L ScrollX = %d, want 0", got)
+	}
+}
+
+func TestHalfPageAndCenter(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 40, Height: 24})
+	e.activeDoc().buffer.Replace(0, 0, strings.Repeat("line\n", 100))
+	half := e.viewport.Height() / 2
+
+	// Half a page down moves the cursor and the view together
+	e.Update(tea.KeyMsg{Type: tea.KeyPgDown, Alt: true})
+	if line := e.activeDoc().cursor.Line(); line != half {
+		t.Errorf("after Alt+PgDn cursor on line %d, want %d", line, half)
+	}
+	if got := e.viewport.ScrollY(); got != half {
+		t.Errorf("after Alt+PgDn ScrollY = %d, want %d", got, half)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyPgUp, Alt: true})
+	if line, y := e.activeDoc().cursor.Line(), e.viewport.ScrollY(); line != 0 || y != 0 {
+		t.Errorf("after Alt+PgUp cursor line %d, ScrollY %d, want 0, 0", line, y)
+	}
+
+	// Ctrl+L puts the cursor line in the middle of the view
+	e.activeDoc().cursor.SetPosition(60, 0)
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if got, want := e.viewport.ScrollY(), 60-e.viewport.Height()/2; got != want {
+		t.Errorf("after Ctrl+L ScrollY = %d, want %d", got, want)
+	}
+}
+
+func TestVerticalMovesKeepGoalColumn(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 40, Height: 24})
+	e.activeDoc().buffer.Replace(0, 0, "abcdefgh\nab\nabcdefgh\n"+strings.Repeat("0123456789\n", 60))
+	e.activeDoc().cursor.SetPosition(0, 6)
+
+	// Passing through a short line returns to the starting column
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if col := e.activeDoc().cursor.Col(); col != 2 {
+		t.Errorf("on the short line col = %d, want 2", col)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if col := e.activeDoc().cursor.Col(); col != 6 {
+		t.Errorf("past the short line col = %d, want 6", col)
+	}
+
+	// A horizontal move sets a new goal
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if col := e.activeDoc().cursor.Col(); col != 1 {
+		t.Errorf("after Left then Down col = %d, want 1", col)
+	}
+
+	// Page Down scrolls a page and keeps the column
+	e.activeDoc().cursor.SetPosition(0, 6)
+	e.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+	page := e.viewport.Height() - 1
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != page || col != 6 {
+		t.Errorf("after PgDn cursor at %d:%d, want %d:6", line, col, page)
+	}
+	if got := e.viewport.ScrollY(); got != page {
+		t.Errorf("after PgDn ScrollY = %d, want %d", got, page)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 0 || col != 6 {
+		t.Errorf("after PgUp cursor at %d:%d, want 0:6", line, col)
+	}
+}
+
+func TestDesiredColumnAcrossWrappedRows(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 40, Height: 24})
+	e.viewport.SetWordWrap(true)
+	width := e.viewport.TextWidth()
+	// Line 1 wraps, and its second row is shorter than the goal column
+	e.activeDoc().buffer.Replace(0, 0, strings.Repeat("a", 30)+"\n"+strings.Repeat("b", width+3)+"\n"+strings.Repeat("c", 30))
+	e.activeDoc().cursor.SetPosition(0, 20)
+
+	for range 3 {
+		e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 2 || col != 20 {
+		t.Errorf("after three Downs cursor at %d:%d, want 2:20", line, col)
+	}
+
+	// Editing drops the goal
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if col := e.activeDoc().cursor.Col(); col != width+3 {
+		t.Fatalf("on the short row col = %d, want %d", col, width+3)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 2 || col != 2 {
+		t.Errorf("after an edit Down went to %d:%d, want 2:2", line, col)
+	}
+}
+
+func TestLineNumberWidthAdapts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 60, Height: 12})
+	e.viewport.ShowLineNumbers(true)
+	e.setupCompositorColumns()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if got := e.viewport.TextWidth(); got != 60-4-e.viewport.ScrollbarWidth() {
+		t.Fatalf("a short file should have a 4-column gutter, TextWidth = %d", got)
+	}
+
+	// Going past 9999 lines widens the gutter for the text, the rendering and clicks
+	e.activeDoc().buffer.Replace(0, 1, strings.TrimSuffix(strings.Repeat("abc\n", 12000), "\n"))
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlEnd})
+	if got := e.viewport.TextWidth(); got != 60-6-e.viewport.ScrollbarWidth() {
+		t.Errorf("TextWidth = %d with 12000 lines, want a 6-column gutter", got)
+	}
+	if view := stripAnsi(e.View()); !strings.Contains(view, "12000 abc") {
+		t.Errorf("last line number should sit beside its text:\n%s", view)
+	}
+	e.Update(tea.MouseMsg{X: 7, Y: 10, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 11999 || col != 1 {
+		t.Errorf("click landed on %d:%d, want 11999:1", line, col)
+	}
+}
+
+func TestIndentUndoKeepsLinesSelected(t *testing.T) {
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("\t  a\n \tb\nc")
+	doc.cursor = NewCursor(doc.buffer)
+	e.config.Editor.TabsToSpaces = false
+
+	// Select from the middle of the first line into the second
+	doc.selection.Start(2)
+	doc.selection.Update(6)
+	e.dedentLines()
+	if got := doc.buffer.String(); got != "  a\n\tb\nc" {
+		t.Fatalf("dedent = %q, want one tab or space off each line", got)
+	}
+	if len(doc.undoStack.current.entry.Edits) != 2 {
+		t.Errorf("dedent should be one edit per line")
+	}
+
+	// An edit further down shouldn't throw undo off
+	doc.selection.Clear()
+	doc.cursor.SetByteOffset(doc.buffer.Length())
+	e.insertText("!")
+	e.undo()
+	e.undo()
+	if got := doc.buffer.String(); got != "\t  a\n \tb\nc" {
+		t.Errorf("undo = %q, want the original indentation back", got)
+	}
+	start, end := doc.selection.Normalize()
+	if !doc.selection.Active || start != 0 || end != len("\t  a\n \tb") {
+		t.Errorf("undo should select both lines from the start, got %d-%d (active %v)", start, end, doc.selection.Active)
+	}
+
+	e.redo()
+	if got := doc.buffer.String(); got != "  a\n\tb\nc" {
+		t.Errorf("redo = %q", got)
+	}
+	if start, end := doc.selection.Normalize(); start != 0 || end != len("  a\n\tb") {
+		t.Errorf("redo should select both lines from the start, got %d-%d", start, end)
+	}
+}
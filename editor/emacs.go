@@ -0,0 +1,221 @@
+package editor
+
+import (
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// emacsState holds the emacs emulation state (shared across buffers)
+type emacsState struct {
+	prefix    string // "ctrl+x" while waiting for the second key of a C-x sequence
+	mark      bool   // Mark is set: motion commands extend the selection
+	lastKill  bool   // Previous command was a kill (consecutive kills append)
+	lastYank  bool   // Previous command was a yank (enables yank-pop)
+	yankStart int    // Byte range of the last yanked text
+	yankEnd   int
+}
+
+// emacsEnabled returns true if the emacs keybinding profile is active
+func (e *Editor) emacsEnabled() bool {
+	return e.config != nil && e.config.Editor.KeybindingProfile == config.ProfileEmacs
+}
+
+// emacsModeIndicator returns the status bar label for a pending emacs prefix
+func (e *Editor) emacsModeIndicator() string {
+	if e.emacs.prefix == "ctrl+x" {
+		return "C-x-"
+	}
+	if e.emacs.mark {
+		return "Mark"
+	}
+	return ""
+}
+
+// handleEmacsKey translates emacs key sequences into editor operations.
+// Returns (true, cmd) if the key was consumed, (false, nil) to fall through
+// to the regular key handling.
+func (e *Editor) handleEmacsKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	keyStr := msg.String()
+	wasKill, wasYank := e.emacs.lastKill, e.emacs.lastYank
+	e.emacs.lastKill, e.emacs.lastYank = false, false
+
+	if e.emacs.prefix == "ctrl+x" {
+		e.emacs.prefix = ""
+		return true, e.emacsCtrlX(keyStr)
+	}
+
+	doc := e.activeDoc()
+	switch keyStr {
+	case "ctrl+x":
+		e.emacs.prefix = "ctrl+x"
+	case "ctrl+a":
+		e.emacsMove(func() { doc.cursor.MoveToLineStart() })
+	case "ctrl+e":
+		e.emacsMove(func() { doc.cursor.MoveToLineEnd() })
+	case "ctrl+f":
+		e.emacsMove(func() { doc.cursor.MoveRight() })
+	case "ctrl+b":
+		e.emacsMove(func() { doc.cursor.MoveLeft() })
+	case "ctrl+n":
+		e.emacsMove(func() { e.moveDownVisual() })
+	case "ctrl+p":
+		e.emacsMove(func() { e.moveUpVisual() })
+	case "alt+f":
+		e.emacsMove(func() { doc.cursor.MoveWordRight() })
+	case "alt+b":
+		e.emacsMove(func() { doc.cursor.MoveWordLeft() })
+	case "ctrl+@":
+		// C-SPC sets the mark
+		e.emacs.mark = true
+		doc.selection.Start(doc.cursor.ByteOffset())
+		e.statusbar.SetMessage("Mark set", "info")
+	case "ctrl+g":
+		e.emacs.mark = false
+		doc.selection.Clear()
+		e.statusbar.SetMessage("Quit", "info")
+	case "ctrl+d":
+		e.emacsClearMark()
+		e.delete()
+	case "ctrl+k":
+		e.emacsKillLine(wasKill)
+	case "alt+d":
+		start := doc.cursor.ByteOffset()
+		doc.cursor.MoveWordRight()
+		e.emacsKill(start, doc.cursor.ByteOffset(), wasKill)
+	case "ctrl+w":
+		if doc.selection.Active && !doc.selection.IsEmpty() {
+			start, end := doc.selection.Normalize()
+			e.emacsKill(start, end, false)
+		}
+		e.emacsClearMark()
+	case "alt+w":
+		if doc.selection.Active && !doc.selection.IsEmpty() {
+			e.copyText(doc.selection.GetText(doc.buffer), "Copied", nil)
+		}
+		e.emacsClearMark()
+	case "ctrl+y":
+		e.emacsYank(e.clipboard.Yank())
+	case "alt+y":
+		if !wasYank {
+			e.statusbar.SetMessage("Previous command was not a yank", "error")
+			break
+		}
+		// Replace the text just yanked with the next older kill
+		doc.selection.Start(e.emacs.yankStart)
+		doc.selection.Update(e.emacs.yankEnd)
+		e.deleteSelection()
+		e.emacsYank(e.clipboard.YankPop())
+	case "ctrl+_":
+		e.emacsClearMark()
+		e.undo()
+	case "ctrl+s":
+		e.mode = ModeFind
+		e.findQuery = ""
+		e.findActive = true
+		e.updateViewportSize()
+	default:
+		return false, nil
+	}
+
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	return true, nil
+}
+
+// emacsCtrlX handles the second key of a C-x sequence
+func (e *Editor) emacsCtrlX(keyStr string) tea.Cmd {
+	switch keyStr {
+	case "ctrl+s":
+		e.SaveFile()
+	case "ctrl+w":
+		e.showSaveAs()
+	case "ctrl+f":
+		e.openFile()
+	case "ctrl+c":
+		return e.quitEditor()
+	case "k":
+		e.closeFile()
+	case "b", "right":
+		if e.bufferCount() > 1 {
+			e.nextBuffer()
+		}
+	case "left":
+		if e.bufferCount() > 1 {
+			e.prevBuffer()
+		}
+	case "h":
+		e.selectAll()
+	case "u":
+		e.undo()
+	case "ctrl+g":
+		e.statusbar.SetMessage("Quit", "info")
+	default:
+		e.statusbar.SetMessage("C-x "+keyStr+" is undefined", "error")
+	}
+	return nil
+}
+
+// emacsMove runs a cursor motion, extending the region if the mark is set
+func (e *Editor) emacsMove(move func()) {
+	doc := e.activeDoc()
+	if e.emacs.mark && doc.selection.Active {
+		move()
+		doc.selection.Update(doc.cursor.ByteOffset())
+		return
+	}
+	e.emacs.mark = false
+	doc.selection.Clear()
+	move()
+}
+
+// emacsClearMark deactivates the mark and clears the region
+func (e *Editor) emacsClearMark() {
+	e.emacs.mark = false
+	e.activeDoc().selection.Clear()
+}
+
+// emacsKillLine kills from the cursor to the end of the line, or the line
+// break itself when the cursor is already at the end of the line
+func (e *Editor) emacsKillLine(appendKill bool) {
+	doc := e.activeDoc()
+	e.emacsClearMark()
+	start := doc.cursor.ByteOffset()
+	end := doc.buffer.LineEndOffset(doc.cursor.Line())
+	if start == end {
+		if end >= doc.buffer.Length() {
+			return
+		}
+		end++ // Kill the newline
+	}
+	e.emacsKill(start, end, appendKill)
+}
+
+// emacsKill deletes a range and puts it on the kill ring
+func (e *Editor) emacsKill(start, end int, appendKill bool) {
+	doc := e.activeDoc()
+	if start > end {
+		start, end = end, start
+	}
+	if start == end {
+		return
+	}
+	e.clipboard.Kill(doc.buffer.Substring(start, end), appendKill)
+	doc.selection.Start(start)
+	doc.selection.Update(end)
+	e.deleteSelection()
+	e.emacs.mark = false
+	e.emacs.lastKill = true
+}
+
+// emacsYank inserts text at the cursor and remembers its range for yank-pop
+func (e *Editor) emacsYank(text string) {
+	if text == "" {
+		return
+	}
+	doc := e.activeDoc()
+	e.emacsClearMark()
+	e.emacs.yankStart = doc.cursor.ByteOffset()
+	e.insertText(text)
+	e.emacs.yankEnd = doc.cursor.ByteOffset()
+	e.emacs.lastYank = true
+}
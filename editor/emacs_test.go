@@ -0,0 +1,38 @@
+package editor
+
+import (
+	"io"
+	"testing"
+
+	"github.com/cornish/textivus-editor/clipboard"
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEmacsKillLineAppendsAndYanks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Editor.KeybindingProfile = config.ProfileEmacs
+	e := NewWithConfig(cfg)
+	e.clipboard = clipboard.New(io.Discard)
+	buf := NewBufferFromString("one\ntwo\nthree")
+	e.activeDoc().buffer = buf
+	e.activeDoc().cursor = NewCursor(buf)
+
+	// C-k C-k kills "one" and its newline into a single kill ring entry
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlK})
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlK})
+	if got := buf.String(); got != "two\nthree" {
+		t.Fatalf("after C-k C-k, buffer = %q", got)
+	}
+	if got := e.clipboard.KillRing().Len(); got != 1 {
+		t.Errorf("consecutive kills should share one entry, ring has %d", got)
+	}
+
+	// C-e then C-y yanks it back at the end of the line
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlE})
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if got := buf.String(); got != "twoone\n\nthree" {
+		t.Errorf("after C-e C-y, buffer = %q", got)
+	}
+}
@@ -0,0 +1,108 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// emergencySuffix ends the names of the files buffers are written to when
+// the editor crashes
+const emergencySuffix = ".recovered"
+
+// recoverPanic is deferred by Update and View: on a panic it writes the
+// modified buffers to emergency files, then lets the panic go on so that
+// Bubbletea restores the terminal. EmergencyReport says where they went.
+func (e *Editor) recoverPanic() {
+	if r := recover(); r != nil {
+		e.logPanic(r)
+		e.EmergencySave()
+		panic(r)
+	}
+}
+
+// EmergencySave writes every modified buffer to an emergency file, as
+// UTF-8, and returns the files written. It only runs once, so the first
+// crash's files are kept. Each file goes beside the buffer's file as
+// name.recovered, or to the temporary directory for untitled buffers and
+// folders that can't be written to.
+func (e *Editor) EmergencySave() []string {
+	if e.emergencySaved {
+		return e.emergencyFiles
+	}
+	e.emergencySaved = true
+	for i, doc := range e.documents {
+		path, err := writeEmergency(doc)
+		switch {
+		case err != nil:
+			e.emergencyErrors = append(e.emergencyErrors, fmt.Sprintf("buffer %d: %v", i+1, err))
+		case path != "":
+			e.emergencyFiles = append(e.emergencyFiles, path)
+		}
+	}
+	return e.emergencyFiles
+}
+
+// EmergencyReport describes the emergency files written after a crash,
+// for printing once the terminal is restored, or "" if there were none
+func (e *Editor) EmergencyReport() string {
+	if len(e.emergencyFiles) == 0 && len(e.emergencyErrors) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	if len(e.emergencyFiles) > 0 {
+		sb.WriteString("textivus: unsaved changes were written to:\n")
+		for _, path := range e.emergencyFiles {
+			sb.WriteString("  " + path + "\n")
+		}
+	}
+	for _, msg := range e.emergencyErrors {
+		sb.WriteString("textivus: could not rescue " + msg + "\n")
+	}
+	return sb.String()
+}
+
+// writeEmergency writes a modified buffer's text to an emergency file and
+// returns its path, or "" for a buffer with nothing unsaved. A buffer the
+// panic left broken is reported as an error.
+func writeEmergency(doc *Document) (path string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if doc == nil || !doc.modified {
+		return "", nil
+	}
+	data := []byte(doc.buffer.String())
+
+	base := "untitled"
+	if doc.filename != "" {
+		base = filepath.Base(doc.filename)
+		if _, ok := archiveMember(doc.filename); !ok {
+			path = doc.filename + emergencySuffix
+			if f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600); err == nil {
+				return path, writeAndClose(f, data)
+			}
+			// A file from an earlier crash is kept: pick a new name beside it
+			if f, err := os.CreateTemp(filepath.Dir(doc.filename), base+".*"+emergencySuffix); err == nil {
+				return f.Name(), writeAndClose(f, data)
+			}
+		}
+	}
+	f, err := os.CreateTemp("", "textivus-"+base+".*"+emergencySuffix)
+	if err != nil {
+		return "", err
+	}
+	return f.Name(), writeAndClose(f, data)
+}
+
+// writeAndClose writes data to f, syncs and closes it
+func writeAndClose(f *os.File, data []byte) error {
+	if err := writeAndSync(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
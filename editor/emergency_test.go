@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmergencySave(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	openBuffers(t, e, root, "a.txt", "b.txt")
+	e.documents[0].buffer.Replace(0, 0, "edited ")
+	e.documents[0].modified = true
+	e.doNewFile()
+	e.activeDoc().buffer.Replace(0, 0, "scratch")
+	e.activeDoc().modified = true
+	if err := os.WriteFile(filepath.Join(root, "a.txt"+emergencySuffix), []byte("earlier crash"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A panic in Update or View writes the buffers out and goes on
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("the panic should go on to Bubbletea")
+			}
+		}()
+		defer e.recoverPanic()
+		panic("boom")
+	}()
+
+	files := e.EmergencySave()
+	if len(files) != 2 {
+		t.Fatalf("files = %v, want the two modified buffers", files)
+	}
+	if data, _ := os.ReadFile(files[0]); string(data) != "edited a.txt" || filepath.Dir(files[0]) != root {
+		t.Errorf("a.txt rescued to %s holding %q", files[0], data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.txt"+emergencySuffix)); string(data) != "earlier crash" {
+		t.Errorf("an earlier emergency file was overwritten")
+	}
+	if data, _ := os.ReadFile(files[1]); string(data) != "scratch" || !strings.HasPrefix(filepath.Base(files[1]), "textivus-untitled.") {
+		t.Errorf("untitled buffer rescued to %s holding %q", files[1], data)
+	}
+	report := e.EmergencyReport()
+	for _, path := range files {
+		if !strings.Contains(report, path) {
+			t.Errorf("report should name %s:\n%s", path, report)
+		}
+	}
+}
+
+func TestEmergencySaveBrokenBuffer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.activeDoc().modified = true
+	e.activeDoc().buffer = nil
+	if files := e.EmergencySave(); len(files) != 0 {
+		t.Errorf("files = %v", files)
+	}
+	if report := e.EmergencyReport(); !strings.Contains(report, "could not rescue buffer 1") {
+		t.Errorf("report = %q", report)
+	}
+}
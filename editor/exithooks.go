@@ -0,0 +1,41 @@
+package editor
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// AddExitHook registers fn to run when the editor quits. The escape sequences
+// it returns are written to the terminal before the alt screen is left, so
+// hooks can clean up state (such as Kitty images) that would otherwise linger.
+func (e *Editor) AddExitHook(fn func() string) {
+	e.exitHooks = append(e.exitHooks, fn)
+}
+
+// exitEscapes collects the escape sequences of all registered exit hooks
+func (e *Editor) exitEscapes() string {
+	var out string
+	for _, fn := range e.exitHooks {
+		out += fn()
+	}
+	return out
+}
+
+// quit marks the editor as quitting, so the final render writes the exit
+// hooks' escapes, and tells Bubbletea to exit
+func (e *Editor) quit() tea.Cmd {
+	e.noteArgClosed(e.argDoc)
+	e.quitting = true
+	return tea.Quit
+}
+
+// minimapImageVisible reports whether the Kitty minimap image may be drawn.
+// The image sits above the text layer, so it is deleted while a dialog is
+// open (it would cover the dialog) and once the editor is quitting.
+func (e *Editor) minimapImageVisible() bool {
+	if e.quitting {
+		return false
+	}
+	switch e.mode {
+	case ModeNormal, ModeMenu, ModeFind, ModeFindReplace, ModePrompt:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestExitHooks(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	e.AddExitHook(func() string { return "\033[cleanup]" })
+
+	if strings.Contains(e.View(), "\033[cleanup]") {
+		t.Fatalf("exit hook ran before quitting")
+	}
+
+	cmd := e.quitEditor()
+	if cmd == nil {
+		t.Fatalf("quit without changes should exit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("quit returned %T, want tea.QuitMsg", cmd())
+	}
+	// Every frame after quitting carries the cleanup, whichever gets flushed
+	for i := 0; i < 2; i++ {
+		if !strings.Contains(e.View(), "\033[cleanup]") {
+			t.Errorf("render %d after quit is missing the exit hook output", i+1)
+		}
+	}
+}
+
+func TestKittyMinimapHiddenUnderDialogs(t *testing.T) {
+	e := New()
+	e.minimapRenderer = ui.NewKittyMinimapRenderer(e.styles, true)
+	e.minimapRenderer.SetEnabled(true)
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	clearSeq := e.minimapRenderer.ClearImage()
+
+	if view := e.View(); strings.Contains(view, clearSeq) || !strings.Contains(view, "\033_Ga=T") {
+		t.Fatalf("minimap image should be drawn in normal mode")
+	}
+
+	e.mode = ModeHelp
+	if view := e.View(); !strings.Contains(view, clearSeq) || strings.Contains(view, "\033_Ga=T") {
+		t.Errorf("minimap image should be deleted while a dialog is open")
+	}
+
+	e.mode = ModeNormal
+	e.quit()
+	if view := e.View(); !strings.Contains(view, clearSeq) {
+		t.Errorf("minimap image should be deleted on quit")
+	}
+}
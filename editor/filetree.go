@@ -0,0 +1,350 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// treeNode is a file or directory in the file tree sidebar. Directory
+// contents are read when first expanded and re-read on every expand.
+type treeNode struct {
+	name     string
+	path     string
+	isDir    bool
+	depth    int
+	expanded bool
+	parent   *treeNode
+	children []*treeNode
+}
+
+// treeIcons returns the markers for expanded and collapsed directories
+func (e *Editor) treeIcons() (expanded, collapsed string) {
+	if e.box.Lock == "*" {
+		return "v", ">" // ASCII mode
+	}
+	return "▾", "▸"
+}
+
+// showFileTree opens the sidebar on the working directory
+func (e *Editor) showFileTree() {
+	root, err := os.Getwd()
+	if err != nil {
+		e.statusbar.SetMessage("File tree: "+err.Error(), "error")
+		return
+	}
+	e.treeRoot = &treeNode{name: filepath.Base(root), path: root, isDir: true, depth: -1}
+	e.expandTreeNode(e.treeRoot)
+	e.treeSelected = 0
+	e.treeScroll = 0
+	e.fileTree.SetEnabled(true)
+	e.viewport.SetSidebarWidth(ui.FileTreeWidth())
+	e.setupCompositorColumns()
+	e.menubar.SetItemLabel(ui.ActionFileTree, "[x] File Tree")
+}
+
+// hideFileTree closes the sidebar and gives the keys back to the text
+func (e *Editor) hideFileTree() {
+	e.fileTree.SetEnabled(false)
+	e.treeFocused = false
+	e.viewport.SetSidebarWidth(0)
+	e.setupCompositorColumns()
+	e.menubar.SetItemLabel(ui.ActionFileTree, "[ ] File Tree")
+}
+
+// toggleFileTree shows or hides the sidebar and remembers the choice
+func (e *Editor) toggleFileTree() {
+	if e.fileTree.IsEnabled() {
+		e.hideFileTree()
+		e.statusbar.SetMessage("File tree hidden", "info")
+	} else {
+		e.showFileTree()
+		if !e.fileTree.IsEnabled() {
+			return
+		}
+		e.treeFocused = true
+		e.revealActiveFile()
+		e.statusbar.SetMessage("File tree shown", "info")
+	}
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+	if e.config != nil {
+		e.config.Editor.FileTree = e.fileTree.IsEnabled()
+		e.saveConfig()
+	}
+}
+
+// focusFileTree moves the keys between the tree and the text, opening the
+// tree first if it is hidden
+func (e *Editor) focusFileTree() {
+	if !e.fileTree.IsEnabled() {
+		e.toggleFileTree()
+		return
+	}
+	e.treeFocused = !e.treeFocused
+	if e.treeFocused {
+		e.revealActiveFile()
+	}
+}
+
+// expandTreeNode reads a directory's entries, directories first, each in
+// name order. Dotfiles follow the file browser's hidden files setting.
+func (e *Editor) expandTreeNode(node *treeNode) {
+	entries, err := os.ReadDir(node.path)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot open: "+err.Error(), "error")
+		return
+	}
+	showHidden := e.config == nil || e.config.Editor.BrowserShowHidden
+	node.children = node.children[:0]
+	for _, entry := range entries {
+		if !showHidden && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		node.children = append(node.children, &treeNode{
+			name:   entry.Name(),
+			path:   filepath.Join(node.path, entry.Name()),
+			isDir:  entry.IsDir(),
+			depth:  node.depth + 1,
+			parent: node,
+		})
+	}
+	sort.SliceStable(node.children, func(i, j int) bool {
+		a, b := node.children[i], node.children[j]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+	node.expanded = true
+}
+
+// treeRows flattens the expanded part of the tree into the visible rows
+func (e *Editor) treeRows() []*treeNode {
+	var rows []*treeNode
+	var walk func(*treeNode)
+	walk = func(node *treeNode) {
+		for _, child := range node.children {
+			rows = append(rows, child)
+			if child.expanded {
+				walk(child)
+			}
+		}
+	}
+	if e.treeRoot != nil {
+		walk(e.treeRoot)
+	}
+	return rows
+}
+
+// treeRenderRows converts the visible rows for the renderer, and returns
+// the row of the file being edited (-1 if it is not shown)
+func (e *Editor) treeRenderRows() ([]ui.TreeRow, int) {
+	rows := e.treeRows()
+	active := -1
+	current := ""
+	if name := e.activeDoc().filename; name != "" {
+		current, _ = filepath.Abs(name)
+	}
+	out := make([]ui.TreeRow, len(rows))
+	for i, node := range rows {
+		out[i] = ui.TreeRow{Name: node.name, Depth: node.depth, IsDir: node.isDir, Expanded: node.expanded}
+		if node.path == current {
+			active = i
+		}
+	}
+	return out, active
+}
+
+// revealActiveFile expands the directories above the file being edited and
+// selects it, when it lies under the tree root
+func (e *Editor) revealActiveFile() {
+	name := e.activeDoc().filename
+	if e.treeRoot == nil || name == "" {
+		return
+	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return
+	}
+	rel, err := filepath.Rel(e.treeRoot.path, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return
+	}
+	node := e.treeRoot
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if node.isDir && !node.expanded {
+			e.expandTreeNode(node)
+		}
+		var next *treeNode
+		for _, child := range node.children {
+			if child.name == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return
+		}
+		node = next
+	}
+	for i, row := range e.treeRows() {
+		if row == node {
+			e.selectTreeRow(i)
+		}
+	}
+}
+
+// selectTreeRow selects a row and scrolls it into view
+func (e *Editor) selectTreeRow(row int) {
+	count := len(e.treeRows())
+	if row >= count {
+		row = count - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	e.treeSelected = row
+	height := e.viewport.Height()
+	if e.treeSelected < e.treeScroll {
+		e.treeScroll = e.treeSelected
+	}
+	if e.treeSelected >= e.treeScroll+height {
+		e.treeScroll = e.treeSelected - height + 1
+	}
+}
+
+// selectedTreeNode returns the node on the selected row, or nil
+func (e *Editor) selectedTreeNode() *treeNode {
+	rows := e.treeRows()
+	if e.treeSelected < 0 || e.treeSelected >= len(rows) {
+		return nil
+	}
+	return rows[e.treeSelected]
+}
+
+// activateTreeNode toggles a directory or opens a file, moving the keys to it
+func (e *Editor) activateTreeNode(node *treeNode) {
+	if node == nil {
+		return
+	}
+	if node.isDir {
+		if node.expanded {
+			node.expanded = false
+		} else {
+			e.expandTreeNode(node)
+		}
+		return
+	}
+	if err := e.LoadFile(node.path); err != nil {
+		e.statusbar.SetMessage("Open failed: "+err.Error(), "error")
+		return
+	}
+	e.treeFocused = false
+	e.reportOpened(node.path)
+}
+
+// handleTreeKey handles keys while the file tree has focus. Ctrl, Alt and
+// function keys are left to the regular bindings; typed letters jump to the
+// next entry starting with them instead of editing the text.
+func (e *Editor) handleTreeKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	node := e.selectedTreeNode()
+	switch msg.Type {
+	case tea.KeyUp:
+		e.selectTreeRow(e.treeSelected - 1)
+	case tea.KeyDown:
+		e.selectTreeRow(e.treeSelected + 1)
+	case tea.KeyHome:
+		e.selectTreeRow(0)
+	case tea.KeyEnd:
+		e.selectTreeRow(len(e.treeRows()) - 1)
+	case tea.KeyPgUp:
+		e.selectTreeRow(e.treeSelected - e.viewport.Height())
+	case tea.KeyPgDown:
+		e.selectTreeRow(e.treeSelected + e.viewport.Height())
+	case tea.KeyLeft:
+		// Collapse, or go up to the parent directory
+		if node != nil && node.isDir && node.expanded {
+			node.expanded = false
+		} else if node != nil && node.parent != e.treeRoot {
+			for i, row := range e.treeRows() {
+				if row == node.parent {
+					e.selectTreeRow(i)
+				}
+			}
+		}
+	case tea.KeyRight:
+		// Expand, or step into an expanded directory
+		if node != nil && node.isDir {
+			if !node.expanded {
+				e.expandTreeNode(node)
+			} else if len(node.children) > 0 {
+				e.selectTreeRow(e.treeSelected + 1)
+			}
+		}
+	case tea.KeyEnter, tea.KeySpace:
+		e.activateTreeNode(node)
+	case tea.KeyEsc, tea.KeyTab:
+		e.treeFocused = false
+	case tea.KeyRunes:
+		if !msg.Alt && len(msg.Runes) == 1 {
+			e.treeJumpTo(msg.Runes[0])
+		} else if msg.Alt {
+			return false, nil
+		}
+	case tea.KeyBackspace, tea.KeyDelete, tea.KeyShiftTab:
+		// Consumed so they don't edit the text behind the tree
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// treeJumpTo selects the next row after the selected one whose name starts
+// with r, wrapping around to the top
+func (e *Editor) treeJumpTo(r rune) {
+	rows := e.treeRows()
+	r = unicode.ToLower(r)
+	for i := 1; i <= len(rows); i++ {
+		idx := (e.treeSelected + i) % len(rows)
+		name := []rune(rows[idx].name)
+		if len(name) > 0 && unicode.ToLower(name[0]) == r {
+			e.selectTreeRow(idx)
+			return
+		}
+	}
+}
+
+// handleTreeMouse selects and activates rows clicked in the tree, and
+// scrolls it with the wheel. Returns false for events outside the tree.
+func (e *Editor) handleTreeMouse(msg tea.MouseMsg, y int) bool {
+	if !e.fileTree.IsEnabled() || msg.X >= ui.FileTreeWidth() || y < 0 || y >= e.viewport.Height() {
+		return false
+	}
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return false // Releases and drags belong to the text selection
+		}
+		e.treeFocused = true
+		row := e.treeScroll + y
+		if row < len(e.treeRows()) {
+			e.treeSelected = row
+			e.activateTreeNode(e.selectedTreeNode())
+		}
+	case tea.MouseButtonWheelUp:
+		if e.treeScroll > 0 {
+			e.treeScroll--
+		}
+	case tea.MouseButtonWheelDown:
+		if e.treeScroll < len(e.treeRows())-e.viewport.Height() {
+			e.treeScroll++
+		}
+	}
+	return true
+}
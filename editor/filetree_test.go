@@ -0,0 +1,79 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeNames lists the names on the visible file tree rows
+func treeNames(e *Editor) []string {
+	var names []string
+	for _, node := range e.treeRows() {
+		names = append(names, node.name)
+	}
+	return names
+}
+
+func TestFileTree(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	t.Chdir(root)
+	for _, name := range []string{"main.go", "editor/editor.go", "README.md"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlB})
+	if !e.fileTree.IsEnabled() || !e.treeFocused || !e.config.Editor.FileTree {
+		t.Fatalf("Ctrl+B should show and focus the tree")
+	}
+	if got := treeNames(e); len(got) != 3 || got[0] != "editor" || got[1] != "main.go" {
+		t.Fatalf("rows = %v, want directories first, then files by name", got)
+	}
+	if view := e.View(); !strings.Contains(view, "main.go") {
+		t.Errorf("the tree column should be drawn")
+	}
+
+	// Right expands the directory, Down and Enter open the file inside it
+	e.Update(tea.KeyMsg{Type: tea.KeyRight})
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.activeDoc().buffer.String() != "editor/editor.go" || e.treeFocused {
+		t.Fatalf("Enter should open editor/editor.go and return the keys to the text")
+	}
+
+	// Typing goes to the text again, and F6 brings the keys back to the tree
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	e.Update(tea.KeyMsg{Type: tea.KeyF6})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if e.activeDoc().buffer.String() != "xeditor/editor.go" {
+		t.Errorf("buffer = %q, want letters typed in the tree kept out of the text", e.activeDoc().buffer.String())
+	}
+	if node := e.selectedTreeNode(); node == nil || node.name != "main.go" {
+		t.Errorf("typing m in the tree should select main.go")
+	}
+
+	// Left collapses back to the parent and then the directory itself
+	e.treeSelected = 1
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := treeNames(e); len(got) != 3 {
+		t.Errorf("rows = %v, want editor/ collapsed", got)
+	}
+}
+
+func TestFileTreeShiftsClicks(t *testing.T) {
+	e := New()
+	e.viewport.SetSidebarWidth(26)
+	if _, col := e.viewport.PositionFromClick(30, 0); col != 4 {
+		t.Errorf("click column = %d, want it measured from the right of the tree", col)
+	}
+}
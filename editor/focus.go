@@ -0,0 +1,35 @@
+package editor
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// pausedChecks records the periodic checks that stopped while the terminal
+// was unfocused
+type pausedChecks struct {
+	fileCheck, reminder, theme bool
+}
+
+// focusLost pauses periodic work until the terminal has focus again.
+// Followed files keep updating, since they are often watched from another
+// window.
+func (e *Editor) focusLost() {
+	e.unfocused = true
+	e.autosaveOnFocusLoss()
+}
+
+// focusGained resumes the periodic checks that paused while the terminal
+// was unfocused, running each right away
+func (e *Editor) focusGained() tea.Cmd {
+	e.unfocused = false
+	var cmds []tea.Cmd
+	if e.paused.fileCheck {
+		cmds = append(cmds, func() tea.Msg { return fileCheckMsg{} })
+	}
+	if e.paused.reminder {
+		cmds = append(cmds, func() tea.Msg { return reminderCheckMsg{} })
+	}
+	if e.paused.theme {
+		cmds = append(cmds, func() tea.Msg { return themeCheckMsg{} })
+	}
+	e.paused = pausedChecks{}
+	return tea.Batch(cmds...)
+}
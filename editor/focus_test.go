@@ -0,0 +1,53 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFocusPausesChecks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// While unfocused a check stops its ticker and does no work
+	e.Update(tea.BlurMsg{})
+	writeLater(t, path, "new")
+	if _, cmd := e.Update(fileCheckMsg{}); cmd != nil {
+		t.Errorf("the file check should not reschedule while unfocused")
+	}
+	if _, cmd := e.Update(themeCheckMsg{}); cmd != nil {
+		t.Errorf("the theme check should not reschedule while unfocused")
+	}
+	if got := e.activeDoc().buffer.String(); got != "old" {
+		t.Errorf("buffer reloaded while unfocused: %q", got)
+	}
+
+	// Focus runs the paused checks right away
+	_, cmd := e.Update(tea.FocusMsg{})
+	if cmd == nil {
+		t.Fatal("focus should restart the paused checks")
+	}
+	msgs := cmd().(tea.BatchMsg)
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 checks restarted, got %d", len(msgs))
+	}
+	for _, c := range msgs {
+		e.Update(c())
+	}
+	if got := e.activeDoc().buffer.String(); got != "new" {
+		t.Errorf("the file check should run on focus, buffer = %q", got)
+	}
+	if _, cmd := e.Update(tea.FocusMsg{}); cmd != nil {
+		t.Errorf("nothing should restart twice")
+	}
+}
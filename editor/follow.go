@@ -0,0 +1,193 @@
+package editor
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// followInterval is how often followed files are checked for new text
+const followInterval = time.Second
+
+// followSegment is the status bar segment marking a followed buffer
+const followSegment = "follow"
+
+// followTickMsg checks the followed files for new text
+type followTickMsg struct {
+	seq int
+}
+
+// followTickCmd schedules the next check of the followed files
+func (e *Editor) followTickCmd() tea.Cmd {
+	seq := e.followSeq
+	return tea.Tick(followInterval, func(time.Time) tea.Msg {
+		return followTickMsg{seq: seq}
+	})
+}
+
+// registerFollowSegment marks the status bar while the active buffer follows its file
+func (e *Editor) registerFollowSegment() {
+	e.statusbar.RegisterSegment(ui.StatusSegment{Name: followSegment, Priority: 3, Render: func() string {
+		if !e.activeDoc().follow {
+			return ""
+		}
+		return "FOLLOW"
+	}})
+}
+
+// toggleFollow starts or stops following the active file: text written to
+// the end of it is added to the buffer as it arrives, like tail -f
+func (e *Editor) toggleFollow() tea.Cmd {
+	doc := e.activeDoc()
+	if doc.follow {
+		doc.follow = false
+		e.updateMenuState()
+		e.statusbar.SetMessage("Stopped following "+e.bufferName(doc), "info")
+		return nil
+	}
+	switch {
+	case doc.filename == "":
+		e.statusbar.SetMessage("No file to follow", "error")
+		return nil
+	case doc.hexView:
+		e.statusbar.SetMessage("Hex view can't follow its file", "error")
+		return nil
+	case doc.modified:
+		e.statusbar.SetMessage("Save or revert changes before following the file", "error")
+		return nil
+	case wideEncoding(e.docEncoding()):
+		e.statusbar.SetMessage("Can't follow "+e.docEncoding().Name+" files", "error")
+		return nil
+	}
+
+	// Start from the file as it is now, at its end
+	e.doReopenWithEncoding(e.docEncoding())
+	info, err := os.Stat(doc.filename)
+	if err != nil || doc.hexView {
+		return nil // Reopening has said what went wrong
+	}
+	doc.follow = true
+	doc.followOffset = info.Size()
+	e.followToEnd(doc)
+	e.updateMenuState()
+	e.statusbar.SetMessage("Following "+e.bufferName(doc)+" (read-only) - Options > Follow File to stop", "info")
+	e.followSeq++
+	return e.followTickCmd()
+}
+
+// followToEnd puts the cursor on the last line and scrolls to it
+func (e *Editor) followToEnd(doc *Document) {
+	doc.cursor.SetByteOffset(doc.buffer.Length())
+	doc.selection.Clear()
+	if doc == e.activeDoc() {
+		e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	}
+}
+
+// checkFollowed adds the text written to each followed file since the last
+// check and schedules the next one while any buffer follows its file
+func (e *Editor) checkFollowed(msg followTickMsg) tea.Cmd {
+	if msg.seq != e.followSeq {
+		return nil // A newer chain of checks has taken over
+	}
+	following := false
+	for _, doc := range e.documents {
+		if !doc.follow {
+			continue
+		}
+		if doc.hexView {
+			doc.follow = false // Switching to hex view stops following
+			continue
+		}
+		following = true
+		e.followFile(doc)
+	}
+	e.updateMenuState()
+	if !following {
+		return nil
+	}
+	return e.followTickCmd()
+}
+
+// followFile appends the text written to doc's file since followOffset.
+// A file that got shorter was truncated or replaced (log rotation), so it
+// is read again from the start. The view stays at the end unless the
+// cursor was moved off the last line.
+func (e *Editor) followFile(doc *Document) {
+	info, err := os.Stat(doc.filename)
+	if err != nil || info.Size() == doc.followOffset {
+		return
+	}
+	pinned := doc.cursor.Line() == doc.buffer.LineCount()-1
+	if info.Size() < doc.followOffset {
+		doc.buffer = NewBufferFromString("")
+		doc.cursor = NewCursor(doc.buffer)
+		doc.undoStack.Clear()
+		doc.followOffset = 0
+		pinned = true
+		if doc == e.activeDoc() {
+			e.statusbar.SetMessage("File was truncated; reading it from the start", "info")
+		}
+	}
+
+	f, err := os.Open(doc.filename)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.NewSectionReader(f, doc.followOffset, info.Size()-doc.followOffset))
+	if err != nil {
+		return
+	}
+	data = followChunk(data, doc.encoding)
+	if len(data) == 0 {
+		return
+	}
+	text, err := enc.DecodeToUTF8(data, doc.encoding)
+	if err != nil {
+		return
+	}
+	doc.followOffset += int64(len(data))
+
+	offset := doc.cursor.ByteOffset()
+	doc.buffer.Replace(doc.buffer.Length(), doc.buffer.Length(), string(text))
+	doc.cursor.SetByteOffset(offset)
+	doc.modTime = info.ModTime()
+	doc.changedOnDisk = false
+	doc.markSaved()
+	if pinned {
+		e.followToEnd(doc)
+	}
+}
+
+// followChunk returns the part of newly written data that can be decoded
+// now. A character cut off at the end waits for the rest of it; for
+// encodings other than UTF-8 that means waiting for the end of the line.
+func followChunk(data []byte, encoding *enc.Encoding) []byte {
+	if encoding == nil || strings.HasPrefix(encoding.ID, "utf-8") {
+		for cut := len(data); cut > 0 && cut > len(data)-utf8.UTFMax; cut-- {
+			if utf8.RuneStart(data[cut-1]) {
+				if !utf8.FullRune(data[cut-1:]) {
+					return data[:cut-1]
+				}
+				break
+			}
+		}
+		return data
+	}
+	return data[:bytes.LastIndexByte(data, '\n')+1]
+}
+
+// wideEncoding reports whether an encoding spends two or four bytes on
+// every character, so that text can't be split at a newline byte
+func wideEncoding(encoding *enc.Encoding) bool {
+	return strings.HasPrefix(encoding.ID, "utf-16") || strings.HasPrefix(encoding.ID, "utf-32")
+}
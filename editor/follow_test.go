@@ -0,0 +1,110 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+func TestFollowChunk(t *testing.T) {
+	utf8 := enc.GetEncodingByID("utf-8")
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"abc\n", "abc\n"},
+		{"abc", "abc"},
+		{"caf\xc3", "caf"},
+		{"caf\xc3\xa9", "caf\xc3\xa9"},
+		{"\xe2\x82", ""},
+	}
+	for _, tt := range tests {
+		if got := string(followChunk([]byte(tt.data), utf8)); got != tt.want {
+			t.Errorf("followChunk(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+	latin1 := enc.GetEncodingByID("iso-8859-1")
+	if got := string(followChunk([]byte("one\ntw"), latin1)); got != "one\n" {
+		t.Errorf("followChunk(latin-1) = %q, want whole lines", got)
+	}
+}
+
+func TestFollowFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	doc := e.activeDoc()
+	if e.toggleFollow() == nil || !doc.follow {
+		t.Fatal("toggleFollow should start following")
+	}
+
+	// Text written to the file is added, and the cursor stays at the end
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("three\nfo")
+	f.Close()
+	if e.checkFollowed(followTickMsg{seq: e.followSeq}) == nil {
+		t.Fatal("checks should continue while following")
+	}
+	if got := doc.buffer.String(); got != "one\ntwo\nthree\nfo" {
+		t.Errorf("buffer = %q", got)
+	}
+	if doc.modified || doc.cursor.ByteOffset() != doc.buffer.Length() {
+		t.Errorf("followed buffer should be unmodified with the cursor at the end")
+	}
+	if e.checkFollowed(followTickMsg{seq: e.followSeq - 1}) != nil {
+		t.Errorf("stale ticks should be dropped")
+	}
+
+	// Edits are refused
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if doc.modified || doc.buffer.String() != "one\ntwo\nthree\nfo" {
+		t.Errorf("followed buffer should be read-only, got %q", doc.buffer.String())
+	}
+	if doc.undoStack.CanUndo() {
+		t.Errorf("a refused edit should leave nothing to undo")
+	}
+
+	// A truncated file is read again from the start
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e.checkFollowed(followTickMsg{seq: e.followSeq})
+	if got := doc.buffer.String(); got != "new\n" {
+		t.Errorf("after truncation buffer = %q", got)
+	}
+
+	// Toggling again stops following
+	e.toggleFollow()
+	if doc.follow || e.checkFollowed(followTickMsg{seq: e.followSeq}) != nil {
+		t.Errorf("toggleFollow should stop following")
+	}
+}
+
+func TestFollowRefusesModified(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if e.toggleFollow() != nil || e.activeDoc().follow {
+		t.Errorf("a modified buffer should not start following")
+	}
+}
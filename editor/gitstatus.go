@@ -0,0 +1,70 @@
+package editor
+
+import (
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/git"
+)
+
+// gitSegment is the status bar segment showing the active file's branch
+const gitSegment = "git"
+
+// gitStatusMsg carries a repository status read in the background
+type gitStatusMsg struct {
+	path   string
+	status git.Status
+	ranGit bool // git itself was run, so Dirty is known
+}
+
+// gitRefreshCmd returns a command that reads the repository status of the
+// active file, or nil if the file is unchanged since the last read and
+// nothing (a save, the periodic file check) has marked the status stale
+func (e *Editor) gitRefreshCmd() tea.Cmd {
+	path := e.activeDoc().filename
+	if e.quitting || (path == e.gitPath && !e.gitStale) {
+		return nil
+	}
+	e.gitPath, e.gitStale = path, false
+	if path == "" {
+		e.statusbar.SetSegmentText(gitSegment, "")
+		return nil
+	}
+	return readGitStatus(path, e.config != nil && e.config.IsTrustedDir(filepath.Dir(path)))
+}
+
+// readGitStatus returns a command reading the status of path's repository.
+// git itself is only run with runGit, as git status can run commands the
+// repository's config names; otherwise just the branch is read.
+func readGitStatus(path string, runGit bool) tea.Cmd {
+	return func() tea.Msg {
+		return gitStatusMsg{path: path, status: git.StatusOf(path, runGit), ranGit: runGit}
+	}
+}
+
+// applyGitStatus shows a status read by gitRefreshCmd, unless the user has
+// since switched to a different file. The first time a repository that
+// isn't trusted is seen, the user is asked whether git may be run in it.
+func (e *Editor) applyGitStatus(msg gitStatusMsg) tea.Cmd {
+	if msg.path != e.activeDoc().filename {
+		return nil
+	}
+	text := msg.status.String()
+	if text != "" {
+		text = "⎇ " + text
+	}
+	e.statusbar.SetSegmentText(gitSegment, text)
+
+	root := msg.status.Root
+	if root == "" || msg.ranGit || e.gitAsked[root] || e.mode != ModeNormal {
+		return nil
+	}
+	if e.gitAsked == nil {
+		e.gitAsked = make(map[string]bool)
+	}
+	e.gitAsked[root] = true
+	return e.requireTrust(root, "git status", func() tea.Cmd {
+		return readGitStatus(msg.path, true)
+	})
+}
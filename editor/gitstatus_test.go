@@ -0,0 +1,90 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitStatusSegment(t *testing.T) {
+	// Opening files updates the recent lists, and trusting a repository
+	// saves the config in the background, after t.TempDir would be removed
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inRepo := filepath.Join(dir, "a.txt")
+	outside := filepath.Join(t.TempDir(), "b.txt")
+	for _, path := range []string{inRepo, outside} {
+		if err := os.WriteFile(path, []byte("text\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	e.statusbar.SetWidth(120)
+	if err := e.LoadFile(inRepo); err != nil {
+		t.Fatal(err)
+	}
+	cmd := e.gitRefreshCmd()
+	if cmd == nil {
+		t.Fatalf("opening a file should read its git status")
+	}
+	if e.gitRefreshCmd() != nil {
+		t.Errorf("status re-read without a save or buffer switch")
+	}
+	msg := cmd().(gitStatusMsg)
+	if msg.ranGit {
+		t.Errorf("git was run in a repository that isn't trusted")
+	}
+	e.applyGitStatus(msg)
+	if view := e.statusbar.View(); !strings.Contains(view, "⎇ main") {
+		t.Errorf("status bar = %q, want branch main", view)
+	}
+
+	// The untrusted repository is asked about once; trusting it lets git run
+	if e.mode != ModeConfirm {
+		t.Fatalf("an untrusted repository should ask before running git")
+	}
+	run := e.confirm.OnChoose(0) // Trust
+	e.mode = ModeNormal
+	if run == nil || !run().(gitStatusMsg).ranGit || !e.config.IsTrustedDir(dir) {
+		t.Errorf("trusting the repository should run git status")
+	}
+	e.applyGitStatus(msg)
+	if e.mode != ModeNormal {
+		t.Errorf("the same repository was asked about twice")
+	}
+	e.gitStale = true
+	if cmd := e.gitRefreshCmd(); cmd == nil || !cmd().(gitStatusMsg).ranGit {
+		t.Errorf("a trusted repository should run git status")
+	}
+
+	// A result arriving after switching to another file is dropped
+	if err := e.LoadFile(outside); err != nil {
+		t.Fatal(err)
+	}
+	e.applyGitStatus(msg)
+	if cmd := e.gitRefreshCmd(); cmd != nil {
+		e.applyGitStatus(cmd().(gitStatusMsg))
+	}
+	if view := e.statusbar.View(); strings.Contains(view, "⎇") {
+		t.Errorf("status bar = %q, want no branch outside a repository", view)
+	}
+
+	e.gitStale = false
+	e.doSave()
+	if e.gitRefreshCmd() == nil {
+		t.Errorf("saving should re-read the git status")
+	}
+}
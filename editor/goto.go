@@ -0,0 +1,164 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// goToTarget resolves Go to Line input to a byte offset. msg describes the
+// jump for the status bar, or the problem when ok is false. Accepted forms are "42" (line), "42:7" (line and
+// character column), "#1234" (byte offset) and "#c1234" (character offset).
+// Lines and columns count from 1, offsets from 0; a leading ":" is ignored.
+// With clamp, targets outside the file land on its nearest position instead
+// of being refused, as vi and nano treat +line on the command line.
+func (e *Editor) goToTarget(input string, clamp bool) (offset int, msg string, ok bool) {
+	buf := e.activeDoc().buffer
+	input = strings.TrimPrefix(strings.TrimSpace(input), ":")
+
+	if rest, found := strings.CutPrefix(input, "#"); found {
+		chars := false
+		if rest, chars = strings.CutPrefix(rest, "c"); chars {
+			n, err := strconv.Atoi(rest)
+			if err != nil || n < 0 {
+				return 0, "Invalid character offset", false
+			}
+			content := buf.String()
+			if total := utf8.RuneCountInString(content); n > total && clamp {
+				n = total
+			} else if n > total {
+				return 0, fmt.Sprintf("Offset %d exceeds file length (%s)", n, countUnits(total, "character")), false
+			}
+			offset, left := len(content), n
+			for i := range content {
+				if left == 0 {
+					offset = i
+					break
+				}
+				left--
+			}
+			return offset, fmt.Sprintf("Jumped to character %d", n), true
+		}
+
+		n, err := strconv.Atoi(rest)
+		if err != nil || n < 0 {
+			return 0, "Invalid byte offset", false
+		}
+		if n > buf.Length() && clamp {
+			n = buf.Length()
+		} else if n > buf.Length() {
+			return 0, fmt.Sprintf("Offset %d exceeds file size (%s)", n, countUnits(buf.Length(), "byte")), false
+		}
+		// Land on the start of the character containing the byte
+		for n > 0 && n < buf.Length() && !utf8.RuneStart(buf.ByteAt(n)) {
+			n--
+		}
+		return n, fmt.Sprintf("Jumped to byte %d", n), true
+	}
+
+	lineStr, colStr, hasCol := strings.Cut(input, ":")
+	lineNum, err := strconv.Atoi(lineStr)
+	if err != nil {
+		return 0, "Invalid line number", false
+	}
+	totalLines := buf.LineCount()
+	if clamp {
+		lineNum = min(max(lineNum, 1), totalLines)
+	}
+	if lineNum < 1 {
+		return 0, "Line number must be at least 1", false
+	}
+	if lineNum > totalLines {
+		return 0, fmt.Sprintf("Line %d exceeds file length (%s)", lineNum, countUnits(totalLines, "line")), false
+	}
+	start := buf.LineStartOffset(lineNum - 1)
+	if !hasCol {
+		return start, fmt.Sprintf("Jumped to line %d", lineNum), true
+	}
+
+	col, err := strconv.Atoi(colStr)
+	if err == nil && clamp {
+		col = max(col, 1)
+	}
+	if err != nil || col < 1 {
+		return 0, "Invalid column number", false
+	}
+	// Columns count characters; past the end of the line lands on its end
+	line := buf.Substring(start, buf.LineEndOffset(lineNum-1))
+	offset, left := start+len(line), col-1
+	for i := range line {
+		if left == 0 {
+			offset = start + i
+			break
+		}
+		left--
+	}
+	return offset, fmt.Sprintf("Jumped to line %d, column %d", lineNum, col), true
+}
+
+// countUnits formats n with unit, e.g. "1 line" or "3 lines"
+func countUnits(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// goToInput jumps to the position typed into the Go to Line prompt
+func (e *Editor) goToInput(input string) {
+	offset, msg, ok := e.goToTarget(input, false)
+	if !ok {
+		e.statusbar.SetMessage(msg, "error")
+		return
+	}
+	doc := e.activeDoc()
+	doc.cursor.SetByteOffset(offset)
+	doc.selection.Clear()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.statusbar.SetMessage(msg, "info")
+}
+
+// GoTo moves the cursor to a Go to Line target such as "42" or "42:7", for
+// the command line's +line argument. A target past the end lands on the last
+// line or column, so a new or short file still opens. The view scrolls to
+// the cursor once the terminal size is known.
+func (e *Editor) GoTo(target string) error {
+	offset, msg, ok := e.goToTarget(target, true)
+	if !ok {
+		return errors.New(msg)
+	}
+	doc := e.activeDoc()
+	doc.cursor.SetByteOffset(offset)
+	doc.selection.Clear()
+	e.revealCursor = true
+	return nil
+}
+
+// cursorInfo describes the character at the cursor, e.g.
+// "Byte 1234, char 1200: 'é' U+00E9, UTF-8 C3 A9"
+func (e *Editor) cursorInfo() string {
+	doc := e.activeDoc()
+	pos := doc.cursor.ByteOffset()
+	where := fmt.Sprintf("Byte %d, char %d", pos, utf8.RuneCountInString(doc.buffer.Substring(0, pos)))
+	if pos >= doc.buffer.Length() {
+		return where + ": end of file"
+	}
+
+	r, size := doc.buffer.RuneAt(pos)
+	if r == utf8.RuneError && size <= 1 {
+		return fmt.Sprintf("%s: invalid UTF-8 byte %02X", where, doc.buffer.ByteAt(pos))
+	}
+	raw := doc.buffer.Substring(pos, pos+size)
+	hex := make([]string, len(raw))
+	for i := 0; i < len(raw); i++ {
+		hex[i] = fmt.Sprintf("%02X", raw[i])
+	}
+	return fmt.Sprintf("%s: %s U+%04X, UTF-8 %s", where, strconv.QuoteRune(r), r, strings.Join(hex, " "))
+}
+
+// showCursorInfo shows the character at the cursor in the status bar
+func (e *Editor) showCursorInfo() {
+	e.statusbar.SetMessage(e.cursorInfo(), "info")
+}
@@ -0,0 +1,109 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGoToTarget(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "first\nnaïve café\nlast", 0)
+	tests := []struct {
+		input  string
+		offset int
+		ok     bool
+	}{
+		{"2", 6, true},
+		{":3", 19, true},
+		{"2:4", 10, true},  // Column counts characters, not bytes
+		{"2:6", 12, true},  // After the two-byte ï
+		{"2:99", 18, true}, // Past the end lands on the line end
+		{"4", 0, false},
+		{"0", 0, false},
+		{"2:0", 0, false},
+		{"#7", 7, true},
+		{"#9", 8, true}, // Inside ï snaps to its first byte
+		{"#100", 0, false},
+		{"#c10", 11, true},
+		{"#c21", 23, true},
+		{"#c22", 0, false},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		offset, msg, ok := e.goToTarget(tt.input, false)
+		if ok != tt.ok || ok && offset != tt.offset {
+			t.Errorf("goToTarget(%q) = %d, %q, %v, want %d, %v", tt.input, offset, msg, ok, tt.offset, tt.ok)
+		}
+	}
+}
+
+func TestGoToTargetClamped(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "first\nnaïve café\nlast", 0)
+	tests := []struct {
+		input  string
+		offset int
+	}{
+		{"50", 19},   // Last line
+		{"0", 0},     // First line
+		{"2:0", 6},   // First column
+		{"50:9", 23}, // End of the last line
+		{"#100", 23},
+		{"#c100", 23},
+	}
+	for _, tt := range tests {
+		if offset, msg, ok := e.goToTarget(tt.input, true); !ok || offset != tt.offset {
+			t.Errorf("goToTarget(%q, clamp) = %d, %q, %v, want %d", tt.input, offset, msg, ok, tt.offset)
+		}
+	}
+	if _, _, ok := e.goToTarget("abc", true); ok {
+		t.Errorf("clamping should still refuse a target that isn't a number")
+	}
+
+	one := newMarkupTestEditor("one.txt", "only", 0)
+	if _, msg, _ := one.goToTarget("5", false); msg != "Line 5 exceeds file length (1 line)" {
+		t.Errorf("msg = %q", msg)
+	}
+}
+
+func TestCursorInfo(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "a€\n", 1)
+	if got, want := e.cursorInfo(), "Byte 1, char 1: '€' U+20AC, UTF-8 E2 82 AC"; got != want {
+		t.Errorf("cursorInfo() = %q, want %q", got, want)
+	}
+	e.activeDoc().cursor.SetByteOffset(4)
+	if got, want := e.cursorInfo(), `Byte 4, char 2: '\n' U+000A, UTF-8 0A`; got != want {
+		t.Errorf("cursorInfo() = %q, want %q", got, want)
+	}
+	e.activeDoc().cursor.SetByteOffset(5)
+	if got, want := e.cursorInfo(), "Byte 5, char 3: end of file"; got != want {
+		t.Errorf("cursorInfo() = %q, want %q", got, want)
+	}
+}
+
+func TestGoToBeforeFirstRender(t *testing.T) {
+	var lines []string
+	for i := 0; i < 200; i++ {
+		lines = append(lines, "line")
+	}
+	e := newMarkupTestEditor("notes.txt", strings.Join(lines, "\n"), 0)
+	if err := e.GoTo("150:3"); err != nil {
+		t.Fatalf("GoTo: %v", err)
+	}
+	if err := e.GoTo("900"); err != nil || e.activeDoc().cursor.Line() != 199 {
+		t.Errorf("GoTo past the end should land on the last line: %v", err)
+	}
+	if err := e.GoTo("150:3"); err != nil {
+		t.Fatalf("GoTo: %v", err)
+	}
+	doc := e.activeDoc()
+	if doc.cursor.Line() != 149 || doc.cursor.Col() != 2 {
+		t.Fatalf("cursor at %d:%d, want 149:2", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	// The first size message scrolls the +line target into view
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	if top := e.viewport.ScrollY(); top > 149 || top+e.viewport.Height() <= 149 {
+		t.Errorf("viewport starts at line %d, cursor line 149 not visible", top)
+	}
+}
@@ -0,0 +1,108 @@
+package editor
+
+import (
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/ui"
+	"github.com/rivo/uniseg"
+)
+
+// firstGraphemeLen returns the byte length of the first grapheme cluster in s
+func firstGraphemeLen(s string) int {
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(s, -1)
+	return len(cluster)
+}
+
+// lastGraphemeLen returns the byte length of the last grapheme cluster in s
+func lastGraphemeLen(s string) int {
+	last := 0
+	state := -1
+	for len(s) > 0 {
+		var cluster string
+		cluster, s, _, state = uniseg.FirstGraphemeClusterInString(s, state)
+		last = len(cluster)
+	}
+	return last
+}
+
+// graphemeBefore returns the byte length of the grapheme cluster ending at
+// pos, or of the line break when pos starts a line
+func (b *Buffer) graphemeBefore(pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	_, col := b.PositionToLineCol(pos)
+	if col == 0 {
+		start := pos - 1
+		for start > 0 && !utf8.RuneStart(b.ByteAt(start)) {
+			start--
+		}
+		return pos - start
+	}
+	return lastGraphemeLen(b.Substring(pos-col, pos))
+}
+
+// graphemeAfter returns the byte length of the grapheme cluster starting at
+// pos, or of the line break when pos ends a line
+func (b *Buffer) graphemeAfter(pos int) int {
+	if pos >= b.Length() {
+		return 0
+	}
+	line, _ := b.PositionToLineCol(pos)
+	end := b.LineEndOffset(line)
+	if pos >= end {
+		_, size := b.RuneAt(pos)
+		return size
+	}
+	return firstGraphemeLen(b.Substring(pos, end))
+}
+
+// graphemeStart moves pos back to the start of the grapheme cluster it is in
+func (b *Buffer) graphemeStart(pos int) int {
+	line, col := b.PositionToLineCol(pos)
+	start := b.LineStartOffset(line)
+	text := b.Substring(start, b.LineEndOffset(line))
+	offset := 0
+	state := -1
+	for len(text) > 0 {
+		var cluster string
+		cluster, text, _, state = uniseg.FirstGraphemeClusterInString(text, state)
+		if offset+len(cluster) > col {
+			break
+		}
+		offset += len(cluster)
+	}
+	return start + offset
+}
+
+// runeColumn converts a byte column on line to the rune column the
+// renderer and viewport use
+func runeColumn(line string, col int) int {
+	return utf8.RuneCountInString(line[:min(max(col, 0), len(line))])
+}
+
+// lineRanges splits the byte range [start, end) of buf into the rune
+// column ranges the renderer draws on each line it touches
+func lineRanges(buf *Buffer, lines []string, start, end int, add func(line int, r ui.SelectionRange)) {
+	startLine, startCol := buf.PositionToLineCol(start)
+	endLine, endCol := buf.PositionToLineCol(end)
+	for line := startLine; line <= endLine && line < len(lines); line++ {
+		sr := ui.SelectionRange{Start: 0, End: -1}
+		if line == startLine {
+			sr.Start = runeColumn(lines[line], startCol)
+		}
+		if line == endLine {
+			sr.End = runeColumn(lines[line], endCol)
+		}
+		add(line, sr)
+	}
+}
+
+// cursorRuneCol returns the cursor's rune column on its line
+func (e *Editor) cursorRuneCol(lines []string) int {
+	doc := e.activeDoc()
+	if line := doc.cursor.Line(); line < len(lines) {
+		return runeColumn(lines[line], doc.cursor.Col())
+	}
+	return doc.cursor.Col()
+}
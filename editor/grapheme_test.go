@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// graphemeText has a decomposed é, a ZWJ emoji sequence and a flag
+const graphemeText = "e\u0301\U0001F469\u200D\U0001F4BB\U0001F1EC\U0001F1E7!"
+
+func TestCursorMovesByGrapheme(t *testing.T) {
+	buf := NewBufferFromString(graphemeText + "\nab")
+	c := NewCursor(buf)
+
+	var stops []int
+	for c.MoveRight() {
+		stops = append(stops, c.ByteOffset())
+	}
+	want := []int{3, 14, 22, 23, 24, 25, 26}
+	if len(stops) != len(want) {
+		t.Fatalf("right stops = %v, want %v", stops, want)
+	}
+	for i := range want {
+		if stops[i] != want[i] {
+			t.Fatalf("right stops = %v, want %v", stops, want)
+		}
+	}
+
+	for i := len(want) - 2; i >= 0; i-- {
+		c.MoveLeft()
+		if c.ByteOffset() != want[i] {
+			t.Fatalf("left stop = %d, want %d", c.ByteOffset(), want[i])
+		}
+	}
+	c.MoveLeft()
+	if c.ByteOffset() != 0 {
+		t.Errorf("left stop = %d, want 0", c.ByteOffset())
+	}
+}
+
+func TestCursorVerticalSnapsToGrapheme(t *testing.T) {
+	// Column 1 on the second line falls between the e and its accent
+	buf := NewBufferFromString("xy\ne\u0301z")
+	c := NewCursor(buf)
+	c.SetPosition(0, 1)
+	c.MoveDown()
+	if line, col := c.Line(), c.Col(); line != 1 || col != 0 {
+		t.Errorf("after MoveDown at (%d, %d), want (1, 0)", line, col)
+	}
+}
+
+func TestDeleteByGrapheme(t *testing.T) {
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer.Replace(0, 0, graphemeText)
+	doc.cursor.SetByteOffset(22) // After the flag
+
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got, want := doc.buffer.String(), "e\u0301\U0001F469\u200D\U0001F4BB!"; got != want {
+		t.Fatalf("after backspace %q, want %q", got, want)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got, want := doc.buffer.String(), "e\u0301!"; got != want {
+		t.Fatalf("after backspace %q, want %q", got, want)
+	}
+	doc.cursor.SetByteOffset(0)
+	e.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if got := doc.buffer.String(); got != "!" {
+		t.Fatalf("after delete %q, want %q", got, "!")
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	if got := doc.buffer.String(); got != graphemeText {
+		t.Errorf("after undo %q, want %q", got, graphemeText)
+	}
+}
+
+func TestRenderColumnsAfterMultibyte(t *testing.T) {
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer.Replace(0, 0, "éé")
+	doc.cursor.SetByteOffset(4)
+	if got := e.buildRenderState().CursorCol; got != 2 {
+		t.Errorf("CursorCol = %d, want 2", got)
+	}
+	e.View() // Highlighting the word under the cursor used byte columns as rune indexes
+}
@@ -0,0 +1,61 @@
+package editor
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// inGutter reports whether screen column x is on the line numbers
+func (e *Editor) inGutter(x int) bool {
+	col, ok := e.compositor.ColumnAt(x)
+	return ok && col.Renderer == e.lineNumRenderer
+}
+
+// gutterLine returns the line beside viewport row y, clamped to the document
+func (e *Editor) gutterLine(y int) int {
+	lines := e.activeDoc().buffer.Lines()
+	y = min(max(y, 0), e.viewport.Height()-1)
+	line, _ := e.viewport.PositionFromClickWrapped(lines, e.viewport.SidebarWidth()+e.viewport.LineNumberWidth(), y)
+	return min(max(line, 0), len(lines)-1)
+}
+
+// selectLineSpan selects whole lines from anchor to line, newlines included,
+// with the cursor at the end toward line
+func (e *Editor) selectLineSpan(anchor, line int) {
+	doc := e.activeDoc()
+	first, last := min(anchor, line), max(anchor, line)
+	start := doc.buffer.LineStartOffset(first)
+	end := doc.buffer.Length()
+	if last+1 < doc.buffer.LineCount() {
+		end = doc.buffer.LineStartOffset(last + 1)
+	}
+	if line < anchor {
+		start, end = end, start
+	}
+	doc.selection.Start(start)
+	doc.selection.Update(end)
+	doc.cursor.SetByteOffset(end)
+}
+
+// handleGutterPress selects the line whose number was clicked and starts a
+// line-wise drag. Returns false when the click isn't on a line number.
+func (e *Editor) handleGutterPress(msg tea.MouseMsg, y int) bool {
+	if !e.viewport.ShowLineNum() || y < 0 || y >= e.viewport.Height() || !e.inGutter(msg.X) {
+		return false
+	}
+	e.gutterAnchor = e.gutterLine(y)
+	e.gutterDrag = true
+	e.mouseDown = true
+	e.selectLineSpan(e.gutterAnchor, e.gutterAnchor)
+	return true
+}
+
+// dragGutterSelection extends the line-wise selection to the line under
+// the pointer, scrolling when it is dragged past the top or bottom
+func (e *Editor) dragGutterSelection(y int) {
+	lines := e.activeDoc().buffer.Lines()
+	switch {
+	case y < 0:
+		e.viewport.ScrollUp()
+	case y >= e.viewport.Height():
+		e.viewport.ScrollDownWrapped(lines)
+	}
+	e.selectLineSpan(e.gutterAnchor, e.gutterLine(y))
+}
@@ -0,0 +1,43 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGutterSelectsLines(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 60, Height: 12})
+	e.viewport.ShowLineNumbers(true)
+	e.setupCompositorColumns()
+	doc := e.activeDoc()
+	doc.buffer.Replace(0, 0, "zero\none\ntwo\nthree")
+	mouse := func(action tea.MouseAction, x, row int) {
+		e.Update(tea.MouseMsg{X: x, Y: row + 1, Button: tea.MouseButtonLeft, Action: action})
+	}
+
+	// Clicking a number selects its line, newline included
+	mouse(tea.MouseActionPress, 1, 1)
+	if got := doc.selection.GetText(doc.buffer); got != "one\n" {
+		t.Fatalf("click on line 2's number selected %q", got)
+	}
+
+	// Dragging extends by whole lines, in either direction
+	mouse(tea.MouseActionMotion, 2, 3)
+	if got := doc.selection.GetText(doc.buffer); got != "one\ntwo\nthree" || doc.cursor.ByteOffset() != doc.buffer.Length() {
+		t.Errorf("drag down selected %q", got)
+	}
+	mouse(tea.MouseActionMotion, 2, 0)
+	if got := doc.selection.GetText(doc.buffer); got != "zero\none\n" || doc.cursor.ByteOffset() != 0 {
+		t.Errorf("drag up selected %q, cursor %d", got, doc.cursor.ByteOffset())
+	}
+	mouse(tea.MouseActionRelease, 2, 0)
+
+	// Clicks in the text still place the cursor
+	mouse(tea.MouseActionPress, 4+2, 2)
+	if doc.selection.Active && !doc.selection.IsEmpty() || doc.cursor.Line() != 2 || doc.cursor.Col() != 2 {
+		t.Errorf("text click: cursor %d:%d", doc.cursor.Line(), doc.cursor.Col())
+	}
+}
@@ -0,0 +1,227 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// hazardClass groups characters that can't be seen, or look like others,
+// and so hide what the text really says
+type hazardClass int
+
+const (
+	hazardNone       hazardClass = iota
+	hazardZeroWidth              // Zero-width spaces and joiners, word joiner, soft hyphen, BOM
+	hazardBidi                   // Controls that reorder how text is displayed
+	hazardNBSP                   // Non-breaking spaces that look like ordinary ones
+	hazardDecomposed             // A letter and combining marks that NFC composes
+)
+
+// hazardSpan is a run of hazardous characters on a line, in rune columns
+type hazardSpan struct {
+	start, end int
+	class      hazardClass
+}
+
+// classifyHazard returns the hazard class of a single character
+func classifyHazard(r rune) hazardClass {
+	switch r {
+	case '\u200B', '\u200C', '\u200D', '\u2060', '\uFEFF', '\u00AD':
+		return hazardZeroWidth
+	case '\u200E', '\u200F', '\u061C',
+		'\u202A', '\u202B', '\u202C', '\u202D', '\u202E',
+		'\u2066', '\u2067', '\u2068', '\u2069':
+		return hazardBidi
+	case '\u00A0', '\u2007', '\u202F':
+		return hazardNBSP
+	}
+	return hazardNone
+}
+
+// isEmojiJoin reports whether a zero-width joiner at i joins two emoji,
+// as in family and profession emoji, where it belongs
+func isEmojiJoin(runes []rune, i int) bool {
+	emoji := func(r rune) bool { return r >= 0x1F000 || unicode.Is(unicode.So, r) }
+	return runes[i] == '\u200D' && i > 0 && i+1 < len(runes) && emoji(runes[i-1]) && emoji(runes[i+1])
+}
+
+// lineHazards finds the hazardous characters on a line
+func lineHazards(line string) []hazardSpan {
+	runes := []rune(line)
+	var spans []hazardSpan
+	for i := 0; i < len(runes); i++ {
+		if class := classifyHazard(runes[i]); class != hazardNone {
+			if !isEmojiJoin(runes, i) {
+				spans = append(spans, hazardSpan{i, i + 1, class})
+			}
+			continue
+		}
+		// A starter and the combining marks after it
+		end := i + 1
+		for end < len(runes) && unicode.Is(unicode.Mn, runes[end]) {
+			end++
+		}
+		if end > i+1 && !norm.NFC.IsNormalString(string(runes[i:end])) {
+			spans = append(spans, hazardSpan{i, end, hazardDecomposed})
+		}
+		i = end - 1
+	}
+	return spans
+}
+
+// hazardCounts tallies hazardous characters by class
+type hazardCounts [hazardDecomposed + 1]int
+
+// countHazards tallies the hazardous characters in s
+func countHazards(s string) hazardCounts {
+	var c hazardCounts
+	for _, line := range strings.Split(s, "\n") {
+		for _, span := range lineHazards(line) {
+			c[span.class]++
+		}
+	}
+	return c
+}
+
+// total returns the number of hazards found
+func (c hazardCounts) total() int {
+	n := 0
+	for _, count := range c {
+		n += count
+	}
+	return n
+}
+
+// String describes the counts, e.g. "1 bidi control, 2 non-breaking spaces"
+func (c hazardCounts) String() string {
+	var parts []string
+	add := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+one)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(c[hazardZeroWidth], "zero-width character", "zero-width characters")
+	add(c[hazardBidi], "bidi control", "bidi controls")
+	add(c[hazardNBSP], "non-breaking space", "non-breaking spaces")
+	add(c[hazardDecomposed], "decomposed (NFD) letter", "decomposed (NFD) letters")
+	return strings.Join(parts, ", ")
+}
+
+// cleanHazards strips zero-width characters and bidi controls, turns
+// non-breaking spaces into spaces, and normalizes to NFC
+func cleanHazards(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		spans := lineHazards(line)
+		if len(spans) == 0 {
+			continue
+		}
+		runes := []rune(line)
+		var sb strings.Builder
+		col := 0
+		for _, span := range spans {
+			sb.WriteString(string(runes[col:span.start]))
+			switch span.class {
+			case hazardNBSP:
+				sb.WriteByte(' ')
+			case hazardDecomposed:
+				sb.WriteString(string(runes[span.start:span.end]))
+			}
+			col = span.end
+		}
+		sb.WriteString(string(runes[col:]))
+		lines[i] = sb.String()
+	}
+	return norm.NFC.String(strings.Join(lines, "\n"))
+}
+
+// flagInvisiblesEnabled reports whether hazardous characters are marked
+func (e *Editor) flagInvisiblesEnabled() bool {
+	return e.config == nil || e.config.Editor.FlagInvisibles
+}
+
+// hazardMap marks the hazardous characters on the visible lines. Characters
+// with no width of their own mark the one after them as well, so they show.
+func (e *Editor) hazardMap(lines []string) map[int][]ui.SelectionRange {
+	if !e.flagInvisiblesEnabled() || e.activeDoc().hexView {
+		return nil
+	}
+	first, last := e.visibleLineRange(lines)
+	var hazards map[int][]ui.SelectionRange
+	for i := first; i < last; i++ {
+		for _, span := range lineHazards(lines[i]) {
+			end := span.end
+			if span.class == hazardZeroWidth || span.class == hazardBidi {
+				end++
+			}
+			if hazards == nil {
+				hazards = make(map[int][]ui.SelectionRange)
+			}
+			hazards[i] = append(hazards[i], ui.SelectionRange{Start: span.start, End: end})
+		}
+	}
+	return hazards
+}
+
+// hazardWarning returns a warning for opening content with invisible or
+// reordering characters in it, or "" when there are none
+func (e *Editor) hazardWarning(content string) string {
+	if !e.flagInvisiblesEnabled() {
+		return ""
+	}
+	c := countHazards(content)
+	c[hazardNBSP], c[hazardDecomposed] = 0, 0 // Common in ordinary prose
+	if c.total() == 0 {
+		return ""
+	}
+	return "Contains " + c.String() + " - Edit > Fix Invisible Characters"
+}
+
+// fixInvisibles reports the hazardous characters in the buffer and offers to
+// strip them and normalize the text to NFC, as a single undoable edit
+func (e *Editor) fixInvisibles() {
+	content := e.activeDoc().buffer.String()
+	counts := countHazards(content)
+	if counts.total() == 0 {
+		e.statusbar.SetMessage("No invisible or decomposed characters found", "info")
+		return
+	}
+	e.showConfirm(&ConfirmDialog{
+		Title:   "Invisible Characters",
+		Message: "Found " + counts.String() + ".\nStrip invisibles and normalize to NFC?",
+		Buttons: []ConfirmButton{
+			{Label: "Strip & Normalize", Hotkey: 's'},
+			{Label: "Normalize Only", Hotkey: 'n'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 0,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.transformBuffer(cleanHazards)
+			case 1:
+				e.transformBuffer(norm.NFC.String)
+			default:
+				e.statusbar.SetMessage("Cancelled", "info")
+				return nil
+			}
+			left := countHazards(e.activeDoc().buffer.String())
+			if left.total() == 0 {
+				e.statusbar.SetMessage("Fixed "+counts.String(), "info")
+			} else {
+				e.statusbar.SetMessage("Normalized; left "+left.String(), "info")
+			}
+			return nil
+		},
+	})
+}
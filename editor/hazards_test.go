@@ -0,0 +1,71 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLineHazards(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []hazardSpan
+	}{
+		{"plain", "hello world", nil},
+		{"zero-width space", "ab\u200Bc", []hazardSpan{{2, 3, hazardZeroWidth}}},
+		{"bidi override", "x = \u202Eevil", []hazardSpan{{4, 5, hazardBidi}}},
+		{"non-breaking space", "10\u00A0km", []hazardSpan{{2, 3, hazardNBSP}}},
+		{"decomposed", "cafe\u0301!", []hazardSpan{{3, 5, hazardDecomposed}}},
+		{"composed", "caf\u00E9", nil},
+		{"emoji joiner", "\U0001F469\u200D\U0001F4BB", nil},
+	}
+	for _, tt := range tests {
+		got := lineHazards(tt.line)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: span %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCleanHazards(t *testing.T) {
+	in := "a\u200Bb \u202Ec\nd\u00A0e cafe\u0301"
+	if got, want := cleanHazards(in), "ab c\nd e caf\u00E9"; got != want {
+		t.Errorf("cleanHazards = %q, want %q", got, want)
+	}
+	if got := countHazards(in).String(); got != "1 zero-width character, 1 bidi control, 1 non-breaking space, 1 decomposed (NFD) letter" {
+		t.Errorf("counts = %q", got)
+	}
+}
+
+func TestFixInvisibles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer.Replace(0, 0, "x\u200By = cafe\u0301")
+
+	e.fixInvisibles()
+	if e.mode != ModeConfirm {
+		t.Fatalf("fixing should ask first")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if got := doc.buffer.String(); got != "x\u200By = caf\u00E9" {
+		t.Errorf("Normalize Only gave %q", got)
+	}
+
+	e.fixInvisibles()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := doc.buffer.String(); got != "xy = caf\u00E9" {
+		t.Errorf("Strip & Normalize gave %q", got)
+	}
+	e.undo()
+	if got := doc.buffer.String(); got != "x\u200By = caf\u00E9" {
+		t.Errorf("undo should restore the stripped text, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// binaryWarning is shown when a file opens in hex view
+const binaryWarning = "Binary file: showing hex view (read-only) - Options > Hex View for text"
+
+// hexBytesPerLine is how many bytes each line of a hex view shows
+const hexBytesPerLine = 16
+
+// hexDump formats data like hexdump -C: the offset, the bytes in hex in two
+// groups of eight, then the bytes as ASCII with "." for the rest
+func hexDump(data []byte) string {
+	var sb strings.Builder
+	for offset := 0; offset < len(data); offset += hexBytesPerLine {
+		if offset > 0 {
+			sb.WriteByte('\n')
+		}
+		row := data[offset:min(offset+hexBytesPerLine, len(data))]
+		fmt.Fprintf(&sb, "%08x ", offset)
+		for i := range hexBytesPerLine {
+			if i%8 == 0 {
+				sb.WriteByte(' ')
+			}
+			if i < len(row) {
+				fmt.Fprintf(&sb, "%02x ", row[i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+		sb.WriteString(" |")
+		for _, b := range row {
+			if b >= 0x20 && b < 0x7f {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('|')
+	}
+	return sb.String()
+}
+
+// setHexView shows data in doc as a read-only hex dump
+func (e *Editor) setHexView(doc *Document, data []byte) {
+	doc.hexView = true
+	doc.hexData = data
+	doc.buffer = NewBufferFromString(hexDump(data))
+	doc.cursor = NewCursor(doc.buffer)
+	doc.selection.Clear()
+	doc.undoStack.Clear()
+	doc.multiSel = nil
+	doc.autoClosers = nil
+	doc.modified = false
+	doc.autosaved = false
+	doc.highlighter.SetFile("")
+	doc.markSaved()
+}
+
+// toggleHexView switches the active file between its text and a hex dump of
+// its bytes
+func (e *Editor) toggleHexView() {
+	doc := e.activeDoc()
+	if doc.hexView {
+		e.doReopenWithEncoding(e.docEncoding())
+		if !doc.hexView {
+			e.statusbar.SetMessage("Showing text as "+e.docEncoding().Name, "info")
+		}
+		return
+	}
+	if doc.filename == "" {
+		e.statusbar.SetMessage("No file to show in hex", "error")
+		return
+	}
+	if doc.modified {
+		e.statusbar.SetMessage("Save or revert changes before switching to hex view", "error")
+		return
+	}
+	data, err := os.ReadFile(doc.filename)
+	if err != nil {
+		e.statusbar.SetMessage("Hex view failed: "+err.Error(), "error")
+		return
+	}
+	e.setHexView(doc, data)
+	e.viewport.SetScrollY(0)
+	e.updateMenuState()
+	e.statusbar.SetMessage(fmt.Sprintf("Hex view: %d bytes (read-only)", len(data)), "info")
+}
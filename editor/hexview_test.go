@@ -0,0 +1,69 @@
+package editor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/clipboard"
+)
+
+func TestHexDump(t *testing.T) {
+	data := []byte("Hello, World!\x00\x01\x02\xffxyz")
+	want := "00000000  48 65 6c 6c 6f 2c 20 57  6f 72 6c 64 21 00 01 02  |Hello, World!...|\n" +
+		"00000010  ff 78 79 7a                                       |.xyz|"
+	if got := hexDump(data); got != want {
+		t.Errorf("hexDump =\n%s\nwant\n%s", got, want)
+	}
+	if got := hexDump(nil); got != "" {
+		t.Errorf("hexDump(nil) = %q", got)
+	}
+}
+
+func TestBinaryOpensInHexView(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := []byte("\x7fELF\x00\x00binary")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	doc := e.activeDoc()
+	if !doc.hexView || doc.buffer.String() != hexDump(data) {
+		t.Fatalf("binary file should open in hex view, got %q", doc.buffer.String())
+	}
+
+	// Typing doesn't change the dump, and saving is refused
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if doc.modified || doc.buffer.String() != hexDump(data) {
+		t.Errorf("hex view should be read-only")
+	}
+
+	// Cutting is refused before the text reaches the clipboard
+	e.clipboard = clipboard.New(&bytes.Buffer{})
+	e.clipboard.SetProvider(clipboard.ProviderInternal)
+	doc.selection.SelectAll(doc.buffer)
+	e.cut()
+	if text, _ := e.clipboard.Paste(); text != "" || doc.buffer.String() != hexDump(data) {
+		t.Errorf("cut in hex view copied %q", text)
+	}
+	if e.doSave() {
+		t.Errorf("saving a hex view should fail")
+	}
+
+	// Switching back shows the bytes as text
+	e.toggleHexView()
+	if doc.hexView || doc.buffer.String() != "\x7fELF\x00\x00binary" {
+		t.Errorf("toggling should show text, got %q", doc.buffer.String())
+	}
+	e.toggleHexView()
+	if !doc.hexView {
+		t.Errorf("toggling again should show hex")
+	}
+}
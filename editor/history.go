@@ -0,0 +1,115 @@
+package editor
+
+import "github.com/cornish/textivus-editor/config"
+
+// inputHistory is the recall list of one input bar, newest entry first.
+// Up steps back through older entries, Down returns toward the text that
+// was being typed before browsing started.
+type inputHistory struct {
+	entries []string
+	pos     int    // 0 = not browsing, n = showing entries[n-1]
+	draft   string // Text typed before browsing started
+}
+
+// add records s as the newest entry, moving it up if already present
+func (h *inputHistory) add(s string) {
+	h.reset()
+	if s == "" {
+		return
+	}
+	kept := make([]string, 0, len(h.entries)+1)
+	kept = append(kept, s)
+	for _, old := range h.entries {
+		if old != s {
+			kept = append(kept, old)
+		}
+	}
+	if len(kept) > config.MaxHistory {
+		kept = kept[:config.MaxHistory]
+	}
+	h.entries = kept
+}
+
+// prev returns the next older entry; current is saved as the draft when
+// browsing starts. ok is false at the oldest entry.
+func (h *inputHistory) prev(current string) (string, bool) {
+	if h.pos >= len(h.entries) {
+		return "", false
+	}
+	if h.pos == 0 {
+		h.draft = current
+	}
+	h.pos++
+	return h.entries[h.pos-1], true
+}
+
+// next returns the next newer entry, or the draft after the newest one.
+// ok is false when not browsing.
+func (h *inputHistory) next() (string, bool) {
+	if h.pos == 0 {
+		return "", false
+	}
+	h.pos--
+	if h.pos == 0 {
+		return h.draft, true
+	}
+	return h.entries[h.pos-1], true
+}
+
+// reset stops browsing, so the next Up starts from the newest entry
+func (h *inputHistory) reset() {
+	h.pos = 0
+	h.draft = ""
+}
+
+// loadHistory seeds the input histories from the config when
+// persist_history is on
+func (e *Editor) loadHistory() {
+	if e.config == nil || !e.config.Editor.PersistHistory {
+		return
+	}
+	e.findHistory.entries = append([]string(nil), e.config.FindHistory...)
+	e.replaceHistory.entries = append([]string(nil), e.config.ReplaceHistory...)
+	e.gotoHistory.entries = append([]string(nil), e.config.GotoHistory...)
+}
+
+// rememberSearch records the find query, and the replacement when replacing
+func (e *Editor) rememberSearch(replacing bool) {
+	e.findHistory.add(e.findQuery)
+	if replacing {
+		e.replaceHistory.add(e.replaceQuery)
+	}
+	e.persistHistory()
+}
+
+// rememberGoto records a go-to-line target
+func (e *Editor) rememberGoto(input string) {
+	e.gotoHistory.add(input)
+	e.persistHistory()
+}
+
+// persistHistory copies the histories to the config and saves it when
+// persist_history is on
+func (e *Editor) persistHistory() {
+	if e.config == nil || !e.config.Editor.PersistHistory {
+		return
+	}
+	e.config.FindHistory = append([]string(nil), e.findHistory.entries...)
+	e.config.ReplaceHistory = append([]string(nil), e.replaceHistory.entries...)
+	e.config.GotoHistory = append([]string(nil), e.gotoHistory.entries...)
+	e.saveConfig()
+}
+
+// recallHistory replaces *field with an older (up) or newer entry from h
+func recallHistory(h *inputHistory, field *string, up bool) {
+	var s string
+	var ok bool
+	if up {
+		s, ok = h.prev(*field)
+	} else {
+		s, ok = h.next()
+	}
+	if ok {
+		*field = s
+	}
+}
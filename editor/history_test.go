@@ -0,0 +1,113 @@
+package editor
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInputHistory(t *testing.T) {
+	var h inputHistory
+	for _, s := range []string{"one", "two", "", "one"} {
+		h.add(s)
+	}
+	if fmt.Sprint(h.entries) != "[one two]" {
+		t.Fatalf("entries = %v, want [one two] (newest first, no blanks or duplicates)", h.entries)
+	}
+
+	if s, _ := h.prev("draft"); s != "one" {
+		t.Errorf("first Up = %q, want one", s)
+	}
+	if s, _ := h.prev("one"); s != "two" {
+		t.Errorf("second Up = %q, want two", s)
+	}
+	if _, ok := h.prev("two"); ok {
+		t.Errorf("Up past the oldest entry should do nothing")
+	}
+	h.next()
+	if s, _ := h.next(); s != "draft" {
+		t.Errorf("Down past the newest entry = %q, want the typed draft back", s)
+	}
+	if _, ok := h.next(); ok {
+		t.Errorf("Down when not browsing should do nothing")
+	}
+
+	for i := 0; i < 60; i++ {
+		h.add(fmt.Sprint(i))
+	}
+	if len(h.entries) != 50 {
+		t.Errorf("kept %d entries, want the newest 50", len(h.entries))
+	}
+}
+
+func TestFindBarHistory(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "alpha beta", 0)
+	e.mode = ModeFind
+	for _, q := range []string{"alpha", "beta"} {
+		e.findQuery = q
+		e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	e.findQuery = "ga"
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if e.findQuery != "beta" {
+		t.Errorf("Up recalled %q, want the last search", e.findQuery)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if e.findQuery != "alpha" {
+		t.Errorf("second Up recalled %q, want alpha", e.findQuery)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if e.findQuery != "ga" {
+		t.Errorf("Down back past the newest = %q, want the typed text", e.findQuery)
+	}
+}
+
+func TestReplaceAndGotoHistory(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "a\nb\nc\n", 0)
+	e.showFindReplace()
+	e.findQuery = "a"
+	e.replaceQuery = "x"
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	e.findQuery, e.replaceQuery = "", ""
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if e.replaceQuery != "x" || e.findQuery != "" {
+		t.Errorf("Up in the replace field gave find %q, replace %q; want only replace = x", e.findQuery, e.replaceQuery)
+	}
+
+	e.mode = ModeNormal
+	e.showPrompt("Go to line: ", PromptGoToLine)
+	e.promptInput = "3"
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	e.showPrompt("Go to line: ", PromptGoToLine)
+	e.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if e.promptInput != "3" {
+		t.Errorf("Up in go-to prompt = %q, want 3", e.promptInput)
+	}
+}
+
+func TestPersistHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := newMarkupTestEditor("notes.txt", "alpha", 0)
+	e.rememberGoto("1")
+	if len(e.config.GotoHistory) != 0 {
+		t.Errorf("history saved to the config with persist_history off")
+	}
+
+	e.config.Editor.PersistHistory = true
+	e.findQuery = "alpha"
+	e.rememberSearch(false)
+	if len(e.config.FindHistory) != 1 || e.config.FindHistory[0] != "alpha" {
+		t.Errorf("config find history = %v, want [alpha]", e.config.FindHistory)
+	}
+
+	e2 := NewWithConfig(e.config)
+	// The go-to entry from before persistence was turned on is saved too
+	if len(e2.findHistory.entries) != 1 || len(e2.gotoHistory.entries) != 1 {
+		t.Errorf("new editor history = %v / %v, want it loaded from the config", e2.findHistory.entries, e2.gotoHistory.entries)
+	}
+}
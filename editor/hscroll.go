@@ -0,0 +1,29 @@
+package editor
+
+import (
+	"fmt"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// scrollMarkersEnabled reports whether lines running out of view sideways
+// are marked at the edge of the text
+func (e *Editor) scrollMarkersEnabled() bool {
+	return e.config == nil || e.config.Editor.ScrollMarkers
+}
+
+// hintCursorColumn says where the cursor is when scrolling sideways has
+// taken its column out of view, and how to get back to it
+func (e *Editor) hintCursorColumn() {
+	doc := e.activeDoc()
+	line := doc.cursor.Line()
+	lines := doc.buffer.Lines()
+	if line >= len(lines) || e.viewport.CursorColumnInView(lines[line], doc.cursor.Col()) {
+		return
+	}
+	hint := fmt.Sprintf("Cursor out of view at col %d", doc.cursor.Col()+1)
+	if key := e.keybindings.GetBinding("center_cursor").Primary; key != "" {
+		hint += " (" + config.FormatKeyForDisplay(key) + " scrolls back)"
+	}
+	e.statusbar.SetMessage(hint, "info")
+}
@@ -0,0 +1,206 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// indentSegment is the status bar segment showing the active buffer's indentation
+const indentSegment = "indent"
+
+// indentDialogWidth is the width of the Indentation dialog
+const indentDialogWidth = 36
+
+// indentStyle is how a buffer indents, overriding the configured settings
+type indentStyle struct {
+	tabs  bool // Indent with tabs rather than spaces
+	width int  // Spaces per level, or the tab width (0 = the configured tab width)
+}
+
+// indentChoice is a row of the Indentation dialog
+type indentChoice struct {
+	label  string
+	style  *indentStyle // nil for the rows below
+	detect bool         // Detect the style from the buffer's text
+}
+
+// indentChoices lists the Indentation dialog's rows
+var indentChoices = []indentChoice{
+	{label: "Spaces: 2", style: &indentStyle{width: 2}},
+	{label: "Spaces: 4", style: &indentStyle{width: 4}},
+	{label: "Spaces: 8", style: &indentStyle{width: 8}},
+	{label: "Tabs: 2", style: &indentStyle{tabs: true, width: 2}},
+	{label: "Tabs: 4", style: &indentStyle{tabs: true, width: 4}},
+	{label: "Tabs: 8", style: &indentStyle{tabs: true, width: 8}},
+	{label: "Detect from Content", detect: true},
+	{label: "Use Settings"},
+}
+
+// detectIndentEnabled reports whether opening a file picks up its indentation
+func (e *Editor) detectIndentEnabled() bool {
+	return e.config == nil || e.config.Editor.DetectIndent
+}
+
+// detectDocIndent sets doc's indentation from its text, leaving the
+// configured settings in charge if no line is indented
+func detectDocIndent(doc *Document) {
+	doc.indent = nil
+	if tabs, width := detectIndent(doc.buffer.Lines()); width >= 0 {
+		doc.indent = &indentStyle{tabs: tabs, width: width}
+	}
+}
+
+// indentLabel describes the active buffer's indentation, e.g. "Spaces: 2"
+func (e *Editor) indentLabel() string {
+	fs := e.fileSettings()
+	if fs.TabsToSpaces {
+		return fmt.Sprintf("Spaces: %d", fs.TabWidth)
+	}
+	return fmt.Sprintf("Tabs: %d", fs.TabWidth)
+}
+
+// registerIndentSegment shows the active buffer's indentation in the status bar
+func (e *Editor) registerIndentSegment() {
+	e.statusbar.RegisterSegment(ui.StatusSegment{Name: indentSegment, Priority: 1, Render: func() string {
+		if e.activeDoc().hexView {
+			return ""
+		}
+		return e.indentLabel()
+	}})
+}
+
+// showIndentDialog opens the Indentation dialog with the buffer's current
+// style selected
+func (e *Editor) showIndentDialog() {
+	e.indentIndex = 0
+	for i, choice := range indentChoices {
+		if choice.label == e.indentLabel() {
+			e.indentIndex = i
+		}
+	}
+	e.mode = ModeIndent
+}
+
+// indentDialog builds the Indentation dialog
+func (e *Editor) indentDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(indentDialogWidth)
+	db.AddTitleBorder(" Indentation ")
+	db.AddEmptyLine()
+	for i, choice := range indentChoices {
+		db.AddSelectableItem("  "+choice.label, i == e.indentIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Select  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayIndentDialog overlays the Indentation dialog centered on the viewport
+func (e *Editor) overlayIndentDialog(viewportContent string) string {
+	return e.indentDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// chooseIndent closes the dialog and applies the chosen row to the active buffer
+func (e *Editor) chooseIndent(index int) {
+	e.mode = ModeNormal
+	if index < 0 || index >= len(indentChoices) {
+		return
+	}
+	doc := e.activeDoc()
+	switch choice := indentChoices[index]; {
+	case choice.detect:
+		detectDocIndent(doc)
+		if doc.indent == nil {
+			e.statusbar.SetMessage("No indented lines; using the settings", "info")
+		}
+	case choice.style != nil:
+		style := *choice.style
+		doc.indent = &style
+	default:
+		doc.indent = nil
+	}
+	e.applyFileSettings()
+	e.statusbar.SetMessage("Indentation: "+e.indentLabel(), "info")
+}
+
+// handleIndentKey handles key events in the Indentation dialog
+func (e *Editor) handleIndentKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		e.indentIndex = max(e.indentIndex-1, 0)
+	case tea.KeyDown:
+		e.indentIndex = min(e.indentIndex+1, len(indentChoices)-1)
+	case tea.KeyHome:
+		e.indentIndex = 0
+	case tea.KeyEnd:
+		e.indentIndex = len(indentChoices) - 1
+	case tea.KeyEnter:
+		e.chooseIndent(e.indentIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// handleIndentMouse selects rows on click and applies one on a second click
+func (e *Editor) handleIndentMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.indentDialog().listMouse(msg, &e.indentIndex) {
+	case listChoose:
+		e.chooseIndent(e.indentIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// convertIndentation rewrites the leading whitespace of every line as tabs
+// or as spaces, keeping each line's indentation width at the tab width, in
+// one undo step. Spaces short of a full tab stay spaces when converting to
+// tabs.
+func (e *Editor) convertIndentation(tabs bool) {
+	doc := e.activeDoc()
+	tabWidth := e.fileSettings().TabWidth
+	e.clearMultiSelection()
+	doc.selection.Clear()
+
+	name, style := "Convert indentation to spaces", "spaces"
+	if tabs {
+		name, style = "Convert indentation to tabs", "tabs"
+	}
+	changed := e.editLineRange(name, 0, doc.buffer.LineCount()-1, false, func(line string) (int, string) {
+		lead := len(line) - len(strings.TrimLeft(line, " \t"))
+		width := 0
+		for _, c := range line[:lead] {
+			if c == '\t' {
+				width += tabWidth - width%tabWidth
+			} else {
+				width++
+			}
+		}
+		indent := strings.Repeat(" ", width)
+		if tabs {
+			indent = strings.Repeat("\t", width/tabWidth) + strings.Repeat(" ", width%tabWidth)
+		}
+		if indent == line[:lead] {
+			return 0, ""
+		}
+		return lead, indent
+	})
+
+	switch changed {
+	case 0:
+		e.statusbar.SetMessage("Indentation already uses "+style, "info")
+		return
+	case 1:
+		e.statusbar.SetMessage("Converted indentation to "+style+" on 1 line", "success")
+	default:
+		e.statusbar.SetMessage(fmt.Sprintf("Converted indentation to %s on %d lines", style, changed), "success")
+	}
+	doc.indent = &indentStyle{tabs: tabs, width: tabWidth}
+	e.applyFileSettings()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
@@ -0,0 +1,92 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDetectIndentOnLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "two.txt")
+	os.WriteFile(path, []byte("a:\n  b:\n    c: 1\n  d: 2\n"), 0644)
+
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 100, Height: 10})
+	e.config.Editor.TabWidth, e.config.Editor.TabsToSpaces = 4, false
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if fs := e.fileSettings(); !fs.TabsToSpaces || fs.TabWidth != 2 {
+		t.Errorf("settings = %+v, want 2 spaces from the file", fs)
+	}
+	e.View()
+	if view := e.statusbar.View(); !strings.Contains(view, "Spaces: 2 | ") {
+		t.Errorf("status bar = %q, want the detected indentation", view)
+	}
+
+	// Clicking it opens the dialog; choose Tabs: 8
+	x := strings.Index(stripAnsi(e.statusbar.View()), "Spaces: 2")
+	e.Update(tea.MouseMsg{X: x + 1, Y: 9, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if e.mode != ModeIndent || indentChoices[e.indentIndex].label != "Spaces: 2" {
+		t.Fatalf("mode = %v, index %d; want the Indentation dialog on Spaces: 2", e.mode, e.indentIndex)
+	}
+	e.chooseIndent(5)
+	if fs := e.fileSettings(); fs.TabsToSpaces || fs.TabWidth != 8 {
+		t.Errorf("after choosing Tabs: 8 settings = %+v", fs)
+	}
+	if e.viewport.TabWidth() != 8 {
+		t.Errorf("viewport tab width = %d, want 8", e.viewport.TabWidth())
+	}
+	e.chooseIndent(len(indentChoices) - 1)
+	if fs := e.fileSettings(); fs.TabsToSpaces || fs.TabWidth != 4 {
+		t.Errorf("Use Settings: settings = %+v, want the configured tabs", fs)
+	}
+
+	e.config.Editor.DetectIndent = false
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if e.activeDoc().indent != nil {
+		t.Error("detect_indent = false should leave the settings alone")
+	}
+}
+
+func TestConvertIndentation(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.statusbar.SetWidth(100)
+	e.config.Editor.TabWidth = 4
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("a\n\tb\n    c\n  \td\n   e\n")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.cursor.SetPosition(1, 1) // On the b
+
+	e.convertIndentation(false)
+	if got, want := doc.buffer.String(), "a\n    b\n    c\n    d\n   e\n"; got != want {
+		t.Errorf("to spaces = %q, want %q", got, want)
+	}
+	if line, col := doc.cursor.Line(), doc.cursor.Col(); line != 1 || col != 4 {
+		t.Errorf("cursor at %d:%d, want still on the b at 1:4", line, col)
+	}
+	if view := e.statusbar.View(); !strings.Contains(view, "Converted indentation to spaces on 2 lines") {
+		t.Errorf("status bar = %q, want the count of lines changed", view)
+	}
+	if fs := e.fileSettings(); !fs.TabsToSpaces {
+		t.Error("the buffer should go on indenting with spaces")
+	}
+
+	e.convertIndentation(true)
+	if got, want := doc.buffer.String(), "a\n\tb\n\tc\n\td\n   e\n"; got != want {
+		t.Errorf("to tabs = %q, want %q", got, want)
+	}
+	e.undo()
+	e.undo()
+	if got, want := doc.buffer.String(), "a\n\tb\n    c\n  \td\n   e\n"; got != want {
+		t.Errorf("after two undos = %q, want the original", got)
+	}
+}
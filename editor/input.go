@@ -0,0 +1,126 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Line editing shared by the find, replace and prompt inputs. Each input is
+// a string field with a cursor kept as the number of bytes after it, so
+// code that sets the field outright leaves the cursor at the end.
+
+// inputCursor returns the byte offset of the cursor in input, given the
+// bytes after it
+func inputCursor(input string, tail int) int {
+	pos := len(input) - max(0, min(tail, len(input)))
+	for pos > 0 && pos < len(input) && !utf8.RuneStart(input[pos]) {
+		pos--
+	}
+	return pos
+}
+
+// editInput applies a line-editing key to input, whose cursor has tail
+// bytes after it: Left/Right, Home/End, Backspace/Delete, Ctrl+W to delete
+// the word before the cursor, Ctrl+U to clear, Ctrl+V to paste, and typed
+// or pasted text. It reports whether the key was one of them.
+func (e *Editor) editInput(input *string, tail *int, msg tea.KeyMsg) bool {
+	s := *input
+	pos := inputCursor(s, *tail)
+	insert := ""
+	switch msg.Type {
+	case tea.KeyLeft:
+		_, size := utf8.DecodeLastRuneInString(s[:pos])
+		pos -= size
+	case tea.KeyRight:
+		_, size := utf8.DecodeRuneInString(s[pos:])
+		pos += size
+	case tea.KeyHome:
+		pos = 0
+	case tea.KeyEnd:
+		pos = len(s)
+	case tea.KeyBackspace:
+		_, size := utf8.DecodeLastRuneInString(s[:pos])
+		s = s[:pos-size] + s[pos:]
+		pos -= size
+	case tea.KeyDelete:
+		_, size := utf8.DecodeRuneInString(s[pos:])
+		s = s[:pos] + s[pos+size:]
+	case tea.KeyCtrlW:
+		start := inputWordStart(s, pos)
+		s = s[:start] + s[pos:]
+		pos = start
+	case tea.KeyCtrlU:
+		s, pos = "", 0
+	case tea.KeyCtrlV:
+		if text, err := e.clipboard.Paste(); err == nil {
+			insert = inputText(text)
+		}
+	case tea.KeyRunes:
+		insert = inputText(string(msg.Runes))
+	case tea.KeySpace:
+		insert = " "
+	default:
+		return false
+	}
+	s = s[:pos] + insert + s[pos:]
+	pos += len(insert)
+	*input = s
+	*tail = len(s) - pos
+	return true
+}
+
+// inputWordStart returns where Ctrl+W stops deleting back from pos: over
+// spaces, then over a word or a run of punctuation, so the parts of a path
+// go one at a time
+func inputWordStart(s string, pos int) int {
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:pos])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		pos -= size
+	}
+	if pos == 0 {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:pos])
+	word := isWordChar(r)
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:pos])
+		if unicode.IsSpace(r) || isWordChar(r) != word {
+			break
+		}
+		pos -= size
+	}
+	return pos
+}
+
+// inputText fits pasted text to a one-line input: line breaks and tabs
+// become spaces and other control characters are dropped
+func inputText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case r < ' ' || r == 0x7f:
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// inputWithCursor draws input with its cursor, the character under it in
+// reverse video or a block after the text. It always takes one cell more
+// than the text, where the block would go.
+func inputWithCursor(input string, tail int) string {
+	pos := inputCursor(input, tail)
+	if pos == len(input) {
+		return input + "▂" // Lower quarter block cursor
+	}
+	_, size := utf8.DecodeRuneInString(input[pos:])
+	return input[:pos] + "\033[7m" + input[pos:pos+size] + "\033[27m" + input[pos+size:] + " "
+}
@@ -0,0 +1,81 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditInput(t *testing.T) {
+	e := New()
+	input, tail := "", 0
+	key := func(msg tea.KeyMsg) {
+		t.Helper()
+		if !e.editInput(&input, &tail, msg) {
+			t.Fatalf("key %v not handled", msg)
+		}
+	}
+	typeText := func(s string) { key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}) }
+
+	typeText("héllo")
+	key(tea.KeyMsg{Type: tea.KeyLeft})
+	key(tea.KeyMsg{Type: tea.KeyLeft})
+	key(tea.KeyMsg{Type: tea.KeyLeft})
+	typeText("X")
+	if input != "héXllo" {
+		t.Errorf("insert mid-input = %q, want %q", input, "héXllo")
+	}
+	key(tea.KeyMsg{Type: tea.KeyBackspace})
+	key(tea.KeyMsg{Type: tea.KeyBackspace})
+	if input != "hllo" {
+		t.Errorf("backspace over é = %q, want %q", input, "hllo")
+	}
+	key(tea.KeyMsg{Type: tea.KeyHome})
+	key(tea.KeyMsg{Type: tea.KeyDelete})
+	if input != "llo" || inputCursor(input, tail) != 0 {
+		t.Errorf("delete at start = %q cursor %d", input, inputCursor(input, tail))
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyEnd})
+	typeText(" /usr/local/bin")
+	key(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if input != "llo /usr/local/" {
+		t.Errorf("Ctrl+W = %q, want the last path part deleted", input)
+	}
+	key(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if input != "llo /usr/local" {
+		t.Errorf("Ctrl+W = %q, want the slash deleted", input)
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a\nb\tc"), Paste: true})
+	if !strings.HasSuffix(input, "a b c") {
+		t.Errorf("paste = %q, want line breaks and tabs as spaces", input)
+	}
+
+	e.clipboard.Copy("clip")
+	key(tea.KeyMsg{Type: tea.KeyCtrlU})
+	key(tea.KeyMsg{Type: tea.KeyCtrlV})
+	if input != "clip" {
+		t.Errorf("Ctrl+U then Ctrl+V = %q, want %q", input, "clip")
+	}
+	if e.editInput(&input, &tail, tea.KeyMsg{Type: tea.KeyEnter}) {
+		t.Error("Enter is not an editing key")
+	}
+}
+
+func TestFindBarCursor(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	e.mode = ModeFind
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fnd")})
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	e.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if e.findQuery != "find" {
+		t.Errorf("findQuery = %q, want %q", e.findQuery, "find")
+	}
+	if view := e.View(); !strings.Contains(view, "fi\033[7mn\033[27md") {
+		t.Error("the find bar should show the cursor on the n")
+	}
+}
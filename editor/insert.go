@@ -0,0 +1,200 @@
+package editor
+
+import (
+	"crypto/rand"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// insertTextWidth is the width of the Insert Text dialog
+const insertTextWidth = 60
+
+// loremIpsum is placeholder text for mocking up documents
+const loremIpsum = `Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed do eiusmod tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam, quis nostrud exercitation ullamco laboris.
+
+Duis aute irure dolor in reprehenderit in voluptate velit esse cillum dolore eu fugiat nulla pariatur. Excepteur sint occaecat cupidatat non proident.
+
+Sed ut perspiciatis unde omnis iste natus error sit voluptatem accusantium doloremque laudantium, totam rem aperiam, eaque ipsa quae ab illo inventore veritatis et quasi architecto beatae vitae dicta sunt explicabo.
+
+Nemo enim ipsam voluptatem quia voluptas sit aspernatur aut odit aut fugit, sed quia consequuntur magni dolores eos qui ratione voluptatem sequi nesciunt.
+
+Neque porro quisquam est, qui dolorem ipsum quia dolor sit amet, consectetur, adipisci velit, sed quia non numquam eius modi tempora incidunt ut labore et dolore magnam aliquam quaerat voluptatem.
+`
+
+// insertChoice is one entry of the Insert Text dialog
+type insertChoice struct {
+	name    string
+	text    string // What is inserted
+	command bool   // Asks for a shell command and inserts its output instead
+}
+
+// insertOutputMsg carries the output of a command run by Insert Text
+type insertOutputMsg struct {
+	doc     *Document
+	command string
+	output  string
+	err     error
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// insertTextChoices lists what can be inserted, with the times and UUID as
+// of now
+func (e *Editor) insertTextChoices(now time.Time) []insertChoice {
+	var choices []insertChoice
+	for _, layout := range e.config.Editor.InsertDateFormats {
+		choices = append(choices, insertChoice{name: "Date/time", text: now.Format(layout)})
+	}
+	if name := e.activeDoc().filename; name != "" {
+		choices = append(choices, insertChoice{name: "File name", text: filepath.Base(name)})
+		if abs, err := filepath.Abs(name); err == nil {
+			name = abs
+		}
+		choices = append(choices, insertChoice{name: "File path", text: name})
+	}
+	return append(choices,
+		insertChoice{name: "UUID", text: newUUID()},
+		insertChoice{name: "Command output...", command: true},
+		insertChoice{name: "Lorem ipsum", text: loremIpsum},
+	)
+}
+
+// showInsertText opens the Insert Text dialog
+func (e *Editor) showInsertText() {
+	e.insertChoices = e.insertTextChoices(time.Now())
+	e.insertIndex = 0
+	e.mode = ModeInsertText
+}
+
+// insertTextLabel shows a choice's name with a preview of its text on the right
+func (e *Editor) insertTextLabel(choice insertChoice, width int) string {
+	preview, _, _ := strings.Cut(choice.text, "\n")
+	if preview != choice.text {
+		preview += e.box.Ellipsis
+	}
+	name := " " + choice.name
+	preview = runewidth.Truncate(preview, width-runewidth.StringWidth(name)-2, e.box.Ellipsis)
+	return name + strings.Repeat(" ", max(width-runewidth.StringWidth(name)-runewidth.StringWidth(preview)-1, 1)) + preview
+}
+
+// insertTextDialog builds the Insert Text dialog
+func (e *Editor) insertTextDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(insertTextWidth)
+	db.AddTitleBorder(" Insert Text ")
+	db.AddEmptyLine()
+	for i, choice := range e.insertChoices {
+		db.AddSelectableItem(e.insertTextLabel(choice, db.InnerWidth()), i == e.insertIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Insert  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayInsertTextDialog overlays the Insert Text dialog centered on the viewport
+func (e *Editor) overlayInsertTextDialog(viewportContent string) string {
+	return e.insertTextDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// chooseInsertText closes the dialog and inserts the chosen text, or asks
+// for the command whose output to insert
+func (e *Editor) chooseInsertText(index int) {
+	e.mode = ModeNormal
+	if index < 0 || index >= len(e.insertChoices) {
+		return
+	}
+	choice := e.insertChoices[index]
+	if choice.command {
+		e.showPrompt("Insert output of: ", PromptInsertCommand)
+		return
+	}
+	e.insertText(choice.text)
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// runInsertCommand runs command in the background from the file's directory
+func (e *Editor) runInsertCommand(command string) tea.Cmd {
+	if command == "" {
+		e.statusbar.SetMessage("Cancelled", "info")
+		return nil
+	}
+	doc := e.activeDoc()
+	dir := ""
+	if doc.filename != "" {
+		dir = filepath.Dir(doc.filename)
+	}
+	e.statusbar.SetMessage("Running "+command+e.box.Ellipsis, "info")
+	return func() tea.Msg {
+		cmd := shellCommand(command)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		return insertOutputMsg{doc: doc, command: command, output: string(out), err: err}
+	}
+}
+
+// applyInsertOutput inserts a command's output at the cursor, without the
+// final newline so a one-line result stays inline
+func (e *Editor) applyInsertOutput(msg insertOutputMsg) {
+	if msg.err != nil {
+		e.statusbar.SetMessage("Command failed: "+msg.err.Error(), "error")
+		return
+	}
+	if msg.doc != e.activeDoc() {
+		e.statusbar.SetMessage("Output of "+msg.command+" not inserted: buffer changed", "warning")
+		return
+	}
+	output := strings.TrimSuffix(msg.output, "\n")
+	if output == "" {
+		e.statusbar.SetMessage("No output from "+msg.command, "info")
+		return
+	}
+	e.insertText(output)
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// handleInsertTextKey handles key events in the Insert Text dialog
+func (e *Editor) handleInsertTextKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.insertIndex > 0 {
+			e.insertIndex--
+		}
+	case tea.KeyDown:
+		if e.insertIndex < len(e.insertChoices)-1 {
+			e.insertIndex++
+		}
+	case tea.KeyEnter:
+		e.chooseInsertText(e.insertIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyRunes:
+		// 1-9 insert the matching choice directly
+		if len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+			e.chooseInsertText(int(msg.Runes[0] - '1'))
+		}
+	}
+	return e, nil
+}
+
+// handleInsertTextMouse selects choices on click and inserts on a second click
+func (e *Editor) handleInsertTextMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.insertTextDialog().listMouse(msg, &e.insertIndex) {
+	case listChoose:
+		e.chooseInsertText(e.insertIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
@@ -0,0 +1,73 @@
+package editor
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInsertTextChoices(t *testing.T) {
+	e := loadSymbolFiles(t, map[string]string{"notes.txt": ""}, "notes.txt")
+	e.config.Editor.InsertDateFormats = []string{"2006-01-02", "15:04"}
+	now := time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC)
+
+	var names, texts []string
+	for _, c := range e.insertTextChoices(now) {
+		names = append(names, c.name)
+		texts = append(texts, c.text)
+	}
+	want := []string{"Date/time", "Date/time", "File name", "File path", "UUID", "Command output...", "Lorem ipsum"}
+	if len(names) != len(want) {
+		t.Fatalf("choices = %q, want %q", names, want)
+	}
+	if texts[0] != "2024-03-09" || texts[1] != "14:05" || texts[2] != "notes.txt" {
+		t.Errorf("texts = %q", texts[:3])
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(texts[4]) {
+		t.Errorf("UUID = %q", texts[4])
+	}
+
+	// Untitled buffers have no file name to offer
+	e.newFile()
+	if got := len(e.insertTextChoices(now)); got != len(want)-2 {
+		t.Errorf("untitled buffer has %d choices, want %d", got, len(want)-2)
+	}
+}
+
+func TestInsertText(t *testing.T) {
+	e := loadSymbolFiles(t, map[string]string{"notes.txt": "[]"}, "notes.txt")
+	doc := e.activeDoc()
+	doc.cursor.SetByteOffset(1)
+
+	e.Update(tea.KeyMsg{Type: tea.KeyF2})
+	if e.mode != ModeInsertText {
+		t.Fatalf("F2 should open Insert Text, mode = %v", e.mode)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'6'}}) // File name
+	if got := doc.buffer.String(); got != "[notes.txt]" || e.mode != ModeNormal {
+		t.Fatalf("buffer = %q, mode %v", got, e.mode)
+	}
+
+	// Command output is inserted without its final newline
+	e.showInsertText()
+	for i, c := range e.insertChoices {
+		if c.command {
+			e.insertIndex = i
+		}
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModePrompt || e.promptAction != PromptInsertCommand {
+		t.Fatalf("Command output should prompt for the command, mode = %v", e.mode)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("echo hi")})
+	_, cmd := e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter should run the command")
+	}
+	e.Update(cmd())
+	if got := doc.buffer.String(); got != "[notes.txthi]" {
+		t.Errorf("buffer = %q, want the output inserted at the cursor", got)
+	}
+}
@@ -0,0 +1,117 @@
+package editor
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// insertBufferWidth is the width of the Insert Buffer dialog
+const insertBufferWidth = 50
+
+// insertBufferChoices returns the buffers that can be inserted into the
+// active one: every open buffer except itself
+func (e *Editor) insertBufferChoices() []*Document {
+	var docs []*Document
+	for i, doc := range e.documents {
+		if i != e.activeIdx {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// showInsertBuffer opens the Insert Buffer dialog
+func (e *Editor) showInsertBuffer() {
+	if len(e.insertBufferChoices()) == 0 {
+		e.statusbar.SetMessage("No other buffers open", "info")
+		return
+	}
+	e.insertBufferIndex = 0
+	e.mode = ModeInsertBuffer
+}
+
+// insertBufferLabel describes a buffer in the dialog, e.g. "notes.txt (12 lines)"
+func insertBufferLabel(doc *Document, name string, width int, ellipsis string) string {
+	if doc.modified {
+		name = "*" + name
+	}
+	suffix := fmt.Sprintf(" (%d lines)", doc.buffer.LineCount())
+	if doc.buffer.LineCount() == 1 {
+		suffix = " (1 line)"
+	}
+	return runewidth.Truncate(name, width-runewidth.StringWidth(suffix), ellipsis) + suffix
+}
+
+// insertBufferDialog builds the Insert Buffer dialog
+func (e *Editor) insertBufferDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(insertBufferWidth)
+	db.AddTitleBorder(" Insert Buffer ")
+	db.AddEmptyLine()
+	for i, doc := range e.insertBufferChoices() {
+		db.AddSelectableItem(insertBufferLabel(doc, e.bufferName(doc), db.InnerWidth()-2, e.box.Ellipsis), i == e.insertBufferIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Insert  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayInsertBufferDialog overlays the Insert Buffer dialog centered on the viewport
+func (e *Editor) overlayInsertBufferDialog(viewportContent string) string {
+	return e.insertBufferDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// insertBuffer closes the dialog and inserts the chosen buffer's contents at
+// the cursor
+func (e *Editor) insertBuffer(index int) {
+	e.mode = ModeNormal
+	docs := e.insertBufferChoices()
+	if index < 0 || index >= len(docs) {
+		return
+	}
+	text := docs[index].buffer.String()
+	if text == "" {
+		e.statusbar.SetMessage("Buffer is empty", "info")
+		return
+	}
+	e.insertText(text)
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// handleInsertBufferKey handles key events in the Insert Buffer dialog
+func (e *Editor) handleInsertBufferKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := len(e.insertBufferChoices())
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.insertBufferIndex > 0 {
+			e.insertBufferIndex--
+		}
+	case tea.KeyDown:
+		if e.insertBufferIndex < count-1 {
+			e.insertBufferIndex++
+		}
+	case tea.KeyEnter:
+		e.insertBuffer(e.insertBufferIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyRunes:
+		// 1-9 insert the matching buffer directly
+		if len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+			e.insertBuffer(int(msg.Runes[0] - '1'))
+		}
+	}
+	return e, nil
+}
+
+// handleInsertBufferMouse selects buffers on click and inserts on a second click
+func (e *Editor) handleInsertBufferMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.insertBufferDialog().listMouse(msg, &e.insertBufferIndex) {
+	case listChoose:
+		e.insertBuffer(e.insertBufferIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
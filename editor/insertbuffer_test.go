@@ -0,0 +1,42 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInsertBuffer(t *testing.T) {
+	e := New()
+	e.showInsertBuffer()
+	if e.mode != ModeNormal {
+		t.Fatalf("dialog opened with no other buffers")
+	}
+
+	e.activeDoc().buffer = NewBufferFromString("header\n")
+	e.newFile()
+	e.insertText("scratch one")
+	e.newFile()
+	e.insertText("<>")
+	e.activeDoc().cursor.SetByteOffset(1)
+
+	e.showInsertBuffer()
+	if e.mode != ModeInsertBuffer {
+		t.Fatalf("mode = %v, want ModeInsertBuffer", e.mode)
+	}
+	if got := len(e.insertBufferChoices()); got != 2 {
+		t.Errorf("choices = %d, want the 2 other buffers", got)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'2'}})
+	if got := e.activeDoc().buffer.String(); got != "<scratch one>" {
+		t.Errorf("buffer = %q, want second buffer inserted at the cursor", got)
+	}
+	if e.mode != ModeNormal {
+		t.Errorf("dialog still open after inserting")
+	}
+
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "<>" {
+		t.Errorf("after undo = %q, want insert undone in one step", got)
+	}
+}
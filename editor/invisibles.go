@@ -0,0 +1,182 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// invisiblePolicy selects which invisible characters are stripped on save
+type invisiblePolicy struct {
+	softHyphens bool // U+00AD
+	zeroWidth   bool // U+200B, U+200C, U+200D, U+2060
+	boms        bool // U+FEFF inside the text
+}
+
+// invisibleCounts tallies the invisible characters a policy would strip
+type invisibleCounts struct {
+	softHyphens int
+	zeroWidth   int
+	boms        int
+}
+
+// total returns the number of characters that would be stripped
+func (c invisibleCounts) total() int {
+	return c.softHyphens + c.zeroWidth + c.boms
+}
+
+// String describes the counts for the pre-save report, e.g. "2 soft hyphens, 1 BOM"
+func (c invisibleCounts) String() string {
+	var parts []string
+	add := func(n int, one, many string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+one)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, many))
+		}
+	}
+	add(c.softHyphens, "soft hyphen", "soft hyphens")
+	add(c.zeroWidth, "zero-width character", "zero-width characters")
+	add(c.boms, "stray BOM", "stray BOMs")
+	return strings.Join(parts, ", ")
+}
+
+// strips reports whether the policy removes r
+func (p invisiblePolicy) strips(r rune) bool {
+	switch r {
+	case '\u00AD':
+		return p.softHyphens
+	case '\u200B', '\u200C', '\u200D', '\u2060':
+		return p.zeroWidth
+	case '\uFEFF':
+		return p.boms
+	}
+	return false
+}
+
+// active reports whether the policy strips anything at all
+func (p invisiblePolicy) active() bool {
+	return p.softHyphens || p.zeroWidth || p.boms
+}
+
+// count tallies the characters in s the policy would strip
+func (p invisiblePolicy) count(s string) invisibleCounts {
+	var c invisibleCounts
+	for _, r := range s {
+		if !p.strips(r) {
+			continue
+		}
+		switch r {
+		case '\u00AD':
+			c.softHyphens++
+		case '\uFEFF':
+			c.boms++
+		default:
+			c.zeroWidth++
+		}
+	}
+	return c
+}
+
+// strip returns s without the characters the policy removes
+func (p invisiblePolicy) strip(s string) string {
+	return strings.Map(func(r rune) rune {
+		if p.strips(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// invisiblePolicy returns the configured strip-on-save policy
+func (e *Editor) invisiblePolicy() invisiblePolicy {
+	if e.config == nil {
+		return invisiblePolicy{}
+	}
+	return invisiblePolicy{
+		softHyphens: e.config.Editor.StripSoftHyphens,
+		zeroWidth:   e.config.Editor.StripZeroWidth,
+		boms:        e.config.Editor.StripStrayBOM,
+	}
+}
+
+// checkInvisibles reports invisible characters the save policy would strip.
+// Returns true if the save can go ahead; otherwise a confirm dialog asks first
+// and resume is called to redo the save once the user has chosen.
+func (e *Editor) checkInvisibles(resume func() bool) bool {
+	policy := e.invisiblePolicy()
+	if e.invisiblesChecked || e.lossySave != lossyAsk || !policy.active() {
+		return true
+	}
+	counts := policy.count(e.activeDoc().buffer.String())
+	if counts.total() == 0 {
+		return true
+	}
+	if e.skipQuietSave(counts.String() + " to strip") {
+		return false
+	}
+
+	e.showConfirm(&ConfirmDialog{
+		Saving:  true,
+		Title:   "Invisible Characters",
+		Message: "Found " + counts.String() + ".\nStrip them before saving?",
+		Buttons: []ConfirmButton{
+			{Label: "Strip & Save", Hotkey: 's'},
+			{Label: "Save As-Is", Hotkey: 'a'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 0,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			if choice == 2 {
+				e.statusbar.SetMessage("Save cancelled", "info")
+				return nil
+			}
+			if choice == 0 {
+				e.stripInvisibles(policy)
+			}
+			e.invisiblesChecked = true
+			resume()
+			e.invisiblesChecked = false
+			return nil
+		},
+	})
+	return false
+}
+
+// stripInvisibles removes the policy's invisible characters from the active
+// buffer as a single undoable edit
+func (e *Editor) stripInvisibles(policy invisiblePolicy) {
+	e.transformBuffer(policy.strip)
+}
+
+// transformBuffer replaces the whole active buffer with transform's result as
+// a single undoable edit. The cursor goes where transform puts the text
+// before it.
+func (e *Editor) transformBuffer(transform func(string) string) {
+	if e.refuseEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	content := doc.buffer.String()
+	changed := transform(content)
+	if changed == content {
+		return
+	}
+
+	cursor := doc.cursor.ByteOffset()
+	entry := &UndoEntry{
+		Position:     0,
+		Deleted:      content,
+		Inserted:     changed,
+		CursorBefore: cursor,
+	}
+	doc.buffer.Replace(0, len(content), changed)
+	doc.selection.Clear()
+	doc.cursor.SetByteOffset(min(len(transform(content[:cursor])), len(changed)))
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+}
@@ -0,0 +1,106 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestInvisiblePolicyStrip(t *testing.T) {
+	content := "co\u00ADop\u200Ber\u200Dation\uFEFF"
+	tests := []struct {
+		name   string
+		policy invisiblePolicy
+		want   string
+		total  int
+	}{
+		{"preserve", invisiblePolicy{}, content, 0},
+		{"soft hyphens", invisiblePolicy{softHyphens: true}, "coop\u200Ber\u200Dation\uFEFF", 1},
+		{"zero width", invisiblePolicy{zeroWidth: true}, "co\u00ADoperation\uFEFF", 2},
+		{"all", invisiblePolicy{true, true, true}, "cooperation", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.strip(content); got != tt.want {
+				t.Errorf("strip = %q, want %q", got, tt.want)
+			}
+			if got := tt.policy.count(content).total(); got != tt.total {
+				t.Errorf("count = %d, want %d", got, tt.total)
+			}
+		})
+	}
+}
+
+func TestInvisibleCountsString(t *testing.T) {
+	c := invisibleCounts{softHyphens: 2, boms: 1}
+	if got, want := c.String(), "2 soft hyphens, 1 stray BOM"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSaveStripsInvisiblesAfterConfirm(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Editor.StripSoftHyphens = true
+	// Saving records the directory in the config asynchronously, so don't
+	// let t.TempDir's cleanup race the write
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	e := NewWithConfig(cfg)
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("hy\u00ADphen")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.filename = filepath.Join(t.TempDir(), "out.txt")
+
+	if e.doSave() {
+		t.Fatalf("save should wait for the invisible character report")
+	}
+	if e.mode != ModeConfirm {
+		t.Fatalf("mode = %v, want ModeConfirm", e.mode)
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyEnter})
+
+	data, err := os.ReadFile(doc.filename)
+	if err != nil {
+		t.Fatalf("file not saved: %v", err)
+	}
+	if string(data) != "hyphen" {
+		t.Errorf("saved %q, want %q", data, "hyphen")
+	}
+	e.undo()
+	if got := doc.buffer.String(); got != "hy\u00ADphen" {
+		t.Errorf("undo restored %q", got)
+	}
+}
+
+func TestQuietSaveSkipsQuestions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Editor.StripSoftHyphens = true
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := NewWithConfig(cfg)
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("hy\u00ADphen")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.filename = filepath.Join(t.TempDir(), "out.txt")
+	doc.modified = true
+
+	if e.quietSave() {
+		t.Fatalf("a quiet save should skip rather than ask")
+	}
+	if e.mode != ModeNormal || !doc.modified {
+		t.Errorf("mode = %v, modified = %v; want no dialog and the buffer still modified", e.mode, doc.modified)
+	}
+	if _, err := os.Stat(doc.filename); err == nil {
+		t.Errorf("the skipped save wrote the file")
+	}
+	if e.savingQuietly {
+		t.Errorf("quiet mode should end with the save")
+	}
+}
@@ -0,0 +1,217 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffMaxCells caps the work of matching up the changed middle of two
+// texts; past it the whole middle is shown as replaced
+const diffMaxCells = 4 << 20
+
+// diffOp is one line of a line diff: kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the edits that turn a into b, keeping the longest run
+// of common lines. Matching lines at the start and end are taken first so
+// that only the changed middle is compared line by line.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// diffMiddle diffs the part of two texts between their common start and
+// end by longest common subsequence
+func diffMiddle(a, b []string) []diffOp {
+	var ops []diffOp
+	if len(a)*len(b) > diffMaxCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int32, len(a)+1)
+	for i := range common {
+		common[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// diffCounts returns how many lines a diff adds and removes
+func diffCounts(ops []diffOp) (added, removed int) {
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// unifiedDiff formats the diff of a to b in unified format, with
+// diffContext lines of context around each change. Returns "" if the
+// texts are the same.
+func unifiedDiff(aName, bName string, a, b []string) string {
+	ops := diffLines(a, b)
+	var out strings.Builder
+	// aLine and bLine count the lines of a and b before ops[i]
+	aLine, bLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			aLine++
+			bLine++
+			i++
+			continue
+		}
+
+		// A hunk runs from diffContext lines before this change to
+		// diffContext lines after the last change less than twice that apart
+		start := max(i-diffContext, 0)
+		for k := start; k < i; k++ {
+			aLine--
+			bLine--
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = next
+		}
+
+		aCount, bCount := 0, 0
+		var body strings.Builder
+		for _, op := range ops[start:end] {
+			body.WriteByte(op.kind)
+			body.WriteString(op.line)
+			body.WriteByte('\n')
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+		out.WriteString(body.String())
+		aLine += aCount
+		bLine += bCount
+		i = end
+	}
+	return out.String()
+}
+
+// hunkRange formats the start and length of one side of a hunk. An empty
+// side is numbered by the line before it, as diff does.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// lineChange replaces lines aStart to aEnd (exclusive) of the old text
+// with lines of the new one
+type lineChange struct {
+	aStart, aEnd int
+	lines        []string
+}
+
+// diffChanges groups the edits of a line diff into the runs of lines they
+// replace, in order
+func diffChanges(ops []diffOp) []lineChange {
+	var changes []lineChange
+	aLine := 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			aLine++
+			i++
+			continue
+		}
+		change := lineChange{aStart: aLine, aEnd: aLine}
+		for ; i < len(ops) && ops[i].kind != ' '; i++ {
+			if ops[i].kind == '-' {
+				change.aEnd++
+			} else {
+				change.lines = append(change.lines, ops[i].line)
+			}
+		}
+		aLine = change.aEnd
+		changes = append(changes, change)
+	}
+	return changes
+}
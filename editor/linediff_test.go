@@ -0,0 +1,40 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := strings.Split("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl", "\n")
+	b := strings.Split("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nl\nm", "\n")
+	want := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -8,5 +8,5 @@
+ h
+ i
+ j
+-k
+ l
++m
+`
+	if got := unifiedDiff("old", "new", a, b); got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+	if added, removed := diffCounts(diffLines(a, b)); added != 2 || removed != 2 {
+		t.Errorf("counts +%d -%d", added, removed)
+	}
+	if got := unifiedDiff("old", "new", a, a); got != "" {
+		t.Errorf("diff of the same text = %q", got)
+	}
+	if got := unifiedDiff("old", "new", nil, []string{"x"}); got != "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n" {
+		t.Errorf("diff from nothing = %q", got)
+	}
+}
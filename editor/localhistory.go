@@ -0,0 +1,301 @@
+package editor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/config"
+	enc "github.com/cornish/textivus-editor/encoding"
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+// localHistoryWidth is the width of the Local History dialog
+const localHistoryWidth = 60
+
+// localHistoryMaxSize is the largest save kept as a snapshot
+const localHistoryMaxSize = 8 << 20
+
+// snapshotLayout names snapshot files by when the save happened, so they
+// sort oldest first
+const snapshotLayout = "2006-01-02T15-04-05.000000000"
+
+// localSnapshot is a saved version of the active file listed in the Local
+// History dialog
+type localSnapshot struct {
+	path           string
+	when           time.Time
+	size           int64
+	added, removed int // Lines the buffer has that the snapshot lacks, and the reverse
+	matchesBuffer  bool
+}
+
+// localHistoryEnabled reports whether saves are kept as snapshots
+func (e *Editor) localHistoryEnabled() bool {
+	return e.config != nil && e.config.Editor.LocalHistory
+}
+
+// localHistoryDir returns the folder holding the snapshots of filename
+func localHistoryDir(filename string) (string, error) {
+	dir, err := config.LocalHistoryDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, encodeBackupName(filename)), nil
+}
+
+// snapshotFiles lists the snapshots of filename, oldest first, with the
+// times they were taken
+func snapshotFiles(filename string) ([]string, []time.Time) {
+	dir, err := localHistoryDir(filename)
+	if err != nil {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	var paths []string
+	var times []time.Time
+	for _, entry := range entries {
+		when, err := time.ParseInLocation(snapshotLayout, entry.Name(), time.Local)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+		times = append(times, when)
+	}
+	return paths, times
+}
+
+// recordLocalHistory keeps data, just saved to filename, as a snapshot
+// unless it matches the newest one, then prunes the file's old snapshots.
+// Failures are not reported: the save itself went through.
+func (e *Editor) recordLocalHistory(filename string, data []byte) {
+	if !e.localHistoryEnabled() || len(data) > localHistoryMaxSize {
+		return
+	}
+	dir, err := localHistoryDir(filename)
+	if err != nil {
+		return
+	}
+	paths, _ := snapshotFiles(filename)
+	if len(paths) > 0 {
+		if last, err := os.ReadFile(paths[len(paths)-1]); err == nil && bytes.Equal(last, data) {
+			return
+		}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(dir, time.Now().Format(snapshotLayout)), data, 0600); err != nil {
+		return
+	}
+	e.pruneLocalHistory(filename, time.Now())
+}
+
+// pruneLocalHistory deletes the snapshots of filename beyond the newest
+// local_history_max and those older than local_history_days. The newest
+// snapshot is always kept.
+func (e *Editor) pruneLocalHistory(filename string, now time.Time) {
+	paths, times := snapshotFiles(filename)
+	keep, days := e.config.Editor.LocalHistoryMax, e.config.Editor.LocalHistoryDays
+	for i, path := range paths {
+		newer := len(paths) - 1 - i
+		if newer == 0 {
+			break
+		}
+		if (keep > 0 && newer >= keep) || (days > 0 && now.Sub(times[i]) > time.Duration(days)*24*time.Hour) {
+			os.Remove(path)
+		}
+	}
+}
+
+// readSnapshot reads a snapshot as text, decoded like the buffer
+func (e *Editor) readSnapshot(path string) (string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	encoding := e.activeDoc().encoding
+	if encoding == nil {
+		encoding = enc.GetEncodingByID("utf-8")
+	}
+	content, err := enc.DecodeToUTF8(raw, encoding)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// showLocalHistory opens the Local History dialog with the active file's
+// snapshots, newest first, each compared with the buffer
+func (e *Editor) showLocalHistory() {
+	doc := e.activeDoc()
+	if doc.filename == "" {
+		e.statusbar.SetMessage("No file to show the history of", "error")
+		return
+	}
+	paths, times := snapshotFiles(doc.filename)
+	if len(paths) == 0 {
+		if e.localHistoryEnabled() {
+			e.statusbar.SetMessage("No snapshots of "+filepath.Base(doc.filename)+" yet", "info")
+		} else {
+			e.statusbar.SetMessage("Local history is off (local_history in [editor])", "info")
+		}
+		return
+	}
+
+	lines := doc.buffer.Lines()
+	e.localSnapshots = nil
+	for i := len(paths) - 1; i >= 0; i-- {
+		snap := localSnapshot{path: paths[i], when: times[i]}
+		if info, err := os.Stat(paths[i]); err == nil {
+			snap.size = info.Size()
+		}
+		if text, err := e.readSnapshot(paths[i]); err == nil {
+			ops := diffLines(strings.Split(text, "\n"), lines)
+			snap.added, snap.removed = diffCounts(ops)
+			snap.matchesBuffer = snap.added == 0 && snap.removed == 0
+		}
+		e.localSnapshots = append(e.localSnapshots, snap)
+	}
+	e.localHistoryIndex = 0
+	e.mode = ModeLocalHistory
+}
+
+// localHistoryDialog builds the Local History dialog
+func (e *Editor) localHistoryDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(localHistoryWidth)
+	db.AddTitleBorder(" Local History ")
+	db.AddEmptyLine()
+	now := time.Now()
+	for i, snap := range e.localSnapshots {
+		when := snap.when.Format("15:04:05")
+		if y, m, d := snap.when.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+			when = snap.when.Format("Jan 2 15:04")
+		}
+		change := "same as buffer"
+		if !snap.matchesBuffer {
+			change = fmt.Sprintf("buffer +%d -%d lines", snap.added, snap.removed)
+		}
+		db.AddSelectableItem(fmt.Sprintf("  %-12s %9s  %s", when, formatFileSize(snap.size), change), i == e.localHistoryIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Restore  [D] Diff  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayLocalHistoryDialog overlays the Local History dialog centered on the viewport
+func (e *Editor) overlayLocalHistoryDialog(viewportContent string) string {
+	return e.localHistoryDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// restoreSnapshot closes the dialog and replaces the buffer with a
+// snapshot as one undoable edit
+func (e *Editor) restoreSnapshot(index int) {
+	e.mode = ModeNormal
+	if index < 0 || index >= len(e.localSnapshots) {
+		return
+	}
+	doc := e.activeDoc()
+	if doc.hexView {
+		e.statusbar.SetMessage("Hex view is read-only", "error")
+		return
+	}
+	snap := e.localSnapshots[index]
+	text, err := e.readSnapshot(snap.path)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot read snapshot: "+errorReason(err), "error")
+		return
+	}
+	if text == doc.buffer.String() {
+		e.statusbar.SetMessage("Snapshot matches the buffer", "info")
+		return
+	}
+	e.transformBuffer(func(string) string { return text })
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.statusbar.SetMessage("Restored the version saved "+snap.when.Format("Jan 2 15:04:05")+" - save to keep it, undo to go back", "success")
+}
+
+// diffSnapshot closes the dialog and opens the changes from a snapshot to
+// the buffer as a unified diff in a new buffer
+func (e *Editor) diffSnapshot(index int) {
+	e.mode = ModeNormal
+	if index < 0 || index >= len(e.localSnapshots) {
+		return
+	}
+	doc := e.activeDoc()
+	snap := e.localSnapshots[index]
+	text, err := e.readSnapshot(snap.path)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot read snapshot: "+errorReason(err), "error")
+		return
+	}
+	name := filepath.Base(doc.filename)
+	diff := unifiedDiff(name+" (saved "+snap.when.Format("2006-01-02 15:04:05")+")", name+" (buffer)",
+		strings.Split(text, "\n"), doc.buffer.Lines())
+	if diff == "" {
+		e.statusbar.SetMessage("Snapshot matches the buffer", "info")
+		return
+	}
+
+	count := len(e.documents)
+	e.doNewFile()
+	if len(e.documents) == count {
+		return // The buffer limit was reached
+	}
+	diffDoc := e.activeDoc()
+	diffDoc.buffer = NewBufferFromString(diff)
+	diffDoc.cursor = NewCursor(diffDoc.buffer)
+	diffDoc.highlighter = syntax.New(name + ".diff")
+	diffDoc.markSaved()
+	e.viewport.SetScrollY(0)
+	e.statusbar.SetMessage(fmt.Sprintf("Diff of %s since %s: +%d -%d lines", name, snap.when.Format("Jan 2 15:04:05"), snap.added, snap.removed), "info")
+}
+
+// handleLocalHistoryKey handles key events in the Local History dialog
+func (e *Editor) handleLocalHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := len(e.localSnapshots)
+	page := max(e.viewport.Height()-6, 1)
+	switch msg.Type {
+	case tea.KeyUp:
+		e.localHistoryIndex = max(e.localHistoryIndex-1, 0)
+	case tea.KeyDown:
+		e.localHistoryIndex = min(e.localHistoryIndex+1, count-1)
+	case tea.KeyPgUp:
+		e.localHistoryIndex = max(e.localHistoryIndex-page, 0)
+	case tea.KeyPgDown:
+		e.localHistoryIndex = min(e.localHistoryIndex+page, count-1)
+	case tea.KeyHome:
+		e.localHistoryIndex = 0
+	case tea.KeyEnd:
+		e.localHistoryIndex = count - 1
+	case tea.KeyEnter:
+		e.restoreSnapshot(e.localHistoryIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyRunes:
+		if strings.EqualFold(string(msg.Runes), "d") {
+			e.diffSnapshot(e.localHistoryIndex)
+		}
+	}
+	return e, nil
+}
+
+// handleLocalHistoryMouse selects snapshots on click and restores one on a second click
+func (e *Editor) handleLocalHistoryMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.localHistoryDialog().listMouse(msg, &e.localHistoryIndex) {
+	case listChoose:
+		e.restoreSnapshot(e.localHistoryIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
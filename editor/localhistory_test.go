@@ -0,0 +1,63 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLocalHistory(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := New()
+	e.statusbar.SetWidth(120)
+	e.config.Editor.LocalHistory = true
+	e.config.Editor.LocalHistoryMax = 2
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"two\n", "two\n", "three\n", "four\n"} {
+		e.transformBuffer(func(string) string { return text })
+		e.SaveFile()
+	}
+	paths, _ := snapshotFiles(path)
+	if len(paths) != 2 {
+		t.Fatalf("%d snapshots kept, want 2", len(paths))
+	}
+
+	e.transformBuffer(func(string) string { return "five\n" })
+	e.showLocalHistory()
+	if e.mode != ModeLocalHistory || len(e.localSnapshots) != 2 {
+		t.Fatalf("mode %v with %d snapshots", e.mode, len(e.localSnapshots))
+	}
+	if view := stripAnsi(e.View()); !strings.Contains(view, "buffer +1 -1 lines") {
+		t.Errorf("dialog doesn't compare with the buffer:\n%s", view)
+	}
+
+	// D opens the changes since the selected (older) snapshot in a new buffer
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	diff := e.activeDoc().buffer.String()
+	if len(e.documents) != 2 || !strings.Contains(diff, "-three\n+five\n") {
+		t.Fatalf("diff buffer:\n%s", diff)
+	}
+
+	// Enter puts the snapshot in the buffer as an undoable edit
+	e.switchToBuffer(0)
+	e.showLocalHistory()
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := e.activeDoc().buffer.String(); got != "four\n" {
+		t.Errorf("restored %q", got)
+	}
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "five\n" {
+		t.Errorf("after undo: %q", got)
+	}
+}
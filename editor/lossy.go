@@ -0,0 +1,103 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+// lossyListMax is the most unrepresentable characters listed before a save
+const lossyListMax = 8
+
+// lossyChoice is how a save handles characters its encoding can't represent
+type lossyChoice int
+
+const (
+	lossyAsk        lossyChoice = iota // Check first and ask
+	lossySubstitute                    // Write the encoding's substitute character
+	lossySkip                          // Leave them out of the file
+)
+
+// describeLossyChar formats a lost character for the lossy save dialog,
+// e.g. "Line 3, col 7: é (U+00E9)"
+func describeLossyChar(c enc.LossyChar) string {
+	code := fmt.Sprintf("U+%04X", c.Rune)
+	if unicode.IsPrint(c.Rune) {
+		code = string(c.Rune) + " (" + code + ")"
+	}
+	return fmt.Sprintf("Line %d, col %d: %s", c.Line+1, c.Col+1, code)
+}
+
+// checkEncodingLoss lists the characters the save encoding can't represent.
+// Returns true if the save can go ahead; otherwise a confirm dialog asks
+// whether to substitute or skip them, and resume redoes the save with that.
+func (e *Editor) checkEncodingLoss(resume func() bool) bool {
+	docEnc := e.activeDoc().encoding
+	if e.lossySave != lossyAsk || docEnc == nil || !docEnc.Supported {
+		return true
+	}
+	lost := enc.FindEncodingLoss([]byte(e.activeDoc().buffer.String()), docEnc)
+	if len(lost) == 0 {
+		return true
+	}
+	if e.skipQuietSave(fmt.Sprintf("%d characters not in %s", len(lost), docEnc.Name)) {
+		return false
+	}
+
+	header := fmt.Sprintf("%d characters cannot be represented in %s:", len(lost), docEnc.Name)
+	if len(lost) == 1 {
+		header = "1 character cannot be represented in " + docEnc.Name + ":"
+	}
+	lines := []string{header}
+	for i, c := range lost {
+		if i == lossyListMax-1 && len(lost) > lossyListMax {
+			lines = append(lines, fmt.Sprintf("and %d more", len(lost)-i))
+			break
+		}
+		lines = append(lines, describeLossyChar(c))
+	}
+
+	e.showConfirm(&ConfirmDialog{
+		Saving:  true,
+		Title:   "Lossy Encoding",
+		Message: strings.Join(lines, "\n"),
+		Buttons: []ConfirmButton{
+			{Label: "Substitute", Hotkey: 's'},
+			{Label: "Skip", Hotkey: 'k', Danger: true},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 2,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.lossySave = lossySubstitute
+			case 1:
+				e.lossySave = lossySkip
+			default:
+				e.statusbar.SetMessage("Save cancelled", "info")
+				return nil
+			}
+			resume()
+			e.lossySave = lossyAsk
+			return nil
+		},
+	})
+	return false
+}
+
+// encodeForSave converts content to the save encoding, handling characters
+// it can't represent as answered in the lossy save dialog
+func (e *Editor) encodeForSave(content string, docEnc *enc.Encoding) ([]byte, error) {
+	switch e.lossySave {
+	case lossySubstitute:
+		return enc.EncodeFromUTF8Lossy([]byte(content), docEnc), nil
+	case lossySkip:
+		return enc.EncodeFromUTF8(enc.DropUnsupported([]byte(content), docEnc), docEnc)
+	}
+	return enc.EncodeFromUTF8([]byte(content), docEnc)
+}
@@ -0,0 +1,89 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// minimapTooltipWidth is the widest the minimap hover tooltip gets
+const minimapTooltipWidth = 50
+
+// minimapLinesPerRow is how many visual lines one minimap row covers
+const minimapLinesPerRow = 4
+
+// minimapLineAt returns the line in the middle of the stretch of text the
+// minimap shows at screen column x and viewport row y
+func (e *Editor) minimapLineAt(x, y int) (int, bool) {
+	if !e.minimapRenderer.IsEnabled() || y < 0 || y >= e.viewport.Height() {
+		return 0, false
+	}
+	if col, ok := e.compositor.ColumnAt(x); !ok || col.Renderer != ui.ColumnRenderer(e.minimapRenderer) {
+		return 0, false
+	}
+	lines := e.activeDoc().buffer.Lines()
+	metrics := e.minimapRenderer.GetMetrics(e.viewport.Height(), e.buildRenderState())
+	visualLine := min(e.minimapRenderer.RowToVisualLine(y, metrics)+minimapLinesPerRow/2, max(metrics.TotalVisualLines-1, 0))
+	if !e.viewport.WordWrap() {
+		return min(visualLine, len(lines)-1), true
+	}
+	line, _ := e.viewport.VisualLineToBufferLine(lines, visualLine)
+	return line, true
+}
+
+// clickMinimap brings the clicked part of the file to the middle of the
+// view. With minimap_moves_cursor the cursor goes there too, keeping its
+// screen column.
+func (e *Editor) clickMinimap(line int) {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+	if e.config.Editor.MinimapMovesCursor {
+		x := e.viewport.VisualX(lines, doc.cursor.Line(), doc.cursor.Col())
+		doc.selection.Clear()
+		doc.cursor.SetPosition(line, ui.OffsetAtVisual(lines[line], x, e.viewport.TabWidth()))
+		doc.cursor.SetDesiredCol(x)
+		e.viewport.CenterCursor(lines, doc.cursor.Line(), doc.cursor.Col())
+		return
+	}
+	e.viewport.CenterCursor(lines, line, 0)
+}
+
+// hoverMinimap notes where the pointer is over the minimap, for the tooltip
+func (e *Editor) hoverMinimap(x, y int) {
+	e.minimapHover = -1
+	if line, ok := e.minimapLineAt(x, y); ok {
+		e.minimapHover = line
+		e.minimapHoverY = y
+	}
+}
+
+// overlayMinimapTooltip shows the line number and text of the line under
+// the pointer beside the minimap
+func (e *Editor) overlayMinimapTooltip(viewportContent string) string {
+	lines := e.activeDoc().buffer.Lines()
+	if e.minimapHover < 0 || e.minimapHover >= len(lines) || !e.minimapRenderer.IsEnabled() {
+		return viewportContent
+	}
+	preview := strings.TrimSpace(strings.ReplaceAll(lines[e.minimapHover], "\t", " "))
+	text := fmt.Sprintf(" Ln %d", e.minimapHover+1)
+	if preview != "" {
+		text += ": " + preview
+	}
+	text = runewidth.Truncate(text, minimapTooltipWidth-1, e.box.Ellipsis) + " "
+
+	scrollbarWidth := 0
+	if e.scrollbar.IsEnabled() {
+		scrollbarWidth = e.scrollbar.Width()
+	}
+	x := max(e.width-scrollbarWidth-ui.MinimapWidth()-runewidth.StringWidth(text), 0)
+	viewportLines := strings.Split(viewportContent, "\n")
+	if e.minimapHoverY < len(viewportLines) {
+		db := e.NewDialogBuilder(minimapTooltipWidth)
+		styled := db.themeUI.dialogStyle + text + db.themeUI.resetStyle
+		viewportLines[e.minimapHoverY] = overlayLineAt(styled, viewportLines[e.minimapHoverY], x)
+	}
+	return strings.Join(viewportLines, "\n")
+}
@@ -0,0 +1,60 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestMinimapClickAndHover(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 22})
+	if !e.minimapRenderer.IsEnabled() {
+		e.toggleMinimap()
+	}
+	doc := e.activeDoc()
+	var text []string
+	for i := range 400 {
+		text = append(text, fmt.Sprintf("line %d", i+1))
+	}
+	doc.buffer.Replace(0, 0, strings.Join(text, "\n"))
+	doc.cursor.SetPosition(0, 3)
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 22})
+	scrollbar := 0
+	if e.scrollbar.IsEnabled() {
+		scrollbar = e.scrollbar.Width()
+	}
+	minimapX := 80 - scrollbar - ui.MinimapWidth()
+
+	// Hovering names the line under the pointer
+	e.Update(tea.MouseMsg{X: minimapX + 1, Y: 1 + 10, Button: tea.MouseButtonNone, Action: tea.MouseActionMotion})
+	if view := stripAnsi(e.View()); !strings.Contains(view, "Ln 43: line 43") {
+		t.Errorf("hover tooltip missing:\n%s", view)
+	}
+	e.Update(tea.MouseMsg{X: 10, Y: 1 + 10, Button: tea.MouseButtonNone, Action: tea.MouseActionMotion})
+	if e.minimapHover != -1 {
+		t.Error("moving off the minimap should hide the tooltip")
+	}
+
+	// A click centers the view there and leaves the cursor alone
+	e.Update(tea.MouseMsg{X: minimapX + 1, Y: 1 + 10, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if got, want := e.viewport.ScrollY(), 42-e.viewport.Height()/2; got != want {
+		t.Errorf("ScrollY = %d, want %d to center line 43", got, want)
+	}
+	if doc.cursor.Line() != 0 || doc.cursor.Col() != 3 {
+		t.Errorf("cursor moved to %d:%d", doc.cursor.Line(), doc.cursor.Col())
+	}
+
+	// With minimap_moves_cursor the cursor follows, keeping its column
+	e.config.Editor.MinimapMovesCursor = true
+	want, _ := e.minimapLineAt(minimapX+1, 15)
+	e.Update(tea.MouseMsg{X: minimapX + 1, Y: 1 + 15, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	if doc.cursor.Line() != want || want <= 42 || doc.cursor.Col() != 3 {
+		t.Errorf("cursor at %d:%d, want %d:3", doc.cursor.Line(), doc.cursor.Col(), want)
+	}
+}
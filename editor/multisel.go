@@ -0,0 +1,220 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cornish/textivus-editor/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// multiRange is one selection of a multi-selection as byte offsets [start, end).
+// A range with start == end is a bare caret.
+type multiRange struct {
+	start, end int
+}
+
+// multiEdit replaces the byte range [start, end) of the buffer with repl
+type multiEdit struct {
+	start, end int
+	repl       string
+	keep       bool // Keep the replacement selected instead of collapsing to a caret
+}
+
+// selectAllMatches turns every occurrence of the find query (or the current
+// selection when the query is empty) into a selection
+func (e *Editor) selectAllMatches() {
+	doc := e.activeDoc()
+	query := e.findQuery
+	if query == "" && doc.selection.Active && !doc.selection.IsEmpty() {
+		query = doc.selection.GetText(doc.buffer)
+	}
+
+	e.mode = ModeNormal
+	e.findActive = false
+	e.updateViewportSize()
+
+	if query == "" {
+		e.statusbar.SetMessage("No search term", "error")
+		return
+	}
+
+	ranges := e.findMatches(doc.buffer.String(), query)
+	if len(ranges) == 0 {
+		e.statusbar.SetMessage("Not found", "error")
+		return
+	}
+
+	doc.multiSel = ranges
+	doc.snippet = nil
+	doc.selection.Clear()
+	doc.cursor.SetByteOffset(ranges[0].end)
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.statusbar.SetMessage(fmt.Sprintf("%d matches selected - type to replace, Esc to finish", len(ranges)), "info")
+}
+
+// clearMultiSelection drops back to the single primary cursor
+func (e *Editor) clearMultiSelection() {
+	e.activeDoc().multiSel = nil
+	e.activeDoc().snippet = nil
+}
+
+// handleMultiSelKey applies edits to every range of an active multi-selection.
+// Returns (true, cmd) if the key was consumed; any other key ends the
+// multi-selection and falls through to the regular key handling.
+func (e *Editor) handleMultiSelKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	doc := e.activeDoc()
+	switch msg.Type {
+	case tea.KeyEsc:
+		e.clearMultiSelection()
+		return true, nil
+	case tea.KeyRunes:
+		if msg.Alt && len(msg.Runes) == 1 {
+			switch msg.Runes[0] {
+			case 'u':
+				e.multiApply(func(r multiRange) multiEdit {
+					return multiEdit{r.start, r.end, strings.ToUpper(doc.buffer.Substring(r.start, r.end)), true}
+				})
+				return true, nil
+			case 'l':
+				e.multiApply(func(r multiRange) multiEdit {
+					return multiEdit{r.start, r.end, strings.ToLower(doc.buffer.Substring(r.start, r.end)), true}
+				})
+				return true, nil
+			}
+			break
+		}
+		e.multiInsert(string(msg.Runes))
+		return true, nil
+	case tea.KeySpace:
+		e.multiInsert(" ")
+		return true, nil
+	case tea.KeyTab:
+		if doc.snippet != nil {
+			e.nextSnippetStop(1)
+			return true, nil
+		}
+		e.multiInsert(e.getIndentString())
+		return true, nil
+	case tea.KeyShiftTab:
+		if doc.snippet != nil {
+			e.nextSnippetStop(-1)
+			return true, nil
+		}
+	case tea.KeyEnter:
+		e.multiInsert("\n")
+		return true, nil
+	case tea.KeyBackspace:
+		e.multiApply(func(r multiRange) multiEdit {
+			if r.start == r.end {
+				return multiEdit{start: r.start - doc.buffer.graphemeBefore(r.start), end: r.end}
+			}
+			return multiEdit{start: r.start, end: r.end}
+		})
+		return true, nil
+	case tea.KeyDelete:
+		e.multiApply(func(r multiRange) multiEdit {
+			if r.start == r.end && r.end < doc.buffer.Length() {
+				return multiEdit{start: r.start, end: r.end + doc.buffer.graphemeAfter(r.end)}
+			}
+			return multiEdit{start: r.start, end: r.end}
+		})
+		return true, nil
+	}
+
+	e.clearMultiSelection()
+	return false, nil
+}
+
+// multiInsert replaces every range with text, leaving a caret after each insertion
+func (e *Editor) multiInsert(text string) {
+	e.multiApply(func(r multiRange) multiEdit {
+		return multiEdit{start: r.start, end: r.end, repl: text}
+	})
+}
+
+// multiApply performs one edit per range as a single undoable change
+func (e *Editor) multiApply(edit func(r multiRange) multiEdit) {
+	if e.refuseEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	if len(doc.multiSel) == 0 {
+		return
+	}
+
+	// Build edits in document order, never letting one overlap the previous
+	edits := make([]multiEdit, 0, len(doc.multiSel))
+	prevEnd := 0
+	for _, r := range doc.multiSel {
+		ed := edit(r)
+		if ed.start < prevEnd {
+			ed.start = prevEnd
+		}
+		if ed.end < ed.start {
+			ed.end = ed.start
+		}
+		edits = append(edits, ed)
+		prevEnd = ed.end
+	}
+
+	spanStart := edits[0].start
+	spanEnd := edits[len(edits)-1].end
+	entry := &UndoEntry{
+		Position:     spanStart,
+		Deleted:      doc.buffer.Substring(spanStart, spanEnd),
+		CursorBefore: doc.cursor.ByteOffset(),
+	}
+
+	// Apply back to front so earlier offsets stay valid
+	for i := len(edits) - 1; i >= 0; i-- {
+		doc.buffer.Replace(edits[i].start, edits[i].end, edits[i].repl)
+	}
+
+	// Map the ranges into the edited text, merging carets that collapsed together
+	ranges := make([]multiRange, 0, len(edits))
+	shift := 0
+	for _, ed := range edits {
+		start := ed.start + shift
+		end := start + len(ed.repl)
+		shift += len(ed.repl) - (ed.end - ed.start)
+		r := multiRange{start: end, end: end}
+		if ed.keep {
+			r.start = start
+		}
+		if n := len(ranges); n > 0 && ranges[n-1] == r {
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+
+	entry.Inserted = doc.buffer.Substring(spanStart, spanEnd+shift)
+	doc.multiSel = ranges
+	if doc.snippet != nil {
+		doc.snippet.remap(edits, doc.buffer.Length())
+	}
+	doc.cursor.SetByteOffset(ranges[0].end)
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	if entry.Deleted != entry.Inserted {
+		doc.undoStack.Push(entry)
+		doc.modified = true
+	}
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// multiSelectionMap converts the multi-selection into per-line column ranges for rendering
+func (e *Editor) multiSelectionMap() map[int][]ui.SelectionRange {
+	doc := e.activeDoc()
+	if len(doc.multiSel) == 0 {
+		return nil
+	}
+	lines := doc.buffer.Lines()
+	m := make(map[int][]ui.SelectionRange)
+	for _, r := range doc.multiSel {
+		lineRanges(doc.buffer, lines, r.start, r.end, func(line int, sr ui.SelectionRange) {
+			m[line] = append(m[line], sr)
+		})
+	}
+	return m
+}
@@ -0,0 +1,64 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newMultiSelTestEditor creates an editor with every "foo" in content selected
+func newMultiSelTestEditor(t *testing.T, content string) *Editor {
+	t.Helper()
+	e := New()
+	buf := NewBufferFromString(content)
+	doc := e.activeDoc()
+	doc.buffer = buf
+	doc.cursor = NewCursor(buf)
+	e.findQuery = "foo"
+	e.selectAllMatches()
+	return e
+}
+
+func TestSelectAllMatchesTypeReplaces(t *testing.T) {
+	e := newMultiSelTestEditor(t, "foo bar foo\nfoo")
+	if got := len(e.activeDoc().multiSel); got != 3 {
+		t.Fatalf("selected %d matches, want 3", got)
+	}
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("qu")})
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if got := e.activeDoc().buffer.String(); got != "qux bar qux\nqux" {
+		t.Errorf("after typing, buffer = %q", got)
+	}
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got := e.activeDoc().buffer.String(); got != "qu bar qu\nqu" {
+		t.Errorf("after backspace, buffer = %q", got)
+	}
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.activeDoc().multiSel != nil {
+		t.Errorf("Esc should end the multi-selection")
+	}
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "qux bar qux\nqux" {
+		t.Errorf("undo should revert one multi-edit, buffer = %q", got)
+	}
+}
+
+func TestSelectAllMatchesCaseTransform(t *testing.T) {
+	e := newMultiSelTestEditor(t, "foo, Foo, foo")
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}, Alt: true})
+	if got := e.activeDoc().buffer.String(); got != "FOO, Foo, FOO" {
+		t.Errorf("after alt+u, buffer = %q", got)
+	}
+	if got := len(e.activeDoc().multiSel); got != 2 {
+		t.Errorf("case transform should keep %d selections, have %d", 2, got)
+	}
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyDelete})
+	if got := e.activeDoc().buffer.String(); got != ", Foo, " {
+		t.Errorf("after delete, buffer = %q", got)
+	}
+}
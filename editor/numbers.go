@@ -0,0 +1,126 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// numberSpan is an integer literal found on a line
+type numberSpan struct {
+	start, end int  // Byte range within the line, including any sign or 0x prefix
+	hex        bool // 0x-prefixed hexadecimal
+}
+
+// isHexDigit reports whether c is a hexadecimal digit
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// findNumber returns the first number on line that ends after col, so the
+// cursor can sit on the number or anywhere before it, as in vi
+func findNumber(line string, col int) (numberSpan, bool) {
+	for i := 0; i < len(line); {
+		if line[i] < '0' || line[i] > '9' {
+			i++
+			continue
+		}
+		span := numberSpan{start: i}
+		if line[i] == '0' && i+2 < len(line) && (line[i+1] == 'x' || line[i+1] == 'X') && isHexDigit(line[i+2]) {
+			span.hex = true
+			i += 2
+			for i < len(line) && isHexDigit(line[i]) {
+				i++
+			}
+		} else {
+			for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+				i++
+			}
+			if span.start > 0 && line[span.start-1] == '-' {
+				span.start--
+			}
+		}
+		span.end = i
+		if span.end > col {
+			return span, true
+		}
+	}
+	return numberSpan{}, false
+}
+
+// stepNumber adds delta to the number text, keeping zero padding and, for
+// hex, the digit case
+func stepNumber(text string, hex bool, delta int64) (string, error) {
+	if hex {
+		digits := text[2:]
+		n, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil {
+			return "", err
+		}
+		out := strconv.FormatUint(n+uint64(delta), 16)
+		if strings.ToLower(digits) != digits {
+			out = strings.ToUpper(out)
+		}
+		if pad := len(digits) - len(out); pad > 0 {
+			out = strings.Repeat("0", pad) + out
+		}
+		return text[:2] + out, nil
+	}
+
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	next := n + delta
+	if (delta > 0 && next < n) || (delta < 0 && next > n) {
+		return "", strconv.ErrRange
+	}
+	digits := strings.TrimPrefix(text, "-")
+	if len(digits) > 1 && digits[0] == '0' {
+		// Keep the field width of zero-padded numbers ("007" -> "008")
+		width := len(digits)
+		if next < 0 {
+			return fmt.Sprintf("-%0*d", width, -next), nil
+		}
+		return fmt.Sprintf("%0*d", width, next), nil
+	}
+	return strconv.FormatInt(next, 10), nil
+}
+
+// incrementNumber adds delta to the number under or after the cursor on the
+// current line and leaves the cursor on its last digit
+func (e *Editor) incrementNumber(delta int64) {
+	if e.refuseEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	line := doc.cursor.Line()
+	lineStart := doc.buffer.LineStartOffset(line)
+	text := doc.buffer.Substring(lineStart, doc.buffer.LineEndOffset(line))
+
+	span, ok := findNumber(text, doc.cursor.ByteOffset()-lineStart)
+	if !ok {
+		e.statusbar.SetMessage("No number under cursor", "info")
+		return
+	}
+	old := text[span.start:span.end]
+	repl, err := stepNumber(old, span.hex, delta)
+	if err != nil {
+		e.statusbar.SetMessage("Number out of range", "error")
+		return
+	}
+
+	start := lineStart + span.start
+	entry := &UndoEntry{
+		Position:     start,
+		Deleted:      old,
+		Inserted:     repl,
+		CursorBefore: doc.cursor.ByteOffset(),
+	}
+	doc.buffer.Replace(start, start+len(old), repl)
+	doc.selection.Clear()
+	doc.cursor.SetByteOffset(start + len(repl) - 1)
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+}
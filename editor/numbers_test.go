@@ -0,0 +1,78 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStepNumber(t *testing.T) {
+	tests := []struct {
+		text  string
+		hex   bool
+		delta int64
+		want  string
+	}{
+		{"41", false, 1, "42"},
+		{"0", false, -1, "-1"},
+		{"-5", false, 10, "5"},
+		{"007", false, 1, "008"},
+		{"099", false, 1, "100"},
+		{"0xff", true, 1, "0x100"},
+		{"0x0A", true, 6, "0x10"},
+		{"0x00ff", true, 1, "0x0100"},
+		{"0xAB", true, 1, "0xAC"},
+	}
+	for _, tt := range tests {
+		got, err := stepNumber(tt.text, tt.hex, tt.delta)
+		if err != nil || got != tt.want {
+			t.Errorf("stepNumber(%q, %d) = %q, %v, want %q", tt.text, tt.delta, got, err, tt.want)
+		}
+	}
+	if _, err := stepNumber("9223372036854775807", false, 1); err == nil {
+		t.Error("stepNumber() should reject overflow")
+	}
+}
+
+func TestIncrementNumber(t *testing.T) {
+	tests := []struct {
+		content string
+		offset  int
+		delta   int64
+		want    string
+		cursor  int
+	}{
+		{"port = 8080", 0, 1, "port = 8081", 10},   // Number after the cursor
+		{"port = 8080", 9, -1, "port = 8079", 10},  // Cursor inside the number
+		{"x = -3, y = 4", 4, 5, "x = 2, y = 4", 4}, // Sign is part of the number
+		{"a1 b2", 2, 1, "a1 b3", 4},                // Skips numbers before the cursor
+		{"color: 0x1f;", 8, 1, "color: 0x20;", 10}, // Cursor on the x of a hex literal
+	}
+	for _, tt := range tests {
+		e := newMarkupTestEditor("test.conf", tt.content, tt.offset)
+		e.incrementNumber(tt.delta)
+		doc := e.activeDoc()
+		if got := doc.buffer.String(); got != tt.want || doc.cursor.ByteOffset() != tt.cursor {
+			t.Errorf("%q: buffer = %q cursor %d, want %q cursor %d", tt.content, got, doc.cursor.ByteOffset(), tt.want, tt.cursor)
+		}
+	}
+
+	e := newMarkupTestEditor("test.conf", "none here\n42", 0)
+	e.incrementNumber(1)
+	if got := e.activeDoc().buffer.String(); got != "none here\n42" {
+		t.Errorf("buffer = %q, want numbers on other lines left alone", got)
+	}
+}
+
+func TestViIncrementCount(t *testing.T) {
+	e := newViTestEditor("retries: 3")
+	viKeys(e, "10")
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlA})
+	if got := e.activeDoc().buffer.String(); got != "retries: 13" {
+		t.Errorf("after 10 Ctrl+A, buffer = %q", got)
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if got := e.activeDoc().buffer.String(); got != "retries: 12" {
+		t.Errorf("after Ctrl+X, buffer = %q", got)
+	}
+}
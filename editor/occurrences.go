@@ -0,0 +1,118 @@
+package editor
+
+import (
+	"unicode"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// highlightWordEnabled reports whether other occurrences of the word under
+// the cursor are highlighted
+func (e *Editor) highlightWordEnabled() bool {
+	return e.config != nil && e.config.Editor.HighlightWord
+}
+
+// wordUnderCursor returns the identifier the cursor is on or just after and
+// its rune columns on the cursor line. ok is false off identifiers, so
+// plain numbers and punctuation are never highlighted.
+func (e *Editor) wordUnderCursor(lines []string) (word string, start, end int, ok bool) {
+	doc := e.activeDoc()
+	line := doc.cursor.Line()
+	if line >= len(lines) {
+		return "", 0, 0, false
+	}
+	col := runeColumn(lines[line], doc.cursor.Col())
+	runes := []rune(lines[line])
+	start, end = col, col
+	for start > 0 && isWordChar(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWordChar(runes[end]) {
+		end++
+	}
+	if start == end || unicode.IsDigit(runes[start]) {
+		return "", 0, 0, false
+	}
+	return string(runes[start:end]), start, end, true
+}
+
+// occurrenceMap finds the other whole-word occurrences of the word under
+// the cursor on the visible lines. It is nil when the option is off, while
+// a selection is shown, or away from the editing area.
+func (e *Editor) occurrenceMap(lines []string) map[int][]ui.SelectionRange {
+	doc := e.activeDoc()
+	if !e.highlightWordEnabled() || e.mode != ModeNormal || doc.selection.Active || len(doc.multiSel) > 0 {
+		return nil
+	}
+	word, wordStart, _, ok := e.wordUnderCursor(lines)
+	if !ok {
+		return nil
+	}
+	cursorLine := doc.cursor.Line()
+	target := []rune(word)
+
+	first, last := e.visibleLineRange(lines)
+	var occurrences map[int][]ui.SelectionRange
+	for i := first; i < last; i++ {
+		runes := []rune(lines[i])
+		for col := 0; col+len(target) <= len(runes); col++ {
+			if (col > 0 && isWordChar(runes[col-1])) || !hasRunesAt(runes, target, col) {
+				continue
+			}
+			end := col + len(target)
+			if end < len(runes) && isWordChar(runes[end]) {
+				continue
+			}
+			if i != cursorLine || col != wordStart {
+				if occurrences == nil {
+					occurrences = make(map[int][]ui.SelectionRange)
+				}
+				occurrences[i] = append(occurrences[i], ui.SelectionRange{Start: col, End: end})
+			}
+			col = end - 1
+		}
+	}
+	return occurrences
+}
+
+// visibleLineRange returns the buffer lines that can be on screen. Each line
+// takes at least one row, so a screenful of lines from the first visible one
+// covers the viewport.
+func (e *Editor) visibleLineRange(lines []string) (first, last int) {
+	first = e.viewport.ScrollY()
+	if e.viewport.WordWrap() {
+		first, _ = e.viewport.VisualLineToBufferLine(lines, first)
+	}
+	return first, min(first+e.viewport.Height(), len(lines))
+}
+
+// hasRunesAt reports whether target appears in runes at col
+func hasRunesAt(runes, target []rune, col int) bool {
+	for j, r := range target {
+		if runes[col+j] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// toggleHighlightWord turns the word-under-cursor highlight on or off
+func (e *Editor) toggleHighlightWord() {
+	e.config.Editor.HighlightWord = !e.config.Editor.HighlightWord
+	e.updateHighlightWordLabel()
+	if e.config.Editor.HighlightWord {
+		e.statusbar.SetMessage("Highlight occurrences enabled", "info")
+	} else {
+		e.statusbar.SetMessage("Highlight occurrences disabled", "info")
+	}
+	e.saveConfig()
+}
+
+// updateHighlightWordLabel syncs the Options menu checkbox with the config
+func (e *Editor) updateHighlightWordLabel() {
+	if e.highlightWordEnabled() {
+		e.menubar.SetItemLabel(ui.ActionHighlightWord, "[x] Highlight Occurrences")
+	} else {
+		e.menubar.SetItemLabel(ui.ActionHighlightWord, "[ ] Highlight Occurrences")
+	}
+}
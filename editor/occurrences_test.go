@@ -0,0 +1,31 @@
+package editor
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestOccurrenceMap(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Toggling saves the config
+	content := "count := 0\ncount++ // recount\nfmt.Println(count)\n"
+	e := newMarkupTestEditor("main.go", content, 2) // Inside the first "count"
+	e.viewport.SetSize(80, 10)
+	lines := e.activeDoc().buffer.Lines()
+
+	got := fmt.Sprint(e.occurrenceMap(lines))
+	want := "map[1:[{0 5}] 2:[{12 17}]]" // Not "recount", not the word itself
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+
+	e.activeDoc().cursor.SetByteOffset(9) // On the number 0
+	if m := e.occurrenceMap(lines); m != nil {
+		t.Errorf("numbers should not be highlighted, got %v", m)
+	}
+
+	e.activeDoc().cursor.SetByteOffset(5) // Just after "count"
+	e.toggleHighlightWord()
+	if m := e.occurrenceMap(lines); m != nil {
+		t.Errorf("toggled off but got %v", m)
+	}
+}
@@ -0,0 +1,89 @@
+package editor
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// osc52Timeout is how long a paste waits for the terminal to answer an
+// OSC52 clipboard query before falling back to the internal clipboard
+const osc52Timeout = time.Second
+
+// osc52TimeoutMsg gives up on an unanswered OSC52 clipboard query
+type osc52TimeoutMsg struct {
+	seq int
+}
+
+// osc52TimeoutCmd returns a command that sends an osc52TimeoutMsg after the timeout
+func osc52TimeoutCmd(seq int) tea.Cmd {
+	return tea.Tick(osc52Timeout, func(t time.Time) tea.Msg {
+		return osc52TimeoutMsg{seq: seq}
+	})
+}
+
+// paste inserts the clipboard at the cursor. When the terminal is the
+// system clipboard and OSC52 reads are enabled, the terminal is asked first
+// and the paste happens once its reply arrives.
+func (e *Editor) paste() tea.Cmd {
+	if e.refuseEdit() {
+		return nil
+	}
+	if e.clipboard.QueryOSC52() {
+		e.osc52Seq++
+		e.osc52Pending = true
+		return osc52TimeoutCmd(e.osc52Seq)
+	}
+	e.pasteClipboard()
+	return nil
+}
+
+// pasteClipboard inserts the clipboard's current text at the cursor
+func (e *Editor) pasteClipboard() {
+	text, err := e.clipboard.Paste()
+	if err != nil || text == "" {
+		return
+	}
+
+	e.insertText(text)
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// handleOSC52Key picks a terminal's OSC52 reply out of the key stream.
+// Bubbletea doesn't parse OSC replies, so ESC ] 52;c;<base64> BEL arrives as
+// an alt+"]52;c;..." key (possibly split over several keys) followed by
+// ctrl+g, or alt+\ for terminals that end it with ST. Returns true if the
+// key was part of a reply.
+func (e *Editor) handleOSC52Key(msg tea.KeyMsg) bool {
+	if msg.Type == tea.KeyRunes && msg.Alt && strings.HasPrefix(string(msg.Runes), "]52;") {
+		// Replies are swallowed even after a timeout so they never land in the text
+		e.osc52Reply = &strings.Builder{}
+		e.osc52Reply.WriteString(string(msg.Runes[1:]))
+		return true
+	}
+	if e.osc52Reply == nil {
+		return false
+	}
+
+	switch {
+	case msg.Type == tea.KeyRunes && !msg.Alt:
+		e.osc52Reply.WriteString(string(msg.Runes))
+		return true
+	case msg.Type == tea.KeyCtrlG, msg.Type == tea.KeyRunes && msg.Alt && string(msg.Runes) == "\\":
+		reply := e.osc52Reply.String()
+		e.osc52Reply = nil
+		if _, err := e.clipboard.ReceiveOSC52(reply); err != nil {
+			e.statusbar.SetMessage("Clipboard: "+err.Error(), "error")
+		}
+		if e.osc52Pending {
+			e.osc52Pending = false
+			e.pasteClipboard()
+		}
+		return true
+	}
+
+	// Anything else means the reply was cut short
+	e.osc52Reply = nil
+	return false
+}
@@ -0,0 +1,33 @@
+package editor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cornish/textivus-editor/clipboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOSC52PasteReply(t *testing.T) {
+	e := New()
+	e.clipboard = clipboard.New(&bytes.Buffer{})
+	e.clipboard.SetProvider(clipboard.ProviderOSC52)
+	e.clipboard.SetOSC52Read(true)
+
+	if cmd := e.paste(); cmd == nil || !e.osc52Pending {
+		t.Fatal("paste() should wait for the terminal's reply")
+	}
+
+	// ESC ] 52;c;aGVsbG8= BEL, as Bubbletea splits it into keys
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Alt: true, Runes: []rune("]52;c;aGVs")})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("bG8=")})
+	e.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+
+	if got := e.activeDoc().buffer.String(); got != "hello" {
+		t.Errorf("buffer = %q, want %q", got, "hello")
+	}
+	if e.osc52Pending || e.osc52Reply != nil {
+		t.Error("reply state not cleared")
+	}
+}
@@ -0,0 +1,108 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// preflightSave checks that size bytes can be written to path before the
+// buffer is encoded and backups are rotated, so a doomed save fails early
+// and leaves everything as it was
+func preflightSave(path string, size int64, backup bool) error {
+	dir := filepath.Dir(path)
+	dirInfo, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("folder %s does not exist", dir)
+	} else if err != nil {
+		return err
+	}
+	if !dirInfo.IsDir() {
+		return fmt.Errorf("%s is not a folder", dir)
+	}
+
+	var oldSize int64
+	info, err := os.Stat(path)
+	exists := err == nil
+	if exists {
+		if info.IsDir() {
+			return fmt.Errorf("%s is a folder", filepath.Base(path))
+		}
+		oldSize = info.Size()
+		if !fileWritable(path) {
+			if ownedByRoot(info) {
+				return fmt.Errorf("%s is owned by root and read-only for you", filepath.Base(path))
+			}
+			return fmt.Errorf("%s is read-only", filepath.Base(path))
+		}
+	}
+
+	// New files and backup copies are created in the folder itself
+	if !exists || backup {
+		if err := dirWritable(dir); err != nil {
+			if ownedByRoot(dirInfo) {
+				return fmt.Errorf("folder %s is owned by root", dir)
+			}
+			return fmt.Errorf("folder %s is not writable", dir)
+		}
+	}
+
+	// Overwriting frees the old contents unless a backup keeps a copy
+	need := size
+	if !backup {
+		need -= oldSize
+	}
+	if free, ok := freeSpace(dir); ok && need > 0 && uint64(need) > free {
+		return fmt.Errorf("not enough disk space (%s needed, %s free)", formatFileSize(need), formatFileSize(int64(free)))
+	}
+	return nil
+}
+
+// preflightActiveSave runs preflightSave for the active buffer. The size is
+// the UTF-8 length, which is close enough for the encodings we write.
+// Backups kept in backup_dir don't need the file's folder to be writable.
+func (e *Editor) preflightActiveSave() error {
+	doc := e.activeDoc()
+	backup := e.config != nil && e.config.Editor.BackupCount > 0 && e.backupDir() == ""
+	return preflightSave(doc.filename, int64(doc.buffer.Length()), backup)
+}
+
+// checkSavePreflight reports problems that would make the save fail.
+// Returns true if the save can go ahead; otherwise a dialog offers to save
+// elsewhere or to retry (calling retry) once the problem is fixed.
+func (e *Editor) checkSavePreflight(retry func() bool) bool {
+	err := e.preflightActiveSave()
+	if err == nil {
+		return true
+	}
+	if e.skipQuietSave(err.Error()) {
+		return false
+	}
+
+	e.showConfirm(&ConfirmDialog{
+		Saving:  true,
+		Title:   "Can't Save",
+		Message: "Cannot save " + filepath.Base(e.activeDoc().filename) + ":\n" + err.Error(),
+		Buttons: []ConfirmButton{
+			{Label: "Save As...", Hotkey: 'a'},
+			{Label: "Retry", Hotkey: 'r'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 0,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.showSaveAs()
+			case 1:
+				retry()
+			default:
+				e.statusbar.SetMessage("Save cancelled", "info")
+			}
+			return nil
+		},
+	})
+	return false
+}
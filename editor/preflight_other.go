@@ -0,0 +1,43 @@
+//go:build !unix
+
+package editor
+
+import "os"
+
+// dirWritable reports whether the current user may create files in dir.
+// Without access(2) this is left to the write itself.
+func dirWritable(dir string) error {
+	return nil
+}
+
+// fileWritable reports whether path exists and isn't marked read-only.
+// Without access(2) only the permission bits are checked.
+func fileWritable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0200 != 0
+}
+
+// freeSpace is not available on this platform
+func freeSpace(dir string) (free uint64, ok bool) {
+	return 0, false
+}
+
+// ownedByRoot is not available on this platform
+func ownedByRoot(info os.FileInfo) bool {
+	return false
+}
+
+// hardLinked is not available on this platform
+func hardLinked(info os.FileInfo) bool {
+	return false
+}
+
+// keepOwner is not needed on this platform
+func keepOwner(path string, info os.FileInfo) bool {
+	return true
+}
+
+// diskFullReason is not available on this platform
+func diskFullReason(err error) string {
+	return ""
+}
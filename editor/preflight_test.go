@@ -0,0 +1,66 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPreflightSave(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.txt")
+	os.WriteFile(existing, []byte("hello"), 0644)
+
+	if err := preflightSave(existing, 10, true); err != nil {
+		t.Errorf("preflightSave(existing) = %v", err)
+	}
+	if err := preflightSave(filepath.Join(dir, "new.txt"), 10, false); err != nil {
+		t.Errorf("preflightSave(new) = %v", err)
+	}
+	if err := preflightSave(filepath.Join(dir, "missing", "new.txt"), 10, false); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("preflightSave(missing folder) = %v", err)
+	}
+	if err := preflightSave(dir, 10, false); err == nil || !strings.Contains(err.Error(), "is a folder") {
+		t.Errorf("preflightSave(folder) = %v", err)
+	}
+	if _, ok := freeSpace(dir); ok {
+		if err := preflightSave(existing, 1<<62, false); err == nil || !strings.Contains(err.Error(), "disk space") {
+			t.Errorf("preflightSave(huge) = %v", err)
+		}
+	}
+}
+
+func TestSavePreflightDialog(t *testing.T) {
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome) // Saving updates the recent lists
+
+	dir := filepath.Join(t.TempDir(), "later")
+	path := filepath.Join(dir, "draft.txt")
+
+	e := New()
+	e.insertText("draft")
+	e.activeDoc().filename = path
+	if e.SaveFile() {
+		t.Fatal("SaveFile() succeeded into a missing folder")
+	}
+	if e.mode != ModeConfirm || e.confirm.Title != "Can't Save" {
+		t.Fatalf("mode = %v, want the Can't Save dialog", e.mode)
+	}
+
+	// Fix the problem, then retry
+	os.Mkdir(dir, 0755)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if data, err := os.ReadFile(path); err != nil || string(data) != "draft" {
+		t.Errorf("after retry, file = %q, %v", data, err)
+	}
+	if e.activeDoc().modified {
+		t.Error("buffer still modified after retry")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build unix
+
+package editor
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// dirWritable reports whether the current user may create files in dir
+func dirWritable(dir string) error {
+	return unix.Access(dir, unix.W_OK)
+}
+
+// fileWritable reports whether the current user may write to path. It asks
+// rather than opening the file, which would wake file watchers and can
+// block on special files.
+func fileWritable(path string) bool {
+	return unix.Access(path, unix.W_OK) == nil
+}
+
+// freeSpace returns the bytes available to unprivileged users on dir's
+// filesystem. ok is false if it cannot be determined.
+func freeSpace(dir string) (free uint64, ok bool) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, false
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), true
+}
+
+// ownedByRoot reports whether info belongs to root while we are not root
+func ownedByRoot(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Uid == 0 && os.Geteuid() != 0
+}
+
+// hardLinked reports whether the file has other names that replacing it
+// by rename would split off
+func hardLinked(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Nlink > 1
+}
+
+// keepOwner gives the file at path the owner and group of info, reporting
+// whether it has them. Only root can give files away, so others saving a
+// file they don't own get false.
+func keepOwner(path string, info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true
+	}
+	return os.Lchown(path, int(st.Uid), int(st.Gid)) == nil
+}
+
+// diskFullReason names a write error caused by a full disk or an exceeded
+// quota, or returns ""
+func diskFullReason(err error) string {
+	switch {
+	case errors.Is(err, unix.ENOSPC):
+		return "the disk is full"
+	case errors.Is(err, unix.EDQUOT):
+		return "your disk quota is used up"
+	}
+	return ""
+}
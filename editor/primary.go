@@ -0,0 +1,80 @@
+package editor
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// primarySyncDelay is how long a selection has to stay put before it
+// becomes the primary selection, so dragging or Shift+arrows don't run the
+// clipboard tool at every step
+const primarySyncDelay = 250 * time.Millisecond
+
+// primarySyncMsg makes the selection the primary selection if it hasn't
+// changed since the sync was scheduled
+type primarySyncMsg struct {
+	seq int
+}
+
+// primarySel is a selection handed to (or waiting for) the primary selection
+type primarySel struct {
+	doc        *Document
+	start, end int
+}
+
+// primaryEnabled reports whether selections set the primary selection and
+// middle-click pastes it: the option is on and the system has one
+func (e *Editor) primaryEnabled() bool {
+	return e.config != nil && e.config.Editor.PrimarySelection && e.clipboard.HasPrimary()
+}
+
+// schedulePrimarySync starts the wait to hand a changed selection to the
+// primary selection. Clearing the selection leaves the primary alone, as
+// in X11.
+func (e *Editor) schedulePrimarySync() tea.Cmd {
+	if !e.primaryEnabled() {
+		return nil
+	}
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() {
+		return nil
+	}
+	start, end := doc.selection.Normalize()
+	sel := primarySel{doc: doc, start: start, end: end}
+	if sel == e.primarySel {
+		return nil
+	}
+	e.primarySel = sel
+	e.primarySeq++
+	seq := e.primarySeq
+	return tea.Tick(primarySyncDelay, func(time.Time) tea.Msg {
+		return primarySyncMsg{seq: seq}
+	})
+}
+
+// syncPrimary makes the selection the primary selection once it has
+// settled
+func (e *Editor) syncPrimary(msg primarySyncMsg) {
+	doc := e.activeDoc()
+	if msg.seq != e.primarySeq || doc != e.primarySel.doc || !doc.selection.Active {
+		return
+	}
+	e.clipboard.SetPrimary(doc.selection.GetText(doc.buffer))
+}
+
+// pastePrimaryAt pastes the primary selection where the middle button was
+// pressed, at column x of viewport row y
+func (e *Editor) pastePrimaryAt(x, y int) {
+	text, err := e.clipboard.PastePrimary()
+	if err != nil || text == "" {
+		return
+	}
+	doc := e.activeDoc()
+	e.clearMultiSelection()
+	line, col := e.viewport.PositionFromClickWrapped(doc.buffer.Lines(), x, y)
+	doc.cursor.SetPosition(line, col)
+	doc.selection.Clear()
+	e.insertText(text)
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
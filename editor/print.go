@@ -0,0 +1,301 @@
+package editor
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// printWidth is the page width, in columns, that headers are laid out for
+const printWidth = 80
+
+// printDialogWidth is the width of the Print dialog
+const printDialogWidth = 60
+
+// What the Print dialog prints
+const (
+	printBuffer = iota
+	printSelection
+	printLines
+)
+
+// Rows of the Print dialog
+const (
+	printRowWhat = iota
+	printRowLines
+	printRowHeader
+	printRowCommand
+	printRowCount
+)
+
+// printDoneMsg reports how the print command finished
+type printDoneMsg struct {
+	command string
+	lines   int
+	err     error
+	output  string // What the command wrote, for the error message
+}
+
+// showPrintDialog opens the Print dialog, set to print the selection when
+// there is one and otherwise the whole buffer
+func (e *Editor) showPrintDialog() {
+	doc := e.activeDoc()
+	e.printWhat = printBuffer
+	e.printRange = fmt.Sprintf("1-%d", doc.buffer.LineCount())
+	if e.hasPrintSelection() {
+		e.printWhat = printSelection
+		first, _ := doc.buffer.PositionToLineCol(doc.selection.StartPos())
+		last, _ := doc.buffer.PositionToLineCol(doc.selection.EndPos())
+		e.printRange = fmt.Sprintf("%d-%d", first+1, last+1)
+	}
+	e.printHeader = e.config.Editor.PrintHeader
+	e.printCommand = e.config.Editor.PrintCommand
+	e.printIndex = printRowWhat
+	e.mode = ModePrint
+}
+
+// hasPrintSelection reports whether there is a selection to print
+func (e *Editor) hasPrintSelection() bool {
+	sel := e.activeDoc().selection
+	return sel.Active && !sel.IsEmpty()
+}
+
+// cyclePrintWhat steps through what to print, skipping the selection when
+// there is none
+func (e *Editor) cyclePrintWhat(delta int) {
+	for {
+		e.printWhat = (e.printWhat + delta + 3) % 3
+		if e.printWhat != printSelection || e.hasPrintSelection() {
+			return
+		}
+	}
+}
+
+// parseLineRange reads "a-b" or "a" as 0-indexed first and last lines
+// within a buffer of count lines
+func parseLineRange(s string, count int) (first, last int, err error) {
+	from, to, found := strings.Cut(strings.TrimSpace(s), "-")
+	if !found {
+		to = from
+	}
+	first, err1 := strconv.Atoi(strings.TrimSpace(from))
+	last, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if err1 != nil || err2 != nil || first < 1 || last < first {
+		return 0, 0, fmt.Errorf("invalid line range %q", s)
+	}
+	if first > count {
+		return 0, 0, fmt.Errorf("line %d is past the end (%d lines)", first, count)
+	}
+	return first - 1, min(last, count) - 1, nil
+}
+
+// printText returns the text the dialog is set to print
+func (e *Editor) printText() (string, error) {
+	doc := e.activeDoc()
+	switch e.printWhat {
+	case printSelection:
+		return doc.selection.GetText(doc.buffer), nil
+	case printLines:
+		first, last, err := parseLineRange(e.printRange, doc.buffer.LineCount())
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(doc.buffer.Lines()[first:last+1], "\n"), nil
+	}
+	return doc.buffer.String(), nil
+}
+
+// formatForPrint lays text out for the printer. With a header each page
+// starts with the title and its page number and a blank line, and pages
+// are separated by form feeds, like pr(1).
+func formatForPrint(text, title string, header bool, pageLines int) string {
+	text = strings.TrimSuffix(text, "\n")
+	if !header {
+		return text + "\n"
+	}
+	lines := strings.Split(text, "\n")
+	body := max(pageLines-2, 1)
+	pages := max((len(lines)+body-1)/body, 1)
+	var sb strings.Builder
+	for page := range pages {
+		if page > 0 {
+			sb.WriteByte('\f')
+		}
+		number := fmt.Sprintf("Page %d of %d", page+1, pages)
+		gap := max(printWidth-utf8.RuneCountInString(title)-len(number), 2)
+		sb.WriteString(title + strings.Repeat(" ", gap) + number + "\n\n")
+		for _, line := range lines[page*body : min((page+1)*body, len(lines))] {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// shellCommand runs command through the system shell, so it may have
+// arguments and pipes
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// runPrint pipes text to command in the background
+func runPrint(command, text string, lines int) tea.Cmd {
+	return func() tea.Msg {
+		cmd := shellCommand(command)
+		cmd.Stdin = strings.NewReader(text)
+		out, err := cmd.CombinedOutput()
+		return printDoneMsg{command: command, lines: lines, err: err, output: strings.TrimSpace(string(out))}
+	}
+}
+
+// startPrint sends the chosen text to the print command and remembers the
+// command and header choice for next time
+func (e *Editor) startPrint() tea.Cmd {
+	command := strings.TrimSpace(e.printCommand)
+	if command == "" {
+		e.statusbar.SetMessage("Enter a print command, e.g. lpr", "error")
+		return nil
+	}
+	text, err := e.printText()
+	if err != nil {
+		e.statusbar.SetMessage("Print: "+err.Error(), "error")
+		return nil
+	}
+	e.mode = ModeNormal
+	if e.config.Editor.PrintCommand != command || e.config.Editor.PrintHeader != e.printHeader {
+		e.config.Editor.PrintCommand = command
+		e.config.Editor.PrintHeader = e.printHeader
+		go e.config.Save()
+	}
+	pageLines := e.config.Editor.PrintPageLines
+	if pageLines <= 0 {
+		pageLines = 66
+	}
+	lines := strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1
+	e.statusbar.SetMessage("Printing"+e.box.Ellipsis, "info")
+	return runPrint(command, formatForPrint(text, e.bufferName(e.activeDoc()), e.printHeader, pageLines), lines)
+}
+
+// applyPrintDone reports how printing went
+func (e *Editor) applyPrintDone(msg printDoneMsg) {
+	if msg.err != nil {
+		detail := msg.err.Error()
+		if first, _, _ := strings.Cut(msg.output, "\n"); first != "" {
+			detail = first
+		}
+		e.statusbar.SetMessage("Print failed: "+detail, "error")
+		return
+	}
+	name, _, _ := strings.Cut(msg.command, " ")
+	e.statusbar.SetMessage(fmt.Sprintf("Sent %d lines to %s", msg.lines, name), "success")
+}
+
+// printDialog builds the Print dialog
+func (e *Editor) printDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(printDialogWidth)
+	db.AddTitleBorder(" Print ")
+	db.AddEmptyLine()
+
+	// Widgets are added in printRow order, so the rows are their ids
+	db.SetFocus(e.printIndex)
+	db.AddChoice("Print:   ", [...]string{"Whole buffer", "Selection", "Lines"}[e.printWhat])
+	db.AddInput("Lines:   ", e.printRange)
+	db.AddCheckbox("Header with file name and page numbers", e.printHeader)
+	db.AddInput("Command: ", e.printCommand)
+
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Print  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayPrintDialog overlays the Print dialog centered on the viewport
+func (e *Editor) overlayPrintDialog(viewportContent string) string {
+	return e.printDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// handlePrintKey handles key events in the Print dialog
+func (e *Editor) handlePrintKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if moveFocus(&e.printIndex, printRowCount, msg) {
+		return e, nil
+	}
+	switch msg.Type {
+	case tea.KeyLeft, tea.KeyRight:
+		if e.printIndex == printRowWhat {
+			if msg.Type == tea.KeyLeft {
+				e.cyclePrintWhat(-1)
+			} else {
+				e.cyclePrintWhat(1)
+			}
+		}
+	case tea.KeySpace:
+		switch e.printIndex {
+		case printRowWhat:
+			e.cyclePrintWhat(1)
+		case printRowHeader:
+			e.printHeader = !e.printHeader
+		case printRowCommand:
+			e.printCommand += " "
+		}
+	case tea.KeyBackspace:
+		switch e.printIndex {
+		case printRowLines:
+			_, size := utf8.DecodeLastRuneInString(e.printRange)
+			e.printRange = e.printRange[:len(e.printRange)-size]
+			e.printWhat = printLines
+		case printRowCommand:
+			_, size := utf8.DecodeLastRuneInString(e.printCommand)
+			e.printCommand = e.printCommand[:len(e.printCommand)-size]
+		}
+	case tea.KeyRunes:
+		switch e.printIndex {
+		case printRowLines:
+			for _, r := range msg.Runes {
+				if r >= '0' && r <= '9' || r == '-' {
+					e.printRange += string(r)
+				}
+			}
+			e.printWhat = printLines
+		case printRowCommand:
+			e.printCommand += string(msg.Runes)
+		}
+	case tea.KeyEnter:
+		return e, e.startPrint()
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// handlePrintMouse focuses the clicked row and toggles or steps a focused
+// one; a click outside cancels
+func (e *Editor) handlePrintMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress {
+		return e, nil
+	}
+	hit := e.printDialog().HitTest(msg.X, msg.Y-1)
+	if !hit.inside {
+		e.mode = ModeNormal
+		return e, nil
+	}
+	if hit.id < 0 {
+		return e, nil
+	}
+	if hit.id == e.printIndex {
+		switch hit.kind {
+		case widgetCheckbox:
+			e.printHeader = !e.printHeader
+		case widgetChoice:
+			e.cyclePrintWhat(1)
+		}
+	}
+	e.printIndex = hit.id
+	return e, nil
+}
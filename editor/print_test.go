@@ -0,0 +1,102 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestFormatForPrint(t *testing.T) {
+	if got := formatForPrint("a\nb\n", "x.txt", false, 66); got != "a\nb\n" {
+		t.Errorf("without a header got %q", got)
+	}
+
+	got := formatForPrint("1\n2\n3\n4\n5", "notes.txt", true, 4)
+	pages := strings.Split(got, "\f")
+	if len(pages) != 3 {
+		t.Fatalf("5 lines at 2 per page should make 3 pages, got %q", got)
+	}
+	header := "notes.txt" + strings.Repeat(" ", printWidth-len("notes.txt")-len("Page 1 of 3")) + "Page 1 of 3"
+	if pages[0] != header+"\n\n1\n2\n" {
+		t.Errorf("first page = %q", pages[0])
+	}
+	if !strings.HasSuffix(pages[2], "Page 3 of 3\n\n5\n") {
+		t.Errorf("last page = %q", pages[2])
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		in          string
+		first, last int
+		ok          bool
+	}{
+		{"1-3", 0, 2, true},
+		{" 4 ", 3, 3, true},
+		{"2-99", 1, 9, true},
+		{"3-2", 0, 0, false},
+		{"0-2", 0, 0, false},
+		{"11", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, tt := range tests {
+		first, last, err := parseLineRange(tt.in, 10)
+		if (err == nil) != tt.ok || first != tt.first || last != tt.last {
+			t.Errorf("parseLineRange(%q) = %d, %d, %v", tt.in, first, last, err)
+		}
+	}
+}
+
+func TestPrintDialog(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	out := filepath.Join(t.TempDir(), "out.txt")
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	e.activeDoc().buffer.Insert("one\ntwo\nthree\nfour")
+
+	e.executeAction(ui.ActionPrint)
+	if e.mode != ModePrint || e.printWhat != printBuffer || e.printRange != "1-4" {
+		t.Fatalf("mode %v, what %d, range %q: want the whole buffer", e.mode, e.printWhat, e.printRange)
+	}
+
+	// Typing a range switches to printing lines
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	for range 3 {
+		e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2-3")})
+	if e.printWhat != printLines || e.printRange != "2-3" {
+		t.Fatalf("what %d, range %q, want lines 2-3", e.printWhat, e.printRange)
+	}
+
+	// Turn the header off and print to a file
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeySpace})
+	e.printCommand = "cat > " + out
+	_, cmd := e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModeNormal || cmd == nil {
+		t.Fatal("Enter should close the dialog and start printing")
+	}
+	e.Update(cmd())
+	if data, err := os.ReadFile(out); err != nil || string(data) != "two\nthree\n" {
+		t.Errorf("printed %q, %v", data, err)
+	}
+	if e.config.Editor.PrintHeader {
+		t.Error("the header choice should be remembered")
+	}
+}
+
+func TestPrintFailure(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.statusbar.SetWidth(120)
+	e.Update(runPrint("echo no printer >&2; exit 1", "x", 1)())
+	if !strings.Contains(e.statusbar.View(), "no printer") {
+		t.Errorf("status bar should show the command's error: %q", e.statusbar.View())
+	}
+}
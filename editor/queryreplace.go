@@ -0,0 +1,140 @@
+package editor
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// queryReplaceHints replaces the find/replace bar hints while asking per match
+const queryReplaceHints = " Replace? [y] Yes [n] No [a] All [q] Quit"
+
+// startQueryReplace steps through the matches from the cursor to the end of
+// the buffer, asking before each replacement, like emacs query-replace
+func (e *Editor) startQueryReplace() {
+	if e.refuseEdit() {
+		return
+	}
+	if e.findQuery == "" {
+		e.statusbar.SetMessage("No search term", "error")
+		return
+	}
+	e.queryReplaced = 0
+	e.querySkipped = 0
+	if !e.selectMatchFrom(e.activeDoc().cursor.ByteOffset()) {
+		e.statusbar.SetMessage("Not found", "error")
+		return
+	}
+	e.queryReplacing = true
+	e.statusbar.SetMessage("Replace this match?", "info")
+}
+
+// selectMatchFrom selects the first match at or after pos. Returns false
+// when there are no more matches before the end of the buffer.
+func (e *Editor) selectMatchFrom(pos int) bool {
+	doc := e.activeDoc()
+	var match *multiRange
+	for _, m := range e.findMatches(doc.buffer.String(), e.findQuery) {
+		if m.start >= pos {
+			match = &m
+			break
+		}
+	}
+	if match == nil {
+		return false
+	}
+	doc.cursor.SetByteOffset(match.start)
+	doc.selection.Active = true
+	doc.selection.Anchor = match.start
+	doc.selection.Cursor = match.end
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	return true
+}
+
+// handleQueryReplaceKey answers the per-match question: y or Space replaces,
+// n or Delete skips, a replaces this and every later match, q or Escape stops
+func (e *Editor) handleQueryReplaceKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	doc := e.activeDoc()
+	start, end := doc.selection.Anchor, doc.selection.Cursor
+	switch msg.String() {
+	case "y", " ":
+		e.replaceMatchAt(start, end)
+		e.nextQueryMatch(start + len(e.replaceQuery))
+	case "n", "delete":
+		e.querySkipped++
+		e.nextQueryMatch(end)
+	case "a", "!":
+		e.replaceRemaining(start)
+		e.finishQueryReplace()
+	case "q", "esc", "enter":
+		e.finishQueryReplace()
+	}
+	return e, nil
+}
+
+// nextQueryMatch selects the next match after pos, or finishes at the end
+func (e *Editor) nextQueryMatch(pos int) {
+	if !e.selectMatchFrom(pos) {
+		e.finishQueryReplace()
+	}
+}
+
+// replaceMatchAt replaces the match from pos to end as one undo step
+func (e *Editor) replaceMatchAt(pos, end int) {
+	doc := e.activeDoc()
+	entry := &UndoEntry{
+		Position:     pos,
+		Deleted:      doc.buffer.Substring(pos, end),
+		Inserted:     e.replaceQuery,
+		CursorBefore: doc.cursor.ByteOffset(),
+		CursorAfter:  pos + len(e.replaceQuery),
+	}
+	doc.buffer.Replace(pos, end, e.replaceQuery)
+	doc.cursor.SetByteOffset(pos + len(e.replaceQuery))
+	doc.selection.Clear()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+	e.queryReplaced++
+}
+
+// replaceRemaining replaces every match from pos to the end as one undo step
+func (e *Editor) replaceRemaining(pos int) {
+	doc := e.activeDoc()
+	content := doc.buffer.String()
+	var matches []multiRange
+	for _, m := range e.findMatches(content, e.findQuery) {
+		if m.start >= pos {
+			matches = append(matches, multiRange{start: m.start - pos, end: m.end - pos})
+		}
+	}
+	count := len(matches)
+	if count == 0 {
+		return
+	}
+	tail := content[pos:]
+	replaced := replaceMatches(tail, matches, e.replaceQuery)
+	entry := &UndoEntry{
+		Position:     pos,
+		Deleted:      tail,
+		Inserted:     replaced,
+		CursorBefore: doc.cursor.ByteOffset(),
+		CursorAfter:  pos,
+	}
+	doc.buffer.Replace(pos, len(content), replaced)
+	doc.cursor.SetByteOffset(pos)
+	doc.selection.Clear()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+	e.queryReplaced += count
+}
+
+// finishQueryReplace leaves the per-match question and reports the totals
+func (e *Editor) finishQueryReplace() {
+	e.queryReplacing = false
+	e.activeDoc().selection.Clear()
+	msg := fmt.Sprintf("Replaced %d", e.queryReplaced)
+	if e.querySkipped > 0 {
+		msg += fmt.Sprintf(", skipped %d", e.querySkipped)
+	}
+	e.statusbar.SetMessage(msg, "info")
+}
@@ -0,0 +1,86 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func queryKeys(e *Editor, keys ...string) {
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "ctrl+r":
+			msg = tea.KeyMsg{Type: tea.KeyCtrlR}
+		}
+		e.Update(msg)
+	}
+}
+
+func TestQueryReplace(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "cat cat cat cat", 4)
+	e.showFindReplace()
+	e.findQuery = "cat"
+	e.replaceQuery = "dog"
+
+	queryKeys(e, "ctrl+r")
+	if !e.queryReplacing {
+		t.Fatalf("Ctrl+R should start asking")
+	}
+	if sel := e.activeDoc().selection; sel.Anchor != 4 || sel.Cursor != 7 {
+		t.Errorf("first match selected at %d-%d, want 4-7 (from the cursor on)", sel.Anchor, sel.Cursor)
+	}
+
+	queryKeys(e, "y", "n", " ")
+	if got := e.activeDoc().buffer.String(); got != "cat dog cat dog" {
+		t.Errorf("buffer = %q, want matches 2 and 4 replaced", got)
+	}
+	if e.queryReplacing {
+		t.Errorf("query replace should stop after the last match")
+	}
+	if e.mode != ModeFindReplace {
+		t.Errorf("mode = %v, want to stay in the find/replace bar", e.mode)
+	}
+	if e.queryReplaced != 2 || e.querySkipped != 1 {
+		t.Errorf("replaced %d, skipped %d, want 2 and 1", e.queryReplaced, e.querySkipped)
+	}
+}
+
+func TestQueryReplaceAllRemaining(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "a-a-a-a", 0)
+	e.showFindReplace()
+	e.findQuery = "a"
+	e.replaceQuery = "aa" // Replacements containing the query are not revisited
+
+	queryKeys(e, "ctrl+r", "n", "a")
+	if got := e.activeDoc().buffer.String(); got != "a-aa-aa-aa" {
+		t.Errorf("buffer = %q, want all but the first match replaced", got)
+	}
+	e.undo()
+	if got := e.activeDoc().buffer.String(); got != "a-a-a-a" {
+		t.Errorf("after undo buffer = %q, want the remaining replacements undone together", got)
+	}
+}
+
+func TestQueryReplaceQuit(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "x x", 0)
+	e.showFindReplace()
+	e.findQuery = "x"
+	e.replaceQuery = "y"
+
+	queryKeys(e, "ctrl+r", "esc")
+	if e.queryReplacing || e.mode != ModeFindReplace {
+		t.Errorf("Escape should stop asking but keep the bar open")
+	}
+	if got := e.activeDoc().buffer.String(); got != "x x" {
+		t.Errorf("buffer = %q, want unchanged", got)
+	}
+	queryKeys(e, "q") // Typing works again once the question is over
+	if e.findQuery != "xq" {
+		t.Errorf("findQuery = %q, want typing to resume", e.findQuery)
+	}
+}
@@ -0,0 +1,307 @@
+package editor
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// quickOpenWidth is the width of the Quick Open dialog
+const quickOpenWidth = 70
+
+// quickOpenShown is the most matches the Quick Open dialog lists
+const quickOpenShown = 15
+
+// quickOpenMaxFiles stops indexing huge trees (a home directory) early
+const quickOpenMaxFiles = 50000
+
+// quickOpenSkipDirs are never indexed: version control data and the usual
+// dependency and build output trees
+var quickOpenSkipDirs = map[string]bool{
+	".git": true, ".hg": true, ".svn": true,
+	"node_modules": true, "vendor": true, "target": true,
+	"__pycache__": true, ".venv": true, "dist": true, "build": true,
+}
+
+// quickOpenIndexMsg carries the files found under root by the background index
+type quickOpenIndexMsg struct {
+	root  string
+	files []string // Paths relative to root
+}
+
+// quickOpenMatch is one candidate ranked against the query
+type quickOpenMatch struct {
+	path  string // Absolute path
+	label string // Shown and matched: relative to the working directory when under it
+	score int
+}
+
+// showQuickOpen opens the Quick Open dialog with the recent files, and
+// returns a command indexing the working directory in the background. The
+// previous index of the same directory is used until the new one arrives.
+func (e *Editor) showQuickOpen() tea.Cmd {
+	root, err := os.Getwd()
+	if err != nil {
+		root = ""
+	}
+	e.quickOpenQuery = ""
+	e.quickOpenIndex = 0
+	e.quickOpenRanked = false
+	e.mode = ModeQuickOpen
+	if root == "" || (root == e.quickOpenRoot && e.quickOpenIndexing) {
+		return nil
+	}
+	if root != e.quickOpenRoot {
+		e.quickOpenFiles = nil
+	}
+	e.quickOpenRoot = root
+	e.quickOpenIndexing = true
+	return func() tea.Msg {
+		return quickOpenIndexMsg{root: root, files: indexFiles(root)}
+	}
+}
+
+// indexFiles lists the regular files under root, skipping hidden and
+// dependency directories, as slash-separated relative paths
+func indexFiles(root string) []string {
+	var files []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Unreadable directories are skipped, not fatal
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || quickOpenSkipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		if len(files) >= quickOpenMaxFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files
+}
+
+// applyQuickOpenIndex stores an index read in the background, unless the
+// dialog has since been opened in a different directory
+func (e *Editor) applyQuickOpenIndex(msg quickOpenIndexMsg) {
+	if msg.root != e.quickOpenRoot {
+		return
+	}
+	e.quickOpenFiles = msg.files
+	e.quickOpenIndexing = false
+	e.quickOpenRanked = false
+}
+
+// quickOpenLabel shows path relative to the indexed directory when under it
+func (e *Editor) quickOpenLabel(path string) string {
+	if e.quickOpenRoot != "" {
+		if rel, err := filepath.Rel(e.quickOpenRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// quickOpenMatches returns the recent and indexed files ranked against the
+// query. The dialog is built on every render and mouse event, so the
+// ranking is kept until the query or the index changes.
+func (e *Editor) quickOpenMatches() []quickOpenMatch {
+	if !e.quickOpenRanked || e.quickOpenMatchedFor != e.quickOpenQuery {
+		e.quickOpenMatched = e.rankQuickOpen()
+		e.quickOpenMatchedFor = e.quickOpenQuery
+		e.quickOpenRanked = true
+	}
+	return e.quickOpenMatched
+}
+
+// rankQuickOpen ranks the recent and indexed files against the query.
+// Recent files come first among equal scores and are all that is listed
+// before anything is typed.
+func (e *Editor) rankQuickOpen() []quickOpenMatch {
+	seen := make(map[string]bool)
+	var matches []quickOpenMatch
+	add := func(path string, bonus int) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		label := e.quickOpenLabel(path)
+		score, ok := fuzzyScore(e.quickOpenQuery, label)
+		if ok {
+			matches = append(matches, quickOpenMatch{path: path, label: label, score: score + bonus})
+		}
+	}
+	if e.config != nil {
+		for _, path := range e.config.RecentFiles {
+			add(path, 5)
+		}
+	}
+	if e.quickOpenQuery != "" {
+		for _, rel := range e.quickOpenFiles {
+			add(filepath.Join(e.quickOpenRoot, filepath.FromSlash(rel)), 0)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].label) < len(matches[j].label)
+	})
+	if len(matches) > quickOpenShown {
+		matches = matches[:quickOpenShown]
+	}
+	return matches
+}
+
+// fuzzyScore matches the letters of pattern in order within s, ignoring
+// case. Matches at the start of a path segment or word, runs of adjacent
+// letters and matches in the file name score higher; gaps score lower.
+func fuzzyScore(pattern, s string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := []rune(strings.ToLower(pattern))
+	runes := []rune(s)
+	base := strings.LastIndexByte(s, '/') + 1
+	baseRune := len([]rune(s[:base]))
+
+	score, pi, last := 0, 0, -1
+	for i, r := range runes {
+		if pi == len(p) {
+			break
+		}
+		if unicode.ToLower(r) != p[pi] {
+			continue
+		}
+		score++
+		if i == 0 || strings.ContainsRune("/_-. ", runes[i-1]) ||
+			(unicode.IsUpper(r) && unicode.IsLower(runes[i-1])) {
+			score += 8 // Start of a segment or word
+		}
+		if last >= 0 && i == last+1 {
+			score += 5 // Adjacent to the previous match
+		} else if last >= 0 {
+			score -= min(i-last-1, 3)
+		}
+		if i >= baseRune {
+			score += 2 // In the file name rather than the directories
+		}
+		last = i
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
+
+// quickOpenDialog builds the Quick Open dialog
+func (e *Editor) quickOpenDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(quickOpenWidth)
+	db.AddTitleBorder(" Quick Open ")
+	db.AddText(" > " + e.quickOpenQuery + "_")
+	db.AddSeparator()
+	matches := e.quickOpenMatches()
+	for i, m := range matches {
+		db.AddSelectableItem(truncateLeft(m.label, db.InnerWidth()-2, e.box.Ellipsis), i == e.quickOpenIndex)
+	}
+	if len(matches) == 0 {
+		db.AddText("  No matching files")
+	}
+	db.AddEmptyLine()
+	footer := "[Enter] Open  [Esc] Cancel"
+	if e.quickOpenIndexing {
+		footer = "Indexing" + e.box.Ellipsis + "  " + footer
+	}
+	db.AddCenteredText(footer)
+	db.AddBottomBorder()
+	return db
+}
+
+// truncateLeft shortens s to width by cutting its start, keeping the file
+// name at the end of a long path visible
+func truncateLeft(s string, width int, ellipsis string) string {
+	over := runewidth.StringWidth(s) - width
+	if over <= 0 {
+		return s
+	}
+	return runewidth.TruncateLeft(s, over+runewidth.StringWidth(ellipsis), ellipsis)
+}
+
+// overlayQuickOpenDialog overlays the Quick Open dialog centered on the viewport
+func (e *Editor) overlayQuickOpenDialog(viewportContent string) string {
+	return e.quickOpenDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// quickOpen closes the dialog and opens the chosen match
+func (e *Editor) quickOpen(index int) {
+	e.mode = ModeNormal
+	matches := e.quickOpenMatches()
+	if index < 0 || index >= len(matches) {
+		return
+	}
+	path := matches[index].path
+	if err := e.LoadFile(path); err != nil {
+		e.statusbar.SetMessage("Open failed: "+err.Error(), "error")
+		return
+	}
+	e.reportOpened(path)
+}
+
+// handleQuickOpenKey handles typing and selection in the Quick Open dialog
+func (e *Editor) handleQuickOpenKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.quickOpenIndex > 0 {
+			e.quickOpenIndex--
+		}
+	case tea.KeyDown:
+		if e.quickOpenIndex < len(e.quickOpenMatches())-1 {
+			e.quickOpenIndex++
+		}
+	case tea.KeyEnter:
+		e.quickOpen(e.quickOpenIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyBackspace:
+		if len(e.quickOpenQuery) > 0 {
+			_, size := utf8.DecodeLastRuneInString(e.quickOpenQuery)
+			e.quickOpenQuery = e.quickOpenQuery[:len(e.quickOpenQuery)-size]
+			e.quickOpenIndex = 0
+		}
+	case tea.KeyRunes:
+		e.quickOpenQuery += string(msg.Runes)
+		e.quickOpenIndex = 0
+	case tea.KeySpace:
+		e.quickOpenQuery += " "
+		e.quickOpenIndex = 0
+	}
+	return e, nil
+}
+
+// handleQuickOpenMouse selects matches on click and opens on a second click
+func (e *Editor) handleQuickOpenMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.quickOpenDialog().listMouse(msg, &e.quickOpenIndex) {
+	case listChoose:
+		e.quickOpen(e.quickOpenIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
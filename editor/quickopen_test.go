@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("edg", "editor/dialogs.go"); !ok {
+		t.Errorf("letters in order should match")
+	}
+	if _, ok := fuzzyScore("gde", "editor/dialogs.go"); ok {
+		t.Errorf("letters out of order should not match")
+	}
+	// Segment starts beat scattered letters
+	dialogs, _ := fuzzyScore("eddi", "editor/dialogs.go")
+	scattered, _ := fuzzyScore("eddi", "editor/readme_old_index.md")
+	if dialogs <= scattered {
+		t.Errorf("editor/dialogs.go scored %d, want above %d", dialogs, scattered)
+	}
+}
+
+func TestTruncateLeft(t *testing.T) {
+	if got := truncateLeft("a/b.go", 10, "…"); got != "a/b.go" {
+		t.Errorf("short path = %q, want unchanged", got)
+	}
+	if got := truncateLeft("editor/dialogs.go", 10, "…"); got != "…ialogs.go" {
+		t.Errorf("long path = %q, want the end kept", got)
+	}
+}
+
+func TestIndexFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"main.go", "editor/dialogs.go", ".git/HEAD", "node_modules/x/index.js"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := strings.Join(indexFiles(root), " ")
+	if got != "editor/dialogs.go main.go" {
+		t.Errorf("indexed %q, want hidden and dependency directories skipped", got)
+	}
+}
+
+func TestQuickOpen(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Opening a file updates the recent lists
+	root := t.TempDir()
+	t.Chdir(root)
+	for _, name := range []string{"editor/dialogs.go", "editor/editor.go", "README.md"} {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := New()
+	cmd := e.showQuickOpen()
+	if e.mode != ModeQuickOpen || cmd == nil {
+		t.Fatalf("Quick Open should open and start indexing")
+	}
+	e.Update(cmd())
+	for _, r := range "eddia" {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	matches := e.quickOpenMatches()
+	if len(matches) == 0 || matches[0].label != "editor/dialogs.go" {
+		t.Fatalf("matches = %+v, want editor/dialogs.go first", matches)
+	}
+	if again := e.quickOpenMatches(); &again[0] != &matches[0] {
+		t.Errorf("matches should be kept while the query and index are unchanged")
+	}
+	e.applyQuickOpenIndex(quickOpenIndexMsg{root: e.quickOpenRoot, files: []string{"README.md"}})
+	if matches := e.quickOpenMatches(); len(matches) != 0 {
+		t.Errorf("matches = %+v, want a new index to be ranked again", matches)
+	}
+	e.applyQuickOpenIndex(quickOpenIndexMsg{root: e.quickOpenRoot, files: []string{"editor/dialogs.go"}})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if e.mode != ModeNormal || e.activeDoc().buffer.String() != "editor/dialogs.go" {
+		t.Errorf("Enter should open the top match")
+	}
+}
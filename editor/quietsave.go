@@ -0,0 +1,26 @@
+package editor
+
+// quietSave saves the active buffer without asking anything. A save that
+// would need an answer (a file changed on disk, invisible characters to
+// strip, a lossy encoding, a failed preflight check or write) is skipped
+// instead, leaving the buffer modified, and the status bar says why.
+// Auto-saves use it so that keys typed as a dialog pops up can't answer it.
+func (e *Editor) quietSave() bool {
+	if e.fileChangedOnDisk() {
+		e.statusbar.SetMessage("Auto-save skipped: "+e.bufferName(e.activeDoc())+" changed on disk - save to choose", "warning")
+		return false
+	}
+	e.savingQuietly = true
+	defer func() { e.savingQuietly = false }()
+	return e.doSave()
+}
+
+// skipQuietSave reports whether a save that needs an answer should be
+// skipped because it is a quiet save, saying why on the status bar
+func (e *Editor) skipQuietSave(reason string) bool {
+	if !e.savingQuietly {
+		return false
+	}
+	e.statusbar.SetMessage("Auto-save skipped: "+reason+" - save to choose", "warning")
+	return true
+}
@@ -0,0 +1,163 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cornish/textivus-editor/clipboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// pasteHistoryWidth is the width of the Paste from History dialog
+const pasteHistoryWidth = 60
+
+// pasteHistoryMax is the most clipboard history entries the dialog lists
+const pasteHistoryMax = 20
+
+// Pending register operations, waiting for the register name key
+const (
+	registerOpNone  = 0
+	registerOpCopy  = 'c'
+	registerOpPaste = 'p'
+)
+
+// pasteHistory returns the clipboard history entries shown in the dialog, newest first
+func (e *Editor) pasteHistory() []string {
+	entries := e.clipboard.KillRing().Entries()
+	if len(entries) > pasteHistoryMax {
+		entries = entries[:pasteHistoryMax]
+	}
+	return entries
+}
+
+// showPasteHistory opens the Paste from History dialog
+func (e *Editor) showPasteHistory() {
+	if len(e.pasteHistory()) == 0 {
+		e.statusbar.SetMessage("Clipboard history is empty", "info")
+		return
+	}
+	e.pasteHistoryIndex = 0
+	e.mode = ModePasteHistory
+}
+
+// historyPreview shows a clipboard entry on one line: its first line, with
+// a count of any lines after it
+func historyPreview(text string, width int, ellipsis string) string {
+	first, rest, multi := strings.Cut(text, "\n")
+	first = strings.ReplaceAll(first, "\t", " ")
+	suffix := ""
+	if multi {
+		suffix = fmt.Sprintf(" (+%d lines)", strings.Count(rest, "\n")+1)
+	}
+	return runewidth.Truncate(first, width-runewidth.StringWidth(suffix), ellipsis) + suffix
+}
+
+// pasteHistoryDialog builds the Paste from History dialog
+func (e *Editor) pasteHistoryDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(pasteHistoryWidth)
+	db.AddTitleBorder(" Paste from History ")
+	db.AddEmptyLine()
+	for i, text := range e.pasteHistory() {
+		db.AddSelectableItem(historyPreview(text, db.InnerWidth()-2, e.box.Ellipsis), i == e.pasteHistoryIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Paste  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayPasteHistoryDialog overlays the Paste from History dialog centered on the viewport
+func (e *Editor) overlayPasteHistoryDialog(viewportContent string) string {
+	return e.pasteHistoryDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// pasteFromHistory closes the dialog and pastes the chosen history entry
+func (e *Editor) pasteFromHistory(index int) {
+	e.mode = ModeNormal
+	entries := e.pasteHistory()
+	if index < 0 || index >= len(entries) {
+		return
+	}
+	e.insertText(entries[index])
+	e.viewport.EnsureCursorVisibleWrapped(e.activeDoc().buffer.Lines(), e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col())
+}
+
+// handlePasteHistoryKey handles key events in the Paste from History dialog
+func (e *Editor) handlePasteHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := len(e.pasteHistory())
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.pasteHistoryIndex > 0 {
+			e.pasteHistoryIndex--
+		}
+	case tea.KeyDown:
+		if e.pasteHistoryIndex < count-1 {
+			e.pasteHistoryIndex++
+		}
+	case tea.KeyEnter:
+		e.pasteFromHistory(e.pasteHistoryIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyRunes:
+		// 1-9 paste the matching entry directly
+		if len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+			e.pasteFromHistory(int(msg.Runes[0] - '1'))
+		}
+	}
+	return e, nil
+}
+
+// handlePasteHistoryMouse selects entries on click and pastes on a second click
+func (e *Editor) handlePasteHistoryMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.pasteHistoryDialog().listMouse(msg, &e.pasteHistoryIndex) {
+	case listChoose:
+		e.pasteFromHistory(e.pasteHistoryIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// startRegisterOp waits for the name of the register to copy to or paste from
+func (e *Editor) startRegisterOp(op rune) {
+	doc := e.activeDoc()
+	if op == registerOpCopy && (!doc.selection.Active || doc.selection.IsEmpty()) {
+		e.statusbar.SetMessage("Select text to copy to a register", "info")
+		return
+	}
+	e.registerOp = op
+	if op == registerOpCopy {
+		e.statusbar.SetMessage("Copy to register (a-z, 0-9; A-Z appends)", "info")
+	} else {
+		e.statusbar.SetMessage("Paste register (a-z, 0-9)", "info")
+	}
+}
+
+// handleRegisterKey completes a pending register operation with the register
+// name typed after it; any other key cancels
+func (e *Editor) handleRegisterKey(msg tea.KeyMsg) {
+	op := e.registerOp
+	e.registerOp = registerOpNone
+	if msg.Type != tea.KeyRunes || msg.Alt || len(msg.Runes) != 1 || !clipboard.IsRegisterName(msg.Runes[0]) {
+		e.statusbar.SetMessage("Cancelled", "info")
+		return
+	}
+	name := msg.Runes[0]
+	doc := e.activeDoc()
+
+	if op == registerOpCopy {
+		e.clipboard.SetRegister(name, doc.selection.GetText(doc.buffer))
+		e.statusbar.SetMessage(fmt.Sprintf("Copied to register %c", name), "info")
+		return
+	}
+
+	text := e.clipboard.Register(name)
+	if text == "" {
+		e.statusbar.SetMessage(fmt.Sprintf("Register %c is empty", name), "info")
+		return
+	}
+	e.insertText(text)
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
@@ -0,0 +1,72 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/cornish/textivus-editor/clipboard"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPasteFromHistory(t *testing.T) {
+	e := New()
+	e.clipboard.SetProvider(clipboard.ProviderInternal)
+	e.clipboard.Copy("first")
+	e.clipboard.Copy("second\nline")
+
+	e.showPasteHistory()
+	if e.mode != ModePasteHistory {
+		t.Fatalf("mode = %v, want ModePasteHistory", e.mode)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyDown})
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := e.activeDoc().buffer.String(); got != "first" {
+		t.Errorf("buffer = %q, want the older entry", got)
+	}
+	if e.mode != ModeNormal {
+		t.Errorf("dialog still open after pasting")
+	}
+}
+
+func TestHistoryPreview(t *testing.T) {
+	if got := historyPreview("func main() {\n\tx()\n}", 40, "..."); got != "func main() { (+2 lines)" {
+		t.Errorf("historyPreview() = %q", got)
+	}
+	if got := historyPreview("abcdefghij", 6, "..."); got != "abc..." {
+		t.Errorf("historyPreview() truncated = %q", got)
+	}
+}
+
+func TestRegisters(t *testing.T) {
+	e := New()
+	e.clipboard.SetProvider(clipboard.ProviderInternal)
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("alpha beta")
+	doc.cursor = NewCursor(doc.buffer)
+	doc.selection.Start(0)
+	doc.selection.Update(5)
+
+	e.startRegisterOp(registerOpCopy)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if got := e.clipboard.Register('a'); got != "alpha" {
+		t.Fatalf("register a = %q, want %q", got, "alpha")
+	}
+	if got, _ := e.clipboard.Paste(); got == "alpha" {
+		t.Error("copying to a register should leave the clipboard alone")
+	}
+
+	doc.selection.Clear()
+	doc.cursor.SetByteOffset(doc.buffer.Length())
+	e.startRegisterOp(registerOpPaste)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if got := doc.buffer.String(); got != "alpha betaalpha" {
+		t.Errorf("buffer = %q after pasting register a", got)
+	}
+
+	// Any other key cancels without typing
+	e.startRegisterOp(registerOpPaste)
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.registerOp != registerOpNone || doc.buffer.String() != "alpha betaalpha" {
+		t.Error("cancelled register paste changed the buffer")
+	}
+}
@@ -0,0 +1,94 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+// showReopenEncodingDialog opens the encoding dialog to re-read the file in
+// the chosen encoding, for when detection guessed wrong
+func (e *Editor) showReopenEncodingDialog() {
+	if e.activeDoc().filename == "" {
+		e.statusbar.SetMessage("No file to reopen", "error")
+		return
+	}
+	e.showEncodingDialog()
+	e.encodingReopen = true
+}
+
+// chooseEncoding closes the encoding dialog and applies its choice: a new
+// save encoding, or re-reading the file when opened for Reopen with Encoding
+func (e *Editor) chooseEncoding(encoding *enc.Encoding) {
+	e.mode = ModeNormal
+	if e.encodingReopen {
+		e.reopenWithEncoding(encoding)
+	} else {
+		e.applyEncoding(encoding)
+	}
+}
+
+// reopenWithEncoding re-reads the active file as encoding, asking first
+// when that would discard unsaved changes
+func (e *Editor) reopenWithEncoding(encoding *enc.Encoding) {
+	if e.activeDoc().modified {
+		e.confirmDiscard("Reopen", "This buffer has unsaved changes.\nDiscard them and reopen as "+encoding.Name+"?", "Discard", 'd', func() tea.Cmd {
+			e.doReopenWithEncoding(encoding)
+			return nil
+		})
+		return
+	}
+	e.doReopenWithEncoding(encoding)
+}
+
+// doReopenWithEncoding replaces the buffer with the file's bytes decoded as
+// encoding, which also becomes the save encoding. The cursor stays on its line.
+func (e *Editor) doReopenWithEncoding(encoding *enc.Encoding) {
+	doc := e.activeDoc()
+	raw, err := os.ReadFile(doc.filename)
+	if err != nil {
+		e.statusbar.SetMessage("Reopen failed: "+err.Error(), "error")
+		return
+	}
+	content, err := enc.DecodeToUTF8(raw, encoding)
+	if err != nil {
+		e.statusbar.SetMessage("Cannot read as "+encoding.Name+": "+err.Error(), "error")
+		return
+	}
+
+	line := doc.cursor.Line()
+	doc.buffer = NewBufferFromString(string(content))
+	doc.cursor = NewCursor(doc.buffer)
+	doc.cursor.SetPosition(min(line, doc.buffer.LineCount()-1), 0)
+	doc.selection.Clear()
+	doc.undoStack.Clear()
+	doc.multiSel = nil
+	doc.autoClosers = nil
+	doc.modified = false
+	doc.autosaved = false
+	doc.changedOnDisk = false
+	if info, err := os.Stat(doc.filename); err == nil {
+		doc.modTime = info.ModTime()
+	}
+	doc.encoding = encoding
+	doc.hexView = false
+	doc.hexData = nil
+	doc.follow = false
+	doc.highlighter.SetFile(doc.filename)
+	doc.markSaved()
+	if e.detectIndentEnabled() {
+		detectDocIndent(doc)
+	}
+
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+	e.updateMenuState()
+	if bad := strings.Count(string(content), "\uFFFD"); bad > 0 {
+		e.statusbar.SetMessage(fmt.Sprintf("Reopened as %s (%d characters could not be decoded)", encoding.Name, bad), "warning")
+	} else {
+		e.statusbar.SetMessage("Reopened as "+encoding.Name, "info")
+	}
+}
@@ -0,0 +1,42 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	enc "github.com/cornish/textivus-editor/encoding"
+)
+
+func TestReopenWithEncoding(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "menu.txt")
+	os.WriteFile(path, []byte("one\ncaf\xc3\xa9\n"), 0644)
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	e.activeDoc().cursor.SetPosition(1, 2)
+
+	e.showReopenEncodingDialog()
+	e.encodingIndex = slices.Index(enc.GetSupportedEncodings(), enc.GetEncodingByID("iso-8859-1"))
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	doc := e.activeDoc()
+	if doc.buffer.String() != "one\ncafÃ©\n" || doc.modified || doc.cursor.Line() != 1 {
+		t.Errorf("reopened as %q (modified %v, line %d)", doc.buffer.String(), doc.modified, doc.cursor.Line())
+	}
+
+	// Unsaved changes are confirmed before they are thrown away
+	doc.modified = true
+	e.reopenWithEncoding(enc.GetEncodingByID("utf-8"))
+	if e.mode != ModeConfirm || doc.buffer.String() != "one\ncafÃ©\n" {
+		t.Fatalf("reopening a modified buffer should ask first")
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if e.activeDoc().buffer.String() != "one\ncafé\n" || e.docEncoding().ID != "utf-8" {
+		t.Errorf("after discarding: %q as %s", e.activeDoc().buffer.String(), e.docEncoding().ID)
+	}
+}
@@ -0,0 +1,157 @@
+package editor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// safeWriteFile replaces the file at path with data without ever leaving
+// it half-written: data goes to a temporary file beside it, which is
+// checked for its full length and synced to disk before being renamed over
+// the original. A failed write leaves the original untouched. The new file
+// keeps the original's permissions, owner and extended attributes.
+//
+// Devices and other special files, files that a rename would change in
+// other ways (hard links, another owner) and folders we can't create files
+// in are written in place instead, still checked and synced.
+func safeWriteFile(path string, data []byte) error {
+	// Write through symlinks rather than replacing them
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return writeNew(path, data)
+	}
+	mode := info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	if !info.Mode().IsRegular() || hardLinked(info) {
+		return writeInPlace(path, data, mode)
+	}
+
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		if os.IsPermission(err) {
+			return writeInPlace(path, data, mode)
+		}
+		return err
+	}
+	tmpPath := tmp.Name()
+	if err := writeAndSync(tmp, data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if !keepOwner(tmpPath, info) {
+		os.Remove(tmpPath)
+		return writeInPlace(path, data, mode)
+	}
+	// Set the mode after the owner, since changing owners clears set-user-ID
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	copyXattrs(path, tmpPath)
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// writeNew creates the file at path holding data, removing it again if
+// the write fails
+func writeNew(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if err := writeAndSync(f, data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// writeInPlace overwrites the file at path with data, checking the write
+// and syncing it
+func writeInPlace(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if err := writeAndSync(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeAndSync writes all of data to f and flushes it to disk, so a full
+// disk or exceeded quota shows up here rather than after the save
+func writeAndSync(f *os.File, data []byte) error {
+	n, err := f.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return fmt.Errorf("only %s of %s written: %w", formatFileSize(int64(n)), formatFileSize(int64(len(data))), err)
+	}
+	return f.Sync()
+}
+
+// saveErrorMessage describes a failed save for the user, naming a full
+// disk or exceeded quota plainly
+func saveErrorMessage(err error) string {
+	if reason := diskFullReason(err); reason != "" {
+		return reason
+	}
+	return strings.TrimPrefix(err.Error(), "open ")
+}
+
+// showSaveFailed tells the user a save failed and that the buffer still
+// has their text, offering to save it elsewhere or to try again (calling
+// retry) once space has been freed
+func (e *Editor) showSaveFailed(err error, retry func() bool) {
+	e.statusbar.SetMessage("Save failed: "+saveErrorMessage(err), "error")
+	if e.savingQuietly {
+		return // The buffer stays modified; the next save asks
+	}
+	e.showConfirm(&ConfirmDialog{
+		Saving: true,
+		Title:  "Save Failed",
+		Message: "Cannot save " + filepath.Base(e.activeDoc().filename) + ":\n" + saveErrorMessage(err) +
+			"\nYour text is still in the buffer.",
+		Buttons: []ConfirmButton{
+			{Label: "Save As...", Hotkey: 'a'},
+			{Label: "Retry", Hotkey: 'r'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 0,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.showSaveAs()
+			case 1:
+				retry()
+			default:
+				e.statusbar.SetMessage("Save cancelled", "info")
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("old text\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(path, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := safeWriteFile(link, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("file holds %q", data)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("the symlink was replaced")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("left behind: %v", entries)
+	}
+}
+
+func TestSaveToFullDisk(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full")
+	}
+	e := New()
+	e.statusbar.SetWidth(120)
+	e.insertText("keep me")
+	e.activeDoc().filename = "/dev/full"
+	if e.doSave() {
+		t.Fatal("save to a full disk succeeded")
+	}
+	if e.mode != ModeConfirm || !strings.Contains(e.confirm.Message, "the disk is full") {
+		t.Fatalf("mode %v, dialog %+v", e.mode, e.confirm)
+	}
+	if !e.activeDoc().modified || e.activeDoc().buffer.String() != "keep me" {
+		t.Error("buffer not kept as it was")
+	}
+}
+
+func TestSaveKeepsMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.sh")
+	if err := os.WriteFile(path, []byte("echo hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0750); err != nil {
+		t.Fatal(err)
+	}
+	xattrs := setTestXattr(path, "user.textivus.test", "kept") == nil
+
+	e := New()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	e.insertText("# ")
+	if !e.SaveFile() {
+		t.Fatal("save failed")
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0750 {
+		t.Errorf("mode after save = %v, want 0750", info.Mode().Perm())
+	}
+	if xattrs {
+		if value, err := getTestXattr(path, "user.textivus.test"); err != nil || value != "kept" {
+			t.Errorf("xattr after save = %q, %v", value, err)
+		}
+	}
+
+	// New files get the usual mode
+	fresh := filepath.Join(filepath.Dir(path), "new.txt")
+	if err := safeWriteFile(fresh, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(fresh); info.Mode().Perm()&0600 != 0600 {
+		t.Errorf("new file mode = %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,152 @@
+package editor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// closeListMax is the most unsaved buffer names listed in a close prompt
+const closeListMax = 6
+
+// saveBuffers saves each modified buffer in docs that has a file name, then
+// returns to the buffer being edited. Untitled buffers need Save As and are
+// only counted. It stops on the buffer whose save failed or needs an answer
+// (an external change, a lossy encoding), returning ok false.
+func (e *Editor) saveBuffers(docs []*Document) (saved, untitled int, ok bool) {
+	current := e.activeDoc()
+	for _, doc := range docs {
+		if !doc.modified {
+			continue
+		}
+		if doc.filename == "" {
+			untitled++
+			continue
+		}
+		e.switchToBuffer(slices.Index(e.documents, doc))
+		if !e.doSave() {
+			return saved, untitled, false
+		}
+		saved++
+	}
+	e.switchToBuffer(slices.Index(e.documents, current))
+	return saved, untitled, true
+}
+
+// saveAll saves every modified buffer
+func (e *Editor) saveAll() {
+	saved, untitled, ok := e.saveBuffers(e.documents)
+	if !ok {
+		return // The failed save has set the message or asked its question
+	}
+	switch {
+	case saved == 0 && untitled == 0:
+		e.statusbar.SetMessage("No unsaved buffers", "info")
+	case untitled > 0:
+		e.statusbar.SetMessage(fmt.Sprintf("Saved %d of %d; untitled buffers need Save As", saved, saved+untitled), "info")
+	default:
+		e.statusbar.SetMessage("Saved "+countBuffers(saved), "info")
+	}
+	e.updateMenuState()
+}
+
+// countBuffers formats n as "1 buffer" or "n buffers"
+func countBuffers(n int) string {
+	if n == 1 {
+		return "1 buffer"
+	}
+	return fmt.Sprintf("%d buffers", n)
+}
+
+// closeAll closes every buffer, leaving one empty untitled buffer
+func (e *Editor) closeAll() {
+	e.confirmCloseBuffers("Close All", slices.Clone(e.documents), nil)
+}
+
+// closeOthers closes every buffer except the active one
+func (e *Editor) closeOthers() {
+	current := e.activeDoc()
+	var others []*Document
+	for _, doc := range e.documents {
+		if doc != current {
+			others = append(others, doc)
+		}
+	}
+	if len(others) == 0 {
+		e.statusbar.SetMessage("No other buffers open", "info")
+		return
+	}
+	e.confirmCloseBuffers("Close Others", others, current)
+}
+
+// confirmCloseBuffers closes docs, first asking once about all of them that
+// have unsaved changes: save them and close, discard them, or cancel
+func (e *Editor) confirmCloseBuffers(title string, docs []*Document, keep *Document) {
+	var unsaved []string
+	for _, doc := range docs {
+		if doc.modified {
+			unsaved = append(unsaved, e.bufferName(doc))
+		}
+	}
+	if len(unsaved) == 0 {
+		e.closeBuffers(docs, keep)
+		return
+	}
+
+	header := fmt.Sprintf("%d buffers have unsaved changes:", len(unsaved))
+	if len(unsaved) == 1 {
+		header = "1 buffer has unsaved changes:"
+	}
+	if len(unsaved) > closeListMax {
+		more := len(unsaved) - closeListMax + 1
+		unsaved = append(unsaved[:closeListMax-1], fmt.Sprintf("and %d more", more))
+	}
+	e.showConfirm(&ConfirmDialog{
+		Title:   title,
+		Message: header + "\n" + strings.Join(unsaved, "\n"),
+		Buttons: []ConfirmButton{
+			{Label: "Save All", Hotkey: 's'},
+			{Label: "Discard", Hotkey: 'd', Danger: true},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 2,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				_, untitled, ok := e.saveBuffers(docs)
+				if !ok {
+					return nil
+				}
+				if untitled > 0 {
+					e.statusbar.SetMessage("Untitled buffers need Save As before closing", "error")
+					e.updateMenuState()
+					return nil
+				}
+				e.closeBuffers(docs, keep)
+			case 1:
+				e.closeBuffers(docs, keep)
+			default:
+				e.statusbar.SetMessage("Cancelled", "info")
+			}
+			return nil
+		},
+	})
+}
+
+// closeBuffers closes docs without asking, then returns to keep
+func (e *Editor) closeBuffers(docs []*Document, keep *Document) {
+	for _, doc := range docs {
+		if i := slices.Index(e.documents, doc); i >= 0 {
+			e.switchToBuffer(i)
+			e.doCloseFile()
+		}
+	}
+	if i := slices.Index(e.documents, keep); keep != nil && i >= 0 {
+		e.switchToBuffer(i)
+	}
+	e.statusbar.SetMessage("Closed "+countBuffers(len(docs)), "info")
+	e.updateMenuState()
+}
@@ -0,0 +1,84 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openBuffers loads each named file from dir into its own buffer
+func openBuffers(t *testing.T, e *Editor, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSaveAll(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	openBuffers(t, e, root, "a.txt", "b.txt", "c.txt")
+	for _, i := range []int{0, 2} {
+		e.documents[i].buffer.Replace(0, 0, "edited ")
+		e.documents[i].modified = true
+	}
+
+	e.saveAll()
+	if e.bufferName(e.activeDoc()) != "c.txt" {
+		t.Errorf("Save All should return to the active buffer")
+	}
+	for _, name := range []string{"a.txt", "c.txt"} {
+		data, _ := os.ReadFile(filepath.Join(root, name))
+		if string(data) != "edited "+name {
+			t.Errorf("%s = %q, want it saved", name, data)
+		}
+	}
+	for _, doc := range e.documents {
+		if doc.modified {
+			t.Errorf("%s still modified after Save All", e.bufferName(doc))
+		}
+	}
+}
+
+func TestCloseOthersAsksOnce(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	openBuffers(t, e, root, "a.txt", "b.txt", "c.txt", "d.txt")
+	e.documents[0].modified = true
+	e.documents[1].modified = true
+	e.documents[3].modified = true // The active buffer, which is kept
+
+	e.closeOthers()
+	if e.mode != ModeConfirm {
+		t.Fatalf("Close Others with unsaved buffers should ask")
+	}
+	msg := e.confirm.Message
+	if !strings.HasPrefix(msg, "2 buffers have") || !strings.Contains(msg, "a.txt\nb.txt") || strings.Contains(msg, "d.txt") {
+		t.Errorf("prompt %q should list a.txt and b.txt only", msg)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if len(e.documents) != 1 || e.bufferName(e.activeDoc()) != "d.txt" || !e.activeDoc().modified {
+		t.Errorf("after discarding: %v, want only the modified d.txt kept", e.bufferNames())
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	openBuffers(t, e, t.TempDir(), "a.txt", "b.txt")
+	e.closeAll()
+	if e.mode != ModeNormal || len(e.documents) != 1 || e.activeDoc().filename != "" {
+		t.Errorf("Close All without unsaved changes should leave one untitled buffer")
+	}
+}
@@ -0,0 +1,226 @@
+package editor
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/syntax"
+)
+
+// searchScope limits find matches to one kind of text, as classified by the
+// syntax highlighter's lexer
+type searchScope int
+
+const (
+	scopeAll      searchScope = iota
+	scopeCode                 // Outside comments and strings
+	scopeComments             // Inside comments
+	scopeStrings              // Inside string literals
+)
+
+// scopeLabels are shown in the find bar for each scope (none for scopeAll)
+var scopeLabels = [...]string{"", "Code", "Comments", "Strings"}
+
+// cycleSearchScope moves the find bar to the next scope filter
+func (e *Editor) cycleSearchScope() {
+	e.searchScope = (e.searchScope + 1) % searchScope(len(scopeLabels))
+	if e.searchScope == scopeAll {
+		e.statusbar.SetMessage("Searching everywhere", "info")
+		return
+	}
+	msg := "Searching " + strings.ToLower(scopeLabels[e.searchScope]) + " only"
+	if !e.activeDoc().highlighter.HasLexer() {
+		msg += " (no syntax for this file: all text is code)"
+	}
+	e.statusbar.SetMessage(msg, "info")
+}
+
+// searchOptions returns the find options from the config
+func (e *Editor) searchOptions() config.EditorConfig {
+	if e.config == nil {
+		return config.DefaultConfig().Editor
+	}
+	return e.config.Editor
+}
+
+// toggleSearchOption flips one of the find bar options (Alt+C, Alt+W, Alt+P)
+// and saves it to the config
+func (e *Editor) toggleSearchOption(key string) {
+	if e.config == nil {
+		e.config = config.DefaultConfig()
+	}
+	cfg := &e.config.Editor
+	var on bool
+	var msg string
+	switch key {
+	case "c":
+		cfg.SearchIgnoreCase = !cfg.SearchIgnoreCase
+		on, msg = cfg.SearchIgnoreCase, "Ignore case"
+	case "w":
+		cfg.SearchWholeWord = !cfg.SearchWholeWord
+		on, msg = cfg.SearchWholeWord, "Whole word"
+	case "p":
+		cfg.SearchWrap = !cfg.SearchWrap
+		on, msg = cfg.SearchWrap, "Wrap around"
+	default:
+		return
+	}
+	if on {
+		msg += " on"
+	} else {
+		msg += " off"
+	}
+	e.statusbar.SetMessage(msg, "info")
+	e.saveConfig()
+}
+
+// findBarLabel is the find field's label, naming any scope and options
+// that differ from a plain wrapping, case-sensitive search
+func (e *Editor) findBarLabel() string {
+	label := "Find"
+	if scope := scopeLabels[e.searchScope]; scope != "" {
+		label += " in " + scope
+	}
+	opts := e.searchOptions()
+	var flags []string
+	if opts.SearchIgnoreCase {
+		flags = append(flags, "any case")
+	}
+	if opts.SearchWholeWord {
+		flags = append(flags, "whole word")
+	}
+	if !opts.SearchWrap {
+		flags = append(flags, "no wrap")
+	}
+	if len(flags) > 0 {
+		label += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return label + ": "
+}
+
+// findMatches returns the non-overlapping matches of query in content, in
+// order, honoring the case and whole-word options and the find bar's scope
+func (e *Editor) findMatches(content, query string) []multiRange {
+	if query == "" {
+		return nil
+	}
+	opts := e.searchOptions()
+	var matches []multiRange
+	if opts.SearchIgnoreCase {
+		re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+		for _, loc := range re.FindAllStringIndex(content, -1) {
+			matches = append(matches, multiRange{start: loc[0], end: loc[1]})
+		}
+	} else {
+		for offset := 0; offset < len(content); {
+			idx := strings.Index(content[offset:], query)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			matches = append(matches, multiRange{start: start, end: start + len(query)})
+			offset = start + len(query)
+		}
+	}
+	if opts.SearchWholeWord {
+		kept := matches[:0]
+		for _, m := range matches {
+			if isWholeWord(content, m) {
+				kept = append(kept, m)
+			}
+		}
+		matches = kept
+	}
+	if e.searchScope == scopeAll || len(matches) == 0 {
+		return matches
+	}
+
+	regions := e.activeDoc().highlighter.Regions(content)
+	kept := matches[:0]
+	for _, m := range matches {
+		if inScope(regions, m, e.searchScope) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// isWholeWord reports whether match m is not joined to letters, digits or
+// underscores on either side
+func isWholeWord(content string, m multiRange) bool {
+	isWord := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	if m.start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(content[:m.start]); isWord(r) {
+			return false
+		}
+	}
+	if m.end < len(content) {
+		if r, _ := utf8.DecodeRuneInString(content[m.end:]); isWord(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextMatch returns the first match starting at or after pos, wrapping to
+// the first match when the search-wrap option allows. ok is false when
+// there is no such match.
+func (e *Editor) nextMatch(matches []multiRange, pos int) (m multiRange, wrapped, ok bool) {
+	for _, m := range matches {
+		if m.start >= pos {
+			return m, false, true
+		}
+	}
+	if len(matches) > 0 && e.searchOptions().SearchWrap {
+		return matches[0], true, true
+	}
+	return multiRange{}, false, false
+}
+
+// inScope reports whether match m lies in the given scope. Comment and
+// string matches must sit entirely inside one region of that class; code
+// matches must not touch any comment or string.
+func inScope(regions []syntax.Region, m multiRange, scope searchScope) bool {
+	for _, r := range regions {
+		if r.End <= m.start || r.Start >= m.end {
+			continue // No overlap
+		}
+		switch scope {
+		case scopeComments:
+			return r.Class == syntax.ClassComment && r.Start <= m.start && m.end <= r.End
+		case scopeStrings:
+			return r.Class == syntax.ClassString && r.Start <= m.start && m.end <= r.End
+		default:
+			return false
+		}
+	}
+	return scope == scopeCode
+}
+
+// handleSearchOptionKey handles Alt+S (scope) and Alt+C, Alt+W, Alt+P
+// (options) in the find and replace bars
+func (e *Editor) handleSearchOptionKey(key string) {
+	if key == "s" {
+		e.cycleSearchScope()
+		return
+	}
+	e.toggleSearchOption(key)
+}
+
+// replaceMatches returns content with every match replaced by repl
+func replaceMatches(content string, matches []multiRange, repl string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(content[last:m.start])
+		sb.WriteString(repl)
+		last = m.end
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+package editor
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSearchScope(t *testing.T) {
+	content := "// total is the sum\ntotal := add(\"total\")\n"
+	e := newMarkupTestEditor("main.go", content, 0)
+	e.findQuery = "total"
+
+	tests := []struct {
+		scope searchScope
+		want  []int
+	}{
+		{scopeAll, []int{3, 20, 34}},
+		{scopeCode, []int{20}},
+		{scopeComments, []int{3}},
+		{scopeStrings, []int{34}},
+	}
+	for _, tt := range tests {
+		e.searchScope = tt.scope
+		matches := e.findMatches(content, e.findQuery)
+		var got []int
+		for _, m := range matches {
+			got = append(got, m.start)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("scope %d: matches at %v, want %v", tt.scope, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("scope %d: matches at %v, want %v", tt.scope, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFindBarScopeToggle(t *testing.T) {
+	e := newMarkupTestEditor("main.go", "x := 1 // x\nx++\n", 0)
+	e.mode = ModeFind
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}, Alt: true})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}, Alt: true})
+	if e.searchScope != scopeComments || e.findQuery != "x" {
+		t.Fatalf("scope = %d, query = %q; want comments and the query untouched", e.searchScope, e.findQuery)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := e.activeDoc().selection.Anchor; got != 10 {
+		t.Errorf("find next selected offset %d, want the x in the comment at 10", got)
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Toggling saves the config
+	content := "Total totals; TOTAL_x total"
+	e := newMarkupTestEditor("notes.txt", content, 0)
+	e.mode = ModeFind
+	e.findQuery = "total"
+
+	starts := func() []int {
+		var got []int
+		for _, m := range e.findMatches(content, e.findQuery) {
+			got = append(got, m.start)
+		}
+		return got
+	}
+	if got := starts(); len(got) != 2 || got[0] != 6 || got[1] != 22 {
+		t.Errorf("case-sensitive matches at %v, want [6 22]", got)
+	}
+
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}, Alt: true})
+	if got := starts(); len(got) != 4 {
+		t.Errorf("ignoring case, matches at %v, want 4", got)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}, Alt: true})
+	if got := starts(); len(got) != 2 || got[0] != 0 || got[1] != 22 {
+		t.Errorf("whole words ignoring case, matches at %v, want [0 22]", got)
+	}
+	if got := e.findBarLabel(); got != "Find (any case, whole word): " {
+		t.Errorf("findBarLabel() = %q", got)
+	}
+
+	// Without wrap, find next stops at the last match
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}, Alt: true})
+	e.activeDoc().cursor.SetByteOffset(22)
+	e.findNext()
+	if got := e.activeDoc().cursor.ByteOffset(); got != 22 {
+		t.Errorf("find next without wrap moved to %d, want to stay at 22", got)
+	}
+
+	// saveConfig writes these in the background
+	if opts := e.config.Editor; !opts.SearchIgnoreCase || !opts.SearchWholeWord || opts.SearchWrap {
+		t.Errorf("options not stored in the config: %+v", opts)
+	}
+}
+
+func TestReplaceAllIgnoringCase(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "Cat cat CAT", 0)
+	e.config.Editor.SearchIgnoreCase = true
+	e.findQuery = "cat"
+	e.replaceQuery = "dog"
+	e.replaceAll()
+	if got := e.activeDoc().buffer.String(); got != "dog dog dog" {
+		t.Errorf("buffer = %q, want every case replaced", got)
+	}
+}
@@ -0,0 +1,22 @@
+package editor
+
+import "github.com/cornish/textivus-editor/ui"
+
+// StatusSegmentMsg updates the text of a registered status bar segment.
+// Background work (a git watcher, an LSP client) sends it through
+// tea.Program.Send rather than touching the status bar from its own goroutine.
+type StatusSegmentMsg struct {
+	Name string
+	Text string
+}
+
+// RegisterStatusSegment adds a segment to the status bar, replacing any
+// segment already registered under the same name
+func (e *Editor) RegisterStatusSegment(seg ui.StatusSegment) {
+	e.statusbar.RegisterSegment(seg)
+}
+
+// UnregisterStatusSegment removes a status bar segment
+func (e *Editor) UnregisterStatusSegment(name string) {
+	e.statusbar.UnregisterSegment(name)
+}
@@ -0,0 +1,191 @@
+package editor
+
+import (
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/cornish/textivus-editor/snippets"
+)
+
+// snippetSession tracks the tab stops of an expanded snippet. The current
+// stop is edited through the multi-selection, so mirrored copies change
+// together; the session ends when the multi-selection does.
+type snippetSession struct {
+	stops   [][]multiRange // Ranges of each stop, in Tab order; the last is where the cursor ends up
+	current int
+}
+
+// snippetSet returns the user's snippets, reloading them when the file has
+// changed since they were read
+func (e *Editor) snippetSet() *snippets.Set {
+	path, err := config.SnippetsPath()
+	if err != nil {
+		return e.snippets
+	}
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	if e.snippets != nil && modTime.Equal(e.snippetsModTime) {
+		return e.snippets
+	}
+	e.snippets, err = snippets.Load(path)
+	e.snippetsModTime = modTime
+	if err != nil {
+		e.statusbar.SetMessage("Snippets: "+err.Error(), "error")
+	}
+	return e.snippets
+}
+
+// snippetPrefix returns the word just before the cursor
+func snippetPrefix(line string, col int) string {
+	start := col
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(line[:start])
+		if !isWordChar(r) {
+			break
+		}
+		start -= size
+	}
+	return line[start:col]
+}
+
+// indentSnippet fits a snippet body to the line it is expanded on: later
+// lines get the line's indentation, and tabs leading a line become one
+// indent level each
+func (e *Editor) indentSnippet(body, lineIndent string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, "\t")
+		line = strings.Repeat(e.getIndentString(), len(line)-len(trimmed)) + trimmed
+		if i > 0 && line != "" {
+			line = lineIndent + line
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// expandSnippet replaces the word before the cursor with the snippet it is
+// the prefix of, if any, and selects the first tab stop
+func (e *Editor) expandSnippet() bool {
+	if e.refuseEdit() {
+		return false
+	}
+	doc := e.activeDoc()
+	set := e.snippetSet()
+	if set == nil {
+		return false
+	}
+	line := doc.buffer.Lines()[doc.cursor.Line()]
+	prefix := snippetPrefix(line, doc.cursor.Col())
+	if prefix == "" {
+		return false
+	}
+	body, ok := set.Lookup(doc.highlighter.Language(), prefix)
+	if !ok {
+		return false
+	}
+
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	text, stops := snippets.Expand(e.indentSnippet(body, indent))
+	end := doc.cursor.ByteOffset()
+	start := end - len(prefix)
+	entry := &UndoEntry{
+		Position:     start,
+		Deleted:      prefix,
+		Inserted:     text,
+		CursorBefore: end,
+	}
+	doc.buffer.Replace(start, end, text)
+	doc.cursor.SetByteOffset(start + len(text))
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+
+	// Group the copies of each stop, ending at $0 or the end of the snippet
+	session := &snippetSession{}
+	final := []multiRange{{start + len(text), start + len(text)}}
+	last := -1
+	for _, stop := range stops {
+		r := multiRange{start + stop.Start, start + stop.End}
+		switch {
+		case stop.Index == 0:
+			if last != 0 {
+				final = nil
+			}
+			final = append(final, r)
+		case stop.Index == last:
+			session.stops[len(session.stops)-1] = append(session.stops[len(session.stops)-1], r)
+		default:
+			session.stops = append(session.stops, []multiRange{r})
+		}
+		last = stop.Index
+	}
+	session.stops = append(session.stops, final)
+	doc.snippet = session
+	e.goToSnippetStop(0)
+	return true
+}
+
+// goToSnippetStop selects every copy of a tab stop. Reaching the last stop
+// places the cursor there and ends the snippet.
+func (e *Editor) goToSnippetStop(i int) {
+	doc := e.activeDoc()
+	session := doc.snippet
+	session.current = i
+	ranges := session.stops[i]
+	if i == len(session.stops)-1 {
+		e.clearMultiSelection()
+		doc.cursor.SetByteOffset(ranges[0].start)
+	} else {
+		doc.multiSel = append([]multiRange(nil), ranges...)
+		doc.selection.Clear()
+		doc.cursor.SetByteOffset(ranges[0].end)
+	}
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// nextSnippetStop moves delta stops through the active snippet
+func (e *Editor) nextSnippetStop(delta int) {
+	session := e.activeDoc().snippet
+	e.goToSnippetStop(max(0, min(session.current+delta, len(session.stops)-1)))
+}
+
+// remap moves the snippet's stops through a multi-selection edit. The
+// current stop grows to take in text typed at its edges; the others are
+// pushed aside.
+func (s *snippetSession) remap(edits []multiEdit, length int) {
+	for i, ranges := range s.stops {
+		current := i == s.current
+		for j, r := range ranges {
+			start := mapOffset(r.start, edits, !current)
+			end := max(mapOffset(r.end, edits, current), start)
+			ranges[j] = multiRange{min(start, length), min(end, length)}
+		}
+	}
+}
+
+// mapOffset returns where p ends up after edits, which are in document
+// order and given in offsets from before any of them. With after set, p
+// moves past text inserted exactly at it; otherwise it stays in front.
+func mapOffset(p int, edits []multiEdit, after bool) int {
+	shift := 0
+	for _, ed := range edits {
+		switch {
+		case ed.end < p || ed.end == p && (ed.start < p || after):
+			shift += len(ed.repl) - (ed.end - ed.start)
+		case ed.start < p:
+			if after {
+				return ed.start + shift + len(ed.repl)
+			}
+			return ed.start + shift
+		default:
+			return p + shift
+		}
+	}
+	return p + shift
+}
@@ -0,0 +1,87 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+// writeSnippets writes the user's snippets file
+func writeSnippets(t *testing.T, data string) {
+	t.Helper()
+	path, err := config.SnippetsPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnippetTabStops(t *testing.T) {
+	e := loadSymbolFiles(t, map[string]string{"main.go": "package main\n\nfunc main() {\n\tfor\n}\n"}, "main.go")
+	writeSnippets(t, "[Go]\nfor = \"for ${1:i} := 0; $1 < ${2:n}; $1++ {\\n\\t$0\\n}\"\n")
+	doc := e.activeDoc()
+	doc.cursor.SetPosition(3, 4)
+	indent := e.getIndentString()
+
+	// Tab expands the prefix, indented to its line, with every copy of $1 selected
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	want := "\tfor i := 0; i < n; i++ {\n\t" + indent + "\n\t}"
+	if got := strings.Join(doc.buffer.Lines()[3:6], "\n"); got != want {
+		t.Fatalf("expanded to %q, want %q", got, want)
+	}
+	if len(doc.multiSel) != 3 {
+		t.Fatalf("want the 3 copies of $1 selected, got %v", doc.multiSel)
+	}
+
+	// Typing fills in the mirrors; Tab moves on to $2
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("idx")})
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("10")})
+	if got := doc.buffer.Lines()[3]; got != "\tfor idx := 0; idx < 10; idx++ {" {
+		t.Fatalf("line = %q", got)
+	}
+
+	// Shift+Tab goes back and selects what was typed
+	e.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	for _, r := range doc.multiSel {
+		if text := doc.buffer.Substring(r.start, r.end); text != "idx" {
+			t.Errorf("stop 1 copy = %q, want idx", text)
+		}
+	}
+
+	// Past the last stop the cursor lands on $0 and the snippet ends
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if line, col := doc.cursor.Line(), doc.cursor.Col(); line != 4 || col != 1+len(indent) || doc.snippet != nil || doc.multiSel != nil {
+		t.Errorf("cursor %d:%d, snippet %v: want the snippet ended at 4:%d", line, col, doc.snippet, 1+len(indent))
+	}
+}
+
+func TestSnippetFallsBackToIndent(t *testing.T) {
+	e := loadSymbolFiles(t, map[string]string{"notes.txt": "todo\nfor"}, "notes.txt")
+	writeSnippets(t, "[Go]\nfor = \"for {}\"\n[\"*\"]\ntodo = \"TODO: \"\n")
+	doc := e.activeDoc()
+
+	doc.cursor.SetPosition(1, 3)
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := doc.buffer.Lines()[1]; got != "for"+e.getIndentString() {
+		t.Errorf("a Go snippet expanded in a text file: %q", got)
+	}
+
+	// Snippets for every language apply; with no stops the cursor goes to the end
+	doc.cursor.SetPosition(0, 4)
+	e.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := doc.buffer.Lines()[0]; got != "TODO: " || doc.cursor.Col() != 6 || doc.multiSel != nil {
+		t.Errorf("line %q, col %d", got, doc.cursor.Col())
+	}
+}
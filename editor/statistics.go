@@ -0,0 +1,129 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+// statisticsWidth is the width of the statistics dialog
+const statisticsWidth = 52
+
+// showStatistics opens the Statistics dialog for the active buffer
+func (e *Editor) showStatistics() {
+	e.dialogScroll = 0
+	e.mode = ModeStatistics
+}
+
+// statisticsRows returns the label/value rows shown in the statistics dialog
+func (e *Editor) statisticsRows() [][2]string {
+	doc := e.activeDoc()
+	content := doc.buffer.String()
+	lines := doc.buffer.Lines()
+
+	name := e.bufferName(doc)
+	encoding := "UTF-8"
+	if doc.encoding != nil {
+		encoding = doc.encoding.Name
+	}
+
+	size := groupThousands(len(content)) + " bytes"
+	if len(content) >= 1024 {
+		size += " (" + formatFileSize(int64(len(content))) + ")"
+	}
+
+	longest, longestLine := 0, 0
+	tabWidth := e.fileSettings().TabWidth
+	for i, line := range lines {
+		if w := ui.VisualWidth(strings.TrimSuffix(line, "\r"), tabWidth); w > longest {
+			longest, longestLine = w, i
+		}
+	}
+
+	indent := "None found"
+	if tabs, width := detectIndent(lines); tabs {
+		indent = "Tabs"
+	} else if width > 0 {
+		indent = fmt.Sprintf("%d spaces", width)
+	}
+
+	undoCount, redoCount := doc.undoStack.Len()
+	undoUsage := formatFileSize(int64(doc.undoStack.MemoryUsage()))
+	if budget := doc.undoStack.MaxBytes(); budget > 0 {
+		undoUsage += " of " + formatFileSize(int64(budget))
+	}
+
+	rows := [][2]string{
+		{"File", name},
+		{"Lines", groupThousands(len(lines))},
+		{"Words", groupThousands(doc.buffer.WordCount())},
+		{"Characters", groupThousands(utf8.RuneCountInString(content))},
+		{"Size", size},
+		{"Longest line", fmt.Sprintf("%s columns (line %s)", groupThousands(longest), groupThousands(longestLine+1))},
+		{"Encoding", encoding},
+		{"Line endings", lineEndingName(content)},
+		{"Indentation", indent},
+	}
+	if words, chars, lines, ok := e.selectionCounts(); ok {
+		count := func(n int, unit string) string {
+			if n != 1 {
+				unit += "s"
+			}
+			return groupThousands(n) + " " + unit
+		}
+		rows = append(rows, [2]string{"Selection", count(words, "word") + ", " + count(chars, "char") + ", " + count(lines, "line")})
+	}
+	return append(rows,
+		[2]string{"Undo steps", fmt.Sprintf("%d undo, %d redo", undoCount, redoCount)},
+		[2]string{"Undo memory", undoUsage},
+	)
+}
+
+// selectionCounts counts the words, characters and lines of the selection,
+// or reports false when nothing is selected. A selection ending at the start
+// of a line doesn't count that line.
+func (e *Editor) selectionCounts() (words, chars, lines int, ok bool) {
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() {
+		return 0, 0, 0, false
+	}
+	text := doc.selection.GetText(doc.buffer)
+	lines = strings.Count(text, "\n") + 1
+	if strings.HasSuffix(text, "\n") {
+		lines--
+	}
+	return countWords(text), utf8.RuneCountInString(text), lines, true
+}
+
+// statisticsDialog builds the statistics dialog
+func (e *Editor) statisticsDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(statisticsWidth)
+	db.AddTitleBorder(" Statistics ")
+	db.AddEmptyLine()
+	for _, row := range e.statisticsRows() {
+		db.AddText(fmt.Sprintf(" %-13s %s", row[0]+":", row[1]))
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("Press any key to close")
+	db.AddBottomBorder()
+	db.Scroll(e.dialogScroll)
+	return db
+}
+
+// overlayStatisticsDialog overlays the statistics dialog centered on the viewport
+func (e *Editor) overlayStatisticsDialog(viewportContent string) string {
+	return e.statisticsDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// cursorLineLength returns the cursor line's width in columns for the
+// status bar, or -1 when the status bar doesn't show it
+func (e *Editor) cursorLineLength() int {
+	if e.config == nil || !e.config.Editor.StatusLineLength {
+		return -1
+	}
+	doc := e.activeDoc()
+	line := doc.buffer.Substring(doc.buffer.LineStartOffset(doc.cursor.Line()), doc.buffer.LineEndOffset(doc.cursor.Line()))
+	return ui.VisualWidth(strings.TrimSuffix(line, "\r"), e.fileSettings().TabWidth)
+}
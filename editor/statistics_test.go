@@ -0,0 +1,68 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStatisticsRows(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString("func main() {\r\n\tfmt.Println(\"hi\")\r\n}\r\n")
+	rows := make(map[string]string)
+	for _, row := range e.statisticsRows() {
+		rows[row[0]] = row[1]
+	}
+	want := map[string]string{
+		"Lines":        "4",
+		"Words":        "5",
+		"Size":         "38 bytes",
+		"Longest line": "21 columns (line 2)",
+		"Line endings": "CRLF",
+		"Indentation":  "Tabs",
+	}
+	for label, value := range want {
+		if rows[label] != value {
+			t.Errorf("%s = %q, want %q", label, rows[label], value)
+		}
+	}
+	if _, ok := rows["Selection"]; ok {
+		t.Error("no Selection row without a selection")
+	}
+
+	doc.selection.Start(0)
+	doc.selection.Update(doc.buffer.LineStartOffset(1))
+	selection := ""
+	for _, row := range e.statisticsRows() {
+		if row[0] == "Selection" {
+			selection = row[1]
+		}
+	}
+	if selection != "3 words, 15 chars, 1 line" {
+		t.Errorf("Selection = %q, want the first line's counts", selection)
+	}
+}
+
+func TestStatusBarSelectionCounts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 100, Height: 10})
+	e.activeDoc().buffer.Replace(0, 0, "one two three\nfour\tfive")
+	e.activeDoc().selection.Start(4)
+	e.activeDoc().selection.Update(18)
+	e.View()
+	if view := e.statusbar.View(); !strings.Contains(view, "Sel W:3 C:14 L:2 | Ln 1, Col 1 |") {
+		t.Errorf("status bar = %q, want the selection's counts", view)
+	}
+
+	e.activeDoc().selection.Clear()
+	e.config.Editor.StatusLineLength = true
+	e.activeDoc().cursor.SetPosition(1, 0)
+	e.View()
+	if view := e.statusbar.View(); !strings.Contains(view, "W:5 C:23 | Ln 2, Col 1, Len 12 |") {
+		t.Errorf("status bar = %q, want the buffer's counts and the line length", view)
+	}
+}
@@ -0,0 +1,106 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// openSummary describes the active buffer's conventions for the status bar
+// after a file is opened, e.g. "UTF-8, LF, spaces:4, 1,204 lines, Go"
+func (e *Editor) openSummary() string {
+	doc := e.activeDoc()
+	lines := doc.buffer.Lines()
+
+	encoding := "UTF-8"
+	if doc.encoding != nil {
+		encoding = doc.encoding.Name
+	}
+
+	indent := "tabs"
+	tabs, width := detectIndent(lines)
+	if width < 0 {
+		// Nothing indented yet: report what Tab will insert
+		fs := e.fileSettings()
+		tabs, width = !fs.TabsToSpaces, fs.TabWidth
+	}
+	if !tabs {
+		indent = fmt.Sprintf("spaces:%d", width)
+	}
+
+	count := groupThousands(len(lines)) + " lines"
+	if len(lines) == 1 {
+		count = "1 line"
+	}
+
+	parts := []string{encoding, lineEndingName(doc.buffer.String()), indent, count}
+	if lang := doc.highlighter.Language(); lang != "" {
+		parts = append(parts, lang)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lineEndingName reports the line ending style of s: "LF", "CRLF" or "Mixed"
+func lineEndingName(s string) string {
+	crlf := strings.Count(s, "\r\n")
+	lf := strings.Count(s, "\n") - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		return "Mixed"
+	case crlf > 0:
+		return "CRLF"
+	}
+	return "LF"
+}
+
+// detectIndent guesses the indentation style from lines. It returns whether
+// most indented lines use tabs and, for spaces, the most common step between
+// indentation levels. width is -1 if no line is indented.
+func detectIndent(lines []string) (tabs bool, width int) {
+	tabLines, spaceLines := 0, 0
+	steps := make(map[int]int)
+	prev := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		switch line[0] {
+		case '\t':
+			tabLines++
+			continue
+		case ' ':
+			spaceLines++
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if step := n - prev; step > 1 || step < -1 {
+			if step < 0 {
+				step = -step
+			}
+			steps[step]++
+		}
+		prev = n
+	}
+
+	if tabLines == 0 && spaceLines == 0 {
+		return false, -1
+	}
+	if tabLines >= spaceLines {
+		return true, 0
+	}
+	width, best := 4, 0
+	for step, n := range steps {
+		if n > best || n == best && step < width {
+			width, best = step, n
+		}
+	}
+	return false, width
+}
+
+// groupThousands formats n with comma separators, e.g. 1204 -> "1,204"
+func groupThousands(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectIndent(t *testing.T) {
+	tests := []struct {
+		text  string
+		tabs  bool
+		width int
+	}{
+		{"func f() {\n\treturn\n}", true, 0},
+		{"a:\n  b:\n    c: 1\n  d: 2", false, 2},
+		{"def f():\n    if x:\n        pass\n    return", false, 4},
+		{"no\nindent\nhere", false, -1},
+		{"/*\n * doc comment\n */\nx", false, 4},
+	}
+	for _, tt := range tests {
+		tabs, width := detectIndent(strings.Split(tt.text, "\n"))
+		if tabs != tt.tabs || width != tt.width {
+			t.Errorf("detectIndent(%q) = %v, %d, want %v, %d", tt.text, tabs, width, tt.tabs, tt.width)
+		}
+	}
+}
+
+func TestLineEndingName(t *testing.T) {
+	for text, want := range map[string]string{
+		"a\nb\n":     "LF",
+		"a\r\nb\r\n": "CRLF",
+		"a\r\nb\n":   "Mixed",
+		"single":     "LF",
+	} {
+		if got := lineEndingName(text); got != want {
+			t.Errorf("lineEndingName(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestGroupThousands(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1204: "1,204", 1234567: "1,234,567"} {
+		if got := groupThousands(n); got != want {
+			t.Errorf("groupThousands(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestOpenSummary(t *testing.T) {
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome) // Opening files updates the recent lists
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln()\n}\n"), 0644)
+
+	e := New()
+	e.statusbar.SetWidth(120)
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if want := "UTF-8, LF, tabs, 6 lines, Go"; !strings.Contains(e.statusbar.View(), want) {
+		t.Errorf("status bar = %q, want summary %q", e.statusbar.View(), want)
+	}
+
+	// The summary can be turned off
+	e = New()
+	e.config.Editor.OpenSummary = false
+	e.statusbar.SetWidth(120)
+	if err := e.LoadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(e.statusbar.View(), "tabs") {
+		t.Errorf("status bar = %q, want no summary", e.statusbar.View())
+	}
+}
@@ -0,0 +1,257 @@
+package editor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/cornish/textivus-editor/symbols"
+)
+
+// symbolsWidth is the width of the Go to Symbol dialog
+const symbolsWidth = 70
+
+// maxSymbolScanSize is the largest buffer searched for definitions
+const maxSymbolScanSize = 4 << 20
+
+// symbolEntry is a definition listed in the Go to Symbol dialog
+type symbolEntry struct {
+	doc *Document
+	sym symbols.Symbol
+}
+
+// docSymbols returns the definitions in doc, or nil when its language isn't
+// known or it is too big to scan
+func docSymbols(doc *Document) []symbols.Symbol {
+	if doc.hexView || doc.buffer.Length() > maxSymbolScanSize {
+		return nil
+	}
+	return symbols.Find(doc.highlighter.Language(), doc.buffer.String())
+}
+
+// showGoToSymbol lists the definitions in the active file, with the one
+// the cursor is in selected
+func (e *Editor) showGoToSymbol() {
+	doc := e.activeDoc()
+	if !symbols.Supported(doc.highlighter.Language()) {
+		e.statusbar.SetMessage("Go to Symbol: no symbols for this file type", "info")
+		return
+	}
+	var entries []symbolEntry
+	selected := 0
+	for i, sym := range docSymbols(doc) {
+		entries = append(entries, symbolEntry{doc, sym})
+		if sym.Line <= doc.cursor.Line() {
+			selected = i
+		}
+	}
+	if len(entries) == 0 {
+		e.statusbar.SetMessage("No symbols found", "info")
+		return
+	}
+	e.openSymbolList(" Go to Symbol ", entries, selected)
+}
+
+// openSymbolList opens the symbol dialog on entries
+func (e *Editor) openSymbolList(title string, entries []symbolEntry, selected int) {
+	e.symbolTitle = title
+	e.symbolEntries = entries
+	e.symbolQuery = ""
+	e.symbolIndex = selected
+	e.mode = ModeSymbols
+}
+
+// symbolMatches filters the listed symbols by the query, best match first.
+// Without a query they stay in file order.
+func (e *Editor) symbolMatches() []symbolEntry {
+	if e.symbolQuery == "" {
+		return e.symbolEntries
+	}
+	type scored struct {
+		entry symbolEntry
+		score int
+	}
+	var matches []scored
+	for _, entry := range e.symbolEntries {
+		if score, ok := fuzzyScore(e.symbolQuery, entry.sym.Name); ok {
+			matches = append(matches, scored{entry, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	entries := make([]symbolEntry, len(matches))
+	for i, m := range matches {
+		entries[i] = m.entry
+	}
+	return entries
+}
+
+// symbolLabel formats an entry as its kind and name, with where it is on
+// the right: the line, and the buffer when listing several
+func (e *Editor) symbolLabel(entry symbolEntry, width int, showBuffer bool) string {
+	where := fmt.Sprintf("%d", entry.sym.Line+1)
+	if showBuffer {
+		where = e.bufferName(entry.doc) + ":" + where
+	}
+	name := fmt.Sprintf(" %-7s %s", entry.sym.Kind, entry.sym.Name)
+	room := width - runewidth.StringWidth(where) - 2
+	name = runewidth.Truncate(name, room, e.box.Ellipsis)
+	return name + strings.Repeat(" ", max(room-runewidth.StringWidth(name), 0)+1) + where
+}
+
+// symbolsDialog builds the Go to Symbol dialog
+func (e *Editor) symbolsDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(symbolsWidth)
+	title := e.symbolTitle
+	if runewidth.StringWidth(title) > db.InnerWidth() {
+		// A long identifier in " Definitions of word "
+		title = runewidth.Truncate(title, db.InnerWidth()-1, e.box.Ellipsis) + " "
+	}
+	db.AddTitleBorder(title)
+	db.AddText(" > " + e.symbolQuery + "_")
+	db.AddSeparator()
+	showBuffer := false
+	for _, entry := range e.symbolEntries {
+		showBuffer = showBuffer || entry.doc != e.activeDoc()
+	}
+	matches := e.symbolMatches()
+	for i, entry := range matches {
+		db.AddSelectableItem(e.symbolLabel(entry, db.InnerWidth(), showBuffer), i == e.symbolIndex)
+	}
+	if len(matches) == 0 {
+		db.AddText("  No matching symbols")
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Go  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlaySymbolsDialog overlays the Go to Symbol dialog centered on the viewport
+func (e *Editor) overlaySymbolsDialog(viewportContent string) string {
+	return e.symbolsDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// goToSymbol closes the dialog and jumps to the chosen match
+func (e *Editor) goToSymbol(index int) {
+	e.mode = ModeNormal
+	matches := e.symbolMatches()
+	if index < 0 || index >= len(matches) {
+		return
+	}
+	e.jumpToSymbol(matches[index])
+}
+
+// jumpToSymbol puts the cursor on a definition's name, switching to its
+// buffer if need be. A definition off screen is brought to the middle.
+func (e *Editor) jumpToSymbol(entry symbolEntry) {
+	for i, doc := range e.documents {
+		if doc == entry.doc {
+			e.switchToBuffer(i)
+			break
+		}
+	}
+	doc := e.activeDoc()
+	if doc != entry.doc || entry.sym.Line >= doc.buffer.LineCount() {
+		return // Closed, or edited since it was listed
+	}
+	doc.selection.Clear()
+	doc.cursor.SetPosition(entry.sym.Line, entry.sym.Col)
+	lines := doc.buffer.Lines()
+	scrollY := e.viewport.ScrollY()
+	e.viewport.EnsureCursorVisibleWrapped(lines, doc.cursor.Line(), doc.cursor.Col())
+	if e.viewport.ScrollY() != scrollY {
+		e.centerCursor()
+	}
+}
+
+// definesWord reports whether a symbol name defines word: the name itself,
+// or a qualified name ending in it such as Widget::draw or M.run
+func definesWord(name, word string) bool {
+	if name == word {
+		return true
+	}
+	for _, sep := range []string{"::", ".", ":"} {
+		if strings.HasSuffix(name, sep+word) {
+			return true
+		}
+	}
+	return false
+}
+
+// goToDefinition jumps to the definition of the word under the cursor,
+// looking in the active buffer first and then the other open buffers.
+// When there are several, they are listed to choose from.
+func (e *Editor) goToDefinition() {
+	word, _, _, ok := e.wordUnderCursor(e.activeDoc().buffer.Lines())
+	if !ok {
+		e.statusbar.SetMessage("Go to Definition: no word at the cursor", "info")
+		return
+	}
+	docs := []*Document{e.activeDoc()}
+	for _, doc := range e.documents {
+		if doc != e.activeDoc() {
+			docs = append(docs, doc)
+		}
+	}
+	var found []symbolEntry
+	for _, doc := range docs {
+		for _, sym := range docSymbols(doc) {
+			if definesWord(sym.Name, word) {
+				found = append(found, symbolEntry{doc, sym})
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		e.statusbar.SetMessage("No definition of "+word+" in open buffers", "info")
+	case 1:
+		e.jumpToSymbol(found[0])
+	default:
+		e.openSymbolList(" Definitions of "+word+" ", found, 0)
+	}
+}
+
+// handleSymbolsKey handles typing and selection in the Go to Symbol dialog
+func (e *Editor) handleSymbolsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		if e.symbolIndex > 0 {
+			e.symbolIndex--
+		}
+	case tea.KeyDown:
+		if e.symbolIndex < len(e.symbolMatches())-1 {
+			e.symbolIndex++
+		}
+	case tea.KeyEnter:
+		e.goToSymbol(e.symbolIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	case tea.KeyBackspace:
+		if len(e.symbolQuery) > 0 {
+			_, size := utf8.DecodeLastRuneInString(e.symbolQuery)
+			e.symbolQuery = e.symbolQuery[:len(e.symbolQuery)-size]
+			e.symbolIndex = 0
+		}
+	case tea.KeyRunes:
+		e.symbolQuery += string(msg.Runes)
+		e.symbolIndex = 0
+	}
+	return e, nil
+}
+
+// handleSymbolsMouse selects symbols on click and goes to one on a second click
+func (e *Editor) handleSymbolsMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.symbolsDialog().listMouse(msg, &e.symbolIndex) {
+	case listChoose:
+		e.goToSymbol(e.symbolIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
@@ -0,0 +1,112 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// loadSymbolFiles writes the files to a temporary directory and opens them
+func loadSymbolFiles(t *testing.T, files map[string]string, order ...string) *Editor {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := t.TempDir()
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	for _, name := range order {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.LoadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+func TestGoToSymbol(t *testing.T) {
+	src := "package main\n\ntype Server struct{}\n\nfunc (s *Server) Start() {\n\ts.listen()\n}\n\nfunc main() {\n}\n"
+	e := loadSymbolFiles(t, map[string]string{"main.go": src}, "main.go")
+	e.activeDoc().cursor.SetPosition(5, 1)
+
+	// Opens on the symbol the cursor is in
+	e.showGoToSymbol()
+	if e.mode != ModeSymbols || e.symbolIndex != 1 {
+		t.Fatalf("mode %v index %d, want the symbol dialog on Start", e.mode, e.symbolIndex)
+	}
+	if dialog := strings.Join(e.symbolsDialog().Lines(), "\n"); !strings.Contains(dialog, "method  Start") {
+		t.Errorf("dialog should list methods:\n%s", dialog)
+	}
+
+	// Typing filters, Enter jumps to the name
+	for _, r := range "mai" {
+		e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if matches := e.symbolMatches(); len(matches) != 1 || matches[0].sym.Name != "main" {
+		t.Fatalf("matches for \"mai\" = %v, want main", matches)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); e.mode != ModeNormal || line != 8 || col != 5 {
+		t.Errorf("after Enter mode %v, cursor %d:%d, want normal mode at 8:5", e.mode, line, col)
+	}
+}
+
+func TestGoToSymbolUnsupported(t *testing.T) {
+	e := loadSymbolFiles(t, map[string]string{"notes.txt": "func main() {}"}, "notes.txt")
+	e.showGoToSymbol()
+	if e.mode == ModeSymbols {
+		t.Error("plain text should have no symbol list")
+	}
+}
+
+func TestGoToDefinition(t *testing.T) {
+	files := map[string]string{
+		"util.py": "def helper():\n    pass\n\nclass Shape:\n    def area(self):\n        pass\n",
+		"main.py": "from util import helper\n\nhelper()\nshape.area()\n\ndef area():\n    pass\n",
+	}
+	e := loadSymbolFiles(t, files, "util.py", "main.py")
+
+	// Found only in another buffer: switch to it
+	e.activeDoc().cursor.SetPosition(2, 2)
+	e.Update(tea.KeyMsg{Type: tea.KeyF12})
+	if name := e.bufferName(e.activeDoc()); name != "util.py" {
+		t.Fatalf("F12 on helper opened %s, want util.py", name)
+	}
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 0 || col != 4 {
+		t.Errorf("cursor at %d:%d, want 0:4", line, col)
+	}
+
+	// Several definitions are listed, the active buffer's first
+	e.switchToBuffer(1)
+	e.activeDoc().cursor.SetPosition(3, 7)
+	e.goToDefinition()
+	if e.mode != ModeSymbols {
+		t.Fatal("two definitions of area should be listed")
+	}
+	matches := e.symbolMatches()
+	if len(matches) != 2 || matches[0].doc != e.activeDoc() {
+		t.Fatalf("want main.py's area first, got %v", matches)
+	}
+	if dialog := strings.Join(e.symbolsDialog().Lines(), "\n"); !strings.Contains(dialog, "util.py:5") {
+		t.Errorf("definitions in other buffers should name the buffer:\n%s", dialog)
+	}
+
+	// A name too long for the title is shortened to fit
+	e.symbolTitle = " Definitions of " + strings.Repeat("long_identifier_", 8) + " "
+	if lines := e.symbolsDialog().Lines(); runewidth.StringWidth(lines[0]) != symbolsWidth {
+		t.Errorf("title border is %d wide, want %d", runewidth.StringWidth(lines[0]), symbolsWidth)
+	}
+
+	// No definition anywhere
+	e.mode = ModeNormal
+	e.activeDoc().cursor.SetPosition(0, 1)
+	e.goToDefinition()
+	if e.mode == ModeSymbols || e.bufferName(e.activeDoc()) != "main.py" {
+		t.Error("an undefined word should stay put")
+	}
+}
@@ -0,0 +1,254 @@
+package editor
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxTagScanSize is the largest document scanned for tag completion and renaming
+const maxTagScanSize = 1 << 20
+
+// htmlVoidElements never have a closing tag in HTML
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// markupTag is an opening or closing tag found by scanTags
+type markupTag struct {
+	name      string
+	nameStart int  // Byte offset of the name
+	closing   bool // </name>
+	pair      int  // Index of the matching tag, or -1
+}
+
+// markupKind returns "html" or "xml" when the active document is markup,
+// or "" for anything else, going by the highlighter's language detection
+func (e *Editor) markupKind() string {
+	switch e.activeDoc().highlighter.Language() {
+	case "HTML":
+		return "html"
+	case "XML":
+		return "xml"
+	}
+	return ""
+}
+
+// isTagNameByte reports whether b can appear in a tag name
+func isTagNameByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		b == '-' || b == '_' || b == ':' || b == '.' || b >= 0x80
+}
+
+// tagNameEnd returns the offset just past the tag name starting at start
+func tagNameEnd(text string, start int) int {
+	end := start
+	for end < len(text) && isTagNameByte(text[end]) {
+		end++
+	}
+	return end
+}
+
+// skipPast returns the offset just past the next occurrence of marker at or
+// after from, or len(text) if there is none
+func skipPast(text string, from int, marker string) int {
+	if i := strings.Index(text[from:], marker); i >= 0 {
+		return from + i + len(marker)
+	}
+	return len(text)
+}
+
+// scanTags finds the tags in text and pairs openings with their closings,
+// also returning the indexes of tags still open at the end, innermost last.
+// Comments, CDATA, doctypes and processing instructions are skipped; in HTML
+// void elements need no closing tag and script/style bodies are not markup.
+func scanTags(text string, html bool) (tags []markupTag, open []int) {
+	same := func(a, b string) bool {
+		if html {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+
+	for i := 0; i < len(text); {
+		lt := strings.IndexByte(text[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+		rest := text[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i = skipPast(text, i+4, "-->")
+			continue
+		case strings.HasPrefix(rest, "<![CDATA["):
+			i = skipPast(text, i+9, "]]>")
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			i = skipPast(text, i+2, ">")
+			continue
+		}
+
+		closing := strings.HasPrefix(rest, "</")
+		nameStart := i + 1
+		if closing {
+			nameStart++
+		}
+		nameEnd := tagNameEnd(text, nameStart)
+		if nameEnd == nameStart || !isTagNameByte(text[nameStart]) || text[nameStart] >= '0' && text[nameStart] <= '9' {
+			i++
+			continue
+		}
+		name := text[nameStart:nameEnd]
+
+		// Find the end of the tag, ignoring '>' inside quoted attribute values
+		end, quote := nameEnd, byte(0)
+		for ; end < len(text); end++ {
+			c := text[end]
+			if quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+			} else if c == '"' || c == '\'' {
+				quote = c
+			} else if c == '>' || c == '<' {
+				break
+			}
+		}
+		selfClosing := end < len(text) && text[end] == '>' && text[end-1] == '/'
+
+		// Self-closing tags and HTML void elements never take a closing tag
+		empty := !closing && (selfClosing || html && htmlVoidElements[strings.ToLower(name)])
+		idx := len(tags)
+		tags = append(tags, markupTag{name: name, nameStart: nameStart, closing: closing, pair: -1})
+
+		switch {
+		case closing:
+			for j := len(open) - 1; j >= 0; j-- {
+				if same(tags[open[j]].name, name) {
+					tags[open[j]].pair = idx
+					tags[idx].pair = open[j]
+					open = open[:j]
+					break
+				}
+			}
+		case empty:
+		default:
+			open = append(open, idx)
+			if html && (strings.EqualFold(name, "script") || strings.EqualFold(name, "style")) {
+				// Raw text up to the closing tag
+				if close := strings.Index(strings.ToLower(text[end:]), "</"+strings.ToLower(name)); close >= 0 {
+					end += close - 1
+				}
+			}
+		}
+		i = end + 1
+	}
+	return tags, open
+}
+
+// unclosedTag returns the name of the innermost tag still open at the end of text
+func unclosedTag(text string, html bool) string {
+	tags, open := scanTags(text, html)
+	if len(open) == 0 {
+		return ""
+	}
+	return tags[open[len(open)-1]].name
+}
+
+// autoCloseTag completes "</" with the nearest unclosed tag in HTML/XML files
+func (e *Editor) autoCloseTag() {
+	kind := e.markupKind()
+	doc := e.activeDoc()
+	pos := doc.cursor.ByteOffset()
+	if kind == "" || pos < 2 || doc.buffer.Length() > maxTagScanSize || doc.buffer.Substring(pos-2, pos) != "</" {
+		return
+	}
+	// Leave existing closing tags alone when a slash is typed into them
+	if pos < doc.buffer.Length() && isTagNameByte(doc.buffer.ByteAt(pos)) {
+		return
+	}
+	if name := unclosedTag(doc.buffer.Substring(0, pos-2), kind == "html"); name != "" {
+		e.insertText(name + ">")
+	}
+}
+
+// tagRename remembers the tag name under the cursor and its partner before
+// an edit, so the partner can follow a rename
+type tagRename struct {
+	nameStart int    // Offset of the name being edited
+	name      string // Name before the edit
+	pairStart int    // Offset of the matching tag's name
+}
+
+// tagRenameAtCursor returns the tag pair whose name the cursor is in, or nil
+func (e *Editor) tagRenameAtCursor() *tagRename {
+	kind := e.markupKind()
+	doc := e.activeDoc()
+	if kind == "" || doc.buffer.Length() > maxTagScanSize || doc.selection.Active && !doc.selection.IsEmpty() {
+		return nil
+	}
+	pos := doc.cursor.ByteOffset()
+	tags, _ := scanTags(doc.buffer.String(), kind == "html")
+	for _, t := range tags {
+		if pos >= t.nameStart && pos <= t.nameStart+len(t.name) && t.pair >= 0 {
+			return &tagRename{nameStart: t.nameStart, name: t.name, pairStart: tags[t.pair].nameStart}
+		}
+	}
+	return nil
+}
+
+// syncTagRename renames the partner tag after the user edited a tag name
+func (e *Editor) syncTagRename(r *tagRename) {
+	if r == nil {
+		return
+	}
+	doc := e.activeDoc()
+	text := doc.buffer.String()
+	pos := doc.cursor.ByteOffset()
+	if r.nameStart < 1 || r.nameStart > len(text) || text[r.nameStart-1] != '<' && text[r.nameStart-1] != '/' {
+		return
+	}
+	newName := text[r.nameStart:tagNameEnd(text, r.nameStart)]
+	if newName == "" || newName == r.name || pos < r.nameStart || pos > r.nameStart+len(newName) {
+		return
+	}
+
+	pairStart := r.pairStart
+	if pairStart > r.nameStart {
+		pairStart += len(newName) - len(r.name)
+	}
+	pairEnd := pairStart + len(r.name)
+	if pairStart < 0 || pairEnd > len(text) || text[pairStart:pairEnd] != r.name || tagNameEnd(text, pairStart) != pairEnd {
+		return
+	}
+
+	entry := &UndoEntry{
+		Position:     pairStart,
+		Deleted:      r.name,
+		Inserted:     newName,
+		CursorBefore: pos,
+	}
+	doc.buffer.Replace(pairStart, pairEnd, newName)
+	if pairStart < pos {
+		pos += len(newName) - len(r.name)
+	}
+	doc.cursor.SetByteOffset(pos)
+	entry.CursorAfter = pos
+	doc.undoStack.BreakMerge()
+	doc.undoStack.Push(entry)
+	doc.undoStack.BreakMerge()
+}
+
+// isTagEditKey reports whether a key edits text in a way that can rename a tag
+func isTagEditKey(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes:
+		return !msg.Alt && !msg.Paste
+	case tea.KeyBackspace, tea.KeyDelete:
+		return true
+	}
+	return false
+}
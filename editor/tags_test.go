@@ -0,0 +1,83 @@
+package editor
+
+import (
+	"testing"
+
+	"github.com/cornish/textivus-editor/syntax"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUnclosedTag(t *testing.T) {
+	tests := []struct {
+		text string
+		html bool
+		want string
+	}{
+		{"<div><p>text", true, "p"},
+		{"<div><p>text</p>", true, "div"},
+		{"<div><br><img src='a>b'>", true, "div"},
+		{"<ul><li>one<li>two</ul>", true, ""},
+		{"<div><!-- <span> --><script>if (a < b) {}</script>", true, "div"},
+		{"<root><item/>", false, "root"},
+		{"<?xml version=\"1.0\"?><root><![CDATA[<x>]]>", false, "root"},
+		{"<a></A>", true, ""},
+		{"<a></A>", false, "a"},
+	}
+	for _, tt := range tests {
+		if got := unclosedTag(tt.text, tt.html); got != tt.want {
+			t.Errorf("unclosedTag(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func newMarkupTestEditor(filename, content string, offset int) *Editor {
+	e := New()
+	doc := e.activeDoc()
+	doc.buffer = NewBufferFromString(content)
+	doc.cursor = NewCursor(doc.buffer)
+	doc.cursor.SetByteOffset(offset)
+	doc.highlighter = syntax.New(filename)
+	return e
+}
+
+func TestAutoCloseTag(t *testing.T) {
+	e := newMarkupTestEditor("page.html", "<div><p>hi", 10)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'<'}})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if got := e.activeDoc().buffer.String(); got != "<div><p>hi</p>" {
+		t.Errorf("buffer = %q, want closing p completed", got)
+	}
+
+	// Only markup files complete tags
+	e = newMarkupTestEditor("main.go", "<div>", 5)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'<'}})
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if got := e.activeDoc().buffer.String(); got != "<div></" {
+		t.Errorf("non-markup buffer = %q, want no completion", got)
+	}
+}
+
+func TestRenameMatchingTag(t *testing.T) {
+	// Cursor at the end of the opening tag's name
+	e := newMarkupTestEditor("page.xml", "<item><item/></item>", 5)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if got := e.activeDoc().buffer.String(); got != "<items><item/></items>" {
+		t.Fatalf("after typing = %q", got)
+	}
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	e.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if got := e.activeDoc().buffer.String(); got != "<ite><item/></ite>" {
+		t.Fatalf("after backspace = %q", got)
+	}
+
+	// Editing the closing tag renames the opening one
+	e = newMarkupTestEditor("page.html", "<b>bold</b>", 10)
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if got := e.activeDoc().buffer.String(); got != "<br>bold</br>" {
+		t.Errorf("closing edit = %q", got)
+	}
+	if got := e.activeDoc().cursor.ByteOffset(); got != 12 {
+		t.Errorf("cursor = %d, want 12 (after the typed r)", got)
+	}
+}
@@ -0,0 +1,126 @@
+package editor
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// titleCase capitalizes the first letter of each word and lowercases the rest
+func titleCase(s string) string {
+	inWord := false
+	return strings.Map(func(r rune) rune {
+		wasInWord := inWord
+		inWord = unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+		switch {
+		case !inWord:
+			return r
+		case wasInWord:
+			return unicode.ToLower(r)
+		}
+		return unicode.ToTitle(r)
+	}, s)
+}
+
+// toggleCase swaps the case of every letter in s
+func toggleCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return unicode.ToLower(r)
+		case unicode.IsLower(r):
+			return unicode.ToUpper(r)
+		}
+		return r
+	}, s)
+}
+
+// sortLines returns lines sorted in byte order
+func sortLines(lines []string) []string {
+	sort.Strings(lines)
+	return lines
+}
+
+// reverseLines returns lines in reverse order
+func reverseLines(lines []string) []string {
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+// uniqueLines drops repeated lines, keeping the first occurrence of each
+func uniqueLines(lines []string) []string {
+	seen := make(map[string]bool, len(lines))
+	out := lines[:0]
+	for _, line := range lines {
+		if !seen[line] {
+			seen[line] = true
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// transformSelection replaces the selected text with fn applied to it as a
+// single undoable edit, keeping the result selected
+func (e *Editor) transformSelection(fn func(string) string) {
+	doc := e.activeDoc()
+	if !doc.selection.Active || doc.selection.IsEmpty() {
+		e.statusbar.SetMessage("No selection", "info")
+		return
+	}
+	start, end := doc.selection.Normalize()
+	e.replaceRange(start, end, fn(doc.buffer.Substring(start, end)))
+}
+
+// transformLines replaces the lines touched by the selection (or the whole
+// buffer without one) with fn applied to them as a single undoable edit
+func (e *Editor) transformLines(fn func([]string) []string) {
+	doc := e.activeDoc()
+	startLine, endLine := 0, doc.buffer.LineCount()-1
+	if doc.selection.Active && !doc.selection.IsEmpty() {
+		startPos, endPos := doc.selection.Normalize()
+		startLine, _ = doc.buffer.PositionToLineCol(startPos)
+		var endCol int
+		endLine, endCol = doc.buffer.PositionToLineCol(endPos)
+		// If selection ends at column 0, don't include that line
+		if endCol == 0 && endLine > startLine {
+			endLine--
+		}
+	}
+
+	start := doc.buffer.LineStartOffset(startLine)
+	end := doc.buffer.LineEndOffset(endLine)
+	lines := strings.Split(doc.buffer.Substring(start, end), "\n")
+	e.replaceRange(start, end, strings.Join(fn(lines), "\n"))
+}
+
+// replaceRange replaces [start, end) with text as one undo step and selects
+// the new text
+func (e *Editor) replaceRange(start, end int, text string) {
+	if e.refuseEdit() {
+		return
+	}
+	doc := e.activeDoc()
+	old := doc.buffer.Substring(start, end)
+	if text == old {
+		e.statusbar.SetMessage("No changes", "info")
+		return
+	}
+
+	entry := &UndoEntry{
+		Position:     start,
+		Deleted:      old,
+		Inserted:     text,
+		CursorBefore: doc.cursor.ByteOffset(),
+	}
+	doc.buffer.Replace(start, end, text)
+	doc.cursor.SetByteOffset(start + len(text))
+	doc.selection.Start(start)
+	doc.selection.Update(start + len(text))
+	entry.CursorAfter = doc.cursor.ByteOffset()
+	doc.undoStack.Push(entry)
+	doc.modified = true
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
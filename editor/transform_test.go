@@ -0,0 +1,69 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/ui"
+)
+
+func TestCaseTransforms(t *testing.T) {
+	if got := titleCase("hELLO wORLD, it's 2nd-rate"); got != "Hello World, It's 2nd-Rate" {
+		t.Errorf("titleCase() = %q", got)
+	}
+	if got := toggleCase("Hello World 42"); got != "hELLO wORLD 42" {
+		t.Errorf("toggleCase() = %q", got)
+	}
+}
+
+func TestTransformSelection(t *testing.T) {
+	e := newMarkupTestEditor("notes.txt", "say hello world", 0)
+	doc := e.activeDoc()
+	doc.selection.Start(4)
+	doc.selection.Update(9)
+	e.executeAction(ui.ActionUppercase)
+	if got := doc.buffer.String(); got != "say HELLO world" {
+		t.Errorf("buffer = %q, want HELLO uppercased", got)
+	}
+	if start, end := doc.selection.Normalize(); start != 4 || end != 9 {
+		t.Errorf("selection = %d-%d, want 4-9", start, end)
+	}
+	e.undo()
+	if got := doc.buffer.String(); got != "say hello world" {
+		t.Errorf("after undo = %q", got)
+	}
+}
+
+func TestTransformLines(t *testing.T) {
+	tests := []struct {
+		action ui.MenuAction
+		want   string
+	}{
+		{ui.ActionSortLines, "head\napple\ncherry\ncherry\nzebra\ntail"},
+		{ui.ActionReverseLines, "head\ncherry\napple\ncherry\nzebra\ntail"},
+		{ui.ActionUniqueLines, "head\nzebra\ncherry\napple\ntail"},
+	}
+	for _, tt := range tests {
+		content := "head\nzebra\ncherry\napple\ncherry\ntail"
+		e := newMarkupTestEditor("notes.txt", content, 0)
+		doc := e.activeDoc()
+		// Select from inside "zebra" to the start of "tail"
+		doc.selection.Start(7)
+		doc.selection.Update(strings.Index(content, "tail"))
+		e.executeAction(tt.action)
+		if got := doc.buffer.String(); got != tt.want {
+			t.Errorf("action %v: buffer = %q, want %q", tt.action, got, tt.want)
+		}
+		e.undo()
+		if got := doc.buffer.String(); got != content {
+			t.Errorf("action %v: after undo = %q", tt.action, got)
+		}
+	}
+
+	// Without a selection the whole buffer is sorted
+	e := newMarkupTestEditor("notes.txt", "b\nc\na", 0)
+	e.executeAction(ui.ActionSortLines)
+	if got := e.activeDoc().buffer.String(); got != "a\nb\nc" {
+		t.Errorf("sort without selection = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package editor
+
+import (
+	"path/filepath"
+
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requireTrust runs a command a project controls (git status, which runs
+// hooks and filters the repository's config names) only once its directory
+// is trusted. The first time, a confirm dialog asks
+// whether to trust the project, run just this once, or refuse; trusted
+// directories are remembered in the config so the question isn't repeated.
+// Anything that executes commands named by files inside a project must go
+// through here so cloning a repository never runs code on its own.
+func (e *Editor) requireTrust(dir, what string, run func() tea.Cmd) tea.Cmd {
+	if e.config != nil && e.config.IsTrustedDir(dir) {
+		return run()
+	}
+
+	e.showConfirm(&ConfirmDialog{
+		Title:   "Trust Project",
+		Message: filepath.Base(dir) + " wants to run " + what + ".\nOnly trust projects you know.",
+		Buttons: []ConfirmButton{
+			{Label: "Trust", Hotkey: 't', Danger: true},
+			{Label: "Run Once", Hotkey: 'o'},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 2,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			if choice == 2 {
+				e.statusbar.SetMessage("Not running "+what+" from untrusted project", "info")
+				return nil
+			}
+			if choice == 0 {
+				e.trustDir(dir)
+			}
+			return run()
+		},
+	})
+	return nil
+}
+
+// trustDir remembers dir as trusted and saves the config
+func (e *Editor) trustDir(dir string) {
+	if e.config == nil {
+		e.config = config.DefaultConfig()
+	}
+	e.config.TrustDir(dir)
+	go e.config.Save()
+	e.statusbar.SetMessage("Trusted: "+dir, "info")
+}
@@ -0,0 +1,54 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRequireTrust(t *testing.T) {
+	configHome, err := os.MkdirTemp("", "textivus-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(configHome) })
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	e := New()
+	project := t.TempDir()
+	runs := 0
+	run := func() tea.Cmd {
+		runs++
+		return nil
+	}
+
+	// Enter picks the safe default and runs nothing
+	e.requireTrust(project, "a formatter", run)
+	if e.mode != ModeConfirm {
+		t.Fatalf("mode = %v, want ModeConfirm", e.mode)
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if runs != 0 {
+		t.Fatalf("cancelled trust prompt ran the command")
+	}
+
+	// Run Once doesn't remember the project
+	e.requireTrust(project, "a formatter", run)
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if runs != 1 || e.config.IsTrustedDir(project) {
+		t.Fatalf("run once: runs = %d, trusted = %v", runs, e.config.IsTrustedDir(project))
+	}
+
+	// Trust remembers it, and subdirectories are covered without asking
+	e.requireTrust(project, "a formatter", run)
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	if runs != 2 || !e.config.IsTrustedDir(project) {
+		t.Fatalf("trust: runs = %d, trusted = %v", runs, e.config.IsTrustedDir(project))
+	}
+	e.requireTrust(filepath.Join(project, "sub"), "a formatter", run)
+	if e.mode == ModeConfirm || runs != 3 {
+		t.Errorf("trusted subdirectory prompted again (runs = %d)", runs)
+	}
+}
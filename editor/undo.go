@@ -1,6 +1,11 @@
 package editor
 
-import "time"
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // UndoEntry represents a single change that can be undone/redone.
 type UndoEntry struct {
@@ -16,58 +21,251 @@ type UndoEntry struct {
 	CursorAfter int
 	// Timestamp of the change (for grouping)
 	Timestamp time.Time
+	// Edits, when set, are the change as separate edits applied in order,
+	// with Deleted and Inserted left empty. Changes to a few places in a
+	// large range, such as indenting lines, keep only what they touched.
+	Edits []UndoEdit
+	// Name describes a change made of Edits in the Undo History dialog
+	Name string
+	// SelectLines selects lines FirstLine to LastLine after undo or redo,
+	// from the start of the first, the way indenting leaves them
+	SelectLines         bool
+	FirstLine, LastLine int
 }
 
-// UndoStack manages undo and redo operations.
+// UndoEdit is one of the edits of an UndoEntry
+type UndoEdit struct {
+	// Byte offset, in the text as the edits before it left it
+	Position int
+	Deleted  string
+	Inserted string
+}
+
+// undoEntryOverhead approximates the fixed memory cost of an entry beyond its text
+const undoEntryOverhead = 96
+
+// undoEditOverhead approximates the fixed memory cost of an edit beyond its text
+const undoEditOverhead = 40
+
+// size returns the approximate memory used by the entry in bytes
+func (e *UndoEntry) size() int {
+	n := len(e.Deleted) + len(e.Inserted) + undoEntryOverhead
+	for _, ed := range e.Edits {
+		n += len(ed.Deleted) + len(ed.Inserted) + undoEditOverhead
+	}
+	return n
+}
+
+// undoNode is a state in the undo tree: the document as it was after its
+// entry was applied to its parent's state
+type undoNode struct {
+	entry    *UndoEntry // nil for the root, the oldest state kept
+	parent   *undoNode
+	children []*undoNode // Oldest first
+	redo     int         // Child Redo goes to: the one last undone or made
+	seq      int         // Creation order, numbering the states
+	label    string      // Checkpoint name, such as "Saved"
+}
+
+// time returns when the state was made. The root has no entry of its own
+// and counts as made just before the oldest change.
+func (n *undoNode) time(u *UndoStack) time.Time {
+	if n.entry != nil {
+		return n.entry.Timestamp
+	}
+	if states := u.chronological(); len(states) > 1 {
+		return states[1].entry.Timestamp
+	}
+	return time.Now()
+}
+
+// walk calls fn for n and every state after it
+func (n *undoNode) walk(fn func(*undoNode)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// leadsTo reports whether target is n or one of the states after it
+func (n *undoNode) leadsTo(target *undoNode) bool {
+	for ; target != nil; target = target.parent {
+		if target == n {
+			return true
+		}
+	}
+	return false
+}
+
+// UndoStack manages undo and redo operations. The history is a tree:
+// making a change after undoing starts a new branch instead of dropping
+// the changes undone, so every earlier state can still be gone back to.
 type UndoStack struct {
-	undoStack []*UndoEntry
-	redoStack []*UndoEntry
-	maxSize   int
+	root     *undoNode
+	current  *undoNode // State the document is in
+	count    int       // Entries in the tree
+	seq      int       // Last state number handed out
+	maxSize  int
+	maxBytes int // Memory budget for the whole tree (0 = unlimited)
+	bytes    int // Approximate memory used by the tree
 	// Grouping: changes within this duration are grouped together
 	groupingInterval time.Duration
 	lastChange       time.Time
+	// Checkpoints: a change this long after the last checkpoint marks the
+	// state before it (0 = no periodic checkpoints)
+	checkpointInterval time.Duration
+	lastCheckpoint     time.Time
 }
 
 // NewUndoStack creates a new undo stack with the given maximum size.
 func NewUndoStack(maxSize int) *UndoStack {
+	root := &undoNode{}
 	return &UndoStack{
-		undoStack:        make([]*UndoEntry, 0, maxSize),
-		redoStack:        make([]*UndoEntry, 0, maxSize),
+		root:             root,
+		current:          root,
 		maxSize:          maxSize,
 		groupingInterval: 500 * time.Millisecond,
 	}
 }
 
-// Push adds a new entry to the undo stack.
-// This clears the redo stack since we're making a new change.
+// Push adds a new entry after the current state. Changes undone before
+// it stay in the tree on their own branch.
 func (u *UndoStack) Push(entry *UndoEntry) {
 	entry.Timestamp = time.Now()
 
 	// Try to merge with the last entry if it's recent and compatible
 	if u.shouldMerge(entry) {
-		last := u.undoStack[len(u.undoStack)-1]
+		last := u.current.entry
+		u.bytes -= last.size()
 		u.mergeEntries(last, entry)
+		u.bytes += last.size()
 	} else {
-		u.undoStack = append(u.undoStack, entry)
+		if u.checkpointInterval > 0 && entry.Timestamp.Sub(u.lastCheckpoint) >= u.checkpointInterval {
+			if u.current != u.root && u.current.label == "" {
+				u.current.label = "Checkpoint"
+			}
+			u.lastCheckpoint = entry.Timestamp
+		}
+		u.seq++
+		node := &undoNode{entry: entry, parent: u.current, seq: u.seq}
+		u.current.children = append(u.current.children, node)
+		u.current.redo = len(u.current.children) - 1
+		u.current = node
+		u.count++
+		u.bytes += entry.size()
 
 		// Trim if over max size
-		if len(u.undoStack) > u.maxSize {
-			u.undoStack = u.undoStack[1:]
+		if u.count > u.maxSize {
+			u.dropOldest()
 		}
 	}
 
-	// Clear redo stack on new change
-	u.redoStack = u.redoStack[:0]
 	u.lastChange = entry.Timestamp
+	u.evict()
+}
+
+// dropOldest forgets the oldest entry in the tree. If the current state
+// comes after it, it is folded into the root, and the branches that left
+// the root before it go too since they can no longer be reached; otherwise
+// its whole branch goes. It reports false when the oldest entry is the
+// current state's, which is always kept so the last change can be undone.
+func (u *UndoStack) dropOldest() bool {
+	if len(u.root.children) == 0 {
+		return false
+	}
+	oldest := u.root.children[0]
+	if oldest == u.current {
+		return false
+	}
+	if !oldest.leadsTo(u.current) {
+		u.forget(oldest)
+		u.root.children = u.root.children[1:]
+		u.root.redo = max(u.root.redo-1, 0)
+		return true
+	}
+	for _, other := range u.root.children[1:] {
+		u.forget(other)
+	}
+	u.bytes -= oldest.entry.size()
+	u.count--
+	oldest.entry = nil // Let the text be collected
+	oldest.parent = nil
+	u.root = oldest
+	return true
+}
+
+// forget removes the accounting for n and the states after it
+func (u *UndoStack) forget(n *undoNode) {
+	n.walk(func(node *undoNode) {
+		u.bytes -= node.entry.size()
+		u.count--
+	})
+}
+
+// evict drops the oldest entries until the tree fits the memory budget.
+// The newest entry is always kept so the last change can be undone.
+func (u *UndoStack) evict() {
+	if u.maxBytes <= 0 {
+		return
+	}
+	for u.bytes > u.maxBytes && u.dropOldest() {
+	}
+}
+
+// SetMaxBytes sets the memory budget in bytes (0 = unlimited) and evicts to fit it.
+func (u *UndoStack) SetMaxBytes(n int) {
+	u.maxBytes = n
+	u.evict()
+}
+
+// MaxBytes returns the memory budget in bytes (0 = unlimited).
+func (u *UndoStack) MaxBytes() int {
+	return u.maxBytes
+}
+
+// MemoryUsage returns the approximate memory used by the undo history in bytes.
+func (u *UndoStack) MemoryUsage() int {
+	return u.bytes
+}
+
+// Len returns how many changes can be undone and redone from the current
+// state, redo following the branch it would take.
+func (u *UndoStack) Len() (undo, redo int) {
+	for n := u.current; n != u.root; n = n.parent {
+		undo++
+	}
+	for n := u.current; len(n.children) > 0; n = n.children[n.redo] {
+		redo++
+	}
+	return undo, redo
+}
+
+// States returns the number of states in the tree, the root included.
+func (u *UndoStack) States() int {
+	return u.count + 1
+}
+
+// Recent returns up to n of the most recent undo entries, newest first.
+func (u *UndoStack) Recent(n int) []*UndoEntry {
+	var recent []*UndoEntry
+	for node := u.current; node != u.root && len(recent) < n; node = node.parent {
+		recent = append(recent, node.entry)
+	}
+	return recent
 }
 
 // shouldMerge returns true if the new entry should be merged with the last one.
 func (u *UndoStack) shouldMerge(entry *UndoEntry) bool {
-	if len(u.undoStack) == 0 {
+	// Only the newest state on its branch can grow, or the states after
+	// it would no longer follow from it
+	if u.current == u.root || len(u.current.children) > 0 {
 		return false
 	}
 
-	last := u.undoStack[len(u.undoStack)-1]
+	last := u.current.entry
+	if len(last.Edits) > 0 || len(entry.Edits) > 0 {
+		return false
+	}
 
 	// Check if within grouping interval
 	if time.Since(last.Timestamp) > u.groupingInterval {
@@ -124,48 +322,173 @@ func (u *UndoStack) mergeEntries(last, entry *UndoEntry) {
 	last.Timestamp = entry.Timestamp
 }
 
-// Undo pops and returns the last entry from the undo stack, or nil if empty.
-// The entry is moved to the redo stack.
+// Undo returns the entry that made the current state, or nil at the
+// oldest state, and steps back to the state before it.
 func (u *UndoStack) Undo() *UndoEntry {
-	if len(u.undoStack) == 0 {
+	if u.current == u.root {
 		return nil
 	}
 
-	entry := u.undoStack[len(u.undoStack)-1]
-	u.undoStack = u.undoStack[:len(u.undoStack)-1]
-	u.redoStack = append(u.redoStack, entry)
+	node := u.current
+	u.current = node.parent
+	u.current.redo = slices.Index(u.current.children, node)
 
-	return entry
+	return node.entry
 }
 
-// Redo pops and returns the last entry from the redo stack, or nil if empty.
-// The entry is moved back to the undo stack.
+// Redo steps forward to the state last undone or made from the current
+// one and returns its entry, or nil if there is none.
 func (u *UndoStack) Redo() *UndoEntry {
-	if len(u.redoStack) == 0 {
+	if len(u.current.children) == 0 {
 		return nil
 	}
 
-	entry := u.redoStack[len(u.redoStack)-1]
-	u.redoStack = u.redoStack[:len(u.redoStack)-1]
-	u.undoStack = append(u.undoStack, entry)
+	u.current = u.current.children[u.current.redo]
+
+	return u.current.entry
+}
 
-	return entry
+// steerTo points redo from the current state along the way to target, a
+// state after it
+func (u *UndoStack) steerTo(target *undoNode) {
+	for n := target; n.parent != nil; n = n.parent {
+		if n.parent == u.current {
+			u.current.redo = slices.Index(u.current.children, n)
+			return
+		}
+	}
+}
+
+// Checkpoint names the current state, so Earlier and Later can count by it
+// and the Undo History dialog shows it.
+func (u *UndoStack) Checkpoint(label string) {
+	u.current.label = label
+	u.lastCheckpoint = time.Now()
+}
+
+// SetCheckpointInterval sets how long after the last checkpoint a change
+// marks a new one (0 = never).
+func (u *UndoStack) SetCheckpointInterval(d time.Duration) {
+	u.checkpointInterval = d
+}
+
+// chronological returns every state in the order it was made, the root first
+func (u *UndoStack) chronological() []*undoNode {
+	var states []*undoNode
+	u.root.walk(func(n *undoNode) {
+		states = append(states, n)
+	})
+	slices.SortFunc(states, func(a, b *undoNode) int {
+		return a.seq - b.seq
+	})
+	return states
+}
+
+// undoTravel is how far Earlier or Later goes through the history. Only
+// one of its fields is set.
+type undoTravel struct {
+	changes     int           // Changes, in the order they were made
+	duration    time.Duration // Wall-clock time
+	checkpoints int           // Checkpoints and saves
+}
+
+// parseUndoTravel reads an Earlier/Later amount the way vim's :earlier
+// does: a count of changes, a time such as 30s, 10m, 2h or 1d, or a count
+// of checkpoints such as 2f
+func parseUndoTravel(s string) (undoTravel, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return undoTravel{changes: 1}, true
+	}
+	unit := s[len(s)-1]
+	if unit >= '0' && unit <= '9' {
+		unit = 0
+	} else {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return undoTravel{}, false
+	}
+	switch unit {
+	case 0:
+		return undoTravel{changes: n}, true
+	case 's':
+		return undoTravel{duration: time.Duration(n) * time.Second}, true
+	case 'm':
+		return undoTravel{duration: time.Duration(n) * time.Minute}, true
+	case 'h':
+		return undoTravel{duration: time.Duration(n) * time.Hour}, true
+	case 'd':
+		return undoTravel{duration: time.Duration(n) * 24 * time.Hour}, true
+	case 'f':
+		return undoTravel{checkpoints: n}, true
+	}
+	return undoTravel{}, false
+}
+
+// travel returns the state Earlier (back) or Later goes to from the
+// current one. It moves through states in the order they were made,
+// across branches, so going back by time finds the buffer as it was then
+// even if that state was since undone.
+func (u *UndoStack) travel(t undoTravel, back bool) *undoNode {
+	states := u.chronological()
+	at := slices.Index(states, u.current)
+	switch {
+	case t.changes > 0 && back:
+		return states[max(at-t.changes, 0)]
+	case t.changes > 0:
+		return states[min(at+t.changes, len(states)-1)]
+	case t.duration > 0:
+		when := u.current.time(u).Add(t.duration)
+		if back {
+			when = u.current.time(u).Add(-t.duration)
+		}
+		// The last state made by then, or the oldest if all came later
+		target := states[0]
+		for _, n := range states[1:] {
+			if n.time(u).After(when) {
+				break
+			}
+			target = n
+		}
+		if !back && target.seq < u.current.seq {
+			return u.current
+		}
+		return target
+	}
+	step, end := 1, len(states)-1
+	if back {
+		step, end = -1, 0
+	}
+	left := t.checkpoints
+	for i := at + step; i >= 0 && i < len(states); i += step {
+		if states[i].label != "" {
+			if left--; left == 0 {
+				return states[i]
+			}
+		}
+	}
+	return states[end]
 }
 
 // CanUndo returns true if there are entries to undo.
 func (u *UndoStack) CanUndo() bool {
-	return len(u.undoStack) > 0
+	return u.current != u.root
 }
 
 // CanRedo returns true if there are entries to redo.
 func (u *UndoStack) CanRedo() bool {
-	return len(u.redoStack) > 0
+	return len(u.current.children) > 0
 }
 
-// Clear clears both the undo and redo stacks.
+// Clear forgets the whole history.
 func (u *UndoStack) Clear() {
-	u.undoStack = u.undoStack[:0]
-	u.redoStack = u.redoStack[:0]
+	u.root = &undoNode{}
+	u.current = u.root
+	u.count = 0
+	u.seq = 0
+	u.bytes = 0
 }
 
 // BreakMerge forces the next change to not merge with previous ones.
@@ -0,0 +1,238 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestUndoStackEvictsByMemory(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.SetMaxBytes(3000)
+	u.SetGroupingInterval(0) // Keep every push a separate entry
+
+	big := strings.Repeat("x", 1000)
+	for i := 0; i < 5; i++ {
+		u.Push(&UndoEntry{Position: i, Inserted: big})
+	}
+	undo, _ := u.Len()
+	if undo != 2 {
+		t.Errorf("kept %d entries, want 2 within the budget", undo)
+	}
+	if got := u.MemoryUsage(); got > 3000 {
+		t.Errorf("MemoryUsage = %d, over the 3000 byte budget", got)
+	}
+	if e := u.Undo(); e == nil || e.Position != 4 {
+		t.Errorf("newest entry should survive eviction")
+	}
+}
+
+func TestUndoStackKeepsOversizedEntry(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.SetMaxBytes(100)
+	u.Push(&UndoEntry{Deleted: strings.Repeat("y", 500)})
+	if !u.CanUndo() {
+		t.Errorf("the latest change must stay undoable even when over budget")
+	}
+}
+
+func TestUndoStackMemoryAccounting(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.Push(&UndoEntry{Position: 0, Inserted: "a"})
+	u.Push(&UndoEntry{Position: 1, Inserted: "b"}) // Merges into the first entry
+	want := len("ab") + undoEntryOverhead
+	if got := u.MemoryUsage(); got != want {
+		t.Errorf("MemoryUsage after merge = %d, want %d", got, want)
+	}
+
+	u.Undo()
+	if got := u.MemoryUsage(); got != want {
+		t.Errorf("undo should keep the entry's memory for redo, got %d", got)
+	}
+	u.BreakMerge()
+	u.Push(&UndoEntry{Position: 5, Inserted: " "})
+	if got, want := u.MemoryUsage(), want+1+undoEntryOverhead; got != want {
+		t.Errorf("new change should keep the undone branch, got %d want %d", got, want)
+	}
+
+	u.Clear()
+	if u.MemoryUsage() != 0 {
+		t.Errorf("Clear left %d bytes accounted", u.MemoryUsage())
+	}
+}
+
+func TestUndoStackKeepsBranches(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.SetGroupingInterval(0)
+	u.Push(&UndoEntry{Position: 0, Inserted: "a"})
+	b := &UndoEntry{Position: 1, Inserted: "b"}
+	u.Push(b)
+	u.Undo()
+	u.Push(&UndoEntry{Position: 1, Inserted: "c"})
+
+	if undo, redo := u.Len(); undo != 2 || redo != 0 {
+		t.Errorf("Len = %d, %d; want 2, 0", undo, redo)
+	}
+	if got := u.States(); got != 4 {
+		t.Errorf("States = %d, want 4 with the undone branch kept", got)
+	}
+
+	// Going back to the fork, redo takes the branch last left
+	u.Undo()
+	if e := u.Redo(); e == nil || e.Inserted != "c" {
+		t.Errorf("redo should follow the newest branch, got %v", e)
+	}
+	u.Undo()
+	u.steerTo(u.current.children[0])
+	if e := u.Redo(); e != b {
+		t.Errorf("redo after steering should reach the undone branch, got %v", e)
+	}
+}
+
+func TestUndoStackEvictsOldBranches(t *testing.T) {
+	u := NewUndoStack(3)
+	u.SetGroupingInterval(0)
+	u.Push(&UndoEntry{Position: 0, Inserted: "a"})
+	u.Undo()
+	u.Push(&UndoEntry{Position: 0, Inserted: "b"})
+	u.Push(&UndoEntry{Position: 1, Inserted: "c"})
+	u.Push(&UndoEntry{Position: 2, Inserted: "d"}) // Over the limit: drops branch a
+
+	if got := u.States(); got != 4 {
+		t.Errorf("States = %d, want 4", got)
+	}
+	if undo, _ := u.Len(); undo != 3 {
+		t.Errorf("the current branch should stay whole, %d undos left", undo)
+	}
+	if got, want := u.MemoryUsage(), 3*(1+undoEntryOverhead); got != want {
+		t.Errorf("MemoryUsage = %d, want %d", got, want)
+	}
+
+	u.Push(&UndoEntry{Position: 3, Inserted: "e"}) // Folds b into the root
+	if undo, _ := u.Len(); undo != 3 {
+		t.Errorf("%d undos left, want 3", undo)
+	}
+}
+
+func TestUndoHistoryRecoversUndoneBranch(t *testing.T) {
+	e := New()
+	e.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("abc")})
+	e.undo()
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("xyz")})
+
+	e.showUndoHistory()
+	rows := e.undoHistoryRows()
+	if len(rows) != 3 || e.undoHistoryIndex != 2 {
+		t.Fatalf("want 3 states with the newest selected, got %d (selected %d)", len(rows), e.undoHistoryIndex)
+	}
+	if !strings.HasPrefix(rows[1].graph, e.box.TeeLeft) {
+		t.Errorf("the undone branch should be drawn as a side branch, graph %q", rows[1].graph)
+	}
+
+	e.handleUndoHistoryKey(tea.KeyMsg{Type: tea.KeyUp})
+	e.handleUndoHistoryKey(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := e.activeDoc().buffer.String(); got != "abc" {
+		t.Errorf("going to the undone branch left %q, want %q", got, "abc")
+	}
+	e.undoTo(rows[2].node)
+	if got := e.activeDoc().buffer.String(); got != "xyz" {
+		t.Errorf("going back to the newest state left %q, want %q", got, "xyz")
+	}
+}
+
+func TestParseUndoTravel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want undoTravel
+		ok   bool
+	}{
+		{"", undoTravel{changes: 1}, true},
+		{"5", undoTravel{changes: 5}, true},
+		{"30s", undoTravel{duration: 30 * time.Second}, true},
+		{"10M", undoTravel{duration: 10 * time.Minute}, true},
+		{"1d", undoTravel{duration: 24 * time.Hour}, true},
+		{"2f", undoTravel{checkpoints: 2}, true},
+		{"0", undoTravel{}, false},
+		{"10x", undoTravel{}, false},
+		{"m", undoTravel{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseUndoTravel(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseUndoTravel(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUndoStackTravel(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.SetGroupingInterval(0)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	for i, minutes := range []int{0, 5, 10, 30} {
+		entry := &UndoEntry{Position: i, Inserted: "x"}
+		u.Push(entry)
+		entry.Timestamp = start.Add(time.Duration(minutes) * time.Minute)
+		if minutes == 10 {
+			u.Checkpoint("Saved")
+		}
+	}
+
+	if got := u.travel(undoTravel{duration: 15 * time.Minute}, true); got.seq != 3 {
+		t.Errorf("15 minutes before the last change should reach state 3, got %d", got.seq)
+	}
+	if got := u.travel(undoTravel{duration: 2 * time.Hour}, true); got != u.root {
+		t.Errorf("going back past the first change should reach the original, got %d", got.seq)
+	}
+	if got := u.travel(undoTravel{checkpoints: 1}, true); got.label != "Saved" {
+		t.Errorf("1f back should reach the save, got state %d", got.seq)
+	}
+	if got := u.travel(undoTravel{changes: 2}, true); got.seq != 2 {
+		t.Errorf("2 changes back should reach state 2, got %d", got.seq)
+	}
+
+	// Earlier counts every state made, including ones since undone
+	u.Undo()
+	u.Undo()
+	u.Push(&UndoEntry{Position: 2, Inserted: "y"})
+	if got := u.travel(undoTravel{changes: 1}, true); got.seq != 4 {
+		t.Errorf("1 change back should reach the undone state 4, got %d", got.seq)
+	}
+	if got := u.travel(undoTravel{changes: 1}, false); got != u.current {
+		t.Errorf("nothing was made after the current state, got %d", got.seq)
+	}
+}
+
+func TestUndoStackCheckpointInterval(t *testing.T) {
+	u := NewUndoStack(1000)
+	u.SetGroupingInterval(0)
+	u.SetCheckpointInterval(time.Nanosecond)
+	u.Push(&UndoEntry{Position: 0, Inserted: "a"})
+	time.Sleep(time.Millisecond)
+	u.Push(&UndoEntry{Position: 1, Inserted: "b"})
+	if got := u.current.parent.label; got != "Checkpoint" {
+		t.Errorf("a change after the interval should checkpoint the state before it, label %q", got)
+	}
+	if u.current.label != "" {
+		t.Errorf("the new state should not be a checkpoint yet")
+	}
+}
+
+func TestEarlierAfterReplaceAll(t *testing.T) {
+	e := New()
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("one two one")})
+	e.findQuery, e.replaceQuery = "one", "three"
+	e.replaceAll()
+	e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+
+	e.undoTravel("1f", true)
+	if got := e.activeDoc().buffer.String(); got != "one two one" {
+		t.Errorf("1f back should undo to before Replace All, got %q", got)
+	}
+	e.undoTravel("5", false)
+	if got := e.activeDoc().buffer.String(); got != "!three two three" {
+		t.Errorf("Later should come back to the newest state, got %q", got)
+	}
+}
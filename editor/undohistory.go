@@ -0,0 +1,217 @@
+package editor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// undoHistoryWidth is the width of the Undo History dialog
+const undoHistoryWidth = 64
+
+// undoHistoryRow is a state listed in the Undo History dialog
+type undoHistoryRow struct {
+	node  *undoNode
+	graph string // Branch lines drawn before the state
+}
+
+// undoHistoryRows lists the states of the active buffer's undo tree, oldest
+// first. The newest branch out of each state carries on below it, and older
+// branches are drawn indented just after the state they left.
+func (e *Editor) undoHistoryRows() []undoHistoryRow {
+	var rows []undoHistoryRow
+	var branch func(n *undoNode, first, rest string)
+	branch = func(n *undoNode, first, rest string) {
+		rows = append(rows, undoHistoryRow{node: n, graph: first})
+		for len(n.children) > 0 {
+			last := len(n.children) - 1
+			for _, side := range n.children[:last] {
+				branch(side, rest+e.box.TeeLeft+e.box.Horizontal, rest+e.box.Vertical+" ")
+			}
+			n = n.children[last]
+			rows = append(rows, undoHistoryRow{node: n, graph: rest})
+		}
+	}
+	branch(e.activeDoc().undoStack.root, "", "")
+	return rows
+}
+
+// undoHistoryLabel describes a state: its number, when it was made and the
+// change that made it
+func (e *Editor) undoHistoryLabel(n *undoNode, now time.Time) string {
+	label := fmt.Sprintf("%d  %s", n.seq, e.undoStateDescription(n, now))
+	if n.label != "" {
+		label += "  [" + n.label + "]"
+	}
+	return label
+}
+
+// undoStateDescription says when a state was made and by what change
+func (e *Editor) undoStateDescription(n *undoNode, now time.Time) string {
+	if n.entry == nil {
+		if n.seq == 0 {
+			return "Original"
+		}
+		return "Oldest kept"
+	}
+	when := n.entry.Timestamp.Format("15:04:05")
+	if y, m, d := n.entry.Timestamp.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+		when = n.entry.Timestamp.Format("Jan 2 15:04")
+	}
+	// Line breaks and tabs are spelled out so a change of whitespace shows
+	quote := func(s string) string {
+		s = strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\t", `\t`).Replace(s)
+		return `"` + runewidth.Truncate(inputText(s), 20, e.box.Ellipsis) + `"`
+	}
+	var change string
+	switch {
+	case n.entry.Name != "":
+		change = n.entry.Name
+	case n.entry.Deleted == "":
+		change = "Insert " + quote(n.entry.Inserted)
+	case n.entry.Inserted == "":
+		change = "Delete " + quote(n.entry.Deleted)
+	default:
+		change = "Replace " + quote(n.entry.Deleted) + " with " + quote(n.entry.Inserted)
+	}
+	return when + "  " + change
+}
+
+// showUndoHistory opens the Undo History dialog with the current state selected
+func (e *Editor) showUndoHistory() {
+	u := e.activeDoc().undoStack
+	if !u.CanUndo() && !u.CanRedo() {
+		e.statusbar.SetMessage("No undo history", "info")
+		return
+	}
+	e.undoHistoryIndex = 0
+	for i, row := range e.undoHistoryRows() {
+		if row.node == u.current {
+			e.undoHistoryIndex = i
+		}
+	}
+	e.mode = ModeUndoHistory
+}
+
+// undoHistoryDialog builds the Undo History dialog
+func (e *Editor) undoHistoryDialog() *DialogBuilder {
+	db := e.NewDialogBuilder(undoHistoryWidth)
+	db.AddTitleBorder(" Undo History ")
+	db.AddEmptyLine()
+	current := e.activeDoc().undoStack.current
+	now := time.Now()
+	for i, row := range e.undoHistoryRows() {
+		marker := "   "
+		if row.node == current {
+			marker = " • "
+		}
+		label := runewidth.Truncate(marker+row.graph+e.undoHistoryLabel(row.node, now), db.InnerWidth()-1, e.box.Ellipsis)
+		db.AddSelectableItem(label, i == e.undoHistoryIndex)
+	}
+	db.AddEmptyLine()
+	db.AddCenteredText("[Enter] Go to State  [Esc] Cancel")
+	db.AddBottomBorder()
+	return db
+}
+
+// overlayUndoHistoryDialog overlays the Undo History dialog centered on the viewport
+func (e *Editor) overlayUndoHistoryDialog(viewportContent string) string {
+	return e.undoHistoryDialog().Overlay(viewportContent, e.width, e.viewport.Height())
+}
+
+// chooseUndoHistory closes the dialog and takes the buffer to the chosen state
+func (e *Editor) chooseUndoHistory(index int) {
+	e.mode = ModeNormal
+	rows := e.undoHistoryRows()
+	if index < 0 || index >= len(rows) {
+		return
+	}
+	e.undoTo(rows[index].node)
+	e.statusbar.SetMessage(fmt.Sprintf("Went to state %d", rows[index].node.seq), "info")
+}
+
+// undoTo undoes back to where the way to target branches off, then redoes
+// along target's branch until the buffer is in that state
+func (e *Editor) undoTo(target *undoNode) {
+	doc := e.activeDoc()
+	u := doc.undoStack
+	for !u.current.leadsTo(target) {
+		e.undo()
+	}
+	for u.current != target {
+		u.steerTo(target)
+		e.redo()
+	}
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// handleUndoHistoryKey handles key events in the Undo History dialog
+func (e *Editor) handleUndoHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	count := len(e.undoHistoryRows())
+	page := max(e.viewport.Height()-6, 1)
+	switch msg.Type {
+	case tea.KeyUp:
+		e.undoHistoryIndex = max(e.undoHistoryIndex-1, 0)
+	case tea.KeyDown:
+		e.undoHistoryIndex = min(e.undoHistoryIndex+1, count-1)
+	case tea.KeyPgUp:
+		e.undoHistoryIndex = max(e.undoHistoryIndex-page, 0)
+	case tea.KeyPgDown:
+		e.undoHistoryIndex = min(e.undoHistoryIndex+page, count-1)
+	case tea.KeyHome:
+		e.undoHistoryIndex = 0
+	case tea.KeyEnd:
+		e.undoHistoryIndex = count - 1
+	case tea.KeyEnter:
+		e.chooseUndoHistory(e.undoHistoryIndex)
+	case tea.KeyEsc:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// handleUndoHistoryMouse selects states on click and goes to one on a second click
+func (e *Editor) handleUndoHistoryMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch e.undoHistoryDialog().listMouse(msg, &e.undoHistoryIndex) {
+	case listChoose:
+		e.chooseUndoHistory(e.undoHistoryIndex)
+	case listClose:
+		e.mode = ModeNormal
+	}
+	return e, nil
+}
+
+// showUndoTravel asks how far Earlier (back) or Later should go
+func (e *Editor) showUndoTravel(back bool) {
+	if back {
+		e.showPrompt("Earlier by (10m, 1h, 5 changes, 2f checkpoints): ", PromptEarlier)
+	} else {
+		e.showPrompt("Later by (10m, 1h, 5 changes, 2f checkpoints): ", PromptLater)
+	}
+}
+
+// undoTravel takes the buffer back (Earlier) or forward (Later) through
+// its history by the amount typed: a count of changes, a time, or a count
+// of checkpoints
+func (e *Editor) undoTravel(input string, back bool) {
+	amount, ok := parseUndoTravel(input)
+	if !ok {
+		e.statusbar.SetMessage("Not a count or time: "+input, "error")
+		return
+	}
+	u := e.activeDoc().undoStack
+	target := u.travel(amount, back)
+	if target == u.current {
+		if back {
+			e.statusbar.SetMessage("Already at the oldest change", "info")
+		} else {
+			e.statusbar.SetMessage("Already at the newest change", "info")
+		}
+		return
+	}
+	e.undoTo(target)
+	e.statusbar.SetMessage(fmt.Sprintf("Went to state %d: %s", target.seq, e.undoStateDescription(target, time.Now())), "info")
+}
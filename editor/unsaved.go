@@ -0,0 +1,93 @@
+package editor
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingSave is a save chosen in the unsaved changes dialog that is
+// waiting on a Save As prompt or a question the save asked
+type pendingSave struct {
+	doc  *Document
+	then func() tea.Cmd // Carries on once doc is saved
+}
+
+// confirmUnsaved asks about each of docs with unsaved changes in turn:
+// save it, don't save it, or cancel. Once every one is answered, done runs
+// and its command is returned; nothing runs if any is cancelled.
+func (e *Editor) confirmUnsaved(title, question string, docs []*Document, done func() tea.Cmd) tea.Cmd {
+	var unsaved []*Document
+	for _, doc := range docs {
+		if doc.modified {
+			unsaved = append(unsaved, doc)
+		}
+	}
+	return e.askUnsaved(title, question, unsaved, 0, done)
+}
+
+// askUnsaved shows the unsaved changes dialog for docs[i]
+func (e *Editor) askUnsaved(title, question string, docs []*Document, i int, done func() tea.Cmd) tea.Cmd {
+	// Skip buffers saved or closed since the first question
+	for i < len(docs) && (!docs[i].modified || !slices.Contains(e.documents, docs[i])) {
+		i++
+	}
+	if i == len(docs) {
+		return done()
+	}
+	doc := docs[i]
+	next := func() tea.Cmd {
+		return e.askUnsaved(title, question, docs, i+1, done)
+	}
+
+	message := e.bufferName(doc) + " has unsaved changes.\n" + question
+	if len(docs) > 1 {
+		// Show each buffer while it is asked about
+		e.switchToBuffer(slices.Index(e.documents, doc))
+		message += fmt.Sprintf("\n(%d of %d)", i+1, len(docs))
+	}
+	e.showConfirm(&ConfirmDialog{
+		Title:   title,
+		Message: message,
+		Buttons: []ConfirmButton{
+			{Label: "Save", Hotkey: 's'},
+			{Label: "Don't Save", Hotkey: 'd', Danger: true},
+			{Label: "Cancel", Hotkey: 'c'},
+		},
+		Default: 2,
+		Cancel:  2,
+		OnChoose: func(choice int) tea.Cmd {
+			switch choice {
+			case 0:
+				e.switchToBuffer(slices.Index(e.documents, doc))
+				e.pendingSave = &pendingSave{doc: doc, then: next}
+				e.SaveFile()
+				return e.resumeSave()
+			case 1:
+				return next()
+			}
+			e.statusbar.SetMessage("Cancelled", "info")
+			return nil
+		},
+	})
+	return nil
+}
+
+// resumeSave carries on after a save chosen in the unsaved changes dialog
+// once it has gone through. A save that failed or whose prompt or question
+// was cancelled drops what was to follow.
+func (e *Editor) resumeSave() tea.Cmd {
+	p := e.pendingSave
+	if p == nil {
+		return nil
+	}
+	switch {
+	case !p.doc.modified:
+		e.pendingSave = nil
+		return p.then()
+	case e.mode != ModePrompt && e.mode != ModeConfirm:
+		e.pendingSave = nil
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestQuitAsksAboutEachUnsavedBuffer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // Saving updates the recent lists
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	os.WriteFile(first, []byte("one"), 0644)
+	os.WriteFile(second, []byte("two"), 0644)
+
+	e := New()
+	if err := e.LoadFile(first); err != nil {
+		t.Fatal(err)
+	}
+	e.insertText("1")
+	if err := e.LoadFile(second); err != nil {
+		t.Fatal(err)
+	}
+	e.insertText("2")
+
+	if cmd := e.quitEditor(); cmd != nil || e.mode != ModeConfirm {
+		t.Fatalf("quit with unsaved changes should ask first")
+	}
+	if e.activeDoc().filename != first {
+		t.Errorf("the first unsaved buffer should be shown while asked about")
+	}
+	if _, cmd := e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}}); cmd != nil {
+		t.Fatalf("saving the first buffer should go on to ask about the second")
+	}
+	if data, _ := os.ReadFile(first); string(data) != "1one" {
+		t.Errorf("first.txt = %q, want it saved", data)
+	}
+	if e.mode != ModeConfirm || e.activeDoc().filename != second {
+		t.Fatalf("the second buffer should be asked about next")
+	}
+
+	_, cmd := e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if cmd == nil {
+		t.Fatalf("Don't Save on the last buffer should quit")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Errorf("quit returned %T, want tea.QuitMsg", cmd())
+	}
+	if data, _ := os.ReadFile(second); string(data) != "two" {
+		t.Errorf("second.txt = %q, want it left alone", data)
+	}
+}
+
+func TestCloseSavesUntitledBuffer(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "new.txt")
+
+	e := New()
+	e.newFile()
+	e.insertText("hello")
+	e.closeFile()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if e.mode != ModePrompt || e.promptAction != PromptSaveAs {
+		t.Fatalf("saving an untitled buffer should ask for a name")
+	}
+	e.promptInput = path
+	e.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("new.txt = %q, want the buffer saved", data)
+	}
+	if e.bufferCount() != 1 {
+		t.Errorf("buffers = %d, want the saved buffer closed", e.bufferCount())
+	}
+
+	// Cancelling Save As leaves the buffer open
+	e.insertText("more")
+	e.activeDoc().filename = ""
+	e.closeFile()
+	e.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	e.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if e.pendingSave != nil || !e.activeDoc().modified || e.activeDoc().buffer.String() != "more" {
+		t.Errorf("cancelled Save As should leave the buffer open and unsaved")
+	}
+}
@@ -0,0 +1,740 @@
+package editor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// viMode represents the modal state of the vi keybinding profile
+type viMode int
+
+const (
+	viNormal viMode = iota
+	viInsert
+	viVisual
+	viVisualLine
+)
+
+// viState holds the vi emulation state (shared across buffers)
+type viState struct {
+	mode       viMode
+	count      string // Count prefix typed so far (e.g. "3" in "3dd")
+	pending    string // Pending operator or prefix keys (e.g. "d", "ci", "g")
+	anchorLine int    // Line where visual line mode started
+}
+
+// viEnabled returns true if the vi keybinding profile is active
+func (e *Editor) viEnabled() bool {
+	return e.config != nil && e.config.Editor.KeybindingProfile == config.ProfileVi
+}
+
+// viReset clears any partially typed vi command
+func (e *Editor) viReset() {
+	e.vi.count = ""
+	e.vi.pending = ""
+}
+
+// viModeIndicator returns the status bar label for the current vi mode
+func (e *Editor) viModeIndicator() string {
+	switch e.vi.mode {
+	case viInsert:
+		return "-- INSERT --"
+	case viVisual:
+		return "-- VISUAL --"
+	case viVisualLine:
+		return "-- VISUAL LINE --"
+	}
+	return e.vi.count + e.vi.pending
+}
+
+// handleViKey translates vi commands into editor operations.
+// Returns (true, cmd) if the key was consumed, (false, nil) to fall through
+// to the regular key handling (insert mode typing, Ctrl shortcuts, menus).
+func (e *Editor) handleViKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	doc := e.activeDoc()
+
+	if e.vi.mode == viInsert {
+		if msg.Type == tea.KeyEsc {
+			e.vi.mode = viNormal
+			doc.selection.Clear()
+			// Like vi, step back onto the last inserted character
+			if doc.cursor.Col() > 0 {
+				doc.cursor.MoveLeft()
+			}
+			doc.undoStack.BreakMerge()
+			return true, nil
+		}
+		return false, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		e.viReset()
+		if e.vi.mode != viNormal {
+			e.vi.mode = viNormal
+			doc.selection.Clear()
+		}
+		return true, nil
+	case tea.KeyCtrlR:
+		e.viReset()
+		e.redo()
+		e.viEnsureVisible()
+		return true, nil
+	case tea.KeyCtrlA, tea.KeyCtrlX:
+		// Increment/decrement the number under the cursor, [count] times
+		if e.vi.mode != viNormal {
+			break
+		}
+		count := int64(1)
+		if n, err := strconv.ParseInt(e.vi.count, 10, 64); err == nil && n > 0 {
+			count = n
+		}
+		e.viReset()
+		if msg.Type == tea.KeyCtrlX {
+			count = -count
+		}
+		e.incrementNumber(count)
+		e.viEnsureVisible()
+		return true, nil
+	case tea.KeyCtrlD, tea.KeyCtrlU:
+		// Scroll half a page down or up
+		if e.vi.mode != viNormal {
+			break
+		}
+		e.viReset()
+		if msg.Type == tea.KeyCtrlD {
+			e.scrollHalfPage(1)
+		} else {
+			e.scrollHalfPage(-1)
+		}
+		return true, nil
+	case tea.KeyRunes:
+		if msg.Alt {
+			return false, nil // Alt+key menu shortcuts
+		}
+		if len(msg.Runes) != 1 {
+			return true, nil // Ignore pasted text outside insert mode
+		}
+		e.viCommand(msg.Runes[0])
+		return true, nil
+	case tea.KeySpace:
+		e.viCommand('l')
+		return true, nil
+	case tea.KeyBackspace:
+		e.viCommand('h')
+		return true, nil
+	case tea.KeyEnter:
+		e.viCommand('j')
+		return true, nil
+	case tea.KeyDelete:
+		e.viCommand('x')
+		return true, nil
+	case tea.KeyTab:
+		return true, nil
+	}
+
+	// Arrow keys extend the selection in visual modes
+	if e.vi.mode == viVisual || e.vi.mode == viVisualLine {
+		switch msg.Type {
+		case tea.KeyLeft:
+			e.viCommand('h')
+			return true, nil
+		case tea.KeyRight:
+			e.viCommand('l')
+			return true, nil
+		case tea.KeyUp:
+			e.viCommand('k')
+			return true, nil
+		case tea.KeyDown:
+			e.viCommand('j')
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// viCommand processes a single printable key in normal or visual mode
+func (e *Editor) viCommand(r rune) {
+	// Count prefix ("0" alone is the line-start motion)
+	if (r >= '1' && r <= '9') || (r == '0' && e.vi.count != "") {
+		e.vi.count += string(r)
+		return
+	}
+
+	hasCount := e.vi.count != ""
+	count := 1
+	if hasCount {
+		if n, err := strconv.Atoi(e.vi.count); err == nil && n > 0 {
+			count = n
+		}
+	}
+
+	pending := e.vi.pending
+	e.viReset()
+
+	switch {
+	case pending != "":
+		e.viPending(pending, r, count, hasCount)
+	case e.vi.mode == viVisual || e.vi.mode == viVisualLine:
+		e.viVisualCommand(r, count, hasCount)
+	default:
+		e.viNormalCommand(r, count, hasCount)
+	}
+	e.viEnsureVisible()
+}
+
+// viNormalCommand executes a normal mode command
+func (e *Editor) viNormalCommand(r rune, count int, hasCount bool) {
+	doc := e.activeDoc()
+
+	if _, ok := e.viMotion(r, count, hasCount); ok {
+		doc.selection.Clear()
+		return
+	}
+
+	switch r {
+	case 'i':
+		e.viEnterInsert()
+	case 'a':
+		if doc.cursor.ByteOffset() < doc.buffer.LineEndOffset(doc.cursor.Line()) {
+			doc.cursor.MoveRight()
+		}
+		e.viEnterInsert()
+	case 'I':
+		e.viMoveToFirstNonBlank()
+		e.viEnterInsert()
+	case 'A':
+		doc.cursor.MoveToLineEnd()
+		e.viEnterInsert()
+	case 'o':
+		doc.cursor.MoveToLineEnd()
+		e.insertChar('\n')
+		e.viEnterInsert()
+	case 'O':
+		doc.cursor.MoveToLineStart()
+		e.insertText("\n")
+		doc.cursor.MoveLeft()
+		e.viEnterInsert()
+	case 'x':
+		start := doc.cursor.ByteOffset()
+		end := e.viOffsetRight(start, count)
+		e.viApplyOperator('d', start, end, false)
+	case 'X':
+		end := doc.cursor.ByteOffset()
+		start := e.viOffsetLeft(end, count)
+		e.viApplyOperator('d', start, end, false)
+	case 'D':
+		e.viApplyOperator('d', doc.cursor.ByteOffset(), doc.buffer.LineEndOffset(doc.cursor.Line()), false)
+	case 'C':
+		e.viApplyOperator('c', doc.cursor.ByteOffset(), doc.buffer.LineEndOffset(doc.cursor.Line()), false)
+	case 'p', 'P':
+		e.viPut(r == 'P', count)
+	case 'u':
+		for i := 0; i < count; i++ {
+			e.undo()
+		}
+	case 'v':
+		e.vi.mode = viVisual
+		doc.selection.Start(doc.cursor.ByteOffset())
+	case 'V':
+		e.vi.mode = viVisualLine
+		e.vi.anchorLine = doc.cursor.Line()
+		e.viUpdateLineSelection()
+	case 'n':
+		e.findNext()
+	case '/':
+		e.mode = ModeFind
+		e.findQuery = ""
+		e.findActive = true
+		e.updateViewportSize()
+	case ':':
+		e.showPrompt(":", PromptViCommand)
+	case 'd', 'c', 'y', 'g', 'z':
+		if hasCount {
+			e.vi.count = strconv.Itoa(count)
+		}
+		e.vi.pending = string(r)
+	}
+}
+
+// viPending completes a multi-key command such as "dd", "cw", "yiw" or "gg"
+func (e *Editor) viPending(pending string, r rune, count int, hasCount bool) {
+	doc := e.activeDoc()
+	op := pending[0]
+
+	// "gg" - go to first line, or to line N with a count; "ga" - cursor info
+	if pending == "g" {
+		switch r {
+		case 'g':
+			line := 0
+			if hasCount {
+				line = count - 1
+			}
+			e.viGoToLine(line)
+			doc.selection.Clear()
+		case 'a':
+			e.showCursorInfo()
+		}
+		return
+	}
+
+	// "zz" - scroll the cursor line to the middle of the view
+	if pending == "z" {
+		if r == 'z' {
+			e.centerCursor()
+		}
+		return
+	}
+
+	// Text objects: "iw" (inner word) and "aw" (word plus trailing space)
+	if len(pending) == 2 {
+		if r == 'w' {
+			start, end := e.viWordBounds(doc.cursor.ByteOffset(), pending[1] == 'a')
+			if start < end {
+				e.viApplyOperator(op, start, end, false)
+			}
+		}
+		return
+	}
+
+	switch {
+	case rune(op) == r:
+		// "dd", "cc", "yy" operate on whole lines
+		startLine := doc.cursor.Line()
+		endLine := startLine + count - 1
+		if last := doc.buffer.LineCount() - 1; endLine > last {
+			endLine = last
+		}
+		e.viApplyOperator(op, doc.buffer.LineStartOffset(startLine), doc.buffer.LineEndOffset(endLine), true)
+	case r == 'i' || r == 'a':
+		if hasCount {
+			e.vi.count = strconv.Itoa(count)
+		}
+		e.vi.pending = pending + string(r)
+	default:
+		// Operator followed by a motion
+		start := doc.cursor.ByteOffset()
+		startLine := doc.cursor.Line()
+		// "cw" behaves like "ce" in vi: don't eat the trailing whitespace
+		if op == 'c' && r == 'w' {
+			_, end := e.viWordBounds(start, false)
+			if end > start {
+				e.viApplyOperator(op, start, end, false)
+				return
+			}
+		}
+		linewise, ok := e.viMotion(r, count, hasCount)
+		if !ok {
+			return
+		}
+		end := doc.cursor.ByteOffset()
+		if linewise {
+			endLine := doc.cursor.Line()
+			if endLine < startLine {
+				startLine, endLine = endLine, startLine
+			}
+			e.viApplyOperator(op, doc.buffer.LineStartOffset(startLine), doc.buffer.LineEndOffset(endLine), true)
+			return
+		}
+		if end < start {
+			start, end = end, start
+		}
+		e.viApplyOperator(op, start, end, false)
+	}
+}
+
+// viVisualCommand executes a command while in visual or visual line mode
+func (e *Editor) viVisualCommand(r rune, count int, hasCount bool) {
+	doc := e.activeDoc()
+
+	if _, ok := e.viMotion(r, count, hasCount); ok {
+		if e.vi.mode == viVisualLine {
+			e.viUpdateLineSelection()
+		} else {
+			doc.selection.Update(doc.cursor.ByteOffset())
+		}
+		return
+	}
+
+	switch r {
+	case 'd', 'x', 'c', 'y':
+		start, end := doc.selection.Normalize()
+		linewise := e.vi.mode == viVisualLine
+		if linewise {
+			first, last := e.vi.anchorLine, doc.cursor.Line()
+			if first > last {
+				first, last = last, first
+			}
+			start, end = doc.buffer.LineStartOffset(first), doc.buffer.LineEndOffset(last)
+		} else {
+			// Visual selections include the character under the cursor
+			end = e.viOffsetRight(end, 1)
+		}
+		op := byte(r)
+		if op == 'x' {
+			op = 'd'
+		}
+		e.vi.mode = viNormal
+		doc.selection.Clear()
+		e.viApplyOperator(op, start, end, linewise)
+	case '>':
+		e.indentLines()
+		e.viExitVisual()
+	case '<':
+		e.dedentLines()
+		e.viExitVisual()
+	case 'v':
+		if e.vi.mode == viVisual {
+			e.viExitVisual()
+		} else {
+			e.vi.mode = viVisual
+			doc.selection.Start(doc.buffer.LineStartOffset(e.vi.anchorLine))
+			doc.selection.Update(doc.cursor.ByteOffset())
+		}
+	case 'V':
+		if e.vi.mode == viVisualLine {
+			e.viExitVisual()
+		} else {
+			e.vi.mode = viVisualLine
+			e.vi.anchorLine, _ = doc.buffer.PositionToLineCol(doc.selection.Anchor)
+			e.viUpdateLineSelection()
+		}
+	case 'g':
+		if hasCount {
+			e.vi.count = strconv.Itoa(count)
+		}
+		e.vi.pending = "g"
+	}
+}
+
+// viMotion moves the cursor according to a vi motion key.
+// Returns whether the motion is linewise and whether r was a motion at all.
+func (e *Editor) viMotion(r rune, count int, hasCount bool) (linewise bool, ok bool) {
+	doc := e.activeDoc()
+	cur := doc.cursor
+
+	switch r {
+	case 'h':
+		for i := 0; i < count && cur.Col() > 0; i++ {
+			cur.MoveLeft()
+		}
+	case 'l':
+		for i := 0; i < count && cur.ByteOffset() < doc.buffer.LineEndOffset(cur.Line()); i++ {
+			cur.MoveRight()
+		}
+	case 'j':
+		e.moveVisual(count)
+		return true, true
+	case 'k':
+		e.moveVisual(-count)
+		return true, true
+	case 'w':
+		for i := 0; i < count; i++ {
+			if !cur.MoveWordRight() {
+				break
+			}
+		}
+	case 'b':
+		for i := 0; i < count; i++ {
+			if !cur.MoveWordLeft() {
+				break
+			}
+		}
+	case '0':
+		cur.MoveToLineStart()
+	case '^':
+		e.viMoveToFirstNonBlank()
+	case '$':
+		if count > 1 {
+			cur.SetPosition(cur.Line()+count-1, 0)
+		}
+		cur.MoveToLineEnd()
+	case 'G':
+		line := doc.buffer.LineCount() - 1
+		if hasCount {
+			line = count - 1
+		}
+		e.viGoToLine(line)
+		return true, true
+	default:
+		return false, false
+	}
+	return false, true
+}
+
+// viApplyOperator applies d (delete), c (change) or y (yank) to a byte range.
+// Deleted and yanked text goes to the clipboard, like vi's unnamed register.
+func (e *Editor) viApplyOperator(op byte, start, end int, linewise bool) {
+	doc := e.activeDoc()
+	if start > end {
+		start, end = end, start
+	}
+
+	text := doc.buffer.Substring(start, end)
+	if linewise {
+		text += "\n"
+		if op == 'd' {
+			// Remove the line break too so the lines disappear entirely
+			if end < doc.buffer.Length() {
+				end++
+			} else if start > 0 {
+				start--
+			}
+		}
+	}
+	if start == end && op != 'c' {
+		return
+	}
+
+	if op == 'y' {
+		verb := "Yanked"
+		if linewise {
+			verb = fmt.Sprintf("Yanked %d lines,", strings.Count(text, "\n"))
+		}
+		e.copyText(text, verb, func() { doc.cursor.SetByteOffset(start) })
+		return
+	}
+
+	// The text is only deleted once it is on the clipboard, so cancelling a
+	// large copy leaves it in place
+	apply := func() {
+		if start < end {
+			doc.selection.Start(start)
+			doc.selection.Update(end)
+			e.deleteSelection()
+		}
+		doc.cursor.SetByteOffset(start)
+		if op == 'c' {
+			e.viEnterInsert()
+		} else if linewise {
+			e.viMoveToFirstNonBlank()
+		}
+		doc.undoStack.BreakMerge()
+	}
+	if text == "" {
+		apply() // A change with nothing to delete leaves the clipboard alone
+		return
+	}
+	e.copyText(text, "Deleted", apply)
+}
+
+// viPut pastes the clipboard after (p) or before (P) the cursor.
+// Text ending in a newline is pasted as whole lines.
+func (e *Editor) viPut(before bool, count int) {
+	doc := e.activeDoc()
+	text, err := e.clipboard.Paste()
+	if err != nil || text == "" {
+		return
+	}
+	text = strings.Repeat(text, count)
+
+	if strings.HasSuffix(text, "\n") {
+		line := doc.cursor.Line()
+		if before {
+			doc.cursor.MoveToLineStart()
+			e.insertText(text)
+			doc.cursor.SetPosition(line, 0)
+		} else {
+			doc.cursor.MoveToLineEnd()
+			e.insertText("\n" + strings.TrimSuffix(text, "\n"))
+			doc.cursor.SetPosition(line+1, 0)
+		}
+		e.viMoveToFirstNonBlank()
+	} else {
+		if !before && doc.cursor.ByteOffset() < doc.buffer.LineEndOffset(doc.cursor.Line()) {
+			doc.cursor.MoveRight()
+		}
+		e.insertText(text)
+		doc.cursor.MoveLeft()
+	}
+	doc.selection.Clear()
+	doc.undoStack.BreakMerge()
+}
+
+// viEnterInsert switches to insert mode
+func (e *Editor) viEnterInsert() {
+	e.vi.mode = viInsert
+	e.activeDoc().selection.Clear()
+	e.activeDoc().undoStack.BreakMerge()
+}
+
+// viExitVisual leaves visual mode and clears the selection
+func (e *Editor) viExitVisual() {
+	e.vi.mode = viNormal
+	e.activeDoc().selection.Clear()
+}
+
+// viUpdateLineSelection selects whole lines between the anchor line and the cursor
+func (e *Editor) viUpdateLineSelection() {
+	doc := e.activeDoc()
+	first, last := e.vi.anchorLine, doc.cursor.Line()
+	if first > last {
+		first, last = last, first
+	}
+	end := doc.buffer.LineEndOffset(last)
+	if end < doc.buffer.Length() {
+		end++ // Include the newline so the full line is highlighted
+	}
+	doc.selection.Start(doc.buffer.LineStartOffset(first))
+	doc.selection.Update(end)
+}
+
+// viGoToLine moves the cursor to the first non-blank character of a line
+func (e *Editor) viGoToLine(line int) {
+	doc := e.activeDoc()
+	if last := doc.buffer.LineCount() - 1; line > last {
+		line = last
+	}
+	if line < 0 {
+		line = 0
+	}
+	doc.cursor.SetPosition(line, 0)
+	e.viMoveToFirstNonBlank()
+}
+
+// viMoveToFirstNonBlank moves the cursor to the first non-whitespace character of the line
+func (e *Editor) viMoveToFirstNonBlank() {
+	doc := e.activeDoc()
+	doc.cursor.MoveToLineStart()
+	end := doc.buffer.LineEndOffset(doc.cursor.Line())
+	pos := doc.cursor.ByteOffset()
+	for pos < end {
+		r, size := doc.buffer.RuneAt(pos)
+		if r != ' ' && r != '\t' {
+			break
+		}
+		pos += size
+	}
+	doc.cursor.SetByteOffset(pos)
+}
+
+// viOffsetRight returns the offset n characters right of pos, stopping at the end of the line
+func (e *Editor) viOffsetRight(pos, n int) int {
+	buf := e.activeDoc().buffer
+	line, _ := buf.PositionToLineCol(pos)
+	end := buf.LineEndOffset(line)
+	for i := 0; i < n && pos < end; i++ {
+		size := buf.graphemeAfter(pos)
+		if size == 0 {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+// viOffsetLeft returns the offset n characters left of pos, stopping at the start of the line
+func (e *Editor) viOffsetLeft(pos, n int) int {
+	buf := e.activeDoc().buffer
+	line, _ := buf.PositionToLineCol(pos)
+	start := buf.LineStartOffset(line)
+	for i := 0; i < n && pos > start; i++ {
+		pos -= buf.graphemeBefore(pos)
+	}
+	return pos
+}
+
+// viWordBounds returns the byte range of the word (or run of whitespace or
+// punctuation) under pos. With around set, trailing whitespace is included.
+func (e *Editor) viWordBounds(pos int, around bool) (int, int) {
+	buf := e.activeDoc().buffer
+	line, _ := buf.PositionToLineCol(pos)
+	lineStart := buf.LineStartOffset(line)
+	lineEnd := buf.LineEndOffset(line)
+	if pos >= lineEnd {
+		return pos, pos
+	}
+
+	class := func(r rune) int {
+		switch {
+		case isWordChar(r):
+			return 0
+		case unicode.IsSpace(r):
+			return 1
+		}
+		return 2
+	}
+
+	r, _ := buf.RuneAt(pos)
+	want := class(r)
+
+	start := pos
+	for start > lineStart {
+		prev := start - 1
+		for prev > lineStart && !utf8.RuneStart(buf.ByteAt(prev)) {
+			prev--
+		}
+		pr, _ := buf.RuneAt(prev)
+		if class(pr) != want {
+			break
+		}
+		start = prev
+	}
+
+	end := pos
+	for end < lineEnd {
+		nr, size := buf.RuneAt(end)
+		if class(nr) != want {
+			break
+		}
+		end += size
+	}
+
+	if around {
+		for end < lineEnd {
+			nr, size := buf.RuneAt(end)
+			if !unicode.IsSpace(nr) {
+				break
+			}
+			end += size
+		}
+	}
+	return start, end
+}
+
+// viEnsureVisible scrolls the viewport to keep the cursor on screen
+func (e *Editor) viEnsureVisible() {
+	doc := e.activeDoc()
+	e.viewport.EnsureCursorVisibleWrapped(doc.buffer.Lines(), doc.cursor.Line(), doc.cursor.Col())
+}
+
+// executeViCommand runs an ex command entered after ":" (w, q, q!, wq, x, or a line number)
+func (e *Editor) executeViCommand(input string) {
+	switch input {
+	case "":
+		return
+	case "w":
+		e.SaveFile()
+	case "q":
+		if e.quitEditor() != nil {
+			e.pendingQuit = true
+		}
+	case "q!", "qa!":
+		e.pendingQuit = true
+	case "wq", "x":
+		if e.SaveFile() && e.quitEditor() != nil {
+			e.pendingQuit = true
+		}
+	default:
+		// :earlier and :later travel through the undo history, as in vim
+		if cmd, arg, _ := strings.Cut(input, " "); cmd == "earlier" || cmd == "ea" || cmd == "later" || cmd == "lat" {
+			e.undoTravel(arg, cmd == "earlier" || cmd == "ea")
+			return
+		}
+		if lineNum, err := strconv.Atoi(input); err == nil {
+			e.viGoToLine(lineNum - 1)
+			e.activeDoc().selection.Clear()
+			e.viEnsureVisible()
+			return
+		}
+		e.statusbar.SetMessage("Not an editor command: "+input, "error")
+	}
+}
@@ -0,0 +1,152 @@
+package editor
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/clipboard"
+	"github.com/cornish/textivus-editor/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newViTestEditor creates an editor in vi mode with the given buffer contents
+func newViTestEditor(content string) *Editor {
+	cfg := config.DefaultConfig()
+	cfg.Editor.KeybindingProfile = config.ProfileVi
+	e := NewWithConfig(cfg)
+	e.clipboard = clipboard.New(io.Discard)
+	buf := NewBufferFromString(content)
+	doc := e.activeDoc()
+	doc.buffer = buf
+	doc.cursor = NewCursor(buf)
+	return e
+}
+
+// viKeys sends each rune as a separate key press
+func viKeys(e *Editor, keys string) {
+	for _, r := range keys {
+		e.handleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestViMotions(t *testing.T) {
+	e := newViTestEditor("one two three\nfour five\nsix")
+
+	viKeys(e, "w")
+	if got := e.activeDoc().cursor.Col(); got != 4 {
+		t.Errorf("after w, col = %d, want 4", got)
+	}
+	viKeys(e, "j")
+	if got := e.activeDoc().cursor.Line(); got != 1 {
+		t.Errorf("after j, line = %d, want 1", got)
+	}
+	viKeys(e, "G")
+	if got := e.activeDoc().cursor.Line(); got != 2 {
+		t.Errorf("after G, line = %d, want 2", got)
+	}
+	viKeys(e, "gg$")
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 0 || col != 13 {
+		t.Errorf("after gg$, pos = %d:%d, want 0:13", line, col)
+	}
+	viKeys(e, "0")
+	if got := e.activeDoc().cursor.Col(); got != 0 {
+		t.Errorf("after 0, col = %d, want 0", got)
+	}
+}
+
+func TestViDeleteLines(t *testing.T) {
+	e := newViTestEditor("a\nb\nc\nd")
+
+	viKeys(e, "j2dd")
+	if got := e.activeDoc().buffer.String(); got != "a\nd" {
+		t.Errorf("after j2dd, buffer = %q, want %q", got, "a\nd")
+	}
+
+	viKeys(e, "u")
+	if got := e.activeDoc().buffer.String(); got != "a\nb\nc\nd" {
+		t.Errorf("after u, buffer = %q, want original", got)
+	}
+}
+
+func TestViYankPut(t *testing.T) {
+	e := newViTestEditor("first\nsecond")
+
+	viKeys(e, "yyjp")
+	if got := e.activeDoc().buffer.String(); got != "first\nsecond\nfirst" {
+		t.Errorf("after yyjp, buffer = %q", got)
+	}
+}
+
+func TestViChangeInnerWord(t *testing.T) {
+	e := newViTestEditor("hello big world")
+
+	viKeys(e, "wciw")
+	if e.vi.mode != viInsert {
+		t.Fatalf("ciw should enter insert mode")
+	}
+	viKeys(e, "small")
+	e.handleKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := e.activeDoc().buffer.String(); got != "hello small world" {
+		t.Errorf("after ciw, buffer = %q", got)
+	}
+	if e.vi.mode != viNormal {
+		t.Errorf("Esc should return to normal mode")
+	}
+}
+
+func TestViCountedDeleteChar(t *testing.T) {
+	e := newViTestEditor("abcdef")
+
+	viKeys(e, "3x")
+	if got := e.activeDoc().buffer.String(); got != "def" {
+		t.Errorf("after 3x, buffer = %q, want %q", got, "def")
+	}
+}
+
+func TestViVisualDelete(t *testing.T) {
+	e := newViTestEditor("abcdef")
+
+	viKeys(e, "lvld")
+	if got := e.activeDoc().buffer.String(); got != "adef" {
+		t.Errorf("after lvld, buffer = %q, want %q", got, "adef")
+	}
+}
+
+func TestViScrolling(t *testing.T) {
+	e := newViTestEditor(strings.Repeat("line\n", 100))
+	e.viewport.SetSize(40, 20)
+
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if line, y := e.activeDoc().cursor.Line(), e.viewport.ScrollY(); line != 10 || y != 10 {
+		t.Errorf("after Ctrl+D line %d, scrollY %d, want 10, 10", line, y)
+	}
+	e.handleKey(tea.KeyMsg{Type: tea.KeyCtrlU})
+	if line, y := e.activeDoc().cursor.Line(), e.viewport.ScrollY(); line != 0 || y != 0 {
+		t.Errorf("after Ctrl+U line %d, scrollY %d, want 0, 0", line, y)
+	}
+
+	viKeys(e, "50Gzz")
+	if got := e.viewport.ScrollY(); got != 39 {
+		t.Errorf("after 50Gzz scrollY %d, want 39", got)
+	}
+}
+
+func TestViVerticalKeepsColumn(t *testing.T) {
+	e := newViTestEditor("abcdefgh\nab\nabcdefgh")
+	viKeys(e, "5l")
+
+	viKeys(e, "j")
+	if col := e.activeDoc().cursor.Col(); col != 2 {
+		t.Errorf("on the short line col = %d, want 2", col)
+	}
+	viKeys(e, "j")
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 2 || col != 5 {
+		t.Errorf("after jj cursor at %d:%d, want 2:5", line, col)
+	}
+	viKeys(e, "2k")
+	if line, col := e.activeDoc().cursor.Line(), e.activeDoc().cursor.Col(); line != 0 || col != 5 {
+		t.Errorf("after 2k cursor at %d:%d, want 0:5", line, col)
+	}
+}
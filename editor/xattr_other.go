@@ -0,0 +1,6 @@
+//go:build !linux && !darwin
+
+package editor
+
+// copyXattrs is not available on this platform
+func copyXattrs(from, to string) {}
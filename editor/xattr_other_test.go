@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package editor
+
+import "errors"
+
+// setTestXattr reports that this platform has no extended attributes
+func setTestXattr(path, name, value string) error {
+	return errors.New("no extended attributes")
+}
+
+// getTestXattr reports that this platform has no extended attributes
+func getTestXattr(path, name string) (string, error) {
+	return "", errors.New("no extended attributes")
+}
@@ -0,0 +1,38 @@
+//go:build linux || darwin
+
+package editor
+
+import (
+	"bytes"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs gives to the extended attributes of from (including ACLs on
+// Linux). Attributes the filesystem or our privileges won't allow are
+// skipped.
+func copyXattrs(from, to string) {
+	size, err := unix.Listxattr(from, nil)
+	if err != nil || size <= 0 {
+		return
+	}
+	names := make([]byte, size)
+	if size, err = unix.Listxattr(from, names); err != nil {
+		return
+	}
+	for _, name := range bytes.Split(names[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+		n, err := unix.Getxattr(from, attr, nil)
+		if err != nil {
+			continue
+		}
+		value := make([]byte, n)
+		if n, err = unix.Getxattr(from, attr, value); err != nil {
+			continue
+		}
+		unix.Setxattr(to, attr, value[:n], 0)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin
+
+package editor
+
+import "golang.org/x/sys/unix"
+
+// setTestXattr sets an extended attribute, failing where the filesystem has none
+func setTestXattr(path, name, value string) error {
+	return unix.Setxattr(path, name, []byte(value), 0)
+}
+
+// getTestXattr reads an extended attribute
+func getTestXattr(path, name string) (string, error) {
+	buf := make([]byte, 256)
+	n, err := unix.Getxattr(path, name, buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
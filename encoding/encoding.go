@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/saintfish/chardet"
 	"golang.org/x/text/encoding"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/text/encoding/korean"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
 	"golang.org/x/text/transform"
 )
 
@@ -24,6 +26,8 @@ type Encoding struct {
 	Aliases     []string          // Alternative names from chardet
 	Supported   bool              // Whether we support encoding/decoding
 	Description string            // Brief description
+	BOM         []byte            // Byte order mark, stripped on load when present (nil = none)
+	WriteBOM    bool              // Start saved files with the BOM
 }
 
 // DetectionResult holds the result of encoding detection
@@ -31,6 +35,7 @@ type DetectionResult struct {
 	Encoding   *Encoding
 	Confidence int  // 0-100
 	HasBOM     bool // Whether a BOM was detected
+	Binary     bool // Data looks like a binary file rather than text
 }
 
 // SupportedEncodings is the list of encodings we fully support
@@ -50,22 +55,80 @@ var SupportedEncodings = []*Encoding{
 		Aliases:     []string{},
 		Supported:   true,
 		Description: "Unicode with byte order mark",
+		BOM:         utf8BOM,
+		WriteBOM:    true,
 	},
 	{
 		Name:        "UTF-16 LE",
 		ID:          "utf-16-le",
-		Encoder:     unicode.UTF16(unicode.LittleEndian, unicode.UseBOM),
-		Aliases:     []string{"UTF-16LE"},
+		Encoder:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
 		Supported:   true,
 		Description: "Unicode 16-bit (Little Endian)",
+		BOM:         utf16LEBOM,
+		WriteBOM:    true,
 	},
 	{
 		Name:        "UTF-16 BE",
 		ID:          "utf-16-be",
-		Encoder:     unicode.UTF16(unicode.BigEndian, unicode.UseBOM),
-		Aliases:     []string{"UTF-16BE"},
+		Encoder:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
 		Supported:   true,
 		Description: "Unicode 16-bit (Big Endian)",
+		BOM:         utf16BEBOM,
+		WriteBOM:    true,
+	},
+	{
+		Name:        "UTF-16 LE no BOM",
+		ID:          "utf-16-le-nobom",
+		Encoder:     unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+		Aliases:     []string{"UTF-16LE"},
+		Supported:   true,
+		Description: "Unicode 16-bit, no byte order mark",
+		BOM:         utf16LEBOM,
+	},
+	{
+		Name:        "UTF-16 BE no BOM",
+		ID:          "utf-16-be-nobom",
+		Encoder:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+		Aliases:     []string{"UTF-16BE"},
+		Supported:   true,
+		Description: "Unicode 16-bit, no byte order mark",
+		BOM:         utf16BEBOM,
+	},
+	{
+		Name:        "UTF-32 LE",
+		ID:          "utf-32-le",
+		Encoder:     utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM),
+		Supported:   true,
+		Description: "Unicode 32-bit (Little Endian)",
+		BOM:         utf32LEBOM,
+		WriteBOM:    true,
+	},
+	{
+		Name:        "UTF-32 BE",
+		ID:          "utf-32-be",
+		Encoder:     utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM),
+		Supported:   true,
+		Description: "Unicode 32-bit (Big Endian)",
+		BOM:         utf32BEBOM,
+		WriteBOM:    true,
+	},
+	{
+		Name:        "UTF-32 LE no BOM",
+		ID:          "utf-32-le-nobom",
+		Encoder:     utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM),
+		Aliases:     []string{"UTF-32LE"},
+		Supported:   true,
+		Description: "Unicode 32-bit, no byte order mark",
+		BOM:         utf32LEBOM,
+	},
+	{
+		Name:        "UTF-32 BE no BOM",
+		ID:          "utf-32-be-nobom",
+		Encoder:     utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM),
+		Aliases:     []string{"UTF-32BE"},
+		Supported:   true,
+		Description: "Unicode 32-bit, no byte order mark",
+		BOM:         utf32BEBOM,
 	},
 	{
 		Name:        "ISO-8859-1",
@@ -140,6 +203,43 @@ var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
 var utf16LEBOM = []byte{0xFF, 0xFE}
 var utf16BEBOM = []byte{0xFE, 0xFF}
 
+// UTF-32 BOMs (the little endian one starts like UTF-16 LE's, so check it first)
+var utf32LEBOM = []byte{0xFF, 0xFE, 0x00, 0x00}
+var utf32BEBOM = []byte{0x00, 0x00, 0xFE, 0xFF}
+
+// wideSample is how much of a file is examined for BOM-less UTF-16 and UTF-32
+const wideSample = 4096
+
+// binarySample is how much of a file is examined for binary content
+const binarySample = 8000
+
+// bomPairs links each Unicode encoding written without a BOM to the one
+// written with it
+var bomPairs = [][2]string{
+	{"utf-8", "utf-8-bom"},
+	{"utf-16-le-nobom", "utf-16-le"},
+	{"utf-16-be-nobom", "utf-16-be"},
+	{"utf-32-le-nobom", "utf-32-le"},
+	{"utf-32-be-nobom", "utf-32-be"},
+}
+
+// BOMVariant returns the same encoding with the byte order mark added or
+// removed, or nil for encodings that have none
+func BOMVariant(enc *Encoding) *Encoding {
+	if enc == nil {
+		return nil
+	}
+	for _, pair := range bomPairs {
+		switch enc.ID {
+		case pair[0]:
+			return GetEncodingByID(pair[1])
+		case pair[1]:
+			return GetEncodingByID(pair[0])
+		}
+	}
+	return nil
+}
+
 // GetEncodingByID returns an encoding by its ID
 func GetEncodingByID(id string) *Encoding {
 	id = strings.ToLower(id)
@@ -181,14 +281,27 @@ func Detect(data []byte) *DetectionResult {
 		result.HasBOM = true
 		return result
 	}
-	if bytes.HasPrefix(data, utf16BEBOM) {
-		result.Encoding = GetEncodingByID("utf-16-be")
-		result.HasBOM = true
+	for _, id := range []string{"utf-32-le", "utf-32-be", "utf-16-be", "utf-16-le"} {
+		if enc := GetEncodingByID(id); bytes.HasPrefix(data, enc.BOM) {
+			result.Encoding = enc
+			result.HasBOM = true
+			return result
+		}
+	}
+
+	// Text full of NUL bytes is valid UTF-8, so look for UTF-16 and UTF-32
+	// without a BOM first
+	if id := detectWide(data); id != "" {
+		result.Encoding = GetEncodingByID(id)
+		result.Confidence = 80
 		return result
 	}
-	if bytes.HasPrefix(data, utf16LEBOM) {
-		result.Encoding = GetEncodingByID("utf-16-le")
-		result.HasBOM = true
+
+	// Binary data reads as Latin-1, which maps every byte to a character
+	if looksBinary(data) {
+		result.Encoding = GetEncodingByID("iso-8859-1")
+		result.Confidence = 50
+		result.Binary = true
 		return result
 	}
 
@@ -227,6 +340,77 @@ func Detect(data []byte) *DetectionResult {
 	return result
 }
 
+// detectWide recognizes UTF-16 and UTF-32 text without a BOM by its zero
+// bytes: mostly Latin text leaves the high bytes of its code units zero. It
+// returns the encoding ID, or "" when the data doesn't look like either.
+func detectWide(data []byte) string {
+	data = data[:min(len(data), wideSample)]
+	if len(data) < 4 {
+		return ""
+	}
+
+	// UTF-32: every unit is a valid code point, none of them NUL
+	if len(data)%4 == 0 {
+		le, be := true, true
+		for i := 0; i < len(data); i += 4 {
+			u := data[i : i+4]
+			le = le && u[3] == 0 && u[2] <= 0x10 && (u[0] != 0 || u[1] != 0 || u[2] != 0)
+			be = be && u[0] == 0 && u[1] <= 0x10 && (u[3] != 0 || u[2] != 0 || u[1] != 0)
+		}
+		switch {
+		case le:
+			return "utf-32-le-nobom"
+		case be:
+			return "utf-32-be-nobom"
+		}
+	}
+
+	// UTF-16: no NUL units, zero bytes only on the high side of at least
+	// half the units
+	if len(data)%2 != 0 {
+		data = data[:len(data)-1]
+	}
+	units := len(data) / 2
+	evenZero, oddZero := 0, 0
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			return ""
+		}
+		if data[i] == 0 {
+			evenZero++
+		}
+		if data[i+1] == 0 {
+			oddZero++
+		}
+	}
+	switch {
+	case evenZero == 0 && oddZero*2 >= units:
+		return "utf-16-le-nobom"
+	case oddZero == 0 && evenZero*2 >= units:
+		return "utf-16-be-nobom"
+	}
+	return ""
+}
+
+// looksBinary reports whether data is probably not text: it has NUL bytes,
+// or more control characters than text in any encoding would. Only the
+// start of the data is examined.
+func looksBinary(data []byte) bool {
+	data = data[:min(len(data), binarySample)]
+	controls := 0
+	for _, b := range data {
+		switch {
+		case b == 0:
+			return true
+		case b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v' || b == 0x1b:
+			// Whitespace and terminal escapes turn up in text files
+		case b < 0x20 || b == 0x7f:
+			controls++
+		}
+	}
+	return controls*10 > len(data)
+}
+
 // isValidUTF8 checks if data is valid UTF-8
 func isValidUTF8(data []byte) bool {
 	// Check for invalid UTF-8 sequences
@@ -266,21 +450,14 @@ func isValidUTF8(data []byte) bool {
 	return true
 }
 
-// DecodeToUTF8 decodes data from the given encoding to UTF-8
+// DecodeToUTF8 decodes data from the given encoding to UTF-8.
+// A BOM is stripped whether or not the encoding writes one back on save.
 func DecodeToUTF8(data []byte, enc *Encoding) ([]byte, error) {
-	if enc == nil || enc.Encoder == nil {
-		// Already UTF-8 or UTF-8 BOM
-		if enc != nil && enc.ID == "utf-8-bom" && bytes.HasPrefix(data, utf8BOM) {
-			return data[3:], nil // Strip BOM
-		}
-		return data, nil
+	if enc != nil && enc.BOM != nil {
+		data = bytes.TrimPrefix(data, enc.BOM)
 	}
-
-	// Handle UTF-16 BOMs
-	if enc.ID == "utf-16-le" && bytes.HasPrefix(data, utf16LEBOM) {
-		data = data[2:]
-	} else if enc.ID == "utf-16-be" && bytes.HasPrefix(data, utf16BEBOM) {
-		data = data[2:]
+	if enc == nil || enc.Encoder == nil {
+		return data, nil // Already UTF-8
 	}
 
 	reader := transform.NewReader(bytes.NewReader(data), enc.Encoder.NewDecoder())
@@ -291,21 +468,17 @@ func DecodeToUTF8(data []byte, enc *Encoding) ([]byte, error) {
 // Returns an error if characters cannot be represented.
 // Use EncodeFromUTF8Lossy for lossy conversion with replacement characters.
 func EncodeFromUTF8(data []byte, enc *Encoding) ([]byte, error) {
-	if enc == nil || enc.Encoder == nil {
-		// UTF-8 or UTF-8 BOM
-		if enc != nil && enc.ID == "utf-8-bom" {
-			return append(utf8BOM, data...), nil
-		}
+	if enc == nil || (enc.Encoder == nil && !enc.WriteBOM) {
 		return data, nil
 	}
 
 	var buf bytes.Buffer
-
-	// Add BOM for UTF-16
-	if enc.ID == "utf-16-le" {
-		buf.Write(utf16LEBOM)
-	} else if enc.ID == "utf-16-be" {
-		buf.Write(utf16BEBOM)
+	if enc.WriteBOM {
+		buf.Write(enc.BOM)
+	}
+	if enc.Encoder == nil {
+		buf.Write(data) // UTF-8 BOM
+		return buf.Bytes(), nil
 	}
 
 	writer := transform.NewWriter(&buf, enc.Encoder.NewEncoder())
@@ -322,55 +495,81 @@ func EncodeFromUTF8(data []byte, enc *Encoding) ([]byte, error) {
 }
 
 // EncodeFromUTF8Lossy encodes UTF-8 data to the given encoding,
-// replacing characters that cannot be represented with a substitute character.
+// writing Substitute for characters that cannot be represented.
 func EncodeFromUTF8Lossy(data []byte, enc *Encoding) []byte {
-	if enc == nil || enc.Encoder == nil {
-		// UTF-8 or UTF-8 BOM
-		if enc != nil && enc.ID == "utf-8-bom" {
-			return append(utf8BOM, data...)
-		}
+	out, err := EncodeFromUTF8(replaceUnsupported(data, enc, Substitute), enc)
+	if err != nil {
 		return data
 	}
+	return out
+}
 
-	var buf bytes.Buffer
+// LossyChar is a character the target encoding cannot represent
+type LossyChar struct {
+	Rune rune
+	Line int // Zero-based line
+	Col  int // Zero-based column, in runes
+}
 
-	// Add BOM for UTF-16
-	if enc.ID == "utf-16-le" {
-		buf.Write(utf16LEBOM)
-	} else if enc.ID == "utf-16-be" {
-		buf.Write(utf16BEBOM)
+// FindEncodingLoss lists the characters in data that the target encoding
+// cannot represent, in order
+func FindEncodingLoss(data []byte, enc *Encoding) []LossyChar {
+	if enc == nil || enc.Encoder == nil {
+		// UTF-8 can represent everything
+		return nil
 	}
 
-	// Use ReplaceUnsupported to handle characters that can't be encoded
-	encoder := encoding.ReplaceUnsupported(enc.Encoder.NewEncoder())
-	writer := transform.NewWriter(&buf, encoder)
-	writer.Write(data)
-	writer.Close()
-
-	return buf.Bytes()
+	var lost []LossyChar
+	encoder := enc.Encoder.NewEncoder()
+	line, col := 0, 0
+	for _, r := range string(data) {
+		if r == '\n' {
+			line++
+			col = 0
+			continue
+		}
+		if _, err := encoder.Bytes([]byte(string(r))); err != nil {
+			lost = append(lost, LossyChar{Rune: r, Line: line, Col: col})
+		}
+		encoder.Reset()
+		col++
+	}
+	return lost
 }
 
 // CheckEncodingLoss checks if encoding the data will cause character loss.
 // Returns the number of characters that cannot be represented in the target encoding.
 func CheckEncodingLoss(data []byte, enc *Encoding) int {
+	return len(FindEncodingLoss(data, enc))
+}
+
+// Substitute replaces characters a lossy save cannot represent. Every
+// supported encoding has it.
+const Substitute = "?"
+
+// DropUnsupported removes the characters the target encoding cannot
+// represent, so the rest encodes without loss
+func DropUnsupported(data []byte, enc *Encoding) []byte {
+	return replaceUnsupported(data, enc, "")
+}
+
+// replaceUnsupported replaces each character the target encoding cannot
+// represent with sub
+func replaceUnsupported(data []byte, enc *Encoding, sub string) []byte {
 	if enc == nil || enc.Encoder == nil {
-		// UTF-8 can represent everything
-		return 0
+		return data
 	}
-
-	lossCount := 0
-	content := string(data)
 	encoder := enc.Encoder.NewEncoder()
-
-	for _, r := range content {
-		_, err := encoder.Bytes([]byte(string(r)))
-		if err != nil {
-			lossCount++
+	out := make([]byte, 0, len(data))
+	for _, r := range string(data) {
+		if _, err := encoder.Bytes([]byte(string(r))); err == nil {
+			out = utf8.AppendRune(out, r)
+		} else {
+			out = append(out, sub...)
 		}
 		encoder.Reset()
 	}
-
-	return lossCount
+	return out
 }
 
 // GetSupportedEncodings returns all supported encodings
@@ -95,6 +95,11 @@ func TestDetectBOM(t *testing.T) {
 		{"UTF-8 BOM", []byte{0xEF, 0xBB, 0xBF, 'h', 'i'}, "utf-8-bom", true},
 		{"UTF-16 LE BOM", []byte{0xFF, 0xFE, 0, 'h', 0, 'i'}, "utf-16-le", true},
 		{"UTF-16 BE BOM", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, "utf-16-be", true},
+		{"UTF-32 LE BOM", []byte{0xFF, 0xFE, 0, 0, 'h', 0, 0, 0}, "utf-32-le", true},
+		{"UTF-32 BE BOM", []byte{0, 0, 0xFE, 0xFF, 0, 0, 0, 'h'}, "utf-32-be", true},
+		{"UTF-16 LE no BOM", []byte{'h', 0, 'i', 0, 0xe9, 0, '!', 0}, "utf-16-le-nobom", false},
+		{"UTF-16 BE no BOM", []byte{0, 'h', 0, 'i', 0x4e, 0x16, 0, '!'}, "utf-16-be-nobom", false},
+		{"UTF-32 LE no BOM", []byte{'h', 0, 0, 0, 0x3c, 0xd8, 0x01, 0}, "utf-32-le-nobom", false},
 		{"No BOM ASCII", []byte("hello"), "utf-8", false},
 	}
 
@@ -186,7 +191,8 @@ func TestRoundTrip(t *testing.T) {
 	// Test that encoding then decoding returns the original text
 	originalText := "Hello, 世界! café résumé"
 
-	encodings := []string{"utf-8", "utf-8-bom", "utf-16-le", "utf-16-be"}
+	encodings := []string{"utf-8", "utf-8-bom", "utf-16-le", "utf-16-be", "utf-16-le-nobom", "utf-16-be-nobom",
+		"utf-32-le", "utf-32-be", "utf-32-le-nobom", "utf-32-be-nobom"}
 
 	for _, encID := range encodings {
 		t.Run(encID, func(t *testing.T) {
@@ -338,8 +344,8 @@ func TestDecodeTestFiles(t *testing.T) {
 
 func TestGetSupportedEncodings(t *testing.T) {
 	encodings := GetSupportedEncodings()
-	if len(encodings) != 12 {
-		t.Errorf("GetSupportedEncodings() returned %d encodings, want 12", len(encodings))
+	if len(encodings) != 18 {
+		t.Errorf("GetSupportedEncodings() returned %d encodings, want 18", len(encodings))
 	}
 
 	// Verify all are marked as supported
@@ -374,3 +380,94 @@ func TestIsValidUTF8(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeBOM(t *testing.T) {
+	tests := []struct {
+		encID string
+		want  []byte
+	}{
+		{"utf-16-le", []byte{0xFF, 0xFE, 'h', 0}},
+		{"utf-16-be-nobom", []byte{0, 'h'}},
+		{"utf-32-le", []byte{0xFF, 0xFE, 0, 0, 'h', 0, 0, 0}},
+		{"utf-32-be-nobom", []byte{0, 0, 0, 'h'}},
+	}
+	for _, tt := range tests {
+		enc := GetEncodingByID(tt.encID)
+		got, err := EncodeFromUTF8([]byte("h"), enc)
+		if err != nil || string(got) != string(tt.want) {
+			t.Errorf("EncodeFromUTF8(%s) = % x, %v; want % x", tt.encID, got, err, tt.want)
+		}
+		if lossy := EncodeFromUTF8Lossy([]byte("h"), enc); string(lossy) != string(tt.want) {
+			t.Errorf("EncodeFromUTF8Lossy(%s) = % x, want % x", tt.encID, lossy, tt.want)
+		}
+	}
+
+	// Reading a file with a BOM as the no-BOM variant strips it for the next save
+	decoded, _ := DecodeToUTF8([]byte{0xFF, 0xFE, 'h', 0}, GetEncodingByID("utf-16-le-nobom"))
+	if string(decoded) != "h" {
+		t.Errorf("decoded %q, want the BOM stripped", decoded)
+	}
+}
+
+func TestBOMVariant(t *testing.T) {
+	for _, enc := range GetSupportedEncodings() {
+		variant := BOMVariant(enc)
+		if enc.BOM == nil && enc.ID != "utf-8" {
+			if variant != nil {
+				t.Errorf("BOMVariant(%s) = %s, want nil", enc.ID, variant.ID)
+			}
+			continue
+		}
+		if variant == nil || variant.WriteBOM == enc.WriteBOM || BOMVariant(variant) != enc {
+			t.Errorf("BOMVariant(%s) should toggle the BOM and back", enc.ID)
+		}
+	}
+}
+
+func TestFindEncodingLoss(t *testing.T) {
+	latin1 := GetEncodingByID("iso-8859-1")
+	lost := FindEncodingLoss([]byte("café €\nok ☃"), latin1)
+	want := []LossyChar{{'€', 0, 5}, {'☃', 1, 3}}
+	if len(lost) != len(want) {
+		t.Fatalf("FindEncodingLoss = %v, want %v", lost, want)
+	}
+	for i := range want {
+		if lost[i] != want[i] {
+			t.Errorf("lost[%d] = %v, want %v", i, lost[i], want[i])
+		}
+	}
+	if n := CheckEncodingLoss([]byte("café"), latin1); n != 0 {
+		t.Errorf("CheckEncodingLoss(café) = %d, want 0", n)
+	}
+	if lost := FindEncodingLoss([]byte("€☃"), GetEncodingByID("utf-8")); lost != nil {
+		t.Errorf("UTF-8 should lose nothing, got %v", lost)
+	}
+}
+
+func TestDropUnsupported(t *testing.T) {
+	latin1 := GetEncodingByID("iso-8859-1")
+	if got := string(DropUnsupported([]byte("café €5\n☃!"), latin1)); got != "café 5\n!" {
+		t.Errorf("DropUnsupported = %q", got)
+	}
+}
+
+func TestDetectBinary(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		binary bool
+	}{
+		{"NUL bytes", []byte("ELF\x00\x01\x02 header"), true},
+		{"control characters", []byte("\x01\x02\x03\x04abcdef"), true},
+		{"text", []byte("plain text\twith tabs\r\n"), false},
+		{"terminal escapes", []byte("\x1b[31mred\x1b[0m\n"), false},
+		{"UTF-16 without BOM", []byte{'h', 0, 'i', 0, '!', 0}, false},
+		{"UTF-16 with BOM", []byte{0xFF, 0xFE, 'h', 0, 0, 0}, false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.data).Binary; got != tt.binary {
+			t.Errorf("%s: Binary = %v, want %v", tt.name, got, tt.binary)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package git reads the state of the git repository a file belongs to.
+package git
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Status describes the repository containing a file
+type Status struct {
+	Root   string // Working tree root, empty when the file is not in a repository
+	Branch string // Current branch, or the short commit hash when HEAD is detached
+	Dirty  bool   // Tracked files have uncommitted changes
+}
+
+// String formats the status for the status bar, e.g. "main*"
+func (s Status) String() string {
+	if s.Root == "" || s.Branch == "" {
+		return ""
+	}
+	if s.Dirty {
+		return s.Branch + "*"
+	}
+	return s.Branch
+}
+
+// StatusOf returns the status of the repository containing path. Missing
+// repositories and a missing git binary are not errors; the fields they
+// would fill are left empty. The branch is read from the git directory
+// without running git, and Dirty is only checked when runGit is set, since
+// git status can run commands the repository's own config names.
+func StatusOf(path string, runGit bool) Status {
+	root, gitDir := FindRoot(path)
+	if root == "" {
+		return Status{}
+	}
+	return Status{
+		Root:   root,
+		Branch: Branch(gitDir),
+		Dirty:  runGit && Dirty(root),
+	}
+}
+
+// FindRoot walks up from path looking for a .git directory or file. It
+// returns the working tree root and the git directory, or empty strings.
+func FindRoot(path string) (root, gitDir string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", ""
+	}
+	dir := abs
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			// Worktrees and submodules use a file pointing at the real git dir
+			if target := readGitFile(dotGit); target != "" {
+				return dir, target
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// readGitFile resolves a ".git" file of the form "gitdir: <path>"
+func readGitFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	line := strings.TrimSpace(string(data))
+	target, ok := strings.CutPrefix(line, "gitdir:")
+	if !ok {
+		return ""
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target
+}
+
+// Branch reads the current branch from the git directory's HEAD. A detached
+// HEAD yields the first seven characters of the commit hash.
+func Branch(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		return strings.TrimPrefix(strings.TrimSpace(ref), "refs/heads/")
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head
+}
+
+// Dirty reports whether tracked files in the working tree have uncommitted
+// changes. Untracked files are ignored, and so is a missing git binary. The
+// repository's fsmonitor hook is turned off, so at least that command it
+// names is never run.
+func Dirty(root string) bool {
+	cmd := exec.Command("git", "-c", "core.fsmonitor=false", "-C", root, "status", "--porcelain", "--untracked-files=no")
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return len(bytes.TrimSpace(out)) > 0
+}
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindRootAndBranch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/feature/x\n")
+	file := filepath.Join(dir, "src", "main.go")
+	writeFile(t, file, "package main\n")
+
+	root, gitDir := FindRoot(file)
+	if root != dir || gitDir != filepath.Join(dir, ".git") {
+		t.Fatalf("FindRoot = %q, %q; want %q", root, gitDir, dir)
+	}
+	if got := Branch(gitDir); got != "feature/x" {
+		t.Errorf("Branch = %q, want feature/x", got)
+	}
+
+	// Unsaved files in the repository still resolve through their folder
+	if root, _ := FindRoot(filepath.Join(dir, "src", "new.go")); root != dir {
+		t.Errorf("FindRoot of a new file = %q, want %q", root, dir)
+	}
+}
+
+func TestDetachedHead(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "HEAD"), "0123456789abcdef0123456789abcdef01234567\n")
+	if got := Branch(dir); got != "0123456" {
+		t.Errorf("Branch = %q, want 0123456", got)
+	}
+}
+
+func TestWorktreeGitFile(t *testing.T) {
+	dir := t.TempDir()
+	realDir := filepath.Join(dir, "main", ".git", "worktrees", "wt")
+	writeFile(t, filepath.Join(realDir, "HEAD"), "ref: refs/heads/wt\n")
+	writeFile(t, filepath.Join(dir, "wt", ".git"), "gitdir: ../main/.git/worktrees/wt\n")
+
+	root, gitDir := FindRoot(filepath.Join(dir, "wt", "file.txt"))
+	if root != filepath.Join(dir, "wt") {
+		t.Fatalf("FindRoot root = %q", root)
+	}
+	if got := Branch(gitDir); got != "wt" {
+		t.Errorf("Branch = %q, want wt", got)
+	}
+}
+
+func TestStatusOutsideRepository(t *testing.T) {
+	if s := StatusOf(filepath.Join(t.TempDir(), "file.txt"), true); s.String() != "" {
+		t.Errorf("status outside a repository = %q, want empty", s.String())
+	}
+}
+
+func TestStatusDirty(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q", "-b", "trunk")
+	file := filepath.Join(dir, "a.txt")
+	writeFile(t, file, "one\n")
+	run("add", "a.txt")
+	run("commit", "-q", "-m", "init")
+
+	if got := StatusOf(file, true).String(); got != "trunk" {
+		t.Errorf("clean status = %q, want trunk", got)
+	}
+	writeFile(t, file, "two\n")
+	if got := StatusOf(file, true).String(); got != "trunk*" {
+		t.Errorf("dirty status = %q, want trunk*", got)
+	}
+	if got := StatusOf(file, false).String(); got != "trunk" {
+		t.Errorf("status without running git = %q, want trunk", got)
+	}
+
+	// An fsmonitor hook the repository names is not run
+	if runtime.GOOS == "windows" {
+		return
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	hook := filepath.Join(dir, ".git", "fsmonitor.sh")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\ntouch "+marker+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	run("config", "core.fsmonitor", hook)
+	Dirty(dir)
+	if _, err := os.Stat(marker); err == nil {
+		t.Errorf("git status ran the repository's fsmonitor hook")
+	}
+}
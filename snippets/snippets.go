@@ -0,0 +1,195 @@
+// Package snippets expands user-defined snippets. Snippets live in one TOML
+// file in the config directory, a table per language:
+//
+//	[Go]
+//	iferr = "if err != nil {\n\treturn ${1:err}\n}"
+//
+//	["*"]
+//	todo = "TODO(${1:name}): $0"
+//
+// A body marks tab stops with $1, ${1} or ${1:placeholder}; $0 is where the
+// cursor ends up. A stop used more than once is mirrored, every copy taking
+// the first placeholder given for it. \$, \} and \\ write the character
+// itself.
+package snippets
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// AllLanguages is the table whose snippets apply to every file
+const AllLanguages = "*"
+
+// Set holds the snippets of each language, keyed by language then prefix
+type Set struct {
+	languages map[string]map[string]string
+}
+
+// Load reads the snippets at path. A missing file gives an empty set.
+func Load(path string) (*Set, error) {
+	s := &Set{languages: make(map[string]map[string]string)}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+	var raw map[string]map[string]string
+	if _, err := toml.DecodeFile(path, &raw); err != nil {
+		return s, err
+	}
+	for lang, prefixes := range raw {
+		s.languages[strings.ToLower(lang)] = prefixes
+	}
+	return s, nil
+}
+
+// Lookup returns the body of the snippet for prefix in language, falling back
+// to the snippets for all languages. Language names ignore case and are the
+// names the syntax highlighter uses, such as "Go" or "Python".
+func (s *Set) Lookup(language, prefix string) (string, bool) {
+	if body, ok := s.languages[strings.ToLower(language)][prefix]; ok {
+		return body, true
+	}
+	body, ok := s.languages[AllLanguages][prefix]
+	return body, ok
+}
+
+// Stop is one tab stop in expanded text, as byte offsets [Start, End)
+type Stop struct {
+	Index      int
+	Start, End int
+}
+
+// token is a piece of a parsed body: literal text, or a tab stop with the
+// placeholder written at it (if any)
+type token struct {
+	text        string
+	stop        int // -1 for literal text
+	placeholder bool
+}
+
+// Expand turns a snippet body into its text and tab stops. Stops are ordered
+// by index, $0 last, and by position among copies of the same stop.
+func Expand(body string) (string, []Stop) {
+	tokens := parse(body)
+	defaults := make(map[int]string)
+	for _, t := range tokens {
+		if _, seen := defaults[t.stop]; t.stop >= 0 && t.placeholder && !seen {
+			defaults[t.stop] = t.text
+		}
+	}
+	var sb strings.Builder
+	var stops []Stop
+	for _, t := range tokens {
+		if t.stop < 0 {
+			sb.WriteString(t.text)
+			continue
+		}
+		start := sb.Len()
+		sb.WriteString(defaults[t.stop])
+		stops = append(stops, Stop{Index: t.stop, Start: start, End: sb.Len()})
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return order(stops[i].Index) < order(stops[j].Index)
+	})
+	return sb.String(), stops
+}
+
+// order sorts $0 after every other stop
+func order(index int) int {
+	if index == 0 {
+		return int(^uint(0) >> 1)
+	}
+	return index
+}
+
+// parse splits a body into literal text and tab stops
+func parse(body string) []token {
+	var tokens []token
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			tokens = append(tokens, token{text: lit.String(), stop: -1})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c == '\\' && i+1 < len(body) && strings.IndexByte(`$}\`, body[i+1]) >= 0 {
+			lit.WriteByte(body[i+1])
+			i++
+			continue
+		}
+		if c != '$' {
+			lit.WriteByte(c)
+			continue
+		}
+		if t, n, ok := parseStop(body[i+1:]); ok {
+			flush()
+			tokens = append(tokens, t)
+			i += n
+			continue
+		}
+		lit.WriteByte(c)
+	}
+	flush()
+	return tokens
+}
+
+// parseStop reads the tab stop after a '$': N, {N} or {N:placeholder}.
+// It returns the stop and how many bytes it took.
+func parseStop(s string) (token, int, bool) {
+	if n := digits(s); n > 0 {
+		return token{stop: atoi(s[:n])}, n, true
+	}
+	if !strings.HasPrefix(s, "{") {
+		return token{}, 0, false
+	}
+	n := digits(s[1:])
+	if n == 0 {
+		return token{}, 0, false
+	}
+	t := token{stop: atoi(s[1 : 1+n])}
+	i := 1 + n
+	if i < len(s) && s[i] == '}' {
+		return t, i + 1, true
+	}
+	if i >= len(s) || s[i] != ':' {
+		return token{}, 0, false
+	}
+	var text strings.Builder
+	for i++; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`$}\`, s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i++
+		case s[i] == '}':
+			t.text = text.String()
+			t.placeholder = true
+			return t, i + 1, true
+		default:
+			text.WriteByte(s[i])
+		}
+	}
+	return token{}, 0, false // Unclosed placeholder: keep it as text
+}
+
+// digits returns the length of the run of ASCII digits at the start of s
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// atoi converts a run of ASCII digits
+func atoi(s string) int {
+	n := 0
+	for _, c := range []byte(s) {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
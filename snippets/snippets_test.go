@@ -0,0 +1,52 @@
+package snippets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		body  string
+		text  string
+		stops []Stop
+	}{
+		{"plain", "plain", nil},
+		{"for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}", "for i := 0; i < n; i++ {\n\t\n}",
+			[]Stop{{1, 4, 5}, {1, 12, 13}, {1, 19, 20}, {2, 16, 17}, {0, 26, 26}}},
+		{"$2 then ${1}", " then ", []Stop{{1, 6, 6}, {2, 0, 0}}},
+		{`cost \$5 ${1:a\}b} $x ${2`, "cost $5 a}b $x ${2", []Stop{{1, 8, 11}}},
+	}
+	for _, tt := range tests {
+		text, stops := Expand(tt.body)
+		if text != tt.text || !reflect.DeepEqual(stops, tt.stops) {
+			t.Errorf("Expand(%q) = %q %v, want %q %v", tt.body, text, stops, tt.text, tt.stops)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippets.toml")
+	if s, err := Load(path); err != nil || s == nil {
+		t.Fatalf("a missing file should give an empty set, got %v", err)
+	}
+	data := "[go]\nfn = \"func $1() {}\"\n\n[\"*\"]\nfn = \"any\"\ntodo = \"TODO: $0\"\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, ok := s.Lookup("Go", "fn"); !ok || body != "func $1() {}" {
+		t.Errorf("Go fn = %q, %v", body, ok)
+	}
+	if body, ok := s.Lookup("Python", "fn"); !ok || body != "any" {
+		t.Errorf("other languages should fall back to \"*\", got %q", body)
+	}
+	if _, ok := s.Lookup("Go", "nope"); ok {
+		t.Error("unknown prefix found")
+	}
+}
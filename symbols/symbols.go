@@ -0,0 +1,151 @@
+// Package symbols finds the definitions in a source file - functions, types,
+// classes, headings - with a few patterns per language, the way ctags does
+// without a parser. It is best effort: definitions written unusually are
+// missed, and commented-out ones are found.
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Symbol is a definition found in a file
+type Symbol struct {
+	Name string
+	Kind string // "func", "method", "type", "class", "module", "macro" or "heading"
+	Line int    // 0-indexed
+	Col  int    // Byte offset of the name in its line
+}
+
+// pattern finds one kind of definition. The name is the first submatch.
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// p compiles the pattern for a kind of definition
+func p(kind, expr string) pattern {
+	return pattern{kind, regexp.MustCompile(expr)}
+}
+
+// Patterns shared by the C family
+var (
+	cFunction = p("func", `^(?:[A-Za-z_][\w:<>,\*&]*[\s\*&]+)+\**([A-Za-z_]\w*(?:::~?\w+)*)\s*\([^;]*$`)
+	cType     = p("type", `^\s*(?:typedef\s+)?(?:struct|union|enum(?:\s+class)?)\s+(\w+)[^;]*$`)
+	cMacro    = p("macro", `^\s*#\s*define\s+(\w+)`)
+	jsFunc    = p("func", `^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*(\w+)`)
+	jsClass   = p("class", `^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`)
+	jsArrow   = p("func", `^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*(?::[^=]+)?=>|\w+\s*=>)`)
+	tsType    = p("type", `^\s*(?:export\s+)?(?:declare\s+)?(?:interface|type|enum)\s+(\w+)`)
+)
+
+// languages holds the patterns for each language, by the name the syntax
+// highlighter gives it
+var languages = map[string][]pattern{
+	"Go": {
+		p("method", `^func\s+\([^)]*\)\s*(\w+)`),
+		p("func", `^func\s+(\w+)`),
+		p("type", `^type\s+(\w+)`),
+		p("type", `^\t(\w+)\s+(?:struct|interface)\b`), // In a type ( ... ) block
+	},
+	"Python": {
+		p("func", `^\s*(?:async\s+)?def\s+(\w+)`),
+		p("class", `^\s*class\s+(\w+)`),
+	},
+	"JavaScript": {jsFunc, jsClass, jsArrow},
+	"TypeScript": {jsFunc, jsClass, jsArrow, tsType},
+	"Rust": {
+		p("func", `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(\w+)`),
+		p("type", `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:struct|enum|union|trait|type)\s+(\w+)`),
+		p("module", `^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(\w+)`),
+		p("macro", `^\s*macro_rules!\s*(\w+)`),
+	},
+	"C":   {cType, cMacro, cFunction},
+	"C++": {p("class", `^\s*(?:template\s*<[^>]*>\s*)?class\s+(\w+)[^;]*$`), cType, cMacro, cFunction},
+	"Java": {
+		p("class", `^\s*(?:(?:public|protected|private|static|final|abstract|sealed)\s+)*(?:class|interface|enum|record)\s+(\w+)`),
+		p("method", `^\s+(?:(?:public|protected|private|static|final|abstract|synchronized|native|default)\s+)+[\w<>\[\],\s\.?]*?\s(\w+)\s*\(`),
+	},
+	"C#": {
+		p("class", `^\s*(?:(?:public|protected|private|internal|static|abstract|sealed|partial)\s+)*(?:class|interface|enum|struct|record)\s+(\w+)`),
+		p("method", `^\s+(?:(?:public|protected|private|internal|static|virtual|override|abstract|async|sealed)\s+)+[\w<>\[\],\s\.?]*?\s(\w+)\s*\(`),
+	},
+	"Ruby": {
+		p("method", `^\s*def\s+(?:self\.)?([\w?!=]+)`),
+		p("class", `^\s*class\s+([\w:]+)`),
+		p("module", `^\s*module\s+([\w:]+)`),
+	},
+	"PHP": {
+		p("func", `^\s*(?:(?:public|protected|private|static|abstract|final)\s+)*function\s+&?(\w+)`),
+		p("class", `^\s*(?:(?:abstract|final)\s+)?(?:class|interface|trait|enum)\s+(\w+)`),
+	},
+	"Lua": {
+		p("func", `^\s*(?:local\s+)?function\s+([\w.:]+)`),
+	},
+	"Bash": {
+		p("func", `^\s*function\s+([\w-]+)`),
+		p("func", `^\s*([\w-]+)\s*\(\)`),
+	},
+	"markdown": {
+		p("heading", `^#{1,6}\s+(.*?)\s*#*\s*$`),
+	},
+}
+
+// aliases maps other highlighter names onto a language above
+var aliases = map[string]string{
+	"Python 2": "Python",
+	"react":    "JavaScript", // .jsx
+}
+
+// notNames are keywords a loose pattern can mistake for a function name
+var notNames = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true,
+	"catch": true, "sizeof": true, "else": true,
+}
+
+// Supported reports whether definitions can be found for the language
+func Supported(language string) bool {
+	_, ok := languages[canonical(language)]
+	return ok
+}
+
+// canonical returns the key in languages for a highlighter's name
+func canonical(language string) string {
+	if alias, ok := aliases[language]; ok {
+		return alias
+	}
+	return language
+}
+
+// Find returns the definitions in text, in line order. language is the name
+// the syntax highlighter gives the file's language; others have none.
+func Find(language, text string) []Symbol {
+	language = canonical(language)
+	patterns := languages[language]
+	if len(patterns) == 0 {
+		return nil
+	}
+	var symbols []Symbol
+	inFence := false
+	for i, line := range strings.Split(text, "\n") {
+		if language == "markdown" && strings.HasPrefix(line, "```") {
+			inFence = !inFence // Code blocks have no headings
+		}
+		if inFence {
+			continue
+		}
+		for _, pat := range patterns {
+			m := pat.re.FindStringSubmatchIndex(line)
+			if m == nil || m[2] < 0 {
+				continue
+			}
+			name := line[m[2]:m[3]]
+			if name == "" || notNames[name] {
+				continue
+			}
+			symbols = append(symbols, Symbol{Name: name, Kind: pat.kind, Line: i, Col: m[2]})
+			break
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,71 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+)
+
+func names(symbols []Symbol) []string {
+	var out []string
+	for _, s := range symbols {
+		out = append(out, s.Kind+" "+s.Name)
+	}
+	return out
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		language string
+		text     string
+		want     []string
+	}{
+		{"Go", "package x\n\ntype Buffer struct {\n\tdata []byte\n}\n\nfunc New() *Buffer {\n\treturn nil\n}\n\nfunc (b *Buffer) Len() int { return 0 }\n\ntype (\n\tA struct{}\n\tB interface{}\n)",
+			[]string{"type Buffer", "func New", "method Len", "type A", "type B"}},
+		{"Python", "class Shape:\n    def area(self):\n        pass\n\nasync def main():\n    if x:\n        pass",
+			[]string{"class Shape", "func area", "func main"}},
+		{"JavaScript", "export function render() {}\nclass View extends Base {}\nconst add = (a, b) => a + b\nconst n = 3\nexport default async function* gen() {}",
+			[]string{"func render", "class View", "func add", "func gen"}},
+		{"TypeScript", "export interface Props {}\ntype ID = string\nexport const f = async (x: number): Promise<void> => {}",
+			[]string{"type Props", "type ID", "func f"}},
+		{"Rust", "pub struct Point { x: i32 }\nimpl Point {\n    pub fn new() -> Self {}\n}\nmod tests {}\nmacro_rules! m { }",
+			[]string{"type Point", "func new", "module tests", "macro m"}},
+		{"C", "#define MAX 10\nstruct node {\n};\nstruct node;\nstatic int *find(struct node *n)\n{\n\tif (n) return 0;\n\tfoo(n);\n}\nint count(int n);",
+			[]string{"macro MAX", "type node", "func find"}},
+		{"C++", "class Widget {\n};\nvoid Widget::draw() const {\n}",
+			[]string{"class Widget", "func Widget::draw"}},
+		{"Java", "public class App {\n    public static void main(String[] args) {\n        return;\n    }\n}",
+			[]string{"class App", "method main"}},
+		{"Ruby", "module Util\n  class Parser\n    def self.parse(s)\n    end\n    def valid?\n    end\n  end\nend",
+			[]string{"module Util", "class Parser", "method parse", "method valid?"}},
+		{"Bash", "function build {\n}\nclean() {\n}",
+			[]string{"func build", "func clean"}},
+		{"markdown", "# Title\n\nText\n\n```sh\n# not a heading\n```\n## Usage ##",
+			[]string{"heading Title", "heading Usage"}},
+		{"react", "function App() {}", []string{"func App"}},
+		{"plaintext", "func main() {}", nil},
+	}
+	for _, tt := range tests {
+		if got := names(Find(tt.language, tt.text)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestFindPositions(t *testing.T) {
+	got := Find("Go", "package x\n\nfunc (r *T) Run() {}")
+	want := []Symbol{{Name: "Run", Kind: "method", Line: 2, Col: 12}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for _, lang := range []string{"Go", "Python 2", "react", "markdown"} {
+		if !Supported(lang) {
+			t.Errorf("Supported(%q) = false", lang)
+		}
+	}
+	if Supported("plaintext") || Supported("") {
+		t.Error("unsupported languages reported as supported")
+	}
+}
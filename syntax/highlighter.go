@@ -43,11 +43,29 @@ type ColorSpan struct {
 	Color string // ANSI color code
 }
 
+// maxDocumentLexSize is the largest document lexed as a whole by a lexer
+// that can't resume mid-document; larger documents fall back to lexing each
+// visible line on its own
+const maxDocumentLexSize = 1 << 20
+
 // Highlighter provides syntax highlighting for source code
 type Highlighter struct {
-	lexer   chroma.Lexer
-	enabled bool
-	colors  SyntaxColors
+	lexer    chroma.Lexer
+	resumer  *resumer // Copy of lexer that can start mid-document, if it's a regex lexer
+	filename string   // File the lexer is matched against
+	resolved bool     // Whether lexer has been matched for filename
+	enabled  bool
+	colors   SyntaxColors
+
+	// Document cache, so lexer state carries across lines. Lines are lexed
+	// as far as they've been asked for, and an edit re-lexes from the
+	// changed line until the lexer state matches what it was before.
+	docVersion uint64        // Version of the text docLines holds
+	docLines   []string      // Line text the spans were computed for
+	docSpans   [][]ColorSpan // Spans for each line lexed so far
+	docEnds    []lineEnd     // Lexer state at the end of each line lexed so far
+	run        *lexRun       // Lexing in progress, continued when more lines are needed
+	resync     *resync       // Results from before an edit, reused once the state matches
 }
 
 // New creates a new Highlighter for the given filename
@@ -60,16 +78,27 @@ func New(filename string) *Highlighter {
 	return h
 }
 
-// SetFile updates the lexer based on the filename
+// SetFile updates the lexer based on the filename.
+// Matching is deferred until highlighting is first needed, so buffers
+// that are never shown don't pay for the lexer lookup.
 func (h *Highlighter) SetFile(filename string) {
-	if filename == "" {
-		h.lexer = nil
-		return
-	}
-	h.lexer = lexers.Match(filename)
-	if h.lexer != nil {
-		h.lexer = chroma.Coalesce(h.lexer)
+	h.invalidate()
+	h.filename = filename
+	h.lexer = nil
+	h.resumer = nil
+	h.resolved = filename == ""
+}
+
+// resolveLexer matches the lexer for the current file on first use
+func (h *Highlighter) resolveLexer() chroma.Lexer {
+	if !h.resolved {
+		h.resolved = true
+		if lexer := lexers.Match(h.filename); lexer != nil {
+			h.lexer = chroma.Coalesce(lexer)
+			h.resumer = resumerFor(lexer)
+		}
 	}
+	return h.lexer
 }
 
 // SetEnabled enables or disables syntax highlighting
@@ -84,18 +113,36 @@ func (h *Highlighter) Enabled() bool {
 
 // HasLexer returns true if a lexer is available for the current file
 func (h *Highlighter) HasLexer() bool {
-	return h.lexer != nil
+	return h.resolveLexer() != nil
+}
+
+// Language returns the name of the detected language (e.g. "HTML"), or "" if none
+func (h *Highlighter) Language() string {
+	if lexer := h.resolveLexer(); lexer != nil {
+		return lexer.Config().Name
+	}
+	return ""
 }
 
 // SetColors sets the syntax highlighting colors
 func (h *Highlighter) SetColors(colors SyntaxColors) {
 	h.colors = colors
+	h.invalidate()
+}
+
+// invalidate drops the document cache
+func (h *Highlighter) invalidate() {
+	h.docLines = nil
+	h.docSpans = nil
+	h.docEnds = nil
+	h.run = nil
+	h.resync = nil
 }
 
 // GetLineColors returns color spans for a line
 // Returns nil if highlighting is disabled or no lexer is available
 func (h *Highlighter) GetLineColors(line string) []ColorSpan {
-	if !h.enabled || h.lexer == nil {
+	if !h.enabled || h.resolveLexer() == nil {
 		return nil
 	}
 
@@ -122,6 +169,147 @@ func (h *Highlighter) GetLineColors(line string) []ColorSpan {
 	return spans
 }
 
+// DocumentColors returns color spans for lines[start:end] of a document
+// whose text is identified by version (a new version for every change).
+// Lexer state carries across lines, so block comments and multi-line
+// strings keep their colors past their first line. Lines are lexed only as
+// far as end, and after an edit only from near the first changed line until
+// the lexer is back in the state it was in before the edit. Lexers that
+// can't resume mid-document lex it whole, up to maxDocumentLexSize.
+// Returns nil if highlighting is disabled or no lexer is available.
+func (h *Highlighter) DocumentColors(lines []string, version uint64, start, end int) [][]ColorSpan {
+	if !h.enabled || h.resolveLexer() == nil {
+		return nil
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	if start >= end {
+		return nil
+	}
+
+	if h.resumer == nil {
+		size := 0
+		for _, line := range lines {
+			size += len(line) + 1
+		}
+		if size > maxDocumentLexSize {
+			h.invalidate()
+			spans := make([][]ColorSpan, end-start)
+			for i := start; i < end; i++ {
+				spans[i-start] = h.GetLineColors(lines[i])
+			}
+			return spans
+		}
+	}
+
+	if h.docLines == nil || version != h.docVersion {
+		if h.resumer == nil {
+			// Lexers that aren't regex-based can't resume mid-document
+			h.docSpans = h.lexDocument(lines)
+		} else {
+			h.linesChanged(lines)
+		}
+		h.docLines = append(h.docLines[:0], lines...)
+		h.docVersion = version
+	}
+	if h.resumer != nil {
+		h.lexTo(end)
+	}
+	return h.docSpans[start:end]
+}
+
+// lexDocument tokenises the whole document and splits the tokens back into
+// per-line spans, so tokens that span lines color each line they touch
+func (h *Highlighter) lexDocument(lines []string) [][]ColorSpan {
+	spans := make([][]ColorSpan, len(lines))
+	iterator, err := h.lexer.Tokenise(nil, strings.Join(lines, "\n"))
+	if err != nil {
+		return spans
+	}
+
+	line, col := 0, 0
+	for _, token := range iterator.Tokens() {
+		color := h.tokenColor(token.Type)
+		for i, part := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				line++
+				col = 0
+			}
+			n := utf8.RuneCountInString(part)
+			if color != "" && n > 0 && line < len(spans) {
+				spans[line] = appendSpan(spans[line], ColorSpan{Start: col, End: col + n, Color: color})
+			}
+			col += n
+		}
+	}
+	return spans
+}
+
+// TokenClass is the broad kind of text a token belongs to, for searches
+// limited to code, comments or strings
+type TokenClass int
+
+const (
+	ClassCode    TokenClass = iota // Everything that isn't a comment or string
+	ClassComment                   // Comments (preprocessor lines count as code)
+	ClassString                    // String and character literals
+)
+
+// Region is a run of comment or string text, as byte offsets into the
+// document passed to Regions
+type Region struct {
+	Start int
+	End   int // Exclusive
+	Class TokenClass
+}
+
+// Regions lexes text and returns its comment and string regions in order;
+// all other text is code. Works whether or not highlighting is enabled.
+// Returns nil if no lexer is available for the file.
+func (h *Highlighter) Regions(text string) []Region {
+	lexer := h.resolveLexer()
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := lexer.Tokenise(nil, text)
+	if err != nil {
+		return nil
+	}
+	var regions []Region
+	offset := 0
+	for _, token := range iterator.Tokens() {
+		start := offset
+		offset += len(token.Value)
+		class := tokenClass(token.Type)
+		if class == ClassCode {
+			continue
+		}
+		if n := len(regions); n > 0 && regions[n-1].End == start && regions[n-1].Class == class {
+			regions[n-1].End = offset
+			continue
+		}
+		regions = append(regions, Region{Start: start, End: offset, Class: class})
+	}
+	return regions
+}
+
+// tokenClass maps a chroma token type to its TokenClass
+func tokenClass(t chroma.TokenType) TokenClass {
+	switch {
+	case t.InSubCategory(chroma.CommentPreproc):
+		return ClassCode
+	case t.InCategory(chroma.Comment):
+		return ClassComment
+	case t.InSubCategory(chroma.LiteralString):
+		return ClassString
+	}
+	return ClassCode
+}
+
 // ColorAt returns the color for a specific column position
 // Returns empty string if no color applies
 func ColorAt(spans []ColorSpan, col int) string {
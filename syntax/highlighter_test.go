@@ -0,0 +1,261 @@
+package syntax
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDocumentColorsBlockComment(t *testing.T) {
+	h := New("main.c")
+	lines := []string{
+		"int x; /* start",
+		"still a comment",
+		"end */ int y;",
+	}
+	comment := colorToANSI(h.colors.Comment)
+
+	spans := h.DocumentColors(lines, 1, 0, len(lines))
+	if len(spans) != 3 {
+		t.Fatalf("got spans for %d lines, want 3", len(spans))
+	}
+	if got := ColorAt(spans[1], 3); got != comment {
+		t.Errorf("middle line of a block comment colored %q, want comment color", got)
+	}
+	if got := ColorAt(spans[2], 0); got != comment {
+		t.Errorf("closing line of a block comment colored %q, want comment color", got)
+	}
+	if got := ColorAt(spans[2], 8); got == comment {
+		t.Errorf("code after the comment should not use the comment color")
+	}
+}
+
+func TestDocumentColorsInvalidatesOnEdit(t *testing.T) {
+	h := New("script.py")
+	lines := []string{`s = """first`, `second`, `"""`, `x = 1`}
+	str := colorToANSI(h.colors.String)
+
+	if got := ColorAt(h.DocumentColors(lines, 1, 0, 4)[1], 0); got != str {
+		t.Errorf("inside a triple-quoted string colored %q, want string color", got)
+	}
+
+	// Removing the opening quotes turns the following lines back into code
+	edited := []string{`s = 1`, `second`, `"""`, `x = 1`}
+	if got := ColorAt(h.DocumentColors(edited, 2, 0, 4)[1], 0); got == str {
+		t.Errorf("downstream line kept its stale string color after the edit")
+	}
+}
+
+func TestDocumentColorsMatchesWholeLex(t *testing.T) {
+	h := New("main.c")
+	lines := []string{"int a; /* one", "two */ int b;", `char *s = "x";`, "", "int c;"}
+	edits := []func([]string) []string{
+		func(l []string) []string { l[0] = "int a;"; return l },       // Comment no longer opens
+		func(l []string) []string { l[3] = "/* open"; return l },      // Comment to the end
+		func(l []string) []string { return slices.Delete(l, 1, 3) },   // Lines removed
+		func(l []string) []string { return slices.Insert(l, 0, "x") }, // Lines added
+		func(l []string) []string { l[1] = "int a; /* one"; return l },
+	}
+	whole := New("main.c")
+	whole.HasLexer()
+	for i, edit := range edits {
+		h.DocumentColors(lines, uint64(i+1), 0, len(lines))
+		lines = edit(slices.Clone(lines))
+		got := h.DocumentColors(lines, uint64(i+100), 0, len(lines))
+		want := whole.lexDocument(lines)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("after edit %d spans = %v, want %v", i, got, want)
+		}
+	}
+}
+
+// Random edits, each followed by a look at all or part of the document,
+// should color lines as a whole lex of the edited text does
+func TestDocumentColorsRandomEdits(t *testing.T) {
+	docs := []struct{ name, text string }{
+		{"a.rb", "# comment\nclass Foo < Bar\n  def initialize(x)\n    @x = \"str #{x}\"\n    z = %w[a b c]\n  end\n  def bar\n    <<~EOS\n      doc #{1 + 2}\n    EOS\n  end\nend\n=begin\nblock\n=end\nputs :sym, /re+gex/i\n"},
+		{"main.c", "int x; /* one\ntwo */\nchar *s = \"a\\\nb\";\n#define F(a) \\\n  (a)\n// line\nint y;\n"},
+		{"script.py", "\"\"\"doc\nstring\"\"\"\ndef f(x, y='s'):\n    # comment\n    s = f\"{x!r} {y}\"\n    return r'\\d+'\n"},
+	}
+	inserts := []string{"\n", " ", "x", "/", "*", "#", "'", "\"", "`", "(", ")", "[", "]", "{", "}", "%w?", "?", "=begin\n", "=end\n", "<<EOS\n", "EOS\n", "/*", "*/", "\"\"\"", "\\", "when\n", ",\n"}
+	for _, doc := range docs {
+		rng := rand.New(rand.NewSource(1))
+		for range 20 {
+			h := New(doc.name)
+			whole := New(doc.name)
+			whole.HasLexer()
+			text := doc.text
+			h.DocumentColors(strings.Split(text, "\n"), 0, 0, strings.Count(text, "\n")+1)
+			for version := uint64(1); version <= 30; version++ {
+				at := rng.Intn(len(text) + 1)
+				if rng.Intn(3) == 0 {
+					text = text[:at] + text[min(at+rng.Intn(8), len(text)):]
+				} else {
+					text = text[:at] + inserts[rng.Intn(len(inserts))] + text[at:]
+				}
+				lines := strings.Split(text, "\n")
+				start, end := 0, len(lines)
+				if rng.Intn(2) == 0 {
+					start = rng.Intn(len(lines))
+					end = min(start+5, len(lines))
+				}
+				got := h.DocumentColors(lines, version, start, end)
+				want := whole.lexDocument(lines)[start:end]
+				if fmt.Sprint(got) != fmt.Sprint(want) {
+					t.Fatalf("%s lines %d-%d of %q colored %v, want %v", doc.name, start, end, text, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDocumentColorsUnevenTokens(t *testing.T) {
+	// Ruby's rule for a %-string with any delimiter emits the delimiter
+	// twice, so its tokens are longer than the text they came from
+	lines := []string{"y = % a ", "# note", "/b", "c/"}
+	for range 12 {
+		lines = append(lines, "x = 1")
+	}
+	h := New("a.rb")
+	whole := New("a.rb")
+	whole.HasLexer()
+	h.DocumentColors(lines, 1, 0, len(lines))
+	lines[len(lines)-1] = "x = 2"
+	got := h.DocumentColors(lines, 2, 0, len(lines))
+	if want := whole.lexDocument(lines); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("spans = %v, want %v", got, want)
+	}
+}
+
+func TestDocumentColorsStopsRelexing(t *testing.T) {
+	h := New("main.c")
+	lines := make([]string, 1000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("int x%d = %d;", i, i)
+	}
+
+	// Lines are lexed only as far as they're needed
+	h.DocumentColors(lines, 1, 0, 20)
+	if len(h.docSpans) >= len(lines) {
+		t.Errorf("lexed %d lines to show 20", len(h.docSpans))
+	}
+	before := h.DocumentColors(lines, 1, 0, len(lines))
+
+	// A change that leaves the lexer state alone re-lexes only the lines near it
+	lines[10] = "int y = 1;"
+	after := h.DocumentColors(lines, 2, 0, len(lines))
+	if &after[2][0] != &before[2][0] || &after[500][0] != &before[500][0] {
+		t.Errorf("lines away from the edit should keep their cached spans")
+	}
+	if ColorAt(after[10], 0) != colorToANSI(h.colors.Keyword) {
+		t.Errorf("edited line not re-lexed")
+	}
+
+	// Opening a comment re-lexes the lines it now covers
+	lines[10] = "/* int y = 1;"
+	comment := colorToANSI(h.colors.Comment)
+	if got := h.DocumentColors(lines, 3, 0, len(lines)); ColorAt(got[999], 0) != comment {
+		t.Errorf("lines after an opened comment colored %q, want comment color", ColorAt(got[999], 0))
+	}
+}
+
+func TestDocumentColorsClosingBelowEdit(t *testing.T) {
+	// Go matches a block comment with one pattern, so "/*" alone isn't a
+	// comment until a "*/" is typed below it
+	h := New("main.go")
+	lines := []string{"x := 1 /* note", "y := 2", "z := 3"}
+	comment := colorToANSI(h.colors.Comment)
+	if got := h.DocumentColors(lines, 1, 0, 3); ColorAt(got[1], 0) == comment {
+		t.Fatalf("unclosed comment colored the next line")
+	}
+	lines[2] = "*/ z := 3"
+	if got := h.DocumentColors(lines, 2, 0, 3); ColorAt(got[1], 0) != comment {
+		t.Errorf("closing a comment below should recolor the lines above the edit")
+	}
+}
+
+func TestOpener(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string // Text the opener matches, or "" for no opener
+	}{
+		{`/(\\\n)?[*](.|\n)*?[*](\\\n)?/`, "/*"},
+		{"(`)([^`]*)(`)", "`"},
+		{`([a-zA-Z_]\w*)(\s*)(\()`, ""}, // Only whitespace can cross lines
+		{`//[^\n]*`, ""},
+		{`(?s)%[qsw]([\W_])((?:\\\1|(?!\1).)*)\1`, "%w?"}, // Backreferences and lookarounds
+	}
+	for _, tt := range tests {
+		open := opener(tt.pattern)
+		if (open == nil) != (tt.want == "") || open != nil && !open.MatchString(tt.want) {
+			t.Errorf("opener(%q) = %v, want one matching %q", tt.pattern, open, tt.want)
+		}
+	}
+}
+
+func TestDocumentColorsSizeCap(t *testing.T) {
+	// Emacs Lisp's lexer can't resume mid-document, so a large document is
+	// colored a line at a time rather than lexed whole on every edit
+	h := New("init.el")
+	lines := make([]string, maxDocumentLexSize/10)
+	for i := range lines {
+		lines[i] = "(setq x 1)"
+	}
+	spans := h.DocumentColors(lines, 1, 0, 2)
+	if len(spans) != 2 || spans[0] == nil || h.docSpans != nil {
+		t.Errorf("spans = %v with %d cached, want the visible lines lexed alone", spans, len(h.docSpans))
+	}
+}
+
+func TestDocumentColorsRange(t *testing.T) {
+	h := New("main.go")
+	lines := []string{"package main", "", "func main() {}"}
+	spans := h.DocumentColors(lines, 1, 2, 3)
+	if len(spans) != 1 || ColorAt(spans[0], 0) != colorToANSI(h.colors.Keyword) {
+		t.Errorf("DocumentColors(2, 3) = %v, want keyword span for func", spans)
+	}
+
+	h.SetEnabled(false)
+	if spans := h.DocumentColors(lines, 1, 0, 3); spans != nil {
+		t.Errorf("disabled highlighter returned %v", spans)
+	}
+}
+
+func TestSetFileDefersLexerLookup(t *testing.T) {
+	h := New("main.go")
+	if h.resolved {
+		t.Fatalf("lexer should not be matched until it is needed")
+	}
+	if !h.HasLexer() || !h.resolved {
+		t.Errorf("HasLexer should match the Go lexer on first use")
+	}
+
+	h.SetFile("")
+	if h.HasLexer() {
+		t.Errorf("no file should mean no lexer")
+	}
+}
+
+func TestRegions(t *testing.T) {
+	h := New("main.go")
+	h.SetEnabled(false) // Scope filters work without highlighting
+	text := "x := \"total\" // total count\ntotal++\n"
+
+	var got []string
+	for _, r := range h.Regions(text) {
+		got = append(got, text[r.Start:r.End])
+		if r.Class == ClassCode {
+			t.Errorf("region %q classed as code", text[r.Start:r.End])
+		}
+	}
+	want := []string{`"total"`, "// total count"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Regions text = %q, want %q", got, want)
+	}
+
+	if New("notes.txt").Regions(text) != nil {
+		t.Errorf("Regions without a lexer should be nil")
+	}
+}
@@ -0,0 +1,353 @@
+package syntax
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// resumeState is the state a resumer's lexer starts in; its one rule puts
+// back the stack the lexer had at the start of the line being resumed from
+const resumeState = "textivus-resume"
+
+// resumeContext is how many bytes of the text before a resumed line the
+// lexer is given, so lookbehinds see what they would in a whole lex. It's
+// also how far back from an edit lexing starts again, since whether a
+// match stopped at the end of a line can depend on the text after it.
+const resumeContext = 64
+
+// lineEnd is the lexer state at the end of a line. A line that ends inside
+// a single match, such as a token running on past its newline, has no
+// state to resume from.
+type lineEnd struct {
+	stack []string
+	ok    bool
+	// The line opens something that isn't closed yet: it has an error
+	// token, or a pattern failed after its opener (see failedOpener), so
+	// text added further down can change how the line lexes
+	unclosed bool
+}
+
+// resumer is a copy of a regex lexer that can start lexing with any state
+// stack, so lexing can pick up at a line rather than the top of a file
+type resumer struct {
+	mu      sync.Mutex
+	lexer   *chroma.RegexLexer
+	stack   []string                    // Stack the next run starts with
+	skip    int                         // Runes of context the next run starts after
+	state   *chroma.LexerState          // State of the run being started
+	openers map[string][]*regexp.Regexp // Openers of each state's rules (see openersFor)
+}
+
+var (
+	resumersMu sync.Mutex
+	resumers   = map[*chroma.RegexLexer]*resumer{}
+)
+
+// resumerFor returns the resumer for lexer, shared by every file that uses
+// it, or nil if lexer isn't a regex lexer
+func resumerFor(lexer chroma.Lexer) *resumer {
+	regex, ok := lexer.(*chroma.RegexLexer)
+	if !ok {
+		return nil
+	}
+	resumersMu.Lock()
+	defer resumersMu.Unlock()
+	if r, ok := resumers[regex]; ok {
+		return r
+	}
+	r := &resumer{}
+	copied, err := chroma.NewLexer(regex.Config(), func() chroma.Rules {
+		rules, err := regex.Rules()
+		if err != nil {
+			return chroma.Rules{"root": nil}
+		}
+		rules = rules.Clone()
+		rules[resumeState] = []chroma.Rule{{Mutator: chroma.MutatorFunc(r.resume)}}
+		return rules
+	})
+	if err != nil {
+		return nil
+	}
+	copied.SetRegistry(lexers.GlobalLexerRegistry)
+	r.lexer = copied
+	resumers[regex] = r
+	return r
+}
+
+// resume is the mutator of the resume state's rule. The first time a run
+// reaches it, it loads the stack the run starts with and skips the context
+// before the run's first line; after that the lexer only comes back to it
+// when recovering from an error at a newline, which starts again in root.
+func (r *resumer) resume(state *chroma.LexerState) error {
+	if state.Get(resumeState) != nil {
+		state.Stack = []string{"root"}
+		return nil
+	}
+	state.Set(resumeState, true)
+	state.Stack = slices.Clone(r.stack)
+	state.Pos = r.skip
+	r.state = state
+	return nil
+}
+
+// start lexes text from rune skip on, with stack as the state there,
+// returning the iterator, its lexer state and the first token
+func (r *resumer) start(text string, skip int, stack []string) (chroma.Iterator, *chroma.LexerState, chroma.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next, err := r.lexer.Tokenise(&chroma.TokeniseOptions{State: resumeState}, text)
+	if err != nil {
+		return nil, nil, chroma.EOF, err
+	}
+	r.stack, r.skip, r.state = stack, skip, nil
+	first := next() // Runs resume, which hands over the state
+	return next, r.state, first, nil
+}
+
+// lexRun is lexing in progress from some line to the end of the document
+type lexRun struct {
+	next     chroma.Iterator
+	state    *chroma.LexerState // Nil if the text was empty
+	pending  *chroma.Token      // First token, read when the run started
+	lines    int                // Document lines the run covers
+	spans    []ColorSpan        // Spans of the line being lexed
+	unclosed bool               // Whether the line being lexed is unclosed (see lineEnd)
+	col      int                // Rune column the next token starts at
+	pos      int                // Runes of the run's text lexed so far, context included
+	matched  int                // Position of the last match checked for failed openers
+}
+
+// resync holds what was lexed before an edit, to be spliced back in once
+// the lexer state at the end of a line matches the state there before
+type resync struct {
+	from  int // First line after the edit that is unchanged
+	delta int // Lines the edit added
+	spans [][]ColorSpan
+	ends  []lineEnd
+}
+
+// linesChanged keeps the results for lines before the first one that
+// differs from docLines, and the rest for reuse if lexing gets back into
+// step with them
+func (h *Highlighter) linesChanged(lines []string) {
+	old := h.docLines
+	first := 0
+	for first < len(old) && first < len(lines) && old[first] == lines[first] {
+		first++
+	}
+	tail := 0
+	for tail < min(len(old), len(lines))-first && old[len(old)-1-tail] == lines[len(lines)-1-tail] {
+		tail++
+	}
+
+	h.resync = nil
+	if tail > 0 {
+		h.resync = &resync{
+			from:  len(lines) - tail,
+			delta: len(lines) - len(old),
+			spans: h.docSpans,
+			ends:  h.docEnds,
+		}
+	}
+	keep := min(contextFrom(lines, first), len(h.docSpans))
+	for i := range keep {
+		if h.docEnds[i].unclosed {
+			keep = i // The edit may close what this line opened
+			break
+		}
+	}
+	for keep > 0 && !h.docEnds[keep-1].ok {
+		keep-- // The match running on past this line may reach the edit
+	}
+	h.docSpans = slices.Clip(h.docSpans[:keep])
+	h.docEnds = slices.Clip(h.docEnds[:keep])
+	h.run = nil
+}
+
+// lexTo lexes until the first end lines have spans
+func (h *Highlighter) lexTo(end int) {
+	for len(h.docSpans) < end {
+		if h.run == nil && !h.startRun() {
+			return
+		}
+		h.step()
+	}
+}
+
+// startRun starts lexing at the first line without spans, backing up to a
+// line whose start state is known
+func (h *Highlighter) startRun() bool {
+	from := len(h.docSpans)
+	for from > 0 && !h.docEnds[from-1].ok {
+		from--
+	}
+	h.docSpans = slices.Clip(h.docSpans[:from])
+	h.docEnds = slices.Clip(h.docEnds[:from])
+	stack := []string{"root"}
+	if from > 0 {
+		stack = h.docEnds[from-1].stack
+	}
+
+	lines := h.docLines[from:]
+	context := contextBefore(h.docLines, from)
+	skip := utf8.RuneCountInString(context)
+	next, state, first, err := h.resumer.start(context+strings.Join(lines, "\n"), skip, stack)
+	if err != nil {
+		for range lines {
+			h.docSpans = append(h.docSpans, nil)
+			h.docEnds = append(h.docEnds, lineEnd{})
+		}
+		return false
+	}
+	h.run = &lexRun{next: next, state: state, pending: &first, lines: len(h.docLines), pos: skip, matched: skip}
+	return true
+}
+
+// contextFrom returns the first line the context before line i reaches into
+func contextFrom(lines []string, i int) int {
+	for n := 0; i > 0 && n < resumeContext; i-- {
+		n += len(lines[i-1]) + 1
+	}
+	return i
+}
+
+// contextBefore returns the last resumeContext bytes of the text before
+// line i, starting on a rune boundary
+func contextBefore(lines []string, i int) string {
+	from := contextFrom(lines, i)
+	if from == i {
+		return ""
+	}
+	context := strings.Join(lines[from:i], "\n") + "\n"
+	cut := max(len(context)-resumeContext, 0)
+	for cut < len(context) && !utf8.RuneStart(context[cut]) {
+		cut++
+	}
+	return context[cut:]
+}
+
+// step lexes one token of the current run
+func (h *Highlighter) step() {
+	run := h.run
+	var t chroma.Token
+	if run.pending != nil {
+		t, run.pending = *run.pending, nil
+	} else {
+		t = run.next()
+	}
+	if t == chroma.EOF {
+		for len(h.docSpans) < run.lines {
+			h.endLine()
+		}
+		h.run = nil
+		return
+	}
+
+	switch {
+	case t.Type == chroma.Error:
+		run.unclosed = true
+	case run.state != nil && run.state.Pos != run.matched:
+		run.matched = run.state.Pos
+		if len(run.state.Groups) > 0 {
+			// The token is the first of a new match. Counting from where
+			// the match starts keeps pos right when a rule's tokens don't
+			// add up to its match, as when a nested group is emitted twice.
+			run.pos = run.state.Pos - utf8.RuneCountInString(run.state.Groups[0])
+		}
+		if h.resumer.failedOpener(run.state) {
+			run.unclosed = true
+		}
+	}
+	color := h.tokenColor(t.Type)
+	parts := strings.Split(t.Value, "\n")
+	for i, part := range parts {
+		if i > 0 {
+			h.endLine()
+		}
+		n := utf8.RuneCountInString(part)
+		if color != "" && n > 0 {
+			run.spans = appendSpan(run.spans, ColorSpan{Start: run.col, End: run.col + n, Color: color})
+		}
+		run.col += n
+	}
+	run.pos += utf8.RuneCountInString(t.Value)
+
+	// A token ending a line with nothing of its match left over leaves the
+	// lexer in the state the next line starts with
+	if len(parts) > 1 && run.col == 0 && run.state != nil && run.state.Pos == run.pos {
+		last := len(h.docEnds) - 1
+		h.docEnds[last].stack = h.sharedStack(run.state.Stack)
+		h.docEnds[last].ok = true
+		h.resynced()
+	}
+}
+
+// endLine adds the spans of the line the run just finished, with its end
+// state unknown until the token that ended it has been looked at
+func (h *Highlighter) endLine() {
+	run := h.run
+	if len(h.docSpans) < run.lines {
+		h.docSpans = append(h.docSpans, run.spans)
+		h.docEnds = append(h.docEnds, lineEnd{unclosed: run.unclosed})
+	}
+	run.spans, run.unclosed, run.col = nil, false, 0
+}
+
+// sharedStack copies stack, reusing the previous line's copy when they're
+// the same, since most lines end in the same state
+func (h *Highlighter) sharedStack(stack []string) []string {
+	for i := len(h.docEnds) - 2; i >= 0; i-- {
+		if h.docEnds[i].ok {
+			if slices.Equal(h.docEnds[i].stack, stack) {
+				return h.docEnds[i].stack
+			}
+			break
+		}
+	}
+	return slices.Clone(stack)
+}
+
+// resynced splices back the results from before an edit if the line just
+// lexed is past the edit and ends in the state it ended in before, and the
+// context lookbehinds see from the next line is unchanged too, since
+// everything after it would lex the same again. Until then the run goes on
+// lexing towards the end of the document.
+func (h *Highlighter) resynced() {
+	rs := h.resync
+	line := len(h.docEnds) - 1
+	if rs == nil || contextFrom(h.docLines, line+1) < rs.from {
+		return
+	}
+	old := line - rs.delta
+	if old >= len(rs.ends) {
+		h.resync = nil // Lexed past what there was before
+		return
+	}
+	before := lineEnd{stack: []string{"root"}, ok: true} // Lines added at the top
+	if old >= 0 {
+		before = rs.ends[old]
+	}
+	if !before.ok || !slices.Equal(before.stack, h.docEnds[line].stack) {
+		return
+	}
+	h.docSpans = append(h.docSpans, rs.spans[old+1:]...)
+	h.docEnds = append(h.docEnds, rs.ends[old+1:]...)
+	h.resync = nil
+	h.run = nil
+}
+
+// appendSpan adds span to spans, extending the last span instead when it
+// has the same color and ends where span starts
+func appendSpan(spans []ColorSpan, span ColorSpan) []ColorSpan {
+	if n := len(spans); n > 0 && spans[n-1].End == span.Start && spans[n-1].Color == span.Color {
+		spans[n-1].End = span.End
+		return spans
+	}
+	return append(spans, span)
+}
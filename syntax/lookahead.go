@@ -0,0 +1,290 @@
+package syntax
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// openerScan is how far past a failed match openers are looked for
+const openerScan = 256
+
+// Many lexers match a whole comment or string with one pattern, such as
+// `/\*(.|\n)*?\*/`. When the closing delimiter is missing the pattern fails
+// and the opening one is lexed as something else, but typing the closing
+// delimiter lines later makes it match. Lines where that happened are
+// marked unclosed, so an edit below them re-lexes from there.
+
+// openersFor returns, for each rule of a lexer state, a pattern matching
+// the opening part of what the rule matches when the rest can run across
+// lines; nil when it can't
+func (r *resumer) openersFor(state string, rules []*chroma.CompiledRule) []*regexp.Regexp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if openers, ok := r.openers[state]; ok {
+		return openers
+	}
+	openers := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if rule.Regexp != nil {
+			openers[i] = opener(strings.TrimPrefix(rule.Regexp.String(), `\G`))
+		}
+	}
+	if r.openers == nil {
+		r.openers = map[string][]*regexp.Regexp{}
+	}
+	r.openers[state] = openers
+	return openers
+}
+
+// opener returns a pattern for the part of pattern before a run of text
+// that can cross lines and must be followed by more, or nil if there is no
+// such run or the pattern isn't one Go can parse. For a comment pattern
+// that's the opening delimiter.
+func opener(pattern string) *regexp.Regexp {
+	re, err := syntax.Parse(goPattern(pattern), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	var alternatives []string
+	for _, open := range openingParts(re.Simplify()) {
+		alternatives = append(alternatives, open.String())
+	}
+	if len(alternatives) == 0 {
+		return nil
+	}
+	open, err := regexp.Compile(`\A(?:` + strings.Join(alternatives, "|") + `)`)
+	if err != nil {
+		return nil
+	}
+	return open
+}
+
+// goPattern rewrites what Go's regexp doesn't have in a lexer's pattern as
+// something that matches at least as much: a backreference becomes any
+// character, a lookaround is dropped and an atomic group becomes a plain one
+func goPattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern) && pattern[i+1] >= '1' && pattern[i+1] <= '9':
+			b.WriteString(`(?s:.)`)
+			for i+1 < len(pattern) && pattern[i+1] >= '0' && pattern[i+1] <= '9' {
+				i++
+			}
+		case c == '\\':
+			b.WriteString(pattern[i:min(i+2, len(pattern))])
+			i++
+		case c == '[':
+			end := classEnd(pattern, i)
+			b.WriteString(pattern[i:end])
+			i = end - 1
+		case c == '(' && hasAnyPrefix(pattern[i:], "(?=", "(?!", "(?<=", "(?<!"):
+			i = groupEnd(pattern, i) - 1
+		case c == '(' && strings.HasPrefix(pattern[i:], "(?>"):
+			b.WriteString("(?:")
+			i += 2
+		case c == '(' && strings.HasPrefix(pattern[i:], "(?<"):
+			b.WriteString("(?P<")
+			i += 2
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// hasAnyPrefix reports whether s starts with any of prefixes
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// classEnd returns the index just past the character class starting at i
+func classEnd(pattern string, i int) int {
+	i++
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		i++ // A ] first is part of the class
+	}
+	for ; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case ']':
+			return i + 1
+		}
+	}
+	return len(pattern)
+}
+
+// groupEnd returns the index just past the group starting at i
+func groupEnd(pattern string, i int) int {
+	depth := 0
+	for ; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			i = classEnd(pattern, i) - 1
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(pattern)
+}
+
+// openingParts returns the opening parts of re's alternatives: what comes
+// before a repeat that can run across lines and must be followed by more
+func openingParts(re *syntax.Regexp) []*syntax.Regexp {
+	switch re.Op {
+	case syntax.OpCapture:
+		return openingParts(re.Sub[0])
+	case syntax.OpAlternate:
+		var parts []*syntax.Regexp
+		for _, sub := range re.Sub {
+			parts = append(parts, openingParts(sub)...)
+		}
+		return parts
+	case syntax.OpConcat:
+		for i, sub := range re.Sub {
+			if !isBody(sub) || minLen(re.Sub[i+1:]...) == 0 {
+				continue
+			}
+			if minLen(re.Sub[:i]...) == 0 {
+				return nil // Nothing to recognise it by
+			}
+			return []*syntax.Regexp{{Op: syntax.OpConcat, Flags: re.Flags, Sub: re.Sub[:i]}}
+		}
+	}
+	return nil
+}
+
+// isBody reports whether re is an unbounded repeat that can match newlines
+// and more than whitespace, like the `(.|\n)*?` between a comment's
+// delimiters
+func isBody(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return isBody(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		return crossesLines(re) && !onlySpace(re)
+	case syntax.OpRepeat:
+		return re.Max < 0 && crossesLines(re) && !onlySpace(re)
+	}
+	return false
+}
+
+// onlySpace reports whether re matches nothing but whitespace
+func onlySpace(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return strings.TrimSpace(string(re.Rune)) == ""
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for c := re.Rune[i]; c <= re.Rune[i+1]; c++ {
+				if !unicode.IsSpace(c) {
+					return false
+				}
+			}
+		}
+		return true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return false
+	}
+	for _, sub := range re.Sub {
+		if !onlySpace(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// crossesLines reports whether re can match a newline
+func crossesLines(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar:
+		return true
+	case syntax.OpLiteral:
+		return strings.ContainsRune(string(re.Rune), '\n')
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '\n' && '\n' <= re.Rune[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sub := range re.Sub {
+		if crossesLines(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// minLen returns the fewest runes res can match in sequence
+func minLen(res ...*syntax.Regexp) int {
+	n := 0
+	for _, re := range res {
+		switch re.Op {
+		case syntax.OpLiteral:
+			n += len(re.Rune)
+		case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			n++
+		case syntax.OpCapture, syntax.OpPlus:
+			n += minLen(re.Sub[0])
+		case syntax.OpRepeat:
+			n += re.Min * minLen(re.Sub[0])
+		case syntax.OpConcat:
+			n += minLen(re.Sub...)
+		case syntax.OpAlternate:
+			least := -1
+			for _, sub := range re.Sub {
+				if m := minLen(sub); least < 0 || m < least {
+					least = m
+				}
+			}
+			n += max(least, 0)
+		}
+	}
+	return n
+}
+
+// failedOpener reports whether a rule before the one that matched last has
+// an opener at the start of that match, meaning the rule failed for want
+// of its closing delimiter
+func (r *resumer) failedOpener(state *chroma.LexerState) bool {
+	if len(state.Groups) == 0 {
+		return false
+	}
+	openers := r.openersFor(state.State, state.Rules[state.State])
+	start := state.Pos - len([]rune(state.Groups[0]))
+	var text string
+	for _, open := range openers[:min(state.Rule, len(openers))] {
+		if open == nil {
+			continue
+		}
+		if text == "" {
+			text = string(state.Text[start:min(start+openerScan, len(state.Text))])
+		}
+		if open.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package ui
+
+// changeStrip is drawn in the minimap's right margin beside unsaved edits
+const changeStrip = "▐"
+
+// changeMarkerRGB is the Kitty minimap color for unsaved edits when the
+// theme color can't be converted to RGB
+var changeMarkerRGB = [3]byte{215, 175, 95}
+
+// lineChanged reports whether a buffer line has unsaved edits
+func lineChanged(state *RenderState, line int) bool {
+	return line >= 0 && line < len(state.ChangedLines) && state.ChangedLines[line]
+}
+
+// rowChanged reports whether any buffer line behind a minimap row has unsaved edits
+func rowChanged(state *RenderState, owners []int, visualStart, visualEnd int) bool {
+	for v := visualStart; v < visualEnd && v < len(owners); v++ {
+		if lineChanged(state, owners[v]) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedRows maps changed buffer lines onto the rows of a track that spans
+// the whole document (the scrollbar). Returns nil when nothing has changed.
+func changedRows(changed []bool, rows int) []bool {
+	if rows <= 0 || len(changed) == 0 {
+		return nil
+	}
+	var marks []bool
+	for line, c := range changed {
+		if !c {
+			continue
+		}
+		if marks == nil {
+			marks = make([]bool, rows)
+		}
+		marks[int(int64(line)*int64(rows)/int64(len(changed)))] = true
+	}
+	return marks
+}
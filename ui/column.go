@@ -37,12 +37,48 @@ type RenderState struct {
 	// Selection state (map of line index to selection range)
 	Selection map[int]SelectionRange
 
+	// Extra selections and carets (multi-selection); Start == End is a bare caret
+	ExtraSelections map[int][]SelectionRange
+
+	// Other occurrences of the word under the cursor (map of line index to ranges)
+	Occurrences map[int][]SelectionRange
+
+	// Invisible and confusable characters to flag (map of line index to ranges)
+	Hazards map[int][]SelectionRange
+
+	// Annotated lines, marked in the line number gutter
+	AnnotatedLines   map[int]bool
+	AnnotationMarker string // Character drawn for the marker
+
+	// File tree sidebar
+	Tree          []TreeRow
+	TreeScroll    int    // First visible row
+	TreeSelected  int    // Highlighted row while the tree has focus
+	TreeFocused   bool   // Keys go to the tree rather than the text
+	TreeActive    int    // Row of the file being edited, or -1
+	TreeExpanded  string // Marker drawn before an expanded directory
+	TreeCollapsed string // Marker drawn before a collapsed directory
+	TreeEllipsis  string // Shown at the end of names cut short
+	TreeBorder    string // Character drawn between the tree and the text
+
 	// Syntax highlighting (map of line index to color spans)
 	LineColors map[int][]syntax.ColorSpan
 
 	// Display options
-	WordWrap bool
-	TabWidth int // Display width of tabs
+	WordWrap  bool
+	WrapWidth int    // Width of the text column, where word wrap breaks lines
+	TabWidth  int    // Columns between tab stops
+	Rulers    []int  // Columns to draw rulers at (no-wrap mode only)
+	RulerChar string // Character drawn for rulers past the end of a line
+	MoreLeft  string // Marks lines with text scrolled off the left edge (no-wrap mode only, "" = off)
+	MoreRight string // Marks lines running past the right edge (no-wrap mode only, "" = off)
+
+	// Minimap heatmap
+	LineHeat     []float64 // Per buffer line tint in [0, 1], negative = untinted (nil = heatmap off)
+	MinimapLabel string    // Shown on the last minimap row, e.g. reading time
+
+	// Unsaved edits, marked in the scrollbar and minimap
+	ChangedLines []bool // Per buffer line, true if edited since the last save (nil = no changes)
 
 	// Total document metrics (used by scrollbar, minimap)
 	TotalLines       int // Total buffer lines
@@ -52,4 +88,13 @@ type RenderState struct {
 	Styles Styles
 }
 
+// wrapWidth returns the width word wrap breaks lines at, or a typical
+// width when the text column's is unknown
+func (s *RenderState) wrapWidth() int {
+	if s.WrapWidth > 0 {
+		return s.WrapWidth
+	}
+	return 80
+}
+
 // Note: SelectionRange is defined in viewport.go
@@ -107,6 +107,23 @@ func (c *Compositor) FlexibleColumnWidth() int {
 	return c.width // No flexible column, return full width
 }
 
+// ColumnAt returns the enabled column covering screen column x, for routing
+// mouse events to the part of the layout they land on.
+func (c *Compositor) ColumnAt(x int) (Column, bool) {
+	widths := c.calculateColumnWidths()
+	start := 0
+	for i, col := range c.columns {
+		if !col.Enabled {
+			continue
+		}
+		if x >= start && x < start+widths[i] {
+			return col, true
+		}
+		start += widths[i]
+	}
+	return Column{}, false
+}
+
 // Render renders all enabled columns and joins them horizontally.
 func (c *Compositor) Render(state *RenderState) string {
 	if len(c.columns) == 0 || c.height <= 0 {
@@ -225,3 +225,25 @@ func TestVisualWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestCompositorColumnAt(t *testing.T) {
+	c := NewCompositor(20, 1)
+	c.SetColumns([]Column{
+		{Width: 3, Enabled: false, Renderer: &mockRenderer{char: "T"}},
+		{Width: 4, Enabled: true, Renderer: &mockRenderer{char: "N"}},
+		{Flexible: true, Enabled: true, Renderer: &mockRenderer{char: "x"}},
+		{Width: 1, Enabled: true, Renderer: &mockRenderer{char: "|"}},
+	})
+	for _, tt := range []struct {
+		x    int
+		want string
+	}{{0, "N"}, {3, "N"}, {4, "x"}, {18, "x"}, {19, "|"}} {
+		col, ok := c.ColumnAt(tt.x)
+		if !ok || col.Renderer.(*mockRenderer).char != tt.want {
+			t.Errorf("ColumnAt(%d) = %v, %v, want the %q column", tt.x, col, ok, tt.want)
+		}
+	}
+	if _, ok := c.ColumnAt(20); ok {
+		t.Error("ColumnAt past the right edge should find nothing")
+	}
+}
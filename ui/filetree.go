@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// TreeRow is one visible row of the file tree sidebar.
+type TreeRow struct {
+	Name     string
+	Depth    int  // Nesting below the tree root (0 = top level)
+	IsDir    bool // Directories are drawn with an expand marker
+	Expanded bool
+}
+
+// FileTreeWidth returns the width of the file tree column, border included.
+func FileTreeWidth() int {
+	return 26
+}
+
+// FileTreeRenderer renders the directory tree column on the left of the text.
+type FileTreeRenderer struct {
+	enabled bool
+}
+
+// NewFileTreeRenderer creates a new file tree renderer.
+func NewFileTreeRenderer() *FileTreeRenderer {
+	return &FileTreeRenderer{}
+}
+
+// SetEnabled shows or hides the file tree.
+func (r *FileTreeRenderer) SetEnabled(enabled bool) {
+	r.enabled = enabled
+}
+
+// IsEnabled returns whether the file tree is shown.
+func (r *FileTreeRenderer) IsEnabled() bool {
+	return r.enabled
+}
+
+// Render implements ColumnRenderer.
+// Draws the visible tree rows with a border on the right. The selected row
+// is highlighted while the tree has focus; the open file's row is tinted.
+func (r *FileTreeRenderer) Render(width, height int, state *RenderState) []string {
+	rows := make([]string, height)
+	if width <= 0 || height <= 0 || state == nil {
+		return rows
+	}
+
+	ui := state.Styles.Theme.UI
+	selectedColor := ColorToANSI(ui.MenuHighlightFg, ui.MenuHighlightBg)
+	activeColor := ColorToANSIFg(ui.LineNumberActive)
+	borderColor := ColorToANSIFg(ui.LineNumber)
+	resetCode := "\033[0m"
+	textWidth := width - 1 // Reserve 1 char for the border
+
+	for row := 0; row < height; row++ {
+		idx := state.TreeScroll + row
+		var sb strings.Builder
+		if idx >= 0 && idx < len(state.Tree) {
+			entry := state.Tree[idx]
+			marker := " "
+			if entry.IsDir {
+				marker = state.TreeCollapsed
+				if entry.Expanded {
+					marker = state.TreeExpanded
+				}
+			}
+			label := strings.Repeat("  ", entry.Depth) + marker + " " + entry.Name
+			label = runewidth.Truncate(label, textWidth, state.TreeEllipsis)
+			label += strings.Repeat(" ", textWidth-runewidth.StringWidth(label))
+
+			switch {
+			case idx == state.TreeSelected && state.TreeFocused:
+				sb.WriteString(selectedColor)
+			case idx == state.TreeActive:
+				sb.WriteString(activeColor)
+			}
+			if entry.IsDir {
+				sb.WriteString("\033[1m")
+			}
+			sb.WriteString(label)
+			sb.WriteString(resetCode)
+		} else {
+			sb.WriteString(strings.Repeat(" ", textWidth))
+		}
+		sb.WriteString(borderColor)
+		sb.WriteString(state.TreeBorder)
+		sb.WriteString(resetCode)
+		rows[row] = sb.String()
+	}
+	return rows
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileTreeRender(t *testing.T) {
+	state := &RenderState{
+		Tree: []TreeRow{
+			{Name: "editor", IsDir: true, Expanded: true},
+			{Name: "a_rather_long_file_name.go", Depth: 1},
+		},
+		TreeActive:    -1,
+		TreeExpanded:  "v",
+		TreeCollapsed: ">",
+		TreeEllipsis:  "~",
+		TreeBorder:    "|",
+	}
+	rows := NewFileTreeRenderer().Render(16, 3, state)
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	for i, row := range rows {
+		if w := visualWidth(row); w != 16 {
+			t.Errorf("row %d is %d wide, want 16", i, w)
+		}
+	}
+	if got := stripANSI(rows[0]); got != "v editor"+strings.Repeat(" ", 7)+"|" {
+		t.Errorf("row 0 = %q, want the expanded directory", got)
+	}
+	if got := stripANSI(rows[1]); got != "    a_rather_l~|" {
+		t.Errorf("row 1 = %q, want the indented name cut short", got)
+	}
+}
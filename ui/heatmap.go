@@ -0,0 +1,77 @@
+package ui
+
+import "fmt"
+
+// Minimap heatmap modes
+const (
+	HeatmapOff     = "off"     // Plain minimap
+	HeatmapLength  = "length"  // Tint lines by length
+	HeatmapRecency = "recency" // Tint recently edited lines
+)
+
+// HeatmapModes returns the minimap heatmap modes in cycle order
+func HeatmapModes() []string {
+	return []string{HeatmapOff, HeatmapLength, HeatmapRecency}
+}
+
+// heatPalette runs from cool to hot
+var heatPalette = []string{"#5f87af", "#5fafaf", "#87af5f", "#d7af5f", "#d7875f", "#d75f5f"}
+
+// heatHex returns the palette color for a heat value in [0, 1]
+func heatHex(heat float64) string {
+	if heat < 0 {
+		heat = 0
+	}
+	idx := int(heat * float64(len(heatPalette)))
+	if idx >= len(heatPalette) {
+		idx = len(heatPalette) - 1
+	}
+	return heatPalette[idx]
+}
+
+// heatColor returns the ANSI foreground escape for a heat value in [0, 1]
+func heatColor(heat float64) string {
+	return ColorToANSIFg(heatHex(heat))
+}
+
+// rowHeat returns the hottest heat among the buffer lines behind a minimap row,
+// or -1 if none of them has a heat value
+func rowHeat(state *RenderState, owners []int, visualStart, visualEnd int) float64 {
+	heat := -1.0
+	for v := visualStart; v < visualEnd && v < len(owners); v++ {
+		line := owners[v]
+		if line < len(state.LineHeat) && state.LineHeat[line] > heat {
+			heat = state.LineHeat[line]
+		}
+	}
+	return heat
+}
+
+// visualLineOwners maps each visual line produced by generateVisualLines
+// back to the buffer line it came from
+func visualLineOwners(lines []string, wordWrap bool, textWidth, tabWidth int) []int {
+	owners := make([]int, 0, len(lines))
+	for i, line := range lines {
+		n := 1
+		if wordWrap && textWidth > 0 {
+			n = len(WrapLine(line, textWidth, tabWidth))
+		}
+		for j := 0; j < n; j++ {
+			owners = append(owners, i)
+		}
+	}
+	return owners
+}
+
+// ReadingTime formats an estimated reading time for a word count (at 200 wpm)
+func ReadingTime(words int) string {
+	minutes := (words + 199) / 200
+	switch {
+	case words == 0:
+		return ""
+	case minutes < 60:
+		return fmt.Sprintf("~%dmin", minutes)
+	default:
+		return fmt.Sprintf("~%dh", (minutes+30)/60)
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVisualLineOwners(t *testing.T) {
+	lines := []string{"short", "0123456789abc", ""}
+
+	if got := visualLineOwners(lines, false, 5, 4); len(got) != 3 {
+		t.Errorf("without wrap, owners = %v, want one per line", got)
+	}
+
+	got := visualLineOwners(lines, true, 5, 4)
+	want := []int{0, 1, 1, 1, 2}
+	if len(got) != len(want) {
+		t.Fatalf("with wrap, owners = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("owners[%d] = %d, want %d", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRowHeat(t *testing.T) {
+	state := &RenderState{LineHeat: []float64{-1, 0.2, 0.9, -1}}
+	owners := []int{0, 1, 2, 3}
+
+	if got := rowHeat(state, owners, 0, 2); got != 0.2 {
+		t.Errorf("rowHeat(0,2) = %v, want 0.2", got)
+	}
+	if got := rowHeat(state, owners, 0, 4); got != 0.9 {
+		t.Errorf("rowHeat(0,4) = %v, want 0.9", got)
+	}
+	if got := rowHeat(state, owners, 3, 4); got >= 0 {
+		t.Errorf("rowHeat of untinted line = %v, want negative", got)
+	}
+	if heatHex(0) == heatHex(1) {
+		t.Errorf("cold and hot should use different colors")
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  string
+	}{
+		{0, ""},
+		{50, "~1min"},
+		{1000, "~5min"},
+		{30000, "~3h"},
+	}
+	for _, tt := range tests {
+		if got := ReadingTime(tt.words); got != tt.want {
+			t.Errorf("ReadingTime(%d) = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestChangedRows(t *testing.T) {
+	if got := changedRows([]bool{false, false}, 4); got != nil {
+		t.Errorf("changedRows() with no changes = %v, want nil", got)
+	}
+	changed := make([]bool, 100)
+	changed[0] = true
+	changed[99] = true
+	got := changedRows(changed, 10)
+	want := []bool{true, false, false, false, false, false, false, false, false, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedRows() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// ITermMinimapRenderer draws the pixel minimap with iTerm2's inline images
+// protocol (OSC 1337), sending it as a PNG. Like the Sixel renderer it
+// shares the Kitty renderer's layout and click handling, and the image
+// lives in the text cells, so text written over the column erases it.
+type ITermMinimapRenderer struct {
+	*KittyMinimapRenderer
+}
+
+// NewITermMinimapRenderer creates an iTerm2 inline image minimap renderer.
+func NewITermMinimapRenderer(styles Styles) *ITermMinimapRenderer {
+	return &ITermMinimapRenderer{KittyMinimapRenderer: NewKittyMinimapRenderer(styles, true)}
+}
+
+// GetKittySequence returns the minimap as an iTerm2 inline image,
+// positioned at the minimap column like the Kitty overlay. iTerm2 scales
+// the image to fill the column.
+func (r *ITermMinimapRenderer) GetKittySequence(width, height, xOffset, yOffset int, state *RenderState) string {
+	if !r.enabled || state == nil || width <= 0 || height <= 0 {
+		return ""
+	}
+	pixels, imgWidth, imgHeight := r.minimapPixels(height, state)
+	data, err := encodePNG(pixels, imgWidth, imgHeight)
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\033[s")
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH", yOffset+1, xOffset+1))
+	sb.WriteString(fmt.Sprintf("\033]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+		len(data), width, height, base64.StdEncoding.EncodeToString(data)))
+	sb.WriteString("\033[u")
+	return sb.String()
+}
+
+// ClearImage returns nothing: redrawing the text erases an inline image.
+func (r *ITermMinimapRenderer) ClearImage() string {
+	return ""
+}
+
+// encodePNG encodes RGBA pixels as a PNG
+func encodePNG(pixels []byte, width, height int) ([]byte, error) {
+	img := &image.NRGBA{Pix: pixels, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
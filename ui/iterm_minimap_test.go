@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestITermMinimapSequence(t *testing.T) {
+	r := NewITermMinimapRenderer(Styles{})
+	r.SetEnabled(true)
+	state := &RenderState{Lines: []string{"package main", "", "func main() {}"}, TabWidth: 4}
+	got := r.GetKittySequence(8, 3, 70, 1, state)
+
+	prefix := "\033[s\033[2;71H\033]1337;File=inline=1;size="
+	if !strings.HasPrefix(got, prefix) || !strings.HasSuffix(got, "\a\033[u") {
+		t.Fatalf("sequence = %q", got)
+	}
+	args, data, _ := strings.Cut(strings.TrimSuffix(got, "\a\033[u"), ":")
+	if !strings.Contains(args, ";width=8;height=3;preserveAspectRatio=0") {
+		t.Errorf("arguments = %q, want the image stretched over 8x3 cells", args)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != kittyMinimapWidth || b.Dy() != 3*16 {
+		t.Errorf("image is %dx%d", b.Dx(), b.Dy())
+	}
+	if !ImageInTextCells(r) || ImageInTextCells(NewKittyMinimapRenderer(Styles{}, true)) {
+		t.Error("only Sixel and iTerm2 images live in the text cells")
+	}
+}
@@ -78,10 +78,11 @@ func (r *KittyMinimapRenderer) SetLineColorFunc(fn func(line string) []syntax.Co
 
 // Pixel dimensions for the minimap image
 const (
-	kittyPixelsPerChar  = 1   // 1 pixel per source character
-	kittyPixelsPerLine  = 2   // 2 pixels per source line (for visibility)
-	kittyMinimapWidth   = 120 // Max source characters shown
-	kittyIndicatorAlpha = 80  // Viewport indicator overlay alpha (0-255)
+	kittyPixelsPerChar    = 1   // 1 pixel per source character
+	kittyPixelsPerLine    = 2   // 2 pixels per source line (for visibility)
+	kittyMinimapWidth     = 120 // Max source characters shown
+	kittyIndicatorAlpha   = 80  // Viewport indicator overlay alpha (0-255)
+	kittyChangeStripWidth = 3   // Pixels of the unsaved-edit strip on the right edge
 )
 
 // Render implements ColumnRenderer.
@@ -119,6 +120,30 @@ func (r *KittyMinimapRenderer) GetKittySequence(width, height, xOffset, yOffset
 		return ""
 	}
 
+	pixels, imgWidth, imgHeight := r.minimapPixels(height, state)
+
+	// Build the escape sequence with cursor positioning
+	var sb strings.Builder
+
+	// Save cursor position
+	sb.WriteString("\033[s")
+
+	// Move cursor to minimap position (1-indexed)
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH", yOffset+1, xOffset+1))
+
+	// Send Kitty graphics
+	sb.WriteString(r.encodeKittyGraphics(pixels, imgWidth, imgHeight, width, height))
+
+	// Restore cursor position
+	sb.WriteString("\033[u")
+
+	return sb.String()
+}
+
+// minimapPixels lays out the minimap image for a column height rows tall:
+// RGBA pixels at a nominal 16 pixels per row, scrolled to keep the viewport
+// in view. It remembers the lines shown for click handling.
+func (r *KittyMinimapRenderer) minimapPixels(height int, state *RenderState) ([]byte, int, int) {
 	totalLines := len(state.Lines)
 	if totalLines == 0 {
 		totalLines = 1
@@ -166,24 +191,7 @@ func (r *KittyMinimapRenderer) GetKittySequence(width, height, xOffset, yOffset
 	r.lastLinesShown = linesShown
 
 	// Generate pixel data - pass viewport height for correct highlight
-	pixels := r.generatePixelDataWithSyntax(imgWidth, imgHeight, startLine, endLine, height, state)
-
-	// Build the escape sequence with cursor positioning
-	var sb strings.Builder
-
-	// Save cursor position
-	sb.WriteString("\033[s")
-
-	// Move cursor to minimap position (1-indexed)
-	sb.WriteString(fmt.Sprintf("\033[%d;%dH", yOffset+1, xOffset+1))
-
-	// Send Kitty graphics
-	sb.WriteString(r.encodeKittyGraphics(pixels, imgWidth, imgHeight, width, height))
-
-	// Restore cursor position
-	sb.WriteString("\033[u")
-
-	return sb.String()
+	return r.generatePixelDataWithSyntax(imgWidth, imgHeight, startLine, endLine, height, state), imgWidth, imgHeight
 }
 
 // renderKittyGraphics generates a VSCode-style minimap using Kitty graphics protocol.
@@ -273,6 +281,12 @@ func (r *KittyMinimapRenderer) generatePixelDataWithSyntax(imgWidth, imgHeight,
 	// Viewport highlight color (darker gray, semi-transparent overlay)
 	viewportHighlight := [3]byte{80, 80, 80}
 
+	// Strip color for lines with unsaved edits
+	changeColor := parseANSIToRGB(ColorToANSIFg(r.styles.Theme.UI.ChangeMarker))
+	if changeColor == [3]byte{} {
+		changeColor = changeMarkerRGB
+	}
+
 	// Fill background (fully opaque)
 	for i := 0; i < len(pixels); i += 4 {
 		pixels[i] = bgColor[0]
@@ -341,7 +355,9 @@ func (r *KittyMinimapRenderer) generatePixelDataWithSyntax(imgWidth, imgHeight,
 
 			// Get color for this character (use rune index for syntax lookup)
 			charColor := defaultTextColor
-			if colors != nil {
+			if lineIdx < len(state.LineHeat) && state.LineHeat[lineIdx] >= 0 {
+				charColor = hexToRGB(heatHex(state.LineHeat[lineIdx]))
+			} else if colors != nil {
 				ansiColor := syntax.ColorAt(colors, runeIdx)
 				if ansiColor != "" {
 					// Parse ANSI color to RGB
@@ -365,6 +381,19 @@ func (r *KittyMinimapRenderer) generatePixelDataWithSyntax(imgWidth, imgHeight,
 			}
 			visualCol++
 		}
+
+		// Unsaved edits get a strip down the right edge
+		if lineChanged(state, lineIdx) {
+			for py := pyStart; py < pyEnd && py < imgHeight; py++ {
+				for px := imgWidth - kittyChangeStripWidth; px < imgWidth; px++ {
+					idx := (py*imgWidth + px) * 4
+					pixels[idx] = changeColor[0]
+					pixels[idx+1] = changeColor[1]
+					pixels[idx+2] = changeColor[2]
+					pixels[idx+3] = 255
+				}
+			}
+		}
 	}
 
 	return pixels
@@ -504,26 +533,15 @@ func (r *KittyMinimapRenderer) encodeKittyGraphics(pixels []byte, imgWidth, imgH
 }
 
 // generateVisualLines converts buffer lines to visual lines respecting word wrap.
-func (r *KittyMinimapRenderer) generateVisualLines(lines []string, wordWrap bool, textWidth int) []string {
+func (r *KittyMinimapRenderer) generateVisualLines(lines []string, wordWrap bool, textWidth, tabWidth int) []string {
 	if !wordWrap || textWidth <= 0 {
+		// No word wrap - visual lines = buffer lines
 		return lines
 	}
 
 	var visualLines []string
 	for _, line := range lines {
-		lineRunes := []rune(line)
-		if len(lineRunes) == 0 {
-			visualLines = append(visualLines, "")
-			continue
-		}
-		for len(lineRunes) > 0 {
-			end := textWidth
-			if end > len(lineRunes) {
-				end = len(lineRunes)
-			}
-			visualLines = append(visualLines, string(lineRunes[:end]))
-			lineRunes = lineRunes[end:]
-		}
+		visualLines = append(visualLines, WrapLine(line, textWidth, tabWidth)...)
 	}
 	if len(visualLines) == 0 {
 		visualLines = []string{""}
@@ -544,8 +562,8 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 	}
 
 	// Generate visual lines
-	textWidth := 80
-	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth)
+	textWidth := state.wrapWidth()
+	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth, state.TabWidth)
 	totalVisualLines := len(visualLines)
 	if totalVisualLines == 0 {
 		totalVisualLines = 1
@@ -554,6 +572,12 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 
 	minimapHeight := (totalVisualLines + 3) / 4
 
+	// Map visual lines back to buffer lines for heatmap tinting
+	var owners []int
+	if state.LineHeat != nil || state.ChangedLines != nil {
+		owners = visualLineOwners(state.Lines, state.WordWrap, textWidth, state.TabWidth)
+	}
+
 	// Viewport indicator range
 	visibleStart := state.ScrollY
 	visibleEnd := state.ScrollY + height
@@ -562,6 +586,7 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 	ui := r.styles.Theme.UI
 	indicatorColor := ColorToANSIFg(ui.MinimapIndicator)
 	textColor := ColorToANSIFg(ui.MinimapText)
+	changeColor := ColorToANSIFg(ui.ChangeMarker)
 	resetCode := "\033[0m"
 
 	// Scroll offset if minimap is taller than viewport
@@ -582,6 +607,16 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 		var sb strings.Builder
 		minimapRow := row + minimapScrollOffset
 
+		// Last row shows the label (e.g. reading time) when there is one
+		if state.MinimapLabel != "" && row == height-1 && height > 1 {
+			label := " " + state.MinimapLabel
+			if len(label) > width {
+				label = label[:width]
+			}
+			rows[row] = indicatorColor + label + resetCode + strings.Repeat(" ", width-len(label))
+			continue
+		}
+
 		if minimapRow >= minimapHeight {
 			sb.WriteString(strings.Repeat(" ", width))
 			rows[row] = sb.String()
@@ -611,12 +646,22 @@ func (r *KittyMinimapRenderer) renderBrailleFallback(width, height int, state *R
 			}
 		}
 
-		sb.WriteString(textColor)
+		rowColor := textColor
+		if state.LineHeat != nil {
+			if heat := rowHeat(state, owners, visualLineStart, visualLineEnd); heat >= 0 {
+				rowColor = heatColor(heat)
+			}
+		}
+		sb.WriteString(rowColor)
 		braille := renderBrailleChars(fourLines, brailleWidth)
 		sb.WriteString(braille)
 		sb.WriteString(resetCode)
 
-		sb.WriteString(" ")
+		if rowChanged(state, owners, visualLineStart, visualLineEnd) {
+			sb.WriteString(changeColor + changeStrip + resetCode)
+		} else {
+			sb.WriteString(" ")
+		}
 		rows[row] = sb.String()
 		visualLineCount++
 	}
@@ -724,8 +769,8 @@ func (r *KittyMinimapRenderer) GetMetrics(viewportHeight int, state *RenderState
 	}
 
 	// Braille fallback metrics
-	textWidth := 80
-	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth)
+	textWidth := state.wrapWidth()
+	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth, state.TabWidth)
 	totalVisualLines := len(visualLines)
 	if totalVisualLines == 0 {
 		totalVisualLines = 1
@@ -1,12 +1,19 @@
 package ui
 
-import (
-	"strings"
-	"unicode/utf8"
-)
+import "strings"
 
-// LineNumberRenderer renders line numbers in a column.
-// Standard width is 5 (4 digits + 1 space separator).
+// minLineNumberDigits is the narrowest the number column gets, so it
+// doesn't change width as a short file grows
+const minLineNumberDigits = 3
+
+// LineNumberColumnWidth returns the width of the line number column for a
+// document of lineCount lines: its largest number plus a separator space
+func LineNumberColumnWidth(lineCount int) int {
+	return max(len(itoaLocal(lineCount)), minLineNumberDigits) + 1
+}
+
+// LineNumberRenderer renders line numbers in a column whose width is set by
+// LineNumberColumnWidth.
 type LineNumberRenderer struct {
 	styles Styles
 }
@@ -64,7 +71,7 @@ func (r *LineNumberRenderer) renderNoWrap(rows []string, width, numWidth, height
 			}
 			sb.WriteString(numStr)
 			sb.WriteString(resetCode)
-			sb.WriteString(" ")
+			writeSeparator(&sb, lineIdx, activeColor, state)
 		} else {
 			// Past end of file - empty gutter
 			sb.WriteString(strings.Repeat(" ", width))
@@ -82,11 +89,8 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	activeColor := ColorToANSIFg(ui.LineNumberActive)
 	resetCode := "\033[0m"
 
-	// Calculate text width (we need this to determine wrap points)
-	// This is a bit of a hack - we don't know the text column width here.
-	// For now, estimate based on a typical layout.
-	// TODO: Pass text width through RenderState
-	textWidth := 80 // Default estimate
+	// Wrap points match the text column's
+	textWidth := state.wrapWidth()
 
 	// Find which buffer line corresponds to ScrollY visual line
 	visualLine := 0
@@ -94,8 +98,7 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	wrapOffset := 0
 
 	for bufferLine < len(state.Lines) && visualLine < state.ScrollY {
-		lineLen := utf8.RuneCountInString(state.Lines[bufferLine])
-		wrappedCount := countWrappedLinesForWidth(lineLen, textWidth)
+		wrappedCount := len(WrapLine(state.Lines[bufferLine], textWidth, state.TabWidth))
 
 		if visualLine+wrappedCount > state.ScrollY {
 			// Start partway through this line
@@ -117,8 +120,7 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 			continue
 		}
 
-		lineLen := utf8.RuneCountInString(state.Lines[bufferLine])
-		wrappedCount := countWrappedLinesForWidth(lineLen, textWidth)
+		wrappedCount := len(WrapLine(state.Lines[bufferLine], textWidth, state.TabWidth))
 
 		if wrapOffset == 0 {
 			// First visual line of buffer line - show number
@@ -132,7 +134,7 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 			}
 			sb.WriteString(numStr)
 			sb.WriteString(resetCode)
-			sb.WriteString(" ")
+			writeSeparator(&sb, bufferLine, activeColor, state)
 		} else {
 			// Continuation line - empty gutter
 			sb.WriteString(strings.Repeat(" ", width))
@@ -149,15 +151,16 @@ func (r *LineNumberRenderer) renderWrapped(rows []string, width, numWidth, heigh
 	}
 }
 
-// countWrappedLinesForWidth returns how many visual lines a buffer line takes.
-func countWrappedLinesForWidth(lineLen, textWidth int) int {
-	if textWidth <= 0 {
-		return 1
-	}
-	if lineLen == 0 {
-		return 1
+// writeSeparator writes the column between the number and the text: the
+// annotation marker on annotated lines, otherwise a space
+func writeSeparator(sb *strings.Builder, lineIdx int, color string, state *RenderState) {
+	if state.AnnotatedLines[lineIdx] && state.AnnotationMarker != "" {
+		sb.WriteString(color)
+		sb.WriteString(state.AnnotationMarker)
+		sb.WriteString("\033[0m")
+		return
 	}
-	return (lineLen + textWidth - 1) / textWidth
+	sb.WriteString(" ")
 }
 
 // padLeftStr pads a string with spaces on the left to reach the target width.
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -18,35 +19,81 @@ const (
 	ActionOpen
 	ActionRecentFiles
 	ActionRecentDirs
+	ActionQuickOpen // Fuzzy-finds a recent file or one under the working directory
 	ActionClose
+	ActionReopenClosed
 	ActionSave
 	ActionSaveAs
 	ActionRevert
-	ActionSetEncoding // Opens encoding selection dialog
+	ActionRestoreBackup  // Lists the active file's backups to restore one
+	ActionLocalHistory   // Lists the snapshots of the active file's saves
+	ActionSetEncoding    // Opens encoding selection dialog
+	ActionReopenEncoding // Re-reads the file in an encoding chosen from the same dialog
+	ActionToggleBOM      // Adds or removes the byte order mark saved with a Unicode file
+	ActionStatistics     // Opens file statistics dialog
+	ActionPrint          // Pipes the buffer or selection to the print command
 	ActionExit
 	// Edit menu
 	ActionUndo
 	ActionRedo
+	ActionUndoHistory // Opens the undo tree to go back to any earlier state
+	ActionEarlier     // Goes back through the undo history by time or count
+	ActionLater       // Goes forward through the undo history by time or count
 	ActionCut
 	ActionCopy
+	ActionCopyAppend // Append selection to clipboard
 	ActionPaste
+	ActionPasteHistory   // Opens the clipboard history dialog
+	ActionCopyToRegister // Copies the selection to a named register
+	ActionPasteRegister  // Pastes a named register
+	ActionInsertBuffer   // Inserts another buffer's contents at the cursor
+	ActionInsertText     // Inserts a date, the file name, a UUID or a command's output
 	ActionCutLine
 	ActionSelectAll
+	ActionUppercase // Text transforms on the selection
+	ActionLowercase
+	ActionTitleCase
+	ActionToggleCase
+	ActionSortLines // Line transforms on the selected lines
+	ActionReverseLines
+	ActionUniqueLines
+	ActionIndentToSpaces  // Rewrites the buffer's leading whitespace as spaces
+	ActionIndentToTabs    // Rewrites the buffer's leading whitespace as tabs
+	ActionIncrementNumber // Adds one to the number at the cursor
+	ActionDecrementNumber // Subtracts one from the number at the cursor
+	ActionFixInvisibles   // Strips invisible characters and normalizes to NFC
 	// Search menu
 	ActionFind
 	ActionFindNext
 	ActionReplace
 	ActionGoToLine
+	ActionCenterCursor   // Scrolls the cursor line to the middle of the view
+	ActionGoToSymbol     // Lists the definitions in the current file
+	ActionGoToDefinition // Jumps to the definition of the word at the cursor
+	ActionCursorInfo     // Shows the character at the cursor in the status bar
+	ActionAnnotateLine   // Adds or edits the note on the cursor line
+	ActionAnnotations    // Lists the notes in the current file
 	// Options menu
 	ActionWordWrap
 	ActionLineNumbers
 	ActionSyntaxHighlight
-	ActionScrollbar   // Toggle scrollbar
-	ActionMinimap     // Toggle minimap
-	ActionTheme       // Opens theme selection dialog
-	ActionKeybindings // Opens keybindings dialog
-	ActionSettings    // Opens settings dialog
+	ActionAutoPair      // Toggle auto-closing brackets and quotes
+	ActionHighlightWord // Toggle highlighting the word under the cursor
+	ActionScrollbar     // Toggle scrollbar
+	ActionMinimap       // Toggle minimap
+	ActionMinimapHeat   // Cycle minimap heatmap mode
+	ActionFileTree      // Toggle the directory tree sidebar
+	ActionHexView       // Toggle showing the file's bytes in hex
+	ActionFollowFile    // Toggle following text written to the end of the file
+	ActionTheme         // Opens theme selection dialog
+	ActionKeybindings   // Opens keybindings dialog
+	ActionSettings      // Opens settings dialog
+	ActionIndentation   // Opens the indentation dialog for the active buffer
 	// Buffers menu
+	ActionBufferList  // Opens the buffer switcher dialog
+	ActionSaveAll     // Saves every modified buffer
+	ActionCloseAll    // Closes every buffer
+	ActionCloseOthers // Closes every buffer but the active one
 	ActionBuffer1
 	ActionBuffer2
 	ActionBuffer3
@@ -70,6 +117,7 @@ const (
 	// Help menu
 	ActionHelp
 	ActionAbout
+	ActionViewLog // Opens the --debug log
 )
 
 // MenuItem represents a single menu option
@@ -108,17 +156,29 @@ func NewMenuBar(styles Styles) *MenuBar {
 					{Label: "Open", Shortcut: "Ctrl+O", HotKey: 'O', Action: ActionOpen},
 					{Label: "Recent Files", Shortcut: "Ctrl+R", HotKey: 'R', Action: ActionRecentFiles},
 					{Label: "Recent Dirs", Shortcut: "", HotKey: 'D', Action: ActionRecentDirs},
+					{Label: "Quick Open...", Shortcut: "Ctrl+P", HotKey: 'Q', Action: ActionQuickOpen},
 					{Label: "Close", Shortcut: "Ctrl+W", HotKey: 'C', Action: ActionClose},
+					{Label: "Reopen Closed", Shortcut: "Ctrl+Shift+T", HotKey: 'P', Action: ActionReopenClosed, Disabled: true},
 					{Label: "Save", Shortcut: "Ctrl+S", HotKey: 'S', Action: ActionSave},
 					{Label: "Save As", Shortcut: "", HotKey: 'A', Action: ActionSaveAs},
 					{Label: "Revert", Shortcut: "", HotKey: 'R', Action: ActionRevert},
+					{Label: "Restore Backup...", Shortcut: "", HotKey: 'K', Action: ActionRestoreBackup},
+					{Label: "Local History...", Shortcut: "", HotKey: 'H', Action: ActionLocalHistory},
 					{Label: "Set Encoding", Shortcut: "", HotKey: 'E', Action: ActionSetEncoding},
+					{Label: "Reopen with Encoding...", Shortcut: "", HotKey: 'W', Action: ActionReopenEncoding},
+					{Label: "Add BOM", Shortcut: "", HotKey: 'B', Action: ActionToggleBOM},
+					{Label: "Statistics", Shortcut: "", HotKey: 'T', Action: ActionStatistics},
+					{Label: "Print...", Shortcut: "", HotKey: 'I', Action: ActionPrint},
 					{Label: "Exit", Shortcut: "Ctrl+Q", HotKey: 'X', Action: ActionExit},
 				},
 			},
 			{
 				Label: "Buffers",
 				Items: []MenuItem{
+					{Label: "Buffer List...", Shortcut: "Ctrl+E", HotKey: 'L', Action: ActionBufferList},
+					{Label: "Save All", Shortcut: "", HotKey: 'S', Action: ActionSaveAll},
+					{Label: "Close All", Shortcut: "", HotKey: 'A', Action: ActionCloseAll},
+					{Label: "Close Others", Shortcut: "", HotKey: 'O', Action: ActionCloseOthers},
 					{Label: "(no buffers)", Shortcut: "", HotKey: 0, Action: ActionNone, Disabled: true},
 				},
 			},
@@ -127,11 +187,32 @@ func NewMenuBar(styles Styles) *MenuBar {
 				Items: []MenuItem{
 					{Label: "Undo", Shortcut: "Ctrl+Z", HotKey: 'U', Action: ActionUndo},
 					{Label: "Redo", Shortcut: "Ctrl+Y", HotKey: 'R', Action: ActionRedo},
+					{Label: "Undo History...", Shortcut: "Alt+Z", HotKey: 'Y', Action: ActionUndoHistory},
+					{Label: "Earlier...", Shortcut: "", HotKey: 0, Action: ActionEarlier},
+					{Label: "Later...", Shortcut: "", HotKey: 0, Action: ActionLater},
 					{Label: "Cut", Shortcut: "Ctrl+X", HotKey: 'T', Action: ActionCut},
 					{Label: "Copy", Shortcut: "Ctrl+C", HotKey: 'C', Action: ActionCopy},
+					{Label: "Copy Append", Shortcut: "Alt+C", HotKey: 'A', Action: ActionCopyAppend},
 					{Label: "Paste", Shortcut: "Ctrl+V", HotKey: 'P', Action: ActionPaste},
+					{Label: "Paste from History...", Shortcut: "Ctrl+Shift+V", HotKey: 'H', Action: ActionPasteHistory},
+					{Label: "Copy to Register...", Shortcut: "Alt+R", HotKey: 'G', Action: ActionCopyToRegister},
+					{Label: "Paste Register...", Shortcut: "Alt+I", HotKey: 'E', Action: ActionPasteRegister},
+					{Label: "Insert Buffer...", Shortcut: "", HotKey: 'B', Action: ActionInsertBuffer},
+					{Label: "Insert Text...", Shortcut: "F2", HotKey: 'X', Action: ActionInsertText},
 					{Label: "Cut Line", Shortcut: "Ctrl+K", HotKey: 'K', Action: ActionCutLine},
 					{Label: "Select All", Shortcut: "Ctrl+A", HotKey: 'L', Action: ActionSelectAll},
+					{Label: "Uppercase", Shortcut: "", HotKey: 'S', Action: ActionUppercase},
+					{Label: "Lowercase", Shortcut: "", HotKey: 'W', Action: ActionLowercase},
+					{Label: "Title Case", Shortcut: "", HotKey: 'I', Action: ActionTitleCase},
+					{Label: "Toggle Case", Shortcut: "", HotKey: 'O', Action: ActionToggleCase},
+					{Label: "Sort Lines", Shortcut: "", HotKey: 'N', Action: ActionSortLines},
+					{Label: "Reverse Lines", Shortcut: "", HotKey: 'V', Action: ActionReverseLines},
+					{Label: "Unique Lines", Shortcut: "", HotKey: 'Q', Action: ActionUniqueLines},
+					{Label: "Convert Indentation to Spaces", Shortcut: "", HotKey: 0, Action: ActionIndentToSpaces},
+					{Label: "Convert Indentation to Tabs", Shortcut: "", HotKey: 0, Action: ActionIndentToTabs},
+					{Label: "Increment Number", Shortcut: "Alt+A", HotKey: 'M', Action: ActionIncrementNumber},
+					{Label: "Decrement Number", Shortcut: "Alt+X", HotKey: 'D', Action: ActionDecrementNumber},
+					{Label: "Fix Invisible Characters...", Shortcut: "", HotKey: 'F', Action: ActionFixInvisibles},
 				},
 			},
 			{
@@ -141,18 +222,31 @@ func NewMenuBar(styles Styles) *MenuBar {
 					{Label: "Find Next", Shortcut: "F3", HotKey: 'N', Action: ActionFindNext},
 					{Label: "Replace", Shortcut: "Ctrl+H", HotKey: 'R', Action: ActionReplace},
 					{Label: "Go to Line", Shortcut: "Ctrl+G", HotKey: 'G', Action: ActionGoToLine},
+					{Label: "Center Cursor", Shortcut: "Ctrl+L", HotKey: 'C', Action: ActionCenterCursor},
+					{Label: "Go to Symbol...", Shortcut: "Ctrl+Shift+O", HotKey: 'S', Action: ActionGoToSymbol},
+					{Label: "Go to Definition", Shortcut: "F12", HotKey: 'D', Action: ActionGoToDefinition},
+					{Label: "Cursor Info", Shortcut: "", HotKey: 'I', Action: ActionCursorInfo},
+					{Label: "Annotate Line...", Shortcut: "", HotKey: 'A', Action: ActionAnnotateLine},
+					{Label: "Annotations...", Shortcut: "", HotKey: 'L', Action: ActionAnnotations},
 				},
 			},
 			{
 				Label: "Options",
 				Items: []MenuItem{
 					{Label: "[ ] Word Wrap", Shortcut: "", HotKey: 'W', Action: ActionWordWrap},
-					{Label: "[ ] Line Numbers", Shortcut: "Ctrl+L", HotKey: 'L', Action: ActionLineNumbers},
+					{Label: "[ ] Line Numbers", Shortcut: "Alt+N", HotKey: 'L', Action: ActionLineNumbers},
 					{Label: "[x] Syntax Highlight", Shortcut: "", HotKey: 'S', Action: ActionSyntaxHighlight},
+					{Label: "[ ] Auto-Pair Brackets", Shortcut: "", HotKey: 'P', Action: ActionAutoPair},
+					{Label: "[x] Highlight Occurrences", Shortcut: "", HotKey: 'O', Action: ActionHighlightWord},
 					{Label: "[ ] Scrollbar", Shortcut: "", HotKey: 'B', Action: ActionScrollbar},
 					{Label: "[ ] Minimap", Shortcut: "", HotKey: 'M', Action: ActionMinimap},
+					{Label: "Minimap Heat: Off", Shortcut: "", HotKey: 'H', Action: ActionMinimapHeat},
+					{Label: "[ ] File Tree", Shortcut: "Ctrl+B", HotKey: 'F', Action: ActionFileTree},
+					{Label: "[ ] Hex View", Shortcut: "", HotKey: 'X', Action: ActionHexView},
+					{Label: "[ ] Follow File", Shortcut: "", HotKey: 'E', Action: ActionFollowFile},
 					{Label: "Theme...", Shortcut: "", HotKey: 'T', Action: ActionTheme},
 					{Label: "Keybindings...", Shortcut: "", HotKey: 'K', Action: ActionKeybindings},
+					{Label: "Indentation...", Shortcut: "", HotKey: 'I', Action: ActionIndentation},
 					{Label: "Settings...", Shortcut: "", HotKey: 'G', Action: ActionSettings},
 				},
 			},
@@ -160,6 +254,7 @@ func NewMenuBar(styles Styles) *MenuBar {
 				Label: "Help",
 				Items: []MenuItem{
 					{Label: "Help", Shortcut: "F1", HotKey: 'H', Action: ActionHelp},
+					{Label: "View Log", Shortcut: "", HotKey: 'L', Action: ActionViewLog},
 					{Label: "About", Shortcut: "", HotKey: 'A', Action: ActionAbout},
 				},
 			},
@@ -181,28 +276,61 @@ func (m *MenuBar) UpdateShortcuts(kb *config.KeybindingsConfig) {
 	// Map MenuAction to keybinding config field
 	actionToBinding := map[MenuAction]config.KeyBinding{
 		// File menu
-		ActionNew:         kb.New,
-		ActionOpen:        kb.Open,
-		ActionRecentFiles: kb.RecentFiles,
-		ActionClose:       kb.Close,
-		ActionSave:        kb.SaveFile,
-		ActionSaveAs:      kb.SaveAs,
-		ActionExit:        kb.Quit,
+		ActionNew:          kb.New,
+		ActionOpen:         kb.Open,
+		ActionRecentFiles:  kb.RecentFiles,
+		ActionQuickOpen:    kb.QuickOpen,
+		ActionClose:        kb.Close,
+		ActionReopenClosed: kb.ReopenClosed,
+		ActionSave:         kb.SaveFile,
+		ActionSaveAs:       kb.SaveAs,
+		ActionPrint:        kb.Print,
+		ActionExit:         kb.Quit,
+		// Buffers menu
+		ActionBufferList:  kb.BufferList,
+		ActionSaveAll:     kb.SaveAll,
+		ActionCloseAll:    kb.CloseAll,
+		ActionCloseOthers: kb.CloseOthers,
 		// Edit menu
-		ActionUndo:      kb.Undo,
-		ActionRedo:      kb.Redo,
-		ActionCut:       kb.Cut,
-		ActionCopy:      kb.Copy,
-		ActionPaste:     kb.Paste,
-		ActionCutLine:   kb.CutLine,
-		ActionSelectAll: kb.SelectAll,
+		ActionUndo:            kb.Undo,
+		ActionRedo:            kb.Redo,
+		ActionUndoHistory:     kb.UndoHistory,
+		ActionEarlier:         kb.Earlier,
+		ActionLater:           kb.Later,
+		ActionCut:             kb.Cut,
+		ActionCopy:            kb.Copy,
+		ActionCopyAppend:      kb.CopyAppend,
+		ActionPaste:           kb.Paste,
+		ActionPasteHistory:    kb.PasteHistory,
+		ActionCopyToRegister:  kb.CopyToRegister,
+		ActionPasteRegister:   kb.PasteRegister,
+		ActionInsertBuffer:    kb.InsertBuffer,
+		ActionInsertText:      kb.InsertText,
+		ActionCutLine:         kb.CutLine,
+		ActionSelectAll:       kb.SelectAll,
+		ActionUppercase:       kb.Uppercase,
+		ActionLowercase:       kb.Lowercase,
+		ActionTitleCase:       kb.TitleCase,
+		ActionToggleCase:      kb.ToggleCase,
+		ActionSortLines:       kb.SortLines,
+		ActionReverseLines:    kb.ReverseLines,
+		ActionUniqueLines:     kb.UniqueLines,
+		ActionIndentToSpaces:  kb.IndentToSpaces,
+		ActionIndentToTabs:    kb.IndentToTabs,
+		ActionIncrementNumber: kb.IncrementNumber,
+		ActionDecrementNumber: kb.DecrementNumber,
 		// Search menu
-		ActionFind:     kb.Find,
-		ActionFindNext: kb.FindNext,
-		ActionReplace:  kb.Replace,
-		ActionGoToLine: kb.GoToLine,
+		ActionFind:           kb.Find,
+		ActionFindNext:       kb.FindNext,
+		ActionReplace:        kb.Replace,
+		ActionGoToLine:       kb.GoToLine,
+		ActionCenterCursor:   kb.CenterCursor,
+		ActionGoToSymbol:     kb.GoToSymbol,
+		ActionGoToDefinition: kb.GoToDefinition,
+		ActionCursorInfo:     kb.CursorInfo,
 		// Options menu
 		ActionLineNumbers: kb.ToggleLineNumbers,
+		ActionFileTree:    kb.ToggleFileTree,
 		// Help menu
 		ActionHelp: kb.Help,
 	}
@@ -396,14 +524,21 @@ func (m *MenuBar) SetBuffers(names []string, activeIdx int) {
 		return
 	}
 
-	// Build new items list
-	var items []MenuItem
 	actions := []MenuAction{
 		ActionBuffer1, ActionBuffer2, ActionBuffer3, ActionBuffer4, ActionBuffer5,
 		ActionBuffer6, ActionBuffer7, ActionBuffer8, ActionBuffer9, ActionBuffer10,
 		ActionBuffer11, ActionBuffer12, ActionBuffer13, ActionBuffer14, ActionBuffer15,
 		ActionBuffer16, ActionBuffer17, ActionBuffer18, ActionBuffer19, ActionBuffer20,
 	}
+
+	// Build new items list, keeping the buffer commands above the buffers
+	var items []MenuItem
+	for _, item := range m.menus[buffersMenuIdx].Items {
+		if item.Action != ActionNone && !slices.Contains(actions, item.Action) {
+			items = append(items, item)
+		}
+	}
+	commands := len(items)
 	hotkeys := []rune{'1', '2', '3', '4', '5', '6', '7', '8', '9', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
 
 	for i, name := range names {
@@ -425,8 +560,8 @@ func (m *MenuBar) SetBuffers(names []string, activeIdx int) {
 		})
 	}
 
-	if len(items) == 0 {
-		items = []MenuItem{{Label: "(no buffers)", Shortcut: "", HotKey: 0, Action: ActionNone, Disabled: true}}
+	if len(items) == commands {
+		items = append(items, MenuItem{Label: "(no buffers)", Shortcut: "", HotKey: 0, Action: ActionNone, Disabled: true})
 	}
 
 	m.menus[buffersMenuIdx].Items = items
@@ -5,7 +5,7 @@ import (
 )
 
 // MinimapController is an interface for minimap renderers.
-// Both the braille-based MinimapRenderer and KittyMinimapRenderer implement this.
+// The braille-based MinimapRenderer and the Kitty, Sixel and iTerm2 image renderers implement this.
 type MinimapController interface {
 	ColumnRenderer
 	SetStyles(styles Styles)
@@ -14,8 +14,19 @@ type MinimapController interface {
 	Toggle() bool
 	GetMetrics(viewportHeight int, state *RenderState) MinimapMetrics
 	RowToVisualLine(row int, metrics MinimapMetrics) int
-	ClearImage() string                                                              // Returns escape sequence to clear graphics (Kitty only, empty for braille)
-	GetKittySequence(width, height, xOffset, yOffset int, state *RenderState) string // Kitty graphics overlay
+	ClearImage() string                                                              // Returns escape sequence to clear graphics (Kitty only, empty otherwise)
+	GetKittySequence(width, height, xOffset, yOffset int, state *RenderState) string // Kitty, Sixel or iTerm2 graphics overlay
+}
+
+// ImageInTextCells reports whether a minimap's image is drawn into the text
+// cells (Sixel, iTerm2) rather than on a layer of its own (Kitty), so that
+// rewriting a line of text erases the part of the image on it.
+func ImageInTextCells(m MinimapController) bool {
+	switch m.(type) {
+	case *SixelMinimapRenderer, *ITermMinimapRenderer:
+		return true
+	}
+	return false
 }
 
 // MinimapRenderer renders a braille-based minimap of the document.
@@ -101,8 +112,8 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 
 	// Generate visual lines (respecting word wrap)
 	// Each visual line is what actually displays on one screen row
-	textWidth := 80 // Approximate text column width for wrapping
-	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth)
+	textWidth := state.wrapWidth()
+	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth, state.TabWidth)
 	totalVisualLines := len(visualLines)
 	if totalVisualLines == 0 {
 		totalVisualLines = 1
@@ -113,6 +124,12 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 	// Each braille char represents 4 visual lines
 	minimapHeight := (totalVisualLines + 3) / 4
 
+	// Map visual lines back to buffer lines for heatmap tinting
+	var owners []int
+	if state.LineHeat != nil || state.ChangedLines != nil {
+		owners = visualLineOwners(state.Lines, state.WordWrap, textWidth, state.TabWidth)
+	}
+
 	// Viewport indicator range (in visual lines)
 	visibleStart := state.ScrollY
 	visibleEnd := state.ScrollY + height
@@ -121,6 +138,7 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 	ui := r.styles.Theme.UI
 	indicatorColor := ColorToANSIFg(ui.MinimapIndicator)
 	textColor := ColorToANSIFg(ui.MinimapText)
+	changeColor := ColorToANSIFg(ui.ChangeMarker)
 	resetCode := "\033[0m"
 
 	rows := make([]string, height)
@@ -145,6 +163,16 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 		var sb strings.Builder
 
 		minimapRow := row + minimapScrollOffset
+
+		// Last row shows the label (e.g. reading time) when there is one
+		if state.MinimapLabel != "" && row == height-1 && height > 1 {
+			label := " " + state.MinimapLabel
+			if len(label) > width {
+				label = label[:width]
+			}
+			rows[row] = indicatorColor + label + resetCode + strings.Repeat(" ", width-len(label))
+			continue
+		}
 		if minimapRow >= minimapHeight {
 			// Past end of minimap - empty row
 			sb.WriteString(strings.Repeat(" ", width))
@@ -181,7 +209,13 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 			}
 		}
 
-		sb.WriteString(textColor)
+		rowColor := textColor
+		if state.LineHeat != nil {
+			if heat := rowHeat(state, owners, visualLineStart, visualLineEnd); heat >= 0 {
+				rowColor = heatColor(heat)
+			}
+		}
+		sb.WriteString(rowColor)
 		tabWidth := state.TabWidth
 		if tabWidth <= 0 {
 			tabWidth = 4
@@ -190,8 +224,12 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 		sb.WriteString(braille)
 		sb.WriteString(resetCode)
 
-		// Right padding
-		sb.WriteString(" ")
+		// Right padding, or a strip beside unsaved edits
+		if rowChanged(state, owners, visualLineStart, visualLineEnd) {
+			sb.WriteString(changeColor + changeStrip + resetCode)
+		} else {
+			sb.WriteString(" ")
+		}
 
 		rows[row] = sb.String()
 	}
@@ -200,7 +238,7 @@ func (r *MinimapRenderer) Render(width, height int, state *RenderState) []string
 }
 
 // generateVisualLines converts buffer lines to visual lines respecting word wrap.
-func (r *MinimapRenderer) generateVisualLines(lines []string, wordWrap bool, textWidth int) []string {
+func (r *MinimapRenderer) generateVisualLines(lines []string, wordWrap bool, textWidth, tabWidth int) []string {
 	if !wordWrap || textWidth <= 0 {
 		// No word wrap - visual lines = buffer lines
 		return lines
@@ -208,20 +246,7 @@ func (r *MinimapRenderer) generateVisualLines(lines []string, wordWrap bool, tex
 
 	var visualLines []string
 	for _, line := range lines {
-		lineRunes := []rune(line)
-		if len(lineRunes) == 0 {
-			visualLines = append(visualLines, "")
-			continue
-		}
-		// Wrap long lines
-		for len(lineRunes) > 0 {
-			end := textWidth
-			if end > len(lineRunes) {
-				end = len(lineRunes)
-			}
-			visualLines = append(visualLines, string(lineRunes[:end]))
-			lineRunes = lineRunes[end:]
-		}
+		visualLines = append(visualLines, WrapLine(line, textWidth, tabWidth)...)
 	}
 	if len(visualLines) == 0 {
 		visualLines = []string{""}
@@ -347,8 +372,8 @@ type MinimapMetrics struct {
 // GetMetrics calculates minimap metrics for a given state.
 func (r *MinimapRenderer) GetMetrics(viewportHeight int, state *RenderState) MinimapMetrics {
 	// Generate visual lines to get accurate count
-	textWidth := 80
-	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth)
+	textWidth := state.wrapWidth()
+	visualLines := r.generateVisualLines(state.Lines, state.WordWrap, textWidth, state.TabWidth)
 	totalVisualLines := len(visualLines)
 	if totalVisualLines == 0 {
 		totalVisualLines = 1
@@ -63,8 +63,9 @@ func (s *Scrollbar) SetStyles(styles Styles) {
 
 // Render renders the scrollbar as a slice of strings, one per viewport row
 // viewportStart is the first visible line, viewportHeight is the number of visible lines,
-// totalLines is the total number of lines in the document
-func (s *Scrollbar) Render(viewportStart, viewportHeight, totalLines int) []string {
+// totalLines is the total number of lines in the document, and changed marks
+// rows covering unsaved edits (nil for none)
+func (s *Scrollbar) Render(viewportStart, viewportHeight, totalLines int, changed []bool) []string {
 	if !s.enabled || s.height <= 0 {
 		return nil
 	}
@@ -75,6 +76,7 @@ func (s *Scrollbar) Render(viewportStart, viewportHeight, totalLines int) []stri
 	// Get ANSI colors
 	trackColor := ColorToANSIFg(ui.ScrollbarTrack)
 	thumbColor := ColorToANSIFg(ui.ScrollbarThumb)
+	changeColor := ColorToANSIFg(ui.ChangeMarker)
 
 	// Handle edge cases
 	if totalLines <= 0 {
@@ -143,11 +145,19 @@ func (s *Scrollbar) Render(viewportStart, viewportHeight, totalLines int) []stri
 	for row := 0; row < s.height; row++ {
 		var sb strings.Builder
 
-		if row >= thumbStart && row < thumbEnd {
+		// Changed rows keep the thumb/track glyph but take the marker color
+		inThumb := row >= thumbStart && row < thumbEnd
+		switch {
+		case row < len(changed) && changed[row]:
+			sb.WriteString(changeColor)
+		case inThumb:
 			sb.WriteString(thumbColor)
+		default:
+			sb.WriteString(trackColor)
+		}
+		if inThumb {
 			sb.WriteString("┃")
 		} else {
-			sb.WriteString(trackColor)
 			sb.WriteString("│")
 		}
 
@@ -187,7 +197,7 @@ func (a *ScrollbarColumnAdapter) Render(width, height int, state *RenderState) [
 		totalLines = state.TotalVisualLines
 	}
 
-	return a.scrollbar.Render(state.ScrollY, height, totalLines)
+	return a.scrollbar.Render(state.ScrollY, height, totalLines, changedRows(state.ChangedLines, height))
 }
 
 // RowToLine converts a scrollbar row to the corresponding visual line index
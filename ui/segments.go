@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// StatusSegment is a piece of state shown on the right of the status bar.
+// Features register their own segments (a git branch, an LSP server's
+// status) instead of adding fields to StatusBar.
+type StatusSegment struct {
+	Name     string        // Unique name, used to update or remove the segment
+	Priority int           // Higher priorities sit further left and are dropped last when space runs out
+	Text     string        // Text to show, replaced by SetSegmentText
+	Render   func() string // Computes the text on every redraw instead of Text (optional)
+}
+
+// segmentSeparator goes after each segment, before the built-in counts
+const segmentSeparator = " | "
+
+// RegisterSegment adds a segment, replacing any registered under the same name
+func (s *StatusBar) RegisterSegment(seg StatusSegment) {
+	for i := range s.segments {
+		if s.segments[i].Name == seg.Name {
+			s.segments[i] = seg
+			return
+		}
+	}
+	s.segments = append(s.segments, seg)
+	// Keep registration order among equal priorities
+	sort.SliceStable(s.segments, func(i, j int) bool {
+		return s.segments[i].Priority > s.segments[j].Priority
+	})
+}
+
+// UnregisterSegment removes the named segment
+func (s *StatusBar) UnregisterSegment(name string) {
+	for i := range s.segments {
+		if s.segments[i].Name == name {
+			s.segments = append(s.segments[:i], s.segments[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetSegmentText updates a segment's text. Returns false if no segment has that name.
+func (s *StatusBar) SetSegmentText(name, text string) bool {
+	for i := range s.segments {
+		if s.segments[i].Name == name {
+			s.segments[i].Text = text
+			return true
+		}
+	}
+	return false
+}
+
+// shownSegment is where a segment was drawn in the last View, for clicks
+type shownSegment struct {
+	name       string
+	start, end int // Cells covered, end exclusive
+}
+
+// segmentsView renders the non-empty segments in priority order, dropping
+// the lowest priorities until the rest fit in width cells
+func (s *StatusBar) segmentsView(width int) string {
+	view, _ := s.visibleSegments(width)
+	return view
+}
+
+// visibleSegments renders the segments as segmentsView does and also
+// returns the names of those shown
+func (s *StatusBar) visibleSegments(width int) (string, []string) {
+	var texts, names []string
+	for _, seg := range s.segments {
+		text := seg.Text
+		if seg.Render != nil {
+			text = seg.Render()
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			texts = append(texts, text)
+			names = append(names, seg.Name)
+		}
+	}
+	for len(texts) > 0 {
+		view := strings.Join(texts, segmentSeparator) + segmentSeparator
+		if runewidth.StringWidth(view) <= width {
+			return view, names
+		}
+		texts, names = texts[:len(texts)-1], names[:len(names)-1]
+	}
+	return "", nil
+}
+
+// placeSegments records where the segments in view start at cell x, so
+// SegmentAt can tell which one a click hit
+func (s *StatusBar) placeSegments(x int, view string, names []string) {
+	s.shown = s.shown[:0]
+	for i, text := range strings.Split(view, segmentSeparator)[:len(names)] {
+		w := runewidth.StringWidth(text)
+		s.shown = append(s.shown, shownSegment{name: names[i], start: x, end: x + w})
+		x += w + len(segmentSeparator)
+	}
+}
+
+// SegmentAt returns the name of the segment drawn at cell x in the last
+// View, or "" if x is not on one
+func (s *StatusBar) SegmentAt(x int) string {
+	for _, seg := range s.shown {
+		if x >= seg.start && x < seg.end {
+			return seg.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+)
+
+func TestStatusSegments(t *testing.T) {
+	s := NewStatusBar(NewStyles(config.DefaultTheme()))
+	s.SetWidth(80)
+	s.RegisterSegment(StatusSegment{Name: "lsp", Priority: 1, Text: "gopls"})
+	s.RegisterSegment(StatusSegment{Name: "git", Priority: 5, Text: "main"})
+	calls := 0
+	s.RegisterSegment(StatusSegment{Name: "clock", Priority: 3, Render: func() string {
+		calls++
+		return "12:00"
+	}})
+
+	if got := s.segmentsView(80); got != "main | 12:00 | gopls | " {
+		t.Errorf("segmentsView() = %q", got)
+	}
+	if calls != 1 {
+		t.Errorf("Render called %d times, want 1", calls)
+	}
+
+	// Lowest priorities drop first when space runs out
+	if got := s.segmentsView(len("main | 12:00 | ")); got != "main | 12:00 | " {
+		t.Errorf("narrow segmentsView() = %q", got)
+	}
+
+	if !s.SetSegmentText("git", "feature") || s.SetSegmentText("missing", "x") {
+		t.Error("SetSegmentText() should only update registered segments")
+	}
+	s.SetSegmentText("lsp", "")
+	s.UnregisterSegment("clock")
+	if got := s.segmentsView(80); got != "feature | " {
+		t.Errorf("segmentsView() after updates = %q", got)
+	}
+	if !strings.Contains(s.View(), "feature | W:0") {
+		t.Errorf("View() missing segment: %q", s.View())
+	}
+}
+
+func TestSegmentAt(t *testing.T) {
+	s := NewStatusBar(NewStyles(config.DefaultTheme()))
+	s.SetWidth(80)
+	s.RegisterSegment(StatusSegment{Name: "git", Priority: 5, Text: "main"})
+	s.RegisterSegment(StatusSegment{Name: "indent", Priority: 1, Text: "Spaces: 2"})
+	view := stripANSI(s.View())
+	for _, tt := range []struct{ text, name string }{{"main", "git"}, {"Spaces: 2", "indent"}} {
+		x := strings.Index(view, tt.text)
+		if got := s.SegmentAt(x); got != tt.name {
+			t.Errorf("SegmentAt(%d) = %q, want %q", x, got, tt.name)
+		}
+		if got := s.SegmentAt(x + len(tt.text)); got != "" {
+			t.Errorf("SegmentAt just past %q = %q, want none", tt.text, got)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SixelMinimapRenderer draws the pixel minimap as a Sixel image, for
+// terminals without the Kitty graphics protocol (xterm, foot, mlterm,
+// WezTerm). It shares the Kitty renderer's layout and click handling; only
+// the encoding differs.
+//
+// Sixel images aren't scaled by the terminal, so the image is resampled to
+// the column's size in pixels. They aren't a separate layer either: text
+// written over the column erases them, so there is nothing to delete.
+type SixelMinimapRenderer struct {
+	*KittyMinimapRenderer
+	cellWidth  int // Pixel size of a character cell
+	cellHeight int
+}
+
+// Default cell size when the terminal doesn't report one
+const (
+	sixelDefaultCellWidth  = 8
+	sixelDefaultCellHeight = 16
+)
+
+// sixelMaxColors is the most color registers an image uses; VT340-class
+// terminals guarantee 256
+const sixelMaxColors = 256
+
+// NewSixelMinimapRenderer creates a Sixel minimap renderer for character
+// cells of the given pixel size (0 for a typical 8x16).
+func NewSixelMinimapRenderer(styles Styles, cellWidth, cellHeight int) *SixelMinimapRenderer {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		cellWidth, cellHeight = sixelDefaultCellWidth, sixelDefaultCellHeight
+	}
+	return &SixelMinimapRenderer{
+		KittyMinimapRenderer: NewKittyMinimapRenderer(styles, true),
+		cellWidth:            cellWidth,
+		cellHeight:           cellHeight,
+	}
+}
+
+// GetKittySequence returns the Sixel image of the minimap, positioned at
+// the minimap column like the Kitty overlay.
+func (r *SixelMinimapRenderer) GetKittySequence(width, height, xOffset, yOffset int, state *RenderState) string {
+	if !r.enabled || state == nil || width <= 0 || height <= 0 {
+		return ""
+	}
+	pixels, srcWidth, srcHeight := r.minimapPixels(height, state)
+
+	// Bands are 6 pixels tall; round down so the last band can't spill onto
+	// the status bar
+	imgWidth := width * r.cellWidth
+	imgHeight := height * r.cellHeight / 6 * 6
+	scaled := scalePixels(pixels, srcWidth, srcHeight, imgWidth, imgHeight)
+
+	var sb strings.Builder
+	sb.WriteString("\033[s")
+	sb.WriteString(fmt.Sprintf("\033[%d;%dH", yOffset+1, xOffset+1))
+	sb.WriteString(encodeSixel(scaled, imgWidth, imgHeight))
+	sb.WriteString("\033[u")
+	return sb.String()
+}
+
+// ClearImage returns nothing: redrawing the text erases a Sixel image.
+func (r *SixelMinimapRenderer) ClearImage() string {
+	return ""
+}
+
+// scalePixels resamples RGBA pixels to a new size, nearest neighbor
+func scalePixels(pixels []byte, srcWidth, srcHeight, width, height int) []byte {
+	out := make([]byte, width*height*4)
+	for y := range height {
+		sy := y * srcHeight / height
+		for x := range width {
+			sx := x * srcWidth / width
+			copy(out[(y*width+x)*4:(y*width+x)*4+4], pixels[(sy*srcWidth+sx)*4:])
+		}
+	}
+	return out
+}
+
+// encodeSixel encodes RGBA pixels (alpha ignored) as a Sixel image. Each
+// distinct color gets a register; past sixelMaxColors, colors are reduced
+// to a 6x6x6 cube first.
+func encodeSixel(pixels []byte, width, height int) string {
+	palette, index := sixelPalette(pixels)
+
+	var sb strings.Builder
+	// P2=1: pixels left at zero stay transparent
+	sb.WriteString("\033P0;1q")
+	sb.WriteString(fmt.Sprintf("\"1;1;%d;%d", width, height))
+	for i, c := range palette {
+		sb.WriteString(fmt.Sprintf("#%d;2;%d;%d;%d", i, int(c[0])*100/255, int(c[1])*100/255, int(c[2])*100/255))
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		// Which colors appear in this band
+		used := make([]bool, len(palette))
+		for y := band; y < min(band+6, height); y++ {
+			for x := range width {
+				used[index[y*width+x]] = true
+			}
+		}
+		first := true
+		for c := range palette {
+			if !used[c] {
+				continue
+			}
+			for x := range width {
+				bits := byte(0)
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if index[(band+dy)*width+x] == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			if !first {
+				sb.WriteByte('$') // Back to the start of the band for the next color
+			}
+			first = false
+			sb.WriteString(fmt.Sprintf("#%d", c))
+			writeSixelRuns(&sb, row)
+		}
+		sb.WriteByte('-')
+	}
+	sb.WriteString("\033\\")
+	return sb.String()
+}
+
+// sixelPalette assigns each pixel a color register
+func sixelPalette(pixels []byte) ([][3]byte, []int) {
+	quantize := false
+	seen := make(map[[3]byte]bool)
+	for i := 0; i < len(pixels); i += 4 {
+		seen[[3]byte{pixels[i], pixels[i+1], pixels[i+2]}] = true
+		if len(seen) > sixelMaxColors {
+			quantize = true
+			break
+		}
+	}
+
+	var palette [][3]byte
+	registers := make(map[[3]byte]int)
+	index := make([]int, len(pixels)/4)
+	for i := 0; i < len(pixels); i += 4 {
+		c := [3]byte{pixels[i], pixels[i+1], pixels[i+2]}
+		if quantize {
+			for j := range c {
+				c[j] = byte((int(c[j]) + 25) / 51 * 51)
+			}
+		}
+		reg, ok := registers[c]
+		if !ok {
+			reg = len(palette)
+			registers[c] = reg
+			palette = append(palette, c)
+		}
+		index[i/4] = reg
+	}
+	return palette, index
+}
+
+// writeSixelRuns writes sixel characters, run-length encoding repeats
+func writeSixelRuns(sb *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			sb.WriteString(fmt.Sprintf("!%d%c", n, row[i]))
+		} else {
+			sb.WriteString(strings.Repeat(string(row[i]), n))
+		}
+		i = j
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeSixel(t *testing.T) {
+	// 5x7 image: black with a white top row, so two bands and two colors
+	pixels := make([]byte, 5*7*4)
+	for x := range 5 {
+		copy(pixels[x*4:], []byte{255, 255, 255, 255})
+	}
+	got := encodeSixel(pixels, 5, 7)
+
+	want := "\033P0;1q\"1;1;5;7#0;2;100;100;100#1;2;0;0;0" +
+		"#0!5@$#1!5}-" + // Band 1: row 0 white, rows 1-5 black
+		"#1!5@-" + // Band 2: row 6 black
+		"\033\\"
+	if got != want {
+		t.Errorf("encodeSixel =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteSixelRuns(t *testing.T) {
+	var sb strings.Builder
+	writeSixelRuns(&sb, []byte("???~~~~~@"))
+	if got := sb.String(); got != "???!5~@" {
+		t.Errorf("writeSixelRuns = %q", got)
+	}
+}
+
+func TestSixelMinimapSequence(t *testing.T) {
+	r := NewSixelMinimapRenderer(Styles{}, 0, 0)
+	state := &RenderState{Lines: []string{"package main", "", "func main() {}"}, TabWidth: 4}
+	if got := r.GetKittySequence(8, 3, 70, 1, state); got != "" {
+		t.Errorf("disabled minimap drew %q", got)
+	}
+
+	r.SetEnabled(true)
+	got := r.GetKittySequence(8, 3, 70, 1, state)
+	// 8x3 cells of the default 8x16 pixels
+	if !strings.HasPrefix(got, "\033[s\033[2;71H\033P0;1q\"1;1;64;48#") || !strings.HasSuffix(got, "\033\\\033[u") {
+		t.Errorf("sequence = %q", got)
+	}
+	if bands := strings.Count(got, "-"); bands != 8 {
+		t.Errorf("%d bands, want 8 for 48 pixel rows", bands)
+	}
+	if r.ClearImage() != "" {
+		t.Error("a Sixel image needs no delete sequence")
+	}
+}
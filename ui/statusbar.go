@@ -4,12 +4,35 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// DocState is the save state of the active buffer, shown as a glyph before
+// the filename
+type DocState int
+
+const (
+	DocSaved     DocState = iota // Matches the file on disk (no glyph)
+	DocModified                  // Unsaved edits
+	DocSaving                    // A save is waiting for the user to answer a question
+	DocAutosaved                 // Last written by auto-save rather than the user
+	DocConflict                  // The file changed on disk since it was loaded or saved
+)
+
+// docStateGlyphs and asciiDocStateGlyphs are the markers for each DocState
+var (
+	docStateGlyphs      = [...]string{DocModified: "*", DocSaving: "…", DocAutosaved: "↻", DocConflict: "!"}
+	asciiDocStateGlyphs = [...]string{DocModified: "*", DocSaving: "~", DocAutosaved: "@", DocConflict: "!"}
 )
 
 // StatusBar represents the bottom status bar
 type StatusBar struct {
 	filename          string
-	modified          bool
+	displayName       string // Name shown instead of the base name (e.g. "dirA/config.toml")
+	docState          DocState
+	ascii             bool // Use ASCII glyphs
+	readOnly          bool
 	line              int
 	col               int
 	totalLines        int
@@ -17,22 +40,28 @@ type StatusBar struct {
 	encodingSupported bool // Whether the encoding is fully supported
 	wordCount         int
 	charCount         int
+	selLines          int    // Lines the selection covers; 0 = nothing selected
+	lineLength        int    // Cursor line's width in columns; -1 = hidden
 	message           string // Temporary message to display
 	messageType       string // "info", "error", "success"
 	width             int
 	styles            Styles
-	bufferIndex       int // Current buffer index (0-based)
-	bufferCount       int // Total number of open buffers
+	bufferIndex       int             // Current buffer index (0-based)
+	bufferCount       int             // Total number of open buffers
+	modeIndicator     string          // Editing mode label (e.g. "-- INSERT --" for vi)
+	segments          []StatusSegment // Registered segments, highest priority first
+	shown             []shownSegment  // Segments drawn by the last View, left to right
 }
 
 // NewStatusBar creates a new status bar
 func NewStatusBar(styles Styles) *StatusBar {
 	return &StatusBar{
 		filename:          "",
-		modified:          false,
+		docState:          DocSaved,
 		line:              1,
 		col:               1,
 		totalLines:        1,
+		lineLength:        -1,
 		encoding:          "UTF-8",
 		encodingSupported: true,
 		styles:            styles,
@@ -44,9 +73,46 @@ func (s *StatusBar) SetFilename(filename string) {
 	s.filename = filename
 }
 
-// SetModified sets whether the buffer has been modified
-func (s *StatusBar) SetModified(modified bool) {
-	s.modified = modified
+// SetDisplayName overrides the base name shown for the current file, so
+// buffers sharing a base name can be told apart
+func (s *StatusBar) SetDisplayName(name string) {
+	s.displayName = name
+}
+
+// SetDocState sets the save state shown before the filename
+func (s *StatusBar) SetDocState(state DocState) {
+	s.docState = state
+}
+
+// SetASCII selects ASCII glyphs for terminals without Unicode support
+func (s *StatusBar) SetASCII(ascii bool) {
+	s.ascii = ascii
+}
+
+// docStateView returns the glyph for the current DocState and its color
+func (s *StatusBar) docStateView() (glyph, color string) {
+	glyphs := docStateGlyphs
+	if s.ascii {
+		glyphs = asciiDocStateGlyphs
+	}
+	glyph = glyphs[s.docState]
+	ui := s.styles.Theme.UI
+	switch s.docState {
+	case DocModified:
+		color = ColorToANSIFg(ui.StatusAccent) + "\033[1m" // Bold
+	case DocSaving:
+		color = ColorToANSIFg(ui.DisabledFg)
+	case DocAutosaved:
+		color = ColorToANSIFg(ui.StatusAccent)
+	case DocConflict:
+		color = ColorToANSIFg(ui.ErrorFg) + "\033[1m"
+	}
+	return glyph, color
+}
+
+// SetReadOnly sets whether the file is write-protected
+func (s *StatusBar) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
 }
 
 // SetPosition sets the cursor position (1-indexed for display)
@@ -72,12 +138,32 @@ func (s *StatusBar) SetCounts(words, chars int) {
 	s.charCount = chars
 }
 
+// SetSelectionCounts shows the selection's word, character and line counts
+// in place of the buffer's; lines 0 goes back to the buffer's
+func (s *StatusBar) SetSelectionCounts(words, chars, lines int) {
+	if lines > 0 {
+		s.wordCount, s.charCount = words, chars
+	}
+	s.selLines = lines
+}
+
+// SetLineLength shows the cursor line's width in columns after the
+// position; -1 hides it
+func (s *StatusBar) SetLineLength(length int) {
+	s.lineLength = length
+}
+
 // SetMessage sets a temporary message to display
 func (s *StatusBar) SetMessage(message, msgType string) {
 	s.message = message
 	s.messageType = msgType
 }
 
+// Message returns the temporary message and its type
+func (s *StatusBar) Message() (string, string) {
+	return s.message, s.messageType
+}
+
 // ClearMessage clears the temporary message
 func (s *StatusBar) ClearMessage() {
 	s.message = ""
@@ -100,6 +186,11 @@ func (s *StatusBar) SetBufferInfo(index, count int) {
 	s.bufferCount = count
 }
 
+// SetModeIndicator sets the editing mode label shown after the filename
+func (s *StatusBar) SetModeIndicator(indicator string) {
+	s.modeIndicator = indicator
+}
+
 // View renders the status bar
 func (s *StatusBar) View() string {
 	var sb strings.Builder
@@ -114,20 +205,30 @@ func (s *StatusBar) View() string {
 	// Start with status bar colors
 	sb.WriteString(normalColor)
 
-	// Left side: modified indicator + filename
-	if s.modified {
-		// Accent color for modified indicator
-		sb.WriteString(accentColor + "*" + resetToNormal)
+	// Left side: save state glyph + filename
+	stateGlyph, stateColor := s.docStateView()
+	if stateGlyph != "" {
+		sb.WriteString(stateColor + stateGlyph + resetToNormal)
 	}
 
 	var filename string
-	if s.filename == "" {
+	switch {
+	case s.displayName != "":
+		filename = s.displayName
+	case s.filename == "":
 		filename = "[Untitled]"
-	} else {
+	default:
 		filename = filepath.Base(s.filename)
 	}
 	sb.WriteString(filename)
 
+	// Read-only marker
+	readOnlyIndicator := ""
+	if s.readOnly {
+		readOnlyIndicator = " [RO]"
+		sb.WriteString(errorColor + readOnlyIndicator + resetToNormal)
+	}
+
 	// Buffer indicator (only show if multiple buffers)
 	bufferIndicator := ""
 	if s.bufferCount > 1 {
@@ -135,24 +236,42 @@ func (s *StatusBar) View() string {
 		sb.WriteString(bufferIndicator)
 	}
 
+	// Mode indicator (e.g. vi mode)
+	modeIndicator := ""
+	if s.modeIndicator != "" {
+		modeIndicator = " " + s.modeIndicator
+		sb.WriteString(accentColor + modeIndicator + resetToNormal)
+	}
+
 	// Right side: word count, char count, line:col, encoding
 	// Build encoding display (may need color)
 	encodingDisplay := s.encoding
-	rightBase := fmt.Sprintf("W:%d C:%d | Ln %d, Col %d | ", s.wordCount, s.charCount, s.line, s.col)
+	counts := fmt.Sprintf("W:%d C:%d", s.wordCount, s.charCount)
+	if s.selLines > 0 {
+		counts = fmt.Sprintf("Sel W:%d C:%d L:%d", s.wordCount, s.charCount, s.selLines)
+	}
+	position := fmt.Sprintf("Ln %d, Col %d", s.line, s.col)
+	if s.lineLength >= 0 {
+		position += fmt.Sprintf(", Len %d", s.lineLength)
+	}
+	rightBase := counts + " | " + position + " | "
 	right := rightBase + encodingDisplay
 
 	// Calculate spacing
-	leftLen := len(filename) + len(bufferIndicator)
-	if s.modified {
-		leftLen++
-	}
-	rightLen := len(right)
+	leftLen := len(filename) + len(readOnlyIndicator) + len(bufferIndicator) + len(modeIndicator)
+	leftLen += runewidth.StringWidth(stateGlyph)
+
+	// Registered segments go before the counts, as far as space allows
+	segments, names := s.visibleSegments(s.width - leftLen - len(right))
+	rightBase = segments + rightBase
+	rightLen := len(right) + runewidth.StringWidth(segments)
 	centerLen := len(s.message)
 
 	availableSpace := s.width - leftLen - rightLen
 	if availableSpace < 0 {
 		availableSpace = 0
 	}
+	s.placeSegments(leftLen+availableSpace, segments, names)
 
 	// Center message if any
 	if s.message != "" && centerLen+4 <= availableSpace {
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cornish/textivus-editor/config"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestStatusBarDocState(t *testing.T) {
+	s := NewStatusBar(NewStyles(config.DefaultTheme()))
+	s.SetWidth(80)
+	s.SetFilename("/tmp/notes.txt")
+
+	tests := []struct {
+		state          DocState
+		unicode, ascii string
+	}{
+		{DocSaved, "notes.txt", "notes.txt"},
+		{DocModified, "*notes.txt", "*notes.txt"},
+		{DocSaving, "…notes.txt", "~notes.txt"},
+		{DocAutosaved, "↻notes.txt", "@notes.txt"},
+		{DocConflict, "!notes.txt", "!notes.txt"},
+	}
+	for _, tt := range tests {
+		s.SetDocState(tt.state)
+		for _, ascii := range []bool{false, true} {
+			s.SetASCII(ascii)
+			want := tt.unicode
+			if ascii {
+				want = tt.ascii
+			}
+			view := stripANSI(s.View())
+			if !strings.HasPrefix(view, want+" ") {
+				t.Errorf("state %d (ascii %v): view = %q, want prefix %q", tt.state, ascii, view, want)
+			}
+			if w := runewidth.StringWidth(view); w != 80 {
+				t.Errorf("state %d (ascii %v): width %d, want 80", tt.state, ascii, w)
+			}
+		}
+	}
+}
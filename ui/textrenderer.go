@@ -5,7 +5,6 @@ import (
 	"unicode/utf8"
 
 	"github.com/cornish/textivus-editor/syntax"
-	"github.com/mattn/go-runewidth"
 )
 
 // TextRenderer renders the main text content column.
@@ -92,7 +91,7 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 	if state.ScrollY > 0 {
 		for logicalLine < len(state.Lines) && visualLinesSkipped < state.ScrollY {
 			line := state.Lines[logicalLine]
-			wrappedCount := countWrappedLinesLocal(line, width, tabWidth)
+			wrappedCount := len(WrapLine(line, width, tabWidth))
 			if visualLinesSkipped+wrappedCount > state.ScrollY {
 				break
 			}
@@ -106,7 +105,7 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 	for visualLineCount < height && logicalLine < len(state.Lines) {
 		line := state.Lines[logicalLine]
 		sel := state.Selection[logicalLine]
-		wrappedLines := wrapLineLocal(line, width, tabWidth)
+		wrappedLines := WrapLine(line, width, tabWidth)
 
 		var colors []syntax.ColorSpan
 		if state.LineColors != nil {
@@ -124,8 +123,8 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 			}
 
 			rows[visualLineCount] = r.renderWrappedSegment(
-				wrappedLines[wrapIdx], logicalLine, segmentStartCol,
-				state.CursorLine, state.CursorCol, sel, width, tabWidth, colors,
+				wrappedLines[wrapIdx], wrapIdx == len(wrappedLines)-1, logicalLine, segmentStartCol,
+				state.CursorLine, state.CursorCol, sel, state.ExtraSelections[logicalLine], state.Occurrences[logicalLine], state.Hazards[logicalLine], width, tabWidth, colors,
 			)
 			visualLineCount++
 			segmentStartCol += utf8.RuneCountInString(wrappedLines[wrapIdx])
@@ -145,8 +144,8 @@ func (r *TextRenderer) renderWrapped(width, height int, state *RenderState) []st
 }
 
 // renderLineContent renders a single line's content with selection and cursor (no wrap).
+// Grapheme clusters are drawn whole, so accents and emoji sequences stay together.
 func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state *RenderState, colors []syntax.ColorSpan) string {
-	runes := []rune(line)
 	var sb strings.Builder
 
 	// Get ANSI codes for cursor and selection
@@ -154,51 +153,89 @@ func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state
 	cursorCode := "\033[7m" // Reverse video for cursor
 	selectionBg := ColorToANSIBg(ui.SelectionBg)
 	selectionFg := ColorToANSIFg(ui.SelectionFg)
+	occurrenceBg := ColorToANSIBg(ui.WordHighlightBg)
+	hazardCode := ColorToANSIFg(ui.ErrorFg) + "\033[7m" // Reverse video in the error color
 	resetCode := "\033[0m"
 
-	// Apply horizontal scroll
-	visibleStart := state.ScrollX
-	visualCol := 0
-	runeIdx := 0
-
-	// Skip to scroll position
 	tabWidth := state.TabWidth
 	if tabWidth <= 0 {
 		tabWidth = 4
 	}
-	for runeIdx < len(runes) && visualCol < visibleStart {
-		ru := runes[runeIdx]
-		if ru == '\t' {
-			visualCol += tabWidth
-		} else {
-			visualCol += runewidth.RuneWidth(ru)
-		}
-		runeIdx++
+	clusters := Graphemes(line, tabWidth)
+
+	// Skip to scroll position
+	visibleStart := state.ScrollX
+	visualCol := 0
+	idx := 0
+	for idx < len(clusters) && visualCol < visibleStart {
+		visualCol += clusters[idx].Width
+		idx++
 	}
 
 	// Get selection range for this line
 	sel, hasSelection := state.Selection[lineIdx]
+	extra := state.ExtraSelections[lineIdx]
+	occurrences := state.Occurrences[lineIdx]
+	hazards := state.Hazards[lineIdx]
+
+	// Text scrolled out of view is marked at the edge it's hidden past, in
+	// the cell the marker replaces, unless the cursor is there
+	lineWidth := 0
+	for _, g := range clusters {
+		lineWidth += g.Width
+	}
+	moreLeft := state.MoreLeft != "" && visibleStart > 0 && lineWidth > 0
+	moreRight := state.MoreRight != "" && lineWidth > visibleStart+width
+	markerCode := ColorToANSIFg(ui.LineNumber)
+	writeMarker := func(marker string, cells int) {
+		sb.WriteString(markerCode)
+		sb.WriteString(marker)
+		sb.WriteString(resetCode)
+		sb.WriteString(strings.Repeat(" ", cells-1))
+	}
 
 	// Render visible portion
 	outputCol := 0
-	for runeIdx < len(runes) && outputCol < width {
-		ru := runes[runeIdx]
-		rw := runewidth.RuneWidth(ru)
-
-		char := string(ru)
-		if ru == '\t' {
-			char = strings.Repeat(" ", tabWidth) // Render tab as spaces
-			rw = tabWidth
+	col := len([]rune(line))
+	if idx < len(clusters) {
+		col = clusters[idx].Col
+	}
+	// A wide character cut by the left edge leaves blank cells, so every
+	// character is drawn at its cell minus ScrollX, where clicks find it
+	if visualCol > visibleStart {
+		outputCol = min(visualCol-visibleStart, width)
+		if moreLeft {
+			writeMarker(state.MoreLeft, outputCol)
+			moreLeft = false
+		} else {
+			sb.WriteString(strings.Repeat(" ", outputCol))
+		}
+	}
+	for ; idx < len(clusters) && outputCol < width; idx++ {
+		g := clusters[idx]
+		col = g.Col
+		char := g.Text
+		if char == "\t" {
+			char = strings.Repeat(" ", g.Width) // Spaces to the next tab stop
 		}
 
-		if outputCol+rw > width {
+		if outputCol+g.Width > width {
 			break
 		}
 
-		isCursor := lineIdx == state.CursorLine && runeIdx == state.CursorCol
-		isSelected := hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End)
+		extraSelected, extraCaret := extraAt(extra, col, g.Runes)
+		isCursor := (lineIdx == state.CursorLine && state.CursorCol >= col && state.CursorCol < col+g.Runes) || extraCaret
+		isSelected := (hasSelection && sel.covers(col, col+g.Runes)) || extraSelected
 
-		if isCursor {
+		if moreRight && outputCol+g.Width > width-1 && !isCursor {
+			break
+		}
+		if moreLeft && outputCol == 0 && !isCursor && g.Width == 0 {
+			continue // The marker goes in the first cell with something in it
+		}
+		if moreLeft && outputCol == 0 && !isCursor {
+			writeMarker(state.MoreLeft, g.Width)
+		} else if isCursor {
 			sb.WriteString(cursorCode)
 			sb.WriteString(char)
 			sb.WriteString(resetCode)
@@ -207,8 +244,17 @@ func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state
 			sb.WriteString(selectionFg)
 			sb.WriteString(char)
 			sb.WriteString(resetCode)
+		} else if overlaps(hazards, col, col+g.Runes) {
+			sb.WriteString(hazardCode)
+			sb.WriteString(char)
+			sb.WriteString(resetCode)
+		} else if occurrence, _ := extraAt(occurrences, col, g.Runes); occurrence {
+			sb.WriteString(occurrenceBg)
+			sb.WriteString(syntax.ColorAt(colors, col))
+			sb.WriteString(char)
+			sb.WriteString(resetCode)
 		} else {
-			syntaxColor := syntax.ColorAt(colors, runeIdx)
+			syntaxColor := syntax.ColorAt(colors, col)
 			if syntaxColor != "" {
 				sb.WriteString(syntaxColor)
 				sb.WriteString(char)
@@ -218,18 +264,33 @@ func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state
 			}
 		}
 
-		visualCol += rw
-		outputCol += rw
-		runeIdx++
+		visualCol += g.Width
+		outputCol += g.Width
+		col += g.Runes
+		moreLeft = false
 	}
 
-	// Render cursor at end of line if needed
-	if lineIdx == state.CursorLine && runeIdx == state.CursorCol {
+	// Render cursor at end of line if needed, where the line ends in view
+	extraSelected, extraCaret := extraAt(extra, col, 1)
+	cursorAtEnd := (lineIdx == state.CursorLine && col == state.CursorCol) || extraCaret
+	endHidden := idx < len(clusters) || visualCol < visibleStart
+	if moreLeft && (endHidden || !cursorAtEnd) {
+		writeMarker(state.MoreLeft, 1)
+		outputCol++
+		endHidden = true
+	}
+	if endHidden {
+		if moreRight && outputCol < width {
+			sb.WriteString(strings.Repeat(" ", width-1-outputCol))
+			writeMarker(state.MoreRight, 1)
+			outputCol = width
+		}
+	} else if cursorAtEnd {
 		sb.WriteString(cursorCode)
 		sb.WriteString(" ")
 		sb.WriteString(resetCode)
 		outputCol++
-	} else if hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End) {
+	} else if (hasSelection && col >= sel.Start && (sel.End == -1 || col < sel.End)) || extraSelected {
 		sb.WriteString(selectionBg)
 		sb.WriteString(selectionFg)
 		sb.WriteString(" ")
@@ -239,23 +300,42 @@ func (r *TextRenderer) renderLineContent(line string, lineIdx, width int, state
 
 	// Pad to full width
 	if outputCol < width {
-		padding := width - outputCol
-		sb.WriteString(strings.Repeat(" ", padding))
+		r.writePadding(&sb, outputCol, width, state)
 	}
 
 	return sb.String()
 }
 
+// writePadding pads a line from outputCol to width, drawing any rulers that
+// fall in the padded area
+func (r *TextRenderer) writePadding(sb *strings.Builder, outputCol, width int, state *RenderState) {
+	rulerCode := ColorToANSIFg(r.styles.Theme.UI.LineNumber)
+	start := outputCol
+	for _, ruler := range state.Rulers {
+		col := ruler - state.ScrollX
+		if col < start || col >= width || state.RulerChar == "" {
+			continue
+		}
+		sb.WriteString(strings.Repeat(" ", col-start))
+		sb.WriteString(rulerCode)
+		sb.WriteString(state.RulerChar)
+		sb.WriteString("\033[0m")
+		start = col + 1
+	}
+	sb.WriteString(strings.Repeat(" ", width-start))
+}
+
 // renderWrappedSegment renders a single wrapped segment of a line.
-func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStartCol, cursorLine, cursorCol int, sel SelectionRange, width, tabWidth int, colors []syntax.ColorSpan) string {
+func (r *TextRenderer) renderWrappedSegment(segment string, last bool, lineIdx, segmentStartCol, cursorLine, cursorCol int, sel SelectionRange, extra, occurrences, hazards []SelectionRange, width, tabWidth int, colors []syntax.ColorSpan) string {
 	var sb strings.Builder
-	runes := []rune(segment)
 
 	// Get ANSI codes for cursor and selection
 	ui := r.styles.Theme.UI
 	cursorCode := "\033[7m" // Reverse video for cursor
 	selectionBg := ColorToANSIBg(ui.SelectionBg)
 	selectionFg := ColorToANSIFg(ui.SelectionFg)
+	occurrenceBg := ColorToANSIBg(ui.WordHighlightBg)
+	hazardCode := ColorToANSIFg(ui.ErrorFg) + "\033[7m" // Reverse video in the error color
 	resetCode := "\033[0m"
 
 	if tabWidth <= 0 {
@@ -263,16 +343,17 @@ func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStar
 	}
 
 	outputCol := 0
-	for i, ru := range runes {
-		col := segmentStartCol + i
-		isCursor := lineIdx == cursorLine && col == cursorCol
-		isSelected := sel.Start <= col && (sel.End == -1 || col < sel.End)
-
-		char := string(ru)
-		charWidth := runewidth.RuneWidth(ru)
-		if ru == '\t' {
-			char = strings.Repeat(" ", tabWidth)
-			charWidth = tabWidth
+	segmentRunes := 0
+	for _, g := range Graphemes(segment, tabWidth) {
+		col := segmentStartCol + g.Col
+		segmentRunes = g.Col + g.Runes
+		extraSelected, extraCaret := extraAt(extra, col, g.Runes)
+		isCursor := (lineIdx == cursorLine && cursorCol >= col && cursorCol < col+g.Runes) || extraCaret
+		isSelected := sel.covers(col, col+g.Runes) || extraSelected
+
+		char := g.Text
+		if char == "\t" {
+			char = strings.Repeat(" ", g.Width)
 		}
 
 		if isCursor {
@@ -284,6 +365,15 @@ func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStar
 			sb.WriteString(selectionFg)
 			sb.WriteString(char)
 			sb.WriteString(resetCode)
+		} else if overlaps(hazards, col, col+g.Runes) {
+			sb.WriteString(hazardCode)
+			sb.WriteString(char)
+			sb.WriteString(resetCode)
+		} else if occurrence, _ := extraAt(occurrences, col, g.Runes); occurrence {
+			sb.WriteString(occurrenceBg)
+			sb.WriteString(syntax.ColorAt(colors, col))
+			sb.WriteString(char)
+			sb.WriteString(resetCode)
 		} else {
 			syntaxColor := syntax.ColorAt(colors, col)
 			if syntaxColor != "" {
@@ -294,20 +384,15 @@ func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStar
 				sb.WriteString(char)
 			}
 		}
-		outputCol += charWidth
+		outputCol += g.Width
 	}
 
-	// Cursor at end of segment
-	segmentEndCol := segmentStartCol + len(runes)
-	if lineIdx == cursorLine && cursorCol == segmentEndCol && segmentEndCol%width == 0 && len(runes) == width {
-		// Cursor is at wrap point, don't show here
-	} else if lineIdx == cursorLine && cursorCol >= segmentStartCol && cursorCol <= segmentEndCol && outputCol < width {
-		if cursorCol == segmentEndCol {
-			sb.WriteString(cursorCode)
-			sb.WriteString(" ")
-			sb.WriteString(resetCode)
-			outputCol++
-		}
+	// Cursor at end of line; at a wrap point it is drawn on the next segment
+	if last && lineIdx == cursorLine && cursorCol == segmentStartCol+segmentRunes && outputCol < width {
+		sb.WriteString(cursorCode)
+		sb.WriteString(" ")
+		sb.WriteString(resetCode)
+		outputCol++
 	}
 
 	// Pad to full width
@@ -318,6 +403,36 @@ func (r *TextRenderer) renderWrappedSegment(segment string, lineIdx, segmentStar
 	return sb.String()
 }
 
+// covers reports whether r covers part of the columns [start, end). Ranges
+// are tested against whole characters, so one that starts or ends inside
+// an emoji sequence or a character with combining marks still shows.
+func (r SelectionRange) covers(start, end int) bool {
+	return r.Start < end && (r.End == -1 || r.End > start) && r.Start != r.End
+}
+
+// overlaps reports whether any of ranges covers part of the columns [start, end)
+func overlaps(ranges []SelectionRange, start, end int) bool {
+	for _, r := range ranges {
+		if r.covers(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraAt reports whether the character at columns [col, col+runes) is in
+// one of the extra selections and whether an extra caret sits on it
+func extraAt(ranges []SelectionRange, col, runes int) (selected, caret bool) {
+	for _, r := range ranges {
+		if r.Start == r.End {
+			caret = caret || (r.Start >= col && r.Start < col+runes)
+			continue
+		}
+		selected = selected || r.covers(col, col+runes)
+	}
+	return selected, caret
+}
+
 // renderEmptyLine renders an empty line marker (~).
 func (r *TextRenderer) renderEmptyLine(width int) string {
 	var sb strings.Builder
@@ -330,81 +445,3 @@ func (r *TextRenderer) renderEmptyLine(width int) string {
 	}
 	return sb.String()
 }
-
-// Helper functions (local copies to avoid dependency issues)
-
-// countWrappedLinesLocal counts how many visual lines a buffer line takes.
-// Accounts for tabs and wide characters.
-func countWrappedLinesLocal(line string, width, tabWidth int) int {
-	if width <= 0 {
-		return 1
-	}
-	visualWidth := calculateVisualWidth(line, tabWidth)
-	if visualWidth == 0 {
-		return 1
-	}
-	return (visualWidth + width - 1) / width
-}
-
-// wrapLineLocal splits a line into segments that fit within width visual columns.
-// Accounts for tabs and wide characters.
-func wrapLineLocal(line string, width, tabWidth int) []string {
-	if width <= 0 {
-		return []string{line}
-	}
-	if tabWidth <= 0 {
-		tabWidth = 4
-	}
-	runes := []rune(line)
-	if len(runes) == 0 {
-		return []string{""}
-	}
-
-	var segments []string
-	var currentSegment strings.Builder
-	currentWidth := 0
-
-	for _, r := range runes {
-		charWidth := runewidth.RuneWidth(r)
-		if r == '\t' {
-			charWidth = tabWidth
-		}
-
-		if currentWidth+charWidth > width {
-			// Start a new segment
-			segments = append(segments, currentSegment.String())
-			currentSegment.Reset()
-			currentWidth = 0
-		}
-
-		currentSegment.WriteRune(r)
-		currentWidth += charWidth
-	}
-
-	// Don't forget the last segment
-	if currentSegment.Len() > 0 {
-		segments = append(segments, currentSegment.String())
-	}
-
-	if len(segments) == 0 {
-		return []string{""}
-	}
-	return segments
-}
-
-// calculateVisualWidth returns the visual width of a string,
-// accounting for tabs and wide characters.
-func calculateVisualWidth(s string, tabWidth int) int {
-	if tabWidth <= 0 {
-		tabWidth = 4
-	}
-	width := 0
-	for _, r := range s {
-		if r == '\t' {
-			width += tabWidth
-		} else {
-			width += runewidth.RuneWidth(r)
-		}
-	}
-	return width
-}
@@ -1,13 +1,6 @@
 package ui
 
-import (
-	"strings"
-	"unicode/utf8"
-
-	"github.com/cornish/textivus-editor/syntax"
-
-	"github.com/mattn/go-runewidth"
-)
+import "strings"
 
 // Viewport handles the scrollable view of the text
 type Viewport struct {
@@ -18,7 +11,11 @@ type Viewport struct {
 	showLineNum    bool
 	wordWrap       bool
 	scrollbarWidth int // Width reserved for scrollbar (0 if disabled)
+	minimapWidth   int // Width reserved for the minimap (0 if disabled)
+	sidebarWidth   int // Width reserved for the file tree on the left (0 if hidden)
+	lineCount      int // Lines in the document, which sets the line number width
 	tabWidth       int // Display width of tabs
+	scrollOff      int // Lines of context kept above and below the cursor
 	styles         Styles
 }
 
@@ -50,6 +47,17 @@ func (v *Viewport) TabWidth() int {
 	return v.tabWidth
 }
 
+// SetScrollOff sets how many lines of context to keep above and below the
+// cursor when scrolling to it
+func (v *Viewport) SetScrollOff(lines int) {
+	v.scrollOff = max(lines, 0)
+}
+
+// ScrollOff returns the lines of context kept around the cursor
+func (v *Viewport) ScrollOff() int {
+	return v.scrollOff
+}
+
 // SetSize sets the viewport dimensions
 func (v *Viewport) SetSize(width, height int) {
 	v.width = width
@@ -117,105 +125,105 @@ func (v *Viewport) SetStyles(styles Styles) {
 	v.styles = styles
 }
 
-// MoveDownVisual moves the cursor down by one visual line when word wrap is enabled.
+// MoveDownVisual moves the cursor down by one visual line, keeping its
+// visual column. Columns are byte offsets into the line, like the cursor's.
 // Returns the new line and column position.
 func (v *Viewport) MoveDownVisual(lines []string, line, col int) (newLine, newCol int) {
-	if !v.wordWrap || line >= len(lines) {
-		// No word wrap or at end - just move to next buffer line
-		if line < len(lines)-1 {
-			return line + 1, col
-		}
-		return line, col
-	}
-
-	textWidth := v.TextWidth()
-	if textWidth <= 0 {
-		textWidth = 1
-	}
+	return v.MoveVisualRows(lines, line, col, 1, v.VisualX(lines, line, col))
+}
 
-	currentLine := lines[line]
-	lineRunes := utf8.RuneCountInString(currentLine)
+// MoveUpVisual moves the cursor up by one visual line, keeping its visual
+// column. Returns the new line and column position.
+func (v *Viewport) MoveUpVisual(lines []string, line, col int) (newLine, newCol int) {
+	return v.MoveVisualRows(lines, line, col, -1, v.VisualX(lines, line, col))
+}
 
-	// Which visual segment is the cursor in?
-	segmentIdx := col / textWidth
-	segmentCount := (lineRunes + textWidth - 1) / textWidth
-	if segmentCount == 0 {
-		segmentCount = 1
+// VisualX returns the cell byte column col is drawn at, counted from the
+// start of its row
+func (v *Viewport) VisualX(lines []string, line, col int) int {
+	if line < 0 || line >= len(lines) {
+		return 0
 	}
+	col = min(max(col, 0), len(lines[line]))
+	_, _, start := v.segmentAt(lines[line], col)
+	return VisualWidth(lines[line][start:col], v.TabWidth())
+}
 
-	// If there's another segment below in the same buffer line, move there
-	if segmentIdx < segmentCount-1 {
-		// Move to next segment, same relative position
-		newCol = (segmentIdx+1)*textWidth + (col % textWidth)
-		if newCol > lineRunes {
-			newCol = lineRunes
-		}
-		return line, newCol
+// MoveVisualRows moves the cursor n screen rows, down when n is positive,
+// onto the character drawn at cell x of the row it lands on. It stops at
+// the first and last rows; if it can't move at all, line and col come back
+// unchanged.
+func (v *Viewport) MoveVisualRows(lines []string, line, col, n, x int) (newLine, newCol int) {
+	if line < 0 || line >= len(lines) || n == 0 {
+		return line, col
 	}
-
-	// Otherwise, move to the next buffer line
-	if line < len(lines)-1 {
-		// Try to maintain column position within the first segment
-		colInSegment := col % textWidth
-		nextLineRunes := utf8.RuneCountInString(lines[line+1])
-		newCol = colInSegment
-		if newCol > nextLineRunes {
-			newCol = nextLineRunes
+	segments, idx, _ := v.segmentAt(lines[line], col)
+	moved := false
+	for ; n > 0; n-- {
+		if idx < len(segments)-1 {
+			idx++
+		} else if line < len(lines)-1 {
+			line++
+			segments, idx = v.segments(lines[line]), 0
+		} else {
+			break
 		}
-		return line + 1, newCol
+		moved = true
 	}
-
-	// At end of file
-	return line, col
-}
-
-// MoveUpVisual moves the cursor up by one visual line when word wrap is enabled.
-// Returns the new line and column position.
-func (v *Viewport) MoveUpVisual(lines []string, line, col int) (newLine, newCol int) {
-	if !v.wordWrap {
-		// No word wrap - just move to previous buffer line
-		if line > 0 {
-			return line - 1, col
+	for ; n < 0; n++ {
+		if idx > 0 {
+			idx--
+		} else if line > 0 {
+			line--
+			segments = v.segments(lines[line])
+			idx = len(segments) - 1
+		} else {
+			break
 		}
+		moved = true
+	}
+	if !moved {
 		return line, col
 	}
-
-	textWidth := v.TextWidth()
-	if textWidth <= 0 {
-		textWidth = 1
+	start := 0
+	for _, segment := range segments[:idx] {
+		start += len(segment)
 	}
+	return line, start + v.offsetInSegment(segments, idx, x)
+}
 
-	// Which visual segment is the cursor in?
-	segmentIdx := col / textWidth
-
-	// If we're not in the first segment, move to the previous segment
-	if segmentIdx > 0 {
-		// Move to previous segment, same relative position
-		newCol = (segmentIdx-1)*textWidth + (col % textWidth)
-		return line, newCol
+// segments splits line into the rows it is drawn on: wrapped segments with
+// word wrap on, otherwise the whole line
+func (v *Viewport) segments(line string) []string {
+	if !v.wordWrap {
+		return []string{line}
 	}
+	return WrapLine(line, max(v.TextWidth(), 1), v.TabWidth())
+}
 
-	// Otherwise, move to the last segment of the previous buffer line
-	if line > 0 {
-		prevLine := lines[line-1]
-		prevLineRunes := utf8.RuneCountInString(prevLine)
-		prevSegmentCount := (prevLineRunes + textWidth - 1) / textWidth
-		if prevSegmentCount == 0 {
-			prevSegmentCount = 1
-		}
+// segmentAt returns the rows line is drawn on, which one byte column col is
+// drawn in, and that row's byte offset in the line
+func (v *Viewport) segmentAt(line string, col int) (segments []string, idx, start int) {
+	segments = v.segments(line)
+	for idx < len(segments)-1 && start+len(segments[idx]) <= col {
+		start += len(segments[idx])
+		idx++
+	}
+	return segments, idx, start
+}
 
-		// Move to last segment of previous line, try to maintain column position
-		colInSegment := col % textWidth
-		lastSegmentStart := (prevSegmentCount - 1) * textWidth
-		newCol = lastSegmentStart + colInSegment
-		if newCol > prevLineRunes {
-			newCol = prevLineRunes
+// offsetInSegment returns the byte offset within segments[idx] of the
+// character drawn at cell x. Past the end of a row that wraps, it stops on
+// the row's last character, since the offset after it starts the next row.
+func (v *Viewport) offsetInSegment(segments []string, idx, x int) int {
+	segment := segments[idx]
+	offset := OffsetAtVisual(segment, x, v.TabWidth())
+	if offset == len(segment) && idx < len(segments)-1 {
+		if clusters := Graphemes(segment, v.TabWidth()); len(clusters) > 0 {
+			offset = clusters[len(clusters)-1].Offset
 		}
-		return line - 1, newCol
 	}
-
-	// At start of file
-	return line, col
+	return offset
 }
 
 // EnsureCursorVisible scrolls the viewport to ensure the cursor is visible
@@ -230,10 +238,7 @@ func (v *Viewport) EnsureCursorVisible(cursorLine, cursorCol int) {
 
 	// Horizontal scrolling (only when word wrap is off)
 	if !v.wordWrap {
-		textWidth := v.width
-		if v.showLineNum {
-			textWidth -= 5 // Line number width
-		}
+		textWidth := v.TextWidth()
 
 		if cursorCol < v.scrollX {
 			v.scrollX = cursorCol
@@ -246,48 +251,108 @@ func (v *Viewport) EnsureCursorVisible(cursorLine, cursorCol int) {
 	}
 }
 
-// EnsureCursorVisibleWrapped scrolls the viewport to ensure cursor is visible (word-wrap aware)
-// lines parameter is needed to calculate visual line positions
+// EnsureCursorVisibleWrapped scrolls the viewport to ensure cursor is visible
+// (word-wrap and tab aware), with scrolloff lines of context around it.
+// cursorCol is a byte offset into the cursor line.
 func (v *Viewport) EnsureCursorVisibleWrapped(lines []string, cursorLine, cursorCol int) {
 	if !v.wordWrap {
-		v.EnsureCursorVisible(cursorLine, cursorCol)
+		visualCol, width := cursorCol, 1
+		if cursorLine < len(lines) {
+			visualCol, width = v.cursorCells(lines[cursorLine], cursorCol)
+		}
+		v.EnsureCursorVisible(cursorLine, visualCol)
+		// Show all of a wide character, not just its first cell
+		if textWidth := v.TextWidth(); width > 1 && visualCol+width > v.scrollX+textWidth {
+			v.scrollX = max(visualCol+width-textWidth, 0)
+		}
+		v.scrollToShow(cursorLine, len(lines))
 		return
 	}
 
-	textWidth := v.TextWidth()
-	if textWidth <= 0 {
-		textWidth = 1
+	v.scrollToShow(v.CursorVisualLine(lines, cursorLine, cursorCol), v.totalVisualLines(lines))
+	v.scrollX = 0 // No horizontal scroll with word wrap
+}
+
+// cursorCells returns the cell the character at byte column col of line
+// is drawn at and how many cells it takes; past the end of the line, the
+// cursor takes one
+func (v *Viewport) cursorCells(line string, col int) (cell, width int) {
+	col = min(max(col, 0), len(line))
+	for _, g := range Graphemes(line, v.TabWidth()) {
+		if g.Offset+len(g.Text) > col {
+			return g.Visual, max(g.Width, 1)
+		}
+		cell = g.Visual + g.Width
 	}
+	return cell, 1
+}
 
-	// Calculate visual line position of cursor
+// CursorColumnInView reports whether the character at byte column col of
+// line is in view horizontally; with word wrap it always is
+func (v *Viewport) CursorColumnInView(line string, col int) bool {
+	if v.wordWrap {
+		return true
+	}
+	cell, width := v.cursorCells(line, col)
+	return cell >= v.scrollX && cell+width <= v.scrollX+v.TextWidth()
+}
+
+// CursorVisualLine returns the visual line the cursor is on, counting
+// wrapped segments when word wrap is on
+func (v *Viewport) CursorVisualLine(lines []string, cursorLine, cursorCol int) int {
+	if !v.wordWrap {
+		return cursorLine
+	}
+	textWidth := max(v.TextWidth(), 1)
 	visualLine := 0
 	for i := 0; i < cursorLine && i < len(lines); i++ {
 		visualLine += v.countWrappedLines(lines[i], textWidth)
 	}
 
-	// Add offset within the current line based on cursor column
+	// Add the wrapped segment the cursor is in
 	if cursorLine < len(lines) {
-		lineLen := utf8.RuneCountInString(lines[cursorLine])
-		if lineLen > 0 && cursorCol > 0 {
-			visualLine += cursorCol / textWidth
-		}
+		_, idx, _ := v.segmentAt(lines[cursorLine], cursorCol)
+		visualLine += idx
 	}
+	return visualLine
+}
 
-	// Scroll to show cursor
-	if visualLine < v.scrollY {
-		v.scrollY = visualLine
+// scrollToShow scrolls just enough to show visualLine with scrolloff lines
+// around it. The margin shrinks on short views so the line can still move,
+// and doesn't reach past the first or last of total lines.
+func (v *Viewport) scrollToShow(visualLine, total int) {
+	margin := min(v.scrollOff, max((v.height-1)/2, 0))
+	if top := visualLine - margin; top < v.scrollY {
+		v.scrollY = max(top, 0)
 	}
-	if visualLine >= v.scrollY+v.height {
-		v.scrollY = visualLine - v.height + 1
+	if bottom := min(visualLine+margin, total-1); bottom >= v.scrollY+v.height {
+		v.scrollY = max(bottom-v.height+1, 0)
 	}
+}
 
-	v.scrollX = 0 // No horizontal scroll with word wrap
+// CenterCursor scrolls so the cursor's visual line is in the middle of the
+// view, as far as the start of the text allows
+func (v *Viewport) CenterCursor(lines []string, cursorLine, cursorCol int) {
+	v.scrollY = max(v.CursorVisualLine(lines, cursorLine, cursorCol)-v.height/2, 0)
+}
+
+// ScrollBy scrolls n visual lines, down when n is positive, without going
+// past the start or end of the text
+func (v *Viewport) ScrollBy(lines []string, n int) {
+	maxScroll := max(v.totalVisualLines(lines)-v.height, 0)
+	v.scrollY = min(max(v.scrollY+n, 0), max(maxScroll, v.scrollY))
+}
+
+// SetLineCount sets how many lines the document has, so the line number
+// column is wide enough for the last one
+func (v *Viewport) SetLineCount(count int) {
+	v.lineCount = count
 }
 
 // LineNumberWidth returns the width of the line number column
 func (v *Viewport) LineNumberWidth() int {
 	if v.showLineNum {
-		return 5
+		return LineNumberColumnWidth(v.lineCount)
 	}
 	return 0
 }
@@ -305,9 +370,30 @@ func (v *Viewport) ScrollbarWidth() int {
 	return v.scrollbarWidth
 }
 
-// TextWidth returns the width available for text (viewport width minus line numbers and scrollbar)
+// SetSidebarWidth sets the width reserved for the file tree left of the text
+func (v *Viewport) SetSidebarWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	v.sidebarWidth = width
+}
+
+// SidebarWidth returns the width reserved for the file tree
+func (v *Viewport) SidebarWidth() int {
+	return v.sidebarWidth
+}
+
+// SetMinimapWidth sets the width reserved for the minimap right of the text
+func (v *Viewport) SetMinimapWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	v.minimapWidth = width
+}
+
+// TextWidth returns the width available for text (viewport width minus file tree, line numbers, minimap and scrollbar)
 func (v *Viewport) TextWidth() int {
-	return v.width - v.LineNumberWidth() - v.scrollbarWidth
+	return v.width - v.sidebarWidth - v.LineNumberWidth() - v.minimapWidth - v.scrollbarWidth
 }
 
 // CountVisualLines returns the total number of visual lines when word wrap is enabled
@@ -323,12 +409,7 @@ func (v *Viewport) CountVisualLines(lines []string) int {
 
 	total := 0
 	for _, line := range lines {
-		lineLen := len([]rune(line))
-		if lineLen == 0 {
-			total++
-		} else {
-			total += (lineLen + textWidth - 1) / textWidth
-		}
+		total += v.countWrappedLines(line, textWidth)
 	}
 	return total
 }
@@ -353,13 +434,7 @@ func (v *Viewport) VisualLineToBufferLine(lines []string, visualLine int) (buffe
 
 	currentVisual := 0
 	for i, line := range lines {
-		lineLen := len([]rune(line))
-		var linesForThis int
-		if lineLen == 0 {
-			linesForThis = 1
-		} else {
-			linesForThis = (lineLen + textWidth - 1) / textWidth
-		}
+		linesForThis := v.countWrappedLines(line, textWidth)
 
 		if currentVisual+linesForThis > visualLine {
 			// The visual line is within this buffer line
@@ -375,359 +450,21 @@ func (v *Viewport) VisualLineToBufferLine(lines []string, visualLine int) (buffe
 	return 0, 0
 }
 
-// RenderLine renders a single line with optional selection highlighting
+// SelectionRange is a range of rune columns on a line, for the selection
+// and the other highlights the text renderer draws
 type SelectionRange struct {
 	Start int // Start column (inclusive)
 	End   int // End column (exclusive), -1 for end of line
 }
 
-// Render renders the visible portion of the text
-// lineColors is an optional map of line index to color spans for syntax highlighting
-func (v *Viewport) Render(lines []string, cursorLine, cursorCol int, selection map[int]SelectionRange, lineColors map[int][]syntax.ColorSpan) string {
-	if v.wordWrap {
-		return v.renderWrapped(lines, cursorLine, cursorCol, selection, lineColors)
-	}
-	return v.renderNoWrap(lines, cursorLine, cursorCol, selection, lineColors)
-}
-
-// renderNoWrap renders without word wrap (original behavior)
-func (v *Viewport) renderNoWrap(lines []string, cursorLine, cursorCol int, selection map[int]SelectionRange, lineColors map[int][]syntax.ColorSpan) string {
-	var sb strings.Builder
-
-	endLine := v.scrollY + v.height
-	if endLine > len(lines) {
-		endLine = len(lines)
-	}
-
-	for lineIdx := v.scrollY; lineIdx < endLine; lineIdx++ {
-		if lineIdx > v.scrollY {
-			sb.WriteString("\n")
-		}
-
-		// Line number
-		if v.showLineNum {
-			lineNumStyle := v.styles.LineNumber
-			if lineIdx == cursorLine {
-				lineNumStyle = v.styles.LineNumberActive
-			}
-			sb.WriteString(lineNumStyle.Render(padLeft(itoa(lineIdx+1), 4)))
-		}
-
-		// Line content
-		line := ""
-		if lineIdx < len(lines) {
-			line = lines[lineIdx]
-		}
-
-		// Get syntax colors for this line
-		var colors []syntax.ColorSpan
-		if lineColors != nil {
-			colors = lineColors[lineIdx]
-		}
-
-		// Apply horizontal scroll
-		displayLine := v.renderLineContent(line, lineIdx, cursorLine, cursorCol, selection, colors)
-		sb.WriteString(displayLine)
-	}
-
-	// Fill remaining lines if buffer is shorter than viewport
-	textWidth := v.TextWidth()
-	for lineIdx := endLine; lineIdx < v.scrollY+v.height; lineIdx++ {
-		if lineIdx > v.scrollY || endLine > v.scrollY {
-			sb.WriteString("\n")
-		}
-		if v.showLineNum {
-			sb.WriteString(v.styles.LineNumber.Render("    "))
-		}
-		// Render ~ and pad to full width
-		sb.WriteString(v.styles.Subtle.Render("~"))
-		if textWidth > 1 {
-			sb.WriteString(strings.Repeat(" ", textWidth-1))
-		}
-	}
-
-	return sb.String()
-}
-
-// renderWrapped renders with word wrap enabled
-func (v *Viewport) renderWrapped(lines []string, cursorLine, cursorCol int, selection map[int]SelectionRange, lineColors map[int][]syntax.ColorSpan) string {
-	var sb strings.Builder
-	textWidth := v.TextWidth()
-	visualLineCount := 0
-
-	// Skip lines until we reach scrollY visual lines
-	logicalLine := 0
-	visualLinesSkipped := 0
-	startOffset := 0
-
-	// Only do the counting loop if we're scrolled down
-	if v.scrollY > 0 {
-		// First, count visual lines to find where to start
-		for logicalLine < len(lines) && visualLinesSkipped < v.scrollY {
-			line := lines[logicalLine]
-			wrappedCount := v.countWrappedLines(line, textWidth)
-			if visualLinesSkipped+wrappedCount > v.scrollY {
-				// Start partway through this logical line
-				break
-			}
-			visualLinesSkipped += wrappedCount
-			logicalLine++
-		}
-
-		// Calculate offset within the current logical line
-		startOffset = v.scrollY - visualLinesSkipped
-	}
-
-	// Render visible lines
-	for visualLineCount < v.height && logicalLine < len(lines) {
-		line := lines[logicalLine]
-		sel := selection[logicalLine]
-		wrappedLines := v.wrapLine(line, textWidth)
-
-		// Get syntax colors for this line
-		var colors []syntax.ColorSpan
-		if lineColors != nil {
-			colors = lineColors[logicalLine]
-		}
-
-		for wrapIdx := 0; wrapIdx < len(wrappedLines) && visualLineCount < v.height; wrapIdx++ {
-			// Skip lines before our start offset
-			if logicalLine == 0 || visualLinesSkipped < v.scrollY {
-				if wrapIdx < startOffset {
-					continue
-				}
-			}
-
-			if visualLineCount > 0 {
-				sb.WriteString("\n")
-			}
-
-			// Line number (only on first wrapped segment)
-			if v.showLineNum {
-				if wrapIdx == 0 {
-					lineNumStyle := v.styles.LineNumber
-					if logicalLine == cursorLine {
-						lineNumStyle = v.styles.LineNumberActive
-					}
-					sb.WriteString(lineNumStyle.Render(padLeft(itoa(logicalLine+1), 4)))
-				} else {
-					sb.WriteString(v.styles.LineNumber.Render("    "))
-				}
-			}
-
-			// Calculate the column range for this wrapped segment
-			segmentStartCol := wrapIdx * textWidth
-
-			// Render the wrapped segment
-			content := v.renderWrappedSegment(wrappedLines[wrapIdx], logicalLine, segmentStartCol,
-				cursorLine, cursorCol, sel, textWidth, colors)
-			sb.WriteString(content)
-
-			visualLineCount++
-		}
-
-		logicalLine++
-		startOffset = 0 // Reset for subsequent lines
-	}
-
-	// Fill remaining lines
-	for visualLineCount < v.height {
-		if visualLineCount > 0 {
-			sb.WriteString("\n")
-		}
-		if v.showLineNum {
-			sb.WriteString(v.styles.LineNumber.Render("    "))
-		}
-		// Render ~ and pad to full width
-		sb.WriteString(v.styles.Subtle.Render("~"))
-		if textWidth > 1 {
-			sb.WriteString(strings.Repeat(" ", textWidth-1))
-		}
-		visualLineCount++
-	}
-
-	return sb.String()
-}
-
 // countWrappedLines returns how many visual lines a logical line takes
 func (v *Viewport) countWrappedLines(line string, textWidth int) int {
-	if textWidth <= 0 {
-		return 1
-	}
-	lineLen := utf8.RuneCountInString(line)
-	if lineLen == 0 {
-		return 1
-	}
-	return (lineLen + textWidth - 1) / textWidth
+	return len(WrapLine(line, textWidth, v.TabWidth()))
 }
 
 // wrapLine splits a line into wrapped segments
 func (v *Viewport) wrapLine(line string, textWidth int) []string {
-	if textWidth <= 0 {
-		return []string{line}
-	}
-	runes := []rune(line)
-	if len(runes) == 0 {
-		return []string{""}
-	}
-
-	var segments []string
-	for i := 0; i < len(runes); i += textWidth {
-		end := i + textWidth
-		if end > len(runes) {
-			end = len(runes)
-		}
-		segments = append(segments, string(runes[i:end]))
-	}
-	return segments
-}
-
-// renderWrappedSegment renders a single wrapped segment of a line
-func (v *Viewport) renderWrappedSegment(segment string, lineIdx, segmentStartCol, cursorLine, cursorCol int, sel SelectionRange, textWidth int, colors []syntax.ColorSpan) string {
-	var sb strings.Builder
-	runes := []rune(segment)
-	tabWidth := v.TabWidth()
-
-	for i, r := range runes {
-		col := segmentStartCol + i
-		isCursor := lineIdx == cursorLine && col == cursorCol
-		isSelected := sel.Start <= col && (sel.End == -1 || col < sel.End)
-
-		char := string(r)
-		if r == '\t' {
-			char = strings.Repeat(" ", tabWidth)
-		}
-
-		if isCursor {
-			sb.WriteString(v.styles.Cursor.Render(char))
-		} else if isSelected {
-			sb.WriteString(v.styles.Selection.Render(char))
-		} else {
-			// Apply syntax color if available
-			syntaxColor := syntax.ColorAt(colors, col)
-			if syntaxColor != "" {
-				sb.WriteString(syntaxColor)
-				sb.WriteString(char)
-				sb.WriteString("\033[0m") // Reset
-			} else {
-				sb.WriteString(char)
-			}
-		}
-	}
-
-	// Cursor at end of segment
-	segmentEndCol := segmentStartCol + len(runes)
-	renderedCursorAtEnd := false
-	if lineIdx == cursorLine && cursorCol == segmentEndCol && segmentEndCol%textWidth == 0 && len(runes) == textWidth {
-		// Cursor is at wrap point, don't show here
-	} else if lineIdx == cursorLine && cursorCol >= segmentStartCol && cursorCol <= segmentEndCol && len(runes) < textWidth {
-		if cursorCol == segmentEndCol {
-			sb.WriteString(v.styles.Cursor.Render(" "))
-			renderedCursorAtEnd = true
-		}
-	}
-
-	// Pad to full width (account for cursor space if rendered)
-	contentLen := len(runes)
-	if renderedCursorAtEnd {
-		contentLen++
-	}
-	if contentLen < textWidth {
-		sb.WriteString(strings.Repeat(" ", textWidth-contentLen))
-	}
-
-	return v.styles.Editor.Render(sb.String())
-}
-
-// renderLineContent renders a single line's content with selection and cursor
-func (v *Viewport) renderLineContent(line string, lineIdx, cursorLine, cursorCol int, selection map[int]SelectionRange, colors []syntax.ColorSpan) string {
-	textWidth := v.TextWidth()
-	tabWidth := v.TabWidth()
-
-	// Convert line to runes for proper unicode handling
-	runes := []rune(line)
-
-	var sb strings.Builder
-
-	// Apply horizontal scroll - find the actual column position
-	visibleStart := v.scrollX
-
-	visualCol := 0
-	runeIdx := 0
-
-	// Skip to scroll position (using visual columns for scrolling)
-	for runeIdx < len(runes) && visualCol < visibleStart {
-		r := runes[runeIdx]
-		if r == '\t' {
-			visualCol += tabWidth
-		} else {
-			visualCol += runewidth.RuneWidth(r)
-		}
-		runeIdx++
-	}
-
-	// Get selection range for this line
-	sel, hasSelection := selection[lineIdx]
-
-	// Render visible portion
-	outputCol := 0
-	for runeIdx < len(runes) && outputCol < textWidth {
-		r := runes[runeIdx]
-		rw := runewidth.RuneWidth(r)
-
-		char := string(r)
-		if r == '\t' {
-			char = strings.Repeat(" ", tabWidth) // Render tab as spaces
-			rw = tabWidth
-		}
-
-		if outputCol+rw > textWidth {
-			break
-		}
-
-		// Check if this position should be rendered with cursor or selection
-		// Use runeIdx for comparison since cursorCol is the character index
-		isCursor := lineIdx == cursorLine && runeIdx == cursorCol
-		isSelected := hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End)
-
-		if isCursor {
-			sb.WriteString(v.styles.Cursor.Render(char))
-		} else if isSelected {
-			sb.WriteString(v.styles.Selection.Render(char))
-		} else {
-			// Apply syntax color if available
-			syntaxColor := syntax.ColorAt(colors, runeIdx)
-			if syntaxColor != "" {
-				sb.WriteString(syntaxColor)
-				sb.WriteString(char)
-				sb.WriteString("\033[0m") // Reset
-			} else {
-				sb.WriteString(char)
-			}
-		}
-
-		visualCol += rw
-		outputCol += rw
-		runeIdx++
-	}
-
-	// Render cursor at end of line if needed
-	if lineIdx == cursorLine && runeIdx == cursorCol {
-		sb.WriteString(v.styles.Cursor.Render(" "))
-		outputCol++
-	} else if hasSelection && runeIdx >= sel.Start && (sel.End == -1 || runeIdx < sel.End) {
-		// Selection extends past end of line
-		sb.WriteString(v.styles.Selection.Render(" "))
-		outputCol++
-	}
-
-	// Pad to full width
-	if outputCol < textWidth {
-		padding := textWidth - outputCol
-		sb.WriteString(strings.Repeat(" ", padding))
-	}
-
-	return v.styles.Editor.Render(sb.String())
+	return WrapLine(line, textWidth, v.TabWidth())
 }
 
 // ScrollUp scrolls the viewport up by one line
@@ -755,6 +492,27 @@ func (v *Viewport) ScrollDownWrapped(lines []string) {
 	}
 }
 
+// ScrollLeft scrolls left by n columns
+func (v *Viewport) ScrollLeft(n int) {
+	v.SetScrollX(v.scrollX - n)
+}
+
+// ScrollRight scrolls right by n columns, stopping once the end of the
+// widest visible line is in view. Word wrap leaves nothing to scroll to.
+func (v *Viewport) ScrollRight(lines []string, n int) {
+	if v.wordWrap {
+		return
+	}
+	widest := 0
+	for _, line := range lines[min(v.scrollY, len(lines)):min(v.scrollY+v.height, len(lines))] {
+		widest = max(widest, VisualWidth(line, v.TabWidth()))
+	}
+	maxScroll := widest - v.TextWidth() + 1 // Room for the cursor after the last character
+	if v.scrollX < maxScroll {
+		v.scrollX = min(v.scrollX+n, maxScroll)
+	}
+}
+
 // PageUp scrolls up by one page
 func (v *Viewport) PageUp() {
 	v.scrollY -= v.height
@@ -838,17 +596,23 @@ func itoa(n int) string {
 // PositionFromClick converts a click position to buffer line and column
 func (v *Viewport) PositionFromClick(x, y int) (line, col int) {
 	line = v.scrollY + y
-	col = v.scrollX + x - v.LineNumberWidth()
+	col = v.scrollX + x - v.sidebarWidth - v.LineNumberWidth()
 	if col < 0 {
 		col = 0
 	}
 	return
 }
 
-// PositionFromClickWrapped converts a click position to buffer line and column (word-wrap aware)
+// PositionFromClickWrapped converts a click position to buffer line and byte
+// column (word-wrap and tab aware), at the start of the character clicked on
 func (v *Viewport) PositionFromClickWrapped(lines []string, x, y int) (line, col int) {
+	x -= v.sidebarWidth + v.LineNumberWidth()
 	if !v.wordWrap {
-		return v.PositionFromClick(x, y)
+		line = v.scrollY + y
+		if line < len(lines) {
+			col = OffsetAtVisual(lines[line], max(v.scrollX+x, 0), v.TabWidth())
+		}
+		return line, col
 	}
 
 	textWidth := v.TextWidth()
@@ -862,31 +626,24 @@ func (v *Viewport) PositionFromClickWrapped(lines []string, x, y int) (line, col
 	// Find the logical line and offset within it
 	visualLine := 0
 	for logicalLine := 0; logicalLine < len(lines); logicalLine++ {
-		lineWrappedCount := v.countWrappedLines(lines[logicalLine], textWidth)
+		segments := v.wrapLine(lines[logicalLine], textWidth)
 
-		if visualLine+lineWrappedCount > targetVisualLine {
+		if visualLine+len(segments) > targetVisualLine {
 			// Click is within this logical line
-			line = logicalLine
-			// Calculate which wrapped segment and column
-			segmentIndex := targetVisualLine - visualLine
-			col = segmentIndex*textWidth + (x - v.LineNumberWidth())
-			if col < 0 {
-				col = 0
-			}
-			// Clamp to line length
-			lineLen := utf8.RuneCountInString(lines[logicalLine])
-			if col > lineLen {
-				col = lineLen
+			idx := targetVisualLine - visualLine
+			start := 0
+			for _, segment := range segments[:idx] {
+				start += len(segment)
 			}
-			return
+			return logicalLine, start + v.offsetInSegment(segments, idx, max(x, 0))
 		}
-		visualLine += lineWrappedCount
+		visualLine += len(segments)
 	}
 
 	// Click is past end of file
 	if len(lines) > 0 {
 		line = len(lines) - 1
-		col = utf8.RuneCountInString(lines[line])
+		col = len(lines[line])
 	}
 	return
 }
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTabsRenderToTabStops(t *testing.T) {
+	state := &RenderState{Lines: []string{"a\tb", "abcd\te"}, CursorLine: -1, TabWidth: 4}
+	rows := NewTextRenderer(DefaultStyles()).Render(12, 2, state)
+	if got := stripANSI(rows[0]); !strings.HasPrefix(got, "a   b ") {
+		t.Errorf("row 0 = %q, want b at the first tab stop", got)
+	}
+	if got := stripANSI(rows[1]); !strings.HasPrefix(got, "abcd    e ") {
+		t.Errorf("row 1 = %q, want e at the second tab stop", got)
+	}
+
+	state.TabWidth = 8
+	rows = NewTextRenderer(DefaultStyles()).Render(12, 1, state)
+	if got := stripANSI(rows[0]); !strings.HasPrefix(got, "a       b ") {
+		t.Errorf("tab width 8: row 0 = %q, want b at column 8", got)
+	}
+}
+
+func TestViewportColumnsFollowTabs(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(40, 10)
+	v.SetTabWidth(4)
+	lines := []string{"\tx", "abcdefgh"}
+
+	// Clicking anywhere on the tab puts the cursor before it
+	for x, want := range []int{0, 0, 0, 0, 1, 2} {
+		if _, col := v.PositionFromClickWrapped(lines, x, 0); col != want {
+			t.Errorf("click at x=%d: col %d, want %d", x, col, want)
+		}
+	}
+
+	// Moving down from after the tab keeps the visual column
+	if line, col := v.MoveDownVisual(lines, 0, 1); line != 1 || col != 4 {
+		t.Errorf("MoveDownVisual = (%d, %d), want (1, 4)", line, col)
+	}
+	if line, col := v.MoveUpVisual(lines, 1, 2); line != 0 || col != 0 {
+		t.Errorf("MoveUpVisual = (%d, %d), want (0, 0)", line, col)
+	}
+}
+
+func TestViewportWrapMatchesRenderer(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(6, 10)
+	v.SetTabWidth(4)
+	v.SetWordWrap(true)
+	lines := []string{"ab\tcdefgh", "z"}
+
+	// The tab takes two cells, so the rows are "ab\tcd" and "efgh"
+	if got := v.CountVisualLines(lines); got != 3 {
+		t.Errorf("CountVisualLines = %d, want 3", got)
+	}
+	if line, col := v.PositionFromClickWrapped(lines, 1, 1); line != 0 || col != 6 {
+		t.Errorf("click on second row = (%d, %d), want (0, 6)", line, col)
+	}
+	if line, col := v.MoveDownVisual(lines, 0, 1); line != 0 || col != 6 {
+		t.Errorf("MoveDownVisual = (%d, %d), want (0, 6)", line, col)
+	}
+	if line, wrap := v.VisualLineToBufferLine(lines, 2); line != 1 || wrap != 0 {
+		t.Errorf("VisualLineToBufferLine(2) = (%d, %d), want (1, 0)", line, wrap)
+	}
+}
+
+func TestScrollOff(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(40, 10)
+	v.SetScrollOff(3)
+	lines := make([]string, 100)
+
+	// Moving down keeps three lines below the cursor
+	v.EnsureCursorVisibleWrapped(lines, 7, 0)
+	if got := v.ScrollY(); got != 1 {
+		t.Errorf("cursor on line 7: scrollY %d, want 1", got)
+	}
+	// and three above it when moving back up
+	v.SetScrollY(50)
+	v.EnsureCursorVisibleWrapped(lines, 52, 0)
+	if got := v.ScrollY(); got != 49 {
+		t.Errorf("cursor on line 52: scrollY %d, want 49", got)
+	}
+	// The margin stops at the ends of the text
+	v.EnsureCursorVisibleWrapped(lines, 99, 0)
+	if got := v.ScrollY(); got != 90 {
+		t.Errorf("cursor on last line: scrollY %d, want 90", got)
+	}
+	v.EnsureCursorVisibleWrapped(lines, 1, 0)
+	if got := v.ScrollY(); got != 0 {
+		t.Errorf("cursor on line 1: scrollY %d, want 0", got)
+	}
+
+	// A margin bigger than half the view still lets the cursor move
+	v.SetScrollOff(100)
+	v.EnsureCursorVisibleWrapped(lines, 20, 0)
+	if got := v.ScrollY(); got != 15 {
+		t.Errorf("large scrolloff: scrollY %d, want 15", got)
+	}
+}
+
+func TestScrollOffWrapped(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(10, 4)
+	v.SetWordWrap(true)
+	v.SetScrollOff(1)
+	// Each line wraps into two rows
+	lines := []string{"aaaaaaaaaabbbbb", "cccccccccceeeee", "ffffffffffggggg", "hhhhhhhhhhiiiii"}
+
+	// Second row of line 1 is visual line 3; one row of context below it
+	v.EnsureCursorVisibleWrapped(lines, 1, 12)
+	if got := v.ScrollY(); got != 1 {
+		t.Errorf("scrollY %d, want 1", got)
+	}
+}
+
+func TestCenterCursorAndScrollBy(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(40, 10)
+	lines := make([]string, 100)
+
+	v.CenterCursor(lines, 50, 0)
+	if got := v.ScrollY(); got != 45 {
+		t.Errorf("CenterCursor: scrollY %d, want 45", got)
+	}
+	v.CenterCursor(lines, 2, 0)
+	if got := v.ScrollY(); got != 0 {
+		t.Errorf("CenterCursor near the top: scrollY %d, want 0", got)
+	}
+
+	v.ScrollBy(lines, 5)
+	if got := v.ScrollY(); got != 5 {
+		t.Errorf("ScrollBy(5): scrollY %d, want 5", got)
+	}
+	v.ScrollBy(lines, 1000)
+	if got := v.ScrollY(); got != 90 {
+		t.Errorf("ScrollBy past the end: scrollY %d, want 90", got)
+	}
+	v.ScrollBy(lines, -1000)
+	if got := v.ScrollY(); got != 0 {
+		t.Errorf("ScrollBy past the start: scrollY %d, want 0", got)
+	}
+}
+
+func TestMoveVisualRows(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(10, 10)
+	v.SetWordWrap(true)
+	// Line 0 wraps into rows "aaaaaaaaaa" and "bbb"
+	lines := []string{"aaaaaaaaaabbb", "cccccccc", "dd"}
+
+	// Three rows down from column 5 of line 0 lands in line 2, clamped to its end
+	if line, col := v.MoveVisualRows(lines, 0, 5, 3, 5); line != 2 || col != 2 {
+		t.Errorf("down 3 = (%d, %d), want (2, 2)", line, col)
+	}
+	// Up again aiming for cell 5 passes through the short rows
+	if line, col := v.MoveVisualRows(lines, 2, 2, -3, 5); line != 0 || col != 5 {
+		t.Errorf("up 3 = (%d, %d), want (0, 5)", line, col)
+	}
+	// Moving past the ends stops at the first and last rows
+	if line, col := v.MoveVisualRows(lines, 0, 5, 100, 1); line != 2 || col != 1 {
+		t.Errorf("down 100 = (%d, %d), want (2, 1)", line, col)
+	}
+	if line, col := v.MoveVisualRows(lines, 0, 5, -1, 1); line != 0 || col != 5 {
+		t.Errorf("up from the first row = (%d, %d), want it unchanged", line, col)
+	}
+}
+
+func TestLineNumberWidthFollowsLineCount(t *testing.T) {
+	for _, tt := range []struct{ lines, want int }{{1, 4}, {999, 4}, {1000, 5}, {123456, 7}} {
+		if got := LineNumberColumnWidth(tt.lines); got != tt.want {
+			t.Errorf("LineNumberColumnWidth(%d) = %d, want %d", tt.lines, got, tt.want)
+		}
+	}
+
+	v := NewViewport(DefaultStyles())
+	v.SetSize(80, 10)
+	v.ShowLineNumbers(true)
+	v.SetLineCount(20000)
+	if got := v.TextWidth(); got != 74 {
+		t.Errorf("TextWidth = %d, want 74 beside a 6-column gutter", got)
+	}
+	if line, col := v.PositionFromClick(8, 0); line != 0 || col != 2 {
+		t.Errorf("click at x=8 hit %d:%d, want 0:2", line, col)
+	}
+}
+
+func TestWideCharactersLineUpWithClicks(t *testing.T) {
+	v := NewViewport(DefaultStyles())
+	v.SetSize(10, 5)
+	lines := []string{strings.Repeat("中", 10)}
+
+	// Scrolled into the middle of a character, its right half is blank and
+	// each character is drawn where a click finds it
+	v.SetScrollX(3)
+	state := &RenderState{Lines: lines, CursorLine: -1, ScrollX: 3, TabWidth: 4}
+	row := stripANSI(NewTextRenderer(DefaultStyles()).Render(10, 1, state)[0])
+	if !strings.HasPrefix(row, " 中") {
+		t.Errorf("row = %q, want a blank cell before the first whole character", row)
+	}
+	if _, col := v.PositionFromClickWrapped(lines, 1, 0); col != 2*len("中") {
+		t.Errorf("click on the first whole character = col %d, want %d", col, 2*len("中"))
+	}
+
+	// Moving onto a character at the right edge shows both its cells
+	v.SetScrollX(0)
+	v.EnsureCursorVisibleWrapped(lines, 0, 5*len("中"))
+	if got := v.ScrollX(); got != 2 {
+		t.Errorf("scrollX = %d, want 2 to show cells 10 and 11", got)
+	}
+}
+
+func TestSelectionCoversWholeCharacters(t *testing.T) {
+	family := "\U0001F468\u200d\U0001F469\u200d\U0001F467" // One grapheme, five runes
+	state := &RenderState{
+		Lines:      []string{"a" + family + "b"},
+		CursorLine: -1,
+		TabWidth:   4,
+		// Starts inside the emoji sequence
+		Selection: map[int]SelectionRange{0: {Start: 3, End: 5}},
+	}
+	for _, wrap := range []bool{false, true} {
+		state.WordWrap = wrap
+		row := NewTextRenderer(DefaultStyles()).Render(20, 1, state)[0]
+		if !strings.Contains(row, family+"\033[0m") || strings.Index(row, "\033[") > strings.Index(row, family) {
+			t.Errorf("wrap %v: row %q should highlight the whole sequence", wrap, row)
+		}
+		if !strings.Contains(row, family+"\033[0mb") {
+			t.Errorf("wrap %v: b is past the selection", wrap)
+		}
+	}
+}
+
+func TestScrollMarkers(t *testing.T) {
+	state := &RenderState{
+		Lines:      []string{"abcdefghijklmnop", "abc", "中中中中中中中中", ""},
+		CursorLine: -1,
+		ScrollX:    3,
+		TabWidth:   4,
+		MoreLeft:   "<",
+		MoreRight:  ">",
+	}
+	rows := NewTextRenderer(DefaultStyles()).Render(8, 4, state)
+	want := []string{"<efghij>", "<       ", "<中中中>", "        "}
+	for i, w := range want {
+		if got := stripANSI(rows[i]); got != w {
+			t.Errorf("row %d = %q, want %q", i, got, w)
+		}
+	}
+
+	// The cursor is drawn over the marker it would be under
+	state.CursorLine, state.CursorCol = 0, 3
+	if got := stripANSI(NewTextRenderer(DefaultStyles()).Render(8, 1, state)[0]); got != "defghij>" {
+		t.Errorf("cursor on the left edge: row = %q", got)
+	}
+	state.CursorCol = 10
+	if got := stripANSI(NewTextRenderer(DefaultStyles()).Render(8, 1, state)[0]); got != "<efghijk" {
+		t.Errorf("cursor on the right edge: row = %q", got)
+	}
+
+	// A zero-width character at the left edge leaves the marker to the next
+	state.CursorLine = -1
+	state.Lines = []string{"abc\u200bdefghij"}
+	if got := stripANSI(NewTextRenderer(DefaultStyles()).Render(8, 1, state)[0]); got != "<efghij " {
+		t.Errorf("zero-width at the left edge: row = %q", got)
+	}
+
+	// Word wrap has nothing hidden to mark
+	state.WordWrap = true
+	if got := stripANSI(NewTextRenderer(DefaultStyles()).Render(8, 1, state)[0]); strings.ContainsAny(got, "<>") {
+		t.Errorf("word wrap: row = %q, want no markers", got)
+	}
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// Ambiguous-width policies for East Asian ambiguous characters (e.g. "…", "★", box drawing)
+const (
+	AmbiguousAuto   = "auto"   // Follow the locale (RUNEWIDTH_EASTASIAN / LANG)
+	AmbiguousNarrow = "narrow" // Always one cell
+	AmbiguousWide   = "wide"   // Always two cells
+)
+
+// localeEastAsian remembers the locale-detected setting so "auto" can restore it
+var localeEastAsian = runewidth.DefaultCondition.EastAsianWidth
+
+// SetAmbiguousWidth applies the ambiguous-width policy to all runewidth-based
+// width calculations (viewport, dialogs, minimap, status bar). It should match
+// how the user's terminal renders these characters, or borders will misalign.
+func SetAmbiguousWidth(policy string) {
+	wide := localeEastAsian
+	switch policy {
+	case AmbiguousNarrow:
+		wide = false
+	case AmbiguousWide:
+		wide = true
+	}
+	runewidth.EastAsianWidth = wide
+	runewidth.DefaultCondition.EastAsianWidth = wide
+}
+
+// Grapheme is one user-perceived character: a base character with its
+// combining marks, or a whole emoji sequence. Columns count runes, as the
+// cursor and selections do.
+type Grapheme struct {
+	Text   string
+	Offset int // Byte offset where it starts
+	Col    int // Rune column where it starts
+	Runes  int // Number of runes in it
+	Visual int // Cell where it is drawn
+	Width  int // Terminal cells; a tab reaches the next tab stop
+}
+
+// TabStopWidth returns how many cells a tab drawn at cell col takes to
+// reach the next tab stop
+func TabStopWidth(col, tabWidth int) int {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	return tabWidth - col%tabWidth
+}
+
+// clusterWidth returns how many cells cluster takes when drawn at cell col
+func clusterWidth(cluster string, col, tabWidth int) int {
+	if cluster == "\t" {
+		return TabStopWidth(col, tabWidth)
+	}
+	return runewidth.StringWidth(cluster)
+}
+
+// Graphemes splits line into its grapheme clusters, drawn from cell 0
+func Graphemes(line string, tabWidth int) []Grapheme {
+	var clusters []Grapheme
+	offset, col, visual, state := 0, 0, 0, -1
+	for rest := line; rest != ""; {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		g := Grapheme{
+			Text:   cluster,
+			Offset: offset,
+			Col:    col,
+			Runes:  utf8.RuneCountInString(cluster),
+			Visual: visual,
+			Width:  clusterWidth(cluster, visual, tabWidth),
+		}
+		clusters = append(clusters, g)
+		offset += len(cluster)
+		col += g.Runes
+		visual += g.Width
+	}
+	return clusters
+}
+
+// VisualWidth returns how many cells s takes, with tab stops counted from
+// its start
+func VisualWidth(s string, tabWidth int) int {
+	width := 0
+	for _, g := range Graphemes(s, tabWidth) {
+		width += g.Width
+	}
+	return width
+}
+
+// OffsetAtVisual returns the byte offset in s of the character drawn at cell
+// x, or len(s) past its end
+func OffsetAtVisual(s string, x, tabWidth int) int {
+	for _, g := range Graphemes(s, tabWidth) {
+		if x < g.Visual+g.Width {
+			return g.Offset
+		}
+	}
+	return len(s)
+}
+
+// WrapLine splits line into segments that fit in width cells, never
+// splitting a grapheme cluster. Each segment is drawn from the left edge,
+// so its tab stops count from its own start.
+func WrapLine(line string, width, tabWidth int) []string {
+	if width <= 0 || line == "" {
+		return []string{line}
+	}
+	var segments []string
+	start, offset, cells, state := 0, 0, 0, -1
+	for rest := line; rest != ""; {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		w := clusterWidth(cluster, cells, tabWidth)
+		if cells+w > width && offset > start {
+			segments = append(segments, line[start:offset])
+			start, cells = offset, 0
+			w = clusterWidth(cluster, 0, tabWidth)
+		}
+		cells += w
+		offset += len(cluster)
+	}
+	return append(segments, line[start:])
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestSetAmbiguousWidth(t *testing.T) {
+	defer SetAmbiguousWidth(AmbiguousAuto)
+
+	// U+2026 HORIZONTAL ELLIPSIS is East Asian ambiguous width
+	SetAmbiguousWidth(AmbiguousWide)
+	if got := runewidth.StringWidth("…"); got != 2 {
+		t.Errorf("wide policy: width = %d, want 2", got)
+	}
+
+	SetAmbiguousWidth(AmbiguousNarrow)
+	if got := runewidth.StringWidth("…"); got != 1 {
+		t.Errorf("narrow policy: width = %d, want 1", got)
+	}
+
+	// Unambiguous characters are unaffected
+	SetAmbiguousWidth(AmbiguousWide)
+	if got := runewidth.StringWidth("a日"); got != 3 {
+		t.Errorf("width of 'a日' = %d, want 3", got)
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	// e + combining acute, a ZWJ emoji sequence, then a tab to the next stop
+	line := "e\u0301x\U0001F469\u200D\U0001F4BB\t"
+	got := Graphemes(line, 4)
+	want := []Grapheme{
+		{Text: "e\u0301", Offset: 0, Col: 0, Runes: 2, Visual: 0, Width: 1},
+		{Text: "x", Offset: 3, Col: 2, Runes: 1, Visual: 1, Width: 1},
+		{Text: "\U0001F469\u200D\U0001F4BB", Offset: 4, Col: 3, Runes: 3, Visual: 2, Width: 2},
+		{Text: "\t", Offset: 15, Col: 6, Runes: 1, Visual: 4, Width: 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Graphemes = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cluster %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"\t", 4},
+		{"a\t", 4},
+		{"abc\t", 4},
+		{"abcd\t", 8},
+		{"a\tb\t", 8},
+	}
+	for _, tt := range tests {
+		if got := VisualWidth(tt.line, 4); got != tt.want {
+			t.Errorf("VisualWidth(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+	if got := VisualWidth("ab\t", 8); got != 8 {
+		t.Errorf("VisualWidth with tab width 8 = %d, want 8", got)
+	}
+
+	// Cells 1-3 are the tab after "a"
+	for x, want := range []int{0, 1, 1, 1, 2, 3} {
+		if got := OffsetAtVisual("a\tb", x, 4); got != want {
+			t.Errorf("OffsetAtVisual(%d) = %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  []string
+	}{
+		{"", 4, []string{""}},
+		{"abcdefg", 3, []string{"abc", "def", "g"}},
+		// The tab after "ab" takes two cells and ends the first row
+		{"ab\tcd", 4, []string{"ab\t", "cd"}},
+		// A tab that doesn't fit starts the next row, where it is full width
+		{"abcd\tx", 5, []string{"abcd", "\tx"}},
+		// Wide characters are never split
+		{"a\u65E5\u672C", 2, []string{"a", "\u65E5", "\u672C"}},
+	}
+	for _, tt := range tests {
+		got := WrapLine(tt.line, tt.width, 4)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("WrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}